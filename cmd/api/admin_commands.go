@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/config"
+	"gin-boilerplate/internal/infrastructure/persistence/postgres"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newAdminCommand creates the command group of operational tasks, which work on the database
+// directly so they don't need ad-hoc SQL
+func newAdminCommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Operational tasks on users, tokens and documents",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		newCreateAdminCommand(opts),
+		newResetPasswordCommand(opts),
+		newRevokeUserTokensCommand(opts),
+		newRotateJWTSecretCommand(opts),
+		newCleanupTokensCommand(opts),
+		newReindexDocumentsCommand(opts),
+		newIndexDocumentsCommand(opts),
+	)
+	return cmd
+}
+
+// runAdmin loads the configuration, connects to the database and runs task with an admin use case
+func runAdmin(opts *rootOptions, task func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error) error {
+	cfg, logger, err := opts.load()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Security alerts are emailed through the job queue, whose workers run in the server. Without
+	// Redis there is no event stream to publish them on.
+	jobQueue := service.NewJobQueue(postgres.NewJobRepository(db.GetDB()), service.JobQueueConfig{
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+	}, nil)
+	userSettingsRepo := postgres.NewUserSettingsRepository(db.GetDB())
+	emailService, err := newEmailService(cfg, jobQueue, userSettingsRepo)
+	if err != nil {
+		return err
+	}
+	securityEventService := service.NewSecurityEventService(
+		postgres.NewSecurityEventRepository(db.GetDB()),
+		nil,
+		emailService,
+		nil,
+		nil,
+		userSettingsRepo,
+		service.SecurityEventConfig{EmailAlerts: cfg.SecurityEvents.EmailAlerts},
+		nil,
+	)
+
+	adminUseCase := usecase.NewAdminUseCase(
+		postgres.NewUserRepository(db.GetDB()),
+		postgres.NewTokenRepository(db.GetDB()),
+		postgres.NewDocumentRepository(db.GetDB()),
+		newPasswordService(cfg),
+		securityEventService,
+		service.NewAuditLogService(postgres.NewAuditLogRepository(db.GetDB())),
+		newSearchIndexer(cfg),
+	)
+	return task(context.Background(), adminUseCase, logger)
+}
+
+// newCreateAdminCommand creates the command creating an admin user
+func newCreateAdminCommand(opts *rootOptions) *cobra.Command {
+	var name, password string
+
+	cmd := &cobra.Command{
+		Use:   "create-admin <email>",
+		Short: "Create a verified admin user",
+		Long:  "Create a verified admin user. The password is read from stdin unless --password is set. Use seed to promote an existing user instead.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, err := readPassword(cmd, password)
+			if err != nil {
+				return err
+			}
+
+			return runAdmin(opts, func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error {
+				user, err := adminUseCase.CreateAdmin(ctx, args[0], name, password)
+				if err != nil {
+					return err
+				}
+				logger.WithFields(logrus.Fields{"user_id": user.ID, "email": user.Email}).Info("Admin user created")
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "Admin", "name of the admin user")
+	cmd.Flags().StringVar(&password, "password", "", "password of the admin user (default read from stdin)")
+	return cmd
+}
+
+// newResetPasswordCommand creates the command setting a user's password
+func newResetPasswordCommand(opts *rootOptions) *cobra.Command {
+	var password string
+
+	cmd := &cobra.Command{
+		Use:   "reset-password <email or user ID>",
+		Short: "Set a new password for a user and revoke the user's refresh tokens",
+		Long:  "Set a new password for a user and revoke the user's refresh tokens. The password is read from stdin unless --password is set.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, err := readPassword(cmd, password)
+			if err != nil {
+				return err
+			}
+
+			return runAdmin(opts, func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error {
+				user, err := adminUseCase.ResetPassword(ctx, args[0], password)
+				if err != nil {
+					return err
+				}
+				logger.WithFields(logrus.Fields{"user_id": user.ID, "email": user.Email}).Info("Password reset and refresh tokens revoked")
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&password, "password", "", "new password (default read from stdin)")
+	return cmd
+}
+
+// newRevokeUserTokensCommand creates the command logging a user out of every device
+func newRevokeUserTokensCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke-user-tokens <email or user ID>",
+		Short: "Revoke a user's refresh tokens, logging the user out once the access tokens expire",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdmin(opts, func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error {
+				user, err := adminUseCase.RevokeUserTokens(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				logger.WithFields(logrus.Fields{"user_id": user.ID, "email": user.Email}).Info("Refresh tokens revoked")
+				return nil
+			})
+		},
+	}
+}
+
+// newRotateJWTSecretCommand creates the command starting a JWT secret rotation. The settings live
+// in the deployment, so it prints them instead of changing them.
+func newRotateJWTSecretCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-jwt-secret",
+		Short: "Generate a new JWT secret and print the settings rotating to it",
+		Long: "Generate a new JWT secret and print JWT_SECRET and JWT_PREVIOUS_SECRETS for the deployment. " +
+			"The current secret keeps validating tokens until it is removed from JWT_PREVIOUS_SECRETS.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			secret, err := generateJWTSecret()
+			if err != nil {
+				return err
+			}
+			printJWTRotation(cmd.OutOrStdout(), cfg.JWT, secret)
+			return nil
+		},
+	}
+}
+
+// jwtSecretBytes is the number of random bytes of a generated JWT secret, the key size of HS256
+const jwtSecretBytes = 32
+
+// generateJWTSecret returns a random hex-encoded secret
+func generateJWTSecret() (string, error) {
+	bytes := make([]byte, jwtSecretBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// printJWTRotation writes the settings making secret the JWT secret, keeping the current and
+// previous secrets valid
+func printJWTRotation(w io.Writer, jwtConfig config.JWTConfig, secret string) {
+	previous := append([]string{jwtConfig.Secret}, jwtConfig.PreviousSecrets...)
+	fmt.Fprintf(w, "JWT_SECRET=%s\n", secret)
+	fmt.Fprintf(w, "JWT_PREVIOUS_SECRETS=%s\n", strings.Join(previous, ","))
+	fmt.Fprintf(w, "\n# Deploy these settings to every instance. After JWT_REFRESH_EXPIRY (%s), once\n", jwtConfig.RefreshExpiry)
+	fmt.Fprintln(w, "# ginfinity_auth_previous_key_tokens_total stops growing, remove the old secrets from")
+	fmt.Fprintln(w, "# JWT_PREVIOUS_SECRETS.")
+}
+
+// newCleanupTokensCommand creates the command deleting expired and revoked refresh tokens, as
+// the token_purge task does
+func newCleanupTokensCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cleanup-tokens",
+		Short: "Delete expired and revoked refresh tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, logger, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			db, err := openDatabase(cfg)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			cleanupUseCase := usecase.NewTokenCleanupUseCase(postgres.NewTokenRepository(db.GetDB()))
+			deleted, remaining, err := cleanupUseCase.Execute(context.Background())
+			if err != nil {
+				return err
+			}
+			logger.WithFields(logrus.Fields{"deleted": deleted, "remaining": remaining}).Info("Expired refresh tokens deleted")
+			return nil
+		},
+	}
+}
+
+// newReindexDocumentsCommand creates the command rebuilding the indexes of the documents
+func newReindexDocumentsCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex-documents",
+		Short: "Rebuild the database indexes of the documents without locking the table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdmin(opts, func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error {
+				if err := adminUseCase.ReindexDocuments(ctx); err != nil {
+					return err
+				}
+				logger.Info("Documents reindexed")
+				return nil
+			})
+		},
+	}
+}
+
+// newIndexDocumentsCommand creates the command indexing every document in the external search
+// engine
+func newIndexDocumentsCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "index-documents",
+		Short: "Index every document in the OpenSearch or Elasticsearch search backend",
+		Long:  "Index every document in the OpenSearch or Elasticsearch cluster of SEARCH_BACKEND=opensearch, after switching to it or to rebuild a lost index. The server keeps the index up to date afterwards.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdmin(opts, func(ctx context.Context, adminUseCase *usecase.AdminUseCase, logger *logrus.Logger) error {
+				indexed, err := adminUseCase.IndexDocuments(ctx)
+				if err != nil {
+					return err
+				}
+				logger.WithField("documents", indexed).Info("Documents indexed")
+				return nil
+			})
+		},
+	}
+}
+
+// readPassword returns the flag value, or else the first line of stdin, so passwords don't have
+// to be on the command line
+func readPassword(cmd *cobra.Command, flagValue string) (string, error) {
+	if cmd.Flags().Changed("password") {
+		return flagValue, nil
+	}
+
+	fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/infrastructure/config"
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/metrics"
+	"gin-boilerplate/internal/infrastructure/persistence/postgres"
+	"gin-boilerplate/internal/interfaces/http/handler"
+	httpmiddleware "gin-boilerplate/internal/interfaces/http/middleware"
+	"gin-boilerplate/internal/interfaces/http/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// rootOptions holds the flags shared by every command. Flags that are set override the
+// environment and the config file.
+type rootOptions struct {
+	configFile string
+	port       string
+	logLevel   string
+}
+
+// newRootCommand creates the command line of the server binary
+func newRootCommand() *cobra.Command {
+	opts := &rootOptions{}
+	serveCmd := newServeCommand(opts)
+
+	rootCmd := &cobra.Command{
+		Use:     "gin-boilerplate",
+		Short:   "Gin Boilerplate API server and operational commands",
+		Version: version,
+		// Serve without a subcommand, so existing deployments keep working
+		RunE:         serveCmd.RunE,
+		SilenceUsage: true,
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&opts.configFile, "config", "", "path to a YAML or TOML config file (default $CONFIG_FILE or ./config.yaml)")
+	flags.StringVar(&opts.port, "port", "", "HTTP port, overriding SERVER_PORT")
+	flags.StringVar(&opts.logLevel, "log-level", "", "log level (trace, debug, info, warn, error), overriding LOG_LEVEL")
+
+	rootCmd.AddCommand(
+		serveCmd,
+		newMigrateCommand(opts),
+		newSeedCommand(opts),
+		newRoutesCommand(opts),
+		newEmailTestCommand(opts),
+		newAdminCommand(opts),
+	)
+	return rootCmd
+}
+
+// load applies the flag overrides, then loads the configuration and sets up the logger
+func (o *rootOptions) load() (*config.Config, *logrus.Logger, error) {
+	// The environment is the highest precedence layer of the configuration
+	overrides := []struct{ flag, env, value string }{
+		{"port", "SERVER_PORT", o.port},
+		{"log-level", "LOG_LEVEL", o.logLevel},
+	}
+	for _, override := range overrides {
+		if override.value == "" {
+			continue
+		}
+		if err := os.Setenv(override.env, override.value); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply --%s: %w", override.flag, err)
+		}
+	}
+
+	cfg, err := config.Load(o.configFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger := setupLogger(cfg)
+	logging.SetDefault(logger)
+	if err := applyModuleLevels(cfg); err != nil {
+		return nil, nil, err
+	}
+	return cfg, logger, nil
+}
+
+// applyModuleLevels overrides the levels of the configured log modules
+func applyModuleLevels(cfg *config.Config) error {
+	for module, name := range cfg.Log.ModuleLevels {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid level of log module %s: %w", module, err)
+		}
+		if err := logging.SetModuleLevel(module, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newServeCommand creates the command starting the API
+func newServeCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP (and gRPC) API",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, logger, err := opts.load()
+			if err != nil {
+				return err
+			}
+			runServer(cfg, logger)
+			return nil
+		},
+	}
+}
+
+// newMigrateCommand creates the command migrating the database schema without serving
+func newMigrateCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the database schema and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, logger, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			// Connecting migrates the schema
+			db, err := openDatabase(cfg)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			logger.Info("Database migrated")
+			return nil
+		},
+	}
+}
+
+// newSeedCommand creates the command seeding the admin user and demo data. Flags override the
+// SEED_* settings.
+func newSeedCommand(opts *rootOptions) *cobra.Command {
+	var email, name, password string
+	var demo bool
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create an admin user, or promote an existing one, and demo data, and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, logger, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			seedConfig := cfg.Seed
+			flags := cmd.Flags()
+			if flags.Changed("admin-email") {
+				seedConfig.AdminEmail = email
+			}
+			if flags.Changed("admin-name") {
+				seedConfig.AdminName = name
+			}
+			if flags.Changed("admin-password") {
+				seedConfig.AdminPassword = password
+			}
+			if flags.Changed("demo") {
+				seedConfig.DemoData = demo
+			}
+			if seedConfig.AdminEmail == "" && !seedConfig.DemoData {
+				return fmt.Errorf("nothing to seed: set --admin-email (or SEED_ADMIN_EMAIL) or --demo")
+			}
+			if seedConfig.DemoData && cfg.IsProduction() {
+				return fmt.Errorf("demo data can't be seeded in production")
+			}
+
+			db, err := openDatabase(cfg)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			seedUseCase := usecase.NewSeedUseCase(postgres.NewUserRepository(db.GetDB()), newPasswordService(cfg))
+			return seed(context.Background(), seedUseCase, seedConfig, logger)
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "admin-email", "", "email of the admin user (default $SEED_ADMIN_EMAIL)")
+	cmd.Flags().StringVar(&name, "admin-name", "Admin", "name of the admin user (default $SEED_ADMIN_NAME)")
+	cmd.Flags().StringVar(&password, "admin-password", "", "password of the admin user, if it is created (default $SEED_ADMIN_PASSWORD)")
+	cmd.Flags().BoolVar(&demo, "demo", false, "also create demo users (default $SEED_DEMO_DATA)")
+	return cmd
+}
+
+// seed creates the admin user and demo data of seedConfig. It is idempotent.
+func seed(ctx context.Context, seedUseCase *usecase.SeedUseCase, seedConfig config.SeedConfig, logger *logrus.Logger) error {
+	if seedConfig.AdminEmail != "" {
+		changed, err := seedUseCase.SeedAdmin(ctx, seedConfig.AdminEmail, seedConfig.AdminName, seedConfig.AdminPassword)
+		if err != nil {
+			return fmt.Errorf("failed to seed admin user: %w", err)
+		}
+
+		if changed {
+			logger.WithField("email", seedConfig.AdminEmail).Info("Admin user seeded")
+		} else {
+			logger.WithField("email", seedConfig.AdminEmail).Info("Admin user already exists")
+		}
+	}
+
+	if seedConfig.DemoData {
+		created, err := seedUseCase.SeedDemoData(ctx, seedConfig.DemoPassword)
+		if err != nil {
+			return fmt.Errorf("failed to seed demo data: %w", err)
+		}
+		logger.WithField("users_created", created).Info("Demo data seeded")
+	}
+
+	return nil
+}
+
+// newEmailTestCommand creates the command sending a test email right away, to check the email
+// settings
+func newEmailTestCommand(opts *rootOptions) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "email-test",
+		Short: "Send a test email with the configured driver and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, logger, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			emailService, err := newEmailService(cfg, nil, nil)
+			if err != nil {
+				return fmt.Errorf("failed to initialize email delivery: %w", err)
+			}
+			data := map[string]interface{}{"SentAt": time.Now().Format(time.RFC1123)}
+			if err := emailService.SendNow(context.Background(), to, "test", data); err != nil {
+				return err
+			}
+			logger.WithFields(logrus.Fields{"to": to, "driver": cfg.Email.Driver}).Info("Test email sent")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "recipient of the test email")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+// newRoutesCommand creates the command listing the HTTP routes
+func newRoutesCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "List the HTTP routes and their handlers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "METHOD\tPATH\tHANDLER")
+			for _, route := range listRoutes(cfg) {
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", route.Method, route.Path, handlerName(route.Handler))
+			}
+			return writer.Flush()
+		},
+	}
+}
+
+// listRoutes registers the routes the server would serve. Handlers and middleware are only
+// referenced, never called, so empty ones are enough and nothing is connected to.
+func listRoutes(cfg *config.Config) gin.RoutesInfo {
+	noop := func() gin.HandlerFunc {
+		return func(c *gin.Context) {}
+	}
+
+	var grpcGateway http.Handler
+	if cfg.GRPC.GatewayEnabled {
+		grpcGateway = http.NotFoundHandler()
+	}
+
+	r := router.NewRouter(
+		&handler.AuthHandler{},
+		&handler.UserHandler{},
+		&handler.DocumentHandler{},
+		&handler.AvatarHandler{},
+		&handler.APIKeyHandler{},
+		&handler.SessionHandler{},
+		&handler.EmailChangeHandler{},
+		&handler.UserProviderHandler{},
+		&handler.TwoFactorHandler{},
+		&handler.WebhookHandler{},
+		&handler.SecurityEventHandler{},
+		&handler.DashboardHandler{},
+		&handler.InvitationHandler{},
+		&handler.RoleHandler{},
+		&handler.AuditLogHandler{},
+		&handler.UserMetadataHandler{},
+		&handler.ActivityHandler{},
+		&handler.UserSettingsHandler{},
+		&handler.QuotaHandler{},
+		&handler.RateLimitHandler{},
+		&handler.LogLevelHandler{},
+		&handler.ConfigHandler{},
+		&handler.JobHandler{},
+		&handler.HealthHandler{},
+		&handler.JWKSHandler{},
+		&handler.EventHandler{},
+		nil,
+		&httpmiddleware.AuthMiddleware{},
+		&httpmiddleware.RoleMiddleware{},
+		&httpmiddleware.RateLimitMiddleware{},
+		&httpmiddleware.QuotaMiddleware{},
+		&httpmiddleware.ConcurrencyLimitMiddleware{},
+		nil,
+		&httpmiddleware.TimeoutMiddleware{},
+		httpmiddleware.NewMetricsMiddleware(metrics.NewMetrics()),
+		&httpmiddleware.NetworkRestrictionMiddleware{},
+		noop,
+		noop,
+		noop,
+		noop,
+		grpcGateway,
+	)
+	return r.GetEngine().Routes()
+}
+
+// handlerName shortens a handler's function name, e.g. "handler.(*AuthHandler).Login"
+func handlerName(name string) string {
+	return strings.TrimSuffix(path.Base(name), "-fm")
+}
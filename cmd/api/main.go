@@ -3,27 +3,48 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// The time zones of user settings, on hosts without a zoneinfo database
+	_ "time/tzdata"
 
 	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/captcha"
+	"gin-boilerplate/internal/infrastructure/clamav"
 	"gin-boilerplate/internal/infrastructure/config"
+	"gin-boilerplate/internal/infrastructure/disposable"
+	"gin-boilerplate/internal/infrastructure/email"
+	"gin-boilerplate/internal/infrastructure/errorreporting"
+	"gin-boilerplate/internal/infrastructure/geoip"
+	"gin-boilerplate/internal/infrastructure/health"
+	"gin-boilerplate/internal/infrastructure/httpserver"
+	"gin-boilerplate/internal/infrastructure/i18n"
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/metrics"
+	"gin-boilerplate/internal/infrastructure/oauth"
 	"gin-boilerplate/internal/infrastructure/persistence/postgres"
 	"gin-boilerplate/internal/infrastructure/redis"
+	"gin-boilerplate/internal/infrastructure/search"
+	"gin-boilerplate/internal/infrastructure/secrets"
+	"gin-boilerplate/internal/infrastructure/shutdown"
+	"gin-boilerplate/internal/infrastructure/sms"
 	"gin-boilerplate/internal/infrastructure/storage"
-	"gin-boilerplate/internal/infrastructure/redis"
+	grpcserver "gin-boilerplate/internal/interfaces/grpc/server"
 	"gin-boilerplate/internal/interfaces/http/handler"
 	httpmiddleware "gin-boilerplate/internal/interfaces/http/middleware"
 	"gin-boilerplate/internal/interfaces/http/router"
 
+	_ "gin-boilerplate/docs" // swagger docs
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	_ "gin-boilerplate/docs" // swagger docs
 )
 
 // @title Gin Boilerplate API
@@ -46,31 +67,87 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// version is the application version reported in logs and health checks
+const version = "1.0.0"
+
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
 	}
+}
 
-	// Setup logger
-	logger := setupLogger(cfg)
-
+// runServer starts the API and blocks until it is shut down by a signal
+func runServer(cfg *config.Config, logger *logrus.Logger) {
 	logger.WithFields(logrus.Fields{
-		"version": "1.0.0",
+		"version": version,
 		"env":     cfg.Server.Env,
 	}).Info("Starting Gin Boilerplate API")
 
+	// Report the effective configuration, with credentials redacted
+	summary := logrus.Fields{}
+	for _, setting := range cfg.Summary() {
+		summary[setting.Name] = setting.String()
+	}
+	logger.WithFields(summary).Info("Effective configuration")
+
+	// Subsystems register their cleanup here as they are created; hooks run in reverse order
+	shutdownManager := shutdown.NewManager(logger, cfg.Timeout.Shutdown)
+
+	// Setup error reporting
+	reporter, err := errorreporting.NewReporter(errorreporting.Config{
+		DSN:         cfg.Sentry.DSN,
+		Environment: cfg.Sentry.Environment,
+		Release:     cfg.Sentry.Release,
+		SampleRate:  cfg.Sentry.SampleRate,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize error reporting")
+	}
+	shutdownManager.Register("error reporting", func(ctx context.Context) error {
+		reporter.Flush(2 * time.Second)
+		return nil
+	})
+	if reporter.Enabled() {
+		logger.Info("Error reporting enabled")
+	}
+
+	// Keep the secrets provider credentials renewed and report rotated secrets
+	if provider := cfg.SecretsProvider(); provider != nil {
+		logger.WithField("provider", provider.Name()).Info("Loaded secrets")
+		if cfg.Secrets.RefreshInterval > 0 {
+			secretsCtx, stopSecrets := context.WithCancel(context.Background())
+			go secrets.Watch(secretsCtx, provider, cfg.Secrets.RefreshInterval, cfg.SecretValues(),
+				func(changed []string) {
+					// Components read their settings once, so new values apply after a restart
+					logger.WithField("secrets", changed).Warn("Secrets changed in the secrets provider; restart to apply them")
+				},
+				func(err error) {
+					logger.WithError(err).Error("Failed to refresh secrets")
+				},
+			)
+			shutdownManager.RegisterFunc("secrets refresh", stopSecrets)
+		}
+	}
+
+	// SIGUSR1 switches debug logging on and off without a restart
+	levelCtx, stopLevelToggle := context.WithCancel(context.Background())
+	go toggleDebugLogging(levelCtx, logger)
+	shutdownManager.RegisterFunc("log level toggle", stopLevelToggle)
+
+	// Setup message translations
+	translator, err := i18n.NewTranslator()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load message translations")
+	}
+
 	// Setup database
-	db, err := postgres.NewDatabase(cfg.Database.DSN, cfg.IsDevelopment())
+	db, err := openDatabase(cfg)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to connect to database")
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.WithError(err).Error("Failed to close database connection")
-		}
-	}()
+	shutdownManager.Register("database", func(ctx context.Context) error {
+		return db.Close()
+	})
 
 	// Check database health
 	if err := db.Health(); err != nil {
@@ -79,20 +156,88 @@ func main() {
 
 	logger.Info("Database connection established successfully")
 
+	// Setup Prometheus metrics; connection pools are registered once they exist
+	appMetrics := metrics.NewMetrics()
+
 	// Setup domain services
-	passwordService := service.NewPasswordService()
-	tokenService := service.NewTokenService(
-		cfg.JWT.Secret,
-		cfg.JWT.AccessExpiry,
-		cfg.JWT.RefreshExpiry,
-	)
+	passwordService := newPasswordService(cfg)
+	tokenService, err := newTokenService(cfg, func(keyID string, tokenType service.TokenType) {
+		appMetrics.PreviousJWTKeyUsed(string(tokenType), keyID)
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load JWT keys")
+	}
 
-	// Setup Google OAuth configuration
-	googleConfig := config.NewGoogleOAuthConfig(
-		cfg.Google.ClientID,
-		cfg.Google.ClientSecret,
-		cfg.Google.RedirectURL,
-	)
+	// Setup the OAuth providers users can sign in with
+	oauthProviders, err := newOAuthProviders(cfg)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to register OAuth providers")
+	}
+	logger.WithField("providers", oauthProviders.Names()).Info("OAuth providers registered")
+
+	// Setup CAPTCHA verification of the auth endpoints
+	var captchaService service.CaptchaService
+	if cfg.Captcha.Enabled() {
+		captchaService, err = captcha.New(captcha.Config{
+			Provider:  cfg.Captcha.Provider,
+			SecretKey: cfg.Captcha.SecretKey,
+			MinScore:  cfg.Captcha.MinScore,
+			Timeout:   cfg.Captcha.Timeout,
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to setup CAPTCHA verification")
+		}
+	}
+
+	// Setup text messages for phone verification and SMS two-factor authentication
+	var smsService service.SMSService
+	if cfg.SMS.Enabled() {
+		smsService, err = sms.New(sms.Config{
+			Provider: cfg.SMS.Provider,
+			From:     cfg.SMS.From,
+			Timeout:  cfg.SMS.Timeout,
+			Twilio: sms.TwilioConfig{
+				AccountSID: cfg.SMS.TwilioAccountSID,
+				AuthToken:  cfg.SMS.TwilioAuthToken,
+			},
+			Vonage: sms.VonageConfig{
+				APIKey:    cfg.SMS.VonageAPIKey,
+				APISecret: cfg.SMS.VonageAPISecret,
+			},
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to setup text messages")
+		}
+	}
+
+	// Index documents in OpenSearch or Elasticsearch, which then answers document searches
+	searchIndexer := newSearchIndexer(cfg)
+	if searchIndexer != nil {
+		indexCtx, cancelIndex := context.WithTimeout(context.Background(), 30*time.Second)
+		err := searchIndexer.EnsureIndex(indexCtx)
+		cancelIndex()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to setup the search index")
+		}
+	}
+
+	// Setup virus scanning of uploaded files with ClamAV
+	var virusScanner service.VirusScanner
+	if cfg.VirusScan.Enabled() {
+		clamavScanner, err := clamav.New(clamav.Config{
+			Address: cfg.VirusScan.ClamAVAddress,
+			Timeout: cfg.VirusScan.Timeout,
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to setup virus scanning")
+		}
+		// clamd may start after the API, so only warn when it doesn't answer yet
+		if err := clamavScanner.Ping(context.Background()); err != nil {
+			logger.WithError(err).Warn("ClamAV is not reachable, uploads can't be scanned until it is")
+		}
+		virusScanner = clamavScanner
+	}
+	virusScanService := service.NewVirusScanService(virusScanner, cfg.VirusScan.Required())
 
 	// Setup S3 client
 	s3Client, err := storage.NewS3Client(storage.S3Config{
@@ -102,6 +247,8 @@ func main() {
 		Region:          cfg.S3.Region,
 		Bucket:          cfg.S3.Bucket,
 		UseSSL:          cfg.S3.UseSSL,
+		DialTimeout:     cfg.S3.DialTimeout,
+		Timeout:         cfg.S3.Timeout,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize S3 client")
@@ -109,80 +256,557 @@ func main() {
 
 	// Setup Redis client
 	redisClient, err := redis.NewRedisClient(redis.RedisConfig{
-		Host:     cfg.Redis.Host,
-		Port:     cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-		PoolSize: cfg.Redis.PoolSize,
+		Host:         cfg.Redis.Host,
+		Port:         cfg.Redis.Port,
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+		PoolSize:     cfg.Redis.PoolSize,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		DialTimeout:  cfg.Redis.DialTimeout,
+		ReadTimeout:  cfg.Redis.ReadTimeout,
+		WriteTimeout: cfg.Redis.WriteTimeout,
+		PoolTimeout:  cfg.Redis.PoolTimeout,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize Redis client")
 	}
-	defer redisClient.Close()
+	shutdownManager.Register("redis", func(ctx context.Context) error {
+		return redisClient.Close()
+	})
+
+	// Expose the connection pools and query latencies
+	sqlDB, err := db.GetDB().DB()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to access database connection pool")
+	}
+	appMetrics.RegisterDB("postgres", sqlDB)
+	if err := db.ObserveQueries(appMetrics); err != nil {
+		logger.WithError(err).Fatal("Failed to observe database queries")
+	}
+	appMetrics.RegisterRedis(redisClient.GetClient())
+
+	// Setup cache-backed services
+	cacheService := service.NewCacheService(redisClient)
+
+	// Setup event delivery to live connections
+	eventBus := service.NewEventBus(redisClient, service.EventBusConfig{
+		HistorySize: cfg.Events.HistorySize,
+		HistoryTTL:  cfg.Events.HistoryTTL,
+	})
+	if err := eventBus.Start(context.Background()); err != nil {
+		logger.WithError(err).Fatal("Failed to start event bus")
+	}
+	shutdownManager.Register("event bus", func(ctx context.Context) error {
+		return eventBus.Close()
+	})
+	loginThrottleService := service.NewLoginThrottleService(cacheService, service.LoginThrottleConfig{
+		MaxAttemptsPerAccount:   cfg.LoginThrottle.MaxAttemptsPerAccount,
+		MaxAttemptsPerAccountIP: cfg.LoginThrottle.MaxAttemptsPerAccountIP,
+		MaxAttemptsPerIP:        cfg.LoginThrottle.MaxAttemptsPerIP,
+		Window:                  cfg.LoginThrottle.Window,
+		LockoutDuration:         cfg.LoginThrottle.LockoutDuration,
+	})
+	tokenDenylist := service.NewTokenDenylistService(cacheService, cfg.JWT.AccessExpiry)
+	oauthStates := service.NewOAuthStateService(cacheService, cfg.OAuth.StateTTL)
 
 	// Setup repositories
 	userRepo := postgres.NewUserRepository(db.GetDB())
 	tokenRepo := postgres.NewTokenRepository(db.GetDB())
 	documentRepo := postgres.NewDocumentRepository(db.GetDB())
+	apiKeyRepo := postgres.NewAPIKeyRepository(db.GetDB())
+	quotaRepo := postgres.NewQuotaRepository(db.GetDB())
+	jobRepo := postgres.NewJobRepository(db.GetDB())
+	webhookRepo := postgres.NewWebhookRepository(db.GetDB())
+	securityEventRepo := postgres.NewSecurityEventRepository(db.GetDB())
+	actionTokenRepo := postgres.NewActionTokenRepository(db.GetDB())
+	userProviderRepo := postgres.NewUserProviderRepository(db.GetDB())
+	previousPasswordRepo := postgres.NewPreviousPasswordRepository(db.GetDB())
+	recoveryCodeRepo := postgres.NewRecoveryCodeRepository(db.GetDB())
+	invitationRepo := postgres.NewInvitationRepository(db.GetDB())
+	roleRepo := postgres.NewRoleRepository(db.GetDB())
+	auditLogRepo := postgres.NewAuditLogRepository(db.GetDB())
+	userMetadataFieldRepo := postgres.NewUserMetadataFieldRepository(db.GetDB())
+	activityRepo := postgres.NewActivityRepository(db.GetDB())
+	userSettingsRepo := postgres.NewUserSettingsRepository(db.GetDB())
+	unitOfWork := postgres.NewUnitOfWork(db.GetDB())
+
+	// Setup the persistent background job queue; job types are registered with their use cases
+	jobQueue := service.NewJobQueue(jobRepo, service.JobQueueConfig{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+		RetryBackoff: cfg.Jobs.RetryBackoff,
+		Timeout:      cfg.Jobs.Timeout,
+		Retention:    cfg.Jobs.Retention,
+	}, func(jobType string, status entity.JobStatus, duration time.Duration) {
+		appMetrics.ObserveJob(jobType, string(status), duration)
+	})
+
+	// Setup email delivery through the job queue
+	emailService, err := newEmailService(cfg, jobQueue, userSettingsRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize email delivery")
+	}
+	jobQueue.Register(service.JobSendEmail, service.JSONJobFunc(emailService.Deliver))
+
+	// Setup event delivery to webhooks through the job queue
+	webhookService := service.NewWebhookService(webhookRepo, jobQueue, service.WebhookConfig{
+		Timeout:              cfg.Webhooks.Timeout,
+		AllowHTTP:            cfg.Webhooks.AllowHTTP,
+		AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+	})
+	jobQueue.Register(service.JobDeliverWebhook, service.JSONJobFunc(webhookService.Deliver))
+
+	// Resolve the countries of logins, to report logins from new countries
+	var geoIP service.GeoIPService
+	if cfg.SecurityEvents.GeoIPDatabase != "" {
+		geoIPDatabase, err := geoip.Open(cfg.SecurityEvents.GeoIPDatabase)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load GeoIP database")
+		}
+		logger.WithField("ranges", geoIPDatabase.Len()).Info("GeoIP database loaded")
+		geoIP = geoIPDatabase
+	}
+
+	// Reject addresses of disposable email providers, keeping their list up to date
+	var disposableEmails service.DisposableEmailService
+	if cfg.DisposableEmails.Blocked {
+		disposableList := disposable.New(cfg.DisposableEmails.ExtraDomains, cfg.DisposableEmails.AllowedDomains)
+		if cfg.DisposableEmails.ListURL != "" {
+			refreshCtx, cancelRefresh := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := disposableList.Refresh(refreshCtx, cfg.DisposableEmails.ListURL); err != nil {
+				logger.WithError(err).Warn("Failed to fetch disposable email domains; using the embedded list")
+			}
+			cancelRefresh()
+
+			disposableCtx, stopDisposable := context.WithCancel(context.Background())
+			go disposableList.Watch(disposableCtx, cfg.DisposableEmails.ListURL, cfg.DisposableEmails.RefreshInterval, func(err error) {
+				logger.WithError(err).Error("Failed to refresh disposable email domains")
+			})
+			shutdownManager.RegisterFunc("disposable email domains refresh", stopDisposable)
+		}
+		logger.WithField("domains", disposableList.Len()).Info("Disposable email domains loaded")
+		disposableEmails = disposableList
+	}
+
+	// Record the activity feeds of users
+	activityService := service.NewActivityService(activityRepo, eventBus)
+	// Record security events and alert the affected users
+	securityEventService := service.NewSecurityEventService(securityEventRepo, eventBus, emailService, geoIP, activityService, userSettingsRepo, service.SecurityEventConfig{
+		EmailAlerts: cfg.SecurityEvents.EmailAlerts,
+	}, func(eventType entity.SecurityEventType) {
+		appMetrics.SecurityEventRecorded(string(eventType))
+	})
+	// Record who did what for admins to review
+	auditLogService := service.NewAuditLogService(auditLogRepo)
+
+	// Single-use tokens of the links emailed to users
+	actionTokenService := service.NewActionTokenService(actionTokenRepo)
+	// Recent passwords users can't set again
+	passwordHistory := service.NewPasswordHistoryService(previousPasswordRepo, passwordService, cfg.Password.HistorySize)
+	// Single-use codes completing two-factor logins in place of the second factor
+	recoveryCodeService := service.NewRecoveryCodeService(recoveryCodeRepo)
+	// Invitations to register, required while registration is invite-only
+	invitationService := service.NewInvitationService(invitationRepo, cfg.Registration.InviteTTL)
+	// Permissions of the built-in and custom roles, checked by the routes
+	policyService := service.NewPolicyService(cacheService, roleRepo)
+
+	// Count logins and rate limit rejections per day for the dashboard
+	dailyCounters := service.NewDailyCounters(cacheService)
+
+	// One-time codes texted to phones, only when an SMS provider is configured
+	var otpService *service.OTPService
+	if smsService != nil {
+		otpService = service.NewOTPService(cacheService, smsService, service.OTPConfig{
+			TTL:         cfg.SMS.OTPTTL,
+			MaxAttempts: cfg.SMS.OTPMaxAttempts,
+		})
+	}
+
+	// Seed the admin user and demo data before serving
+	if cfg.Seed.OnStartup {
+		seedUseCase := usecase.NewSeedUseCase(userRepo, passwordService)
+		if err := seed(context.Background(), seedUseCase, cfg.Seed, logger); err != nil {
+			logger.WithError(err).Fatal("Failed to seed database")
+		}
+	}
 
 	// Setup use cases
-	registerUseCase := usecase.NewRegisterUseCase(userRepo, passwordService, tokenService)
-	loginUseCase := usecase.NewLoginUseCase(userRepo, tokenRepo, passwordService, tokenService)
-	refreshTokenUseCase := usecase.NewRefreshTokenUseCase(userRepo, tokenRepo, tokenService)
-	logoutUseCase := usecase.NewLogoutUseCase(tokenRepo)
-	googleAuthUseCase := usecase.NewGoogleAuthUseCase(userRepo, tokenRepo, tokenService)
+	sendVerificationUseCase := usecase.NewSendVerificationUseCase(userRepo, actionTokenService, emailService, usecase.EmailVerificationConfig{
+		URL:      cfg.EmailVerification.URL,
+		TokenTTL: cfg.EmailVerification.TokenTTL,
+		Required: cfg.EmailVerification.Required,
+	})
+	verifyEmailUseCase := usecase.NewVerifyEmailUseCase(userRepo, unitOfWork, actionTokenService)
+	registrationConfig := usecase.RegistrationConfig{
+		Closed:         cfg.Registration.Closed(),
+		InviteOnly:     cfg.Registration.InviteOnly(),
+		AllowedDomains: cfg.Registration.AllowedDomains,
+	}
+	registerUseCase := usecase.NewRegisterUseCase(userRepo, unitOfWork, passwordService, tokenService, invitationService, sendVerificationUseCase, disposableEmails, registrationConfig)
+	sessionConfig := usecase.SessionConfig{
+		RememberMeExpiry: cfg.JWT.RememberMeExpiry,
+		Sliding:          cfg.JWT.SlidingRefresh,
+	}
+	loginUseCase := usecase.NewLoginUseCase(userRepo, tokenRepo, unitOfWork, passwordService, passwordHistory, tokenService, loginThrottleService, securityEventService, auditLogService, dailyCounters, recoveryCodeService, otpService, sessionConfig, cfg.EmailVerification.Required)
+	refreshTokenUseCase := usecase.NewRefreshTokenUseCase(userRepo, tokenRepo, unitOfWork, tokenService, sessionConfig, securityEventService)
+	logoutUseCase := usecase.NewLogoutUseCase(tokenRepo, tokenDenylist, auditLogService)
+	listSessionsUseCase := usecase.NewListSessionsUseCase(tokenRepo)
+	revokeSessionUseCase := usecase.NewRevokeSessionUseCase(tokenRepo)
+	oauthLoginUseCase := usecase.NewOAuthLoginUseCase(userRepo, userProviderRepo, tokenRepo, unitOfWork, tokenService, invitationService, securityEventService, auditLogService, dailyCounters, registrationConfig, loginUseCase)
+	forgotPasswordUseCase := usecase.NewForgotPasswordUseCase(userRepo, actionTokenService, emailService, usecase.PasswordResetConfig{
+		URL:      cfg.PasswordReset.URL,
+		TokenTTL: cfg.PasswordReset.TokenTTL,
+	})
+	resetPasswordUseCase := usecase.NewResetPasswordUseCase(userRepo, tokenRepo, unitOfWork, passwordService, passwordHistory, actionTokenService, tokenDenylist, securityEventService, auditLogService)
+	emailChangeConfig := usecase.EmailChangeConfig{
+		URL:            cfg.EmailChange.URL,
+		RevertURL:      cfg.EmailChange.RevertURL,
+		TokenTTL:       cfg.EmailChange.TokenTTL,
+		RollbackWindow: cfg.EmailChange.RollbackWindow,
+	}
+	requestEmailChangeUseCase := usecase.NewRequestEmailChangeUseCase(userRepo, unitOfWork, passwordService, actionTokenService, emailService, disposableEmails, emailChangeConfig)
+	confirmEmailChangeUseCase := usecase.NewConfirmEmailChangeUseCase(userRepo, unitOfWork, actionTokenService, emailService, securityEventService, emailChangeConfig)
+	userProviderUseCase := usecase.NewUserProviderUseCase(userRepo, userProviderRepo, unitOfWork, passwordService, securityEventService)
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(userRepo, unitOfWork, passwordService, recoveryCodeService, otpService, securityEventService)
+	revertEmailChangeUseCase := usecase.NewRevertEmailChangeUseCase(userRepo, tokenRepo, unitOfWork, actionTokenService, securityEventService, tokenDenylist)
+	invitationUseCase := usecase.NewInvitationUseCase(userRepo, unitOfWork, invitationService, emailService, usecase.InvitationConfig{
+		URL: cfg.Registration.InviteURL,
+	})
 
 	// User management use cases
 	getUserProfileUseCase := usecase.NewGetUserProfileUseCase(userRepo)
-	updateUserProfileUseCase := usecase.NewUpdateUserProfileUseCase(userRepo)
+	updateUserProfileUseCase := usecase.NewUpdateUserProfileUseCase(userRepo, userMetadataFieldRepo, activityService)
+	changePasswordUseCase := usecase.NewChangePasswordUseCase(userRepo, tokenRepo, unitOfWork, passwordService, passwordHistory, tokenService, tokenDenylist, securityEventService, auditLogService)
 	listUsersUseCase := usecase.NewListUsersUseCase(userRepo)
-	deleteUserUseCase := usecase.NewDeleteUserUseCase(userRepo)
-	promoteUserUseCase := usecase.NewPromoteUserUseCase(userRepo)
-	demoteUserUseCase := usecase.NewDemoteUserUseCase(userRepo)
+	createUserUseCase := usecase.NewCreateUserUseCase(userRepo, roleRepo, passwordService, policyService, auditLogService)
+	exportUsersUseCase := usecase.NewExportUsersUseCase(userRepo)
+	deleteUserUseCase := usecase.NewDeleteUserUseCase(userRepo, tokenDenylist, auditLogService)
+	restoreUserUseCase := usecase.NewRestoreUserUseCase(userRepo, auditLogService)
+	unlockUserUseCase := usecase.NewUnlockUserUseCase(userRepo, loginThrottleService, auditLogService)
+	roleUseCase := usecase.NewRoleUseCase(roleRepo, userRepo, tokenRepo, unitOfWork, policyService, tokenDenylist, securityEventService, auditLogService)
+	accountDeletionUseCase := usecase.NewAccountDeletionUseCase(
+		userRepo,
+		documentRepo,
+		tokenRepo,
+		userProviderRepo,
+		apiKeyRepo,
+		webhookRepo,
+		previousPasswordRepo,
+		activityRepo,
+		userSettingsRepo,
+		unitOfWork,
+		passwordService,
+		recoveryCodeService,
+		jobQueue,
+		otpService,
+		tokenDenylist,
+		auditLogService,
+		usecase.AccountDeletionConfig{
+			Anonymize:   cfg.AccountDeletion.Anonymize(),
+			SearchIndex: searchIndexer != nil,
+		},
+	)
+
+	// Usage quotas
+	quotaService := service.NewQuotaService(cacheService, quotaRepo, service.QuotaLimits{
+		entity.QuotaMetricRequestsDaily:        cfg.Quota.RequestsPerDay,
+		entity.QuotaMetricUploadsMonthly:       cfg.Quota.UploadsPerMonth,
+		entity.QuotaMetricDownloadBytesMonthly: cfg.Quota.DownloadBytesPerMonth,
+	})
+	quotaUseCase := usecase.NewQuotaUseCase(userRepo, quotaRepo, quotaService)
 
 	// Document management use cases
-	documentUseCase := usecase.NewDocumentUseCase(documentRepo, s3Client)
+	documentUseCase := usecase.NewDocumentUseCase(documentRepo, unitOfWork, s3Client, quotaService, eventBus, webhookService, jobQueue, auditLogService, activityService, virusScanService, searchIndexer)
+	jobQueue.Register(usecase.JobDeleteDocumentFile, service.JSONJobFunc(documentUseCase.DeleteFile))
+	if searchIndexer != nil {
+		jobQueue.Register(usecase.JobIndexDocument, service.JSONJobFunc(documentUseCase.IndexDocument))
+	}
 
 	// Avatar management use cases
-	avatarService := service.NewAvatarService(s3Client)
-	avatarUseCase := usecase.NewAvatarUseCase(userRepo, avatarService, s3Client)
+	avatarService := service.NewAvatarService(s3Client, virusScanService)
+	avatarUseCase := usecase.NewAvatarUseCase(userRepo, unitOfWork, avatarService, s3Client, eventBus, webhookService, jobQueue, auditLogService, cfg.Avatar.Moderation)
+	jobQueue.Register(usecase.JobDeleteAvatar, service.JSONJobFunc(avatarUseCase.DeleteAvatarFile))
+
+	// User data export use cases
+	dataExportUseCase := usecase.NewDataExportUseCase(
+		userRepo,
+		documentRepo,
+		auditLogRepo,
+		activityRepo,
+		userSettingsRepo,
+		s3Client,
+		jobQueue,
+		emailService,
+		auditLogService,
+		usecase.DataExportConfig{LinkTTL: cfg.DataExport.LinkTTL},
+	)
+	jobQueue.Register(usecase.JobExportUserData, service.JSONJobFunc(dataExportUseCase.Export))
+	jobQueue.Register(usecase.JobDeleteUserExports, service.JSONJobFunc(dataExportUseCase.DeleteExports))
+
+	// Webhook management use cases
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, webhookService, cfg.Webhooks.MaxPerUser)
+
+	// Security event feed use cases
+	securityEventUseCase := usecase.NewSecurityEventUseCase(securityEventRepo, tokenRepo)
+
+	// Audit log review use cases
+	auditLogUseCase := usecase.NewAuditLogUseCase(auditLogRepo)
+
+	// Activity feed use cases
+	activityUseCase := usecase.NewActivityUseCase(activityRepo)
+
+	// User settings use cases
+	userSettingsUseCase := usecase.NewUserSettingsUseCase(userRepo, userSettingsRepo, unitOfWork, newUnsubscribeTokenService(cfg))
+
+	// Custom profile field administration use cases
+	userMetadataUseCase := usecase.NewUserMetadataUseCase(userMetadataFieldRepo)
+
+	// Background job administration use cases
+	jobUseCase := usecase.NewJobUseCase(jobRepo)
+
+	// Admin operations dashboard use cases
+	dashboardUseCase := usecase.NewDashboardUseCase(userRepo, documentRepo, jobRepo, webhookRepo, dailyCounters, cacheService, cfg.Dashboard.CacheTTL)
+
+	// API key management use cases
+	apiKeyService := service.NewAPIKeyService()
+	createAPIKeyUseCase := usecase.NewCreateAPIKeyUseCase(
+		apiKeyRepo,
+		apiKeyService,
+		cfg.RateLimit.APIKeyDefaultLimit,
+		cfg.RateLimit.APIKeyDefaultWindow,
+	)
+	listAPIKeysUseCase := usecase.NewListAPIKeysUseCase(apiKeyRepo)
+	revokeAPIKeyUseCase := usecase.NewRevokeAPIKeyUseCase(apiKeyRepo)
+	updateAPIKeyRateLimitUseCase := usecase.NewUpdateAPIKeyRateLimitUseCase(apiKeyRepo)
+	authenticateAPIKeyUseCase := usecase.NewAuthenticateAPIKeyUseCase(apiKeyRepo, userRepo, apiKeyService)
 
 	// Setup handlers
+	oauthConfig := handler.OAuthConfig{
+		CallbackMode:  cfg.OAuth.CallbackMode,
+		SuccessURL:    cfg.OAuth.SuccessURL,
+		CookieDomain:  cfg.OAuth.CookieDomain,
+		CookieSecure:  cfg.OAuth.CookieSecure,
+		RefreshExpiry: cfg.JWT.RefreshExpiry,
+	}
+	tokenCookies := handler.TokenCookieConfig{
+		Enabled:  cfg.AuthCookies.Enabled,
+		Domain:   cfg.AuthCookies.Domain,
+		Secure:   cfg.AuthCookies.Secure,
+		SameSite: cfg.AuthCookies.SameSiteMode(),
+		// Long enough for logins with remember_me
+		RefreshExpiry: max(cfg.JWT.RefreshExpiry, cfg.JWT.RememberMeExpiry),
+	}
 	authHandler := handler.NewAuthHandler(
 		registerUseCase,
 		loginUseCase,
 		refreshTokenUseCase,
 		logoutUseCase,
-		googleAuthUseCase,
-		googleConfig,
+		oauthLoginUseCase,
+		userProviderUseCase,
+		forgotPasswordUseCase,
+		resetPasswordUseCase,
+		sendVerificationUseCase,
+		verifyEmailUseCase,
+		oauthProviders,
+		oauthStates,
+		oauthConfig,
+		tokenCookies,
+		captchaService,
+		cfg.Captcha.Endpoints,
 	)
 
 	userHandler := handler.NewUserHandler(
 		getUserProfileUseCase,
 		updateUserProfileUseCase,
+		changePasswordUseCase,
 		listUsersUseCase,
+		createUserUseCase,
+		exportUsersUseCase,
 		deleteUserUseCase,
-		promoteUserUseCase,
-		demoteUserUseCase,
+		restoreUserUseCase,
+		unlockUserUseCase,
+		accountDeletionUseCase,
+		dataExportUseCase,
+		tokenCookies,
 	)
 
 	documentHandler := handler.NewDocumentHandler(documentUseCase)
 	avatarHandler := handler.NewAvatarHandler(avatarUseCase)
+	eventHandler := handler.NewEventHandler(eventBus, cfg.Events.HeartbeatInterval)
 
-	// Setup cache service and middleware
-	cacheService := service.NewCacheService(redisClient)
+	// Serve the built frontend, leaving the API and operational paths to their own 404s
+	var staticHandler *handler.StaticHandler
+	if cfg.Static.Dir != "" {
+		staticHandler = handler.NewStaticHandler(cfg.Static.Dir, cfg.Static.AssetsPath, cfg.Static.AssetsMaxAge,
+			"/api", "/swagger", "/rpc", "/metrics", "/healthz", "/readyz")
+	}
+	apiKeyHandler := handler.NewAPIKeyHandler(
+		createAPIKeyUseCase,
+		listAPIKeysUseCase,
+		revokeAPIKeyUseCase,
+		updateAPIKeyRateLimitUseCase,
+	)
+	sessionHandler := handler.NewSessionHandler(listSessionsUseCase, revokeSessionUseCase)
+	emailChangeHandler := handler.NewEmailChangeHandler(requestEmailChangeUseCase, confirmEmailChangeUseCase, revertEmailChangeUseCase)
+	userProviderHandler := handler.NewUserProviderHandler(userProviderUseCase, oauthProviders, oauthStates, oauthConfig)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUseCase)
+	webhookHandler := handler.NewWebhookHandler(webhookUseCase)
+	securityEventHandler := handler.NewSecurityEventHandler(securityEventUseCase)
+	dashboardHandler := handler.NewDashboardHandler(dashboardUseCase)
+	invitationHandler := handler.NewInvitationHandler(invitationUseCase)
+	roleHandler := handler.NewRoleHandler(roleUseCase)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogUseCase)
+	userMetadataHandler := handler.NewUserMetadataHandler(userMetadataUseCase)
+	activityHandler := handler.NewActivityHandler(activityUseCase)
+	userSettingsHandler := handler.NewUserSettingsHandler(userSettingsUseCase)
+	quotaHandler := handler.NewQuotaHandler(quotaUseCase)
+	jobHandler := handler.NewJobHandler(jobUseCase)
+
+	// Setup the gRPC API, which shares the use cases with the HTTP API
+	var grpcServer *grpcserver.Server
+	var grpcGateway http.Handler
+	if cfg.GRPC.Enabled {
+		grpcServer = grpcserver.NewServer(
+			grpcserver.NewAuthService(registerUseCase, loginUseCase, refreshTokenUseCase, logoutUseCase),
+			grpcserver.NewUserService(getUserProfileUseCase, updateUserProfileUseCase),
+			grpcserver.NewDocumentService(documentUseCase, policyService),
+			grpcserver.NewAuthInterceptor(tokenService, authenticateAPIKeyUseCase, tokenDenylist, grpcserver.PublicMethods...),
+			logger,
+			reporter,
+		)
+
+		if cfg.GRPC.GatewayEnabled {
+			grpcGateway, err = grpcserver.NewGateway(context.Background(), "localhost:"+cfg.GRPC.Port)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to setup gRPC gateway")
+			}
+		}
+	}
+
+	// Setup rate limit and quota middleware
+	failureModes := make(map[string]httpmiddleware.FailureMode, len(cfg.RateLimit.FailClosedRoutes))
+	for _, routeClass := range cfg.RateLimit.FailClosedRoutes {
+		failureModes[routeClass] = httpmiddleware.FailClosed
+	}
+	rateLimitPolicies := make(map[string]httpmiddleware.RateLimitPolicy, len(cfg.RateLimit.Policies))
+	for _, policy := range cfg.RateLimit.Policies {
+		rateLimitPolicies[policy.Name] = httpmiddleware.RateLimitPolicy{
+			Algorithm: policy.Algorithm,
+			Limit:     policy.Limit,
+			Window:    policy.Window,
+			Key:       policy.Key,
+		}
+	}
+	rateLimitTiers := make(map[entity.RateLimitTier]httpmiddleware.RateLimitPolicy, len(cfg.RateLimit.Tiers))
+	for _, tier := range cfg.RateLimit.Tiers {
+		rateLimitTiers[entity.RateLimitTier(tier.Name)] = httpmiddleware.RateLimitPolicy{
+			Algorithm: httpmiddleware.RateLimitFixedWindow,
+			Limit:     tier.Limit,
+			Window:    tier.Window,
+		}
+	}
+	rateLimitRoutes := make([]httpmiddleware.RateLimitRoute, len(cfg.RateLimit.Routes))
+	for i, route := range cfg.RateLimit.Routes {
+		rateLimitRoutes[i] = httpmiddleware.RateLimitRoute{Method: route.Method, Path: route.Path, Policy: route.Policy}
+	}
 	rateLimitMiddleware := httpmiddleware.NewRateLimitMiddleware(cacheService, httpmiddleware.RateLimitConfig{
-		RequestsPerWindow: 100,
-		WindowDuration:    time.Minute,
+		RequestsPerWindow: cfg.RateLimit.RequestsPerWindow,
+		WindowDuration:    cfg.RateLimit.WindowDuration,
+		FailureMode:       httpmiddleware.ParseFailureMode(cfg.RateLimit.FailureMode),
+		FailureModes:      failureModes,
+		Policies:          rateLimitPolicies,
+		Routes:            rateLimitRoutes,
+		Tiers:             rateLimitTiers,
+	}, appMetrics, dailyCounters)
+
+	rateLimitUseCase := usecase.NewRateLimitUseCase(cacheService, userRepo, auditLogService)
+	rateLimitHandler := handler.NewRateLimitHandler(rateLimitUseCase, rateLimitMiddleware)
+	logLevelHandler := handler.NewLogLevelHandler()
+	configHandler := handler.NewConfigHandler(cfg)
+
+	// Setup readiness probes
+	healthChecker := health.NewChecker(cfg.Timeout.HealthCheck)
+	healthChecker.Register("postgres", db.Ping)
+	healthChecker.Register("redis", redisClient.Ping)
+	healthChecker.Register("s3", s3Client.Ping)
+	healthHandler := handler.NewHealthHandler(healthChecker, version)
+	jwksHandler := handler.NewJWKSHandler(tokenService)
+	quotaMiddleware := httpmiddleware.NewQuotaMiddleware(quotaService)
+	concurrencyMiddleware := httpmiddleware.NewConcurrencyLimitMiddleware(httpmiddleware.ConcurrencyLimitConfig{
+		MaxGlobal:    cfg.Concurrency.MaxGlobal,
+		MaxPerClient: cfg.Concurrency.MaxPerClient,
+		RetryAfter:   cfg.Concurrency.RetryAfter,
+	})
+
+	compressionMiddleware := httpmiddleware.NewCompressionMiddleware(httpmiddleware.CompressionConfig{
+		Level:   cfg.Compression.Level,
+		MinSize: cfg.Compression.MinSize,
+	})
+	if !cfg.Compression.Enabled {
+		compressionMiddleware = nil
+	}
+
+	routeTimeouts := make(map[string]time.Duration)
+	for _, version := range router.APIVersions() {
+		routeTimeouts["POST /api/"+version+"/documents/upload"] = cfg.Timeout.Upload
+		routeTimeouts["POST /api/"+version+"/users/avatar"] = cfg.Timeout.Upload
+		// Exports stream large responses, as long as uploads
+		routeTimeouts["GET /api/"+version+"/users/export"] = cfg.Timeout.Upload
+		routeTimeouts["POST /api/"+version+"/documents/download-zip"] = cfg.Timeout.Upload
+		// Event streams stay open until the client disconnects
+		routeTimeouts["GET /api/"+version+"/events"] = 0
+	}
+	timeoutMiddleware := httpmiddleware.NewTimeoutMiddleware(httpmiddleware.TimeoutConfig{
+		Default: cfg.Timeout.Request,
+		Routes:  routeTimeouts,
 	})
 
+	metricsMiddleware := httpmiddleware.NewMetricsMiddleware(appMetrics)
+
+	// Setup admin network restriction (disabled unless networks or a trusted header are configured)
+	networkRestrictionMiddleware, err := httpmiddleware.NewNetworkRestrictionMiddleware(httpmiddleware.NetworkRestrictionConfig{
+		AllowedCIDRs:       cfg.AdminAccess.AllowedCIDRs,
+		TrustedHeader:      cfg.AdminAccess.TrustedHeader,
+		TrustedHeaderValue: cfg.AdminAccess.TrustedHeaderValue,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure admin network restriction")
+	}
+
 	// Setup other middleware
-	authMiddleware := httpmiddleware.NewAuthMiddleware(tokenService)
-	roleMiddleware := httpmiddleware.NewRoleMiddleware()
+	authMiddleware := httpmiddleware.NewAuthMiddleware(tokenService, authenticateAPIKeyUseCase, tokenDenylist, cfg.AuthCookies.Enabled)
+	roleMiddleware := httpmiddleware.NewRoleMiddleware(policyService)
 
 	// Setup logger middleware
 	loggerMiddleware := func() gin.HandlerFunc {
-		return httpmiddleware.LoggerMiddleware(logger)
+		return httpmiddleware.LoggerMiddleware(logger, httpmiddleware.LogSampling{
+			Routes: cfg.Log.SampledRoutes,
+			Rate:   cfg.Log.SampleRate,
+		})
+	}
+
+	// Setup error middleware
+	errorMiddleware := func() gin.HandlerFunc {
+		return httpmiddleware.ErrorMiddleware(reporter, translator)
+	}
+
+	// Setup recovery middleware
+	recoveryMiddleware := func() gin.HandlerFunc {
+		return httpmiddleware.RecoveryMiddleware(reporter, translator)
+	}
+
+	// Setup CORS middleware
+	corsMiddleware := func() gin.HandlerFunc {
+		return httpmiddleware.CORSMiddleware(httpmiddleware.CORSConfig{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+			AllowAll:         cfg.CORS.AllowAll,
+		})
 	}
 
 	// Setup router
@@ -191,28 +815,207 @@ func main() {
 		userHandler,
 		documentHandler,
 		avatarHandler,
+		apiKeyHandler,
+		sessionHandler,
+		emailChangeHandler,
+		userProviderHandler,
+		twoFactorHandler,
+		webhookHandler,
+		securityEventHandler,
+		dashboardHandler,
+		invitationHandler,
+		roleHandler,
+		auditLogHandler,
+		userMetadataHandler,
+		activityHandler,
+		userSettingsHandler,
+		quotaHandler,
+		rateLimitHandler,
+		logLevelHandler,
+		configHandler,
+		jobHandler,
+		healthHandler,
+		jwksHandler,
+		eventHandler,
+		staticHandler,
 		authMiddleware,
 		roleMiddleware,
 		rateLimitMiddleware,
+		quotaMiddleware,
+		concurrencyMiddleware,
+		compressionMiddleware,
+		timeoutMiddleware,
+		metricsMiddleware,
+		networkRestrictionMiddleware,
 		loggerMiddleware,
+		errorMiddleware,
+		recoveryMiddleware,
+		corsMiddleware,
+		grpcGateway,
 	)
 
+	// Only honour X-Forwarded-For from known proxies, so clients can't spoof their IP
+	if len(cfg.Server.TrustedProxies) > 0 {
+		if err := router.GetEngine().SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			logger.WithError(err).Fatal("Failed to configure trusted proxies")
+		}
+	}
+
+	// Uploads are read and answered within their own request deadline, so the server
+	// timeouts must not cut them off first
+	readTimeout := max(cfg.Server.ReadTimeout, cfg.Timeout.Upload+5*time.Second)
+	writeTimeout := max(cfg.Server.WriteTimeout, cfg.Timeout.Upload+5*time.Second)
+
 	// Create HTTP server
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
-		Handler:      router.GetEngine(),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	// Start server in a goroutine
-	go func() {
-		logger.WithField("port", cfg.Server.Port).Info("Starting HTTP server")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.WithError(err).Fatal("Failed to start server")
+		Handler:        router.Handler(),
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+		IdleTimeout:    cfg.Server.IdleTimeout,
+		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
+	}
+
+	// Serve HTTPS directly when certificates are configured
+	var serverTLS *httpserver.TLS
+	if cfg.TLS.Enabled() {
+		serverTLS, err = httpserver.NewTLS(httpserver.TLSConfig{
+			CertFile:         cfg.TLS.CertFile,
+			KeyFile:          cfg.TLS.KeyFile,
+			AutocertDomains:  cfg.TLS.AutocertDomains,
+			AutocertEmail:    cfg.TLS.AutocertEmail,
+			AutocertCacheDir: cfg.TLS.AutocertCacheDir,
+			HTTPSPort:        cfg.Server.Port,
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to configure TLS")
 		}
-	}()
+		server.TLSConfig = serverTLS.Config()
+	}
+
+	// Flush the latest usage counters into Postgres, after the scheduler has stopped
+	shutdownManager.Register("quota rollup", quotaService.Rollup)
+
+	// Run recurring maintenance on the instance elected leader
+	if cfg.Scheduler.Enabled {
+		scheduler := service.NewScheduler(redisClient, cfg.Scheduler.LeaderTTL, func(task string, err error, duration time.Duration) {
+			appMetrics.ObserveScheduledTask(task, err != nil, duration)
+		})
+		scheduleMaintenance(scheduler, cfg, maintenanceUseCases{
+			tokenCleanup:   usecase.NewTokenCleanupUseCase(tokenRepo),
+			actionTokens:   actionTokenService,
+			trashPurge:     usecase.NewTrashPurgeUseCase(userRepo, documentRepo),
+			storageGC:      usecase.NewStorageGCUseCase(userRepo, documentRepo, s3Client),
+			dataExports:    dataExportUseCase,
+			webhooks:       webhookUseCase,
+			securityEvents: securityEventUseCase,
+			auditLog:       auditLogUseCase,
+			activity:       activityUseCase,
+			quota:          quotaService,
+		}, appMetrics)
+		appMetrics.RegisterSchedulerLeader(scheduler.IsLeader)
+
+		schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+		schedulerDone := make(chan struct{})
+		go func() {
+			defer close(schedulerDone)
+			scheduler.Run(schedulerCtx)
+		}()
+		shutdownManager.Register("scheduler", func(ctx context.Context) error {
+			stopScheduler()
+			select {
+			case <-schedulerDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	// Run background jobs; on shutdown, wait for the running ones to finish
+	if cfg.Jobs.Workers > 0 {
+		jobsCtx, stopJobs := context.WithCancel(context.Background())
+		jobsDone := make(chan struct{})
+		go func() {
+			defer close(jobsDone)
+			jobQueue.Run(jobsCtx)
+		}()
+		shutdownManager.Register("job workers", func(ctx context.Context) error {
+			stopJobs()
+			select {
+			case <-jobsDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	// Open the TCP port, unix socket and systemd-activated sockets
+	listenerConfig := httpserver.ListenerConfig{
+		SocketPath:        cfg.Server.SocketPath,
+		SocketMode:        cfg.Server.SocketMode,
+		SystemdActivation: cfg.Server.SystemdActivation,
+	}
+	if cfg.Server.TCPEnabled {
+		listenerConfig.Port = cfg.Server.Port
+	}
+	listeners, err := httpserver.Listen(listenerConfig)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to start server")
+	}
+
+	// Serve every listener in its own goroutine
+	for _, listener := range listeners {
+		go func(listener httpserver.Listener) {
+			var err error
+			if serverTLS != nil {
+				logger.WithField("listener", listener.Description).Info("Starting HTTPS server")
+				err = server.ServeTLS(listener, "", "")
+			} else {
+				logger.WithField("listener", listener.Description).Info("Starting HTTP server")
+				err = server.Serve(listener)
+			}
+			if err != nil && err != http.ErrServerClosed {
+				logger.WithError(err).Fatal("Failed to start server")
+			}
+		}(listener)
+	}
+
+	// Redirect plain HTTP to HTTPS, answering ACME challenges on the way
+	if serverTLS != nil && cfg.TLS.RedirectPort != "" {
+		redirectServer := &http.Server{
+			Addr:         fmt.Sprintf(":%s", cfg.TLS.RedirectPort),
+			Handler:      serverTLS.RedirectHandler(),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		go func() {
+			logger.WithField("port", cfg.TLS.RedirectPort).Info("Starting HTTP redirect server")
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.WithError(err).Fatal("Failed to start HTTP redirect server")
+			}
+		}()
+		shutdownManager.Register("http redirect server", redirectServer.Shutdown)
+	}
+
+	// Serve gRPC on its own port
+	if grpcServer != nil {
+		grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to start gRPC server")
+		}
+		go func() {
+			logger.WithField("port", cfg.GRPC.Port).Info("Starting gRPC server")
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				logger.WithError(err).Fatal("Failed to start gRPC server")
+			}
+		}()
+		shutdownManager.Register("grpc server", grpcServer.Shutdown)
+	}
+
+	// Registered last so in-flight requests finish before the services they use are closed
+	shutdownManager.Register("http server", server.Shutdown)
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
@@ -220,44 +1023,333 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	shutdownManager.Shutdown()
+	logger.Info("Server shutdown completed")
+}
+
+// openDatabase connects to Postgres and migrates the schema
+func openDatabase(cfg *config.Config) (*postgres.Database, error) {
+	return postgres.NewDatabase(cfg.Database.DSN, postgres.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	}, cfg.Database.SlowQueryThreshold)
+}
+
+// newPasswordService creates the password service with the configured hashing
+func newPasswordService(cfg *config.Config) service.PasswordService {
+	return service.NewPasswordServiceWithConfig(service.PasswordHashConfig{
+		Algorithm:         cfg.Password.HashAlgorithm,
+		BcryptCost:        cfg.Password.BcryptCost,
+		Argon2Memory:      uint32(cfg.Password.Argon2Memory),
+		Argon2Iterations:  uint32(cfg.Password.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Password.Argon2Parallelism),
+	})
+}
+
+// newTokenService creates the token service, signing with the asymmetric key when one is
+// configured
+func newTokenService(cfg *config.Config, onPreviousKey service.PreviousKeyObserver) (service.TokenService, error) {
+	serviceConfig := service.TokenServiceConfig{
+		Secret:          cfg.JWT.Secret,
+		PreviousSecrets: cfg.JWT.PreviousSecrets,
+		AccessExpiry:    cfg.JWT.AccessExpiry,
+		RefreshExpiry:   cfg.JWT.RefreshExpiry,
+		Issuer:          cfg.JWT.Issuer,
+		Audience:        cfg.JWT.Audience,
+		OnPreviousKey:   onPreviousKey,
+	}
+
+	if cfg.JWT.SigningKeyFile != "" {
+		key, err := loadJWTKey(cfg.JWT.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Private == nil {
+			return nil, fmt.Errorf("JWT signing key %s is a public key", cfg.JWT.SigningKeyFile)
+		}
+		serviceConfig.SigningKey = key
+	}
+
+	for _, path := range cfg.JWT.PreviousKeyFiles {
+		key, err := loadJWTKey(path)
+		if err != nil {
+			return nil, err
+		}
+		serviceConfig.PreviousKeys = append(serviceConfig.PreviousKeys, key)
+	}
 
-	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	return service.NewTokenServiceWithConfig(serviceConfig), nil
+}
 
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
-	} else {
-		logger.Info("Server shutdown completed")
+// loadJWTKey reads a PEM key file
+func loadJWTKey(path string) (*service.AsymmetricKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %w", err)
 	}
+	key, err := service.ParseAsymmetricKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT key %s: %w", path, err)
+	}
+	return key, nil
 }
 
-// setupLogger configures the application logger
-func setupLogger(cfg *config.Config) *logrus.Logger {
-	logger := logrus.New()
+// newOAuthProviders registers the configured OAuth providers. A new provider implements
+// oauth.Provider and is registered here.
+func newOAuthProviders(cfg *config.Config) (*oauth.Registry, error) {
+	registry := oauth.NewRegistry()
 
-	// Set log level
-	if cfg.IsDevelopment() {
-		logger.SetLevel(logrus.DebugLevel)
-		logger.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp: true,
-			ForceColors:   true,
+	if cfg.Google.Enabled() {
+		google := oauth.NewGoogleProvider(cfg.Google.ClientID, cfg.Google.ClientSecret, cfg.Google.RedirectURL, cfg.Google.MobileClientIDs)
+		if err := registry.Register(google); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.OIDC.Enabled() {
+		oidc, err := oauth.NewOIDCProvider(context.Background(), oauth.OIDCConfig{
+			Name:         cfg.OIDC.Name,
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
 		})
-	} else {
-		logger.SetLevel(logrus.InfoLevel)
-		logger.SetFormatter(&logrus.JSONFormatter{})
-	}
-
-	// Add file output in production
-	if cfg.IsProduction() {
-		file, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err == nil {
-			logger.SetOutput(file)
-		} else {
-			logger.WithError(err).Warn("Failed to create log file, using stdout")
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(oidc); err != nil {
+			return nil, err
 		}
 	}
 
+	return registry, nil
+}
+
+// newEmailService creates the email service with the configured driver and templates. jobQueue
+// may be nil when emails are only sent with SendNow, and settings when no notifications are sent.
+func newEmailService(cfg *config.Config, jobQueue *service.JobQueue, settings repository.UserSettingsRepository) (*service.EmailService, error) {
+	sender, err := email.NewSender(context.Background(), email.Config{
+		Driver:   cfg.Email.Driver,
+		From:     cfg.Email.From,
+		FromName: cfg.Email.FromName,
+		Timeout:  cfg.Email.Timeout,
+		SMTP: email.SMTPConfig{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			TLS:      cfg.Email.SMTPTLS,
+		},
+		SES: email.SESConfig{
+			Region: cfg.Email.SESRegion,
+		},
+		SendGrid: email.SendGridConfig{
+			APIKey: cfg.Email.SendGridAPIKey,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := email.NewTemplates(cfg.Email.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewEmailService(sender, templates, jobQueue, settings, newUnsubscribeTokenService(cfg)), nil
+}
+
+// newSearchIndexer creates the indexer of the external search backend, or returns nil when
+// documents are searched in Postgres
+func newSearchIndexer(cfg *config.Config) service.SearchIndexer {
+	if !cfg.Search.External() {
+		return nil
+	}
+	return search.NewOpenSearch(search.Config{
+		URL:      cfg.Search.OpenSearchURL,
+		Index:    cfg.Search.OpenSearchIndex,
+		Username: cfg.Search.OpenSearchUsername,
+		Password: cfg.Search.OpenSearchPassword,
+		Timeout:  cfg.Search.Timeout,
+	})
+}
+
+// newUnsubscribeTokenService creates the service signing unsubscribe links with the JWT secret,
+// so they keep working during a rotation of the secret
+func newUnsubscribeTokenService(cfg *config.Config) *service.UnsubscribeTokenService {
+	return service.NewUnsubscribeTokenService(cfg.Email.UnsubscribeURL, append([]string{cfg.JWT.Secret}, cfg.JWT.PreviousSecrets...)...)
+}
+
+// maintenanceUseCases are the use cases the scheduled maintenance tasks run
+type maintenanceUseCases struct {
+	tokenCleanup   *usecase.TokenCleanupUseCase
+	actionTokens   *service.ActionTokenService
+	trashPurge     *usecase.TrashPurgeUseCase
+	storageGC      *usecase.StorageGCUseCase
+	dataExports    *usecase.DataExportUseCase
+	webhooks       *usecase.WebhookUseCase
+	securityEvents *usecase.SecurityEventUseCase
+	auditLog       *usecase.AuditLogUseCase
+	activity       *usecase.ActivityUseCase
+	quota          *service.QuotaService
+}
+
+// scheduleMaintenance adds the maintenance tasks to the scheduler; those whose interval is 0 are
+// disabled
+func scheduleMaintenance(scheduler *service.Scheduler, cfg *config.Config, useCases maintenanceUseCases, appMetrics *metrics.Metrics) {
+	// Delete expired and revoked refresh tokens
+	scheduler.Every("token_purge", cfg.TokenCleanup.Interval, func(ctx context.Context) error {
+		deleted, remaining, err := useCases.tokenCleanup.Execute(ctx)
+		if err != nil {
+			return err
+		}
+		appMetrics.TokensCleanedUp(deleted, remaining)
+		logging.FromContext(ctx).WithFields(logrus.Fields{
+			"deleted":   deleted,
+			"remaining": remaining,
+		}).Debug("Cleaned up tokens")
+		return nil
+	})
+
+	// Delete the expired tokens of emailed links, such as password reset links
+	scheduler.Every("action_token_purge", cfg.TokenCleanup.Interval, func(ctx context.Context) error {
+		deleted, err := useCases.actionTokens.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged expired action tokens")
+		return nil
+	})
+
+	// Permanently delete users and documents soft-deleted longer ago than the retention
+	scheduler.Every("trash_purge", cfg.Scheduler.TrashPurgeInterval, func(ctx context.Context) error {
+		users, documents, err := useCases.trashPurge.Execute(ctx, cfg.Scheduler.TrashRetention)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithFields(logrus.Fields{
+			"users":     users,
+			"documents": documents,
+		}).Debug("Purged deleted records")
+		return nil
+	})
+
+	// Delete stored files no record refers to
+	scheduler.Every("storage_gc", cfg.Scheduler.StorageGCInterval, func(ctx context.Context) error {
+		deleted, err := useCases.storageGC.Execute(ctx, cfg.Scheduler.StorageGCMinAge)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithField("deleted", deleted).Debug("Deleted orphaned files")
+		return nil
+	})
+
+	// Delete the user data exports whose download links expired
+	scheduler.Every("data_export_purge", time.Hour, func(ctx context.Context) error {
+		deleted, err := useCases.dataExports.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged expired data exports")
+		return nil
+	})
+
+	// Delete the webhook delivery log past its retention
+	if cfg.Webhooks.DeliveryRetention > 0 {
+		scheduler.Every("webhook_delivery_purge", time.Hour, func(ctx context.Context) error {
+			deleted, err := useCases.webhooks.PurgeDeliveries(ctx, cfg.Webhooks.DeliveryRetention)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged webhook deliveries")
+			return nil
+		})
+	}
+
+	// Delete the security events past their retention
+	if cfg.SecurityEvents.Retention > 0 {
+		scheduler.Every("security_event_purge", time.Hour, func(ctx context.Context) error {
+			deleted, err := useCases.securityEvents.PurgeEvents(ctx, cfg.SecurityEvents.Retention)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged security events")
+			return nil
+		})
+	}
+
+	// Delete the audit log entries past their retention
+	if cfg.AuditLog.Retention > 0 {
+		scheduler.Every("audit_log_purge", time.Hour, func(ctx context.Context) error {
+			deleted, err := useCases.auditLog.Purge(ctx, cfg.AuditLog.Retention)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged audit log")
+			return nil
+		})
+	}
+
+	// Delete the activities past their retention
+	if cfg.Activity.Retention > 0 {
+		scheduler.Every("activity_purge", time.Hour, func(ctx context.Context) error {
+			deleted, err := useCases.activity.Purge(ctx, cfg.Activity.Retention)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged activities")
+			return nil
+		})
+	}
+
+	// Reconcile the usage counters in Redis with their rollups in Postgres
+	scheduler.Every("quota_reconciliation", cfg.Quota.RollupInterval, useCases.quota.Rollup)
+}
+
+// setupLogger configures the application logger, and routes log/slog and the log package into
+// it. An output that can't be opened is left out with a warning.
+func setupLogger(cfg *config.Config) *logrus.Logger {
+	level := logrus.InfoLevel
+	format := logging.FormatJSON
+	if cfg.IsDevelopment() {
+		level = logrus.DebugLevel
+		format = logging.FormatText
+	}
+	if parsed, err := logrus.ParseLevel(cfg.Log.Level); err == nil {
+		level = parsed
+	}
+	if cfg.Log.Format != "" {
+		format = cfg.Log.Format
+	}
+
+	// Production used to log to the file only, so it still does unless outputs are set
+	outputs := cfg.Log.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{logging.OutputStdout}
+		if cfg.IsProduction() {
+			outputs = []string{logging.OutputFile}
+		}
+	}
+
+	logger, err := logging.New(logging.Options{
+		Level:   level,
+		Format:  format,
+		Handler: cfg.Log.Handler,
+		Color:   cfg.IsDevelopment(),
+		Outputs: outputs,
+		File: logging.FileOptions{
+			Path:           cfg.Log.File,
+			MaxSize:        int64(cfg.Log.FileMaxSizeMB) << 20,
+			RotateInterval: cfg.Log.FileRotateInterval,
+			MaxBackups:     cfg.Log.FileMaxBackups,
+		},
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to open log output")
+	}
+
+	slog.SetDefault(slog.New(logging.NewSlogHandler()))
 	return logger
-}
\ No newline at end of file
+}
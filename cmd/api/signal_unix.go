@@ -0,0 +1,39 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// toggleDebugLogging switches the log level to debug on SIGUSR1 and back on the next SIGUSR1,
+// until ctx is cancelled. Module level overrides are left alone.
+func toggleDebugLogging(ctx context.Context, logger *logrus.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	// Restored when the level already was debug or trace before the first signal
+	restore := logrus.InfoLevel
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if level := logging.Level(); level < logrus.DebugLevel {
+				restore = level
+				logging.SetLevel(logrus.DebugLevel)
+			} else {
+				logging.SetLevel(restore)
+			}
+			logger.WithField("level", logging.Level().String()).Warn("Log level changed by SIGUSR1")
+		}
+	}
+}
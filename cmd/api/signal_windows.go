@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// toggleDebugLogging does nothing on Windows, which has no SIGUSR1. Use the admin log level
+// endpoint instead.
+func toggleDebugLogging(ctx context.Context, logger *logrus.Logger) {}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// ActivityResponse represents an activity in a user's feed
+type ActivityResponse struct {
+	ID        string            `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Type      string            `json:"type" example:"document_uploaded"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt string            `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ActivityListResponse represents a page of a user's activity feed
+type ActivityListResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	Total      int64              `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToActivityResponse converts entity.Activity to ActivityResponse
+func ToActivityResponse(activity *entity.Activity) ActivityResponse {
+	return ActivityResponse{
+		ID:        activity.ID,
+		Type:      string(activity.Type),
+		Details:   activity.Details,
+		CreatedAt: activity.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToActivityListResponse converts a page of activities to ActivityListResponse
+func ToActivityListResponse(activities []*entity.Activity, total int64, limit, offset int) ActivityListResponse {
+	responses := make([]ActivityResponse, len(activities))
+	for i, activity := range activities {
+		responses[i] = ToActivityResponse(activity)
+	}
+
+	return ActivityListResponse{
+		Activities: responses,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// CreateAPIKeyRequest represents API key creation request
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" binding:"required,min=1,max=100" example:"CI pipeline"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=3650" example:"90"`
+}
+
+// UpdateAPIKeyRateLimitRequest represents an admin request to change per-key limits
+type UpdateAPIKeyRateLimitRequest struct {
+	RateLimit         int `json:"rate_limit" binding:"required,min=1" example:"5000"`
+	RateWindowSeconds int `json:"rate_window_seconds" binding:"required,min=1" example:"60"`
+}
+
+// APIKeyResponse represents API key response
+type APIKeyResponse struct {
+	ID                string  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name              string  `json:"name" example:"CI pipeline"`
+	Prefix            string  `json:"prefix" example:"gbk_1a2b3c4d"`
+	RateLimit         int     `json:"rate_limit" example:"1000"`
+	RateWindowSeconds int     `json:"rate_window_seconds" example:"60"`
+	ExpiresAt         *string `json:"expires_at" example:"2024-01-01T00:00:00Z"`
+	LastUsedAt        *string `json:"last_used_at" example:"2023-06-01T00:00:00Z"`
+	Revoked           bool    `json:"revoked" example:"false"`
+	CreatedAt         string  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// CreateAPIKeyResponse represents API key creation response.
+// The plaintext key is only returned once.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"gbk_1a2b3c4d..."`
+}
+
+// ToAPIKeyResponse converts entity.APIKey to APIKeyResponse
+func ToAPIKeyResponse(apiKey *entity.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:                apiKey.ID,
+		Name:              apiKey.Name,
+		Prefix:            apiKey.Prefix,
+		RateLimit:         apiKey.RateLimit,
+		RateWindowSeconds: apiKey.RateWindowSeconds,
+		ExpiresAt:         formatOptionalTime(apiKey.ExpiresAt),
+		LastUsedAt:        formatOptionalTime(apiKey.LastUsedAt),
+		Revoked:           apiKey.IsRevoked(),
+		CreatedAt:         apiKey.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToAPIKeyListResponse converts API keys slice to responses
+func ToAPIKeyListResponse(apiKeys []*entity.APIKey) []APIKeyResponse {
+	responses := make([]APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		responses[i] = ToAPIKeyResponse(apiKey)
+	}
+	return responses
+}
+
+// formatOptionalTime formats a nullable timestamp
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
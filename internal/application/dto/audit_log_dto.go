@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// AuditLogFilterRequest represents the filters of the audit log. Empty fields don't filter.
+type AuditLogFilterRequest struct {
+	ActorID    string `form:"actor_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174001"`
+	Action     string `form:"action" example:"role_assigned"`
+	TargetType string `form:"target_type" binding:"omitempty,oneof=user role document" example:"user"`
+	TargetID   string `form:"target_id" example:"123e4567-e89b-12d3-a456-426614174002"`
+	// From and To are RFC 3339 times; From is included and To isn't
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	To   string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00" example:"2024-02-01T00:00:00Z"`
+}
+
+// AuditLogResponse represents an entry of the audit log
+type AuditLogResponse struct {
+	ID         string            `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ActorID    *string           `json:"actor_id" example:"123e4567-e89b-12d3-a456-426614174001"`
+	Action     string            `json:"action" example:"role_assigned"`
+	TargetType string            `json:"target_type,omitempty" example:"user"`
+	TargetID   string            `json:"target_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174002"`
+	IP         string            `json:"ip,omitempty" example:"203.0.113.7"`
+	UserAgent  string            `json:"user_agent,omitempty" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	Details    map[string]string `json:"details,omitempty"`
+	CreatedAt  string            `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// AuditLogListResponse represents a page of the audit log
+type AuditLogListResponse struct {
+	Entries []AuditLogResponse `json:"entries"`
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToAuditLogResponse converts entity.AuditLog to AuditLogResponse
+func ToAuditLogResponse(entry *entity.AuditLog) AuditLogResponse {
+	return AuditLogResponse{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Action:     string(entry.Action),
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		Details:    entry.Details,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToAuditLogListResponse converts a page of audit log entries to AuditLogListResponse
+func ToAuditLogListResponse(entries []*entity.AuditLog, total int64, limit, offset int) AuditLogListResponse {
+	responses := make([]AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = ToAuditLogResponse(entry)
+	}
+
+	return AuditLogListResponse{
+		Entries: responses,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+}
@@ -2,8 +2,8 @@ package dto
 
 import (
 	"fmt"
-	"strings"
 	"gin-boilerplate/internal/domain/entity"
+	"strings"
 )
 
 // RegisterRequest represents user registration request
@@ -11,12 +11,24 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
 	Password string `json:"password" binding:"required,min=8" example:"password123"`
 	Name     string `json:"name" binding:"required,min=2,max=100" example:"John Doe"`
+	// InviteToken is the token of an invitation link, required while registration is invite-only
+	InviteToken string `json:"invite_token,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
 }
 
 // LoginRequest represents user login request
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
 	Password string `json:"password" binding:"required" example:"password123"`
+	// RememberMe keeps the session signed in for JWT_REMEMBER_ME_EXPIRY instead of
+	// JWT_REFRESH_EXPIRY
+	RememberMe bool `json:"remember_me" example:"false"`
+	// NewPassword replaces the password of a user who has to change it, answered with
+	// PASSWORD_CHANGE_REQUIRED without one
+	NewPassword string `json:"new_password,omitempty" binding:"omitempty,min=8" example:"newpassword123"`
+	// ClientIP is filled in by the handler for login throttling
+	ClientIP string `json:"-"`
+	// UserAgent is filled in by the handler to recognize new devices
+	UserAgent string `json:"-"`
 }
 
 // GoogleAuthRequest represents Google OAuth callback request
@@ -25,24 +37,113 @@ type GoogleAuthRequest struct {
 	State string `json:"state" example:"random_state_string"`
 }
 
+// IDTokenRequest represents signing in with an ID token a mobile app got from the provider's
+// native SDK
+type IDTokenRequest struct {
+	IDToken string `json:"id_token" binding:"required" example:"eyJhbGciOiJSUzI1NiIsImtpZCI6Ij..."`
+}
+
 // RefreshTokenRequest represents refresh token request
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// ForgotPasswordRequest represents a request for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest represents setting a new password with a reset link's token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Password string `json:"password" binding:"required,min=8" example:"newpassword123"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// VerifyEmailRequest represents verifying an email with a verification link's token
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
 }
 
-// UpdateProfileRequest represents profile update request
+// ResendVerificationRequest represents a request for a new email verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// UpdateProfileRequest represents profile update request. It replaces the name and avatar, so
+// leaving the avatar out removes it; PatchProfileRequest changes only the fields sent.
 type UpdateProfileRequest struct {
 	Name   string  `json:"name" binding:"omitempty,min=2,max=100" example:"John Doe"`
 	Avatar *string `json:"avatar" example:"https://example.com/avatar.jpg"`
+	// Locale is a BCP 47 language tag for translated API messages; an empty string clears it
+	Locale *string `json:"locale" binding:"omitempty,bcp47_language_tag" example:"id"`
+	// Metadata sets the custom profile fields defined by admins, and removes those set to null.
+	// The other fields are kept, and leaving it out changes none.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// PatchProfileRequest represents a partial profile update, which changes only the fields sent
+// and keeps the others. With UpdateMask, it changes only the fields the mask names instead, and
+// those left out of the body are cleared, which is how the avatar is removed.
+type PatchProfileRequest struct {
+	Name   *string `json:"name" binding:"omitempty,min=2,max=100" example:"John Doe"`
+	Avatar *string `json:"avatar" example:"https://example.com/avatar.jpg"`
+	// Locale is a BCP 47 language tag for translated API messages; an empty string clears it
+	Locale *string `json:"locale" binding:"omitempty,bcp47_language_tag" example:"id"`
+	// Metadata sets the custom profile fields defined by admins, and removes those set to null.
+	// When the mask names it, the fields left out are removed too.
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// UpdateMask names the fields to change
+	UpdateMask []string `json:"update_mask,omitempty" binding:"omitempty,dive,oneof=name avatar locale metadata" example:"name,avatar"`
+}
+
+// ChangePasswordRequest represents changing the password of the current user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123"`
+	NewPassword     string `json:"new_password" binding:"required,min=8" example:"newpassword123"`
+	// ClientIP and UserAgent are filled in by the handler for the new session and security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// DeleteAccountRequest represents deleting the account of the current user. Users with a password
+// confirm with it, and the others by typing their email. Users with two-factor authentication
+// also send the code texted by a first request without one, or a recovery code.
+type DeleteAccountRequest struct {
+	Password     string `json:"password,omitempty" example:"password123"`
+	Email        string `json:"email,omitempty" example:"user@example.com"`
+	Code         string `json:"code,omitempty" binding:"omitempty,numeric,len=6" example:"123456"`
+	RecoveryCode string `json:"recovery_code,omitempty" example:"7k3np-q9x2m"`
 }
 
-// AuthResponse represents authentication response with tokens
+// ChangeEmailRequest represents changing the email of the current user
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"new@example.com"`
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+// EmailChangeTokenRequest represents confirming or reverting an email change with the token of
+// its link
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// AuthResponse represents authentication response with tokens. A registration that has to
+// verify its email first gets no tokens.
 type AuthResponse struct {
 	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken string       `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	TokenType    string       `json:"token_type" example:"Bearer"`
-	ExpiresIn    int64        `json:"expires_in" example:"900"`
+	AccessToken  string       `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string       `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType    string       `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresIn    int64        `json:"expires_in,omitempty" example:"900"`
 }
 
 // UserResponse represents user response
@@ -54,16 +155,81 @@ type UserResponse struct {
 	Provider      string  `json:"provider" example:"LOCAL"`
 	Avatar        *string `json:"avatar" example:"https://example.com/avatar.jpg"`
 	EmailVerified bool    `json:"email_verified" example:"true"`
+	Locale        string  `json:"locale,omitempty" example:"id"`
+	Phone         *string `json:"phone,omitempty" example:"+6281234567890"`
+	PhoneVerified bool    `json:"phone_verified" example:"true"`
+	TwoFactor     string  `json:"two_factor,omitempty" example:"sms"` // second factor of logins, empty when there is none
 	CreatedAt     string  `json:"created_at" example:"2023-01-01T00:00:00Z"`
 	UpdatedAt     string  `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+
+	// PasswordChangeRequired is set until the user chooses a new password on its next login
+	PasswordChangeRequired bool `json:"password_change_required,omitempty" example:"false"`
+	// Metadata holds the custom profile fields defined by admins
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// RateLimitTier decides the user's rate limit: free, pro or internal
+	RateLimitTier string `json:"rate_limit_tier,omitempty" example:"free"`
+	// AvatarPending is set while an uploaded avatar awaits moderation, Avatar being the one shown
+	AvatarPending bool `json:"avatar_pending,omitempty" example:"false"`
 }
 
 // UsersListResponse represents users list response
 type UsersListResponse struct {
-	Users []UserResponse `json:"users"`
-	Total int64          `json:"total"`
-	Limit int            `json:"limit"`
-	Offset int           `json:"offset"`
+	Users  []UserResponse `json:"users"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// UserFilterRequest represents the filters and sort order of the admin user list. Empty
+// fields don't filter.
+type UserFilterRequest struct {
+	// Search matches users whose email or name contains it, ignoring case
+	Search        string `form:"search" example:"jane"`
+	Role          string `form:"role" example:"ADMIN"`
+	Provider      string `form:"provider" example:"GOOGLE"`
+	EmailVerified *bool  `form:"email_verified" example:"true"`
+	// CreatedFrom and CreatedTo are UTC days, both included
+	CreatedFrom string `form:"created_from" binding:"omitempty,datetime=2006-01-02" example:"2024-01-01"`
+	CreatedTo   string `form:"created_to" binding:"omitempty,datetime=2006-01-02" example:"2024-01-31"`
+	// Sort is the field to sort by, created_at by default
+	Sort  string `form:"sort" binding:"omitempty,oneof=created_at email name" example:"email"`
+	Order string `form:"order" binding:"omitempty,oneof=asc desc" example:"asc"`
+	// Deleted lists the users in the trash instead of the others
+	Deleted bool `form:"deleted" example:"false"`
+}
+
+// UserExportRequest represents the file format and columns of a user export, which takes the
+// filters of the user list
+type UserExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv xlsx" example:"csv"`
+	// Columns is a comma-separated list of columns, in order; empty exports the default columns
+	Columns string `form:"columns" example:"email,name,role"`
+}
+
+// CreateUserRequest represents an admin creating a user, without the checks of public
+// registration
+type CreateUserRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+	Name  string `json:"name" binding:"required,min=2,max=100" example:"John Doe"`
+	// Role is a built-in or custom role, USER by default
+	Role string `json:"role,omitempty" example:"SUPPORT"`
+	// Password is a temporary password for the user. Without one a random password is
+	// generated, which the user has to change on its first login.
+	Password string `json:"password,omitempty" binding:"omitempty,min=8" example:"password123"`
+	// PasswordChangeRequired makes the user change the password on its first login
+	PasswordChangeRequired bool `json:"password_change_required" example:"true"`
+	// EmailVerified skips verifying the email, e.g. for addresses of the organization
+	EmailVerified bool `json:"email_verified" example:"true"`
+}
+
+// CreateUserResponse represents a user created by an admin
+type CreateUserResponse struct {
+	User UserResponse `json:"user"`
+	// TemporaryPassword is the generated password, shown only once, or empty when the admin
+	// chose one
+	TemporaryPassword string `json:"temporary_password,omitempty" example:"7hKm2xQpa9RtzW4c"`
 }
 
 // ErrorResponse represents error response
@@ -73,8 +239,9 @@ type ErrorResponse struct {
 
 // ErrorDetail represents error detail
 type ErrorDetail struct {
-	Code    string `json:"code" example:"INVALID_CREDENTIALS"`
-	Message string `json:"message" example:"Email or password is incorrect"`
+	Code      string `json:"code" example:"INVALID_CREDENTIALS"`
+	Message   string `json:"message" example:"Email or password is incorrect"`
+	RequestID string `json:"request_id,omitempty" example:"20230101000000-a1b2c3d4"`
 }
 
 // SuccessResponse represents success response
@@ -89,6 +256,15 @@ type PaginationRequest struct {
 	Offset int `json:"offset" form:"offset" example:"0"`
 }
 
+// PaginationLinks are the URLs of the neighbouring pages of a list response. Links that don't
+// apply, such as prev on the first page, are left empty.
+type PaginationLinks struct {
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last"`
+}
+
 // ToUserResponse converts entity.User to UserResponse
 func ToUserResponse(user *entity.User) UserResponse {
 	var avatarURL *string
@@ -111,8 +287,17 @@ func ToUserResponse(user *entity.User) UserResponse {
 		Provider:      string(user.Provider),
 		Avatar:        avatarURL,
 		EmailVerified: user.EmailVerified,
+		Locale:        user.Locale,
+		Phone:         user.Phone,
+		PhoneVerified: user.PhoneVerified,
+		TwoFactor:     string(user.TwoFactor),
 		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		PasswordChangeRequired: user.PasswordChangeRequired,
+		Metadata:               user.Metadata,
+		RateLimitTier:          string(user.RateLimitTier),
+		AvatarPending:          user.PendingAvatar != nil,
 	}
 }
 
@@ -146,4 +331,4 @@ func ToAuthResponse(user *entity.User, accessToken, refreshToken string, expires
 		TokenType:    "Bearer",
 		ExpiresIn:    expiresIn,
 	}
-}
\ No newline at end of file
+}
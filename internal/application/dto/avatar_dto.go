@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"fmt"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// PendingAvatarResponse represents an uploaded avatar awaiting moderation
+type PendingAvatarResponse struct {
+	UserID string `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Email  string `json:"email" example:"user@example.com"`
+	Name   string `json:"name" example:"John Doe"`
+	// Avatar is the avatar shown until the pending one is approved
+	Avatar *string `json:"avatar" example:"/api/v1/users/avatar/123e4567-e89b-12d3-a456-426614174000"`
+	// PendingAvatarURL shows the pending avatar to moderators
+	PendingAvatarURL string `json:"pending_avatar_url" example:"/api/v1/admin/avatars/123e4567-e89b-12d3-a456-426614174000"`
+	UploadedAt       string `json:"uploaded_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// PendingAvatarsListResponse represents a page of the avatars awaiting moderation
+type PendingAvatarsListResponse struct {
+	Avatars []PendingAvatarResponse `json:"avatars"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// RejectAvatarRequest represents rejecting an avatar awaiting moderation. The reason is sent to
+// the user with the avatar.rejected event.
+type RejectAvatarRequest struct {
+	Reason string `json:"reason" binding:"max=500" example:"Avatars must not contain text"`
+}
+
+// ToPendingAvatarResponse converts a user with an avatar awaiting moderation to
+// PendingAvatarResponse
+func ToPendingAvatarResponse(user *entity.User) PendingAvatarResponse {
+	response := PendingAvatarResponse{
+		UserID:           user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		Avatar:           ToUserResponse(user).Avatar,
+		PendingAvatarURL: fmt.Sprintf("/api/v1/admin/avatars/%s", user.ID),
+	}
+	if user.PendingAvatarAt != nil {
+		response.UploadedAt = user.PendingAvatarAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
+
+// ToPendingAvatarsListResponse converts a page of users with avatars awaiting moderation to
+// PendingAvatarsListResponse
+func ToPendingAvatarsListResponse(users []*entity.User, total int64, limit, offset int) PendingAvatarsListResponse {
+	responses := make([]PendingAvatarResponse, len(users))
+	for i, user := range users {
+		responses[i] = ToPendingAvatarResponse(user)
+	}
+
+	return PendingAvatarsListResponse{
+		Avatars: responses,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+}
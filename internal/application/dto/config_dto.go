@@ -0,0 +1,15 @@
+package dto
+
+// ConfigSettingResponse represents the effective value of one setting
+type ConfigSettingResponse struct {
+	Name  string `json:"name" example:"DB_HOST"`
+	Value string `json:"value" example:"localhost"`
+	// Source is "env" (including .env files), "secrets", "aws", "file" or "default"
+	Source string `json:"source" example:"env"`
+}
+
+// ConfigResponse represents the configuration the server was started with
+type ConfigResponse struct {
+	Environment string                  `json:"environment" example:"production"`
+	Settings    []ConfigSettingResponse `json:"settings"`
+}
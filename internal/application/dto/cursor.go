@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// CursorPaginationRequest represents a keyset pagination request. An empty cursor starts at the
+// newest record.
+type CursorPaginationRequest struct {
+	Limit  int    `json:"limit" form:"limit" example:"10"`
+	Cursor string `json:"cursor" form:"cursor" example:"MjAyNC0wMS0wMVQwMDowMDowMFp8MTIz"`
+}
+
+// UsersCursorListResponse represents a keyset page of users
+type UsersCursorListResponse struct {
+	Users []UserResponse `json:"users"`
+	Limit int            `json:"limit"`
+	// NextCursor requests the next page, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EncodeCursor returns the opaque token clients send back to get the page after cursor
+func EncodeCursor(cursor repository.Cursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token made by EncodeCursor. An empty token is the first page, a nil
+// cursor.
+func DecodeCursor(token string) (*repository.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor.Wrap(err)
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor.Wrap(err)
+	}
+
+	cursor := repository.CursorOf(parsed, id)
+	return &cursor, nil
+}
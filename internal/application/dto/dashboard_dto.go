@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// DailyCountResponse represents the count of a UTC day
+type DailyCountResponse struct {
+	Date  string `json:"date" example:"2023-01-01"`
+	Count int64  `json:"count" example:"42"`
+}
+
+// DashboardActivityResponse represents the daily signups, logins and document uploads of the
+// last days, oldest first
+type DashboardActivityResponse struct {
+	Days        int                  `json:"days" example:"30"`
+	Signups     []DailyCountResponse `json:"signups"`
+	Logins      []DailyCountResponse `json:"logins"`
+	Uploads     []DailyCountResponse `json:"uploads"`
+	GeneratedAt string               `json:"generated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// StorageConsumerResponse represents the storage taken by a user's documents
+type StorageConsumerResponse struct {
+	UserID    string `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Email     string `json:"email,omitempty" example:"user@example.com"`
+	Name      string `json:"name,omitempty" example:"John Doe"`
+	Documents int64  `json:"documents" example:"120"`
+	Bytes     int64  `json:"bytes" example:"524288000"`
+}
+
+// DashboardStorageResponse represents the users whose documents take the most storage
+type DashboardStorageResponse struct {
+	Consumers   []StorageConsumerResponse `json:"consumers"`
+	GeneratedAt string                    `json:"generated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// DashboardRateLimitResponse represents the daily rate limit rejections of the last days,
+// oldest first
+type DashboardRateLimitResponse struct {
+	Days        int                  `json:"days" example:"30"`
+	Rejections  []DailyCountResponse `json:"rejections"`
+	GeneratedAt string               `json:"generated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// DashboardJobsResponse represents the depth of the background job queue
+type DashboardJobsResponse struct {
+	Pending     int64  `json:"pending" example:"12"`
+	Running     int64  `json:"running" example:"2"`
+	Succeeded   int64  `json:"succeeded" example:"5310"`
+	Dead        int64  `json:"dead" example:"1"`
+	GeneratedAt string `json:"generated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// WebhookFailureResponse represents the delivery attempts of a failing webhook
+type WebhookFailureResponse struct {
+	WebhookID   string  `json:"webhook_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserID      string  `json:"user_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174001"`
+	URL         string  `json:"url,omitempty" example:"https://example.com/webhooks"`
+	Attempts    int64   `json:"attempts" example:"40"`
+	Failed      int64   `json:"failed" example:"10"`
+	FailureRate float64 `json:"failure_rate" example:"0.25"`
+}
+
+// DashboardWebhooksResponse represents the webhook delivery failures of the last hours
+type DashboardWebhooksResponse struct {
+	Hours       int                      `json:"hours" example:"24"`
+	Attempts    int64                    `json:"attempts" example:"1200"`
+	Failed      int64                    `json:"failed" example:"36"`
+	FailureRate float64                  `json:"failure_rate" example:"0.03"`
+	Failing     []WebhookFailureResponse `json:"failing"`
+	GeneratedAt string                   `json:"generated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ToDailyCountsResponse converts the daily counts since a day to one count per day up to today,
+// oldest first, filling in the days without any
+func ToDailyCountsResponse(counts []repository.DailyCount, since time.Time) []DailyCountResponse {
+	byDay := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		byDay[count.Day.UTC().Format(time.DateOnly)] += count.Count
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	responses := []DailyCountResponse{}
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		responses = append(responses, DailyCountResponse{Date: date, Count: byDay[date]})
+	}
+	return responses
+}
+
+// FailureRate returns the share of failed attempts, or 0 without attempts
+func FailureRate(attempts, failed int64) float64 {
+	if attempts == 0 {
+		return 0
+	}
+	return float64(failed) / float64(attempts)
+}
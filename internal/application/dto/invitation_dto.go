@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// Invitation statuses
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusExpired  = "expired"
+)
+
+// CreateInvitationRequest represents inviting an email address to register
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+	// Role is the role of the user registering with the invitation, USER by default
+	Role string `json:"role" binding:"omitempty,oneof=USER MODERATOR ADMIN" example:"USER"`
+}
+
+// InvitationResponse represents an invitation to register
+type InvitationResponse struct {
+	ID         string  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Email      string  `json:"email" example:"user@example.com"`
+	Role       string  `json:"role" example:"USER"`
+	Status     string  `json:"status" example:"pending"`
+	InvitedBy  string  `json:"invited_by" example:"123e4567-e89b-12d3-a456-426614174001"`
+	AcceptedBy *string `json:"accepted_by,omitempty" example:"123e4567-e89b-12d3-a456-426614174002"`
+	AcceptedAt *string `json:"accepted_at,omitempty" example:"2023-01-02T00:00:00Z"`
+	ExpiresAt  string  `json:"expires_at" example:"2023-01-08T00:00:00Z"`
+	CreatedAt  string  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	// InviteURL is the link emailed to the invitee, only returned when the invitation is created
+	InviteURL string `json:"invite_url,omitempty" example:"http://localhost:3000/accept-invite?token=9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+// InvitationsListResponse represents a page of invitations
+type InvitationsListResponse struct {
+	Invitations []InvitationResponse `json:"invitations"`
+	Total       int64                `json:"total"`
+	Limit       int                  `json:"limit"`
+	Offset      int                  `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToInvitationResponse converts entity.Invitation to InvitationResponse
+func ToInvitationResponse(invitation *entity.Invitation) InvitationResponse {
+	response := InvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Role:       string(invitation.Role),
+		Status:     InvitationStatusPending,
+		InvitedBy:  invitation.InvitedBy,
+		AcceptedBy: invitation.AcceptedBy,
+		ExpiresAt:  invitation.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:  invitation.CreatedAt.Format(time.RFC3339),
+	}
+	switch {
+	case invitation.IsAccepted():
+		response.Status = InvitationStatusAccepted
+		acceptedAt := invitation.AcceptedAt.Format(time.RFC3339)
+		response.AcceptedAt = &acceptedAt
+	case invitation.IsExpired():
+		response.Status = InvitationStatusExpired
+	}
+	return response
+}
+
+// ToInvitationsListResponse converts a page of invitations to InvitationsListResponse
+func ToInvitationsListResponse(invitations []*entity.Invitation, total int64, limit, offset int) InvitationsListResponse {
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = ToInvitationResponse(invitation)
+	}
+
+	return InvitationsListResponse{
+		Invitations: responses,
+		Total:       total,
+		Limit:       limit,
+		Offset:      offset,
+	}
+}
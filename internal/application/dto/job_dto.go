@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// JobResponse represents a background job
+type JobResponse struct {
+	ID          string          `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Type        string          `json:"type" example:"avatar.delete"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status" example:"dead"`
+	Attempts    int             `json:"attempts" example:"5"`
+	MaxAttempts int             `json:"max_attempts" example:"5"`
+	RunAt       string          `json:"run_at" example:"2023-01-01T00:00:00Z"`
+	LastError   string          `json:"last_error,omitempty" example:"failed to delete avatar: connection refused"`
+	FinishedAt  *string         `json:"finished_at" example:"2023-01-01T00:10:00Z"`
+	CreatedAt   string          `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// JobsListResponse represents a page of background jobs
+type JobsListResponse struct {
+	Jobs   []JobResponse `json:"jobs"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToJobResponse converts entity.Job to JobResponse
+func ToJobResponse(job *entity.Job) JobResponse {
+	return JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     json.RawMessage(job.Payload),
+		Status:      string(job.Status),
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt.Format(time.RFC3339),
+		LastError:   job.LastError,
+		FinishedAt:  formatOptionalTime(job.FinishedAt),
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToJobsListResponse converts a page of jobs to JobsListResponse
+func ToJobsListResponse(jobs []*entity.Job, total int64, limit, offset int) JobsListResponse {
+	responses := make([]JobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = ToJobResponse(job)
+	}
+
+	return JobsListResponse{
+		Jobs:   responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
@@ -0,0 +1,15 @@
+package dto
+
+// LogLevelResponse represents the current log levels
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+	// Modules are the overridden module levels
+	Modules map[string]string `json:"modules"`
+}
+
+// SetLogLevelRequest represents an admin request to change the level of the logger, or of one
+// module when Module is set
+type SetLogLevelRequest struct {
+	Level  string `json:"level" binding:"required" example:"debug"`
+	Module string `json:"module,omitempty" example:"auth"`
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// SetQuotaOverrideRequest represents an admin request to override a user's quota
+type SetQuotaOverrideRequest struct {
+	Limit int64 `json:"limit" binding:"min=0" example:"50000"`
+}
+
+// QuotaStatusResponse represents the usage of one quota metric
+type QuotaStatusResponse struct {
+	Metric     string `json:"metric" example:"requests_daily"`
+	Limit      int64  `json:"limit" example:"10000"`
+	Used       int64  `json:"used" example:"1234"`
+	Period     string `json:"period" example:"2023-01-01"`
+	ResetsAt   string `json:"resets_at" example:"2023-01-02T00:00:00Z"`
+	Overridden bool   `json:"overridden" example:"false"`
+}
+
+// UserQuotaResponse represents all quota metrics of a user
+type UserQuotaResponse struct {
+	UserID string                `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Quotas []QuotaStatusResponse `json:"quotas"`
+}
+
+// QuotaExceededDetail represents the details of an exhausted quota
+type QuotaExceededDetail struct {
+	Metric   string `json:"metric" example:"uploads_monthly"`
+	Limit    int64  `json:"limit" example:"100"`
+	Used     int64  `json:"used" example:"100"`
+	ResetsAt string `json:"resets_at" example:"2023-02-01T00:00:00Z"`
+}
+
+// QuotaExceededResponse represents a quota exceeded error response
+type QuotaExceededResponse struct {
+	Error ErrorDetail         `json:"error"`
+	Quota QuotaExceededDetail `json:"quota"`
+}
+
+// ToQuotaExceededResponse converts a domain quota error to QuotaExceededResponse
+func ToQuotaExceededResponse(err *domain.QuotaExceededError) QuotaExceededResponse {
+	return QuotaExceededResponse{
+		Error: ErrorDetail{
+			Code:    domain.ErrQuotaExceeded.Code,
+			Message: domain.ErrQuotaExceeded.Message,
+		},
+		Quota: QuotaExceededDetail{
+			Metric:   err.Metric,
+			Limit:    err.Limit,
+			Used:     err.Used,
+			ResetsAt: err.ResetsAt.Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+// RateLimitEntryResponse represents the current state of one rate-limit counter
+type RateLimitEntryResponse struct {
+	Key        string `json:"key" example:"login:ip:203.0.113.7"`
+	Count      int64  `json:"count" example:"12"`
+	TTLSeconds int64  `json:"ttl_seconds" example:"42"`
+	ResetsAt   string `json:"resets_at,omitempty" example:"2023-01-01T00:01:00Z"`
+}
+
+// RateLimitListResponse represents a list of rate-limit counters
+type RateLimitListResponse struct {
+	Entries   []RateLimitEntryResponse `json:"entries"`
+	Truncated bool                     `json:"truncated" example:"false"`
+}
+
+// SetRateLimitTierRequest represents an admin request to change the rate limit tier of a user
+type SetRateLimitTierRequest struct {
+	Tier string `json:"tier" binding:"required,oneof=free pro internal" example:"pro"`
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// CreateRoleRequest represents creating a custom role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required" example:"SUPPORT"`
+	Description string   `json:"description" example:"Helps users with their accounts"`
+	Permissions []string `json:"permissions" binding:"required" example:"users:read,users:unlock"`
+}
+
+// UpdateRoleRequest represents replacing the description and permissions of a custom role
+type UpdateRoleRequest struct {
+	Description string   `json:"description" example:"Helps users with their accounts"`
+	Permissions []string `json:"permissions" binding:"required" example:"users:read,users:unlock"`
+}
+
+// AssignRoleRequest represents assigning a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"SUPPORT"`
+}
+
+// RoleResponse represents a role and its permissions
+type RoleResponse struct {
+	Name        string   `json:"name" example:"SUPPORT"`
+	Description string   `json:"description" example:"Helps users with their accounts"`
+	Permissions []string `json:"permissions" example:"users:read,users:unlock"`
+	// Builtin is set for the USER, MODERATOR and ADMIN roles, which can't be changed
+	Builtin   bool    `json:"builtin" example:"false"`
+	CreatedAt *string `json:"created_at,omitempty" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt *string `json:"updated_at,omitempty" example:"2023-01-01T00:00:00Z"`
+}
+
+// RolesListResponse represents the built-in and custom roles
+type RolesListResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+// PermissionsResponse represents the permissions roles can have
+type PermissionsResponse struct {
+	Permissions []string `json:"permissions" example:"documents:read,users:read"`
+}
+
+// ToRoleResponse converts entity.CustomRole to RoleResponse
+func ToRoleResponse(role *entity.CustomRole) RoleResponse {
+	createdAt := role.CreatedAt.Format(time.RFC3339)
+	updatedAt := role.UpdatedAt.Format(time.RFC3339)
+	return RoleResponse{
+		Name:        string(role.Name),
+		Description: role.Description,
+		Permissions: ToPermissionNames(role.PermissionList()),
+		CreatedAt:   &createdAt,
+		UpdatedAt:   &updatedAt,
+	}
+}
+
+// ToBuiltinRoleResponse converts a built-in role to RoleResponse
+func ToBuiltinRoleResponse(role entity.Role, permissions []entity.Permission) RoleResponse {
+	return RoleResponse{
+		Name:        string(role),
+		Permissions: ToPermissionNames(permissions),
+		Builtin:     true,
+	}
+}
+
+// ToPermissionNames converts permissions to their names
+func ToPermissionNames(permissions []entity.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return names
+}
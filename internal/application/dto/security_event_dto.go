@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// SecurityEventResponse represents a security event of an account
+type SecurityEventResponse struct {
+	ID        string            `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserID    string            `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174001"`
+	Type      string            `json:"type" example:"new_device_login"`
+	IP        string            `json:"ip,omitempty" example:"203.0.113.7"`
+	UserAgent string            `json:"user_agent,omitempty" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt string            `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// SecurityEventsListResponse represents a page of security events
+type SecurityEventsListResponse struct {
+	Events []SecurityEventResponse `json:"events"`
+	Total  int64                   `json:"total"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToSecurityEventResponse converts entity.SecurityEvent to SecurityEventResponse
+func ToSecurityEventResponse(event *entity.SecurityEvent) SecurityEventResponse {
+	return SecurityEventResponse{
+		ID:        event.ID,
+		UserID:    event.UserID,
+		Type:      string(event.Type),
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToSecurityEventsListResponse converts a page of security events to SecurityEventsListResponse
+func ToSecurityEventsListResponse(events []*entity.SecurityEvent, total int64, limit, offset int) SecurityEventsListResponse {
+	responses := make([]SecurityEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = ToSecurityEventResponse(event)
+	}
+
+	return SecurityEventsListResponse{
+		Events: responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
+
+// SuspiciousLoginResponse represents a login from a new device or country, and whether the
+// session it started is still signed in
+type SuspiciousLoginResponse struct {
+	ID        string `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Type      string `json:"type" example:"new_country_login"`
+	IP        string `json:"ip,omitempty" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent,omitempty" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	Country   string `json:"country,omitempty" example:"ID"`
+	// SessionID is revoked with DELETE /users/me/sessions/{id}
+	SessionID     string `json:"session_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174002"`
+	SessionActive bool   `json:"session_active" example:"true"`
+	CreatedAt     string `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// SuspiciousLoginsListResponse represents a page of suspicious logins
+type SuspiciousLoginsListResponse struct {
+	Logins []SuspiciousLoginResponse `json:"logins"`
+	Total  int64                     `json:"total"`
+	Limit  int                       `json:"limit"`
+	Offset int                       `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToSuspiciousLoginsListResponse converts a page of login events to SuspiciousLoginsListResponse.
+// activeSessions holds the IDs of the user's sessions that are still signed in.
+func ToSuspiciousLoginsListResponse(events []*entity.SecurityEvent, activeSessions map[string]bool, total int64, limit, offset int) SuspiciousLoginsListResponse {
+	responses := make([]SuspiciousLoginResponse, len(events))
+	for i, event := range events {
+		sessionID := event.Details["session_id"]
+		responses[i] = SuspiciousLoginResponse{
+			ID:            event.ID,
+			Type:          string(event.Type),
+			IP:            event.IP,
+			UserAgent:     event.UserAgent,
+			Country:       event.Details["country"],
+			SessionID:     sessionID,
+			SessionActive: sessionID != "" && activeSessions[sessionID],
+			CreatedAt:     event.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return SuspiciousLoginsListResponse{
+		Logins: responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
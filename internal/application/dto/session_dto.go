@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// SessionResponse represents a device the user is signed in on
+type SessionResponse struct {
+	ID string `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	// Device is a short description of the user agent
+	Device       string `json:"device" example:"Chrome on Windows"`
+	UserAgent    string `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"`
+	IP           string `json:"ip" example:"203.0.113.7"`
+	LastActiveAt string `json:"last_active_at" example:"2023-06-01T00:00:00Z"`
+	ExpiresAt    string `json:"expires_at" example:"2023-06-08T00:00:00Z"`
+}
+
+// ToSessionResponse converts the refresh token of a session to SessionResponse. The token is
+// replaced on every refresh, so its creation is the last activity of the session.
+func ToSessionResponse(token *entity.Token) SessionResponse {
+	return SessionResponse{
+		ID:           token.SessionID,
+		Device:       describeDevice(token.UserAgent),
+		UserAgent:    token.UserAgent,
+		IP:           token.IP,
+		LastActiveAt: token.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:    token.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// ToSessionListResponse converts the refresh tokens of sessions to responses
+func ToSessionListResponse(tokens []*entity.Token) []SessionResponse {
+	responses := make([]SessionResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = ToSessionResponse(token)
+	}
+	return responses
+}
+
+// userAgentBrowsers and userAgentSystems are matched against user agents in order, since
+// browsers name the ones they are based on too, e.g. Edge names Chrome and Safari
+var (
+	userAgentBrowsers = []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"},
+		{"Safari/", "Safari"}, {"curl/", "curl"}, {"okhttp/", "Android app"}, {"Dart/", "Flutter app"},
+	}
+	userAgentSystems = []struct{ token, name string }{
+		{"Windows", "Windows"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Android", "Android"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+)
+
+// describeDevice describes a user agent as "<browser> on <system>", as far as it is recognized
+func describeDevice(userAgent string) string {
+	var browser, system string
+	for _, candidate := range userAgentBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	for _, candidate := range userAgentSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
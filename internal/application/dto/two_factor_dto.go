@@ -0,0 +1,86 @@
+package dto
+
+import "strings"
+
+// PhoneVerificationRequest represents adding or changing the phone number of the current user
+type PhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164" example:"+6281234567890"`
+}
+
+// VerifyPhoneRequest represents confirming a phone number with the code texted to it
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6" example:"123456"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// EnableTwoFactorRequest represents turning on a second factor for the logins of the current
+// user
+type EnableTwoFactorRequest struct {
+	Method   string `json:"method" binding:"required,oneof=sms" example:"sms"`
+	Password string `json:"password" binding:"required" example:"password123"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// DisableTwoFactorRequest represents turning off the second factor of the current user
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// TwoFactorLoginRequest represents the second step of a login, with the texted code or one of
+// the user's recovery codes
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Code           string `json:"code" binding:"required_without=RecoveryCode,omitempty,numeric,len=6" example:"123456"`
+	RecoveryCode   string `json:"recovery_code" binding:"required_without=Code,omitempty,max=32" example:"7hk2m-x9qpa"`
+	// ClientIP and UserAgent are filled in by the handler for the session and new device checks
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// RegenerateRecoveryCodesRequest represents replacing the recovery codes of the current user
+type RegenerateRecoveryCodesRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// TwoFactorResponse represents the current user after turning on two-factor authentication.
+// RecoveryCodes are only returned when it was off before, and are shown this once.
+type TwoFactorResponse struct {
+	User          UserResponse `json:"user"`
+	RecoveryCodes []string     `json:"recovery_codes,omitempty" example:"7hk2m-x9qpa,3rw8c-tn5ve"`
+}
+
+// RecoveryCodesResponse represents the recovery codes of the current user. RecoveryCodes are
+// only returned when they were just generated, and are shown this once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes,omitempty" example:"7hk2m-x9qpa,3rw8c-tn5ve"`
+	Remaining     int64    `json:"remaining" example:"10"`
+}
+
+// TwoFactorChallengeResponse represents a login waiting for its second factor. The client
+// completes it at POST /auth/login/two-factor with the token and the code, or a recovery code.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	Method            string `json:"method" example:"sms"`
+	TwoFactorToken    string `json:"two_factor_token" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// Phone is the number the code was sent to, masked
+	Phone     string `json:"phone" example:"+62*******7890"`
+	ExpiresIn int64  `json:"expires_in" example:"300"`
+}
+
+// MaskPhone hides all but the first 2 and the last 4 digits of a phone number
+func MaskPhone(phone string) string {
+	if len(phone) <= 7 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
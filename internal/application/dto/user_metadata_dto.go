@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// CreateMetadataFieldRequest represents defining a metadata field users can set
+type CreateMetadataFieldRequest struct {
+	Key         string `json:"key" binding:"required" example:"department"`
+	Type        string `json:"type" binding:"required,oneof=string number boolean" example:"string"`
+	Description string `json:"description" example:"Department the user works in"`
+}
+
+// UpdateMetadataFieldRequest represents replacing the type and description of a metadata field
+type UpdateMetadataFieldRequest struct {
+	Type        string `json:"type" binding:"required,oneof=string number boolean" example:"string"`
+	Description string `json:"description" example:"Department the user works in"`
+}
+
+// MetadataFieldResponse represents a metadata field users can set
+type MetadataFieldResponse struct {
+	Key         string `json:"key" example:"department"`
+	Type        string `json:"type" example:"string"`
+	Description string `json:"description" example:"Department the user works in"`
+	CreatedAt   string `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt   string `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// MetadataFieldsListResponse represents the metadata fields users can set
+type MetadataFieldsListResponse struct {
+	Fields []MetadataFieldResponse `json:"fields"`
+}
+
+// ToMetadataFieldResponse converts entity.UserMetadataField to MetadataFieldResponse
+func ToMetadataFieldResponse(field *entity.UserMetadataField) MetadataFieldResponse {
+	return MetadataFieldResponse{
+		Key:         field.Key,
+		Type:        string(field.Type),
+		Description: field.Description,
+		CreatedAt:   field.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   field.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToMetadataFieldsListResponse converts metadata fields to MetadataFieldsListResponse
+func ToMetadataFieldsListResponse(fields []*entity.UserMetadataField) MetadataFieldsListResponse {
+	responses := make([]MetadataFieldResponse, len(fields))
+	for i, field := range fields {
+		responses[i] = ToMetadataFieldResponse(field)
+	}
+	return MetadataFieldsListResponse{Fields: responses}
+}
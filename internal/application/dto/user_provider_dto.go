@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// LocalProvider names the password sign-in method among the providers of a user
+const LocalProvider = "local"
+
+// LinkedProviderResponse represents a way the user can sign in: their password or an account at
+// an OAuth provider
+type LinkedProviderResponse struct {
+	// Provider is "local" for the password, or the name of an OAuth provider
+	Provider string `json:"provider" example:"google"`
+	// Email is the email of the provider account; the password has none
+	Email string `json:"email,omitempty" example:"user@gmail.com"`
+	// LinkedAt is when the provider account was linked; the password has none
+	LinkedAt string `json:"linked_at,omitempty" example:"2023-01-01T00:00:00Z"`
+}
+
+// ToLinkedProviderListResponse converts the sign-in methods of a user to responses, the password
+// first
+func ToLinkedProviderListResponse(user *entity.User, links []*entity.UserProvider) []LinkedProviderResponse {
+	responses := make([]LinkedProviderResponse, 0, len(links)+1)
+	if user.HasPassword() {
+		responses = append(responses, LinkedProviderResponse{Provider: LocalProvider})
+	}
+	for _, link := range links {
+		responses = append(responses, LinkedProviderResponse{
+			Provider: link.Provider.Name(),
+			Email:    link.Email,
+			LinkedAt: link.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return responses
+}
+
+// LinkProviderRequest represents adding a sign-in method. Adding a password needs the password;
+// linking an OAuth provider needs no body.
+type LinkProviderRequest struct {
+	Password string `json:"password" binding:"omitempty,min=8" example:"password123"`
+	// ClientIP and UserAgent are filled in by the handler for security events
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// LinkProviderResponse represents the start of linking an OAuth provider. The browser is sent to
+// the authorization URL, and the provider's callback links the account.
+type LinkProviderResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/auth?client_id=..."`
+}
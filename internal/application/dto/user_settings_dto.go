@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// UpdateUserSettingsRequest represents changing the settings of the current user. Fields left
+// out are kept.
+type UpdateUserSettingsRequest struct {
+	// Locale is a BCP 47 language tag for translated API messages; an empty string clears it
+	Locale *string `json:"locale" binding:"omitempty,bcp47_language_tag" example:"id"`
+	// Timezone is an IANA time zone; an empty string means UTC
+	Timezone           *string                          `json:"timezone" binding:"omitempty,eq=|timezone" example:"Asia/Jakarta"`
+	Theme              *string                          `json:"theme" binding:"omitempty,oneof=system light dark" example:"dark"`
+	EmailNotifications *UpdateEmailNotificationsRequest `json:"email_notifications"`
+}
+
+// UpdateEmailNotificationsRequest represents changing the emails the current user gets. Toggles
+// left out are kept.
+type UpdateEmailNotificationsRequest struct {
+	SecurityAlerts *bool `json:"security_alerts" example:"true"`
+	ProductUpdates *bool `json:"product_updates" example:"false"`
+}
+
+// UserSettingsResponse represents the settings of a user
+type UserSettingsResponse struct {
+	Locale             string                            `json:"locale" example:"id"`
+	Timezone           string                            `json:"timezone" example:"Asia/Jakarta"`
+	Theme              string                            `json:"theme" example:"system"`
+	EmailNotifications EmailNotificationSettingsResponse `json:"email_notifications"`
+	// UpdatedAt is left out until the user changes its settings
+	UpdatedAt string `json:"updated_at,omitempty" example:"2023-01-01T00:00:00Z"`
+}
+
+// EmailNotificationSettingsResponse represents the emails a user gets
+type EmailNotificationSettingsResponse struct {
+	SecurityAlerts bool `json:"security_alerts" example:"true"`
+	ProductUpdates bool `json:"product_updates" example:"false"`
+}
+
+// UnsubscribeRequest represents following the unsubscribe link of a notification email
+type UnsubscribeRequest struct {
+	Token string `json:"token" binding:"required" example:"dXNlci1pZDpzZWN1cml0eV9hbGVydHM.c2lnbmF0dXJl"`
+}
+
+// UnsubscribeResponse represents the notification category a user was unsubscribed from
+type UnsubscribeResponse struct {
+	Category string `json:"category" example:"security_alerts"`
+	Message  string `json:"message" example:"Unsubscribed successfully"`
+}
+
+// ToUserSettingsResponse converts the settings and locale of a user to UserSettingsResponse
+func ToUserSettingsResponse(settings *entity.UserSettings, locale string) UserSettingsResponse {
+	response := UserSettingsResponse{
+		Locale:   locale,
+		Timezone: settings.Timezone,
+		Theme:    string(settings.Theme),
+		EmailNotifications: EmailNotificationSettingsResponse{
+			SecurityAlerts: settings.EmailNotifications.SecurityAlerts,
+			ProductUpdates: settings.EmailNotifications.ProductUpdates,
+		},
+	}
+	if !settings.UpdatedAt.IsZero() {
+		response.UpdatedAt = settings.UpdatedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// CreateWebhookRequest represents webhook registration request
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048" example:"https://example.com/webhooks"`
+	Description string   `json:"description" binding:"max=255" example:"Document sync"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,required" example:"document.created,document.deleted"`
+}
+
+// UpdateWebhookRequest represents webhook update request. It replaces every setting of the
+// webhook; the secret is kept.
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048" example:"https://example.com/webhooks"`
+	Description string   `json:"description" binding:"max=255" example:"Document sync"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,required" example:"document.created,document.deleted"`
+	Active      *bool    `json:"active" binding:"required" example:"true"`
+}
+
+// WebhookResponse represents webhook response
+type WebhookResponse struct {
+	ID          string   `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	URL         string   `json:"url" example:"https://example.com/webhooks"`
+	Description string   `json:"description" example:"Document sync"`
+	EventTypes  []string `json:"event_types" example:"document.created,document.deleted"`
+	Active      bool     `json:"active" example:"true"`
+	CreatedAt   string   `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt   string   `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// CreateWebhookResponse represents webhook registration response.
+// The secret signing the deliveries is only returned once.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret" example:"whsec_1a2b3c4d..."`
+}
+
+// WebhookDeliveryResponse represents one attempt at delivering an event to a webhook
+type WebhookDeliveryResponse struct {
+	ID         string          `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	EventID    string          `json:"event_id" example:"123e4567-e89b-12d3-a456-426614174001"`
+	EventType  string          `json:"event_type" example:"document.created"`
+	Attempt    int             `json:"attempt" example:"1"`
+	Request    json.RawMessage `json:"request" swaggertype:"object"`
+	StatusCode int             `json:"status_code" example:"200"`
+	Response   string          `json:"response" example:"ok"`
+	Error      string          `json:"error,omitempty" example:"endpoint answered with status 503"`
+	Succeeded  bool            `json:"succeeded" example:"true"`
+	DurationMs int64           `json:"duration_ms" example:"120"`
+	CreatedAt  string          `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// WebhookDeliveriesListResponse represents a page of the delivery log of a webhook
+type WebhookDeliveriesListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int64                     `json:"total"`
+	Limit      int                       `json:"limit"`
+	Offset     int                       `json:"offset"`
+	// Links is set by the HTTP API
+	Links *PaginationLinks `json:"links,omitempty"`
+}
+
+// ToWebhookResponse converts entity.Webhook to WebhookResponse
+func ToWebhookResponse(webhook *entity.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Description: webhook.Description,
+		EventTypes:  webhook.EventTypes,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   webhook.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToWebhookListResponse converts webhooks slice to responses
+func ToWebhookListResponse(webhooks []*entity.Webhook) []WebhookResponse {
+	responses := make([]WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = ToWebhookResponse(webhook)
+	}
+	return responses
+}
+
+// ToWebhookDeliveryResponse converts entity.WebhookDelivery to WebhookDeliveryResponse
+func ToWebhookDeliveryResponse(delivery *entity.WebhookDelivery) WebhookDeliveryResponse {
+	var request json.RawMessage
+	if delivery.Request != "" {
+		request = json.RawMessage(delivery.Request)
+	}
+
+	return WebhookDeliveryResponse{
+		ID:         delivery.ID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Attempt:    delivery.Attempt,
+		Request:    request,
+		StatusCode: delivery.StatusCode,
+		Response:   delivery.Response,
+		Error:      delivery.Error,
+		Succeeded:  delivery.Succeeded,
+		DurationMs: delivery.DurationMs,
+		CreatedAt:  delivery.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToWebhookDeliveriesListResponse converts a page of deliveries to WebhookDeliveriesListResponse
+func ToWebhookDeliveriesListResponse(deliveries []*entity.WebhookDelivery, total int64, limit, offset int) WebhookDeliveriesListResponse {
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = ToWebhookDeliveryResponse(delivery)
+	}
+
+	return WebhookDeliveriesListResponse{
+		Deliveries: responses,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}
+}
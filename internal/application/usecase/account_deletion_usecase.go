@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// accountDeletionBatchSize is how many documents are deleted at a time
+const accountDeletionBatchSize = 100
+
+// AccountDeletionConfig configures what deleting an account does with the user
+type AccountDeletionConfig struct {
+	// Anonymize keeps the user with its personal data replaced instead of deleting it, so the
+	// records referring to it stay consistent
+	Anonymize bool
+	// SearchIndex removes the deleted documents from the external search index, when documents
+	// are indexed in one
+	SearchIndex bool
+}
+
+// AccountDeletionUseCase handles users deleting their own account. Their documents, data exports,
+// sessions, sign-in methods, API keys, webhooks, activity and settings are deleted permanently
+// with it.
+type AccountDeletionUseCase struct {
+	userRepo             repository.UserRepository
+	documentRepo         repository.DocumentRepository
+	tokenRepo            repository.TokenRepository
+	userProviderRepo     repository.UserProviderRepository
+	apiKeyRepo           repository.APIKeyRepository
+	webhookRepo          repository.WebhookRepository
+	previousPasswordRepo repository.PreviousPasswordRepository
+	activityRepo         repository.ActivityRepository
+	settingsRepo         repository.UserSettingsRepository
+	unitOfWork           repository.UnitOfWork
+	passwordService      service.PasswordService
+	recoveryCodes        *service.RecoveryCodeService
+	jobQueue             *service.JobQueue
+	// otp texts the code confirming the deletion to users with SMS two-factor authentication;
+	// nil leaves them their recovery codes
+	otp *service.OTPService
+	// denylist rejects the deleted user's access tokens; nil leaves them valid until they expire
+	denylist *service.TokenDenylistService
+	auditLog *service.AuditLogService
+	config   AccountDeletionConfig
+}
+
+// NewAccountDeletionUseCase creates a new account deletion use case. otp and denylist may be
+// nil.
+func NewAccountDeletionUseCase(
+	userRepo repository.UserRepository,
+	documentRepo repository.DocumentRepository,
+	tokenRepo repository.TokenRepository,
+	userProviderRepo repository.UserProviderRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	webhookRepo repository.WebhookRepository,
+	previousPasswordRepo repository.PreviousPasswordRepository,
+	activityRepo repository.ActivityRepository,
+	settingsRepo repository.UserSettingsRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	recoveryCodes *service.RecoveryCodeService,
+	jobQueue *service.JobQueue,
+	otp *service.OTPService,
+	denylist *service.TokenDenylistService,
+	auditLog *service.AuditLogService,
+	config AccountDeletionConfig,
+) *AccountDeletionUseCase {
+	return &AccountDeletionUseCase{
+		userRepo:             userRepo,
+		documentRepo:         documentRepo,
+		tokenRepo:            tokenRepo,
+		userProviderRepo:     userProviderRepo,
+		apiKeyRepo:           apiKeyRepo,
+		webhookRepo:          webhookRepo,
+		previousPasswordRepo: previousPasswordRepo,
+		activityRepo:         activityRepo,
+		settingsRepo:         settingsRepo,
+		unitOfWork:           unitOfWork,
+		passwordService:      passwordService,
+		recoveryCodes:        recoveryCodes,
+		jobQueue:             jobQueue,
+		otp:                  otp,
+		denylist:             denylist,
+		auditLog:             auditLog,
+		config:               config,
+	}
+}
+
+// Execute deletes the user's account once the request confirms it. It reports false when the
+// user has two-factor authentication and a code was texted instead, which a second request
+// sends along.
+func (uc *AccountDeletionUseCase) Execute(ctx context.Context, userID string, req dto.DeleteAccountRequest) (bool, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if user.HasPassword() {
+		if err := uc.passwordService.VerifyPassword(req.Password, *user.Password); err != nil {
+			return false, domain.ErrIncorrectPassword
+		}
+	} else if !strings.EqualFold(strings.TrimSpace(req.Email), user.Email) {
+		return false, domain.ErrDeletionUnconfirmed
+	}
+
+	if user.HasTwoFactor() {
+		confirmed, err := uc.verifySecondFactor(ctx, user, req)
+		if err != nil || !confirmed {
+			return false, err
+		}
+	}
+
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		return uc.delete(ctx, user)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUser(ctx, user.ID); err != nil {
+			return false, fmt.Errorf("failed to deny access tokens: %w", err)
+		}
+	}
+
+	details := map[string]string{"anonymized": strconv.FormatBool(uc.config.Anonymize)}
+	uc.auditLog.Record(ctx, entity.AuditActionAccountDeleted, entity.AuditTargetUser, user.ID, details)
+	return true, nil
+}
+
+// verifySecondFactor checks the texted code or recovery code of the request. Without either, it
+// texts a code and reports false.
+func (uc *AccountDeletionUseCase) verifySecondFactor(ctx context.Context, user *entity.User, req dto.DeleteAccountRequest) (bool, error) {
+	if req.RecoveryCode != "" {
+		return true, uc.recoveryCodes.Use(ctx, user.ID, req.RecoveryCode)
+	}
+	if uc.otp == nil || user.Phone == nil {
+		return false, domain.ErrSMSUnavailable
+	}
+	if req.Code == "" {
+		return false, uc.otp.Send(ctx, service.OTPPurposeAccountDeletion, user.ID, &service.OTPChallenge{
+			UserID: user.ID,
+			Phone:  *user.Phone,
+		})
+	}
+	if _, err := uc.otp.Verify(ctx, service.OTPPurposeAccountDeletion, user.ID, req.Code); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// delete deletes the user's records, and the user itself or its personal data. The files of its
+// documents, its uploaded avatars and its data exports are deleted from storage once the deletion
+// is committed.
+func (uc *AccountDeletionUseCase) delete(ctx context.Context, user *entity.User) error {
+	if err := uc.deleteDocuments(ctx, user.ID); err != nil {
+		return err
+	}
+	// Exports still pending skip the deleted account
+	if err := uc.jobQueue.Enqueue(ctx, JobDeleteUserExports, UserDataExportPayload{UserID: user.ID}); err != nil {
+		return err
+	}
+
+	// Avatars of providers are links to their servers
+	for _, avatar := range []*string{user.Avatar, user.PendingAvatar} {
+		if avatar != nil && strings.Contains(*avatar, "avatars/"+user.ID+"/") {
+			if err := uc.jobQueue.Enqueue(ctx, JobDeleteAvatar, StoredFilePayload{URL: *avatar}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete refresh tokens: %w", err)
+	}
+	if err := uc.recoveryCodes.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := uc.previousPasswordRepo.Prune(ctx, user.ID, 0); err != nil {
+		return err
+	}
+	if err := uc.activityRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete activities: %w", err)
+	}
+	if err := uc.settingsRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	links, err := uc.userProviderRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list user providers: %w", err)
+	}
+	for _, link := range links {
+		if err := uc.userProviderRepo.Delete(ctx, user.ID, link.Provider); err != nil {
+			return fmt.Errorf("failed to delete user provider: %w", err)
+		}
+	}
+
+	apiKeys, err := uc.apiKeyRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+	for _, apiKey := range apiKeys {
+		if err := uc.apiKeyRepo.Delete(ctx, apiKey.ID); err != nil {
+			return fmt.Errorf("failed to delete API key: %w", err)
+		}
+	}
+
+	webhooks, err := uc.webhookRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for _, webhook := range webhooks {
+		if err := uc.webhookRepo.Delete(ctx, webhook.ID); err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+	}
+
+	if uc.config.Anonymize {
+		user.Anonymize()
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+		return nil
+	}
+
+	// Deleted permanently rather than kept in the trash
+	if err := uc.userRepo.Delete(repository.WithDeleted(ctx), user.ID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// deleteDocuments permanently deletes the user's documents, those in the trash included, and
+// enqueues the deletion of the files that are still stored. Files of documents in the trash were
+// deleted with them.
+func (uc *AccountDeletionUseCase) deleteDocuments(ctx context.Context, userID string) error {
+	ctx = repository.WithDeleted(ctx)
+	for {
+		documents, err := uc.documentRepo.FindByUserID(ctx, userID, accountDeletionBatchSize, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+		if len(documents) == 0 {
+			return nil
+		}
+
+		for _, document := range documents {
+			if err := uc.documentRepo.Delete(ctx, document.ID); err != nil {
+				return fmt.Errorf("failed to delete document: %w", err)
+			}
+			if uc.config.SearchIndex {
+				if err := uc.jobQueue.Enqueue(ctx, JobIndexDocument, DocumentIndexPayload{DocumentID: document.ID}); err != nil {
+					return err
+				}
+			}
+			if document.DeletedAt.Valid {
+				continue
+			}
+			if err := uc.jobQueue.Enqueue(ctx, JobDeleteDocumentFile, StoredFilePayload{URL: document.FileURL}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// actionLink returns the link of an emailed action token: the frontend page at baseURL with a
+// token parameter
+func actionLink(baseURL, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// formatExpiry formats how long a link can be used for an email, e.g. "1 hour" or "30 minutes"
+func formatExpiry(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return pluralize(int(ttl/time.Hour), "hour")
+	}
+	return pluralize(int(ttl.Round(time.Minute)/time.Minute), "minute")
+}
+
+// pluralize formats a count of a unit, e.g. "1 hour" or "2 hours"
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
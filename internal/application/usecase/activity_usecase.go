@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// ActivityUseCase handles the activity feeds of users
+type ActivityUseCase struct {
+	activityRepo repository.ActivityRepository
+}
+
+// NewActivityUseCase creates a new activity use case
+func NewActivityUseCase(activityRepo repository.ActivityRepository) *ActivityUseCase {
+	return &ActivityUseCase{
+		activityRepo: activityRepo,
+	}
+}
+
+// ListUserActivity returns a page of the user's activity feed, newest first, optionally only the
+// activities of a type
+func (uc *ActivityUseCase) ListUserActivity(ctx context.Context, userID string, activityType entity.ActivityType, req dto.PaginationRequest) (*dto.ActivityListResponse, error) {
+	if activityType != "" && !activityType.IsValid() {
+		return nil, domain.ErrInvalidActivityType
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filter := repository.ActivityFilter{UserID: userID, Type: activityType}
+	activities, err := uc.activityRepo.List(ctx, filter, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	total, err := uc.activityRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count activities: %w", err)
+	}
+
+	response := dto.ToActivityListResponse(activities, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// Purge deletes the activities older than the retention, and returns how many were deleted
+func (uc *ActivityUseCase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := uc.activityRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge activities: %w", err)
+	}
+	return deleted, nil
+}
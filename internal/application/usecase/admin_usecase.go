@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// indexDocumentsBatchSize is how many documents IndexDocuments reads at a time
+const indexDocumentsBatchSize = 100
+
+// AdminUseCase handles the operational tasks of the admin command line, which work on the
+// database directly instead of through the API
+type AdminUseCase struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.TokenRepository
+	documentRepo    repository.DocumentRepository
+	passwordService service.PasswordService
+	// securityEvents records password resets; nil skips it
+	securityEvents *service.SecurityEventService
+	auditLog       *service.AuditLogService
+	// searchIndexer is nil unless documents are indexed in an external search engine
+	searchIndexer service.SearchIndexer
+}
+
+// NewAdminUseCase creates a new admin use case
+func NewAdminUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	documentRepo repository.DocumentRepository,
+	passwordService service.PasswordService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+	searchIndexer service.SearchIndexer,
+) *AdminUseCase {
+	return &AdminUseCase{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		documentRepo:    documentRepo,
+		passwordService: passwordService,
+		securityEvents:  securityEvents,
+		auditLog:        auditLog,
+		searchIndexer:   searchIndexer,
+	}
+}
+
+// CreateAdmin creates a verified admin user. Unlike seeding, it fails with
+// domain.ErrEmailAlreadyExists instead of promoting an existing user.
+func (uc *AdminUseCase) CreateAdmin(ctx context.Context, email, name, password string) (*entity.User, error) {
+	hashedPassword, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.NewUser(email, name, entity.RoleAdmin)
+	user.SetPassword(hashedPassword)
+	user.VerifyEmail()
+
+	if err := user.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// ResetPassword sets a new password for the user with the email or ID, and revokes the user's
+// refresh tokens so every session has to log in again
+func (uc *AdminUseCase) ResetPassword(ctx context.Context, emailOrID, password string) (*entity.User, error) {
+	user, err := uc.FindUser(ctx, emailOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	user.SetPassword(hashedPassword)
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if uc.securityEvents != nil {
+		details := map[string]string{"changed_by": "admin_command"}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventPasswordChanged, "", "", details)
+	}
+	// Commands have no actor, so the entry is the API's own
+	uc.auditLog.Record(ctx, entity.AuditActionPasswordReset, entity.AuditTargetUser, user.ID, map[string]string{"reset_by": "admin_command"})
+	return user, nil
+}
+
+// RevokeUserTokens revokes the refresh tokens of the user with the email or ID. Access tokens
+// already issued stay valid until they expire.
+func (uc *AdminUseCase) RevokeUserTokens(ctx context.Context, emailOrID string) (*entity.User, error) {
+	user, err := uc.FindUser(ctx, emailOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ReindexDocuments rebuilds the indexes of the documents
+func (uc *AdminUseCase) ReindexDocuments(ctx context.Context) error {
+	if err := uc.documentRepo.Reindex(ctx); err != nil {
+		return fmt.Errorf("failed to reindex documents: %w", err)
+	}
+	return nil
+}
+
+// IndexDocuments indexes every document in the external search engine, newest first, and returns
+// how many were indexed
+func (uc *AdminUseCase) IndexDocuments(ctx context.Context) (int, error) {
+	if uc.searchIndexer == nil {
+		return 0, errors.New("documents are searched in Postgres; set SEARCH_BACKEND=opensearch to index them")
+	}
+	if err := uc.searchIndexer.EnsureIndex(ctx); err != nil {
+		return 0, fmt.Errorf("failed to create the search index: %w", err)
+	}
+
+	indexed := 0
+	var after *repository.Cursor
+	for {
+		documents, err := uc.documentRepo.FindAfter(ctx, after, indexDocumentsBatchSize)
+		if err != nil {
+			return indexed, fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		for _, document := range documents {
+			if err := uc.searchIndexer.Index(ctx, document); err != nil {
+				return indexed, fmt.Errorf("failed to index document %s: %w", document.ID, err)
+			}
+			indexed++
+		}
+
+		if len(documents) < indexDocumentsBatchSize {
+			return indexed, nil
+		}
+		last := documents[len(documents)-1]
+		cursor := repository.CursorOf(last.CreatedAt, last.ID)
+		after = &cursor
+	}
+}
+
+// FindUser finds a user by email, or by ID when emailOrID has no @
+func (uc *AdminUseCase) FindUser(ctx context.Context, emailOrID string) (*entity.User, error) {
+	var user *entity.User
+	var err error
+	if strings.Contains(emailOrID, "@") {
+		user, err = uc.userRepo.FindByEmail(ctx, emailOrID)
+	} else {
+		user, err = uc.userRepo.FindByID(ctx, emailOrID)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrUserNotFound.WithMessage(fmt.Sprintf("User %s not found", emailOrID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// CreateAPIKeyUseCase handles creating API keys for the current user
+type CreateAPIKeyUseCase struct {
+	apiKeyRepo        repository.APIKeyRepository
+	apiKeyService     service.APIKeyService
+	defaultRateLimit  int
+	defaultRateWindow time.Duration
+}
+
+// NewCreateAPIKeyUseCase creates a new create API key use case
+func NewCreateAPIKeyUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+	apiKeyService service.APIKeyService,
+	defaultRateLimit int,
+	defaultRateWindow time.Duration,
+) *CreateAPIKeyUseCase {
+	return &CreateAPIKeyUseCase{
+		apiKeyRepo:        apiKeyRepo,
+		apiKeyService:     apiKeyService,
+		defaultRateLimit:  defaultRateLimit,
+		defaultRateWindow: defaultRateWindow,
+	}
+}
+
+// Execute executes the create API key use case
+func (uc *CreateAPIKeyUseCase) Execute(ctx context.Context, userID string, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	key, prefix, hash, err := uc.apiKeyService.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		expiry := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		expiresAt = &expiry
+	}
+
+	apiKey := entity.NewAPIKey(userID, req.Name, prefix, hash, uc.defaultRateLimit, uc.defaultRateWindow, expiresAt)
+
+	if err := apiKey.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &dto.CreateAPIKeyResponse{
+		APIKeyResponse: dto.ToAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+// ListAPIKeysUseCase handles listing the current user's API keys
+type ListAPIKeysUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewListAPIKeysUseCase creates a new list API keys use case
+func NewListAPIKeysUseCase(apiKeyRepo repository.APIKeyRepository) *ListAPIKeysUseCase {
+	return &ListAPIKeysUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Execute executes the list API keys use case
+func (uc *ListAPIKeysUseCase) Execute(ctx context.Context, userID string) ([]dto.APIKeyResponse, error) {
+	apiKeys, err := uc.apiKeyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	return dto.ToAPIKeyListResponse(apiKeys), nil
+}
+
+// RevokeAPIKeyUseCase handles revoking one of the current user's API keys
+type RevokeAPIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewRevokeAPIKeyUseCase creates a new revoke API key use case
+func NewRevokeAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository) *RevokeAPIKeyUseCase {
+	return &RevokeAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Execute executes the revoke API key use case
+func (uc *RevokeAPIKeyUseCase) Execute(ctx context.Context, userID, apiKeyID string) error {
+	apiKey, err := uc.apiKeyRepo.FindByID(ctx, apiKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to find API key: %w", err)
+	}
+	if apiKey.UserID != userID {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	if apiKey.IsRevoked() {
+		return nil
+	}
+
+	apiKey.Revoke()
+
+	if err := uc.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAPIKeyRateLimitUseCase handles changing the quota of an API key (admin only)
+type UpdateAPIKeyRateLimitUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewUpdateAPIKeyRateLimitUseCase creates a new update API key rate limit use case
+func NewUpdateAPIKeyRateLimitUseCase(apiKeyRepo repository.APIKeyRepository) *UpdateAPIKeyRateLimitUseCase {
+	return &UpdateAPIKeyRateLimitUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Execute executes the update API key rate limit use case
+func (uc *UpdateAPIKeyRateLimitUseCase) Execute(ctx context.Context, apiKeyID string, req dto.UpdateAPIKeyRateLimitRequest) (*dto.APIKeyResponse, error) {
+	apiKey, err := uc.apiKeyRepo.FindByID(ctx, apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+
+	apiKey.UpdateRateLimit(req.RateLimit, time.Duration(req.RateWindowSeconds)*time.Second)
+
+	if err := apiKey.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	response := dto.ToAPIKeyResponse(apiKey)
+	return &response, nil
+}
+
+// AuthenticateAPIKeyUseCase resolves a plaintext API key to its key record and owner
+type AuthenticateAPIKeyUseCase struct {
+	apiKeyRepo    repository.APIKeyRepository
+	userRepo      repository.UserRepository
+	apiKeyService service.APIKeyService
+}
+
+// NewAuthenticateAPIKeyUseCase creates a new authenticate API key use case
+func NewAuthenticateAPIKeyUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+	userRepo repository.UserRepository,
+	apiKeyService service.APIKeyService,
+) *AuthenticateAPIKeyUseCase {
+	return &AuthenticateAPIKeyUseCase{
+		apiKeyRepo:    apiKeyRepo,
+		userRepo:      userRepo,
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Execute executes the authenticate API key use case
+func (uc *AuthenticateAPIKeyUseCase) Execute(ctx context.Context, key string) (*entity.APIKey, *entity.User, error) {
+	apiKey, err := uc.apiKeyRepo.FindByKeyHash(ctx, uc.apiKeyService.HashKey(key))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+	if !apiKey.IsActive() {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, apiKey.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := uc.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID); err != nil {
+		// Usage tracking must not block authentication
+	}
+
+	return apiKey, user, nil
+}
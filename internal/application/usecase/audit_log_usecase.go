@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// AuditLogUseCase handles reviewing and purging the audit log (admin only)
+type AuditLogUseCase struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+// NewAuditLogUseCase creates a new audit log use case
+func NewAuditLogUseCase(auditLogRepo repository.AuditLogRepository) *AuditLogUseCase {
+	return &AuditLogUseCase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// List returns a page of the audit log entries matching the filters, newest first
+func (uc *AuditLogUseCase) List(ctx context.Context, filterReq dto.AuditLogFilterRequest, req dto.PaginationRequest) (*dto.AuditLogListResponse, error) {
+	filter, err := toAuditLogFilter(filterReq)
+	if err != nil {
+		return nil, err
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	entries, err := uc.auditLogRepo.List(ctx, filter, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	total, err := uc.auditLogRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit log: %w", err)
+	}
+
+	response := dto.ToAuditLogListResponse(entries, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// Purge deletes the audit log entries older than the retention, and returns how many were
+// deleted
+func (uc *AuditLogUseCase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := uc.auditLogRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge audit log: %w", err)
+	}
+	return deleted, nil
+}
+
+// toAuditLogFilter converts the filters of the audit log, whose times were validated on binding
+func toAuditLogFilter(req dto.AuditLogFilterRequest) (repository.AuditLogFilter, error) {
+	filter := repository.AuditLogFilter{
+		ActorID:    req.ActorID,
+		Action:     entity.AuditAction(req.Action),
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+	if filter.Action != "" && !filter.Action.IsValid() {
+		return filter, domain.ErrInvalidAuditAction
+	}
+	if req.From != "" {
+		from, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return filter, domain.NewValidationError(err)
+		}
+		filter.From = from
+	}
+	if req.To != "" {
+		to, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return filter, domain.NewValidationError(err)
+		}
+		filter.Before = to
+	}
+	return filter, nil
+}
@@ -6,22 +6,39 @@ import (
 	"mime/multipart"
 	"strings"
 
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 	"gin-boilerplate/internal/infrastructure/storage"
 )
 
 type AvatarUseCase struct {
-	userRepo      repository.UserRepository
-	avatarService *service.AvatarService
-	storage       *storage.S3Client
+	userRepo       repository.UserRepository
+	unitOfWork     repository.UnitOfWork
+	avatarService  *service.AvatarService
+	storage        *storage.S3Client
+	eventBus       *service.EventBus
+	webhookService *service.WebhookService
+	jobQueue       *service.JobQueue
+	auditLog       *service.AuditLogService
+	// moderation keeps uploaded avatars pending until a moderator approves them
+	moderation bool
 }
 
-func NewAvatarUseCase(userRepo repository.UserRepository, avatarService *service.AvatarService, storage *storage.S3Client) *AvatarUseCase {
+func NewAvatarUseCase(userRepo repository.UserRepository, unitOfWork repository.UnitOfWork, avatarService *service.AvatarService, storage *storage.S3Client, eventBus *service.EventBus, webhookService *service.WebhookService, jobQueue *service.JobQueue, auditLog *service.AuditLogService, moderation bool) *AvatarUseCase {
 	return &AvatarUseCase{
-		userRepo:      userRepo,
-		avatarService: avatarService,
-		storage:       storage,
+		userRepo:       userRepo,
+		unitOfWork:     unitOfWork,
+		avatarService:  avatarService,
+		storage:        storage,
+		eventBus:       eventBus,
+		webhookService: webhookService,
+		jobQueue:       jobQueue,
+		auditLog:       auditLog,
+		moderation:     moderation,
 	}
 }
 
@@ -35,89 +52,252 @@ type UpdateAvatarRequest struct {
 	AvatarURL *string
 }
 
-func (uc *AvatarUseCase) UploadAvatar(ctx context.Context, req *UploadAvatarRequest) (*string, error) {
+// UploadAvatar sets the uploaded avatar, and returns the URL of the avatar shown and whether the
+// upload awaits moderation. A pending upload leaves the avatar shown, and replaces an earlier
+// pending one.
+func (uc *AvatarUseCase) UploadAvatar(ctx context.Context, req *UploadAvatarRequest) (*string, bool, error) {
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, req.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, false, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	// Upload new avatar to S3
 	newAvatarURL, err := uc.avatarService.UploadAvatar(ctx, req.File, req.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload avatar: %w", err)
+		return nil, false, fmt.Errorf("failed to upload avatar: %w", err)
 	}
 
-	// Delete old avatar from S3 if exists
-	if user.Avatar != nil && !uc.isGoogleAvatar(*user.Avatar) {
-		if deleteErr := uc.avatarService.DeleteAvatar(ctx, *user.Avatar); deleteErr != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Warning: failed to delete old avatar: %v\n", deleteErr)
+	// Update user avatar in database, and delete the replaced ones from S3 once they are no longer
+	// used
+	replaced := []*string{user.PendingAvatar}
+	if uc.moderation {
+		user.SetPendingAvatar(newAvatarURL)
+	} else {
+		replaced = append(replaced, user.Avatar)
+		user.Avatar = newAvatarURL
+		user.SetPendingAvatar(nil)
+	}
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user avatar: %w", err)
+		}
+		return uc.deleteAvatarFiles(ctx, replaced...)
+	})
+	if err != nil {
+		// Try to rollback S3 upload, even if the request deadline has passed
+		if deleteErr := uc.avatarService.DeleteAvatar(context.WithoutCancel(ctx), *newAvatarURL); deleteErr != nil {
+			logging.FromContext(ctx).WithError(deleteErr).Warn("Failed to rollback avatar upload")
 		}
+		return nil, false, err
 	}
 
-	// Update user avatar in database
-	user.Avatar = newAvatarURL
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		// Try to rollback S3 upload
-		if deleteErr := uc.avatarService.DeleteAvatar(ctx, *newAvatarURL); deleteErr != nil {
-			fmt.Printf("Warning: failed to rollback avatar upload: %v\n", deleteErr)
-		}
-		return nil, fmt.Errorf("failed to update user avatar: %w", err)
+	if uc.moderation {
+		return uc.avatarURL(user), true, nil
 	}
 
 	// Return API endpoint URL instead of direct S3 URL
-	apiURL := fmt.Sprintf("/api/v1/users/avatar/%s", req.UserID)
-	return &apiURL, nil
+	apiURL := uc.avatarURL(user)
+	uc.publish(ctx, req.UserID, "avatar.updated", map[string]string{"avatar_url": *apiURL})
+	return apiURL, false, nil
 }
 
+// RemoveAvatar removes the user's avatar, and withdraws an upload awaiting moderation
 func (uc *AvatarUseCase) RemoveAvatar(ctx context.Context, userID string) error {
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// Don't remove Google avatars, though an upload awaiting moderation can still be withdrawn
+	googleAvatar := user.Avatar != nil && uc.isGoogleAvatar(*user.Avatar)
+	if googleAvatar && user.PendingAvatar == nil {
+		return domain.ErrOAuthAvatarReadOnly
+	}
+
+	// Remove avatar URL from database, and the avatar from S3 once it is no longer used
+	removed := []*string{user.PendingAvatar}
+	if !googleAvatar {
+		removed = append(removed, user.Avatar)
+		user.Avatar = nil
+	}
+	user.SetPendingAvatar(nil)
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return uc.deleteAvatarFiles(ctx, removed...)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !googleAvatar {
+		uc.publish(ctx, userID, "avatar.removed", map[string]string{})
+	}
+
+	return nil
+}
+
+// ListPendingAvatars lists the avatars awaiting moderation, the oldest upload first
+func (uc *AvatarUseCase) ListPendingAvatars(ctx context.Context, req dto.PaginationRequest) (*dto.PendingAvatarsListResponse, error) {
+	// Set default pagination values
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filter := repository.UserFilter{PendingAvatar: true}
+	users, err := uc.userRepo.List(ctx, filter, repository.UserSort{Field: repository.UserSortPendingAvatarAt, Ascending: true}, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending avatars: %w", err)
+	}
+
+	total, err := uc.userRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending avatars: %w", err)
+	}
+
+	response := dto.ToPendingAvatarsListResponse(users, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// ServePendingAvatar returns a presigned URL of the user's avatar awaiting moderation
+func (uc *AvatarUseCase) ServePendingAvatar(ctx context.Context, userID string) (*string, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.PendingAvatar == nil {
+		return nil, domain.ErrNoPendingAvatar
+	}
+
+	presignedURL, err := uc.storage.GetPresignedURL(ctx, *user.PendingAvatar, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get avatar URL: %w", err)
+	}
+	return presignedURL, nil
+}
+
+// ApproveAvatar shows the user's avatar awaiting moderation in place of its avatar, which is
+// deleted from S3 once it is no longer used
+func (uc *AvatarUseCase) ApproveAvatar(ctx context.Context, userID string) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.PendingAvatar == nil {
+		return domain.ErrNoPendingAvatar
 	}
 
-	// Don't remove Google avatars
-	if user.Avatar != nil && uc.isGoogleAvatar(*user.Avatar) {
-		return fmt.Errorf("cannot remove Google OAuth avatar")
+	previous := user.Avatar
+	user.ApprovePendingAvatar()
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user avatar: %w", err)
+		}
+		return uc.deleteAvatarFiles(ctx, previous)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Delete avatar from S3 if exists
-	if user.Avatar != nil {
-		if err := uc.avatarService.DeleteAvatar(ctx, *user.Avatar); err != nil {
-			return fmt.Errorf("failed to delete avatar: %w", err)
+	uc.auditLog.Record(ctx, entity.AuditActionAvatarApproved, entity.AuditTargetUser, user.ID, nil)
+	uc.publish(ctx, user.ID, "avatar.updated", map[string]string{"avatar_url": *uc.avatarURL(user)})
+	return nil
+}
+
+// RejectAvatar deletes the user's avatar awaiting moderation, keeping its avatar. The user is told
+// the reason with an avatar.rejected event.
+func (uc *AvatarUseCase) RejectAvatar(ctx context.Context, userID string, req dto.RejectAvatarRequest) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.PendingAvatar == nil {
+		return domain.ErrNoPendingAvatar
+	}
+
+	rejected := user.PendingAvatar
+	user.SetPendingAvatar(nil)
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user avatar: %w", err)
 		}
+		return uc.deleteAvatarFiles(ctx, rejected)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Remove avatar URL from database
-	user.Avatar = nil
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+	var details map[string]string
+	if req.Reason != "" {
+		details = map[string]string{"reason": req.Reason}
 	}
+	uc.auditLog.Record(ctx, entity.AuditActionAvatarRejected, entity.AuditTargetUser, user.ID, details)
+	uc.publish(ctx, user.ID, "avatar.rejected", map[string]string{"reason": req.Reason})
+	return nil
+}
 
+// deleteAvatarFiles enqueues the deletion of the uploaded avatars from S3, skipping nil URLs and
+// the avatars of providers, which are links to their servers
+func (uc *AvatarUseCase) deleteAvatarFiles(ctx context.Context, avatarURLs ...*string) error {
+	for _, avatarURL := range avatarURLs {
+		if avatarURL == nil || uc.isGoogleAvatar(*avatarURL) {
+			continue
+		}
+		if err := uc.jobQueue.Enqueue(ctx, JobDeleteAvatar, StoredFilePayload{URL: *avatarURL}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// DeleteAvatarFile deletes an avatar that is no longer used from S3. It runs JobDeleteAvatar
+// jobs.
+func (uc *AvatarUseCase) DeleteAvatarFile(ctx context.Context, payload StoredFilePayload) error {
+	return uc.avatarService.DeleteAvatar(ctx, payload.URL)
+}
+
+// publish notifies the user's live connections and webhooks; the change itself has already
+// succeeded
+func (uc *AvatarUseCase) publish(ctx context.Context, userID, eventType string, data interface{}) {
+	ctx = context.WithoutCancel(ctx)
+	if err := uc.eventBus.Publish(ctx, userID, eventType, data); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to publish event")
+	}
+	if err := uc.webhookService.Dispatch(ctx, userID, eventType, data); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to dispatch event to webhooks")
+	}
+}
+
 func (uc *AvatarUseCase) GetAvatarURL(ctx context.Context, userID string) (*string, error) {
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
+	return uc.avatarURL(user), nil
+}
+
+// avatarURL returns the URL of the avatar shown for the user, or nil when it has none
+func (uc *AvatarUseCase) avatarURL(user *entity.User) *string {
 	if user.Avatar == nil {
-		return nil, nil // No avatar set
+		return nil // No avatar set
 	}
 
 	// Return Google avatar directly
 	if uc.isGoogleAvatar(*user.Avatar) {
-		return user.Avatar, nil
+		return user.Avatar
 	}
 
 	// Return API endpoint URL for S3 avatars
-	apiURL := fmt.Sprintf("/api/v1/users/avatar/%s", userID)
-	return &apiURL, nil
+	apiURL := fmt.Sprintf("/api/v1/users/avatar/%s", user.ID)
+	return &apiURL
 }
 
 func (uc *AvatarUseCase) isGoogleAvatar(avatarURL string) bool {
@@ -130,11 +310,11 @@ func (uc *AvatarUseCase) ServeAvatar(ctx context.Context, userID string) (*strin
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("user not found")
+		return nil, nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	if user.Avatar == nil {
-		return nil, nil, fmt.Errorf("user has no avatar")
+		return nil, nil, domain.ErrAvatarNotFound
 	}
 
 	// Return redirect URL for Google avatars
@@ -145,8 +325,8 @@ func (uc *AvatarUseCase) ServeAvatar(ctx context.Context, userID string) (*strin
 	// For S3 avatars, get presigned URL
 	presignedURL, err := uc.storage.GetPresignedURL(ctx, *user.Avatar, 0)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get avatar URL")
+		return nil, nil, fmt.Errorf("failed to get avatar URL: %w", err)
 	}
 
 	return presignedURL, nil, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// Dashboard time series cover at most the days the daily counters are kept
+var maxDashboardDays = int(service.DailyCounterRetention / (24 * time.Hour))
+
+// DashboardUseCase aggregates the figures of the admin operations dashboard from Postgres and
+// Redis (admin only). Figures are cached for a short TTL, so a dashboard refreshing every few
+// seconds doesn't run the aggregate queries each time.
+type DashboardUseCase struct {
+	userRepo      repository.UserRepository
+	documentRepo  repository.DocumentRepository
+	jobRepo       repository.JobRepository
+	webhookRepo   repository.WebhookRepository
+	dailyCounters *service.DailyCounters
+	cacheService  *service.CacheService
+	cacheTTL      time.Duration
+}
+
+// NewDashboardUseCase creates a new dashboard use case. A cacheTTL of 0 disables caching.
+func NewDashboardUseCase(
+	userRepo repository.UserRepository,
+	documentRepo repository.DocumentRepository,
+	jobRepo repository.JobRepository,
+	webhookRepo repository.WebhookRepository,
+	dailyCounters *service.DailyCounters,
+	cacheService *service.CacheService,
+	cacheTTL time.Duration,
+) *DashboardUseCase {
+	return &DashboardUseCase{
+		userRepo:      userRepo,
+		documentRepo:  documentRepo,
+		jobRepo:       jobRepo,
+		webhookRepo:   webhookRepo,
+		dailyCounters: dailyCounters,
+		cacheService:  cacheService,
+		cacheTTL:      cacheTTL,
+	}
+}
+
+// Activity returns the daily signups, logins and document uploads of the last days
+func (uc *DashboardUseCase) Activity(ctx context.Context, days int) (*dto.DashboardActivityResponse, error) {
+	days = clampDays(days)
+	return cached(ctx, uc, "activity:"+strconv.Itoa(days), func(ctx context.Context) (*dto.DashboardActivityResponse, error) {
+		since := dashboardSince(days)
+
+		// Users and documents deleted since still count as signed up and uploaded
+		signups, err := uc.userRepo.CountCreatedPerDay(repository.WithDeleted(ctx), since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count signups: %w", err)
+		}
+		uploads, err := uc.documentRepo.CountCreatedPerDay(repository.WithDeleted(ctx), since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count uploads: %w", err)
+		}
+		logins, err := uc.dailyCounters.Series(ctx, service.DailyCounterLogins, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read login counters: %w", err)
+		}
+
+		return &dto.DashboardActivityResponse{
+			Days:        days,
+			Signups:     dto.ToDailyCountsResponse(signups, since),
+			Logins:      dto.ToDailyCountsResponse(logins, since),
+			Uploads:     dto.ToDailyCountsResponse(uploads, since),
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// StorageConsumers returns the limit users whose documents take the most storage
+func (uc *DashboardUseCase) StorageConsumers(ctx context.Context, limit int) (*dto.DashboardStorageResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	return cached(ctx, uc, "storage:"+strconv.Itoa(limit), func(ctx context.Context) (*dto.DashboardStorageResponse, error) {
+		usage, err := uc.documentRepo.TopStorageUsers(ctx, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find storage consumers: %w", err)
+		}
+
+		consumers := make([]dto.StorageConsumerResponse, len(usage))
+		for i, userUsage := range usage {
+			consumers[i] = dto.StorageConsumerResponse{
+				UserID:    userUsage.UserID,
+				Documents: userUsage.Documents,
+				Bytes:     userUsage.Bytes,
+			}
+
+			// Documents outlive their user until the user is purged
+			user, err := uc.userRepo.FindByID(repository.WithDeleted(ctx), userUsage.UserID)
+			if errors.Is(err, domain.ErrUserNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to find user: %w", err)
+			}
+			consumers[i].Email = user.Email
+			consumers[i].Name = user.Name
+		}
+
+		return &dto.DashboardStorageResponse{
+			Consumers:   consumers,
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// RateLimitRejections returns the daily rate limit rejections of the last days
+func (uc *DashboardUseCase) RateLimitRejections(ctx context.Context, days int) (*dto.DashboardRateLimitResponse, error) {
+	days = clampDays(days)
+	return cached(ctx, uc, "rate_limits:"+strconv.Itoa(days), func(ctx context.Context) (*dto.DashboardRateLimitResponse, error) {
+		since := dashboardSince(days)
+
+		rejections, err := uc.dailyCounters.Series(ctx, service.DailyCounterRateLimitRejections, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rate limit counters: %w", err)
+		}
+
+		return &dto.DashboardRateLimitResponse{
+			Days:        days,
+			Rejections:  dto.ToDailyCountsResponse(rejections, since),
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// JobQueue returns how many background jobs have each status
+func (uc *DashboardUseCase) JobQueue(ctx context.Context) (*dto.DashboardJobsResponse, error) {
+	return cached(ctx, uc, "jobs", func(ctx context.Context) (*dto.DashboardJobsResponse, error) {
+		counts := make(map[entity.JobStatus]int64, 4)
+		for _, status := range []entity.JobStatus{entity.JobStatusPending, entity.JobStatusRunning, entity.JobStatusSucceeded, entity.JobStatusDead} {
+			count, err := uc.jobRepo.Count(ctx, status)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count %s jobs: %w", status, err)
+			}
+			counts[status] = count
+		}
+
+		return &dto.DashboardJobsResponse{
+			Pending:     counts[entity.JobStatusPending],
+			Running:     counts[entity.JobStatusRunning],
+			Succeeded:   counts[entity.JobStatusSucceeded],
+			Dead:        counts[entity.JobStatusDead],
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// WebhookFailures returns the failure rate of the webhook deliveries of the last hours, and the
+// limit webhooks that failed most
+func (uc *DashboardUseCase) WebhookFailures(ctx context.Context, hours, limit int) (*dto.DashboardWebhooksResponse, error) {
+	if hours <= 0 || hours > 24*maxDashboardDays {
+		hours = 24
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	return cached(ctx, uc, fmt.Sprintf("webhooks:%d:%d", hours, limit), func(ctx context.Context) (*dto.DashboardWebhooksResponse, error) {
+		since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+		attempts, failed, err := uc.webhookRepo.CountDeliveriesSince(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+		stats, err := uc.webhookRepo.FailingWebhooks(ctx, since, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		failing := make([]dto.WebhookFailureResponse, len(stats))
+		for i, webhookStats := range stats {
+			failing[i] = dto.WebhookFailureResponse{
+				WebhookID:   webhookStats.WebhookID,
+				Attempts:    webhookStats.Attempts,
+				Failed:      webhookStats.Failed,
+				FailureRate: dto.FailureRate(webhookStats.Attempts, webhookStats.Failed),
+			}
+
+			webhook, err := uc.webhookRepo.FindByID(ctx, webhookStats.WebhookID)
+			if errors.Is(err, domain.ErrWebhookNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to find webhook: %w", err)
+			}
+			failing[i].UserID = webhook.UserID
+			failing[i].URL = webhook.URL
+		}
+
+		return &dto.DashboardWebhooksResponse{
+			Hours:       hours,
+			Attempts:    attempts,
+			Failed:      failed,
+			FailureRate: dto.FailureRate(attempts, failed),
+			Failing:     failing,
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// cached returns the figures of a dashboard panel from the cache, or loads and caches them. A
+// cache that can't be read or written only costs a reload.
+func cached[T any](ctx context.Context, uc *DashboardUseCase, panel string, load func(ctx context.Context) (*T, error)) (*T, error) {
+	if uc.cacheTTL <= 0 {
+		return load(ctx)
+	}
+
+	key := service.DashboardCacheKey(panel)
+	if value, err := uc.cacheService.GetString(ctx, key); err == nil && value != "" {
+		var response T
+		if err := json.Unmarshal([]byte(value), &response); err == nil {
+			return &response, nil
+		}
+	}
+
+	response, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.cacheService.Set(ctx, key, response, uc.cacheTTL); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("panel", panel).Debug("Failed to cache dashboard figures")
+	}
+	return response, nil
+}
+
+// clampDays defaults the days of a time series to 30 and caps them at what the counters keep
+func clampDays(days int) int {
+	if days <= 0 {
+		return 30
+	}
+	return min(days, maxDashboardDays)
+}
+
+// dashboardSince returns midnight UTC of the first of the last days, today included
+func dashboardSince(days int) time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+}
@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/storage"
+)
+
+// dataExportTemplate is the email template carrying the download link of a data export
+const dataExportTemplate = "data_export"
+
+// dataExportBatchSize is how many documents, audit log entries or activities are read at a time
+const dataExportBatchSize = 100
+
+// DataExportConfig configures the data exports of users
+type DataExportConfig struct {
+	// LinkTTL is how long the emailed download link works, after which the export is deleted
+	LinkTTL time.Duration
+}
+
+// UserDataExportPayload is the payload of JobExportUserData jobs
+type UserDataExportPayload struct {
+	UserID string `json:"user_id"`
+}
+
+// exportedDocument is a document of a data export, which tells the documents in the trash apart
+type exportedDocument struct {
+	*entity.Document
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// DataExportUseCase handles users exporting their data: their profile and settings, the metadata
+// of their documents, their audit history and their activity, assembled in the background into a
+// ZIP file whose download link is emailed to them
+type DataExportUseCase struct {
+	userRepo     repository.UserRepository
+	documentRepo repository.DocumentRepository
+	auditLogRepo repository.AuditLogRepository
+	activityRepo repository.ActivityRepository
+	settingsRepo repository.UserSettingsRepository
+	storage      *storage.S3Client
+	jobQueue     *service.JobQueue
+	emailService *service.EmailService
+	auditLog     *service.AuditLogService
+	config       DataExportConfig
+}
+
+// NewDataExportUseCase creates a new data export use case. Register Export as the function of
+// JobExportUserData jobs.
+func NewDataExportUseCase(
+	userRepo repository.UserRepository,
+	documentRepo repository.DocumentRepository,
+	auditLogRepo repository.AuditLogRepository,
+	activityRepo repository.ActivityRepository,
+	settingsRepo repository.UserSettingsRepository,
+	storage *storage.S3Client,
+	jobQueue *service.JobQueue,
+	emailService *service.EmailService,
+	auditLog *service.AuditLogService,
+	config DataExportConfig,
+) *DataExportUseCase {
+	return &DataExportUseCase{
+		userRepo:     userRepo,
+		documentRepo: documentRepo,
+		auditLogRepo: auditLogRepo,
+		activityRepo: activityRepo,
+		settingsRepo: settingsRepo,
+		storage:      storage,
+		jobQueue:     jobQueue,
+		emailService: emailService,
+		auditLog:     auditLog,
+		config:       config,
+	}
+}
+
+// Request enqueues the export of the user's data. The user is emailed once it is ready.
+func (uc *DataExportUseCase) Request(ctx context.Context, userID string) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := uc.jobQueue.Enqueue(ctx, JobExportUserData, UserDataExportPayload{UserID: user.ID}); err != nil {
+		return err
+	}
+
+	uc.auditLog.Record(ctx, entity.AuditActionDataExportRequested, entity.AuditTargetUser, user.ID, nil)
+	return nil
+}
+
+// Export assembles the user's data into a ZIP file, uploads it and emails its download link. It
+// runs JobExportUserData jobs. Users deleted since they asked are skipped, and an export uploaded
+// while the account was deleted is deleted again.
+func (uc *DataExportUseCase) Export(ctx context.Context, payload UserDataExportPayload) error {
+	user, err := uc.findExportedUser(ctx, payload.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	// Assembled on disk, since the documents and audit history of a user have no bound
+	file, err := os.CreateTemp("", "data-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := uc.writeArchive(ctx, file, user); err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read data export: %w", err)
+	}
+
+	filename := fmt.Sprintf("data-export-%s.zip", time.Now().UTC().Format("20060102"))
+	fileURL, err := uc.storage.UploadExport(ctx, user.ID, file, filename, "application/zip")
+	if err != nil {
+		return err
+	}
+	if user, err = uc.findExportedUser(ctx, user.ID); err != nil || user == nil {
+		if deleteErr := uc.storage.DeleteFile(ctx, *fileURL); deleteErr != nil {
+			return errors.Join(err, deleteErr)
+		}
+		return err
+	}
+	link, err := uc.storage.GetPresignedURL(ctx, *fileURL, uc.config.LinkTTL)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"URL":       *link,
+		"ExpiresIn": formatExpiry(uc.config.LinkTTL),
+	}
+	if err := uc.emailService.Send(ctx, user.Email, dataExportTemplate, data); err != nil {
+		return fmt.Errorf("failed to send data export email: %w", err)
+	}
+	return nil
+}
+
+// DeleteExports deletes the data exports of a deleted account from storage. It runs
+// JobDeleteUserExports jobs.
+func (uc *DataExportUseCase) DeleteExports(ctx context.Context, payload UserDataExportPayload) error {
+	return uc.storage.ListUserExports(ctx, payload.UserID, func(fileURLs []string) error {
+		for _, fileURL := range fileURLs {
+			if err := uc.storage.DeleteFile(ctx, fileURL); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// findExportedUser returns the user whose data is exported, or nil when its account was deleted
+func (uc *DataExportUseCase) findExportedUser(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) || (err == nil && user.IsAnonymized()) {
+		logging.FromContext(ctx).WithField("user_id", userID).Debug("Skipped data export of deleted user")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
+
+// PurgeExpired deletes the exports whose download links expired, and returns how many were
+// deleted
+func (uc *DataExportUseCase) PurgeExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := uc.storage.ListExports(ctx, time.Now().Add(-uc.config.LinkTTL), func(fileURLs []string) error {
+		for _, fileURL := range fileURLs {
+			if err := uc.storage.DeleteFile(ctx, fileURL); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// writeArchive writes the ZIP file of the user's data: profile.json, settings.json,
+// documents.json, audit_log.json with the actions the user took and those others took on its
+// account, and activity.json
+func (uc *DataExportUseCase) writeArchive(ctx context.Context, w io.Writer, user *entity.User) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("profile.json")
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, user); err != nil {
+		return err
+	}
+
+	settings, err := uc.settingsRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	f, err = archive.Create("settings.json")
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, settings); err != nil {
+		return err
+	}
+
+	f, err = archive.Create("documents.json")
+	if err != nil {
+		return err
+	}
+	if err := uc.writeDocuments(ctx, f, user.ID); err != nil {
+		return err
+	}
+
+	f, err = archive.Create("audit_log.json")
+	if err != nil {
+		return err
+	}
+	if err := uc.writeAuditLog(ctx, f, user.ID); err != nil {
+		return err
+	}
+
+	f, err = archive.Create("activity.json")
+	if err != nil {
+		return err
+	}
+	if err := uc.writeActivity(ctx, f, user.ID); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeDocuments writes the metadata of the user's documents, those in the trash included, as a
+// JSON array
+func (uc *DataExportUseCase) writeDocuments(ctx context.Context, w io.Writer, userID string) error {
+	ctx = repository.WithDeleted(ctx)
+	var after *repository.Cursor
+	return writeJSONArray(w, func() ([]any, bool, error) {
+		documents, err := uc.documentRepo.FindByUserIDAfter(ctx, userID, after, dataExportBatchSize)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		page := make([]any, len(documents))
+		for i, document := range documents {
+			exported := exportedDocument{Document: document}
+			if document.DeletedAt.Valid {
+				exported.DeletedAt = &document.DeletedAt.Time
+			}
+			page[i] = exported
+		}
+		if len(documents) < dataExportBatchSize {
+			return page, false, nil
+		}
+		last := documents[len(documents)-1]
+		cursor := repository.CursorOf(last.CreatedAt, last.ID)
+		after = &cursor
+		return page, true, nil
+	})
+}
+
+// writeAuditLog writes the audit log entries of the user as a JSON object: the actions it took,
+// and those others took on its account. The IPs and user agents of the others are left out.
+func (uc *DataExportUseCase) writeAuditLog(ctx context.Context, w io.Writer, userID string) error {
+	// Entries recorded while exporting would shift the pages
+	before := time.Now()
+
+	if _, err := io.WriteString(w, `{"actions":`); err != nil {
+		return err
+	}
+	err := uc.writeAuditLogEntries(ctx, w, repository.AuditLogFilter{ActorID: userID, Before: before}, func(entry *entity.AuditLog) bool {
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `,"account_history":`); err != nil {
+		return err
+	}
+	filter := repository.AuditLogFilter{TargetType: entity.AuditTargetUser, TargetID: userID, Before: before}
+	err = uc.writeAuditLogEntries(ctx, w, filter, func(entry *entity.AuditLog) bool {
+		// The user's own actions on its account are in the actions
+		if entry.ActorID != nil && *entry.ActorID == userID {
+			return false
+		}
+		entry.IP = ""
+		entry.UserAgent = ""
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+// writeAuditLogEntries writes the audit log entries matching the filter that keep reports true
+// for as a JSON array, newest first
+func (uc *DataExportUseCase) writeAuditLogEntries(ctx context.Context, w io.Writer, filter repository.AuditLogFilter, keep func(entry *entity.AuditLog) bool) error {
+	offset := 0
+	return writeJSONArray(w, func() ([]any, bool, error) {
+		entries, err := uc.auditLogRepo.List(ctx, filter, dataExportBatchSize, offset)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list audit log: %w", err)
+		}
+		offset += len(entries)
+
+		page := make([]any, 0, len(entries))
+		for _, entry := range entries {
+			if keep(entry) {
+				page = append(page, entry)
+			}
+		}
+		return page, len(entries) == dataExportBatchSize, nil
+	})
+}
+
+// writeActivity writes the user's activity as a JSON array, newest first
+func (uc *DataExportUseCase) writeActivity(ctx context.Context, w io.Writer, userID string) error {
+	// Activities recorded while exporting would shift the pages
+	filter := repository.ActivityFilter{UserID: userID, Before: time.Now()}
+	offset := 0
+	return writeJSONArray(w, func() ([]any, bool, error) {
+		activities, err := uc.activityRepo.List(ctx, filter, dataExportBatchSize, offset)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list activities: %w", err)
+		}
+		offset += len(activities)
+
+		page := make([]any, len(activities))
+		for i, activity := range activities {
+			page[i] = activity
+		}
+		return page, len(activities) == dataExportBatchSize, nil
+	})
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeJSONArray writes the records of the pages next returns as a JSON array, one record per
+// line, until it reports there are no more pages. Only one page is held in memory at a time.
+func writeJSONArray(w io.Writer, next func() (page []any, more bool, err error)) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	separator := "\n"
+	for more := true; more; {
+		page, hasMore, err := next()
+		if err != nil {
+			return err
+		}
+		more = hasMore
+
+		for _, record := range page {
+			encoded, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+			if _, err := w.Write(encoded); err != nil {
+				return err
+			}
+			separator = ",\n"
+		}
+	}
+	_, err := io.WriteString(w, "\n]")
+	return err
+}
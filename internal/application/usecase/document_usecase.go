@@ -1,27 +1,55 @@
 package usecase
 
 import (
+	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"io"
 	"mime/multipart"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
-	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/application/dto"
 	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 	"gin-boilerplate/internal/infrastructure/storage"
 )
 
 type DocumentUseCase struct {
-	documentRepo repository.DocumentRepository
-	storage      *storage.S3Client
+	documentRepo   repository.DocumentRepository
+	unitOfWork     repository.UnitOfWork
+	storage        *storage.S3Client
+	quotaService   *service.QuotaService
+	eventBus       *service.EventBus
+	webhookService *service.WebhookService
+	jobQueue       *service.JobQueue
+	auditLog       *service.AuditLogService
+	activities     *service.ActivityService
+	virusScan      *service.VirusScanService
+	// searchIndexer answers searches in place of Postgres when set
+	searchIndexer service.SearchIndexer
 }
 
-func NewDocumentUseCase(documentRepo repository.DocumentRepository, storage *storage.S3Client) *DocumentUseCase {
+func NewDocumentUseCase(documentRepo repository.DocumentRepository, unitOfWork repository.UnitOfWork, storage *storage.S3Client, quotaService *service.QuotaService, eventBus *service.EventBus, webhookService *service.WebhookService, jobQueue *service.JobQueue, auditLog *service.AuditLogService, activities *service.ActivityService, virusScan *service.VirusScanService, searchIndexer service.SearchIndexer) *DocumentUseCase {
 	return &DocumentUseCase{
-		documentRepo: documentRepo,
-		storage:      storage,
+		documentRepo:   documentRepo,
+		unitOfWork:     unitOfWork,
+		storage:        storage,
+		quotaService:   quotaService,
+		eventBus:       eventBus,
+		webhookService: webhookService,
+		jobQueue:       jobQueue,
+		auditLog:       auditLog,
+		activities:     activities,
+		virusScan:      virusScan,
+		searchIndexer:  searchIndexer,
 	}
 }
 
@@ -40,16 +68,33 @@ type DocumentResponse struct {
 	FileName    string `json:"file_name"`
 	FileSize    int64  `json:"file_size"`
 	ContentType string `json:"content_type"`
+	ScanStatus  string `json:"scan_status"`
 	UserID      string `json:"user_id"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
 }
 
+// DocumentSearchResult is a document matching a search. TitleHighlight and Snippet are HTML, the
+// matching words in <mark> elements and the rest escaped.
+type DocumentSearchResult struct {
+	*DocumentResponse
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// maxSearchQueryLength bounds the length of a search query
+const maxSearchQueryLength = 256
+
+// maxExtractedText bounds the text extracted from a file for search, well below the 1MB a
+// Postgres tsvector can hold
+const maxExtractedText = 64 * 1024
+
 func (uc *DocumentUseCase) UploadDocument(ctx context.Context, req *UploadDocumentRequest) (*DocumentResponse, error) {
 	// Validate file size (max 10MB)
 	const maxFileSize = 10 * 1024 * 1024
 	if req.File.Size > maxFileSize {
-		return nil, domain.ErrFileTooLarge
+		return nil, domain.ErrFileTooLarge.WithMessage("File too large (max 10MB)")
 	}
 
 	// Validate file type
@@ -58,6 +103,18 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, req *UploadDocume
 		return nil, domain.ErrInvalidFileType
 	}
 
+	// Scan the file for malware, rejecting infected files
+	scanStatus, err := uc.virusScan.ScanUpload(ctx, req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the text of the file for full-text search
+	extractedText, err := extractText(req.File)
+	if err != nil {
+		return nil, err
+	}
+
 	// Open the uploaded file
 	file, err := req.File.Open()
 	if err != nil {
@@ -68,7 +125,7 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, req *UploadDocume
 	// Upload file to S3
 	fileURL, err := uc.storage.UploadFile(ctx, file, req.File.Filename, req.File.Header.Get("Content-Type"))
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", domain.ErrFileUploadFailed, err)
+		return nil, domain.ErrFileUploadFailed.Wrap(err)
 	}
 
 	// Create document entity
@@ -81,26 +138,41 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, req *UploadDocume
 		req.File.Header.Get("Content-Type"),
 		req.UserID,
 	)
+	document.ExtractedText = extractedText
+	document.ScanStatus = scanStatus
 
 	// Validate document
 	if err := document.Validate(); err != nil {
 		// If validation fails, try to delete the uploaded file
 		if fileURL != nil {
-			uc.storage.DeleteFile(ctx, *fileURL)
+			uc.storage.DeleteFile(context.WithoutCancel(ctx), *fileURL)
 		}
 		return nil, err
 	}
 
-	// Save document to database
-	if err := uc.documentRepo.Create(ctx, document); err != nil {
-		// If database save fails, try to delete the uploaded file
+	// Save document to database, and index it once the save is committed
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.documentRepo.Create(ctx, document); err != nil {
+			return fmt.Errorf("failed to save document: %w", err)
+		}
+		return uc.enqueueIndexing(ctx, document.ID)
+	})
+	if err != nil {
+		// If database save fails, try to delete the uploaded file, even if the request deadline has passed
 		if fileURL != nil {
-			uc.storage.DeleteFile(ctx, *fileURL)
+			uc.storage.DeleteFile(context.WithoutCancel(ctx), *fileURL)
 		}
-		return nil, fmt.Errorf("failed to save document: %w", err)
+		return nil, err
 	}
 
-	return uc.toDocumentResponse(document), nil
+	response := uc.toDocumentResponse(document)
+	uc.publish(ctx, req.UserID, "document.created", response)
+	uc.activities.Record(ctx, req.UserID, entity.ActivityDocumentUploaded, map[string]string{
+		"document_id": document.ID,
+		"title":       document.Title,
+	})
+
+	return response, nil
 }
 
 func (uc *DocumentUseCase) GetDocument(ctx context.Context, id, userID string) (*DocumentResponse, error) {
@@ -117,10 +189,16 @@ func (uc *DocumentUseCase) GetDocument(ctx context.Context, id, userID string) (
 	return uc.toDocumentResponse(document), nil
 }
 
-func (uc *DocumentUseCase) GetUserDocuments(ctx context.Context, userID string, limit, offset int) ([]*DocumentResponse, error) {
+// GetUserDocuments returns a page of the user's documents and the total number of their documents
+func (uc *DocumentUseCase) GetUserDocuments(ctx context.Context, userID string, limit, offset int) ([]*DocumentResponse, int64, error) {
 	documents, err := uc.documentRepo.FindByUserID(ctx, userID, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user documents: %w", err)
+		return nil, 0, fmt.Errorf("failed to find user documents: %w", err)
+	}
+
+	total, err := uc.documentRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count user documents: %w", err)
 	}
 
 	responses := make([]*DocumentResponse, len(documents))
@@ -128,7 +206,117 @@ func (uc *DocumentUseCase) GetUserDocuments(ctx context.Context, userID string,
 		responses[i] = uc.toDocumentResponse(doc)
 	}
 
-	return responses, nil
+	return responses, total, nil
+}
+
+// GetUserDocumentsAfter returns a keyset page of a user's documents after the cursor token, and
+// the token of the next page, empty on the last page
+func (uc *DocumentUseCase) GetUserDocumentsAfter(ctx context.Context, userID, cursor string, limit int) ([]*DocumentResponse, string, error) {
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One more document than the page tells whether there is a next page
+	documents, err := uc.documentRepo.FindByUserIDAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find user documents: %w", err)
+	}
+
+	nextCursor := ""
+	if len(documents) > limit {
+		documents = documents[:limit]
+		last := documents[len(documents)-1]
+		nextCursor = dto.EncodeCursor(repository.CursorOf(last.CreatedAt, last.ID))
+	}
+
+	responses := make([]*DocumentResponse, len(documents))
+	for i, doc := range documents {
+		responses[i] = uc.toDocumentResponse(doc)
+	}
+
+	return responses, nextCursor, nil
+}
+
+// SearchDocuments returns a page of the user's documents matching the query, the best matches
+// first, and the total number of matches
+func (uc *DocumentUseCase) SearchDocuments(ctx context.Context, userID, query string, limit, offset int) ([]*DocumentSearchResult, int64, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, domain.ErrValidation.WithMessage("The search query q is required")
+	}
+	if len(query) > maxSearchQueryLength {
+		return nil, 0, domain.ErrValidation.WithMessage(fmt.Sprintf("The search query q must be at most %d characters", maxSearchQueryLength))
+	}
+
+	if uc.searchIndexer != nil {
+		return uc.searchIndex(ctx, userID, query, limit, offset)
+	}
+
+	matches, err := uc.documentRepo.Search(ctx, userID, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	total, err := uc.documentRepo.CountSearch(ctx, userID, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching documents: %w", err)
+	}
+
+	results := make([]*DocumentSearchResult, len(matches))
+	for i, match := range matches {
+		results[i] = &DocumentSearchResult{
+			DocumentResponse: uc.toDocumentResponse(match.Document),
+			Rank:             match.Rank,
+			TitleHighlight:   highlightHTML(match.TitleHighlight),
+			Snippet:          highlightHTML(match.Snippet),
+		}
+	}
+
+	return results, total, nil
+}
+
+// searchIndex searches the external search index. The documents found are read from the
+// database, leaving out those deleted since they were indexed.
+func (uc *DocumentUseCase) searchIndex(ctx context.Context, userID, query string, limit, offset int) ([]*DocumentSearchResult, int64, error) {
+	hits, total, err := uc.searchIndexer.Search(ctx, userID, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.DocumentID
+	}
+	documents, err := uc.documentRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find documents: %w", err)
+	}
+	byID := make(map[string]*entity.Document, len(documents))
+	for _, document := range documents {
+		byID[document.ID] = document
+	}
+
+	results := make([]*DocumentSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		document, ok := byID[hit.DocumentID]
+		if !ok || document.UserID != userID {
+			continue
+		}
+		// The title is only highlighted when it matches
+		titleHighlight := hit.TitleHighlight
+		if titleHighlight == "" {
+			titleHighlight = document.Title
+		}
+		results = append(results, &DocumentSearchResult{
+			DocumentResponse: uc.toDocumentResponse(document),
+			Rank:             hit.Rank,
+			TitleHighlight:   highlightHTML(titleHighlight),
+			Snippet:          highlightHTML(hit.Snippet),
+		})
+	}
+
+	return results, total, nil
 }
 
 func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, id, userID, title, description string) (*DocumentResponse, error) {
@@ -150,12 +338,21 @@ func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, id, userID, title
 		return nil, err
 	}
 
-	// Save to database
-	if err := uc.documentRepo.Update(ctx, document); err != nil {
-		return nil, fmt.Errorf("failed to update document: %w", err)
+	// Save to database, and reindex it once the save is committed
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.documentRepo.Update(ctx, document); err != nil {
+			return fmt.Errorf("failed to update document: %w", err)
+		}
+		return uc.enqueueIndexing(ctx, document.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return uc.toDocumentResponse(document), nil
+	response := uc.toDocumentResponse(document)
+	uc.publish(ctx, userID, "document.updated", response)
+
+	return response, nil
 }
 
 func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, id, userID string) error {
@@ -169,20 +366,69 @@ func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, id, userID string
 		return domain.ErrDocumentNotFound
 	}
 
-	// Delete file from storage
-	if err := uc.storage.DeleteFile(ctx, document.FileURL); err != nil {
-		// Log error but continue with database deletion
-		fmt.Printf("Warning: failed to delete file from storage: %v\n", err)
+	// Delete from database, and the file from storage and the search index once the deletion is
+	// committed
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.documentRepo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
+		if err := uc.jobQueue.Enqueue(ctx, JobDeleteDocumentFile, StoredFilePayload{URL: document.FileURL}); err != nil {
+			return err
+		}
+		return uc.enqueueIndexing(ctx, id)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Delete from database
-	if err := uc.documentRepo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
-	}
+	uc.publish(ctx, userID, "document.deleted", map[string]string{"id": id})
+	uc.auditLog.Record(ctx, entity.AuditActionDocumentDeleted, entity.AuditTargetDocument, id, map[string]string{"title": document.Title})
 
 	return nil
 }
 
+// DeleteFile deletes the file of a deleted document from storage. It runs JobDeleteDocumentFile
+// jobs.
+func (uc *DocumentUseCase) DeleteFile(ctx context.Context, payload StoredFilePayload) error {
+	return uc.storage.DeleteFile(ctx, payload.URL)
+}
+
+// IndexDocument brings the entry of a document in the external search index up to date: the
+// document is indexed while it exists, and removed from the index once deleted. It runs
+// JobIndexDocument jobs.
+func (uc *DocumentUseCase) IndexDocument(ctx context.Context, payload DocumentIndexPayload) error {
+	document, err := uc.documentRepo.FindByID(ctx, payload.DocumentID)
+	if errors.Is(err, domain.ErrDocumentNotFound) {
+		return uc.searchIndexer.Delete(ctx, payload.DocumentID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find document: %w", err)
+	}
+	return uc.searchIndexer.Index(ctx, document)
+}
+
+// enqueueIndexing enqueues bringing the entry of a document in the external search index up to
+// date, when there is one. The job reads the document when it runs, so jobs of the same document
+// running in any order leave its entry current.
+func (uc *DocumentUseCase) enqueueIndexing(ctx context.Context, documentID string) error {
+	if uc.searchIndexer == nil {
+		return nil
+	}
+	return uc.jobQueue.Enqueue(ctx, JobIndexDocument, DocumentIndexPayload{DocumentID: documentID})
+}
+
+// publish notifies the user's live connections and webhooks; the change itself has already
+// succeeded
+func (uc *DocumentUseCase) publish(ctx context.Context, userID, eventType string, data interface{}) {
+	ctx = context.WithoutCancel(ctx)
+	if err := uc.eventBus.Publish(ctx, userID, eventType, data); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to publish event")
+	}
+	if err := uc.webhookService.Dispatch(ctx, userID, eventType, data); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event_type", eventType).Warn("Failed to dispatch event to webhooks")
+	}
+}
+
 func (uc *DocumentUseCase) GetPresignedURL(ctx context.Context, id, userID string) (*string, error) {
 	document, err := uc.documentRepo.FindByID(ctx, id)
 	if err != nil {
@@ -194,10 +440,152 @@ func (uc *DocumentUseCase) GetPresignedURL(ctx context.Context, id, userID strin
 		return nil, domain.ErrDocumentNotFound
 	}
 
+	// Count the download against the user's monthly transfer quota
+	if uc.quotaService != nil {
+		if _, err := uc.quotaService.Consume(ctx, userID, entity.QuotaMetricDownloadBytesMonthly, document.FileSize); err != nil {
+			if errors.Is(err, domain.ErrQuotaExceeded) {
+				return nil, err
+			}
+			// Don't block downloads when usage can't be recorded
+		}
+	}
+
 	// Generate presigned URL (valid for 1 hour)
 	return uc.storage.GetPresignedURL(ctx, document.FileURL, time.Hour)
 }
 
+// MaxZipDocuments bounds the documents downloaded in one ZIP archive
+const MaxZipDocuments = 100
+
+// zipErrorsFile is the entry of a ZIP archive listing the documents whose file couldn't be read
+const zipErrorsFile = "errors.txt"
+
+// zipStoredTypes are the content types of already compressed files, stored in ZIP archives as
+// they are rather than compressed again
+var zipStoredTypes = []string{"image/jpeg", "image/png", "image/gif", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+
+// DownloadDocumentsZip writes a ZIP archive of the files of the user's documents, in the order of
+// the IDs, to the writer open returns. Files are streamed from storage into the archive as it is
+// written, and nothing is written when a document isn't found. A file missing from storage or
+// failing to open is left out and listed in an errors.txt entry instead of failing the archive;
+// any other failure cuts the archive short, which makes it invalid.
+func (uc *DocumentUseCase) DownloadDocumentsZip(ctx context.Context, userID string, documentIDs []string, open func() io.Writer) error {
+	ids := []string{}
+	for _, id := range documentIDs {
+		if !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > MaxZipDocuments {
+		return domain.ErrValidation.WithMessage(fmt.Sprintf("Between 1 and %d documents can be downloaded at once", MaxZipDocuments))
+	}
+
+	found, err := uc.documentRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to find documents: %w", err)
+	}
+	documents := make([]*entity.Document, 0, len(ids))
+	var totalSize int64
+	for _, id := range ids {
+		i := slices.IndexFunc(found, func(document *entity.Document) bool { return document.ID == id })
+		// Other users' documents are reported as not found, as by GetDocument
+		if i == -1 || found[i].UserID != userID {
+			return domain.ErrDocumentNotFound
+		}
+		documents = append(documents, found[i])
+		totalSize += found[i].FileSize
+	}
+
+	// Count the download against the user's monthly transfer quota
+	if uc.quotaService != nil {
+		if _, err := uc.quotaService.Consume(ctx, userID, entity.QuotaMetricDownloadBytesMonthly, totalSize); err != nil {
+			if errors.Is(err, domain.ErrQuotaExceeded) {
+				return err
+			}
+			// Don't block downloads when usage can't be recorded
+		}
+	}
+
+	archive := zip.NewWriter(open())
+	names := map[string]bool{}
+	failures := []string{}
+	for _, document := range documents {
+		name := zipEntryName(names, document.FileName, document.ID)
+
+		file, err := uc.storage.OpenFile(ctx, document.FileURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logging.FromContext(ctx).WithError(err).WithField("document_id", document.ID).Warn("Left a document out of a ZIP download")
+			reason := "the file could not be read"
+			if errors.Is(err, storage.ErrFileNotFound) {
+				reason = "the file is missing"
+			}
+			failures = append(failures, fmt.Sprintf("%s (document %s): %s", name, document.ID, reason))
+			continue
+		}
+
+		err = writeZipEntry(archive, name, document, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s to ZIP archive: %w", name, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     zipEntryName(names, zipErrorsFile, zipErrorsFile),
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, strings.Join(failures, "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// writeZipEntry copies a document's file into a new entry of the archive
+func writeZipEntry(archive *zip.Writer, name string, document *entity.Document, file io.Reader) error {
+	method := zip.Deflate
+	if contains(zipStoredTypes, document.ContentType) {
+		method = zip.Store
+	}
+	f, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: document.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, file)
+	return err
+}
+
+// zipEntryName returns a name for a file in a ZIP archive that no other entry has, numbering it
+// like "report (2).pdf" when taken, and records it in names. Directories are dropped from the
+// file name, and fallback is used when nothing is left.
+func zipEntryName(names map[string]bool, fileName, fallback string) string {
+	base := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if base == "." || base == "/" || base == ".." {
+		base = fallback
+	}
+
+	name := base
+	ext := path.Ext(base)
+	for n := 2; names[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(base, ext), n, ext)
+	}
+	names[strings.ToLower(name)] = true
+	return name
+}
+
 func (uc *DocumentUseCase) toDocumentResponse(doc *entity.Document) *DocumentResponse {
 	return &DocumentResponse{
 		ID:          doc.ID,
@@ -207,12 +595,43 @@ func (uc *DocumentUseCase) toDocumentResponse(doc *entity.Document) *DocumentRes
 		FileName:    doc.FileName,
 		FileSize:    doc.FileSize,
 		ContentType: doc.ContentType,
+		ScanStatus:  string(doc.ScanStatus),
 		UserID:      doc.UserID,
 		CreatedAt:   doc.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   doc.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
+// highlightHTML escapes a search highlight for HTML, with the matching words in <mark> elements
+func highlightHTML(text string) string {
+	return strings.NewReplacer(
+		repository.SearchHighlightStart, "<mark>",
+		repository.SearchHighlightStop, "</mark>",
+	).Replace(html.EscapeString(text))
+}
+
+// extractText returns the text of a plain-text file for full-text search, up to
+// maxExtractedText, and "" for other types
+func extractText(fileHeader *multipart.FileHeader) (string, error) {
+	if !strings.EqualFold(fileHeader.Header.Get("Content-Type"), "text/plain") {
+		return "", nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxExtractedText))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Postgres text can't hold NUL bytes or invalid UTF-8, such as a character the limit cut
+	return strings.ReplaceAll(strings.ToValidUTF8(string(data), ""), "\x00", ""), nil
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if strings.ToLower(s) == strings.ToLower(item) {
@@ -220,4 +639,4 @@ func contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
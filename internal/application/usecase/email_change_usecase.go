@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// Email templates of the email change flow
+const (
+	// emailChangeTemplate carries the confirmation link to the new address
+	emailChangeTemplate = "email_change"
+	// emailChangeRequestedTemplate tells the current address about a requested change, with a
+	// link cancelling it
+	emailChangeRequestedTemplate = "email_change_requested"
+	// emailChangedTemplate tells the previous address about a confirmed change, with a link
+	// undoing it
+	emailChangedTemplate = "email_changed"
+)
+
+// EmailChangeConfig configures the email change links
+type EmailChangeConfig struct {
+	// URL is the frontend page that confirms the new address; the link adds a token parameter
+	URL string
+	// RevertURL is the frontend page that cancels or undoes a change; the link adds a token
+	// parameter
+	RevertURL string
+	// TokenTTL is how long a confirmation link can be used
+	TokenTTL time.Duration
+	// RollbackWindow is how long the previous address can undo a confirmed change
+	RollbackWindow time.Duration
+}
+
+// RequestEmailChangeUseCase starts changing the email of a user. The email stays the same until
+// the new address is confirmed.
+type RequestEmailChangeUseCase struct {
+	userRepo        repository.UserRepository
+	unitOfWork      repository.UnitOfWork
+	passwordService service.PasswordService
+	actionTokens    *service.ActionTokenService
+	emailService    *service.EmailService
+	// disposableEmails rejects addresses of disposable email providers; nil accepts them
+	disposableEmails service.DisposableEmailService
+	config           EmailChangeConfig
+}
+
+// NewRequestEmailChangeUseCase creates a new request email change use case. disposableEmails
+// may be nil.
+func NewRequestEmailChangeUseCase(
+	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	actionTokens *service.ActionTokenService,
+	emailService *service.EmailService,
+	disposableEmails service.DisposableEmailService,
+	config EmailChangeConfig,
+) *RequestEmailChangeUseCase {
+	return &RequestEmailChangeUseCase{
+		userRepo:         userRepo,
+		unitOfWork:       unitOfWork,
+		passwordService:  passwordService,
+		actionTokens:     actionTokens,
+		emailService:     emailService,
+		disposableEmails: disposableEmails,
+		config:           config,
+	}
+}
+
+// Execute emails a confirmation link to the new address after checking the password, and tells
+// the current address, with a link cancelling the change. Earlier confirmation links of the user
+// stop working.
+func (uc *RequestEmailChangeUseCase) Execute(ctx context.Context, userID string, req dto.ChangeEmailRequest) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	// The email of users without a password comes from their provider
+	if !user.HasPassword() {
+		return domain.ErrOAuthRequired
+	}
+
+	if err := uc.passwordService.VerifyPassword(req.Password, *user.Password); err != nil {
+		return domain.ErrIncorrectPassword
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if newEmail == user.Email {
+		return domain.ErrEmailUnchanged
+	}
+	if uc.disposableEmails != nil && uc.disposableEmails.IsDisposable(newEmail) {
+		return domain.ErrDisposableEmail
+	}
+	if err := ensureEmailAvailable(ctx, uc.userRepo, user.ID, newEmail); err != nil {
+		return err
+	}
+
+	var confirmToken, cancelToken string
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.actionTokens.Revoke(ctx, user.ID, entity.ActionTokenEmailChange); err != nil {
+			return fmt.Errorf("failed to revoke email change tokens: %w", err)
+		}
+
+		confirmToken, err = uc.actionTokens.IssueForEmail(ctx, user.ID, entity.ActionTokenEmailChange, newEmail, uc.config.TokenTTL)
+		if err != nil {
+			return fmt.Errorf("failed to issue email change token: %w", err)
+		}
+		cancelToken, err = uc.actionTokens.IssueForEmail(ctx, user.ID, entity.ActionTokenEmailChangeRevert, user.Email, uc.config.TokenTTL)
+		if err != nil {
+			return fmt.Errorf("failed to issue email change revert token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	confirmLink, err := actionLink(uc.config.URL, confirmToken)
+	if err != nil {
+		return fmt.Errorf("invalid email change URL: %w", err)
+	}
+	cancelLink, err := actionLink(uc.config.RevertURL, cancelToken)
+	if err != nil {
+		return fmt.Errorf("invalid email change revert URL: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"Email":     newEmail,
+		"URL":       confirmLink,
+		"ExpiresIn": formatExpiry(uc.config.TokenTTL),
+	}
+	if err := uc.emailService.Send(ctx, newEmail, emailChangeTemplate, data); err != nil {
+		return fmt.Errorf("failed to send email change email: %w", err)
+	}
+
+	data["URL"] = cancelLink
+	if err := uc.emailService.Send(ctx, user.Email, emailChangeRequestedTemplate, data); err != nil {
+		return fmt.Errorf("failed to send email change notice: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChangeUseCase changes the email of a user with the token of a confirmation link
+type ConfirmEmailChangeUseCase struct {
+	userRepo       repository.UserRepository
+	unitOfWork     repository.UnitOfWork
+	actionTokens   *service.ActionTokenService
+	emailService   *service.EmailService
+	securityEvents *service.SecurityEventService
+	config         EmailChangeConfig
+}
+
+// NewConfirmEmailChangeUseCase creates a new confirm email change use case. securityEvents may
+// be nil.
+func NewConfirmEmailChangeUseCase(
+	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
+	actionTokens *service.ActionTokenService,
+	emailService *service.EmailService,
+	securityEvents *service.SecurityEventService,
+	config EmailChangeConfig,
+) *ConfirmEmailChangeUseCase {
+	return &ConfirmEmailChangeUseCase{
+		userRepo:       userRepo,
+		unitOfWork:     unitOfWork,
+		actionTokens:   actionTokens,
+		emailService:   emailService,
+		securityEvents: securityEvents,
+		config:         config,
+	}
+}
+
+// Execute sets the token's address as the user's verified email and returns the user. The
+// previous address is emailed a link undoing the change, which works for the rollback window.
+func (uc *ConfirmEmailChangeUseCase) Execute(ctx context.Context, req dto.EmailChangeTokenRequest) (*dto.UserResponse, error) {
+	var (
+		user          *entity.User
+		previousEmail string
+		revertToken   string
+	)
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token, err := uc.actionTokens.Consume(ctx, entity.ActionTokenEmailChange, req.Token)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume email change token: %w", err)
+		}
+
+		user, err = uc.userRepo.FindByID(ctx, token.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+
+		// The address may have been taken since the change was requested
+		if err := ensureEmailAvailable(ctx, uc.userRepo, user.ID, token.Email); err != nil {
+			return err
+		}
+
+		previousEmail = user.Email
+		user.ChangeEmail(token.Email)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		revertToken, err = uc.actionTokens.IssueForEmail(ctx, user.ID, entity.ActionTokenEmailChangeRevert, previousEmail, uc.config.RollbackWindow)
+		if err != nil {
+			return fmt.Errorf("failed to issue email change revert token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.securityEvents != nil {
+		details := map[string]string{"previous_email": previousEmail}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventEmailChanged, req.ClientIP, req.UserAgent, details)
+	}
+
+	// The email is changed either way, so failing to tell the previous address is only logged
+	if err := uc.notifyPreviousEmail(ctx, user, previousEmail, revertToken); err != nil {
+		logging.ModuleFromContext(ctx, logging.ModuleAuth).
+			WithError(err).
+			WithField("user_id", user.ID).
+			Warn("Failed to send email changed notice")
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// notifyPreviousEmail emails the previous address that the email was changed, with a link
+// undoing the change
+func (uc *ConfirmEmailChangeUseCase) notifyPreviousEmail(ctx context.Context, user *entity.User, previousEmail, revertToken string) error {
+	link, err := actionLink(uc.config.RevertURL, revertToken)
+	if err != nil {
+		return fmt.Errorf("invalid email change revert URL: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"Email":     user.Email,
+		"URL":       link,
+		"ExpiresIn": formatExpiry(uc.config.RollbackWindow),
+	}
+	return uc.emailService.Send(ctx, previousEmail, emailChangedTemplate, data)
+}
+
+// RevertEmailChangeUseCase cancels or undoes an email change with the token of a link sent to
+// the previous address
+type RevertEmailChangeUseCase struct {
+	userRepo       repository.UserRepository
+	tokenRepo      repository.TokenRepository
+	unitOfWork     repository.UnitOfWork
+	actionTokens   *service.ActionTokenService
+	securityEvents *service.SecurityEventService
+	// denylist rejects the access tokens of the sessions signed out by an undone change; nil
+	// leaves them valid until they expire
+	denylist *service.TokenDenylistService
+}
+
+// NewRevertEmailChangeUseCase creates a new revert email change use case. securityEvents and
+// denylist may be nil.
+func NewRevertEmailChangeUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
+	actionTokens *service.ActionTokenService,
+	securityEvents *service.SecurityEventService,
+	denylist *service.TokenDenylistService,
+) *RevertEmailChangeUseCase {
+	return &RevertEmailChangeUseCase{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		unitOfWork:     unitOfWork,
+		actionTokens:   actionTokens,
+		securityEvents: securityEvents,
+		denylist:       denylist,
+	}
+}
+
+// Execute cancels the user's pending email change and disables the user's other revert links.
+// When the change was already confirmed, it restores the token's address and signs out every
+// session, since whoever changed the email may have been signed in. It returns the user.
+func (uc *RevertEmailChangeUseCase) Execute(ctx context.Context, req dto.EmailChangeTokenRequest) (*dto.UserResponse, error) {
+	var (
+		user          *entity.User
+		previousEmail string
+		restored      bool
+	)
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token, err := uc.actionTokens.Consume(ctx, entity.ActionTokenEmailChangeRevert, req.Token)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume email change revert token: %w", err)
+		}
+
+		user, err = uc.userRepo.FindByID(ctx, token.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+
+		if err := uc.actionTokens.Revoke(ctx, user.ID, entity.ActionTokenEmailChange); err != nil {
+			return fmt.Errorf("failed to revoke email change tokens: %w", err)
+		}
+		if err := uc.actionTokens.Revoke(ctx, user.ID, entity.ActionTokenEmailChangeRevert); err != nil {
+			return fmt.Errorf("failed to revoke email change revert tokens: %w", err)
+		}
+
+		if user.Email == token.Email {
+			// The change wasn't confirmed, cancelling it is enough
+			return nil
+		}
+
+		if err := ensureEmailAvailable(ctx, uc.userRepo, user.ID, token.Email); err != nil {
+			return err
+		}
+
+		previousEmail = user.Email
+		user.ChangeEmail(token.Email)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		restored = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restored {
+		// The email is restored either way, so failing to deny the access tokens is only logged
+		if uc.denylist != nil {
+			if err := uc.denylist.DenyUser(ctx, user.ID); err != nil {
+				logging.ModuleFromContext(ctx, logging.ModuleAuth).
+					WithError(err).
+					WithField("user_id", user.ID).
+					Warn("Failed to deny access tokens")
+			}
+		}
+
+		if uc.securityEvents != nil {
+			details := map[string]string{"previous_email": previousEmail, "reverted": "true"}
+			uc.securityEvents.Record(ctx, user, entity.SecurityEventEmailChanged, req.ClientIP, req.UserAgent, details)
+		}
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// ensureEmailAvailable returns domain.ErrEmailAlreadyExists when a user other than userID has
+// the email
+func ensureEmailAvailable(ctx context.Context, userRepo repository.UserRepository, userID, email string) error {
+	existing, err := userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if existing.ID != userID {
+		return domain.ErrEmailAlreadyExists
+	}
+	return nil
+}
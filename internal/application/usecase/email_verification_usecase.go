@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// emailVerificationTemplate is the email template carrying an email verification link
+const emailVerificationTemplate = "email_verification"
+
+// EmailVerificationConfig configures the email verification links
+type EmailVerificationConfig struct {
+	// URL is the frontend page that verifies the email; the link adds a token parameter
+	URL string
+	// TokenTTL is how long a link can be used
+	TokenTTL time.Duration
+	// Required blocks logins of local accounts until their email is verified
+	Required bool
+}
+
+// SendVerificationUseCase emails email verification links to local users
+type SendVerificationUseCase struct {
+	userRepo     repository.UserRepository
+	actionTokens *service.ActionTokenService
+	emailService *service.EmailService
+	config       EmailVerificationConfig
+}
+
+// NewSendVerificationUseCase creates a new send verification use case
+func NewSendVerificationUseCase(
+	userRepo repository.UserRepository,
+	actionTokens *service.ActionTokenService,
+	emailService *service.EmailService,
+	config EmailVerificationConfig,
+) *SendVerificationUseCase {
+	return &SendVerificationUseCase{
+		userRepo:     userRepo,
+		actionTokens: actionTokens,
+		emailService: emailService,
+		config:       config,
+	}
+}
+
+// Required reports whether logins are blocked until the email is verified
+func (uc *SendVerificationUseCase) Required() bool {
+	return uc.config.Required
+}
+
+// Execute emails a verification link to the user. Earlier links of the user stop working.
+func (uc *SendVerificationUseCase) Execute(ctx context.Context, user *entity.User) error {
+	token, err := uc.actionTokens.Issue(ctx, user.ID, entity.ActionTokenEmailVerification, uc.config.TokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to issue email verification token: %w", err)
+	}
+
+	link, err := actionLink(uc.config.URL, token)
+	if err != nil {
+		return fmt.Errorf("invalid email verification URL: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"Email":     user.Email,
+		"URL":       link,
+		"ExpiresIn": formatExpiry(uc.config.TokenTTL),
+	}
+	if err := uc.emailService.Send(ctx, user.Email, emailVerificationTemplate, data); err != nil {
+		return fmt.Errorf("failed to send email verification email: %w", err)
+	}
+	return nil
+}
+
+// Resend emails a new verification link to the user with the email. It succeeds whether or not
+// there is such an unverified user, so the endpoint doesn't tell which emails have accounts.
+func (uc *SendVerificationUseCase) Resend(ctx context.Context, req dto.ResendVerificationRequest) error {
+	logger := logging.ModuleFromContext(ctx, logging.ModuleAuth).WithField("email", req.Email)
+
+	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		logger.Debug("Email verification requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.EmailVerified {
+		logger.Debug("Email verification requested for verified email")
+		return nil
+	}
+
+	return uc.Execute(ctx, user)
+}
+
+// VerifyEmailUseCase marks the email of a user verified with the token of a verification link
+type VerifyEmailUseCase struct {
+	userRepo     repository.UserRepository
+	unitOfWork   repository.UnitOfWork
+	actionTokens *service.ActionTokenService
+}
+
+// NewVerifyEmailUseCase creates a new verify email use case
+func NewVerifyEmailUseCase(
+	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
+	actionTokens *service.ActionTokenService,
+) *VerifyEmailUseCase {
+	return &VerifyEmailUseCase{
+		userRepo:     userRepo,
+		unitOfWork:   unitOfWork,
+		actionTokens: actionTokens,
+	}
+}
+
+// Execute verifies the email of the token's user and returns the user. The token can't be used
+// again.
+func (uc *VerifyEmailUseCase) Execute(ctx context.Context, req dto.VerifyEmailRequest) (*dto.UserResponse, error) {
+	var user *entity.User
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token, err := uc.actionTokens.Consume(ctx, entity.ActionTokenEmailVerification, req.Token)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidVerificationToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume email verification token: %w", err)
+		}
+
+		user, err = uc.userRepo.FindByID(ctx, token.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidVerificationToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+
+		user.VerifyEmail()
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
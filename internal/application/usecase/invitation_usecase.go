@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// invitationTemplate is the email template carrying an invitation link
+const invitationTemplate = "invitation"
+
+// InvitationConfig configures the invitation links
+type InvitationConfig struct {
+	// URL is the frontend page that registers with an invitation; the link adds a token
+	// parameter
+	URL string
+}
+
+// InvitationUseCase handles the invitations to register (admin only)
+type InvitationUseCase struct {
+	userRepo     repository.UserRepository
+	unitOfWork   repository.UnitOfWork
+	invitations  *service.InvitationService
+	emailService *service.EmailService
+	config       InvitationConfig
+}
+
+// NewInvitationUseCase creates a new invitation use case
+func NewInvitationUseCase(
+	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
+	invitations *service.InvitationService,
+	emailService *service.EmailService,
+	config InvitationConfig,
+) *InvitationUseCase {
+	return &InvitationUseCase{
+		userRepo:     userRepo,
+		unitOfWork:   unitOfWork,
+		invitations:  invitations,
+		emailService: emailService,
+		config:       config,
+	}
+}
+
+// Create invites an email address to register, and emails it the invitation link. The link is
+// also returned, for admins who share it another way. Inviting an address again disables its
+// earlier link.
+func (uc *InvitationUseCase) Create(ctx context.Context, adminID string, req dto.CreateInvitationRequest) (*dto.InvitationResponse, error) {
+	exists, err := uc.userRepo.EmailExists(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
+	}
+	if exists {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	admin, err := uc.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	role := entity.RoleUser
+	if req.Role != "" {
+		role = entity.Role(req.Role)
+	}
+
+	var response dto.InvitationResponse
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		invitation, token, err := uc.invitations.Create(ctx, req.Email, role, admin.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+
+		link, err := actionLink(uc.config.URL, token)
+		if err != nil {
+			return fmt.Errorf("invalid invitation URL: %w", err)
+		}
+
+		data := map[string]interface{}{
+			"Email":     invitation.Email,
+			"InvitedBy": admin.Name,
+			"URL":       link,
+			"ExpiresIn": formatExpiry(uc.invitations.TTL()),
+		}
+		if err := uc.emailService.Send(ctx, invitation.Email, invitationTemplate, data); err != nil {
+			return fmt.Errorf("failed to send invitation email: %w", err)
+		}
+
+		response = dto.ToInvitationResponse(invitation)
+		response.InviteURL = link
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// List returns a page of invitations, newest first
+func (uc *InvitationUseCase) List(ctx context.Context, req dto.PaginationRequest) (*dto.InvitationsListResponse, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	invitations, total, err := uc.invitations.List(ctx, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	response := dto.ToInvitationsListResponse(invitations, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// Revoke deletes an invitation, so its link stops working
+func (uc *InvitationUseCase) Revoke(ctx context.Context, id string) error {
+	return uc.invitations.Revoke(ctx, id)
+}
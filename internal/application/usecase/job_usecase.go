@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// Job types run by the job queue
+const (
+	// JobDeleteAvatar deletes a replaced or removed avatar from storage
+	JobDeleteAvatar = "avatar.delete"
+	// JobDeleteDocumentFile deletes the file of a deleted document from storage
+	JobDeleteDocumentFile = "document.delete_file"
+	// JobIndexDocument brings the entry of a created, updated or deleted document in the external
+	// search index up to date
+	JobIndexDocument = "document.index"
+	// JobExportUserData assembles the data export a user asked for and emails its download link
+	JobExportUserData = "user_data.export"
+	// JobDeleteUserExports deletes the data exports of a deleted account from storage
+	JobDeleteUserExports = "user_data.delete_exports"
+)
+
+// StoredFilePayload is the payload of the jobs working on a stored file
+type StoredFilePayload struct {
+	URL string `json:"url"`
+}
+
+// DocumentIndexPayload is the payload of JobIndexDocument jobs
+type DocumentIndexPayload struct {
+	DocumentID string `json:"document_id"`
+}
+
+// JobUseCase handles inspecting the job queue and retrying dead jobs (admin only)
+type JobUseCase struct {
+	jobRepo repository.JobRepository
+}
+
+// NewJobUseCase creates a new job use case
+func NewJobUseCase(jobRepo repository.JobRepository) *JobUseCase {
+	return &JobUseCase{
+		jobRepo: jobRepo,
+	}
+}
+
+// ListJobs returns a page of the jobs with status, or of every job when status is empty, newest
+// first
+func (uc *JobUseCase) ListJobs(ctx context.Context, status entity.JobStatus, req dto.PaginationRequest) (*dto.JobsListResponse, error) {
+	if status != "" && !status.IsValid() {
+		return nil, domain.ErrInvalidJobStatus
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	jobs, err := uc.jobRepo.List(ctx, status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	total, err := uc.jobRepo.Count(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	response := dto.ToJobsListResponse(jobs, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// GetJob returns a job
+func (uc *JobUseCase) GetJob(ctx context.Context, id string) (*dto.JobResponse, error) {
+	job, err := uc.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	response := dto.ToJobResponse(job)
+	return &response, nil
+}
+
+// RetryJob moves a dead job back to the queue with a fresh set of attempts
+func (uc *JobUseCase) RetryJob(ctx context.Context, id string) (*dto.JobResponse, error) {
+	job, err := uc.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+	if !job.IsDead() {
+		return nil, domain.ErrJobNotDead
+	}
+
+	job.Retry()
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	response := dto.ToJobResponse(job)
+	return &response, nil
+}
@@ -4,88 +4,320 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 )
 
+// SessionConfig configures how long the sessions started by logins last
+type SessionConfig struct {
+	// RememberMeExpiry is the refresh token lifetime of logins with remember_me; 0 ignores
+	// remember_me
+	RememberMeExpiry time.Duration
+	// Sliding moves the expiry of a session forward on each refresh. Otherwise a session ends
+	// when its first refresh token expires, however often it is refreshed.
+	Sliding bool
+}
+
+// refreshExpiry returns the refresh token lifetime of a session
+func (c SessionConfig) refreshExpiry(tokenService service.TokenService, rememberMe bool) time.Duration {
+	if rememberMe && c.RememberMeExpiry > 0 {
+		return c.RememberMeExpiry
+	}
+	return tokenService.GetTokenExpiration(service.TokenTypeRefresh)
+}
+
 // LoginUseCase handles user login
 type LoginUseCase struct {
 	userRepo        repository.UserRepository
 	tokenRepo       repository.TokenRepository
+	unitOfWork      repository.UnitOfWork
 	passwordService service.PasswordService
+	passwordHistory *service.PasswordHistoryService
 	tokenService    service.TokenService
+	loginThrottle   *service.LoginThrottleService
+	securityEvents  *service.SecurityEventService
+	auditLog        *service.AuditLogService
+	dailyCounters   *service.DailyCounters
+	recoveryCodes   *service.RecoveryCodeService
+	// otp texts the codes of users with SMS two-factor authentication; nil when no SMS provider
+	// is configured
+	otp           *service.OTPService
+	sessionConfig SessionConfig
+	// requireVerifiedEmail refuses logins until the email is verified
+	requireVerifiedEmail bool
 }
 
 // NewLoginUseCase creates a new login use case
 func NewLoginUseCase(
 	userRepo repository.UserRepository,
 	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
 	passwordService service.PasswordService,
+	passwordHistory *service.PasswordHistoryService,
 	tokenService service.TokenService,
+	loginThrottle *service.LoginThrottleService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+	dailyCounters *service.DailyCounters,
+	recoveryCodes *service.RecoveryCodeService,
+	otp *service.OTPService,
+	sessionConfig SessionConfig,
+	requireVerifiedEmail bool,
 ) *LoginUseCase {
 	return &LoginUseCase{
-		userRepo:        userRepo,
-		tokenRepo:       tokenRepo,
-		passwordService: passwordService,
-		tokenService:    tokenService,
+		userRepo:             userRepo,
+		tokenRepo:            tokenRepo,
+		unitOfWork:           unitOfWork,
+		passwordService:      passwordService,
+		passwordHistory:      passwordHistory,
+		tokenService:         tokenService,
+		loginThrottle:        loginThrottle,
+		securityEvents:       securityEvents,
+		auditLog:             auditLog,
+		dailyCounters:        dailyCounters,
+		recoveryCodes:        recoveryCodes,
+		otp:                  otp,
+		sessionConfig:        sessionConfig,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
-// Execute executes the login use case
-func (uc *LoginUseCase) Execute(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+// Execute executes the login use case. Users with two-factor authentication get a challenge
+// instead of tokens, which CompleteTwoFactor exchanges for the tokens with the code. Users who
+// have to change their password log in with a new one, which is set once every factor is
+// verified.
+func (uc *LoginUseCase) Execute(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, *dto.TwoFactorChallengeResponse, error) {
+	// Reject early while the account is throttled
+	if uc.loginThrottle != nil {
+		if err := uc.loginThrottle.Check(ctx, req.Email, req.ClientIP); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Find user by email
 	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		uc.recordFailure(ctx, req, nil)
+		return nil, nil, domain.ErrInvalidCredentials
 	}
-	if user == nil {
-		return nil, errors.New("invalid credentials")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	// Check if user is OAuth user (no password)
-	if user.IsOAuthUser() {
-		return nil, errors.New("please use OAuth login for this account")
+	if !user.HasPassword() {
+		return nil, nil, domain.ErrOAuthRequired
 	}
 
 	// Verify password
 	if user.Password == nil {
-		return nil, errors.New("invalid credentials")
+		uc.recordFailure(ctx, req, user)
+		return nil, nil, domain.ErrInvalidCredentials
 	}
 
 	if err := uc.passwordService.VerifyPassword(req.Password, *user.Password); err != nil {
-		return nil, errors.New("invalid credentials")
+		uc.recordFailure(ctx, req, user)
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	if uc.loginThrottle != nil {
+		// Counters expire on their own, so failing to reset them is only logged
+		if err := uc.loginThrottle.Reset(ctx, req.Email, req.ClientIP); err != nil {
+			logging.ModuleFromContext(ctx, logging.ModuleAuth).WithError(err).Warn("Failed to reset login throttle")
+		}
+	}
+
+	// Checked after the password, so it doesn't tell which accounts exist
+	if uc.requireVerifiedEmail && !user.EmailVerified {
+		return nil, nil, domain.ErrEmailNotVerified
 	}
 
-	// Revoke all existing refresh tokens for this user (single session)
-	if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
-		// Log error but don't fail login
-		// This could be logged in a real application
+	var newPasswordHash string
+	if user.PasswordChangeRequired {
+		if req.NewPassword == "" {
+			return nil, nil, domain.ErrPasswordChangeRequired
+		}
+		if newPasswordHash, err = uc.hashNewPassword(ctx, user, req.NewPassword); err != nil {
+			return nil, nil, err
+		}
+	} else if uc.passwordService.NeedsRehash(*user.Password) {
+		// Upgrade the hash while the password is known, after a hashing setting changed
+		uc.rehashPassword(ctx, user, req.Password)
+	}
+
+	// The new password waits for the second factor, so the temporary password alone can't set it
+	if user.HasTwoFactor() {
+		challenge, err := uc.challenge(ctx, user, req, newPasswordHash)
+		return nil, challenge, err
+	}
+
+	if newPasswordHash != "" {
+		if err := uc.changePassword(ctx, user, newPasswordHash, req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	response, err := uc.complete(ctx, user, req)
+	return response, nil, err
+}
+
+// CompleteTwoFactor completes the login of a two-factor challenge with the code sent to the
+// user, or one of the user's recovery codes. Wrong recovery codes count as wrong attempts of the
+// challenge.
+func (uc *LoginUseCase) CompleteTwoFactor(ctx context.Context, req dto.TwoFactorLoginRequest) (*dto.AuthResponse, error) {
+	if uc.otp == nil {
+		return nil, domain.ErrInvalidOTP
 	}
 
+	var challenge *service.OTPChallenge
+	var err error
+	if req.RecoveryCode != "" {
+		challenge, err = uc.otp.VerifyFunc(ctx, service.OTPPurposeLogin, req.TwoFactorToken, func(challenge *service.OTPChallenge) error {
+			return uc.recoveryCodes.Use(ctx, challenge.UserID, req.RecoveryCode)
+		})
+	} else {
+		challenge, err = uc.otp.Verify(ctx, service.OTPPurposeLogin, req.TwoFactorToken, req.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, challenge.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	loginReq := dto.LoginRequest{
+		Email:      user.Email,
+		RememberMe: challenge.RememberMe,
+		ClientIP:   req.ClientIP,
+		UserAgent:  req.UserAgent,
+	}
+	if challenge.NewPasswordHash != "" {
+		if err := uc.changePassword(ctx, user, challenge.NewPasswordHash, loginReq); err != nil {
+			return nil, err
+		}
+	}
+	response, err := uc.complete(ctx, user, loginReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RecoveryCode != "" {
+		uc.recordRecoveryCodeUsed(ctx, user, loginReq)
+	}
+	return response, nil
+}
+
+// recordRecoveryCodeUsed records a login completed with a recovery code, with how many codes
+// are left
+func (uc *LoginUseCase) recordRecoveryCodeUsed(ctx context.Context, user *entity.User, req dto.LoginRequest) {
+	if uc.securityEvents == nil {
+		return
+	}
+
+	details := map[string]string{}
+	if remaining, err := uc.recoveryCodes.Remaining(ctx, user.ID); err == nil {
+		details["remaining"] = strconv.FormatInt(remaining, 10)
+	}
+	uc.securityEvents.Record(ctx, user, entity.SecurityEventRecoveryCodeUsed, req.ClientIP, req.UserAgent, details)
+}
+
+// challenge texts a code to the phone of a user with SMS two-factor authentication, and returns
+// the challenge the login is completed with. newPasswordHash is set on logins that have to change
+// the password, and the password is changed when the challenge is completed.
+func (uc *LoginUseCase) challenge(ctx context.Context, user *entity.User, req dto.LoginRequest, newPasswordHash string) (*dto.TwoFactorChallengeResponse, error) {
+	// The user can't sign in until SMS is configured again, rather than skipping the factor
+	if uc.otp == nil || user.Phone == nil {
+		return nil, domain.ErrSMSUnavailable
+	}
+
+	token, err := uc.otp.SendLogin(ctx, &service.OTPChallenge{
+		UserID:          user.ID,
+		Phone:           *user.Phone,
+		RememberMe:      req.RememberMe,
+		NewPasswordHash: newPasswordHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		Method:            string(user.TwoFactor),
+		TwoFactorToken:    token,
+		Phone:             dto.MaskPhone(*user.Phone),
+		ExpiresIn:         int64(uc.otp.TTL().Seconds()),
+	}, nil
+}
+
+// complete starts the session of a login whose factors were all checked
+func (uc *LoginUseCase) complete(ctx context.Context, user *entity.User, req dto.LoginRequest) (*dto.AuthResponse, error) {
+	var response *dto.AuthResponse
+	var sessionID string
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		response, sessionID, err = uc.issueTokens(ctx, user, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.securityEvents != nil {
+		uc.securityEvents.RecordLogin(ctx, user, sessionID, req.ClientIP, req.UserAgent)
+	}
+	// Logins aren't authenticated yet, so the user becomes the actor here
+	actorCtx := service.WithAuditActor(ctx, service.AuditActor{UserID: user.ID, IP: req.ClientIP, UserAgent: req.UserAgent})
+	uc.auditLog.Record(actorCtx, entity.AuditActionLogin, entity.AuditTargetUser, user.ID, map[string]string{
+		"provider":   string(entity.ProviderLocal),
+		"session_id": sessionID,
+	})
+	if uc.dailyCounters != nil {
+		uc.dailyCounters.Increment(ctx, service.DailyCounterLogins)
+	}
+
+	return response, nil
+}
+
+// issueTokens issues new tokens, starting a session of the device. The user's other sessions
+// are kept; they are listed and revoked through the sessions API. A remembered session lasts
+// longer. It returns the ID of the new
+// session.
+func (uc *LoginUseCase) issueTokens(ctx context.Context, user *entity.User, req dto.LoginRequest) (*dto.AuthResponse, string, error) {
 	// Generate new tokens
-	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role), user.Locale, string(user.RateLimitTier))
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, "", fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+	expiresAt := time.Now().Add(uc.sessionConfig.refreshExpiry(uc.tokenService, req.RememberMe))
+	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role), expiresAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	// Store refresh token in database
-	refreshTokenEntity := &entity.Token{
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-		ExpiresAt:    time.Now().Add(uc.tokenService.GetTokenExpiration(service.TokenTypeRefresh)),
-	}
+	refreshTokenEntity := entity.NewToken(
+		user.ID,
+		refreshToken,
+		expiresAt,
+	)
+	refreshTokenEntity.RememberMe = req.RememberMe
+	refreshTokenEntity.SetDevice(req.ClientIP, req.UserAgent)
 
 	if err := uc.tokenRepo.Create(ctx, refreshTokenEntity); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+		return nil, "", fmt.Errorf("failed to store refresh token: %w", err)
 	}
 
 	// Calculate token expiration
@@ -94,5 +326,78 @@ func (uc *LoginUseCase) Execute(ctx context.Context, req dto.LoginRequest) (*dto
 	// Create response
 	response := dto.ToAuthResponse(user, accessToken, refreshToken, expiresIn)
 
-	return &response, nil
-}
\ No newline at end of file
+	return &response, refreshTokenEntity.SessionID, nil
+}
+
+// hashNewPassword checks the new password of a login that has to change the password against
+// the password policy and the user's recent passwords, and hashes it
+func (uc *LoginUseCase) hashNewPassword(ctx context.Context, user *entity.User, newPassword string) (string, error) {
+	// Enforces the password policy
+	hashedPassword, err := uc.passwordService.HashPassword(newPassword)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.passwordHistory.CheckReuse(ctx, user, newPassword); err != nil {
+		return "", err
+	}
+	return hashedPassword, nil
+}
+
+// changePassword replaces the password a user has to change, e.g. a temporary one set by an
+// admin, with the hash of the new password of the login
+func (uc *LoginUseCase) changePassword(ctx context.Context, user *entity.User, hashedPassword string, req dto.LoginRequest) error {
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.passwordHistory.Remember(ctx, user); err != nil {
+			return err
+		}
+		user.SetPassword(hashedPassword)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if uc.securityEvents != nil {
+		details := map[string]string{"changed_by": "user"}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventPasswordChanged, req.ClientIP, req.UserAgent, details)
+	}
+	actorCtx := service.WithAuditActor(ctx, service.AuditActor{UserID: user.ID, IP: req.ClientIP, UserAgent: req.UserAgent})
+	uc.auditLog.Record(actorCtx, entity.AuditActionPasswordChanged, entity.AuditTargetUser, user.ID, nil)
+	return nil
+}
+
+// rehashPassword replaces the stored hash of a password with one made with the current settings.
+// Failures are logged and retried on the next login.
+func (uc *LoginUseCase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hash, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		logging.ModuleFromContext(ctx, logging.ModuleAuth).WithError(err).Warn("Failed to rehash password")
+		return
+	}
+
+	user.Password = &hash
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		logging.ModuleFromContext(ctx, logging.ModuleAuth).WithError(err).Warn("Failed to store rehashed password")
+	}
+}
+
+// recordFailure counts a failed attempt against the account's login throttle, and records the
+// account being locked when the attempt locks it. user is nil when there is no such account.
+func (uc *LoginUseCase) recordFailure(ctx context.Context, req dto.LoginRequest, user *entity.User) {
+	logging.ModuleFromContext(ctx, logging.ModuleAuth).WithField("email", req.Email).Debug("Login failed")
+
+	if uc.loginThrottle == nil {
+		return
+	}
+	locked, err := uc.loginThrottle.RecordFailure(ctx, req.Email, req.ClientIP)
+	if err != nil {
+		// Throttling is best-effort when the cache is unavailable
+		return
+	}
+	if locked && user != nil && uc.securityEvents != nil {
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventAccountLocked, req.ClientIP, req.UserAgent, nil)
+	}
+}
@@ -4,27 +4,44 @@ import (
 	"context"
 	"fmt"
 
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
 )
 
 // LogoutUseCase handles user logout
 type LogoutUseCase struct {
 	tokenRepo repository.TokenRepository
+	// denylist rejects the access tokens of logged out sessions; nil leaves them valid until
+	// they expire
+	denylist *service.TokenDenylistService
+	auditLog *service.AuditLogService
 }
 
 // NewLogoutUseCase creates a new logout use case
-func NewLogoutUseCase(tokenRepo repository.TokenRepository) *LogoutUseCase {
+func NewLogoutUseCase(tokenRepo repository.TokenRepository, denylist *service.TokenDenylistService, auditLog *service.AuditLogService) *LogoutUseCase {
 	return &LogoutUseCase{
 		tokenRepo: tokenRepo,
+		denylist:  denylist,
+		auditLog:  auditLog,
 	}
 }
 
-// Execute executes the logout use case (logout from current device)
-func (uc *LogoutUseCase) Execute(ctx context.Context, refreshToken string) error {
+// Execute executes the logout use case (logout from current device). accessToken holds the
+// claims of the access token the request was made with, and may be nil, e.g. for API keys.
+func (uc *LogoutUseCase) Execute(ctx context.Context, refreshToken string, accessToken *service.TokenClaims) error {
 	if err := uc.tokenRepo.DeleteByRefreshToken(ctx, refreshToken); err != nil {
 		return fmt.Errorf("failed to delete refresh token: %w", err)
 	}
 
+	if uc.denylist != nil && accessToken != nil {
+		if err := uc.denylist.Deny(ctx, accessToken); err != nil {
+			return fmt.Errorf("failed to deny access token: %w", err)
+		}
+	}
+
+	// Logging out is authenticated, so the user logging out is the actor
+	uc.auditLog.Record(ctx, entity.AuditActionLogout, entity.AuditTargetUser, service.AuditActorFromContext(ctx).UserID, nil)
 	return nil
 }
 
@@ -34,5 +51,12 @@ func (uc *LogoutUseCase) ExecuteAll(ctx context.Context, userID string) error {
 		return fmt.Errorf("failed to revoke all user tokens: %w", err)
 	}
 
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUser(ctx, userID); err != nil {
+			return fmt.Errorf("failed to deny access tokens: %w", err)
+		}
+	}
+
+	uc.auditLog.Record(ctx, entity.AuditActionLogoutAll, entity.AuditTargetUser, userID, nil)
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/oauth"
+)
+
+// OAuthLoginUseCase signs users in with their account at an OAuth provider
+type OAuthLoginUseCase struct {
+	userRepo         repository.UserRepository
+	userProviderRepo repository.UserProviderRepository
+	tokenRepo        repository.TokenRepository
+	unitOfWork       repository.UnitOfWork
+	tokenService     service.TokenService
+	invitations      *service.InvitationService
+	// securityEvents reports logins from new devices and countries; nil skips it
+	securityEvents *service.SecurityEventService
+	auditLog       *service.AuditLogService
+	// dailyCounters counts the logins; nil skips it
+	dailyCounters *service.DailyCounters
+	registration  RegistrationConfig
+	// login challenges users with two-factor authentication, whose login it then completes
+	login *LoginUseCase
+}
+
+// NewOAuthLoginUseCase creates a new OAuth login use case
+func NewOAuthLoginUseCase(
+	userRepo repository.UserRepository,
+	userProviderRepo repository.UserProviderRepository,
+	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
+	tokenService service.TokenService,
+	invitations *service.InvitationService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+	dailyCounters *service.DailyCounters,
+	registration RegistrationConfig,
+	login *LoginUseCase,
+) *OAuthLoginUseCase {
+	return &OAuthLoginUseCase{
+		userRepo:         userRepo,
+		userProviderRepo: userProviderRepo,
+		tokenRepo:        tokenRepo,
+		unitOfWork:       unitOfWork,
+		tokenService:     tokenService,
+		invitations:      invitations,
+		securityEvents:   securityEvents,
+		auditLog:         auditLog,
+		dailyCounters:    dailyCounters,
+		registration:     registration,
+		login:            login,
+	}
+}
+
+// Execute signs in the user of an account at the named provider. clientIP and userAgent are
+// those of the device signing in. Like a password login, the login of a user with two-factor
+// authentication returns a challenge instead of tokens, completed with
+// LoginUseCase.CompleteTwoFactor.
+func (uc *OAuthLoginUseCase) Execute(ctx context.Context, providerName string, account *oauth.UserInfo, clientIP, userAgent string) (*dto.AuthResponse, *dto.TwoFactorChallengeResponse, error) {
+	if account == nil || account.ID == "" {
+		return nil, nil, domain.ErrOAuthFailed
+	}
+
+	// Accounts are merged by email, so it must belong to the user
+	if !account.EmailVerified {
+		return nil, nil, domain.ErrEmailNotVerified
+	}
+
+	// Creating or merging the user and storing its refresh token succeed or fail together
+	var response *dto.AuthResponse
+	var user *entity.User
+	var sessionID string
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.authenticate(ctx, entity.OAuthProvider(providerName), account)
+		if err != nil || user.HasTwoFactor() {
+			return err
+		}
+		response, sessionID, err = uc.issueTokens(ctx, user, clientIP, userAgent)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The provider account stands for the password, not for the second factor
+	if user.HasTwoFactor() {
+		challenge, err := uc.login.challenge(ctx, user, dto.LoginRequest{Email: user.Email, ClientIP: clientIP, UserAgent: userAgent}, "")
+		return nil, challenge, err
+	}
+
+	if uc.securityEvents != nil {
+		uc.securityEvents.RecordLogin(ctx, user, sessionID, clientIP, userAgent)
+	}
+	actorCtx := service.WithAuditActor(ctx, service.AuditActor{UserID: user.ID, IP: clientIP, UserAgent: userAgent})
+	uc.auditLog.Record(actorCtx, entity.AuditActionLogin, entity.AuditTargetUser, user.ID, map[string]string{
+		"provider":   string(entity.OAuthProvider(providerName)),
+		"session_id": sessionID,
+	})
+
+	if uc.dailyCounters != nil {
+		uc.dailyCounters.Increment(ctx, service.DailyCounterLogins)
+	}
+
+	return response, nil, nil
+}
+
+// authenticate finds, merges or creates the user of a provider account
+func (uc *OAuthLoginUseCase) authenticate(ctx context.Context, provider entity.Provider, account *oauth.UserInfo) (*entity.User, error) {
+	user, err := uc.findLinkedUser(ctx, provider, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the account isn't linked, try by email (for merging accounts)
+	if user == nil {
+		user, err = uc.userRepo.FindByEmail(ctx, account.Email)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, fmt.Errorf("failed to find user by email: %w", err)
+		}
+
+		// If user exists with same email, link the account to it
+		if user != nil {
+			if account.Avatar != "" {
+				user.Avatar = &account.Avatar
+			}
+			user.EmailVerified = true
+
+			if err := uc.userRepo.Update(ctx, user); err != nil {
+				return nil, fmt.Errorf("failed to merge user account: %w", err)
+			}
+			if err := uc.link(ctx, user, provider, account); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// If user still doesn't exist, create new one, with the invitation of the email if any
+	if user == nil {
+		invitation, err := uc.invitations.FindPendingByEmail(ctx, account.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find invitation: %w", err)
+		}
+		if err := uc.registration.allows(account.Email, invitation != nil); err != nil {
+			return nil, err
+		}
+
+		var avatar *string
+		if account.Avatar != "" {
+			avatar = &account.Avatar
+		}
+
+		user = entity.NewOAuthUser(
+			account.Email,
+			account.Name,
+			provider,
+			avatar,
+		)
+		if invitation != nil {
+			user.Role = invitation.Role
+		}
+
+		if err := user.Validate(); err != nil {
+			return nil, domain.NewValidationError(err)
+		}
+
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		if err := uc.link(ctx, user, provider, account); err != nil {
+			return nil, err
+		}
+		if invitation != nil {
+			if err := uc.invitations.Accept(ctx, invitation, user.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return user, nil
+}
+
+// issueTokens issues the tokens of a user, starting a session of the device. The user's other
+// sessions are kept. It returns the ID of the new session.
+func (uc *OAuthLoginUseCase) issueTokens(ctx context.Context, user *entity.User, clientIP, userAgent string) (*dto.AuthResponse, string, error) {
+	// Generate new tokens
+	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role), user.Locale, string(user.RateLimitTier))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(uc.tokenService.GetTokenExpiration(service.TokenTypeRefresh))
+	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role), expiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	// Store refresh token in database
+	refreshTokenEntity := entity.NewToken(
+		user.ID,
+		refreshToken,
+		expiresAt,
+	)
+	refreshTokenEntity.SetDevice(clientIP, userAgent)
+
+	if err := uc.tokenRepo.Create(ctx, refreshTokenEntity); err != nil {
+		return nil, "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	// Calculate token expiration
+	expiresIn := int64(uc.tokenService.GetTokenExpiration(service.TokenTypeAccess).Seconds())
+
+	// Create response
+	response := dto.ToAuthResponse(user, accessToken, refreshToken, expiresIn)
+
+	return &response, refreshTokenEntity.SessionID, nil
+}
+
+// findLinkedUser returns the user the provider account is linked to, or nil when it isn't linked.
+// A user in the trash keeps its link, so it can still be restored, and can't sign in. A link
+// left behind by a user deleted permanently is removed, so the account can sign up again.
+func (uc *OAuthLoginUseCase) findLinkedUser(ctx context.Context, provider entity.Provider, providerID string) (*entity.User, error) {
+	link, err := uc.userProviderRepo.FindByProviderID(ctx, provider, providerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user provider: %w", err)
+	}
+
+	user, err := uc.userRepo.FindByID(repository.WithDeleted(ctx), link.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		if err := uc.userProviderRepo.Delete(ctx, link.UserID, provider); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete user provider: %w", err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.DeletedAt.Valid {
+		return nil, domain.ErrAccountDeleted
+	}
+	return user, nil
+}
+
+// link links the provider account to the user. A user merged by email who already linked
+// another account of the provider can't sign in with this one.
+func (uc *OAuthLoginUseCase) link(ctx context.Context, user *entity.User, provider entity.Provider, account *oauth.UserInfo) error {
+	err := uc.userProviderRepo.Create(ctx, entity.NewUserProvider(user.ID, provider, account.ID, account.Email))
+	if errors.Is(err, domain.ErrDuplicate) {
+		return domain.ErrProviderAlreadyLinked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to link provider account: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// passwordResetTemplate is the email template carrying a password reset link
+const passwordResetTemplate = "password_reset"
+
+// PasswordResetConfig configures the password reset links
+type PasswordResetConfig struct {
+	// URL is the frontend page that sets the new password; the link adds a token parameter
+	URL string
+	// TokenTTL is how long a link can be used
+	TokenTTL time.Duration
+}
+
+// ForgotPasswordUseCase emails a password reset link to a user who forgot their password
+type ForgotPasswordUseCase struct {
+	userRepo     repository.UserRepository
+	actionTokens *service.ActionTokenService
+	emailService *service.EmailService
+	config       PasswordResetConfig
+}
+
+// NewForgotPasswordUseCase creates a new forgot password use case
+func NewForgotPasswordUseCase(
+	userRepo repository.UserRepository,
+	actionTokens *service.ActionTokenService,
+	emailService *service.EmailService,
+	config PasswordResetConfig,
+) *ForgotPasswordUseCase {
+	return &ForgotPasswordUseCase{
+		userRepo:     userRepo,
+		actionTokens: actionTokens,
+		emailService: emailService,
+		config:       config,
+	}
+}
+
+// Execute emails a reset link to the user with the email. It succeeds whether or not there is
+// such a user, so the endpoint doesn't tell which emails have accounts. Users without a password,
+// such as those created with Google, have none to reset and get no link.
+func (uc *ForgotPasswordUseCase) Execute(ctx context.Context, req dto.ForgotPasswordRequest) error {
+	logger := logging.ModuleFromContext(ctx, logging.ModuleAuth).WithField("email", req.Email)
+
+	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		logger.Debug("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.HasPassword() {
+		logger.Debug("Password reset requested for user without a password")
+		return nil
+	}
+
+	token, err := uc.actionTokens.Issue(ctx, user.ID, entity.ActionTokenPasswordReset, uc.config.TokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to issue password reset token: %w", err)
+	}
+
+	link, err := actionLink(uc.config.URL, token)
+	if err != nil {
+		return fmt.Errorf("invalid password reset URL: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"URL":       link,
+		"ExpiresIn": formatExpiry(uc.config.TokenTTL),
+	}
+	if err := uc.emailService.Send(ctx, user.Email, passwordResetTemplate, data); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPasswordUseCase sets a new password with the token of a reset link
+type ResetPasswordUseCase struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.TokenRepository
+	unitOfWork      repository.UnitOfWork
+	passwordService service.PasswordService
+	passwordHistory *service.PasswordHistoryService
+	actionTokens    *service.ActionTokenService
+	// denylist rejects the user's access tokens; nil leaves them valid until they expire
+	denylist       *service.TokenDenylistService
+	securityEvents *service.SecurityEventService
+	auditLog       *service.AuditLogService
+}
+
+// NewResetPasswordUseCase creates a new reset password use case. denylist and securityEvents may
+// be nil.
+func NewResetPasswordUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	passwordHistory *service.PasswordHistoryService,
+	actionTokens *service.ActionTokenService,
+	denylist *service.TokenDenylistService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+) *ResetPasswordUseCase {
+	return &ResetPasswordUseCase{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		unitOfWork:      unitOfWork,
+		passwordService: passwordService,
+		passwordHistory: passwordHistory,
+		actionTokens:    actionTokens,
+		denylist:        denylist,
+		securityEvents:  securityEvents,
+		auditLog:        auditLog,
+	}
+}
+
+// Execute sets the new password of the token's user, revokes the user's refresh tokens and denies
+// its access tokens, so whoever knew the old password is signed out. The token can't be used
+// again.
+func (uc *ResetPasswordUseCase) Execute(ctx context.Context, req dto.ResetPasswordRequest) error {
+	// A password that is rejected, even for being used recently, doesn't use up the link
+	hashedPassword, err := uc.passwordService.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	var user *entity.User
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token, err := uc.actionTokens.Consume(ctx, entity.ActionTokenPasswordReset, req.Token)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidResetToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume password reset token: %w", err)
+		}
+
+		user, err = uc.userRepo.FindByID(ctx, token.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidResetToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+		if !user.HasPassword() {
+			return domain.ErrOAuthRequired
+		}
+		if err := uc.passwordHistory.CheckReuse(ctx, user, req.Password); err != nil {
+			return err
+		}
+		if err := uc.passwordHistory.Remember(ctx, user); err != nil {
+			return err
+		}
+
+		user.SetPassword(hashedPassword)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The password is reset either way, so failing to deny the access tokens is only logged
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUser(ctx, user.ID); err != nil {
+			logging.ModuleFromContext(ctx, logging.ModuleAuth).
+				WithError(err).
+				WithField("user_id", user.ID).
+				Warn("Failed to deny access tokens")
+		}
+	}
+
+	if uc.securityEvents != nil {
+		details := map[string]string{"changed_by": "password_reset"}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventPasswordChanged, req.ClientIP, req.UserAgent, details)
+	}
+	// The reset link proves who the unauthenticated requester is
+	actorCtx := service.WithAuditActor(ctx, service.AuditActor{UserID: user.ID, IP: req.ClientIP, UserAgent: req.UserAgent})
+	uc.auditLog.Record(actorCtx, entity.AuditActionPasswordReset, entity.AuditTargetUser, user.ID, map[string]string{"reset_by": "password_reset"})
+	return nil
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// QuotaUseCase handles inspecting and overriding usage quotas (admin only)
+type QuotaUseCase struct {
+	userRepo     repository.UserRepository
+	quotaRepo    repository.QuotaRepository
+	quotaService *service.QuotaService
+}
+
+// NewQuotaUseCase creates a new quota use case
+func NewQuotaUseCase(
+	userRepo repository.UserRepository,
+	quotaRepo repository.QuotaRepository,
+	quotaService *service.QuotaService,
+) *QuotaUseCase {
+	return &QuotaUseCase{
+		userRepo:     userRepo,
+		quotaRepo:    quotaRepo,
+		quotaService: quotaService,
+	}
+}
+
+// GetUserQuotas returns the current usage and limits of a user
+func (uc *QuotaUseCase) GetUserQuotas(ctx context.Context, userID string) (*dto.UserQuotaResponse, error) {
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	statuses, err := uc.quotaService.Usage(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+
+	quotas := make([]dto.QuotaStatusResponse, len(statuses))
+	for i, status := range statuses {
+		quotas[i] = dto.QuotaStatusResponse{
+			Metric:     string(status.Metric),
+			Limit:      status.Limit,
+			Used:       status.Used,
+			Period:     status.Period,
+			ResetsAt:   status.ResetsAt.Format(time.RFC3339),
+			Overridden: status.Overridden,
+		}
+	}
+
+	return &dto.UserQuotaResponse{
+		UserID: userID,
+		Quotas: quotas,
+	}, nil
+}
+
+// SetOverride sets a per-user limit for a metric
+func (uc *QuotaUseCase) SetOverride(ctx context.Context, userID string, metric entity.QuotaMetric, req dto.SetQuotaOverrideRequest) (*dto.UserQuotaResponse, error) {
+	if !metric.IsValid() {
+		return nil, domain.ErrInvalidQuotaMetric
+	}
+
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	override, err := uc.quotaRepo.FindOverride(ctx, userID, metric)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to find quota override: %w", err)
+	}
+
+	if override == nil {
+		override = entity.NewQuotaOverride(userID, metric, req.Limit)
+	} else {
+		override.UpdateLimit(req.Limit)
+	}
+
+	if err := override.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.quotaRepo.SaveOverride(ctx, override); err != nil {
+		return nil, fmt.Errorf("failed to save quota override: %w", err)
+	}
+
+	if err := uc.quotaService.InvalidateOverride(ctx, userID, metric); err != nil {
+		// The cached limit expires on its own shortly
+	}
+
+	return uc.GetUserQuotas(ctx, userID)
+}
+
+// RemoveOverride restores the configured default limit for a metric
+func (uc *QuotaUseCase) RemoveOverride(ctx context.Context, userID string, metric entity.QuotaMetric) error {
+	if !metric.IsValid() {
+		return domain.ErrInvalidQuotaMetric
+	}
+
+	if err := uc.quotaRepo.DeleteOverride(ctx, userID, metric); err != nil {
+		return fmt.Errorf("failed to delete quota override: %w", err)
+	}
+
+	if err := uc.quotaService.InvalidateOverride(ctx, userID, metric); err != nil {
+		// The cached limit expires on its own shortly
+	}
+
+	return nil
+}
+
+// ResetUsage clears a user's usage of a metric for the current period
+func (uc *QuotaUseCase) ResetUsage(ctx context.Context, userID string, metric entity.QuotaMetric) error {
+	if !metric.IsValid() {
+		return domain.ErrInvalidQuotaMetric
+	}
+
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+
+	return uc.quotaService.ResetUsage(ctx, userID, metric)
+}
+
+func (uc *QuotaUseCase) ensureUserExists(ctx context.Context, userID string) error {
+	if _, err := uc.userRepo.FindByID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	return nil
+}
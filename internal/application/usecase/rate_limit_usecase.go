@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// maxRateLimitEntries caps how many counters a single inspection returns
+const maxRateLimitEntries = 100
+
+// RateLimitUseCase lets operators inspect and reset rate-limit counters, and set the rate limit
+// tiers of users (admin only)
+type RateLimitUseCase struct {
+	cacheService *service.CacheService
+	userRepo     repository.UserRepository
+	auditLog     *service.AuditLogService
+}
+
+// NewRateLimitUseCase creates a new rate limit use case
+func NewRateLimitUseCase(cacheService *service.CacheService, userRepo repository.UserRepository, auditLog *service.AuditLogService) *RateLimitUseCase {
+	return &RateLimitUseCase{
+		cacheService: cacheService,
+		userRepo:     userRepo,
+		auditLog:     auditLog,
+	}
+}
+
+// SetUserTier changes the rate limit tier of a user. The tier applies to the user's access
+// tokens issued after it, e.g. on the next token refresh, and to its API keys at once.
+func (uc *RateLimitUseCase) SetUserTier(ctx context.Context, userID string, req dto.SetRateLimitTierRequest) (*dto.UserResponse, error) {
+	tier := entity.RateLimitTier(req.Tier)
+	if !tier.IsValid() {
+		return nil, domain.ErrValidation.WithMessage("tier must be free, pro or internal")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if user.RateLimitTier != tier {
+		previousTier := user.RateLimitTier
+		user.SetRateLimitTier(tier)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+
+		uc.auditLog.Record(ctx, entity.AuditActionRateLimitTierChanged, entity.AuditTargetUser, user.ID, map[string]string{
+			"tier":          string(tier),
+			"previous_tier": string(previousTier),
+		})
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// ListCounters returns the counters whose key starts with prefix, e.g. "ip:203.0.113.7" or "login:"
+func (uc *RateLimitUseCase) ListCounters(ctx context.Context, prefix string) (*dto.RateLimitListResponse, error) {
+	keys, err := uc.cacheService.ScanNamespacePrefix(ctx, "rate_limit", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan rate limit counters: %w", err)
+	}
+	sort.Strings(keys)
+
+	response := &dto.RateLimitListResponse{
+		Entries: make([]dto.RateLimitEntryResponse, 0, len(keys)),
+	}
+	if len(keys) > maxRateLimitEntries {
+		keys = keys[:maxRateLimitEntries]
+		response.Truncated = true
+	}
+
+	for _, key := range keys {
+		entry, err := uc.counter(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		// Counter expired between scan and read
+		if entry == nil {
+			continue
+		}
+		response.Entries = append(response.Entries, *entry)
+	}
+
+	return response, nil
+}
+
+// ResetCounter deletes a counter so the client is unblocked immediately
+func (uc *RateLimitUseCase) ResetCounter(ctx context.Context, key string) error {
+	exists, err := uc.cacheService.Exists(ctx, service.RateLimitCacheKey(key))
+	if err != nil {
+		return fmt.Errorf("failed to find rate limit counter: %w", err)
+	}
+	if !exists {
+		return domain.ErrRateLimitCounterNotFound
+	}
+
+	if err := uc.cacheService.Delete(ctx, service.RateLimitCacheKey(key)); err != nil {
+		return fmt.Errorf("failed to reset rate limit counter: %w", err)
+	}
+	return nil
+}
+
+// counter reads a single counter and its remaining window
+func (uc *RateLimitUseCase) counter(ctx context.Context, key string) (*dto.RateLimitEntryResponse, error) {
+	cacheKey := service.RateLimitCacheKey(key)
+
+	value, err := uc.cacheService.GetString(ctx, cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit counter %q: %w", key, err)
+	}
+
+	entry := &dto.RateLimitEntryResponse{
+		Key:   key,
+		Count: count,
+	}
+
+	if ttl, err := uc.cacheService.TTL(ctx, cacheKey); err == nil && ttl > 0 {
+		entry.TTLSeconds = int64(ttl.Seconds())
+		entry.ResetsAt = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	return entry, nil
+}
@@ -2,11 +2,11 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
 	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 	"gin-boilerplate/internal/domain/service"
@@ -16,19 +16,30 @@ import (
 type RefreshTokenUseCase struct {
 	userRepo     repository.UserRepository
 	tokenRepo    repository.TokenRepository
+	unitOfWork   repository.UnitOfWork
 	tokenService service.TokenService
+	// sessionConfig sets the expiry of rotated refresh tokens
+	sessionConfig SessionConfig
+	// securityEvents records refresh token reuse; nil skips it
+	securityEvents *service.SecurityEventService
 }
 
 // NewRefreshTokenUseCase creates a new refresh token use case
 func NewRefreshTokenUseCase(
 	userRepo repository.UserRepository,
 	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
 	tokenService service.TokenService,
+	sessionConfig SessionConfig,
+	securityEvents *service.SecurityEventService,
 ) *RefreshTokenUseCase {
 	return &RefreshTokenUseCase{
-		userRepo:     userRepo,
-		tokenRepo:    tokenRepo,
-		tokenService: tokenService,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		unitOfWork:     unitOfWork,
+		tokenService:   tokenService,
+		sessionConfig:  sessionConfig,
+		securityEvents: securityEvents,
 	}
 }
 
@@ -37,7 +48,7 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 	// Validate refresh token
 	claims, err := uc.tokenService.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, domain.ErrInvalidRefreshToken.Wrap(err)
 	}
 
 	// Check if refresh token exists in database and is valid
@@ -46,7 +57,10 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 		return nil, fmt.Errorf("failed to validate refresh token: %w", err)
 	}
 	if !isValid {
-		return nil, errors.New("refresh token has been revoked or expired")
+		if err := uc.detectReuse(ctx, req, claims.UserID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
 	}
 
 	// Find user
@@ -54,8 +68,56 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return nil, errors.New("user not found")
+
+	// The old refresh token is only deleted if the new one is stored, so a failure doesn't end
+	// the session
+	var response *dto.AuthResponse
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = uc.rotate(ctx, user, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// detectReuse signs out every session of the user when the refresh token was already rotated
+// or logged out before it expired. A deleted token is only used again by whoever copied it, so
+// either the user or an attacker holds a session issued from a stolen token.
+func (uc *RefreshTokenUseCase) detectReuse(ctx context.Context, req dto.RefreshTokenRequest, userID string) error {
+	deleted, err := uc.tokenRepo.IsTokenValid(repository.WithOnlyDeleted(ctx), req.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to check refresh token reuse: %w", err)
+	}
+	if !deleted {
+		return nil
+	}
+
+	// The deleted tokens are revoked too, so using them yet again isn't reported again
+	if err := uc.tokenRepo.RevokeAllUserTokens(repository.WithDeleted(ctx), userID); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	if uc.securityEvents != nil {
+		user, err := uc.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventTokenReuse, req.ClientIP, req.UserAgent, nil)
+	}
+	return nil
+}
+
+// rotate replaces a refresh token with a new one of the same session and issues a new access
+// token. The new refresh token expires a full lifetime from now when sessions slide, and with
+// the old one otherwise.
+func (uc *RefreshTokenUseCase) rotate(ctx context.Context, user *entity.User, req dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	oldToken, err := uc.tokenRepo.FindByRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find old refresh token: %w", err)
 	}
 
 	// Delete old refresh token
@@ -64,12 +126,18 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 	}
 
 	// Generate new tokens
-	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role), user.Locale, string(user.RateLimitTier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	newRefreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+	// The session keeps its expiry unless it slides
+	expiresAt := oldToken.ExpiresAt
+	if uc.sessionConfig.Sliding {
+		expiresAt = time.Now().Add(uc.sessionConfig.refreshExpiry(uc.tokenService, oldToken.RememberMe))
+	}
+
+	newRefreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role), expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -78,8 +146,11 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 	refreshTokenEntity := entity.NewToken(
 		user.ID,
 		newRefreshToken,
-		time.Now().Add(uc.tokenService.GetTokenExpiration(service.TokenTypeRefresh)),
+		expiresAt,
 	)
+	refreshTokenEntity.SessionID = oldToken.SessionID
+	refreshTokenEntity.RememberMe = oldToken.RememberMe
+	refreshTokenEntity.SetDevice(req.ClientIP, req.UserAgent)
 
 	if err := uc.tokenRepo.Create(ctx, refreshTokenEntity); err != nil {
 		return nil, fmt.Errorf("failed to store new refresh token: %w", err)
@@ -92,4 +163,4 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req dto.RefreshToken
 	response := dto.ToAuthResponse(user, accessToken, newRefreshToken, expiresIn)
 
 	return &response, nil
-}
\ No newline at end of file
+}
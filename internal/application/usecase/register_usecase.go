@@ -2,44 +2,114 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 )
 
+// RegistrationConfig configures who can sign up, with a password or an OAuth provider
+type RegistrationConfig struct {
+	// Closed refuses every new account, invited or not
+	Closed bool
+	// InviteOnly requires an invitation to register
+	InviteOnly bool
+	// AllowedDomains limits registration to emails of these domains, unless the user was
+	// invited; empty allows any
+	AllowedDomains []string
+}
+
+// allows returns the error refusing a new account with the email, or nil when it can be
+// created. invited is whether the account comes from an invitation, which an admin chose to
+// send to the address.
+func (c RegistrationConfig) allows(email string, invited bool) error {
+	if c.Closed {
+		return domain.ErrRegistrationClosed
+	}
+	if invited {
+		return nil
+	}
+	if c.InviteOnly {
+		return domain.ErrInvitationRequired
+	}
+	if len(c.AllowedDomains) > 0 {
+		emailDomain := email[strings.LastIndex(email, "@")+1:]
+		for _, allowed := range c.AllowedDomains {
+			if strings.EqualFold(emailDomain, allowed) {
+				return nil
+			}
+		}
+		return domain.ErrEmailDomainNotAllowed
+	}
+	return nil
+}
+
 // RegisterUseCase handles user registration
 type RegisterUseCase struct {
 	userRepo        repository.UserRepository
+	unitOfWork      repository.UnitOfWork
 	passwordService service.PasswordService
 	tokenService    service.TokenService
+	invitations     *service.InvitationService
+	// sendVerification emails the new user a verification link; nil skips it
+	sendVerification *SendVerificationUseCase
+	// disposableEmails rejects addresses of disposable email providers; nil accepts them
+	disposableEmails service.DisposableEmailService
+	config           RegistrationConfig
 }
 
-// NewRegisterUseCase creates a new register use case
+// NewRegisterUseCase creates a new register use case. sendVerification and disposableEmails may
+// be nil.
 func NewRegisterUseCase(
 	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
 	passwordService service.PasswordService,
 	tokenService service.TokenService,
+	invitations *service.InvitationService,
+	sendVerification *SendVerificationUseCase,
+	disposableEmails service.DisposableEmailService,
+	config RegistrationConfig,
 ) *RegisterUseCase {
 	return &RegisterUseCase{
-		userRepo:        userRepo,
-		passwordService: passwordService,
-		tokenService:    tokenService,
+		userRepo:         userRepo,
+		unitOfWork:       unitOfWork,
+		passwordService:  passwordService,
+		tokenService:     tokenService,
+		invitations:      invitations,
+		sendVerification: sendVerification,
+		disposableEmails: disposableEmails,
+		config:           config,
 	}
 }
 
-// Execute executes the register use case
+// Execute executes the register use case. A user registering with an invitation gets its role,
+// and its email is verified, since the invitation was emailed to it.
 func (uc *RegisterUseCase) Execute(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error) {
+	invitation, err := uc.invitation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.config.allows(req.Email, invitation != nil); err != nil {
+		return nil, err
+	}
+	// Invitations were sent to the address by an admin
+	if invitation == nil && uc.disposableEmails != nil && uc.disposableEmails.IsDisposable(req.Email) {
+		return nil, domain.ErrDisposableEmail
+	}
+
 	// Check if email already exists
 	exists, err := uc.userRepo.EmailExists(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check email existence: %w", err)
 	}
 	if exists {
-		return nil, errors.New("email already exists")
+		return nil, domain.ErrEmailAlreadyExists
 	}
 
 	// Hash password
@@ -49,26 +119,56 @@ func (uc *RegisterUseCase) Execute(ctx context.Context, req dto.RegisterRequest)
 	}
 
 	// Create user
-	user := entity.NewUser(req.Email, req.Name, entity.RoleUser)
+	role := entity.RoleUser
+	if invitation != nil {
+		role = invitation.Role
+	}
+	user := entity.NewUser(req.Email, req.Name, role)
 	user.SetPassword(hashedPassword)
+	if invitation != nil {
+		user.VerifyEmail()
+	}
 
 	// Validate user
 	if err := user.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid user data: %w", err)
+		return nil, domain.NewValidationError(err)
 	}
 
-	// Save user to database
-	if err := uc.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	// Save user to database, using up the invitation
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if invitation != nil {
+			return uc.invitations.Accept(ctx, invitation, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.sendVerification != nil && !user.EmailVerified {
+		// The user can ask for another link, so a failure doesn't fail the registration
+		if err := uc.sendVerification.Execute(ctx, user); err != nil {
+			logging.ModuleFromContext(ctx, logging.ModuleAuth).WithError(err).Warn("Failed to send email verification")
+		}
+
+		// Without a verified email the user can't log in, so there are no tokens yet
+		if uc.sendVerification.Required() {
+			response := dto.AuthResponse{User: dto.ToUserResponse(user)}
+			return &response, nil
+		}
 	}
 
 	// Generate tokens
-	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role), user.Locale, string(user.RateLimitTier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+	expiresAt := time.Now().Add(uc.tokenService.GetTokenExpiration(service.TokenTypeRefresh))
+	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role), expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -80,4 +180,20 @@ func (uc *RegisterUseCase) Execute(ctx context.Context, req dto.RegisterRequest)
 	response := dto.ToAuthResponse(user, accessToken, refreshToken, expiresIn)
 
 	return &response, nil
-}
\ No newline at end of file
+}
+
+// invitation returns the invitation of the registration's token, or nil when it has none
+func (uc *RegisterUseCase) invitation(ctx context.Context, req dto.RegisterRequest) (*entity.Invitation, error) {
+	if req.InviteToken == "" {
+		return nil, nil
+	}
+
+	invitation, err := uc.invitations.Find(ctx, req.InviteToken)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, req.Email) {
+		return nil, domain.ErrInvitationEmailMismatch
+	}
+	return invitation, nil
+}
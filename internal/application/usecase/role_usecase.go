@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// RoleUseCase handles the custom roles and the role of users (admin only)
+type RoleUseCase struct {
+	roleRepo   repository.RoleRepository
+	userRepo   repository.UserRepository
+	tokenRepo  repository.TokenRepository
+	unitOfWork repository.UnitOfWork
+	policy     *service.PolicyService
+	// denylist rejects the access tokens issued with a user's previous role; nil leaves them
+	// valid until they expire
+	denylist *service.TokenDenylistService
+	// securityEvents records users given the admin role; nil skips it
+	securityEvents *service.SecurityEventService
+	auditLog       *service.AuditLogService
+}
+
+// NewRoleUseCase creates a new role use case. denylist and securityEvents may be nil.
+func NewRoleUseCase(
+	roleRepo repository.RoleRepository,
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
+	policy *service.PolicyService,
+	denylist *service.TokenDenylistService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+) *RoleUseCase {
+	return &RoleUseCase{
+		roleRepo:       roleRepo,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		unitOfWork:     unitOfWork,
+		policy:         policy,
+		denylist:       denylist,
+		securityEvents: securityEvents,
+		auditLog:       auditLog,
+	}
+}
+
+// Permissions returns every permission roles can have
+func (uc *RoleUseCase) Permissions() *dto.PermissionsResponse {
+	return &dto.PermissionsResponse{
+		Permissions: dto.ToPermissionNames(entity.Permissions),
+	}
+}
+
+// List returns the built-in roles followed by the custom roles, by name
+func (uc *RoleUseCase) List(ctx context.Context) (*dto.RolesListResponse, error) {
+	roles, err := uc.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	responses := make([]dto.RoleResponse, 0, len(roles)+len(entity.BuiltinRoles))
+	for _, builtin := range entity.BuiltinRoles {
+		permissions, _ := entity.BuiltinPermissions(builtin)
+		responses = append(responses, dto.ToBuiltinRoleResponse(builtin, permissions))
+	}
+	for _, role := range roles {
+		responses = append(responses, dto.ToRoleResponse(role))
+	}
+
+	return &dto.RolesListResponse{Roles: responses}, nil
+}
+
+// Get returns a role and its permissions
+func (uc *RoleUseCase) Get(ctx context.Context, name string) (*dto.RoleResponse, error) {
+	if permissions, ok := entity.BuiltinPermissions(entity.Role(name)); ok {
+		response := dto.ToBuiltinRoleResponse(entity.Role(name), permissions)
+		return &response, nil
+	}
+
+	role, err := uc.roleRepo.FindByName(ctx, entity.Role(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find role: %w", err)
+	}
+
+	response := dto.ToRoleResponse(role)
+	return &response, nil
+}
+
+// Create creates a custom role allowing a set of permissions, which actorRole, the role of the
+// admin creating it, has to have too
+func (uc *RoleUseCase) Create(ctx context.Context, actorRole entity.Role, req dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	name := entity.Role(req.Name)
+	if _, ok := entity.BuiltinPermissions(name); ok {
+		return nil, domain.ErrBuiltinRole
+	}
+
+	role := entity.NewCustomRole(name, req.Description, toPermissions(req.Permissions))
+	if err := role.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+	if err := uc.checkPermissions(ctx, actorRole, role.PermissionList()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.Create(ctx, role); err != nil {
+		if errors.Is(err, domain.ErrDuplicate) {
+			return nil, domain.ErrRoleExists
+		}
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	// A role deleted earlier under the same name may still be cached without permissions
+	if err := uc.policy.Invalidate(ctx, name); err != nil {
+		return nil, fmt.Errorf("failed to invalidate role permissions: %w", err)
+	}
+	uc.auditLog.Record(ctx, entity.AuditActionRoleCreated, entity.AuditTargetRole, string(role.Name), permissionDetails(role))
+
+	response := dto.ToRoleResponse(role)
+	return &response, nil
+}
+
+// Update replaces the description and permissions of a custom role. They apply to the users
+// with the role on their next request. actorRole, the role of the admin updating it, has to have
+// every permission given.
+func (uc *RoleUseCase) Update(ctx context.Context, actorRole entity.Role, name string, req dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	if _, ok := entity.BuiltinPermissions(entity.Role(name)); ok {
+		return nil, domain.ErrBuiltinRole
+	}
+
+	role, err := uc.roleRepo.FindByName(ctx, entity.Role(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find role: %w", err)
+	}
+
+	role.Description = req.Description
+	role.SetPermissions(toPermissions(req.Permissions))
+	if err := role.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+	if err := uc.checkPermissions(ctx, actorRole, role.PermissionList()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	if err := uc.policy.Invalidate(ctx, role.Name); err != nil {
+		return nil, fmt.Errorf("failed to invalidate role permissions: %w", err)
+	}
+	uc.auditLog.Record(ctx, entity.AuditActionRoleUpdated, entity.AuditTargetRole, string(role.Name), permissionDetails(role))
+
+	response := dto.ToRoleResponse(role)
+	return &response, nil
+}
+
+// Delete deletes a custom role no user has
+func (uc *RoleUseCase) Delete(ctx context.Context, name string) error {
+	role := entity.Role(name)
+	if _, ok := entity.BuiltinPermissions(role); ok {
+		return domain.ErrBuiltinRole
+	}
+
+	users, err := uc.userRepo.FindByRole(ctx, role, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to find users with role: %w", err)
+	}
+	if len(users) > 0 {
+		return domain.ErrRoleInUse
+	}
+
+	if err := uc.roleRepo.Delete(ctx, role); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if err := uc.policy.Invalidate(ctx, role); err != nil {
+		return fmt.Errorf("failed to invalidate role permissions: %w", err)
+	}
+	uc.auditLog.Record(ctx, entity.AuditActionRoleDeleted, entity.AuditTargetRole, string(role), nil)
+	return nil
+}
+
+// Assign gives a user a built-in or custom role. The user's sessions are revoked and its access
+// tokens denied, so it signs in again with the new role. The last admin can't be given another
+// role. grantedBy is the ID of the admin assigning it, and actorRole its role, which has to be
+// able to grant both the new and the user's current role.
+func (uc *RoleUseCase) Assign(ctx context.Context, actorRole entity.Role, targetUserID, grantedBy string, req dto.AssignRoleRequest) (*dto.UserResponse, error) {
+	role := entity.Role(req.Role)
+	if _, ok := entity.BuiltinPermissions(role); !ok {
+		if _, err := uc.roleRepo.FindByName(ctx, role); err != nil {
+			return nil, fmt.Errorf("failed to find role: %w", err)
+		}
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.Role == role {
+		response := dto.ToUserResponse(user)
+		return &response, nil
+	}
+
+	for _, granted := range []entity.Role{role, user.Role} {
+		allowed, err := uc.policy.CanGrant(ctx, actorRole, granted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check role: %w", err)
+		}
+		if !allowed {
+			return nil, domain.ErrRoleNotGrantable
+		}
+	}
+
+	previousRole := user.Role
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if previousRole == entity.RoleAdmin {
+			admins, err := uc.userRepo.Count(ctx, repository.UserFilter{Role: entity.RoleAdmin})
+			if err != nil {
+				return fmt.Errorf("failed to count admins: %w", err)
+			}
+			if admins <= 1 {
+				return domain.ErrLastAdmin
+			}
+		}
+
+		user.SetRole(role)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUser(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to deny access tokens: %w", err)
+		}
+	}
+
+	if role == entity.RoleAdmin && uc.securityEvents != nil {
+		details := map[string]string{"granted_by": grantedBy}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventAdminRoleGranted, "", "", details)
+	}
+	recordRoleAssigned(ctx, uc.auditLog, user, previousRole)
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// checkPermissions checks that actorRole has every permission it grants
+func (uc *RoleUseCase) checkPermissions(ctx context.Context, actorRole entity.Role, permissions []entity.Permission) error {
+	allowed, err := uc.policy.HasAll(ctx, actorRole, permissions)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !allowed {
+		return domain.ErrRoleNotGrantable
+	}
+	return nil
+}
+
+// recordRoleAssigned records the user given its current role in place of the previous one
+func recordRoleAssigned(ctx context.Context, auditLog *service.AuditLogService, user *entity.User, previousRole entity.Role) {
+	auditLog.Record(ctx, entity.AuditActionRoleAssigned, entity.AuditTargetUser, user.ID, map[string]string{
+		"role":          string(user.Role),
+		"previous_role": string(previousRole),
+	})
+}
+
+// permissionDetails lists the permissions of a custom role in the details of an audit log entry
+func permissionDetails(role *entity.CustomRole) map[string]string {
+	names := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.PermissionList() {
+		names = append(names, string(permission))
+	}
+	return map[string]string{"permissions": strings.Join(names, ",")}
+}
+
+// toPermissions converts permission names to permissions
+func toPermissions(names []string) []entity.Permission {
+	permissions := make([]entity.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = entity.Permission(name)
+	}
+	return permissions
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// SecurityEventUseCase handles the feeds of recorded security events
+type SecurityEventUseCase struct {
+	securityEventRepo repository.SecurityEventRepository
+	tokenRepo         repository.TokenRepository
+}
+
+// NewSecurityEventUseCase creates a new security event use case
+func NewSecurityEventUseCase(securityEventRepo repository.SecurityEventRepository, tokenRepo repository.TokenRepository) *SecurityEventUseCase {
+	return &SecurityEventUseCase{
+		securityEventRepo: securityEventRepo,
+		tokenRepo:         tokenRepo,
+	}
+}
+
+// ListEvents returns a page of the security events matching the filter, newest first (admin
+// only)
+func (uc *SecurityEventUseCase) ListEvents(ctx context.Context, filter repository.SecurityEventFilter, req dto.PaginationRequest) (*dto.SecurityEventsListResponse, error) {
+	if filter.Type != "" && !entity.IsValidSecurityEventType(filter.Type) {
+		return nil, domain.ErrInvalidSecurityEventType
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	events, err := uc.securityEventRepo.List(ctx, filter, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+
+	total, err := uc.securityEventRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count security events: %w", err)
+	}
+
+	response := dto.ToSecurityEventsListResponse(events, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// ListUserEvents returns a page of the user's own security events, newest first
+func (uc *SecurityEventUseCase) ListUserEvents(ctx context.Context, userID string, eventType entity.SecurityEventType, req dto.PaginationRequest) (*dto.SecurityEventsListResponse, error) {
+	return uc.ListEvents(ctx, repository.SecurityEventFilter{UserID: userID, Type: eventType}, req)
+}
+
+// ListSuspiciousLogins returns a page of the user's logins from new devices and countries, newest
+// first, with whether the sessions they started are still signed in, so the user can revoke
+// those that weren't theirs
+func (uc *SecurityEventUseCase) ListSuspiciousLogins(ctx context.Context, userID string, req dto.PaginationRequest) (*dto.SuspiciousLoginsListResponse, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filter := repository.SecurityEventFilter{UserID: userID, Types: entity.SuspiciousLoginEvents}
+	events, err := uc.securityEventRepo.List(ctx, filter, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suspicious logins: %w", err)
+	}
+
+	total, err := uc.securityEventRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count suspicious logins: %w", err)
+	}
+
+	tokens, err := uc.tokenRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	activeSessions := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		activeSessions[token.SessionID] = true
+	}
+
+	response := dto.ToSuspiciousLoginsListResponse(events, activeSessions, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// PurgeEvents deletes the security events older than the retention, and returns how many were
+// deleted
+func (uc *SecurityEventUseCase) PurgeEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := uc.securityEventRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge security events: %w", err)
+	}
+	return deleted, nil
+}
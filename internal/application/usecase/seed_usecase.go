@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// SeedUseCase fills a fresh database with the records the application needs to be used. Seeding
+// is idempotent, so it can run on every deploy.
+type SeedUseCase struct {
+	userRepo        repository.UserRepository
+	passwordService service.PasswordService
+}
+
+// NewSeedUseCase creates a new seed use case
+func NewSeedUseCase(userRepo repository.UserRepository, passwordService service.PasswordService) *SeedUseCase {
+	return &SeedUseCase{
+		userRepo:        userRepo,
+		passwordService: passwordService,
+	}
+}
+
+// SeedAdmin creates an admin user, or promotes the existing user with that email. The password
+// of an existing user is left unchanged. It reports whether the database was changed.
+func (uc *SeedUseCase) SeedAdmin(ctx context.Context, email, name, password string) (bool, error) {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return false, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err == nil {
+		if user.IsAdmin() {
+			return false, nil
+		}
+		user.PromoteToAdmin()
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return false, fmt.Errorf("failed to promote user: %w", err)
+		}
+		return true, nil
+	}
+
+	hashedPassword, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user = entity.NewUser(email, name, entity.RoleAdmin)
+	user.SetPassword(hashedPassword)
+	user.VerifyEmail()
+
+	if err := user.Validate(); err != nil {
+		return false, domain.NewValidationError(err)
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return false, fmt.Errorf("failed to create user: %w", err)
+	}
+	return true, nil
+}
+
+// demoUsers are the users created by SeedDemoData
+var demoUsers = []struct {
+	email string
+	name  string
+}{
+	{"alice@demo.example.com", "Alice Demo"},
+	{"bob@demo.example.com", "Bob Demo"},
+	{"carol@demo.example.com", "Carol Demo"},
+}
+
+// SeedDemoData creates demo users sharing password, skipping those that already exist. It
+// returns the number of users created.
+func (uc *SeedUseCase) SeedDemoData(ctx context.Context, password string) (int, error) {
+	created := 0
+	for _, demo := range demoUsers {
+		exists, err := uc.userRepo.EmailExists(ctx, demo.email)
+		if err != nil {
+			return created, fmt.Errorf("failed to check demo user: %w", err)
+		}
+		if exists {
+			continue
+		}
+
+		hashedPassword, err := uc.passwordService.HashPassword(password)
+		if err != nil {
+			return created, fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		user := entity.NewUser(demo.email, demo.name, entity.RoleUser)
+		user.SetPassword(hashedPassword)
+		user.VerifyEmail()
+
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return created, fmt.Errorf("failed to create demo user: %w", err)
+		}
+		created++
+	}
+	return created, nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// ListSessionsUseCase handles listing the devices the current user is signed in on
+type ListSessionsUseCase struct {
+	tokenRepo repository.TokenRepository
+}
+
+// NewListSessionsUseCase creates a new list sessions use case
+func NewListSessionsUseCase(tokenRepo repository.TokenRepository) *ListSessionsUseCase {
+	return &ListSessionsUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Execute executes the list sessions use case
+func (uc *ListSessionsUseCase) Execute(ctx context.Context, userID string) ([]dto.SessionResponse, error) {
+	tokens, err := uc.tokenRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return dto.ToSessionListResponse(tokens), nil
+}
+
+// RevokeSessionUseCase handles signing the current user out of one device
+type RevokeSessionUseCase struct {
+	tokenRepo repository.TokenRepository
+}
+
+// NewRevokeSessionUseCase creates a new revoke session use case
+func NewRevokeSessionUseCase(tokenRepo repository.TokenRepository) *RevokeSessionUseCase {
+	return &RevokeSessionUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Execute revokes the refresh token of the session. The device's access token stays valid
+// until it expires.
+func (uc *RevokeSessionUseCase) Execute(ctx context.Context, userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return domain.ErrSessionNotFound
+	}
+
+	// The token is expired rather than deleted, so the device using it again isn't taken for
+	// a stolen token being reused
+	err := uc.tokenRepo.RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
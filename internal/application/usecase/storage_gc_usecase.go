@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/storage"
+)
+
+// StorageGCUseCase deletes uploaded files no document or user refers to, such as files whose
+// upload was rolled back, whose delete job was lost, or the avatars of purged users
+type StorageGCUseCase struct {
+	userRepo     repository.UserRepository
+	documentRepo repository.DocumentRepository
+	storage      *storage.S3Client
+}
+
+// NewStorageGCUseCase creates a new storage garbage collection use case
+func NewStorageGCUseCase(userRepo repository.UserRepository, documentRepo repository.DocumentRepository, storage *storage.S3Client) *StorageGCUseCase {
+	return &StorageGCUseCase{
+		userRepo:     userRepo,
+		documentRepo: documentRepo,
+		storage:      storage,
+	}
+}
+
+// Execute deletes the orphaned files older than minAge, and returns how many were deleted.
+// Younger files are kept, since a file is uploaded before the record referring to it is saved.
+func (uc *StorageGCUseCase) Execute(ctx context.Context, minAge time.Duration) (int64, error) {
+	var deleted int64
+	err := uc.storage.ListFiles(ctx, time.Now().Add(-minAge), func(fileURLs []string) error {
+		documentFiles, err := uc.documentRepo.FileURLsInUse(ctx, fileURLs)
+		if err != nil {
+			return fmt.Errorf("failed to find document files in use: %w", err)
+		}
+		avatars, err := uc.userRepo.AvatarsInUse(ctx, fileURLs)
+		if err != nil {
+			return fmt.Errorf("failed to find avatars in use: %w", err)
+		}
+
+		inUse := make(map[string]bool, len(documentFiles)+len(avatars))
+		for _, fileURL := range append(documentFiles, avatars...) {
+			inUse[fileURL] = true
+		}
+
+		for _, fileURL := range fileURLs {
+			if inUse[fileURL] {
+				continue
+			}
+			if err := uc.storage.DeleteFile(ctx, fileURL); err != nil {
+				return err
+			}
+			deleted++
+			logging.FromContext(ctx).WithField("file_url", fileURL).Debug("Deleted orphaned file")
+		}
+		return nil
+	})
+	return deleted, err
+}
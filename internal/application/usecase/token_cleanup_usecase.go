@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// TokenCleanupUseCase deletes the refresh tokens that can no longer be used, so the token table
+// doesn't grow forever. Revoked tokens are expired, so they are deleted too.
+type TokenCleanupUseCase struct {
+	tokenRepo repository.TokenRepository
+}
+
+// NewTokenCleanupUseCase creates a new token cleanup use case
+func NewTokenCleanupUseCase(tokenRepo repository.TokenRepository) *TokenCleanupUseCase {
+	return &TokenCleanupUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Execute deletes the expired tokens and returns how many were deleted and how many rows the
+// token table has left
+func (uc *TokenCleanupUseCase) Execute(ctx context.Context) (deleted, remaining int64, err error) {
+	deleted, err = uc.tokenRepo.DeleteExpiredTokens(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	remaining, err = uc.tokenRepo.CountRows(ctx)
+	if err != nil {
+		return deleted, 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return deleted, remaining, nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// TrashPurgeUseCase permanently deletes the users and documents that were soft-deleted longer
+// ago than the retention, so deleted records can be restored for a while but don't stay forever.
+// The files of deleted documents are removed when they are deleted; the avatars of purged users
+// are left to the storage garbage collection.
+type TrashPurgeUseCase struct {
+	userRepo     repository.UserRepository
+	documentRepo repository.DocumentRepository
+}
+
+// NewTrashPurgeUseCase creates a new trash purge use case
+func NewTrashPurgeUseCase(userRepo repository.UserRepository, documentRepo repository.DocumentRepository) *TrashPurgeUseCase {
+	return &TrashPurgeUseCase{
+		userRepo:     userRepo,
+		documentRepo: documentRepo,
+	}
+}
+
+// Execute purges the records soft-deleted more than retention ago, and returns how many users
+// and documents were purged
+func (uc *TrashPurgeUseCase) Execute(ctx context.Context, retention time.Duration) (users, documents int64, err error) {
+	before := time.Now().Add(-retention)
+
+	documents, err = uc.documentRepo.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to purge deleted documents: %w", err)
+	}
+
+	users, err = uc.userRepo.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return 0, documents, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+	return users, documents, nil
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// TwoFactorUseCase handles the phone number of the current user, the second factor of its
+// logins and its recovery codes
+type TwoFactorUseCase struct {
+	userRepo        repository.UserRepository
+	unitOfWork      repository.UnitOfWork
+	passwordService service.PasswordService
+	recoveryCodes   *service.RecoveryCodeService
+	// otp texts one-time codes; nil when no SMS provider is configured
+	otp            *service.OTPService
+	securityEvents *service.SecurityEventService
+}
+
+// NewTwoFactorUseCase creates a new two-factor use case. otp and securityEvents may be nil.
+func NewTwoFactorUseCase(
+	userRepo repository.UserRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	recoveryCodes *service.RecoveryCodeService,
+	otp *service.OTPService,
+	securityEvents *service.SecurityEventService,
+) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		userRepo:        userRepo,
+		unitOfWork:      unitOfWork,
+		passwordService: passwordService,
+		recoveryCodes:   recoveryCodes,
+		otp:             otp,
+		securityEvents:  securityEvents,
+	}
+}
+
+// RequestPhoneVerification texts a code to a phone number the user wants to add. The number
+// replaces the user's current one once the code is entered.
+func (uc *TwoFactorUseCase) RequestPhoneVerification(ctx context.Context, userID string, req dto.PhoneVerificationRequest) error {
+	if uc.otp == nil {
+		return domain.ErrSMSUnavailable
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return uc.otp.Send(ctx, service.OTPPurposePhone, user.ID, &service.OTPChallenge{
+		UserID: user.ID,
+		Phone:  req.PhoneNumber,
+	})
+}
+
+// VerifyPhone sets the phone number the code was texted to as the user's verified number
+func (uc *TwoFactorUseCase) VerifyPhone(ctx context.Context, userID string, req dto.VerifyPhoneRequest) (*dto.UserResponse, error) {
+	if uc.otp == nil {
+		return nil, domain.ErrSMSUnavailable
+	}
+
+	challenge, err := uc.otp.Verify(ctx, service.OTPPurposePhone, userID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	previousPhone := user.Phone
+	user.SetVerifiedPhone(challenge.Phone)
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	// SMS codes of later logins go to the new number
+	if previousPhone != nil && *previousPhone != challenge.Phone && uc.securityEvents != nil {
+		details := map[string]string{"previous_phone": dto.MaskPhone(*previousPhone)}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventPhoneChanged, req.ClientIP, req.UserAgent, details)
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// Enable turns on the second factor of the user's logins after checking the password. SMS
+// codes need a verified phone number. Turning it on generates the user's recovery codes, which
+// are returned this once.
+func (uc *TwoFactorUseCase) Enable(ctx context.Context, userID string, req dto.EnableTwoFactorRequest) (*dto.TwoFactorResponse, error) {
+	user, err := uc.findWithPassword(ctx, userID, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	method := entity.TwoFactor(req.Method)
+	if method == entity.TwoFactorSMS {
+		if uc.otp == nil {
+			return nil, domain.ErrSMSUnavailable
+		}
+		if user.Phone == nil || !user.PhoneVerified {
+			return nil, domain.ErrPhoneNotVerified
+		}
+	}
+
+	var codes []string
+	if user.TwoFactor != method {
+		enabling := !user.HasTwoFactor()
+		user.SetTwoFactor(method)
+		err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			if err := uc.userRepo.Update(ctx, user); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+			if !enabling {
+				return nil
+			}
+			var err error
+			codes, err = uc.recoveryCodes.Generate(ctx, user.ID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		uc.record(ctx, user, entity.SecurityEventTwoFactorEnabled, method, req.ClientIP, req.UserAgent)
+	}
+
+	return &dto.TwoFactorResponse{
+		User:          dto.ToUserResponse(user),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Disable turns off the second factor of the user's logins after checking the password, and
+// deletes its recovery codes
+func (uc *TwoFactorUseCase) Disable(ctx context.Context, userID string, req dto.DisableTwoFactorRequest) (*dto.UserResponse, error) {
+	user, err := uc.findWithPassword(ctx, userID, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTwoFactor() {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+
+	method := user.TwoFactor
+	user.SetTwoFactor(entity.TwoFactorNone)
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return uc.recoveryCodes.Delete(ctx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, user, entity.SecurityEventTwoFactorDisabled, method, req.ClientIP, req.UserAgent)
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// RecoveryCodes returns how many of the user's recovery codes are left
+func (uc *TwoFactorUseCase) RecoveryCodes(ctx context.Context, userID string) (*dto.RecoveryCodesResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.HasTwoFactor() {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+
+	remaining, err := uc.recoveryCodes.Remaining(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{Remaining: remaining}, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking the password, and
+// returns the new ones this once
+func (uc *TwoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, userID string, req dto.RegenerateRecoveryCodesRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := uc.findWithPassword(ctx, userID, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTwoFactor() {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+
+	codes, err := uc.recoveryCodes.Generate(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, user, entity.SecurityEventRecoveryCodesRegenerated, user.TwoFactor, req.ClientIP, req.UserAgent)
+
+	return &dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Remaining:     int64(len(codes)),
+	}, nil
+}
+
+// findWithPassword finds the user and checks its password. The second factor only applies to
+// password logins, so users without a password can't turn it on.
+func (uc *TwoFactorUseCase) findWithPassword(ctx context.Context, userID, password string) (*entity.User, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.HasPassword() {
+		return nil, domain.ErrOAuthRequired
+	}
+	if err := uc.passwordService.VerifyPassword(password, *user.Password); err != nil {
+		return nil, domain.ErrIncorrectPassword
+	}
+	return user, nil
+}
+
+// record records a change of the second factor, naming its method
+func (uc *TwoFactorUseCase) record(ctx context.Context, user *entity.User, eventType entity.SecurityEventType, method entity.TwoFactor, ip, userAgent string) {
+	if uc.securityEvents == nil {
+		return
+	}
+	uc.securityEvents.Record(ctx, user, eventType, ip, userAgent, map[string]string{"method": string(method)})
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// UserMetadataUseCase handles the metadata fields users can set on their profile (admin only)
+type UserMetadataUseCase struct {
+	fieldRepo repository.UserMetadataFieldRepository
+}
+
+// NewUserMetadataUseCase creates a new user metadata use case
+func NewUserMetadataUseCase(fieldRepo repository.UserMetadataFieldRepository) *UserMetadataUseCase {
+	return &UserMetadataUseCase{
+		fieldRepo: fieldRepo,
+	}
+}
+
+// List returns every metadata field, by key
+func (uc *UserMetadataUseCase) List(ctx context.Context) (*dto.MetadataFieldsListResponse, error) {
+	fields, err := uc.fieldRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata fields: %w", err)
+	}
+
+	response := dto.ToMetadataFieldsListResponse(fields)
+	return &response, nil
+}
+
+// Create defines a metadata field users can set
+func (uc *UserMetadataUseCase) Create(ctx context.Context, req dto.CreateMetadataFieldRequest) (*dto.MetadataFieldResponse, error) {
+	field := entity.NewUserMetadataField(req.Key, entity.MetadataFieldType(req.Type), req.Description)
+	if err := field.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.fieldRepo.Create(ctx, field); err != nil {
+		if errors.Is(err, domain.ErrDuplicate) {
+			return nil, domain.ErrMetadataFieldExists
+		}
+		return nil, fmt.Errorf("failed to create metadata field: %w", err)
+	}
+
+	response := dto.ToMetadataFieldResponse(field)
+	return &response, nil
+}
+
+// Update replaces the type and description of a metadata field. The values users already have
+// are kept, and checked against the new type when they are set again.
+func (uc *UserMetadataUseCase) Update(ctx context.Context, key string, req dto.UpdateMetadataFieldRequest) (*dto.MetadataFieldResponse, error) {
+	field, err := uc.fieldRepo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata field: %w", err)
+	}
+
+	field.Type = entity.MetadataFieldType(req.Type)
+	field.Description = req.Description
+	if err := field.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.fieldRepo.Update(ctx, field); err != nil {
+		return nil, fmt.Errorf("failed to update metadata field: %w", err)
+	}
+
+	response := dto.ToMetadataFieldResponse(field)
+	return &response, nil
+}
+
+// Delete deletes a metadata field, so users can no longer set it. The values users have are kept
+// until they remove them.
+func (uc *UserMetadataUseCase) Delete(ctx context.Context, key string) error {
+	if err := uc.fieldRepo.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete metadata field: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/oauth"
+)
+
+// UserProviderUseCase handles the ways the current user signs in: adding and removing a password
+// and linking and unlinking OAuth provider accounts
+type UserProviderUseCase struct {
+	userRepo         repository.UserRepository
+	userProviderRepo repository.UserProviderRepository
+	unitOfWork       repository.UnitOfWork
+	passwordService  service.PasswordService
+	securityEvents   *service.SecurityEventService
+}
+
+// NewUserProviderUseCase creates a new user provider use case. securityEvents may be nil.
+func NewUserProviderUseCase(
+	userRepo repository.UserRepository,
+	userProviderRepo repository.UserProviderRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	securityEvents *service.SecurityEventService,
+) *UserProviderUseCase {
+	return &UserProviderUseCase{
+		userRepo:         userRepo,
+		userProviderRepo: userProviderRepo,
+		unitOfWork:       unitOfWork,
+		passwordService:  passwordService,
+		securityEvents:   securityEvents,
+	}
+}
+
+// List returns the ways the user can sign in
+func (uc *UserProviderUseCase) List(ctx context.Context, userID string) ([]dto.LinkedProviderResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return uc.list(ctx, user)
+}
+
+// AddPassword lets a user without a password, such as one created with Google, also sign in
+// with a password
+func (uc *UserProviderUseCase) AddPassword(ctx context.Context, userID string, req dto.LinkProviderRequest) ([]dto.LinkedProviderResponse, error) {
+	if req.Password == "" {
+		return nil, domain.NewValidationError(errors.New("password is required"))
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.HasPassword() {
+		return nil, domain.ErrProviderAlreadyLinked
+	}
+
+	// Enforces the password policy
+	hashedPassword, err := uc.passwordService.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user.SetPassword(hashedPassword)
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	uc.record(ctx, user, entity.SecurityEventProviderLinked, dto.LocalProvider, req.ClientIP, req.UserAgent)
+	return uc.list(ctx, user)
+}
+
+// Link links the user's account at the named provider. Linking the account again does nothing,
+// but an account linked to another user, or a second account of a provider, is refused.
+func (uc *UserProviderUseCase) Link(ctx context.Context, userID, providerName string, account *oauth.UserInfo, clientIP, userAgent string) ([]dto.LinkedProviderResponse, error) {
+	if account == nil || account.ID == "" {
+		return nil, domain.ErrOAuthFailed
+	}
+	provider := entity.OAuthProvider(providerName)
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	existing, err := uc.userProviderRepo.FindByProviderID(ctx, provider, account.ID)
+	switch {
+	case err == nil && existing.UserID == user.ID:
+		return uc.list(ctx, user)
+	case err == nil:
+		return nil, domain.ErrProviderAccountInUse
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, fmt.Errorf("failed to find user provider: %w", err)
+	}
+
+	err = uc.userProviderRepo.Create(ctx, entity.NewUserProvider(user.ID, provider, account.ID, account.Email))
+	if errors.Is(err, domain.ErrDuplicate) {
+		return nil, domain.ErrProviderAlreadyLinked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to link provider account: %w", err)
+	}
+
+	uc.record(ctx, user, entity.SecurityEventProviderLinked, providerName, clientIP, userAgent)
+	return uc.list(ctx, user)
+}
+
+// Unlink removes a way the user signs in: the password for "local", or the account at the named
+// provider. The last way can't be removed, so the user can always sign in.
+func (uc *UserProviderUseCase) Unlink(ctx context.Context, userID, providerName, clientIP, userAgent string) ([]dto.LinkedProviderResponse, error) {
+	var user *entity.User
+	err := uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+
+		links, err := uc.userProviderRepo.ListByUserID(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list user providers: %w", err)
+		}
+
+		if providerName == dto.LocalProvider {
+			if !user.HasPassword() {
+				return domain.ErrProviderNotLinked
+			}
+			if len(links) == 0 {
+				return domain.ErrLastSignInMethod
+			}
+			user.RemovePassword()
+		} else {
+			provider := entity.OAuthProvider(providerName)
+			if !hasProvider(links, provider) {
+				return domain.ErrProviderNotLinked
+			}
+			if !user.HasPassword() && len(links) == 1 {
+				return domain.ErrLastSignInMethod
+			}
+			if err := uc.userProviderRepo.Delete(ctx, user.ID, provider); err != nil {
+				return fmt.Errorf("failed to unlink provider account: %w", err)
+			}
+		}
+
+		// Updating the user, even when only a link changed, makes concurrent unlinks conflict,
+		// so they can't remove the last two ways between them
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, user, entity.SecurityEventProviderUnlinked, providerName, clientIP, userAgent)
+	return uc.list(ctx, user)
+}
+
+// list returns the ways the user can sign in
+func (uc *UserProviderUseCase) list(ctx context.Context, user *entity.User) ([]dto.LinkedProviderResponse, error) {
+	links, err := uc.userProviderRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user providers: %w", err)
+	}
+	return dto.ToLinkedProviderListResponse(user, links), nil
+}
+
+// record records a security event about a sign-in method of the user
+func (uc *UserProviderUseCase) record(ctx context.Context, user *entity.User, eventType entity.SecurityEventType, providerName, clientIP, userAgent string) {
+	if uc.securityEvents == nil {
+		return
+	}
+	details := map[string]string{"provider": providerName}
+	uc.securityEvents.Record(ctx, user, eventType, clientIP, userAgent, details)
+}
+
+// hasProvider reports whether one of the links is to the provider
+func hasProvider(links []*entity.UserProvider, provider entity.Provider) bool {
+	for _, link := range links {
+		if link.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
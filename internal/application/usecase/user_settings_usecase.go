@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// UserSettingsUseCase handles users reading and changing their settings, and unsubscribing from
+// notification emails
+type UserSettingsUseCase struct {
+	userRepo          repository.UserRepository
+	settingsRepo      repository.UserSettingsRepository
+	unitOfWork        repository.UnitOfWork
+	unsubscribeTokens *service.UnsubscribeTokenService
+}
+
+// NewUserSettingsUseCase creates a new user settings use case
+func NewUserSettingsUseCase(userRepo repository.UserRepository, settingsRepo repository.UserSettingsRepository, unitOfWork repository.UnitOfWork, unsubscribeTokens *service.UnsubscribeTokenService) *UserSettingsUseCase {
+	return &UserSettingsUseCase{
+		userRepo:          userRepo,
+		settingsRepo:      settingsRepo,
+		unitOfWork:        unitOfWork,
+		unsubscribeTokens: unsubscribeTokens,
+	}
+}
+
+// Get returns the settings of the user, the defaults until it changes them
+func (uc *UserSettingsUseCase) Get(ctx context.Context, userID string) (*dto.UserSettingsResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	settings, err := uc.settingsRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserSettingsResponse(settings, user.Locale)
+	return &response, nil
+}
+
+// Update changes the settings of the request, keeping those it leaves out. The locale is stored
+// on the user, and applies to access tokens from the next login or token refresh.
+func (uc *UserSettingsUseCase) Update(ctx context.Context, userID string, req dto.UpdateUserSettingsRequest) (*dto.UserSettingsResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	settings, err := uc.settingsRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := *settings
+
+	if req.Timezone != nil {
+		settings.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.Theme != nil {
+		settings.Theme = entity.Theme(*req.Theme)
+	}
+	if req.EmailNotifications != nil {
+		if req.EmailNotifications.SecurityAlerts != nil {
+			settings.EmailNotifications.SecurityAlerts = *req.EmailNotifications.SecurityAlerts
+		}
+		if req.EmailNotifications.ProductUpdates != nil {
+			settings.EmailNotifications.ProductUpdates = *req.EmailNotifications.ProductUpdates
+		}
+	}
+	// Validated on binding, but the settings may come from elsewhere
+	if !settings.Theme.IsValid() {
+		return nil, domain.ErrValidation.WithMessage("theme must be system, light or dark")
+	}
+
+	localeChanged := req.Locale != nil && strings.TrimSpace(*req.Locale) != user.Locale
+	if localeChanged {
+		user.SetLocale(*req.Locale)
+	}
+
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if localeChanged {
+			if err := uc.userRepo.Update(ctx, user); err != nil {
+				return fmt.Errorf("failed to update user locale: %w", err)
+			}
+		}
+		if *settings != before {
+			if err := uc.settingsRepo.Save(ctx, settings); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserSettingsResponse(settings, user.Locale)
+	return &response, nil
+}
+
+// Unsubscribe turns off the notification category of the token of an unsubscribe link, without
+// signing in. Following a link again changes nothing.
+func (uc *UserSettingsUseCase) Unsubscribe(ctx context.Context, req dto.UnsubscribeRequest) (*dto.UnsubscribeResponse, error) {
+	userID, category, err := uc.unsubscribeTokens.Parse(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The links of deleted accounts stop working
+	if _, err := uc.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidUnsubscribeToken
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	settings, err := uc.settingsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.EmailNotifications.Enabled(category) {
+		settings.EmailNotifications.SetEnabled(category, false)
+		if err := uc.settingsRepo.Save(ctx, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.UnsubscribeResponse{
+		Category: string(category),
+		Message:  "Unsubscribed successfully",
+	}, nil
+}
@@ -2,10 +2,21 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 )
 
 // GetUserProfileUseCase handles getting user profile
@@ -26,9 +37,6 @@ func (uc *GetUserProfileUseCase) Execute(ctx context.Context, userID string) (*d
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
 
 	response := dto.ToUserResponse(user)
 	return &response, nil
@@ -36,32 +44,103 @@ func (uc *GetUserProfileUseCase) Execute(ctx context.Context, userID string) (*d
 
 // UpdateUserProfileUseCase handles updating user profile
 type UpdateUserProfileUseCase struct {
-	userRepo repository.UserRepository
+	userRepo          repository.UserRepository
+	metadataFieldRepo repository.UserMetadataFieldRepository
+	activities        *service.ActivityService
 }
 
 // NewUpdateUserProfileUseCase creates a new update user profile use case
-func NewUpdateUserProfileUseCase(userRepo repository.UserRepository) *UpdateUserProfileUseCase {
+func NewUpdateUserProfileUseCase(userRepo repository.UserRepository, metadataFieldRepo repository.UserMetadataFieldRepository, activities *service.ActivityService) *UpdateUserProfileUseCase {
 	return &UpdateUserProfileUseCase{
-		userRepo: userRepo,
+		userRepo:          userRepo,
+		metadataFieldRepo: metadataFieldRepo,
+		activities:        activities,
 	}
 }
 
-// Execute executes the update user profile use case
+// Execute executes the update user profile use case. An update changing anything is added to
+// the user's activity feed, with the fields it changed.
 func (uc *UpdateUserProfileUseCase) Execute(ctx context.Context, userID string, req dto.UpdateProfileRequest) (*dto.UserResponse, error) {
 	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
+	before := *user
 
 	// Update profile
 	user.UpdateProfile(req.Name, req.Avatar)
+	if req.Locale != nil {
+		user.SetLocale(*req.Locale)
+	}
+	if req.Metadata != nil {
+		if err := uc.updateMetadata(ctx, user, req.Metadata); err != nil {
+			return nil, err
+		}
+	}
 
+	return uc.save(ctx, user, &before)
+}
+
+// Patch changes only the fields of the request that are sent, or with an update mask, the fields
+// it names, clearing those the request leaves out
+func (uc *UpdateUserProfileUseCase) Patch(ctx context.Context, userID string, req dto.PatchProfileRequest) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	before := *user
+
+	masked := len(req.UpdateMask) > 0
+	update := func(field string, sent bool) bool {
+		if masked {
+			return slices.Contains(req.UpdateMask, field)
+		}
+		return sent
+	}
+
+	if update("name", req.Name != nil) {
+		var name string
+		if req.Name != nil {
+			name = *req.Name
+		}
+		user.SetName(name)
+	}
+	if update("avatar", req.Avatar != nil) {
+		user.SetAvatar(req.Avatar)
+	}
+	if update("locale", req.Locale != nil) {
+		var locale string
+		if req.Locale != nil {
+			locale = *req.Locale
+		}
+		user.SetLocale(locale)
+	}
+	if update("metadata", req.Metadata != nil) {
+		changes := maps.Clone(req.Metadata)
+		if masked {
+			// The mask replaces the metadata, so the fields left out are removed
+			if changes == nil {
+				changes = entity.UserMetadata{}
+			}
+			for key := range user.Metadata {
+				if _, ok := changes[key]; !ok {
+					changes[key] = nil
+				}
+			}
+		}
+		if err := uc.updateMetadata(ctx, user, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.save(ctx, user, &before)
+}
+
+// save validates and saves the updated user, and adds the update to the user's activity feed
+func (uc *UpdateUserProfileUseCase) save(ctx context.Context, user, before *entity.User) (*dto.UserResponse, error) {
 	// Validate updated user
 	if err := user.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid user data: %w", err)
+		return nil, domain.NewValidationError(err)
 	}
 
 	// Save changes
@@ -69,10 +148,186 @@ func (uc *UpdateUserProfileUseCase) Execute(ctx context.Context, userID string,
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if changed := changedProfileFields(before, user); len(changed) > 0 {
+		uc.activities.Record(ctx, user.ID, entity.ActivityProfileUpdated, map[string]string{
+			"fields": strings.Join(changed, ","),
+		})
+	}
+
 	response := dto.ToUserResponse(user)
 	return &response, nil
 }
 
+// changedProfileFields returns the JSON names of the profile fields that differ between the
+// user before and after an update
+func changedProfileFields(before, after *entity.User) []string {
+	changed := []string{}
+	if before.Name != after.Name {
+		changed = append(changed, "name")
+	}
+	if !reflect.DeepEqual(before.Avatar, after.Avatar) {
+		changed = append(changed, "avatar")
+	}
+	if before.Locale != after.Locale {
+		changed = append(changed, "locale")
+	}
+	// Users without metadata may have a nil or an empty map
+	if len(before.Metadata)+len(after.Metadata) > 0 && !reflect.DeepEqual(before.Metadata, after.Metadata) {
+		changed = append(changed, "metadata")
+	}
+	return changed
+}
+
+// updateMetadata sets the user's metadata fields of the changes, which must be defined by admins
+func (uc *UpdateUserProfileUseCase) updateMetadata(ctx context.Context, user *entity.User, changes entity.UserMetadata) error {
+	fields, err := uc.metadataFieldRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list metadata fields: %w", err)
+	}
+	if err := entity.NewUserMetadataSchema(fields).Validate(changes); err != nil {
+		return domain.NewValidationError(err)
+	}
+
+	user.UpdateMetadata(changes)
+	return nil
+}
+
+// ChangePasswordUseCase handles changing the current user's password
+type ChangePasswordUseCase struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.TokenRepository
+	unitOfWork      repository.UnitOfWork
+	passwordService service.PasswordService
+	passwordHistory *service.PasswordHistoryService
+	tokenService    service.TokenService
+	// denylist rejects the access tokens of the other devices; nil leaves them valid until they
+	// expire
+	denylist       *service.TokenDenylistService
+	securityEvents *service.SecurityEventService
+	auditLog       *service.AuditLogService
+}
+
+// NewChangePasswordUseCase creates a new change password use case. denylist and securityEvents
+// may be nil.
+func NewChangePasswordUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	unitOfWork repository.UnitOfWork,
+	passwordService service.PasswordService,
+	passwordHistory *service.PasswordHistoryService,
+	tokenService service.TokenService,
+	denylist *service.TokenDenylistService,
+	securityEvents *service.SecurityEventService,
+	auditLog *service.AuditLogService,
+) *ChangePasswordUseCase {
+	return &ChangePasswordUseCase{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		unitOfWork:      unitOfWork,
+		passwordService: passwordService,
+		passwordHistory: passwordHistory,
+		tokenService:    tokenService,
+		denylist:        denylist,
+		securityEvents:  securityEvents,
+		auditLog:        auditLog,
+	}
+}
+
+// Execute sets a new password after checking the current one, and revokes the user's refresh
+// tokens and denies its access tokens, so other devices have to log in again. The device
+// changing it gets new tokens in a new session.
+func (uc *ChangePasswordUseCase) Execute(ctx context.Context, userID string, req dto.ChangePasswordRequest) (*dto.AuthResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.HasPassword() {
+		return nil, domain.ErrOAuthRequired
+	}
+
+	if err := uc.passwordService.VerifyPassword(req.CurrentPassword, *user.Password); err != nil {
+		return nil, domain.ErrIncorrectPassword
+	}
+
+	// Enforces the password policy
+	hashedPassword, err := uc.passwordService.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.passwordHistory.CheckReuse(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	// The new tokens are issued after it
+	changedAt := time.Now()
+
+	var response *dto.AuthResponse
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := uc.passwordHistory.Remember(ctx, user); err != nil {
+			return err
+		}
+		user.SetPassword(hashedPassword)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := uc.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+
+		response, err = uc.issueTokens(ctx, user, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The password is changed either way, so failing to deny the access tokens is only logged
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUserBefore(ctx, user.ID, changedAt); err != nil {
+			logging.ModuleFromContext(ctx, logging.ModuleAuth).
+				WithError(err).
+				WithField("user_id", user.ID).
+				Warn("Failed to deny access tokens")
+		}
+	}
+
+	if uc.securityEvents != nil {
+		details := map[string]string{"changed_by": "user"}
+		uc.securityEvents.Record(ctx, user, entity.SecurityEventPasswordChanged, req.ClientIP, req.UserAgent, details)
+	}
+	uc.auditLog.Record(ctx, entity.AuditActionPasswordChanged, entity.AuditTargetUser, user.ID, nil)
+	return response, nil
+}
+
+// issueTokens issues new tokens, starting a session of the device
+func (uc *ChangePasswordUseCase) issueTokens(ctx context.Context, user *entity.User, req dto.ChangePasswordRequest) (*dto.AuthResponse, error) {
+	accessToken, err := uc.tokenService.GenerateAccessToken(user.ID, user.Email, string(user.Role), user.Locale, string(user.RateLimitTier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(uc.tokenService.GetTokenExpiration(service.TokenTypeRefresh))
+	refreshToken, err := uc.tokenService.GenerateRefreshToken(user.ID, user.Email, string(user.Role), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	refreshTokenEntity := entity.NewToken(
+		user.ID,
+		refreshToken,
+		expiresAt,
+	)
+	refreshTokenEntity.SetDevice(req.ClientIP, req.UserAgent)
+
+	if err := uc.tokenRepo.Create(ctx, refreshTokenEntity); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	expiresIn := int64(uc.tokenService.GetTokenExpiration(service.TokenTypeAccess).Seconds())
+	response := dto.ToAuthResponse(user, accessToken, refreshToken, expiresIn)
+	return &response, nil
+}
+
 // ListUsersUseCase handles listing users (admin only)
 type ListUsersUseCase struct {
 	userRepo repository.UserRepository
@@ -85,8 +340,16 @@ func NewListUsersUseCase(userRepo repository.UserRepository) *ListUsersUseCase {
 	}
 }
 
-// Execute executes the list users use case
-func (uc *ListUsersUseCase) Execute(ctx context.Context, req dto.PaginationRequest) (*dto.UsersListResponse, error) {
+// Execute lists a page of the users matching the filter, in its sort order
+func (uc *ListUsersUseCase) Execute(ctx context.Context, filterReq dto.UserFilterRequest, req dto.PaginationRequest) (*dto.UsersListResponse, error) {
+	filter, err := toUserFilter(filterReq)
+	if err != nil {
+		return nil, err
+	}
+	if filterReq.Deleted {
+		ctx = repository.WithOnlyDeleted(ctx)
+	}
+
 	// Set default pagination values
 	if req.Limit <= 0 || req.Limit > 100 {
 		req.Limit = 10
@@ -96,12 +359,12 @@ func (uc *ListUsersUseCase) Execute(ctx context.Context, req dto.PaginationReque
 	}
 
 	// Get users and total count
-	users, err := uc.userRepo.List(ctx, req.Limit, req.Offset)
+	users, err := uc.userRepo.List(ctx, filter, toUserSort(filterReq), req.Limit, req.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
-	total, err := uc.userRepo.Count(ctx)
+	total, err := uc.userRepo.Count(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -110,109 +373,414 @@ func (uc *ListUsersUseCase) Execute(ctx context.Context, req dto.PaginationReque
 	return &response, nil
 }
 
+// ExecuteAfter lists a keyset page of the users matching the filter, which stays fast on large
+// tables. It has no total count, and is always sorted newest first.
+func (uc *ListUsersUseCase) ExecuteAfter(ctx context.Context, filterReq dto.UserFilterRequest, req dto.CursorPaginationRequest) (*dto.UsersCursorListResponse, error) {
+	if filterReq.Sort != "" || filterReq.Order != "" {
+		return nil, domain.ErrValidation.WithMessage("The sort and order parameters can't be combined with cursor")
+	}
+	filter, err := toUserFilter(filterReq)
+	if err != nil {
+		return nil, err
+	}
+	if filterReq.Deleted {
+		ctx = repository.WithOnlyDeleted(ctx)
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+
+	after, err := dto.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more user than the page tells whether there is a next page
+	users, err := uc.userRepo.ListAfter(ctx, filter, after, req.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	response := &dto.UsersCursorListResponse{
+		Users: []dto.UserResponse{},
+		Limit: req.Limit,
+	}
+	if len(users) > req.Limit {
+		users = users[:req.Limit]
+		last := users[len(users)-1]
+		response.NextCursor = dto.EncodeCursor(repository.CursorOf(last.CreatedAt, last.ID))
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, dto.ToUserResponse(user))
+	}
+	return response, nil
+}
+
+// toUserFilter converts the filters of the user list, whose days were validated on binding
+func toUserFilter(req dto.UserFilterRequest) (repository.UserFilter, error) {
+	filter := repository.UserFilter{
+		Search:        strings.TrimSpace(req.Search),
+		Role:          entity.Role(strings.ToUpper(req.Role)),
+		Provider:      entity.Provider(strings.ToUpper(req.Provider)),
+		EmailVerified: req.EmailVerified,
+	}
+	if req.CreatedFrom != "" {
+		day, err := time.Parse(time.DateOnly, req.CreatedFrom)
+		if err != nil {
+			return filter, domain.NewValidationError(err)
+		}
+		filter.CreatedFrom = day
+	}
+	// The last day is included, up to the start of the next one
+	if req.CreatedTo != "" {
+		day, err := time.Parse(time.DateOnly, req.CreatedTo)
+		if err != nil {
+			return filter, domain.NewValidationError(err)
+		}
+		filter.CreatedBefore = day.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// toUserSort converts the sort order of the user list. The newest users come first by default,
+// and emails and names sort alphabetically.
+func toUserSort(req dto.UserFilterRequest) repository.UserSort {
+	sort := repository.UserSort{Field: repository.UserSortField(req.Sort)}
+	if sort.Field == "" {
+		sort.Field = repository.UserSortCreatedAt
+	}
+	switch req.Order {
+	case "asc":
+		sort.Ascending = true
+	case "":
+		sort.Ascending = sort.Field != repository.UserSortCreatedAt
+	}
+	return sort
+}
+
+// userExportBatchSize is how many users an export reads at a time
+const userExportBatchSize = 500
+
+// userExportColumns are the columns user exports can have, by name
+var userExportColumns = map[string]func(user *entity.User) string{
+	"id":             func(user *entity.User) string { return user.ID },
+	"email":          func(user *entity.User) string { return user.Email },
+	"name":           func(user *entity.User) string { return user.Name },
+	"role":           func(user *entity.User) string { return string(user.Role) },
+	"provider":       func(user *entity.User) string { return string(user.Provider) },
+	"email_verified": func(user *entity.User) string { return strconv.FormatBool(user.EmailVerified) },
+	"locale":         func(user *entity.User) string { return user.Locale },
+	"phone": func(user *entity.User) string {
+		if user.Phone == nil {
+			return ""
+		}
+		return *user.Phone
+	},
+	"two_factor": func(user *entity.User) string { return string(user.TwoFactor) },
+	"metadata": func(user *entity.User) string {
+		if len(user.Metadata) == 0 {
+			return ""
+		}
+		encoded, _ := json.Marshal(user.Metadata)
+		return string(encoded)
+	},
+	"created_at": func(user *entity.User) string { return user.CreatedAt.UTC().Format(time.RFC3339) },
+	"updated_at": func(user *entity.User) string { return user.UpdatedAt.UTC().Format(time.RFC3339) },
+}
+
+// defaultUserExportColumns are the columns of exports that don't choose theirs
+var defaultUserExportColumns = []string{"id", "email", "name", "role", "provider", "email_verified", "created_at"}
+
+// ExportUsersUseCase handles exporting the user list as a table (admin only)
+type ExportUsersUseCase struct {
+	userRepo repository.UserRepository
+}
+
+// NewExportUsersUseCase creates a new export users use case
+func NewExportUsersUseCase(userRepo repository.UserRepository) *ExportUsersUseCase {
+	return &ExportUsersUseCase{
+		userRepo: userRepo,
+	}
+}
+
+// Execute writes a header row with the columns, then a row for each user matching the filter,
+// newest first. The users are read a batch at a time, so the rows are written as they are read,
+// and nothing is written when the request is invalid.
+func (uc *ExportUsersUseCase) Execute(ctx context.Context, filterReq dto.UserFilterRequest, req dto.UserExportRequest, writeRow func(values []string) error) error {
+	if filterReq.Sort != "" || filterReq.Order != "" {
+		return domain.ErrValidation.WithMessage("Exports are sorted newest first, without the sort and order parameters")
+	}
+	filter, err := toUserFilter(filterReq)
+	if err != nil {
+		return err
+	}
+	if filterReq.Deleted {
+		ctx = repository.WithOnlyDeleted(ctx)
+	}
+
+	names := defaultUserExportColumns
+	if req.Columns != "" {
+		names = strings.Split(req.Columns, ",")
+	}
+	columns := make([]string, len(names))
+	values := make([]func(user *entity.User) string, len(names))
+	for i, name := range names {
+		columns[i] = strings.TrimSpace(name)
+		value, ok := userExportColumns[columns[i]]
+		if !ok {
+			return domain.ErrValidation.WithMessage("Unknown export column " + columns[i])
+		}
+		values[i] = value
+	}
+
+	if err := writeRow(columns); err != nil {
+		return err
+	}
+
+	var after *repository.Cursor
+	row := make([]string, len(columns))
+	for {
+		users, err := uc.userRepo.ListAfter(ctx, filter, after, userExportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			for i, value := range values {
+				row[i] = value(user)
+			}
+			if err := writeRow(row); err != nil {
+				return err
+			}
+		}
+		if len(users) < userExportBatchSize {
+			return nil
+		}
+		last := users[len(users)-1]
+		cursor := repository.CursorOf(last.CreatedAt, last.ID)
+		after = &cursor
+	}
+}
+
+// CreateUserUseCase handles admins creating users, e.g. for internal tools, without the checks
+// of public registration (admin only)
+type CreateUserUseCase struct {
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+	passwordService service.PasswordService
+	policy          *service.PolicyService
+	auditLog        *service.AuditLogService
+}
+
+// NewCreateUserUseCase creates a new create user use case
+func NewCreateUserUseCase(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	passwordService service.PasswordService,
+	policy *service.PolicyService,
+	auditLog *service.AuditLogService,
+) *CreateUserUseCase {
+	return &CreateUserUseCase{
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		passwordService: passwordService,
+		policy:          policy,
+		auditLog:        auditLog,
+	}
+}
+
+// Execute creates a user with a built-in or custom role. Roles other than USER need the
+// users:assign_role permission of the admin's role, actorRole. Without a password a temporary
+// one is generated and returned, which the user has to change on its first login.
+func (uc *CreateUserUseCase) Execute(ctx context.Context, actorRole entity.Role, req dto.CreateUserRequest) (*dto.CreateUserResponse, error) {
+	role := entity.RoleUser
+	if req.Role != "" {
+		role = entity.Role(req.Role)
+	}
+	if role != entity.RoleUser {
+		if err := uc.checkRole(ctx, actorRole, role); err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := uc.userRepo.EmailExists(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
+	}
+	if exists {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	password := req.Password
+	var temporaryPassword string
+	if password == "" {
+		if temporaryPassword, err = service.GenerateTemporaryPassword(); err != nil {
+			return nil, err
+		}
+		password = temporaryPassword
+	}
+
+	// Enforces the password policy
+	hashedPassword, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.NewUser(req.Email, req.Name, role)
+	user.SetPassword(hashedPassword)
+	if req.PasswordChangeRequired || temporaryPassword != "" {
+		user.RequirePasswordChange()
+	}
+	if req.EmailVerified {
+		user.VerifyEmail()
+	}
+
+	if err := user.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	uc.auditLog.Record(ctx, entity.AuditActionUserCreated, entity.AuditTargetUser, user.ID, map[string]string{
+		"role":                     string(user.Role),
+		"password_change_required": strconv.FormatBool(user.PasswordChangeRequired),
+	})
+
+	return &dto.CreateUserResponse{
+		User:              dto.ToUserResponse(user),
+		TemporaryPassword: temporaryPassword,
+	}, nil
+}
+
+// checkRole checks that the role exists and that the admin's role can assign it
+func (uc *CreateUserUseCase) checkRole(ctx context.Context, actorRole, role entity.Role) error {
+	allowed, err := uc.policy.Can(ctx, actorRole, entity.PermissionUsersAssignRole)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.ErrForbidden
+	}
+
+	if _, ok := entity.BuiltinPermissions(role); !ok {
+		if _, err := uc.roleRepo.FindByName(ctx, role); err != nil {
+			return fmt.Errorf("failed to find role: %w", err)
+		}
+	}
+
+	if allowed, err = uc.policy.CanGrant(ctx, actorRole, role); err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.ErrRoleNotGrantable
+	}
+	return nil
+}
+
 // DeleteUserUseCase handles deleting a user (admin only)
 type DeleteUserUseCase struct {
 	userRepo repository.UserRepository
+	// denylist rejects the deleted user's access tokens; nil leaves them valid until they expire
+	denylist *service.TokenDenylistService
+	auditLog *service.AuditLogService
 }
 
 // NewDeleteUserUseCase creates a new delete user use case
-func NewDeleteUserUseCase(userRepo repository.UserRepository) *DeleteUserUseCase {
+func NewDeleteUserUseCase(userRepo repository.UserRepository, denylist *service.TokenDenylistService, auditLog *service.AuditLogService) *DeleteUserUseCase {
 	return &DeleteUserUseCase{
 		userRepo: userRepo,
+		denylist: denylist,
+		auditLog: auditLog,
 	}
 }
 
-// Execute executes the delete user use case
-func (uc *DeleteUserUseCase) Execute(ctx context.Context, targetUserID string) error {
+// Execute moves a user to the trash, from where it can be restored until it is purged.
+// Permanent deletes remove the user at once, including users already in the trash.
+func (uc *DeleteUserUseCase) Execute(ctx context.Context, targetUserID string, permanent bool) error {
+	if permanent {
+		ctx = repository.WithDeleted(ctx)
+	}
+
 	// Check if user exists
-	user, err := uc.userRepo.FindByID(ctx, targetUserID)
-	if err != nil {
+	if _, err := uc.userRepo.FindByID(ctx, targetUserID); err != nil {
 		return fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return fmt.Errorf("user not found")
-	}
 
 	// Delete user
 	if err := uc.userRepo.Delete(ctx, targetUserID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	if uc.denylist != nil {
+		if err := uc.denylist.DenyUser(ctx, targetUserID); err != nil {
+			return fmt.Errorf("failed to deny access tokens: %w", err)
+		}
+	}
+
+	details := map[string]string{"permanent": strconv.FormatBool(permanent)}
+	uc.auditLog.Record(ctx, entity.AuditActionUserDeleted, entity.AuditTargetUser, targetUserID, details)
 	return nil
 }
 
-// PromoteUserUseCase handles promoting a user to admin (admin only)
-type PromoteUserUseCase struct {
+// RestoreUserUseCase handles restoring a user from the trash (admin only)
+type RestoreUserUseCase struct {
 	userRepo repository.UserRepository
+	auditLog *service.AuditLogService
 }
 
-// NewPromoteUserUseCase creates a new promote user use case
-func NewPromoteUserUseCase(userRepo repository.UserRepository) *PromoteUserUseCase {
-	return &PromoteUserUseCase{
+// NewRestoreUserUseCase creates a new restore user use case
+func NewRestoreUserUseCase(userRepo repository.UserRepository, auditLog *service.AuditLogService) *RestoreUserUseCase {
+	return &RestoreUserUseCase{
 		userRepo: userRepo,
+		auditLog: auditLog,
 	}
 }
 
-// Execute executes the promote user use case
-func (uc *PromoteUserUseCase) Execute(ctx context.Context, targetUserID string) (*dto.UserResponse, error) {
+// Execute restores a soft-deleted user. Its access tokens issued before the deletion stay
+// denied, so it signs in again or refreshes its session.
+func (uc *RestoreUserUseCase) Execute(ctx context.Context, targetUserID string) (*dto.UserResponse, error) {
+	if err := uc.userRepo.Restore(ctx, targetUserID); err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+	uc.auditLog.Record(ctx, entity.AuditActionUserRestored, entity.AuditTargetUser, targetUserID, nil)
+
 	user, err := uc.userRepo.FindByID(ctx, targetUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
-
-	if user.IsAdmin() {
-		return nil, fmt.Errorf("user is already an admin")
-	}
-
-	// Promote user
-	user.PromoteToAdmin()
-
-	// Save changes
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to promote user: %w", err)
-	}
 
 	response := dto.ToUserResponse(user)
 	return &response, nil
 }
 
-// DemoteUserUseCase handles demoting an admin to user (admin only)
-type DemoteUserUseCase struct {
-	userRepo repository.UserRepository
+// UnlockUserUseCase handles lifting the lock of a user locked out by failed logins (admin only)
+type UnlockUserUseCase struct {
+	userRepo      repository.UserRepository
+	loginThrottle *service.LoginThrottleService
+	auditLog      *service.AuditLogService
 }
 
-// NewDemoteUserUseCase creates a new demote user use case
-func NewDemoteUserUseCase(userRepo repository.UserRepository) *DemoteUserUseCase {
-	return &DemoteUserUseCase{
-		userRepo: userRepo,
+// NewUnlockUserUseCase creates a new unlock user use case
+func NewUnlockUserUseCase(userRepo repository.UserRepository, loginThrottle *service.LoginThrottleService, auditLog *service.AuditLogService) *UnlockUserUseCase {
+	return &UnlockUserUseCase{
+		userRepo:      userRepo,
+		loginThrottle: loginThrottle,
+		auditLog:      auditLog,
 	}
 }
 
-// Execute executes the demote user use case
-func (uc *DemoteUserUseCase) Execute(ctx context.Context, targetUserID string) (*dto.UserResponse, error) {
+// Execute lifts the user's lock and clears the failed logins counted against the account
+func (uc *UnlockUserUseCase) Execute(ctx context.Context, targetUserID string) error {
 	user, err := uc.userRepo.FindByID(ctx, targetUserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
-	}
-	if user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
-
-	if !user.IsAdmin() {
-		return nil, fmt.Errorf("user is not an admin")
+		return fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Demote user
-	user.DemoteToUser()
-
-	// Save changes
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to demote user: %w", err)
+	if err := uc.loginThrottle.Unlock(ctx, user.Email); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
 	}
 
-	response := dto.ToUserResponse(user)
-	return &response, nil
-}
\ No newline at end of file
+	uc.auditLog.Record(ctx, entity.AuditActionUserUnlocked, entity.AuditTargetUser, user.ID, nil)
+	return nil
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// WebhookUseCase handles managing the current user's webhooks and inspecting their deliveries
+type WebhookUseCase struct {
+	webhookRepo    repository.WebhookRepository
+	webhookService *service.WebhookService
+	maxPerUser     int
+}
+
+// NewWebhookUseCase creates a new webhook use case. maxPerUser of 0 doesn't limit the webhooks
+// of a user.
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository, webhookService *service.WebhookService, maxPerUser int) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo:    webhookRepo,
+		webhookService: webhookService,
+		maxPerUser:     maxPerUser,
+	}
+}
+
+// CreateWebhook registers a webhook for the user. The secret signing its deliveries is only
+// returned here.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, userID string, req dto.CreateWebhookRequest) (*dto.CreateWebhookResponse, error) {
+	if err := uc.validate(req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	if uc.maxPerUser > 0 {
+		count, err := uc.webhookRepo.CountByUserID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count webhooks: %w", err)
+		}
+		if count >= int64(uc.maxPerUser) {
+			return nil, domain.ErrWebhookLimitReached
+		}
+	}
+
+	secret, err := uc.webhookService.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := entity.NewWebhook(userID, req.URL, req.Description, req.EventTypes, secret)
+
+	if err := webhook.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &dto.CreateWebhookResponse{
+		WebhookResponse: dto.ToWebhookResponse(webhook),
+		Secret:          secret,
+	}, nil
+}
+
+// ListWebhooks returns the user's webhooks, newest first
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context, userID string) ([]dto.WebhookResponse, error) {
+	webhooks, err := uc.webhookRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return dto.ToWebhookListResponse(webhooks), nil
+}
+
+// GetWebhook returns one of the user's webhooks
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, userID, id string) (*dto.WebhookResponse, error) {
+	webhook, err := uc.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	return &response, nil
+}
+
+// UpdateWebhook replaces the URL, description, subscriptions and state of one of the user's
+// webhooks
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, userID, id string, req dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	if err := uc.validate(req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	webhook, err := uc.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Update(req.URL, req.Description, req.EventTypes, *req.Active)
+
+	if err := webhook.Validate(); err != nil {
+		return nil, domain.NewValidationError(err)
+	}
+
+	if err := uc.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	return &response, nil
+}
+
+// DeleteWebhook deletes one of the user's webhooks with its delivery log. Deliveries still
+// queued are dropped.
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, userID, id string) error {
+	if _, err := uc.findOwned(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := uc.webhookRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// PingWebhook sends a ping event to one of the user's webhooks right away and returns the
+// recorded attempt, so the user can check the endpoint and its signature verification
+func (uc *WebhookUseCase) PingWebhook(ctx context.Context, userID, id string) (*dto.WebhookDeliveryResponse, error) {
+	webhook, err := uc.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := uc.webhookService.Ping(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookDeliveryResponse(delivery)
+	return &response, nil
+}
+
+// ListDeliveries returns a page of the delivery log of one of the user's webhooks, newest first
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, userID, id string, req dto.PaginationRequest) (*dto.WebhookDeliveriesListResponse, error) {
+	if _, err := uc.findOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	deliveries, err := uc.webhookRepo.ListDeliveries(ctx, id, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	total, err := uc.webhookRepo.CountDeliveries(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	response := dto.ToWebhookDeliveriesListResponse(deliveries, total, req.Limit, req.Offset)
+	return &response, nil
+}
+
+// PurgeDeliveries deletes the delivery log older than the retention, and returns how many
+// attempts were deleted
+func (uc *WebhookUseCase) PurgeDeliveries(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := uc.webhookRepo.DeleteDeliveriesBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	return deleted, nil
+}
+
+// validate checks the URL and event types of a webhook
+func (uc *WebhookUseCase) validate(url string, eventTypes []string) error {
+	if err := uc.webhookService.ValidateURL(url); err != nil {
+		return err
+	}
+	return uc.webhookService.ValidateEventTypes(eventTypes)
+}
+
+// findOwned finds a webhook of the user; other users' webhooks are not found
+func (uc *WebhookUseCase) findOwned(ctx context.Context, userID, id string) (*entity.Webhook, error) {
+	webhook, err := uc.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	if webhook.UserID != userID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
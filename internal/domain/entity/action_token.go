@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActionTokenPurpose is the action an action token confirms
+type ActionTokenPurpose string
+
+// Action token purposes
+const (
+	// ActionTokenPasswordReset lets a user who forgot their password set a new one
+	ActionTokenPasswordReset ActionTokenPurpose = "password_reset"
+	// ActionTokenEmailVerification confirms that a user receives email at their address
+	ActionTokenEmailVerification ActionTokenPurpose = "email_verification"
+	// ActionTokenEmailChange confirms that a user receives email at the address they are
+	// changing their email to
+	ActionTokenEmailChange ActionTokenPurpose = "email_change"
+	// ActionTokenEmailChangeRevert lets the owner of the previous address cancel an email change,
+	// or undo it for a while after it was confirmed
+	ActionTokenEmailChangeRevert ActionTokenPurpose = "email_change_revert"
+)
+
+// ActionToken is a single-use, time-limited token sent to a user by email to confirm an action,
+// such as resetting the password. Only the SHA-256 hash of the token is stored.
+type ActionToken struct {
+	ID        string             `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    string             `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   ActionTokenPurpose `json:"purpose" gorm:"type:varchar(32);not null"`
+	TokenHash string             `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	// Email is the address the token is about, for purposes that need one such as email changes
+	Email     string    `json:"email,omitempty" gorm:"type:varchar(255)"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewActionToken creates an action token of a user for the purpose, valid for ttl. email is the
+// address the token is about, or "" when the purpose needs none.
+func NewActionToken(userID string, purpose ActionTokenPurpose, tokenHash, email string, ttl time.Duration) *ActionToken {
+	return &ActionToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+}
+
+// IsExpired checks if the action token has expired
+func (t *ActionToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityType is the kind of an activity in a user's feed
+type ActivityType string
+
+// Activity types
+const (
+	// ActivityDocumentUploaded is a document the user uploaded
+	ActivityDocumentUploaded ActivityType = "document_uploaded"
+	// ActivityProfileUpdated is a change of the user's name, locale or metadata
+	ActivityProfileUpdated ActivityType = "profile_updated"
+	// ActivityNewDeviceLogin is a login to the user's account from a device or country it hasn't
+	// been used from before
+	ActivityNewDeviceLogin ActivityType = "new_device_login"
+)
+
+// ActivityTypes lists the activity types
+var ActivityTypes = []ActivityType{
+	ActivityDocumentUploaded,
+	ActivityProfileUpdated,
+	ActivityNewDeviceLogin,
+}
+
+// IsValid reports whether the type is a known activity type
+func (t ActivityType) IsValid() bool {
+	return slices.Contains(ActivityTypes, t)
+}
+
+// Activity is something that happened to a user's account that the user sees in its activity
+// feed, such as an upload or a login from a new device
+type Activity struct {
+	ID     string       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID string       `json:"user_id" gorm:"type:uuid;not null;index:idx_activities_user_id_created_at,priority:1"`
+	Type   ActivityType `json:"type" gorm:"type:varchar(50);not null"`
+	// Details are specific to the type, e.g. the ID and title of an uploaded document
+	Details   map[string]string `json:"details" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time         `json:"created_at" gorm:"index:idx_activities_user_id_created_at,priority:2;index"`
+}
+
+// NewActivity creates an activity of a user
+func NewActivity(userID string, activityType ActivityType, details map[string]string) *Activity {
+	return &Activity{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		Type:    activityType,
+		Details: details,
+	}
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey represents a long-lived credential used by machine clients.
+// Only the SHA-256 hash of the key is stored; the plaintext is shown once on creation.
+type APIKey struct {
+	ID                string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name              string     `json:"name" gorm:"not null"`
+	Prefix            string     `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash           string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	RateLimit         int        `json:"rate_limit" gorm:"not null"`
+	RateWindowSeconds int        `json:"rate_window_seconds" gorm:"not null"`
+	ExpiresAt         *time.Time `json:"expires_at" gorm:"null"`
+	LastUsedAt        *time.Time `json:"last_used_at" gorm:"null"`
+	RevokedAt         *time.Time `json:"revoked_at" gorm:"null"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// NewAPIKey creates a new API key record
+func NewAPIKey(userID, name, prefix, keyHash string, rateLimit int, rateWindow time.Duration, expiresAt *time.Time) *APIKey {
+	return &APIKey{
+		ID:                uuid.New().String(),
+		UserID:            userID,
+		Name:              strings.TrimSpace(name),
+		Prefix:            prefix,
+		KeyHash:           keyHash,
+		RateLimit:         rateLimit,
+		RateWindowSeconds: int(rateWindow.Seconds()),
+		ExpiresAt:         expiresAt,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// Validate validates the API key entity
+func (k *APIKey) Validate() error {
+	if k.UserID == "" {
+		return errors.New("user ID is required")
+	}
+
+	if k.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if len(k.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	if k.KeyHash == "" {
+		return errors.New("key hash is required")
+	}
+
+	if k.RateLimit <= 0 {
+		return errors.New("rate limit must be positive")
+	}
+
+	if k.RateWindowSeconds <= 0 {
+		return errors.New("rate window must be positive")
+	}
+
+	return nil
+}
+
+// RateWindow returns the rate limit window as a duration
+func (k *APIKey) RateWindow() time.Duration {
+	return time.Duration(k.RateWindowSeconds) * time.Second
+}
+
+// IsExpired checks if the API key has expired
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// IsRevoked checks if the API key has been revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsActive checks if the API key can be used to authenticate
+func (k *APIKey) IsActive() bool {
+	return !k.IsExpired() && !k.IsRevoked()
+}
+
+// Revoke marks the API key as revoked
+func (k *APIKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+	k.UpdatedAt = now
+}
+
+// UpdateRateLimit changes the per-key rate limit
+func (k *APIKey) UpdateRateLimit(rateLimit int, rateWindow time.Duration) {
+	k.RateLimit = rateLimit
+	k.RateWindowSeconds = int(rateWindow.Seconds())
+	k.UpdatedAt = time.Now()
+}
@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is what an audit log entry records someone doing
+type AuditAction string
+
+// Audit actions
+const (
+	// AuditActionLogin is a user logging in, with a password or a provider
+	AuditActionLogin AuditAction = "login"
+	// AuditActionLogout is a user logging out of one session
+	AuditActionLogout AuditAction = "logout"
+	// AuditActionLogoutAll is a user logging out of every session
+	AuditActionLogoutAll AuditAction = "logout_all"
+	// AuditActionPasswordChanged is a user changing its password
+	AuditActionPasswordChanged AuditAction = "password_changed"
+	// AuditActionPasswordReset is a password set with a reset link or by an admin
+	AuditActionPasswordReset AuditAction = "password_reset"
+	// AuditActionRoleAssigned is a user given a role
+	AuditActionRoleAssigned AuditAction = "role_assigned"
+	// AuditActionRoleCreated is a custom role created
+	AuditActionRoleCreated AuditAction = "role_created"
+	// AuditActionRoleUpdated is the description or permissions of a custom role changed
+	AuditActionRoleUpdated AuditAction = "role_updated"
+	// AuditActionRoleDeleted is a custom role deleted
+	AuditActionRoleDeleted AuditAction = "role_deleted"
+	// AuditActionUserCreated is a user created by an admin
+	AuditActionUserCreated AuditAction = "user_created"
+	// AuditActionUserDeleted is a user deleted by an admin, into the trash or permanently
+	AuditActionUserDeleted AuditAction = "user_deleted"
+	// AuditActionUserRestored is a user restored from the trash
+	AuditActionUserRestored AuditAction = "user_restored"
+	// AuditActionUserUnlocked is a user locked by failed logins unlocked by an admin
+	AuditActionUserUnlocked AuditAction = "user_unlocked"
+	// AuditActionRateLimitTierChanged is the rate limit tier of a user changed by an admin
+	AuditActionRateLimitTierChanged AuditAction = "rate_limit_tier_changed"
+	// AuditActionAvatarApproved is an uploaded avatar awaiting moderation approved
+	AuditActionAvatarApproved AuditAction = "avatar_approved"
+	// AuditActionAvatarRejected is an uploaded avatar awaiting moderation rejected
+	AuditActionAvatarRejected AuditAction = "avatar_rejected"
+	// AuditActionAccountDeleted is a user deleting its own account
+	AuditActionAccountDeleted AuditAction = "account_deleted"
+	// AuditActionDataExportRequested is a user requesting an export of its data
+	AuditActionDataExportRequested AuditAction = "data_export_requested"
+	// AuditActionDocumentDeleted is a document moved to the trash
+	AuditActionDocumentDeleted AuditAction = "document_deleted"
+)
+
+// AuditActions lists the audit actions
+var AuditActions = []AuditAction{
+	AuditActionLogin,
+	AuditActionLogout,
+	AuditActionLogoutAll,
+	AuditActionPasswordChanged,
+	AuditActionPasswordReset,
+	AuditActionRoleAssigned,
+	AuditActionRoleCreated,
+	AuditActionRoleUpdated,
+	AuditActionRoleDeleted,
+	AuditActionUserCreated,
+	AuditActionUserDeleted,
+	AuditActionUserRestored,
+	AuditActionUserUnlocked,
+	AuditActionRateLimitTierChanged,
+	AuditActionAvatarApproved,
+	AuditActionAvatarRejected,
+	AuditActionAccountDeleted,
+	AuditActionDataExportRequested,
+	AuditActionDocumentDeleted,
+}
+
+// IsValid reports whether the action is a known audit action
+func (a AuditAction) IsValid() bool {
+	return slices.Contains(AuditActions, a)
+}
+
+// Types of the records audit log entries are about
+const (
+	AuditTargetUser     = "user"
+	AuditTargetRole     = "role"
+	AuditTargetDocument = "document"
+)
+
+// AuditLog records an action someone took, for admins to review who did what and when
+type AuditLog struct {
+	ID string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	// ActorID is the user who acted; nil for actions of the API itself, such as the admin CLI
+	ActorID *string     `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	Action  AuditAction `json:"action" gorm:"type:varchar(50);not null;index"`
+	// TargetType and TargetID are the record acted on, e.g. a user or a role name
+	TargetType string `json:"target_type" gorm:"type:varchar(50)"`
+	TargetID   string `json:"target_id" gorm:"type:varchar(255);index"`
+	IP         string `json:"ip" gorm:"type:varchar(45)"`
+	UserAgent  string `json:"user_agent" gorm:"type:varchar(512)"`
+	// Details are specific to the action, e.g. the role a user was given
+	Details   map[string]string `json:"details" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time         `json:"created_at" gorm:"index"`
+}
+
+// NewAuditLog creates an audit log entry of the actor acting on a target. An empty actorID is
+// the API itself.
+func NewAuditLog(actorID string, action AuditAction, targetType, targetID, ip, userAgent string, details map[string]string) *AuditLog {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	entry := &AuditLog{
+		ID:         uuid.New().String(),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		Details:    details,
+	}
+	if actorID != "" {
+		entry.ActorID = &actorID
+	}
+	return entry
+}
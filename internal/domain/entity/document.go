@@ -5,23 +5,42 @@ import (
 
 	"gin-boilerplate/internal/domain"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScanStatus is the outcome of scanning an uploaded file for malware. Infected files are rejected,
+// so they are never stored.
+type ScanStatus string
+
+const (
+	// ScanStatusClean files were scanned and no malware was found
+	ScanStatusClean ScanStatus = "clean"
+	// ScanStatusUnscanned files weren't scanned: scanning was off, or the scanner was unavailable
+	// while scanning was best-effort
+	ScanStatusUnscanned ScanStatus = "unscanned"
 )
 
 type Document struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	FileURL     string    `json:"file_url"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	ContentType string    `json:"content_type"`
-	UserID      string    `json:"user_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string         `json:"id" gorm:"index:idx_documents_user_id_created_at_id,priority:3"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	FileURL     string         `json:"file_url"`
+	FileName    string         `json:"file_name"`
+	FileSize    int64          `json:"file_size"`
+	ContentType string         `json:"content_type"`
+	UserID      string         `json:"user_id" gorm:"index:idx_documents_user_id_created_at_id,priority:1"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"index:idx_documents_user_id_created_at_id,priority:2"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Version     int64          `json:"version" gorm:"not null;default:1"` // incremented by every update, for optimistic locking
+
+	// ExtractedText is the text of the file, for full-text search, when it can be extracted
+	ExtractedText string `json:"-" gorm:"type:text"`
+	// ScanStatus is the outcome of scanning the file for malware when it was uploaded
+	ScanStatus ScanStatus `json:"scan_status" gorm:"type:varchar(16);not null;default:'unscanned'"`
 }
 
 func NewDocument(title, description, fileURL, fileName string, fileSize int64, contentType, userID string) *Document {
-	now := time.Now()
 	return &Document{
 		ID:          uuid.New().String(),
 		Title:       title,
@@ -31,8 +50,7 @@ func NewDocument(title, description, fileURL, fileName string, fileSize int64, c
 		FileSize:    fileSize,
 		ContentType: contentType,
 		UserID:      userID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Version:     1,
 	}
 }
 
@@ -52,5 +70,10 @@ func (d *Document) Validate() error {
 func (d *Document) Update(title, description string) {
 	d.Title = title
 	d.Description = description
-	d.UpdatedAt = time.Now()
-}
\ No newline at end of file
+}
+
+// BeforeSave stores the timestamps GORM does not set itself in UTC
+func (d *Document) BeforeSave(tx *gorm.DB) error {
+	d.CreatedAt = d.CreatedAt.UTC()
+	return nil
+}
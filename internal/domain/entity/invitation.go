@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets the owner of an email address register while registration is invite-only.
+// Only the SHA-256 hash of its token is stored.
+type Invitation struct {
+	ID        string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email     string `json:"email" gorm:"type:varchar(255);not null;index"`
+	Role      Role   `json:"role" gorm:"type:varchar(10);not null;default:'USER'"`
+	TokenHash string `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	// InvitedBy is the admin who created the invitation
+	InvitedBy string `json:"invited_by" gorm:"type:uuid"`
+	// AcceptedBy is the user who registered with the invitation
+	AcceptedBy *string    `json:"accepted_by,omitempty" gorm:"type:uuid"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewInvitation creates an invitation of the email with the role, valid for ttl
+func NewInvitation(email string, role Role, tokenHash, invitedBy string, ttl time.Duration) *Invitation {
+	return &Invitation{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Role:      role,
+		TokenHash: tokenHash,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+}
+
+// IsExpired checks if the invitation has expired
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// IsAccepted checks if a user registered with the invitation
+func (i *Invitation) IsAccepted() bool {
+	return i.AcceptedAt != nil
+}
+
+// IsPending checks if the invitation can still be used to register
+func (i *Invitation) IsPending() bool {
+	return !i.IsAccepted() && !i.IsExpired()
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	// JobStatusPending jobs wait for a worker, until RunAt when they are retried
+	JobStatusPending JobStatus = "pending"
+	// JobStatusRunning jobs are held by a worker until LockedUntil
+	JobStatusRunning JobStatus = "running"
+	// JobStatusSucceeded jobs are done, and kept for inspection until they are purged
+	JobStatusSucceeded JobStatus = "succeeded"
+	// JobStatusDead jobs failed their last attempt and wait in the dead-letter queue for a retry
+	JobStatusDead JobStatus = "dead"
+)
+
+// IsValid reports whether the status is known
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusDead:
+		return true
+	}
+	return false
+}
+
+// Job is a unit of background work stored in the persistent job queue. Its payload is the JSON
+// the handler of its type reads.
+type Job struct {
+	ID          string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Type        string     `json:"type" gorm:"type:varchar(64);not null;index"`
+	Payload     string     `json:"payload" gorm:"type:jsonb;not null"`
+	Status      JobStatus  `json:"status" gorm:"type:varchar(16);not null;index:idx_jobs_status_run_at,priority:1"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_jobs_status_run_at,priority:2"`
+	LockedUntil *time.Time `json:"locked_until" gorm:"null"`
+	LastError   string     `json:"last_error" gorm:"type:text"`
+	FinishedAt  *time.Time `json:"finished_at" gorm:"null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewJob creates a pending job that runs as soon as a worker is free
+func NewJob(jobType, payload string, maxAttempts int) *Job {
+	return &Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		Payload:     payload,
+		Status:      JobStatusPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now().UTC(),
+	}
+}
+
+// Start marks the job running for one more attempt, held by its worker until lease runs out
+func (j *Job) Start(lease time.Duration) {
+	lockedUntil := time.Now().UTC().Add(lease)
+	j.Status = JobStatusRunning
+	j.Attempts++
+	j.LockedUntil = &lockedUntil
+}
+
+// Succeed marks the job done
+func (j *Job) Succeed() {
+	now := time.Now().UTC()
+	j.Status = JobStatusSucceeded
+	j.LockedUntil = nil
+	j.LastError = ""
+	j.FinishedAt = &now
+}
+
+// Fail records a failed attempt. The job is retried after backoff, or dead-lettered when it has
+// no attempts left.
+func (j *Job) Fail(err error, backoff time.Duration) {
+	now := time.Now().UTC()
+	j.LockedUntil = nil
+	j.LastError = err.Error()
+	if j.Attempts >= j.MaxAttempts {
+		j.Status = JobStatusDead
+		j.FinishedAt = &now
+		return
+	}
+	j.Status = JobStatusPending
+	j.RunAt = now.Add(backoff)
+}
+
+// Retry moves a dead job back to the queue with a fresh set of attempts
+func (j *Job) Retry() {
+	j.Status = JobStatusPending
+	j.Attempts = 0
+	j.RunAt = time.Now().UTC()
+	j.FinishedAt = nil
+}
+
+// IsDead reports whether the job is in the dead-letter queue
+func (j *Job) IsDead() bool {
+	return j.Status == JobStatusDead
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PreviousPassword is a password a user replaced, kept so it can't be set again soon. Only its
+// hash is stored.
+type PreviousPassword struct {
+	ID           string    `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       string    `json:"-" gorm:"type:uuid;not null;index:idx_previous_passwords_user_created,priority:1"`
+	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time `json:"-" gorm:"index:idx_previous_passwords_user_created,priority:2"`
+}
+
+// NewPreviousPassword keeps the hash of a password the user is replacing
+func NewPreviousPassword(userID, passwordHash string) *PreviousPassword {
+	return &PreviousPassword{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a single-use code completing a two-factor login in place of the second
+// factor, for users who lost access to it. Only its hash is stored.
+type RecoveryCode struct {
+	ID        string     `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    string     `json:"-" gorm:"type:uuid;not null;index:idx_recovery_codes_user_hash,priority:1"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null;index:idx_recovery_codes_user_hash,priority:2"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+// NewRecoveryCode creates an unused recovery code of the user from the hash of the code
+func NewRecoveryCode(userID, codeHash string) *RecoveryCode {
+	return &RecoveryCode{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		CodeHash: codeHash,
+	}
+}
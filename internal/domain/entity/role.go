@@ -0,0 +1,174 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Permission is an action a role allows, named resource:action
+type Permission string
+
+// Permissions of the users' own resources
+const (
+	PermissionDocumentsRead   Permission = "documents:read"
+	PermissionDocumentsWrite  Permission = "documents:write"
+	PermissionDocumentsDelete Permission = "documents:delete"
+)
+
+// Permissions of the admin endpoints
+const (
+	PermissionUsersRead          Permission = "users:read"
+	PermissionUsersCreate        Permission = "users:create"
+	PermissionUsersDelete        Permission = "users:delete"
+	PermissionUsersUnlock        Permission = "users:unlock"
+	PermissionUsersAssignRole    Permission = "users:assign_role"
+	PermissionRolesManage        Permission = "roles:manage"
+	PermissionUserMetadataManage Permission = "user_metadata:manage"
+	PermissionAPIKeysManage      Permission = "api_keys:manage"
+	PermissionQuotasManage       Permission = "quotas:manage"
+	PermissionRateLimitsManage   Permission = "rate_limits:manage"
+	PermissionLogLevelManage     Permission = "log_level:manage"
+	PermissionConfigRead         Permission = "config:read"
+	PermissionJobsManage         Permission = "jobs:manage"
+	PermissionInvitationsManage  Permission = "invitations:manage"
+	PermissionSecurityEventsRead Permission = "security_events:read"
+	PermissionAuditLogRead       Permission = "audit_log:read"
+	PermissionDashboardRead      Permission = "dashboard:read"
+	// PermissionDocumentsModerate lists and deletes the documents of every user
+	PermissionDocumentsModerate Permission = "documents:moderate"
+	// PermissionAvatarsModerate approves or rejects the uploaded avatars awaiting moderation
+	PermissionAvatarsModerate Permission = "avatars:moderate"
+)
+
+// Permissions lists every permission, which the ADMIN role has
+var Permissions = []Permission{
+	PermissionDocumentsRead,
+	PermissionDocumentsWrite,
+	PermissionDocumentsDelete,
+	PermissionUsersRead,
+	PermissionUsersCreate,
+	PermissionUsersDelete,
+	PermissionUsersUnlock,
+	PermissionUsersAssignRole,
+	PermissionRolesManage,
+	PermissionUserMetadataManage,
+	PermissionAPIKeysManage,
+	PermissionQuotasManage,
+	PermissionRateLimitsManage,
+	PermissionLogLevelManage,
+	PermissionConfigRead,
+	PermissionJobsManage,
+	PermissionInvitationsManage,
+	PermissionSecurityEventsRead,
+	PermissionAuditLogRead,
+	PermissionDashboardRead,
+	PermissionDocumentsModerate,
+	PermissionAvatarsModerate,
+}
+
+// userPermissions are the permissions of the USER role
+var userPermissions = []Permission{
+	PermissionDocumentsRead,
+	PermissionDocumentsWrite,
+	PermissionDocumentsDelete,
+}
+
+// moderatorPermissions are the permissions of the MODERATOR role: those of USER, viewing users,
+// and managing their documents and avatars
+var moderatorPermissions = []Permission{
+	PermissionDocumentsRead,
+	PermissionDocumentsWrite,
+	PermissionDocumentsDelete,
+	PermissionUsersRead,
+	PermissionDocumentsModerate,
+	PermissionAvatarsModerate,
+}
+
+// BuiltinRoles lists the built-in roles, from the least to the most allowed
+var BuiltinRoles = []Role{RoleUser, RoleModerator, RoleAdmin}
+
+// IsValid reports whether the permission exists
+func (p Permission) IsValid() bool {
+	return slices.Contains(Permissions, p)
+}
+
+// BuiltinPermissions returns the permissions of the USER, MODERATOR and ADMIN roles, which can't
+// be changed, and whether the role is one of them
+func BuiltinPermissions(role Role) ([]Permission, bool) {
+	switch role {
+	case RoleUser:
+		return userPermissions, true
+	case RoleModerator:
+		return moderatorPermissions, true
+	case RoleAdmin:
+		return Permissions, true
+	default:
+		return nil, false
+	}
+}
+
+// roleNamePattern matches the names of custom roles, upper-cased like the built-in ones
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,49}$`)
+
+// CustomRole is a role defined by admins, allowing a set of permissions. Users get it by name,
+// like the built-in USER, MODERATOR and ADMIN roles.
+type CustomRole struct {
+	Name        Role             `json:"name" gorm:"type:varchar(50);primaryKey"`
+	Description string           `json:"description" gorm:"type:varchar(255)"`
+	Permissions []RolePermission `json:"permissions" gorm:"foreignKey:Role;references:Name;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission grants a permission to a custom role
+type RolePermission struct {
+	Role       Role       `json:"role" gorm:"type:varchar(50);primaryKey"`
+	Permission Permission `json:"permission" gorm:"type:varchar(50);primaryKey"`
+}
+
+// NewCustomRole creates a custom role allowing the permissions
+func NewCustomRole(name Role, description string, permissions []Permission) *CustomRole {
+	role := &CustomRole{
+		Name:        name,
+		Description: description,
+	}
+	role.SetPermissions(permissions)
+	return role
+}
+
+// SetPermissions replaces the permissions of the role, ignoring duplicates
+func (r *CustomRole) SetPermissions(permissions []Permission) {
+	r.Permissions = make([]RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !slices.Contains(r.PermissionList(), permission) {
+			r.Permissions = append(r.Permissions, RolePermission{Role: r.Name, Permission: permission})
+		}
+	}
+}
+
+// PermissionList returns the permissions of the role
+func (r *CustomRole) PermissionList() []Permission {
+	permissions := make([]Permission, len(r.Permissions))
+	for i, granted := range r.Permissions {
+		permissions[i] = granted.Permission
+	}
+	return permissions
+}
+
+// Validate validates the role's name and permissions
+func (r *CustomRole) Validate() error {
+	if !roleNamePattern.MatchString(string(r.Name)) {
+		return errors.New("name must be 2 to 50 upper-case letters, digits or underscores, starting with a letter")
+	}
+	if len(r.Description) > 255 {
+		return errors.New("description must be at most 255 characters")
+	}
+	for _, granted := range r.Permissions {
+		if !granted.Permission.IsValid() {
+			return errors.New("unknown permission " + string(granted.Permission))
+		}
+	}
+	return nil
+}
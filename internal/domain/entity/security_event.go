@@ -0,0 +1,167 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEventType is the kind of a security event
+type SecurityEventType string
+
+// Security event types
+const (
+	// SecurityEventNewDeviceLogin is a login from a device the user hasn't logged in from before
+	SecurityEventNewDeviceLogin SecurityEventType = "new_device_login"
+	// SecurityEventNewCountryLogin is a login from a country the user hasn't logged in from
+	// before, by the GeoIP database
+	SecurityEventNewCountryLogin SecurityEventType = "new_country_login"
+	// SecurityEventPasswordChanged is a change of the user's password
+	SecurityEventPasswordChanged SecurityEventType = "password_changed"
+	// SecurityEventEmailChanged is a change of the user's email address, or its undoing
+	SecurityEventEmailChanged SecurityEventType = "email_changed"
+	// SecurityEventProviderLinked is a sign-in method added to the user: a provider account or
+	// a password
+	SecurityEventProviderLinked SecurityEventType = "provider_linked"
+	// SecurityEventProviderUnlinked is a sign-in method removed from the user
+	SecurityEventProviderUnlinked SecurityEventType = "provider_unlinked"
+	// SecurityEventTokenReuse is a refresh token used again after it was rotated or logged out,
+	// which suggests it was stolen
+	SecurityEventTokenReuse SecurityEventType = "token_reuse_detected"
+	// SecurityEventAccountLocked is an account throttled after too many failed logins
+	SecurityEventAccountLocked SecurityEventType = "account_locked"
+	// SecurityEventAdminRoleGranted is a user given the admin role
+	SecurityEventAdminRoleGranted SecurityEventType = "admin_role_granted"
+	// SecurityEventPhoneChanged is a verified phone number replacing the user's previous one
+	SecurityEventPhoneChanged SecurityEventType = "phone_changed"
+	// SecurityEventTwoFactorEnabled is a second factor turned on for the user's logins
+	SecurityEventTwoFactorEnabled SecurityEventType = "two_factor_enabled"
+	// SecurityEventTwoFactorDisabled is the second factor of the user's logins turned off
+	SecurityEventTwoFactorDisabled SecurityEventType = "two_factor_disabled"
+	// SecurityEventRecoveryCodeUsed is a login completed with a recovery code instead of the
+	// second factor
+	SecurityEventRecoveryCodeUsed SecurityEventType = "recovery_code_used"
+	// SecurityEventRecoveryCodesRegenerated is new recovery codes replacing the user's previous
+	// ones
+	SecurityEventRecoveryCodesRegenerated SecurityEventType = "recovery_codes_regenerated"
+)
+
+// SecurityEventTypes lists the security event types
+var SecurityEventTypes = []SecurityEventType{
+	SecurityEventNewDeviceLogin,
+	SecurityEventNewCountryLogin,
+	SecurityEventPasswordChanged,
+	SecurityEventEmailChanged,
+	SecurityEventProviderLinked,
+	SecurityEventProviderUnlinked,
+	SecurityEventTokenReuse,
+	SecurityEventAccountLocked,
+	SecurityEventAdminRoleGranted,
+	SecurityEventPhoneChanged,
+	SecurityEventTwoFactorEnabled,
+	SecurityEventTwoFactorDisabled,
+	SecurityEventRecoveryCodeUsed,
+	SecurityEventRecoveryCodesRegenerated,
+}
+
+// SuspiciousLoginEvents are the security event types of logins the user should review
+var SuspiciousLoginEvents = []SecurityEventType{
+	SecurityEventNewDeviceLogin,
+	SecurityEventNewCountryLogin,
+}
+
+// IsValidSecurityEventType reports whether eventType is a known security event type
+func IsValidSecurityEventType(eventType SecurityEventType) bool {
+	for _, known := range SecurityEventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SecurityEvent records something that happened to an account that its owner or an admin should
+// know about
+type SecurityEvent struct {
+	ID        string            `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    string            `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type      SecurityEventType `json:"type" gorm:"type:varchar(50);not null;index"`
+	IP        string            `json:"ip" gorm:"type:varchar(45)"`
+	UserAgent string            `json:"user_agent" gorm:"type:varchar(512)"`
+	// Details are specific to the type, e.g. the admin who granted a role
+	Details   map[string]string `json:"details" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time         `json:"created_at" gorm:"index"`
+}
+
+// NewSecurityEvent creates a security event of a user
+func NewSecurityEvent(userID string, eventType SecurityEventType, ip, userAgent string, details map[string]string) *SecurityEvent {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return &SecurityEvent{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Type:      eventType,
+		IP:        ip,
+		UserAgent: userAgent,
+		Details:   details,
+	}
+}
+
+// KnownDevice is a device a user has logged in from, identified by its user agent, so logins
+// from new devices stand out
+type KnownDevice struct {
+	ID          string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      string    `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_known_devices_user_fingerprint,priority:1"`
+	Fingerprint string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex:idx_known_devices_user_fingerprint,priority:2"`
+	UserAgent   string    `json:"user_agent" gorm:"type:varchar(512)"`
+	LastIP      string    `json:"last_ip" gorm:"type:varchar(45)"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewKnownDevice creates a device of a user seen now
+func NewKnownDevice(userID, userAgent, ip string) *KnownDevice {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return &KnownDevice{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Fingerprint: DeviceFingerprint(userAgent),
+		UserAgent:   userAgent,
+		LastIP:      ip,
+		LastSeenAt:  time.Now().UTC(),
+	}
+}
+
+// DeviceFingerprint identifies a device by its user agent. The IP isn't part of it, as it
+// changes whenever a phone switches networks.
+func DeviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// KnownCountry is a country a user has logged in from, so logins from new countries stand out
+type KnownCountry struct {
+	ID      string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID  string `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_known_countries_user_country,priority:1"`
+	Country string `json:"country" gorm:"type:varchar(2);not null;uniqueIndex:idx_known_countries_user_country,priority:2"`
+	// LastIP is that of the latest login from the country
+	LastIP     string    `json:"last_ip" gorm:"type:varchar(45)"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewKnownCountry creates a country of a user seen now
+func NewKnownCountry(userID, country, ip string) *KnownCountry {
+	return &KnownCountry{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Country:    country,
+		LastIP:     ip,
+		LastSeenAt: time.Now().UTC(),
+	}
+}
@@ -5,29 +5,47 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Token struct {
-	ID           string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID       string    `json:"user_id" gorm:"type:uuid;not null;index"`
-	RefreshToken string    `json:"refresh_token" gorm:"type:text;not null;uniqueIndex"`
-	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID     string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID string `json:"user_id" gorm:"type:uuid;not null;index;index:idx_tokens_user_id_expires_at,priority:1"`
+	// SessionID identifies the sign-in of a device. It is kept when the token is rotated, so
+	// the session keeps its ID while its refresh token changes.
+	SessionID    string `json:"session_id" gorm:"type:uuid;not null;default:gen_random_uuid();index"`
+	RefreshToken string `json:"refresh_token" gorm:"type:text;not null;uniqueIndex"`
+	// RememberMe keeps the session for the longer remember-me lifetime, and is kept on rotation
+	RememberMe bool `json:"remember_me" gorm:"not null;default:false"`
+	// UserAgent and IP are those of the device's latest sign-in or refresh
+	UserAgent string         `json:"user_agent" gorm:"type:varchar(512)"`
+	IP        string         `json:"ip" gorm:"type:varchar(45)"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"not null;index;index:idx_tokens_user_id_expires_at,priority:2"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-// NewToken creates a new refresh token
+// NewToken creates a new refresh token, starting a new session
 func NewToken(userID, refreshToken string, expiresAt time.Time) *Token {
 	return &Token{
 		ID:           uuid.New().String(),
 		UserID:       userID,
+		SessionID:    uuid.New().String(),
 		RefreshToken: refreshToken,
 		ExpiresAt:    expiresAt,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
 	}
 }
 
+// SetDevice records the device the token was issued to
+func (t *Token) SetDevice(ip, userAgent string) {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	t.IP = ip
+	t.UserAgent = userAgent
+}
+
 // Validate validates the token entity
 func (t *Token) Validate() error {
 	if t.UserID == "" {
@@ -63,11 +81,16 @@ func (t *Token) IsValid() bool {
 func (t *Token) UpdateRefreshToken(newRefreshToken string, newExpiresAt time.Time) {
 	t.RefreshToken = newRefreshToken
 	t.ExpiresAt = newExpiresAt
-	t.UpdatedAt = time.Now()
 }
 
 // Revoke marks the token as revoked by setting expiration to past
 func (t *Token) Revoke() {
 	t.ExpiresAt = time.Now().Add(-1 * time.Hour)
-	t.UpdatedAt = time.Now()
-}
\ No newline at end of file
+}
+
+// BeforeSave stores the timestamps GORM does not set itself in UTC
+func (t *Token) BeforeSave(tx *gorm.DB) error {
+	t.CreatedAt = t.CreatedAt.UTC()
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	return nil
+}
@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuotaMetric identifies a long-window usage counter
+type QuotaMetric string
+
+const (
+	QuotaMetricRequestsDaily        QuotaMetric = "requests_daily"
+	QuotaMetricUploadsMonthly       QuotaMetric = "uploads_monthly"
+	QuotaMetricDownloadBytesMonthly QuotaMetric = "download_bytes_monthly"
+)
+
+// QuotaMetrics lists all supported quota metrics
+var QuotaMetrics = []QuotaMetric{
+	QuotaMetricRequestsDaily,
+	QuotaMetricUploadsMonthly,
+	QuotaMetricDownloadBytesMonthly,
+}
+
+// IsValid checks if the metric is a supported quota metric
+func (m QuotaMetric) IsValid() bool {
+	for _, metric := range QuotaMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDaily checks if the metric resets every day rather than every month
+func (m QuotaMetric) IsDaily() bool {
+	return m == QuotaMetricRequestsDaily
+}
+
+// Period returns the period identifier the given time falls into (UTC)
+func (m QuotaMetric) Period(t time.Time) string {
+	if m.IsDaily() {
+		return t.UTC().Format("2006-01-02")
+	}
+	return t.UTC().Format("2006-01")
+}
+
+// PeriodEnd returns the time the period containing t ends (UTC)
+func (m QuotaMetric) PeriodEnd(t time.Time) time.Time {
+	t = t.UTC()
+	if m.IsDaily() {
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageRollup is the persisted total of a quota metric for one user and period
+type UsageRollup struct {
+	ID        string      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    string      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_usage_rollup_user_metric_period"`
+	Metric    QuotaMetric `json:"metric" gorm:"type:varchar(32);not null;uniqueIndex:idx_usage_rollup_user_metric_period"`
+	Period    string      `json:"period" gorm:"type:varchar(10);not null;uniqueIndex:idx_usage_rollup_user_metric_period"`
+	Value     int64       `json:"value" gorm:"not null;default:0"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// NewUsageRollup creates a new usage rollup
+func NewUsageRollup(userID string, metric QuotaMetric, period string, value int64) *UsageRollup {
+	return &UsageRollup{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Metric:    metric,
+		Period:    period,
+		Value:     value,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// QuotaOverride replaces the configured default limit of a metric for one user
+type QuotaOverride struct {
+	ID        string      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    string      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_quota_override_user_metric"`
+	Metric    QuotaMetric `json:"metric" gorm:"type:varchar(32);not null;uniqueIndex:idx_quota_override_user_metric"`
+	Limit     int64       `json:"limit" gorm:"not null"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// NewQuotaOverride creates a new quota override
+func NewQuotaOverride(userID string, metric QuotaMetric, limit int64) *QuotaOverride {
+	return &QuotaOverride{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Metric:    metric,
+		Limit:     limit,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate validates the quota override entity
+func (o *QuotaOverride) Validate() error {
+	if o.UserID == "" {
+		return errors.New("user ID is required")
+	}
+
+	if !o.Metric.IsValid() {
+		return errors.New("invalid quota metric")
+	}
+
+	if o.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+
+	return nil
+}
+
+// UpdateLimit changes the overridden limit
+func (o *QuotaOverride) UpdateLimit(limit int64) {
+	o.Limit = limit
+	o.UpdatedAt = time.Now()
+}
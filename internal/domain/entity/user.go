@@ -2,17 +2,21 @@ package entity
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Role string
 
 const (
-	RoleUser  Role = "USER"
-	RoleAdmin Role = "ADMIN"
+	RoleUser Role = "USER"
+	// RoleModerator views users and manages their documents, without changing roles
+	RoleModerator Role = "MODERATOR"
+	RoleAdmin     Role = "ADMIN"
 )
 
 type Provider string
@@ -22,18 +26,78 @@ const (
 	ProviderGoogle Provider = "GOOGLE"
 )
 
+// OAuthProvider returns the provider of the users of the OAuth provider with the name, e.g.
+// GOOGLE for "google"
+func OAuthProvider(name string) Provider {
+	return Provider(strings.ToUpper(name))
+}
+
+// Name returns the name of the provider in URLs, e.g. "google" for GOOGLE
+func (p Provider) Name() string {
+	return strings.ToLower(string(p))
+}
+
+// TwoFactor is the second factor a user signs in with after the password
+type TwoFactor string
+
+const (
+	// TwoFactorNone signs in with the password only
+	TwoFactorNone TwoFactor = ""
+	// TwoFactorSMS texts a one-time code to the user's verified phone number
+	TwoFactorSMS TwoFactor = "sms"
+)
+
+// RateLimitTier decides the request budget of a user's rate limit
+type RateLimitTier string
+
+const (
+	// RateLimitTierFree has the budget of anonymous traffic, and is the tier of new users
+	RateLimitTierFree RateLimitTier = "free"
+	// RateLimitTierPro is for paying users
+	RateLimitTierPro RateLimitTier = "pro"
+	// RateLimitTierInternal is for the operators' own users and services
+	RateLimitTierInternal RateLimitTier = "internal"
+)
+
+// RateLimitTiers lists the rate limit tiers
+var RateLimitTiers = []RateLimitTier{RateLimitTierFree, RateLimitTierPro, RateLimitTierInternal}
+
+// IsValid checks if the tier is one of RateLimitTiers
+func (t RateLimitTier) IsValid() bool {
+	return slices.Contains(RateLimitTiers, t)
+}
+
+// anonymizedEmailDomain is the domain of the emails anonymized accounts are given
+const anonymizedEmailDomain = "@anonymized.invalid"
+
 type User struct {
-	ID           string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
-	Password     *string   `json:"-" gorm:"null"` // nullable for OAuth users
-	Name         string    `json:"name" gorm:"not null"`
-	Role         Role      `json:"role" gorm:"type:varchar(10);default:'USER'"`
-	Provider     Provider  `json:"provider" gorm:"type:varchar(10);default:'LOCAL'"`
-	ProviderID   *string   `json:"-" gorm:"null"` // nullable for local users
-	Avatar       *string   `json:"avatar" gorm:"null"`
-	EmailVerified bool     `json:"email_verified" gorm:"default:false"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            string         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid();index:idx_users_created_at_id,priority:2"`
+	Email         string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password      *string        `json:"-" gorm:"null"` // nullable for OAuth users
+	Name          string         `json:"name" gorm:"not null"`
+	Role          Role           `json:"role" gorm:"type:varchar(50);default:'USER'"`
+	Provider      Provider       `json:"provider" gorm:"type:varchar(10);default:'LOCAL'"` // how the account was created; UserProvider links the providers it signs in with
+	Avatar        *string        `json:"avatar" gorm:"null"`
+	EmailVerified bool           `json:"email_verified" gorm:"default:false"`
+	Locale        string         `json:"locale" gorm:"type:varchar(35)"` // preferred locale for API messages, empty means Accept-Language
+	Phone         *string        `json:"phone" gorm:"type:varchar(16)"`  // E.164 phone number, set once verified
+	PhoneVerified bool           `json:"phone_verified" gorm:"not null;default:false"`
+	TwoFactor     TwoFactor      `json:"two_factor" gorm:"type:varchar(10);not null;default:''"`
+	RateLimitTier RateLimitTier  `json:"rate_limit_tier" gorm:"type:varchar(20);not null;default:'free'"`
+	Metadata      UserMetadata   `json:"metadata" gorm:"type:jsonb;serializer:json"` // custom profile fields, see UserMetadataField
+	CreatedAt     time.Time      `json:"created_at" gorm:"index:idx_users_created_at_id,priority:1"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+	Version       int64          `json:"version" gorm:"not null;default:1"` // incremented by every update, for optimistic locking
+
+	// PasswordChangeRequired makes the user choose a new password on its next login, e.g. after
+	// an admin created it with a temporary one
+	PasswordChangeRequired bool `json:"password_change_required" gorm:"not null;default:false"`
+
+	// PendingAvatar is an uploaded avatar awaiting moderation, uploaded at PendingAvatarAt. Avatar
+	// stays the one shown until it's approved.
+	PendingAvatar   *string    `json:"-" gorm:"null"`
+	PendingAvatarAt *time.Time `json:"-" gorm:"index"`
 }
 
 // NewUser creates a new user instance
@@ -45,24 +109,24 @@ func NewUser(email, name string, role Role) *User {
 		Role:          role,
 		Provider:      ProviderLocal,
 		EmailVerified: false,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		RateLimitTier: RateLimitTierFree,
+		Version:       1,
 	}
 }
 
-// NewOAuthUser creates a new user from OAuth provider
-func NewOAuthUser(email, name, providerID string, provider Provider, avatar *string) *User {
+// NewOAuthUser creates a new user from OAuth provider. The provider account is linked with a
+// UserProvider.
+func NewOAuthUser(email, name string, provider Provider, avatar *string) *User {
 	return &User{
 		ID:            uuid.New().String(),
 		Email:         strings.ToLower(strings.TrimSpace(email)),
 		Name:          strings.TrimSpace(name),
 		Role:          RoleUser,
 		Provider:      provider,
-		ProviderID:    &providerID,
 		Avatar:        avatar,
 		EmailVerified: true, // OAuth users are considered verified
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		RateLimitTier: RateLimitTierFree,
+		Version:       1,
 	}
 }
 
@@ -85,15 +149,10 @@ func (u *User) Validate() error {
 	}
 
 	// For local users, password is required
-	if u.Provider == ProviderLocal && (u.Password == nil || *u.Password == "") {
+	if u.Provider == ProviderLocal && !u.HasPassword() {
 		return errors.New("password is required for local users")
 	}
 
-	// For OAuth users, provider ID is required
-	if u.Provider != ProviderLocal && (u.ProviderID == nil || *u.ProviderID == "") {
-		return errors.New("provider ID is required for OAuth users")
-	}
-
 	return nil
 }
 
@@ -114,40 +173,163 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
-// IsOAuthUser checks if user is from OAuth provider
-func (u *User) IsOAuthUser() bool {
-	return u.Provider != ProviderLocal
+// HasPassword checks if user can log in with a password. Users created with an OAuth provider
+// have none until they add one.
+func (u *User) HasPassword() bool {
+	return u.Password != nil && *u.Password != ""
 }
 
 // UpdateProfile updates user profile information
 func (u *User) UpdateProfile(name string, avatar *string) {
 	u.Name = strings.TrimSpace(name)
 	u.Avatar = avatar
-	u.UpdatedAt = time.Now()
 }
 
-// SetPassword sets the password for local users
-func (u *User) SetPassword(hashedPassword string) {
-	if u.Provider == ProviderLocal {
-		u.Password = &hashedPassword
-		u.UpdatedAt = time.Now()
+// SetName sets the name, keeping the rest of the profile
+func (u *User) SetName(name string) {
+	u.Name = strings.TrimSpace(name)
+}
+
+// SetAvatar sets the avatar URL, keeping the rest of the profile. A nil or empty URL removes it.
+func (u *User) SetAvatar(avatar *string) {
+	if avatar == nil || *avatar == "" {
+		u.Avatar = nil
+		return
 	}
+	u.Avatar = avatar
+}
+
+// SetPendingAvatar sets the uploaded avatar awaiting moderation, keeping the current one. A nil
+// URL withdraws it.
+func (u *User) SetPendingAvatar(avatar *string) {
+	if avatar == nil {
+		u.PendingAvatar = nil
+		u.PendingAvatarAt = nil
+		return
+	}
+	now := time.Now().UTC()
+	u.PendingAvatar = avatar
+	u.PendingAvatarAt = &now
+}
+
+// ApprovePendingAvatar replaces the avatar with the pending one
+func (u *User) ApprovePendingAvatar() {
+	u.Avatar = u.PendingAvatar
+	u.SetPendingAvatar(nil)
+}
+
+// SetLocale sets the preferred locale, an empty locale falls back to the request's Accept-Language
+func (u *User) SetLocale(locale string) {
+	u.Locale = strings.TrimSpace(locale)
+}
+
+// SetPassword sets the password, which users created with an OAuth provider can add. A password
+// the user has to change no longer has to be.
+func (u *User) SetPassword(hashedPassword string) {
+	u.Password = &hashedPassword
+	u.PasswordChangeRequired = false
+}
+
+// RequirePasswordChange makes the user choose a new password on its next login
+func (u *User) RequirePasswordChange() {
+	u.PasswordChangeRequired = true
+}
+
+// RemovePassword removes the password, so the user can only sign in with a linked provider
+func (u *User) RemovePassword() {
+	u.Password = nil
+}
+
+// ChangeEmail sets a new email address. The address was confirmed by the user, so it is
+// verified.
+func (u *User) ChangeEmail(email string) {
+	u.Email = strings.ToLower(strings.TrimSpace(email))
+	u.EmailVerified = true
+}
+
+// SetVerifiedPhone sets the phone number, which the user confirmed with a code sent to it
+func (u *User) SetVerifiedPhone(phone string) {
+	u.Phone = &phone
+	u.PhoneVerified = true
+}
+
+// SetTwoFactor sets the second factor the user signs in with
+func (u *User) SetTwoFactor(method TwoFactor) {
+	u.TwoFactor = method
+}
+
+// HasTwoFactor reports whether logins need a second factor
+func (u *User) HasTwoFactor() bool {
+	return u.TwoFactor != TwoFactorNone
 }
 
 // VerifyEmail marks email as verified
 func (u *User) VerifyEmail() {
 	u.EmailVerified = true
-	u.UpdatedAt = time.Now()
 }
 
 // PromoteToAdmin promotes user to admin role
 func (u *User) PromoteToAdmin() {
 	u.Role = RoleAdmin
-	u.UpdatedAt = time.Now()
 }
 
-// DemoteToUser demotes admin to user role
-func (u *User) DemoteToUser() {
-	u.Role = RoleUser
-	u.UpdatedAt = time.Now()
-}
\ No newline at end of file
+// SetRole assigns a built-in or custom role to the user
+func (u *User) SetRole(role Role) {
+	u.Role = role
+}
+
+// SetRateLimitTier sets the tier of the user's rate limit. It applies to the user's access tokens
+// issued after it, like a new role.
+func (u *User) SetRateLimitTier(tier RateLimitTier) {
+	u.RateLimitTier = tier
+}
+
+// Anonymize replaces the personal data of a deleted account, keeping the user's ID, role and
+// dates. The account can no longer sign in.
+func (u *User) Anonymize() {
+	u.Email = "deleted-" + u.ID + anonymizedEmailDomain
+	u.Name = "Deleted user"
+	u.Password = nil
+	u.Avatar = nil
+	u.SetPendingAvatar(nil)
+	u.EmailVerified = false
+	u.Locale = ""
+	u.Phone = nil
+	u.PhoneVerified = false
+	u.TwoFactor = TwoFactorNone
+}
+
+// IsAnonymized reports whether the user's account was deleted with its personal data replaced
+func (u *User) IsAnonymized() bool {
+	return strings.HasSuffix(u.Email, anonymizedEmailDomain)
+}
+
+// BeforeSave stores the timestamps GORM does not set itself in UTC
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.CreatedAt = u.CreatedAt.UTC()
+	return nil
+}
+
+// UserProvider links a user to their account at an OAuth provider, which the user can sign in
+// with. A user links at most one account of each provider.
+type UserProvider struct {
+	ID       string   `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID   string   `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_user_providers_user_provider,priority:1"`
+	Provider Provider `json:"provider" gorm:"type:varchar(10);not null;uniqueIndex:idx_user_providers_user_provider,priority:2;uniqueIndex:idx_user_providers_account,priority:1"`
+	// ProviderID identifies the account at the provider
+	ProviderID string `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_providers_account,priority:2"`
+	// Email is the email of the account at the provider, which may differ from the user's
+	Email     string    `json:"email" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewUserProvider links the account of a user at the provider
+func NewUserProvider(userID string, provider Provider, providerID, email string) *UserProvider {
+	return &UserProvider{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Provider:   provider,
+		ProviderID: providerID,
+		Email:      strings.ToLower(strings.TrimSpace(email)),
+	}
+}
@@ -0,0 +1,169 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// UserMetadata holds the custom profile fields of a user, by key. Values are strings, numbers or
+// booleans, as decoded from JSON.
+type UserMetadata map[string]any
+
+// String returns the value of a string field, and whether the user has one
+func (m UserMetadata) String(key string) (string, bool) {
+	value, ok := m[key].(string)
+	return value, ok
+}
+
+// Number returns the value of a number field, and whether the user has one
+func (m UserMetadata) Number(key string) (float64, bool) {
+	value, ok := m[key].(float64)
+	return value, ok
+}
+
+// Bool returns the value of a boolean field, and whether the user has one
+func (m UserMetadata) Bool(key string) (bool, bool) {
+	value, ok := m[key].(bool)
+	return value, ok
+}
+
+// UpdateMetadata sets the metadata fields of the changes, removing those set to nil. The other
+// fields are kept.
+func (u *User) UpdateMetadata(changes UserMetadata) {
+	// A new map, so copies of the user don't see the change
+	metadata := maps.Clone(u.Metadata)
+	if metadata == nil {
+		metadata = UserMetadata{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(metadata, key)
+		} else {
+			metadata[key] = value
+		}
+	}
+	u.Metadata = metadata
+}
+
+// MetadataFieldType is the type of the values of a metadata field
+type MetadataFieldType string
+
+const (
+	MetadataFieldString  MetadataFieldType = "string"
+	MetadataFieldNumber  MetadataFieldType = "number"
+	MetadataFieldBoolean MetadataFieldType = "boolean"
+)
+
+// IsValid reports whether the type exists
+func (t MetadataFieldType) IsValid() bool {
+	switch t {
+	case MetadataFieldString, MetadataFieldNumber, MetadataFieldBoolean:
+		return true
+	default:
+		return false
+	}
+}
+
+// MaxMetadataStringLength is the maximum number of characters of a string metadata value
+const MaxMetadataStringLength = 1000
+
+// metadataKeyPattern matches the keys of metadata fields, lower-cased like JSON fields
+var metadataKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// UserMetadataField is a custom profile field defined by admins. Users can only set the metadata
+// fields that are defined, with values of their type.
+type UserMetadataField struct {
+	Key         string            `json:"key" gorm:"type:varchar(50);primaryKey"`
+	Type        MetadataFieldType `json:"type" gorm:"type:varchar(10);not null"`
+	Description string            `json:"description" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// NewUserMetadataField creates a metadata field of the type
+func NewUserMetadataField(key string, fieldType MetadataFieldType, description string) *UserMetadataField {
+	return &UserMetadataField{
+		Key:         key,
+		Type:        fieldType,
+		Description: description,
+	}
+}
+
+// Validate validates the field's key, type and description
+func (f *UserMetadataField) Validate() error {
+	if !metadataKeyPattern.MatchString(f.Key) {
+		return errors.New("key must be 1 to 50 lower-case letters, digits or underscores, starting with a letter")
+	}
+	if !f.Type.IsValid() {
+		return errors.New("type must be string, number or boolean")
+	}
+	if len(f.Description) > 255 {
+		return errors.New("description must be at most 255 characters")
+	}
+	return nil
+}
+
+// Check checks that a value has the type of the field
+func (f *UserMetadataField) Check(value any) error {
+	switch f.Type {
+	case MetadataFieldString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("metadata field %s must be a string", f.Key)
+		}
+		if len([]rune(s)) > MaxMetadataStringLength {
+			return fmt.Errorf("metadata field %s must be at most %d characters", f.Key, MaxMetadataStringLength)
+		}
+	case MetadataFieldNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("metadata field %s must be a number", f.Key)
+		}
+	case MetadataFieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("metadata field %s must be a boolean", f.Key)
+		}
+	}
+	return nil
+}
+
+// UserMetadataSchema is the metadata fields users can set, by key
+type UserMetadataSchema map[string]*UserMetadataField
+
+// NewUserMetadataSchema returns the schema of the fields
+func NewUserMetadataSchema(fields []*UserMetadataField) UserMetadataSchema {
+	schema := make(UserMetadataSchema, len(fields))
+	for _, field := range fields {
+		schema[field.Key] = field
+	}
+	return schema
+}
+
+// Validate checks that every field the changes set is defined and has a value of its type.
+// Fields set to nil are removed, even when they are no longer defined.
+func (s UserMetadataSchema) Validate(changes UserMetadata) error {
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	// The first error is the same for every request
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := changes[key]
+		if value == nil {
+			continue
+		}
+		field, ok := s[key]
+		if !ok {
+			return fmt.Errorf("unknown metadata field %s", key)
+		}
+		if err := field.Check(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
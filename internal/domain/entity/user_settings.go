@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Theme is the color theme of the user's interface
+type Theme string
+
+const (
+	// ThemeSystem follows the theme of the user's device
+	ThemeSystem Theme = "system"
+	ThemeLight  Theme = "light"
+	ThemeDark   Theme = "dark"
+)
+
+// IsValid reports whether the theme exists
+func (t Theme) IsValid() bool {
+	switch t {
+	case ThemeSystem, ThemeLight, ThemeDark:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationCategory is a kind of email users can opt out of. Emails answering the user's
+// own requests, such as password resets, have none and are always sent.
+type NotificationCategory string
+
+const (
+	// NotificationSecurityAlerts are the emails about the security events of the user's account
+	NotificationSecurityAlerts NotificationCategory = "security_alerts"
+	// NotificationProductUpdates are the emails about the application's news
+	NotificationProductUpdates NotificationCategory = "product_updates"
+)
+
+// NotificationCategories lists the notification categories
+var NotificationCategories = []NotificationCategory{NotificationSecurityAlerts, NotificationProductUpdates}
+
+// IsValid reports whether the category exists
+func (c NotificationCategory) IsValid() bool {
+	return slices.Contains(NotificationCategories, c)
+}
+
+// EmailNotificationSettings are the emails a user chose to get, one field per notification
+// category
+type EmailNotificationSettings struct {
+	// SecurityAlerts emails the user about the security events of its account
+	SecurityAlerts bool `json:"security_alerts" gorm:"not null"`
+	// ProductUpdates lets the application email the user about its news
+	ProductUpdates bool `json:"product_updates" gorm:"not null"`
+}
+
+// Enabled reports whether the user gets the emails of the category
+func (s EmailNotificationSettings) Enabled(category NotificationCategory) bool {
+	switch category {
+	case NotificationSecurityAlerts:
+		return s.SecurityAlerts
+	case NotificationProductUpdates:
+		return s.ProductUpdates
+	default:
+		return false
+	}
+}
+
+// SetEnabled turns the emails of the category on or off
+func (s *EmailNotificationSettings) SetEnabled(category NotificationCategory, enabled bool) {
+	switch category {
+	case NotificationSecurityAlerts:
+		s.SecurityAlerts = enabled
+	case NotificationProductUpdates:
+		s.ProductUpdates = enabled
+	}
+}
+
+// UserSettings holds the preferences of a user, apart from the profile so changing them leaves
+// the user's identity alone. The preferred locale stays on the user, since access tokens carry it.
+type UserSettings struct {
+	UserID             string                    `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Timezone           string                    `json:"timezone" gorm:"type:varchar(64);not null"` // IANA time zone, empty means UTC
+	Theme              Theme                     `json:"theme" gorm:"type:varchar(10);not null"`
+	EmailNotifications EmailNotificationSettings `json:"email_notifications" gorm:"embedded;embeddedPrefix:email_"`
+	UpdatedAt          time.Time                 `json:"updated_at"`
+}
+
+// NewUserSettings creates the default settings of a user, which apply until it changes them
+func NewUserSettings(userID string) *UserSettings {
+	return &UserSettings{
+		UserID: userID,
+		Theme:  ThemeSystem,
+		EmailNotifications: EmailNotificationSettings{
+			SecurityAlerts: true,
+		},
+	}
+}
+
+// Location returns the user's time zone, or UTC when it has none or it is no longer known
+func (s *UserSettings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is an endpoint of a user that is sent the user's events of the types it subscribes to.
+// Deliveries are signed with the secret, which is kept in plaintext because signing needs it, and
+// shown to the user once on creation.
+type Webhook struct {
+	ID          string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      string    `json:"user_id" gorm:"type:uuid;not null;index"`
+	URL         string    `json:"url" gorm:"type:varchar(2048);not null"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	EventTypes  []string  `json:"event_types" gorm:"type:jsonb;serializer:json;not null"`
+	Secret      string    `json:"-" gorm:"type:varchar(128);not null"`
+	Active      bool      `json:"active" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewWebhook creates an active webhook
+func NewWebhook(userID, url, description string, eventTypes []string, secret string) *Webhook {
+	return &Webhook{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		URL:         strings.TrimSpace(url),
+		Description: strings.TrimSpace(description),
+		EventTypes:  normalizeEventTypes(eventTypes),
+		Secret:      secret,
+		Active:      true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// Validate validates the webhook entity
+func (w *Webhook) Validate() error {
+	if w.UserID == "" {
+		return errors.New("user ID is required")
+	}
+
+	if w.URL == "" {
+		return errors.New("URL is required")
+	}
+
+	if len(w.URL) > 2048 {
+		return errors.New("URL must be at most 2048 characters")
+	}
+
+	if len(w.Description) > 255 {
+		return errors.New("description must be at most 255 characters")
+	}
+
+	if len(w.EventTypes) == 0 {
+		return errors.New("at least one event type is required")
+	}
+
+	if w.Secret == "" {
+		return errors.New("secret is required")
+	}
+
+	return nil
+}
+
+// Update changes the endpoint, its description, its subscriptions and whether it is active
+func (w *Webhook) Update(url, description string, eventTypes []string, active bool) {
+	w.URL = strings.TrimSpace(url)
+	w.Description = strings.TrimSpace(description)
+	w.EventTypes = normalizeEventTypes(eventTypes)
+	w.Active = active
+	w.UpdatedAt = time.Now()
+}
+
+// Subscribes reports whether the webhook is active and subscribed to events of eventType
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && slices.Contains(w.EventTypes, eventType)
+}
+
+// normalizeEventTypes sorts event types and drops duplicates
+func normalizeEventTypes(eventTypes []string) []string {
+	normalized := slices.Clone(eventTypes)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// WebhookDelivery records one attempt at delivering an event to a webhook, for the delivery log
+type WebhookDelivery struct {
+	ID        string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	WebhookID string `json:"webhook_id" gorm:"type:uuid;not null;index:idx_webhook_deliveries_webhook_created,priority:1"`
+	// EventID is the same for every attempt at delivering an event, so receivers can deduplicate
+	EventID   string `json:"event_id" gorm:"type:varchar(64);not null"`
+	EventType string `json:"event_type" gorm:"type:varchar(64);not null"`
+	Attempt   int    `json:"attempt" gorm:"not null"`
+	Request   string `json:"request" gorm:"type:text;not null"`
+	// StatusCode is 0 when the endpoint didn't answer
+	StatusCode int    `json:"status_code" gorm:"not null"`
+	Response   string `json:"response" gorm:"type:text"`
+	Error      string `json:"error" gorm:"type:text"`
+	Succeeded  bool   `json:"succeeded" gorm:"not null"`
+	DurationMs int64  `json:"duration_ms" gorm:"not null"`
+	// CreatedAt is when the attempt was made
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_webhook_deliveries_webhook_created,priority:2;index"`
+}
@@ -1,14 +1,316 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorKind classifies domain errors so transports can map them to status codes
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindTooManyRequests
+	KindUnavailable
+	KindTimeout
+)
+
+// Error is a domain error with a stable, machine-readable code and a client-safe message
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+// NewError creates a new domain error
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches domain errors by code, so a wrapped or re-messaged error still matches its sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error carrying cause
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+// WithMessage returns a copy of the error with a more specific client-safe message
+func (e *Error) WithMessage(message string) *Error {
+	wrapped := *e
+	wrapped.Message = message
+	return &wrapped
+}
+
+// KindOf returns the kind of the first domain error in err's chain, or KindInternal
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// NewValidationError reports invalid input, exposing the validation message to the client
+func NewValidationError(err error) *Error {
+	return ErrValidation.WithMessage(err.Error()).Wrap(err)
+}
+
+// Generic errors
+var (
+	ErrInternal      = NewError(KindInternal, "INTERNAL_ERROR", "Internal server error")
+	ErrValidation    = NewError(KindInvalid, "INVALID_REQUEST", "Invalid request")
+	ErrUnauthorized  = NewError(KindUnauthorized, "UNAUTHORIZED", "User not authenticated")
+	ErrForbidden     = NewError(KindForbidden, "INSUFFICIENT_PERMISSIONS", "Insufficient permissions to access this resource")
+	ErrTimeout       = NewError(KindTimeout, "REQUEST_TIMEOUT", "Request took too long to process")
+	ErrNotFound      = NewError(KindNotFound, "NOT_FOUND", "Resource not found")
+	ErrConflict      = NewError(KindConflict, "CONFLICT", "The record was changed by another request, please reload it and try again")
+	ErrDuplicate     = NewError(KindConflict, "ALREADY_EXISTS", "The record already exists")
+	ErrForeignKey    = NewError(KindConflict, "FOREIGN_KEY_VIOLATION", "The record refers to a missing record, or is still referred to")
+	ErrInvalidCursor = NewError(KindInvalid, "INVALID_CURSOR", "Invalid pagination cursor")
+
+	ErrNetworkNotAllowed = NewError(KindForbidden, "NETWORK_NOT_ALLOWED", "Access from this network is not allowed")
+)
+
+// User errors
+var (
+	ErrUserNotFound        = NewError(KindNotFound, "USER_NOT_FOUND", "User not found")
+	ErrEmailAlreadyExists  = NewError(KindConflict, "EMAIL_EXISTS", "Email already exists")
+	ErrAvatarNotFound      = NewError(KindNotFound, "AVATAR_NOT_FOUND", "User has no avatar")
+	ErrOAuthAvatarReadOnly = NewError(KindForbidden, "OAUTH_AVATAR", "Cannot remove Google OAuth avatar")
+	ErrNoPendingAvatar     = NewError(KindNotFound, "PENDING_AVATAR_NOT_FOUND", "User has no avatar awaiting approval")
+	ErrDeletionUnconfirmed = NewError(KindInvalid, "DELETION_NOT_CONFIRMED", "Type your email to confirm the deletion of your account")
+	ErrAccountDeleted      = NewError(KindForbidden, "ACCOUNT_DELETED", "This account was deleted, please contact an administrator to restore it")
+)
+
+// Role errors
+var (
+	ErrRoleNotFound     = NewError(KindNotFound, "ROLE_NOT_FOUND", "Role not found")
+	ErrRoleExists       = NewError(KindConflict, "ROLE_EXISTS", "A role with this name already exists")
+	ErrBuiltinRole      = NewError(KindInvalid, "BUILTIN_ROLE", "The USER, MODERATOR and ADMIN roles can't be changed")
+	ErrRoleInUse        = NewError(KindConflict, "ROLE_IN_USE", "The role is assigned to users")
+	ErrLastAdmin        = NewError(KindConflict, "LAST_ADMIN", "The last admin can't be given another role")
+	ErrRoleNotGrantable = NewError(KindForbidden, "ROLE_NOT_GRANTABLE", "Roles and permissions beyond those of your own role can't be granted")
+)
+
+// User metadata errors
+var (
+	ErrMetadataFieldNotFound = NewError(KindNotFound, "METADATA_FIELD_NOT_FOUND", "Metadata field not found")
+	ErrMetadataFieldExists   = NewError(KindConflict, "METADATA_FIELD_EXISTS", "A metadata field with this key already exists")
+)
+
+// Authentication errors
+var (
+	ErrInvalidCredentials       = NewError(KindUnauthorized, "INVALID_CREDENTIALS", "Email or password is incorrect")
+	ErrOAuthRequired            = NewError(KindInvalid, "OAUTH_REQUIRED", "Please use OAuth login for this account")
+	ErrIncorrectPassword        = NewError(KindInvalid, "INCORRECT_PASSWORD", "Current password is incorrect")
+	ErrPasswordReused           = NewError(KindInvalid, "PASSWORD_REUSED", "Password was used recently, please choose another one")
+	ErrMissingToken             = NewError(KindUnauthorized, "MISSING_TOKEN", "Authorization header is required")
+	ErrInvalidTokenFormat       = NewError(KindUnauthorized, "INVALID_TOKEN_FORMAT", "Authorization header must be in format: Bearer <token>")
+	ErrInvalidToken             = NewError(KindUnauthorized, "INVALID_TOKEN", "Invalid or expired access token")
+	ErrTokenRevoked             = NewError(KindUnauthorized, "TOKEN_REVOKED", "Access token has been revoked")
+	ErrInvalidCSRFToken         = NewError(KindForbidden, "INVALID_CSRF_TOKEN", "Missing or invalid CSRF token")
+	ErrInvalidRefreshToken      = NewError(KindUnauthorized, "INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
+	ErrEmailNotVerified         = NewError(KindForbidden, "EMAIL_NOT_VERIFIED", "Email is not verified")
+	ErrPasswordChangeRequired   = NewError(KindForbidden, "PASSWORD_CHANGE_REQUIRED", "The password must be changed, please log in again with a new password")
+	ErrInvalidOAuthState        = NewError(KindInvalid, "INVALID_STATE", "Invalid OAuth state")
+	ErrMissingOAuthCode         = NewError(KindInvalid, "MISSING_CODE", "Authorization code not found")
+	ErrOAuthFailed              = NewError(KindUnauthorized, "GOOGLE_AUTH_FAILED", "Failed to authenticate with the OAuth provider")
+	ErrOAuthProviderNotFound    = NewError(KindNotFound, "OAUTH_PROVIDER_NOT_FOUND", "OAuth provider not found")
+	ErrInvalidIDToken           = NewError(KindUnauthorized, "INVALID_ID_TOKEN", "Invalid or expired ID token")
+	ErrProviderAlreadyLinked    = NewError(KindConflict, "PROVIDER_ALREADY_LINKED", "This sign-in method is already linked")
+	ErrProviderAccountInUse     = NewError(KindConflict, "PROVIDER_ACCOUNT_IN_USE", "This provider account is linked to another user")
+	ErrProviderNotLinked        = NewError(KindNotFound, "PROVIDER_NOT_LINKED", "This sign-in method is not linked")
+	ErrLastSignInMethod         = NewError(KindConflict, "LAST_SIGN_IN_METHOD", "The last sign-in method can't be removed")
+	ErrLoginThrottled           = NewError(KindTooManyRequests, "TOO_MANY_LOGIN_ATTEMPTS", "Too many failed login attempts, please try again later")
+	ErrAccountLocked            = NewError(KindTooManyRequests, "ACCOUNT_LOCKED", "Account is locked after too many failed login attempts, please try again later")
+	ErrInvalidResetToken        = NewError(KindInvalid, "INVALID_RESET_TOKEN", "Invalid or expired password reset link")
+	ErrInvalidVerificationToken = NewError(KindInvalid, "INVALID_VERIFICATION_TOKEN", "Invalid or expired email verification link")
+	ErrInvalidEmailChangeToken  = NewError(KindInvalid, "INVALID_EMAIL_CHANGE_TOKEN", "Invalid or expired email change link")
+	ErrInvalidUnsubscribeToken  = NewError(KindInvalid, "INVALID_UNSUBSCRIBE_TOKEN", "Invalid unsubscribe link")
+	ErrEmailUnchanged           = NewError(KindInvalid, "EMAIL_UNCHANGED", "The new email is the current email")
+	ErrSessionNotFound          = NewError(KindNotFound, "SESSION_NOT_FOUND", "Session not found")
+)
+
+// Two-factor authentication errors
+var (
+	ErrInvalidOTP          = NewError(KindInvalid, "INVALID_OTP", "Invalid or expired verification code")
+	ErrOTPRecentlySent     = NewError(KindTooManyRequests, "OTP_RECENTLY_SENT", "A verification code was sent recently, please wait before asking for another")
+	ErrPhoneNotVerified    = NewError(KindInvalid, "PHONE_NOT_VERIFIED", "Verify a phone number before enabling SMS two-factor authentication")
+	ErrSMSUnavailable      = NewError(KindUnavailable, "SMS_UNAVAILABLE", "Text messages can't be sent right now, please retry later")
+	ErrTwoFactorRequired   = NewError(KindForbidden, "TWO_FACTOR_REQUIRED", "This account signs in with a second factor, which this API doesn't support")
+	ErrTwoFactorNotEnabled = NewError(KindInvalid, "TWO_FACTOR_NOT_ENABLED", "Two-factor authentication is not enabled")
+	ErrInvalidRecoveryCode = NewError(KindInvalid, "INVALID_RECOVERY_CODE", "Invalid or already used recovery code")
+)
+
+// Registration errors
+var (
+	ErrRegistrationClosed      = NewError(KindForbidden, "REGISTRATION_CLOSED", "Registration is closed")
+	ErrEmailDomainNotAllowed   = NewError(KindForbidden, "EMAIL_DOMAIN_NOT_ALLOWED", "Registration is not open to emails of this domain")
+	ErrDisposableEmail         = NewError(KindInvalid, "DISPOSABLE_EMAIL", "Addresses of disposable email providers are not accepted")
+	ErrInvitationRequired      = NewError(KindForbidden, "INVITATION_REQUIRED", "Registration is by invitation only")
+	ErrInvalidInvitation       = NewError(KindInvalid, "INVALID_INVITATION", "Invalid, expired or already used invitation")
+	ErrInvitationEmailMismatch = NewError(KindInvalid, "INVITATION_EMAIL_MISMATCH", "The invitation is for another email address")
+	ErrInvitationNotFound      = NewError(KindNotFound, "INVITATION_NOT_FOUND", "Invitation not found")
+)
+
+// CAPTCHA errors
+var (
+	ErrCaptchaRequired    = NewError(KindInvalid, "CAPTCHA_REQUIRED", "CAPTCHA token is required")
+	ErrInvalidCaptcha     = NewError(KindInvalid, "INVALID_CAPTCHA", "CAPTCHA verification failed")
+	ErrCaptchaUnavailable = NewError(KindUnavailable, "CAPTCHA_UNAVAILABLE", "CAPTCHA verification is temporarily unavailable, please retry later")
+)
+
+// API key errors
+var (
+	ErrAPIKeyNotFound = NewError(KindNotFound, "API_KEY_NOT_FOUND", "API key not found")
+	ErrInvalidAPIKey  = NewError(KindUnauthorized, "INVALID_API_KEY", "Invalid, expired or revoked API key")
+)
+
+// Rate limit errors
+var (
+	ErrRateLimitExceeded        = NewError(KindTooManyRequests, "RATE_LIMIT_EXCEEDED", "Rate limit exceeded")
+	ErrRateLimitUnavailable     = NewError(KindUnavailable, "RATE_LIMIT_UNAVAILABLE", "Service temporarily unavailable, please retry later")
+	ErrRateLimitCounterNotFound = NewError(KindNotFound, "RATE_LIMIT_NOT_FOUND", "Rate limit counter not found")
+	ErrTooManyConcurrent        = NewError(KindUnavailable, "TOO_MANY_CONCURRENT_REQUESTS", "Too many concurrent requests")
+)
+
+// Job errors
+var (
+	ErrJobNotFound      = NewError(KindNotFound, "JOB_NOT_FOUND", "Job not found")
+	ErrJobNotDead       = NewError(KindInvalid, "JOB_NOT_DEAD", "Only dead jobs can be retried")
+	ErrInvalidJobStatus = NewError(KindInvalid, "INVALID_JOB_STATUS", "Invalid job status")
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound         = NewError(KindNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found")
+	ErrInvalidWebhookURL       = NewError(KindInvalid, "INVALID_WEBHOOK_URL", "Invalid webhook URL")
+	ErrInvalidWebhookEventType = NewError(KindInvalid, "INVALID_WEBHOOK_EVENT_TYPE", "Unknown webhook event type")
+	ErrWebhookLimitReached     = NewError(KindConflict, "WEBHOOK_LIMIT_REACHED", "Maximum number of webhooks reached")
+)
+
+// Security event errors
+var (
+	ErrInvalidSecurityEventType = NewError(KindInvalid, "INVALID_SECURITY_EVENT_TYPE", "Unknown security event type")
+)
+
+// Audit log errors
+var (
+	ErrInvalidAuditAction = NewError(KindInvalid, "INVALID_AUDIT_ACTION", "Unknown audit action")
+)
+
+// Activity feed errors
+var (
+	ErrInvalidActivityType = NewError(KindInvalid, "INVALID_ACTIVITY_TYPE", "Unknown activity type")
+)
 
 // Document errors
 var (
-	ErrDocumentNotFound       = errors.New("document not found")
-	ErrDocumentTitleRequired  = errors.New("document title is required")
-	ErrDocumentFileURLRequired = errors.New("document file URL is required")
-	ErrDocumentUserIDRequired = errors.New("document user ID is required")
-	ErrFileUploadFailed       = errors.New("file upload failed")
-	ErrInvalidFileType        = errors.New("invalid file type")
-	ErrFileTooLarge           = errors.New("file too large")
-)
\ No newline at end of file
+	ErrDocumentNotFound        = NewError(KindNotFound, "DOCUMENT_NOT_FOUND", "Document not found")
+	ErrDocumentTitleRequired   = NewError(KindInvalid, "TITLE_REQUIRED", "Document title is required")
+	ErrDocumentFileURLRequired = NewError(KindInvalid, "FILE_URL_REQUIRED", "Document file URL is required")
+	ErrDocumentUserIDRequired  = NewError(KindInvalid, "USER_ID_REQUIRED", "Document user ID is required")
+	ErrFileRequired            = NewError(KindInvalid, "FILE_REQUIRED", "File is required")
+	ErrFileUploadFailed        = NewError(KindInternal, "FILE_UPLOAD_FAILED", "File upload failed")
+	ErrInvalidFileType         = NewError(KindInvalid, "INVALID_FILE_TYPE", "Invalid file type")
+	ErrFileTooLarge            = NewError(KindTooLarge, "FILE_TOO_LARGE", "File too large")
+	ErrSearchUnavailable       = NewError(KindUnavailable, "SEARCH_UNAVAILABLE", "Search is temporarily unavailable, please retry later")
+	ErrFileInfected            = NewError(KindInvalid, "FILE_INFECTED", "The file contains malware")
+	ErrVirusScanUnavailable    = NewError(KindUnavailable, "VIRUS_SCAN_UNAVAILABLE", "Files can't be scanned for malware right now, please retry later")
+)
+
+// Quota errors
+var (
+	ErrQuotaExceeded      = NewError(KindTooManyRequests, "QUOTA_EXCEEDED", "Usage quota exceeded")
+	ErrInvalidQuotaMetric = NewError(KindInvalid, "INVALID_QUOTA_METRIC", "Unknown quota metric")
+)
+
+// QuotaExceededError carries the details of an exhausted usage quota
+type QuotaExceededError struct {
+	Metric   string
+	Limit    int64
+	Used     int64
+	ResetsAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded for %s: used %d of %d", e.Metric, e.Used, e.Limit)
+}
+
+// Unwrap allows errors.Is(err, ErrQuotaExceeded)
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// RetryAfter returns how long until the quota resets
+func (e *QuotaExceededError) RetryAfter() time.Duration {
+	return time.Until(e.ResetsAt)
+}
+
+// LoginThrottledError reports that login attempts for an account are temporarily blocked
+type LoginThrottledError struct {
+	Wait time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many login attempts, retry after %s", e.Wait)
+}
+
+// Unwrap allows errors.Is(err, ErrLoginThrottled)
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// RetryAfter returns how long until login attempts are accepted again
+func (e *LoginThrottledError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// AccountLockedError reports that an account is locked after too many failed logins
+type AccountLockedError struct {
+	Wait time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked, retry after %s", e.Wait)
+}
+
+// Unwrap allows errors.Is(err, ErrAccountLocked)
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// RetryAfter returns how long until the lock ends
+func (e *AccountLockedError) RetryAfter() time.Duration {
+	return e.Wait
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// ActionTokenRepository defines the interface for action token data operations
+type ActionTokenRepository interface {
+	// Create stores a new action token
+	Create(ctx context.Context, token *entity.ActionToken) error
+
+	// Consume deletes the action token with the purpose and hash and returns it, expired or not,
+	// so it can be used only once. It returns domain.ErrNotFound when there is none.
+	Consume(ctx context.Context, purpose entity.ActionTokenPurpose, tokenHash string) (*entity.ActionToken, error)
+
+	// DeleteByUserID deletes the user's action tokens for the purpose
+	DeleteByUserID(ctx context.Context, userID string, purpose entity.ActionTokenPurpose) error
+
+	// DeleteExpired deletes the expired action tokens, and returns how many were deleted
+	DeleteExpired(ctx context.Context) (int64, error)
+}
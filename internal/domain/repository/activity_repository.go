@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// ActivityFilter narrows a user's activity feed. Empty fields don't filter.
+type ActivityFilter struct {
+	UserID string
+	Type   entity.ActivityType
+	// Before excludes the activities recorded at or after the time
+	Before time.Time
+}
+
+// ActivityRepository defines the interface for activity feed data operations
+type ActivityRepository interface {
+	// Create records an activity
+	Create(ctx context.Context, activity *entity.Activity) error
+
+	// List returns a page of the activities matching the filter, newest first
+	List(ctx context.Context, filter ActivityFilter, limit, offset int) ([]*entity.Activity, error)
+
+	// Count returns the number of activities matching the filter
+	Count(ctx context.Context, filter ActivityFilter) (int64, error)
+
+	// DeleteByUserID deletes the activities of a user
+	DeleteByUserID(ctx context.Context, userID string) error
+
+	// DeleteBefore deletes the activities recorded before the time, and returns how many were
+	// deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
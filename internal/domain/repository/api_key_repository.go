@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	// Create creates a new API key
+	Create(ctx context.Context, apiKey *entity.APIKey) error
+
+	// FindByID finds an API key by ID, returning domain.ErrAPIKeyNotFound when there is none
+	FindByID(ctx context.Context, id string) (*entity.APIKey, error)
+
+	// FindByKeyHash finds an API key by the hash of its plaintext value, returning
+	// domain.ErrAPIKeyNotFound when there is none
+	FindByKeyHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+
+	// FindByUserID finds API keys by user ID
+	FindByUserID(ctx context.Context, userID string) ([]*entity.APIKey, error)
+
+	// Update updates an API key
+	Update(ctx context.Context, apiKey *entity.APIKey) error
+
+	// TouchLastUsed records the time the API key was last used
+	TouchLastUsed(ctx context.Context, id string) error
+
+	// Delete deletes an API key by ID
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// AuditLogFilter narrows a list of audit log entries. Empty fields don't filter.
+type AuditLogFilter struct {
+	ActorID    string
+	Action     entity.AuditAction
+	TargetType string
+	TargetID   string
+	// From and Before bound the time of the entries, From included
+	From   time.Time
+	Before time.Time
+}
+
+// AuditLogRepository defines the interface for audit log data operations
+type AuditLogRepository interface {
+	// Create records an audit log entry
+	Create(ctx context.Context, entry *entity.AuditLog) error
+
+	// List returns a page of the audit log entries matching the filter, newest first
+	List(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]*entity.AuditLog, error)
+
+	// Count returns the number of audit log entries matching the filter
+	Count(ctx context.Context, filter AuditLogFilter) (int64, error)
+
+	// DeleteBefore deletes the audit log entries recorded before the time, and returns how many
+	// were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
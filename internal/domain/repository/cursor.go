@@ -0,0 +1,24 @@
+package repository
+
+import "time"
+
+// Cursor is the position a keyset page starts after: the created_at and ID of the last record of
+// the previous page. Keyset pages are ordered newest first, with ties broken by ID, so they stay
+// fast on large tables where OFFSET has to skip every earlier row.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorOf returns the cursor of the page starting after a record
+func CursorOf(createdAt time.Time, id string) Cursor {
+	return Cursor{CreatedAt: createdAt, ID: id}
+}
+
+// Before reports whether c comes before other in (created_at, id) order
+func (c Cursor) Before(other Cursor) bool {
+	if c.CreatedAt.Equal(other.CreatedAt) {
+		return c.ID < other.ID
+	}
+	return c.CreatedAt.Before(other.CreatedAt)
+}
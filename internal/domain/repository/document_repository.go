@@ -2,16 +2,67 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gin-boilerplate/internal/domain/entity"
 )
 
+// Search highlights wrap the matching words of DocumentSearchResult.TitleHighlight and
+// DocumentSearchResult.Snippet. Control characters can't be mistaken for the text around them.
+const (
+	SearchHighlightStart = "\x02"
+	SearchHighlightStop  = "\x03"
+)
+
+// DocumentSearchResult is a document matching a full-text search
+type DocumentSearchResult struct {
+	Document *entity.Document
+	// Rank is how well the document matches, higher first
+	Rank float64
+	// TitleHighlight is the title, and Snippet excerpts of the description or the extracted text,
+	// with the matching words highlighted
+	TitleHighlight string
+	Snippet        string
+}
+
 type DocumentRepository interface {
 	Create(ctx context.Context, document *entity.Document) error
+	// FindByID finds a document by ID, returning domain.ErrDocumentNotFound when there is none
 	FindByID(ctx context.Context, id string) (*entity.Document, error)
 	FindByUserID(ctx context.Context, userID string, limit, offset int) ([]*entity.Document, error)
+	// FindByUserIDAfter returns up to limit of a user's documents, newest first, after the cursor,
+	// or from the newest when after is nil
+	FindByUserIDAfter(ctx context.Context, userID string, after *Cursor, limit int) ([]*entity.Document, error)
+	// FindByIDs finds the documents with the IDs, in no particular order, leaving out those there
+	// are none of
+	FindByIDs(ctx context.Context, ids []string) ([]*entity.Document, error)
+	// FindAfter returns up to limit documents of every user, newest first, after the cursor, or
+	// from the newest when after is nil
+	FindAfter(ctx context.Context, after *Cursor, limit int) ([]*entity.Document, error)
+	// Update updates a document if it still has the version it was read with, incrementing the
+	// version, and returns domain.ErrConflict otherwise
 	Update(ctx context.Context, document *entity.Document) error
 	Delete(ctx context.Context, id string) error
+	// GetFileURL returns the file URL of a document, or domain.ErrDocumentNotFound when there is
+	// none
 	GetFileURL(ctx context.Context, id string) (string, error)
 	CountByUserID(ctx context.Context, userID string) (int64, error)
-}
\ No newline at end of file
+	// Search returns a page of a user's documents matching the query, in web search syntax
+	// ("quoted phrases", or, -excluded words), the best matches first
+	Search(ctx context.Context, userID, query string, limit, offset int) ([]DocumentSearchResult, error)
+	// CountSearch returns the number of a user's documents matching the query
+	CountSearch(ctx context.Context, userID, query string) (int64, error)
+	// PurgeDeletedBefore permanently deletes the documents soft-deleted before the time, and
+	// returns how many were deleted
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// FileURLsInUse returns those of the file URLs some document, soft-deleted or not, refers to
+	FileURLsInUse(ctx context.Context, fileURLs []string) ([]string, error)
+	// Reindex rebuilds the indexes of the documents, e.g. after they became bloated or corrupt
+	Reindex(ctx context.Context) error
+	// CountCreatedPerDay returns how many documents were uploaded on each UTC day since the time,
+	// oldest first. Days without any are left out.
+	CountCreatedPerDay(ctx context.Context, since time.Time) ([]DailyCount, error)
+	// TopStorageUsers returns the limit users whose documents take the most storage, largest
+	// first
+	TopStorageUsers(ctx context.Context, limit int) ([]StorageUsage, error)
+}
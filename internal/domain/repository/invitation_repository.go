@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// InvitationRepository defines the interface for the invitations to register
+type InvitationRepository interface {
+	// Create stores an invitation
+	Create(ctx context.Context, invitation *entity.Invitation) error
+
+	// FindByID returns the invitation with the ID, or domain.ErrInvitationNotFound
+	FindByID(ctx context.Context, id string) (*entity.Invitation, error)
+
+	// FindByTokenHash returns the invitation of the token hash, or domain.ErrInvitationNotFound
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+
+	// FindPendingByEmail returns the latest invitation of the email that is neither accepted nor
+	// expired, or domain.ErrInvitationNotFound
+	FindPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error)
+
+	// List returns a page of invitations, newest first
+	List(ctx context.Context, limit, offset int) ([]*entity.Invitation, error)
+
+	// Count returns the number of invitations
+	Count(ctx context.Context) (int64, error)
+
+	// Accept marks the invitation as accepted by the user. It returns
+	// domain.ErrInvitationNotFound when the invitation was already accepted, so an invitation
+	// can't be used twice by concurrent registrations.
+	Accept(ctx context.Context, id, userID string) error
+
+	// Delete deletes an invitation, or returns domain.ErrInvitationNotFound
+	Delete(ctx context.Context, id string) error
+
+	// DeletePendingByEmail deletes the invitations of the email that weren't accepted
+	DeletePendingByEmail(ctx context.Context, email string) error
+}
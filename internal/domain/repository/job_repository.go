@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// JobRepository defines the interface for the persistent job queue
+type JobRepository interface {
+	// Create enqueues a job. Within a unit of work, the job is only enqueued if the transaction
+	// commits.
+	Create(ctx context.Context, job *entity.Job) error
+
+	// FindByID finds a job by ID, returning domain.ErrJobNotFound when there is none
+	FindByID(ctx context.Context, id string) (*entity.Job, error)
+
+	// Claim starts the pending job of one of types that has been due the longest, or a running
+	// job whose lease ran out, holding it for lease. It returns domain.ErrNotFound when no job is
+	// due. Concurrent workers never claim the same job.
+	Claim(ctx context.Context, types []string, lease time.Duration) (*entity.Job, error)
+
+	// Update saves a job
+	Update(ctx context.Context, job *entity.Job) error
+
+	// List returns a page of the jobs with status, or of every job when status is empty, newest
+	// first
+	List(ctx context.Context, status entity.JobStatus, limit, offset int) ([]*entity.Job, error)
+
+	// Count returns the number of jobs with status, or of every job when status is empty
+	Count(ctx context.Context, status entity.JobStatus) (int64, error)
+
+	// DeleteSucceededBefore deletes the jobs that succeeded before the time, and returns how many
+	// were deleted
+	DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// PreviousPasswordRepository defines the interface for the passwords users replaced
+type PreviousPasswordRepository interface {
+	// Create keeps a replaced password
+	Create(ctx context.Context, password *entity.PreviousPassword) error
+
+	// ListRecent returns the user's latest limit replaced passwords, newest first
+	ListRecent(ctx context.Context, userID string, limit int) ([]*entity.PreviousPassword, error)
+
+	// Prune deletes the user's replaced passwords but the latest keep
+	Prune(ctx context.Context, userID string, keep int) error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// QuotaRepository defines the interface for usage quota data operations
+type QuotaRepository interface {
+	// UpsertRollup creates or replaces the usage total for a user, metric and period
+	UpsertRollup(ctx context.Context, rollup *entity.UsageRollup) error
+
+	// FindRollup finds the usage total for a user, metric and period, returning domain.ErrNotFound
+	// when there is none
+	FindRollup(ctx context.Context, userID string, metric entity.QuotaMetric, period string) (*entity.UsageRollup, error)
+
+	// FindOverride finds the quota override of a metric for a user, returning domain.ErrNotFound
+	// when there is none
+	FindOverride(ctx context.Context, userID string, metric entity.QuotaMetric) (*entity.QuotaOverride, error)
+
+	// FindOverridesByUserID finds all quota overrides for a user
+	FindOverridesByUserID(ctx context.Context, userID string) ([]*entity.QuotaOverride, error)
+
+	// SaveOverride creates or updates a quota override
+	SaveOverride(ctx context.Context, override *entity.QuotaOverride) error
+
+	// DeleteOverride deletes the quota override of a metric for a user
+	DeleteOverride(ctx context.Context, userID string, metric entity.QuotaMetric) error
+}
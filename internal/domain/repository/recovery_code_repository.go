@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// RecoveryCodeRepository defines the interface for the recovery codes of two-factor logins
+type RecoveryCodeRepository interface {
+	// Replace deletes the user's recovery codes and stores codes in their place
+	Replace(ctx context.Context, userID string, codes []*entity.RecoveryCode) error
+
+	// Use marks the user's unused recovery code of the hash as used. It returns
+	// domain.ErrNotFound when there is none.
+	Use(ctx context.Context, userID, codeHash string) error
+
+	// CountUnused returns how many of the user's recovery codes are left
+	CountUnused(ctx context.Context, userID string) (int64, error)
+
+	// DeleteByUserID deletes all the user's recovery codes
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// RoleRepository defines the interface for the custom roles and their permissions
+type RoleRepository interface {
+	// Create stores a role with its permissions, or returns domain.ErrDuplicate when a role has
+	// its name
+	Create(ctx context.Context, role *entity.CustomRole) error
+
+	// FindByName returns the role with its permissions, or domain.ErrRoleNotFound
+	FindByName(ctx context.Context, name entity.Role) (*entity.CustomRole, error)
+
+	// List returns every role with its permissions, by name
+	List(ctx context.Context) ([]*entity.CustomRole, error)
+
+	// Update stores the description of the role and replaces its permissions, or returns
+	// domain.ErrRoleNotFound
+	Update(ctx context.Context, role *entity.CustomRole) error
+
+	// Delete deletes a role with its permissions, or returns domain.ErrRoleNotFound
+	Delete(ctx context.Context, name entity.Role) error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import "context"
+
+// DeletedScope decides which soft-deleted records repository queries see
+type DeletedScope int
+
+const (
+	// ExcludeDeleted hides soft-deleted records, the default
+	ExcludeDeleted DeletedScope = iota
+	// IncludeDeleted shows soft-deleted records along with the others
+	IncludeDeleted
+	// OnlyDeleted shows soft-deleted records only
+	OnlyDeleted
+)
+
+type deletedScopeKey struct{}
+
+// WithDeleted returns a copy of ctx whose repository queries also see soft-deleted records.
+// Deletes made with it are permanent.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, IncludeDeleted)
+}
+
+// WithOnlyDeleted returns a copy of ctx whose repository queries only see soft-deleted records.
+// Deletes made with it permanently remove records that were already soft-deleted.
+func WithOnlyDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, OnlyDeleted)
+}
+
+// DeletedScopeFromContext returns the scope set on ctx, ExcludeDeleted by default
+func DeletedScopeFromContext(ctx context.Context) DeletedScope {
+	scope, _ := ctx.Value(deletedScopeKey{}).(DeletedScope)
+	return scope
+}
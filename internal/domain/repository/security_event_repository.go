@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// SecurityEventFilter narrows a list of security events. Empty fields don't filter.
+type SecurityEventFilter struct {
+	UserID string
+	Type   entity.SecurityEventType
+	// Types matches events of any of the types
+	Types []entity.SecurityEventType
+}
+
+// SecurityEventRepository defines the interface for security event and known device data
+// operations
+type SecurityEventRepository interface {
+	// Create records a security event
+	Create(ctx context.Context, event *entity.SecurityEvent) error
+
+	// List returns a page of the security events matching the filter, newest first
+	List(ctx context.Context, filter SecurityEventFilter, limit, offset int) ([]*entity.SecurityEvent, error)
+
+	// Count returns the number of security events matching the filter
+	Count(ctx context.Context, filter SecurityEventFilter) (int64, error)
+
+	// DeleteBefore deletes the security events recorded before the time, and returns how many
+	// were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// TouchDevice records that the user was seen on the device, and reports whether the device
+	// is new and whether the user had other devices before
+	TouchDevice(ctx context.Context, device *entity.KnownDevice) (isNew, hadDevices bool, err error)
+
+	// TouchCountry records that the user logged in from the country, and reports whether the
+	// country is new and whether the user had logged in from other countries before
+	TouchCountry(ctx context.Context, country *entity.KnownCountry) (isNew, hadCountries bool, err error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "time"
+
+// DailyCount is how many records were created on a UTC day
+type DailyCount struct {
+	// Day is midnight UTC of the day
+	Day   time.Time
+	Count int64
+}
+
+// StorageUsage is the storage taken by a user's documents
+type StorageUsage struct {
+	UserID    string
+	Documents int64
+	Bytes     int64
+}
+
+// WebhookDeliveryStats counts the delivery attempts of a webhook
+type WebhookDeliveryStats struct {
+	WebhookID string
+	Attempts  int64
+	Failed    int64
+}
@@ -11,12 +11,17 @@ type TokenRepository interface {
 	// Create creates a new refresh token
 	Create(ctx context.Context, token *entity.Token) error
 
-	// FindByRefreshToken finds a token by refresh token
+	// FindByRefreshToken finds a token by refresh token, returning domain.ErrNotFound when there
+	// is none
 	FindByRefreshToken(ctx context.Context, refreshToken string) (*entity.Token, error)
 
 	// FindByUserID finds tokens by user ID
 	FindByUserID(ctx context.Context, userID string) ([]*entity.Token, error)
 
+	// FindActiveByUserID finds the unexpired tokens of a user, one per signed-in device, newest
+	// first
+	FindActiveByUserID(ctx context.Context, userID string) ([]*entity.Token, error)
+
 	// Update updates a token
 	Update(ctx context.Context, token *entity.Token) error
 
@@ -29,8 +34,12 @@ type TokenRepository interface {
 	// DeleteByUserID deletes all tokens for a user (logout from all devices)
 	DeleteByUserID(ctx context.Context, userID string) error
 
-	// DeleteExpiredTokens deletes all expired tokens
-	DeleteExpiredTokens(ctx context.Context) error
+	// DeleteExpiredTokens permanently deletes all expired tokens, revoked ones included, and
+	// returns how many were deleted
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+
+	// CountRows returns the number of rows of the token table, soft-deleted ones included
+	CountRows(ctx context.Context) (int64, error)
 
 	// RevokeToken revokes a token by setting expiration to past
 	RevokeToken(ctx context.Context, refreshToken string) error
@@ -38,6 +47,10 @@ type TokenRepository interface {
 	// RevokeAllUserTokens revokes all tokens for a user
 	RevokeAllUserTokens(ctx context.Context, userID string) error
 
+	// RevokeSession revokes the tokens of a session of a user, returning domain.ErrNotFound
+	// when the user has no such active session
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+
 	// IsTokenValid checks if a refresh token is valid and not expired
 	IsTokenValid(ctx context.Context, refreshToken string) (bool, error)
-}
\ No newline at end of file
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// UnitOfWork runs repository calls in a single transaction
+type UnitOfWork interface {
+	// Do runs fn in a transaction. Repository calls made with the context passed to fn are part
+	// of it. The transaction is committed when fn returns nil and rolled back when it returns an
+	// error or panics. Calling Do inside fn nests a savepoint in the same transaction.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// UserMetadataFieldRepository defines the interface for the metadata fields users can set
+type UserMetadataFieldRepository interface {
+	// Create stores a field, or returns domain.ErrDuplicate when a field has its key
+	Create(ctx context.Context, field *entity.UserMetadataField) error
+
+	// FindByKey returns the field, or domain.ErrMetadataFieldNotFound
+	FindByKey(ctx context.Context, key string) (*entity.UserMetadataField, error)
+
+	// List returns every field, by key
+	List(ctx context.Context) ([]*entity.UserMetadataField, error)
+
+	// Update stores the type and description of the field, or returns
+	// domain.ErrMetadataFieldNotFound
+	Update(ctx context.Context, field *entity.UserMetadataField) error
+
+	// Delete deletes a field, or returns domain.ErrMetadataFieldNotFound
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// UserProviderRepository defines the interface for the links of users to their OAuth provider
+// accounts
+type UserProviderRepository interface {
+	// Create links a provider account, returning domain.ErrDuplicate when the account or the
+	// user's provider is already linked
+	Create(ctx context.Context, link *entity.UserProvider) error
+
+	// FindByProviderID finds the link of a provider account, returning domain.ErrNotFound when
+	// there is none
+	FindByProviderID(ctx context.Context, provider entity.Provider, providerID string) (*entity.UserProvider, error)
+
+	// ListByUserID returns the links of the user, oldest first
+	ListByUserID(ctx context.Context, userID string) ([]*entity.UserProvider, error)
+
+	// Delete unlinks the user's account at the provider, returning domain.ErrNotFound when
+	// there is none
+	Delete(ctx context.Context, userID string, provider entity.Provider) error
+}
@@ -2,39 +2,91 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gin-boilerplate/internal/domain/entity"
 )
 
+// UserFilter narrows a list of users. Empty fields don't filter.
+type UserFilter struct {
+	// Search matches users whose email or name contains it, ignoring case
+	Search        string
+	Role          entity.Role
+	Provider      entity.Provider
+	EmailVerified *bool
+	// PendingAvatar keeps only the users with an uploaded avatar awaiting moderation
+	PendingAvatar bool
+	// CreatedFrom and CreatedBefore bound when the users were created; zero times don't
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+}
+
+// UserSortField is a field users can be sorted by
+type UserSortField string
+
+// User sort fields
+const (
+	UserSortCreatedAt UserSortField = "created_at"
+	UserSortEmail     UserSortField = "email"
+	UserSortName      UserSortField = "name"
+	// UserSortPendingAvatarAt sorts by when the avatar awaiting moderation was uploaded
+	UserSortPendingAvatarAt UserSortField = "pending_avatar_at"
+)
+
+// UserSort orders a list of users. The zero value lists the newest first.
+type UserSort struct {
+	Field UserSortField
+	// Ascending lists the smallest values first instead of the largest
+	Ascending bool
+}
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
-	// Create creates a new user
+	// Create creates a new user, returning domain.ErrEmailAlreadyExists when the email is taken
 	Create(ctx context.Context, user *entity.User) error
 
-	// FindByID finds a user by ID
+	// FindByID finds a user by ID, returning domain.ErrUserNotFound when there is none
 	FindByID(ctx context.Context, id string) (*entity.User, error)
 
-	// FindByEmail finds a user by email
+	// FindByEmail finds a user by email, returning domain.ErrUserNotFound when there is none
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 
-	// FindByProviderID finds a user by provider and provider ID
-	FindByProviderID(ctx context.Context, provider entity.Provider, providerID string) (*entity.User, error)
-
-	// Update updates a user
+	// Update updates a user if it still has the version it was read with, incrementing the
+	// version, and returns domain.ErrConflict otherwise
 	Update(ctx context.Context, user *entity.User) error
 
 	// Delete deletes a user by ID
 	Delete(ctx context.Context, id string) error
 
-	// List returns a list of users with pagination
-	List(ctx context.Context, limit, offset int) ([]*entity.User, error)
+	// Restore undeletes a soft-deleted user, returning domain.ErrUserNotFound when no
+	// soft-deleted user has the ID
+	Restore(ctx context.Context, id string) error
 
-	// Count returns the total number of users
-	Count(ctx context.Context) (int64, error)
+	// List returns a page of the users matching the filter, in the sort order
+	List(ctx context.Context, filter UserFilter, sort UserSort, limit, offset int) ([]*entity.User, error)
+
+	// ListAfter returns up to limit users matching the filter, newest first, after the cursor,
+	// or from the newest when after is nil
+	ListAfter(ctx context.Context, filter UserFilter, after *Cursor, limit int) ([]*entity.User, error)
+
+	// Count returns the number of users matching the filter
+	Count(ctx context.Context, filter UserFilter) (int64, error)
 
 	// EmailExists checks if email already exists
 	EmailExists(ctx context.Context, email string) (bool, error)
 
 	// FindByRole finds users by role
 	FindByRole(ctx context.Context, role entity.Role, limit, offset int) ([]*entity.User, error)
-}
\ No newline at end of file
+
+	// PurgeDeletedBefore permanently deletes the users soft-deleted before the time, and returns
+	// how many were deleted
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// AvatarsInUse returns those of the avatar URLs some user, soft-deleted or not, still has,
+	// awaiting moderation or not
+	AvatarsInUse(ctx context.Context, avatarURLs []string) ([]string, error)
+
+	// CountCreatedPerDay returns how many users were created on each UTC day since the time,
+	// oldest first. Days without any are left out.
+	CountCreatedPerDay(ctx context.Context, since time.Time) ([]DailyCount, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// UserSettingsRepository defines the interface for the settings of users
+type UserSettingsRepository interface {
+	// FindByUserID returns the settings of a user, or the defaults of entity.NewUserSettings when
+	// it never changed them
+	FindByUserID(ctx context.Context, userID string) (*entity.UserSettings, error)
+
+	// Save creates or replaces the settings of a user
+	Save(ctx context.Context, settings *entity.UserSettings) error
+
+	// DeleteByUserID deletes the settings of a user
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// WebhookRepository defines the interface for webhook and webhook delivery data operations
+type WebhookRepository interface {
+	// Create creates a new webhook
+	Create(ctx context.Context, webhook *entity.Webhook) error
+
+	// FindByID finds a webhook by ID, returning domain.ErrWebhookNotFound when there is none
+	FindByID(ctx context.Context, id string) (*entity.Webhook, error)
+
+	// FindByUserID finds the webhooks of a user, newest first
+	FindByUserID(ctx context.Context, userID string) ([]*entity.Webhook, error)
+
+	// CountByUserID returns the number of webhooks of a user
+	CountByUserID(ctx context.Context, userID string) (int64, error)
+
+	// Update updates a webhook
+	Update(ctx context.Context, webhook *entity.Webhook) error
+
+	// Delete deletes a webhook by ID together with its deliveries
+	Delete(ctx context.Context, id string) error
+
+	// CreateDelivery records a delivery attempt
+	CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+
+	// ListDeliveries returns a page of the delivery attempts of a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error)
+
+	// CountDeliveries returns the number of delivery attempts of a webhook
+	CountDeliveries(ctx context.Context, webhookID string) (int64, error)
+
+	// DeleteDeliveriesBefore deletes the delivery attempts made before the time, and returns how
+	// many were deleted
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// CountDeliveriesSince counts the delivery attempts made since the time, and those of them
+	// that failed
+	CountDeliveriesSince(ctx context.Context, since time.Time) (attempts, failed int64, err error)
+
+	// FailingWebhooks returns the limit webhooks with the most failed delivery attempts since the
+	// time, most failures first. Webhooks without failures are left out.
+	FailingWebhooks(ctx context.Context, since time.Time, limit int) ([]WebhookDeliveryStats, error)
+}
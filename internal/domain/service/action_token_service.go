@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// actionTokenBytes is the amount of random entropy in an action token
+const actionTokenBytes = 32
+
+// ActionTokenService issues and redeems the single-use tokens emailed to users to confirm an
+// action. Only their hashes are stored, so a leaked database doesn't leak usable links.
+type ActionTokenService struct {
+	repo repository.ActionTokenRepository
+}
+
+// NewActionTokenService creates a new action token service
+func NewActionTokenService(repo repository.ActionTokenRepository) *ActionTokenService {
+	return &ActionTokenService{
+		repo: repo,
+	}
+}
+
+// Issue creates a token of the user for the purpose, valid for ttl, and returns its plaintext.
+// The user's earlier tokens for the purpose are deleted, so only the latest email works.
+func (s *ActionTokenService) Issue(ctx context.Context, userID string, purpose entity.ActionTokenPurpose, ttl time.Duration) (string, error) {
+	if err := s.Revoke(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	return s.IssueForEmail(ctx, userID, purpose, "", ttl)
+}
+
+// IssueForEmail creates a token of the user for the purpose about the email address, valid for
+// ttl, and returns its plaintext. Unlike Issue, it keeps the user's earlier tokens for the
+// purpose.
+func (s *ActionTokenService) IssueForEmail(ctx context.Context, userID string, purpose entity.ActionTokenPurpose, email string, ttl time.Duration) (string, error) {
+	buf := make([]byte, actionTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate action token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := s.repo.Create(ctx, entity.NewActionToken(userID, purpose, hashActionToken(token), email, ttl)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Revoke deletes the user's tokens for the purpose, so their links stop working
+func (s *ActionTokenService) Revoke(ctx context.Context, userID string, purpose entity.ActionTokenPurpose) error {
+	return s.repo.DeleteByUserID(ctx, userID, purpose)
+}
+
+// Consume redeems a token for the purpose and returns it. Unknown, used and expired tokens all
+// return domain.ErrNotFound.
+func (s *ActionTokenService) Consume(ctx context.Context, purpose entity.ActionTokenPurpose, token string) (*entity.ActionToken, error) {
+	actionToken, err := s.repo.Consume(ctx, purpose, hashActionToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if actionToken.IsExpired() {
+		return nil, domain.ErrNotFound
+	}
+	return actionToken, nil
+}
+
+// PurgeExpired deletes the expired tokens, and returns how many were deleted
+func (s *ActionTokenService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx)
+}
+
+// hashActionToken hashes a plaintext action token for storage and lookup
+func hashActionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ActivityService records the activities of users' feeds, and publishes them on their event
+// streams as activity.<type>, for notifications to build on. Recording is best-effort: failures
+// are logged and never fail the operation the activity is about.
+type ActivityService struct {
+	repo     repository.ActivityRepository
+	eventBus *EventBus
+}
+
+// NewActivityService creates a new activity service. eventBus may be nil, e.g. in commands
+// without Redis, to skip publishing.
+func NewActivityService(repo repository.ActivityRepository, eventBus *EventBus) *ActivityService {
+	return &ActivityService{
+		repo:     repo,
+		eventBus: eventBus,
+	}
+}
+
+// Record records an activity of the user
+func (s *ActivityService) Record(ctx context.Context, userID string, activityType entity.ActivityType, details map[string]string) {
+	// The activity happened even when the request that caused it is cancelled
+	ctx = context.WithoutCancel(ctx)
+	activity := entity.NewActivity(userID, activityType, details)
+
+	logger := logging.FromContext(ctx).WithFields(logrus.Fields{
+		"activity":       activityType,
+		"target_user_id": userID,
+	})
+
+	if err := s.repo.Create(ctx, activity); err != nil {
+		logger.WithError(err).Error("Failed to record activity")
+		return
+	}
+
+	if s.eventBus != nil {
+		if err := s.eventBus.Publish(ctx, userID, "activity."+string(activityType), activity); err != nil {
+			logger.WithError(err).Warn("Failed to publish activity")
+		}
+	}
+}
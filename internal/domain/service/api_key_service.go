@@ -0,0 +1,50 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// apiKeyPrefix marks plaintext keys so they are recognizable in logs and secret scanners
+	apiKeyPrefix = "gbk_"
+	// apiKeyBytes is the amount of random entropy in a generated key
+	apiKeyBytes = 32
+	// apiKeyDisplayLength is the number of leading characters kept for identification
+	apiKeyDisplayLength = 12
+)
+
+// APIKeyService handles API key generation and hashing
+type APIKeyService interface {
+	// GenerateKey generates a new plaintext API key, its display prefix and its hash
+	GenerateKey() (key, prefix, hash string, err error)
+
+	// HashKey hashes a plaintext API key for storage and lookup
+	HashKey(key string) string
+}
+
+type apiKeyService struct{}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService() APIKeyService {
+	return &apiKeyService{}
+}
+
+// GenerateKey generates a new plaintext API key, its display prefix and its hash
+func (s *apiKeyService) GenerateKey() (string, string, string, error) {
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+	return key, key[:apiKeyDisplayLength], s.HashKey(key), nil
+}
+
+// HashKey hashes a plaintext API key for storage and lookup
+func (s *apiKeyService) HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
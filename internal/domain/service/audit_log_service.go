@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AuditActor is who made the request that acted: the authenticated user, and the IP and user
+// agent of the request
+type AuditActor struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a copy of ctx whose audited actions are the actor's. The HTTP and
+// gRPC authentication set it for every authenticated request.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor of ctx, which is empty for actions of the API itself
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
+
+// AuditLogService records who did what in the audit log, for admins to review. Recording is
+// best-effort: failures are logged and never fail the audited action.
+type AuditLogService struct {
+	repo repository.AuditLogRepository
+}
+
+// NewAuditLogService creates a new audit log service
+func NewAuditLogService(repo repository.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{
+		repo: repo,
+	}
+}
+
+// Record records the actor of ctx taking the action on a target
+func (s *AuditLogService) Record(ctx context.Context, action entity.AuditAction, targetType, targetID string, details map[string]string) {
+	// The action is done even when the request that made it is cancelled
+	ctx = context.WithoutCancel(ctx)
+	actor := AuditActorFromContext(ctx)
+	entry := entity.NewAuditLog(actor.UserID, action, targetType, targetID, actor.IP, actor.UserAgent, details)
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"audit_action": action,
+			"target_id":    targetID,
+		}).Error("Failed to record audit log entry")
+	}
+}
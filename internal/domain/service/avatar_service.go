@@ -11,12 +11,14 @@ import (
 )
 
 type AvatarService struct {
-	storage *storage.S3Client
+	storage   *storage.S3Client
+	virusScan *VirusScanService
 }
 
-func NewAvatarService(storage *storage.S3Client) *AvatarService {
+func NewAvatarService(storage *storage.S3Client, virusScan *VirusScanService) *AvatarService {
 	return &AvatarService{
-		storage: storage,
+		storage:   storage,
+		virusScan: virusScan,
 	}
 }
 
@@ -24,7 +26,7 @@ func (s *AvatarService) UploadAvatar(ctx context.Context, file *multipart.FileHe
 	// Validate file size (max 2MB for avatar)
 	const maxAvatarSize = 2 * 1024 * 1024
 	if file.Size > maxAvatarSize {
-		return nil, domain.ErrFileTooLarge
+		return nil, domain.ErrFileTooLarge.WithMessage("File too large (max 2MB)")
 	}
 
 	// Validate file type
@@ -40,6 +42,11 @@ func (s *AvatarService) UploadAvatar(ctx context.Context, file *multipart.FileHe
 		return nil, domain.ErrInvalidFileType
 	}
 
+	// Scan the file for malware, rejecting infected files
+	if _, err := s.virusScan.ScanUpload(ctx, file); err != nil {
+		return nil, err
+	}
+
 	// Open the uploaded file
 	fileReader, err := file.Open()
 	if err != nil {
@@ -84,4 +91,4 @@ func (s *AvatarService) contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -4,21 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"gin-boilerplate/internal/infrastructure/logging"
 	"gin-boilerplate/internal/infrastructure/redis"
 )
 
 type CacheService struct {
 	redisClient *redis.RedisClient
-	prefix     string
-	defaultTTL time.Duration
+	prefix      string
+	defaultTTL  time.Duration
 }
 
 func NewCacheService(redisClient *redis.RedisClient) *CacheService {
 	return &CacheService{
 		redisClient: redisClient,
-		prefix:     "gin-boilerplate:",
+		prefix:      "gin-boilerplate:",
 		defaultTTL:  15 * time.Minute, // 15 minutes default TTL
 	}
 }
@@ -31,7 +33,7 @@ type CacheKey struct {
 
 // String returns formatted cache key
 func (ck CacheKey) String() string {
-	return fmt.Sprintf("%s:%s:%s", ck.Namespace, ck.ID)
+	return fmt.Sprintf("%s:%s", ck.Namespace, ck.ID)
 }
 
 // Set stores a value in cache with TTL
@@ -121,9 +123,9 @@ func (s *CacheService) DeletePattern(ctx context.Context, pattern string) error
 
 	// Delete all matching keys
 	for _, key := range keys {
-		if err := s.redisClient.Del(ctx, key).Err(); err != nil {
+		if err := s.redisClient.Del(ctx, key); err != nil {
 			// Log error but continue with other keys
-			fmt.Printf("Warning: failed to delete cache key %s: %v\n", key, err)
+			logging.FromContext(ctx).WithError(err).WithField("key", key).Warn("Failed to delete cache key")
 		}
 	}
 
@@ -161,6 +163,68 @@ func (s *CacheService) Increment(ctx context.Context, key CacheKey) (int64, erro
 	return s.redisClient.Increment(ctx, cacheKey)
 }
 
+// IncrementWithExpiry atomically increments a counter, starting its TTL when the counter is created
+func (s *CacheService) IncrementWithExpiry(ctx context.Context, key CacheKey, expiration time.Duration) (int64, error) {
+	cacheKey := key.String()
+	return s.redisClient.IncrementWithExpiry(ctx, cacheKey, expiration)
+}
+
+// IncrementByWithExpiry atomically increments a counter by amount, starting its TTL when the counter is created
+func (s *CacheService) IncrementByWithExpiry(ctx context.Context, key CacheKey, amount int64, expiration time.Duration) (int64, error) {
+	cacheKey := key.String()
+	return s.redisClient.IncrementByWithExpiry(ctx, cacheKey, amount, expiration)
+}
+
+// DecrementBy atomically decrements a counter by amount
+func (s *CacheService) DecrementBy(ctx context.Context, key CacheKey, amount int64) (int64, error) {
+	cacheKey := key.String()
+	return s.redisClient.DecrementBy(ctx, cacheKey, amount)
+}
+
+// AddToSetWithExpiry adds a member to a set, which expires once no member was added for
+// expiration
+func (s *CacheService) AddToSetWithExpiry(ctx context.Context, key CacheKey, member string, expiration time.Duration) error {
+	cacheKey := key.String()
+	return s.redisClient.AddToSetWithExpiry(ctx, cacheKey, member, expiration)
+}
+
+// SetMembers returns the members of a set
+func (s *CacheService) SetMembers(ctx context.Context, key CacheKey) ([]string, error) {
+	cacheKey := key.String()
+	return s.redisClient.SetMembers(ctx, cacheKey)
+}
+
+// ScanNamespace returns the IDs of all keys stored under a namespace
+func (s *CacheService) ScanNamespace(ctx context.Context, namespace string) ([]string, error) {
+	return s.ScanNamespacePrefix(ctx, namespace, "")
+}
+
+// ScanNamespacePrefix returns the IDs of all keys under a namespace whose ID starts with prefix
+func (s *CacheService) ScanNamespacePrefix(ctx context.Context, namespace, prefix string) ([]string, error) {
+	keys, err := s.redisClient.Scan(ctx, namespace+":"+escapePattern(prefix)+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = strings.TrimPrefix(key, namespace+":")
+	}
+	return ids, nil
+}
+
+// escapePattern escapes Redis glob characters so value is matched literally
+func escapePattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+	return replacer.Replace(value)
+}
+
+// TTL returns the remaining time to live of a key
+func (s *CacheService) TTL(ctx context.Context, key CacheKey) (time.Duration, error) {
+	cacheKey := key.String()
+	return s.redisClient.TTL(ctx, cacheKey)
+}
+
 // Utility functions for common cache namespaces
 func UserCacheKey(userID string) CacheKey {
 	return CacheKey{Namespace: "user", ID: userID}
@@ -174,10 +238,42 @@ func RateLimitCacheKey(identifier string) CacheKey {
 	return CacheKey{Namespace: "rate_limit", ID: identifier}
 }
 
+func LoginThrottleCacheKey(identifier string) CacheKey {
+	return CacheKey{Namespace: "login_throttle", ID: identifier}
+}
+
 func DocumentCacheKey(documentID string) CacheKey {
 	return CacheKey{Namespace: "document", ID: documentID}
 }
 
 func SessionCacheKey(sessionID string) CacheKey {
 	return CacheKey{Namespace: "session", ID: sessionID}
-}
\ No newline at end of file
+}
+
+func QuotaCacheKey(metric, period, userID string) CacheKey {
+	return CacheKey{Namespace: "quota", ID: fmt.Sprintf("%s:%s:%s", metric, period, userID)}
+}
+
+func DailyCounterCacheKey(name string, day time.Time) CacheKey {
+	return CacheKey{Namespace: "daily_counter", ID: name + ":" + day.UTC().Format("2006-01-02")}
+}
+
+func DashboardCacheKey(panel string) CacheKey {
+	return CacheKey{Namespace: "dashboard", ID: panel}
+}
+
+func TokenDenylistCacheKey(identifier string) CacheKey {
+	return CacheKey{Namespace: "token_denylist", ID: identifier}
+}
+
+func OAuthStateCacheKey(nonce string) CacheKey {
+	return CacheKey{Namespace: "oauth_state", ID: nonce}
+}
+
+func OTPCacheKey(purpose, id string) CacheKey {
+	return CacheKey{Namespace: "otp", ID: purpose + ":" + id}
+}
+
+func PolicyCacheKey(role string) CacheKey {
+	return CacheKey{Namespace: "policy", ID: role}
+}
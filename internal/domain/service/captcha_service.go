@@ -0,0 +1,18 @@
+package service
+
+import "context"
+
+// CAPTCHA actions name the endpoints a CAPTCHA can be required on
+const (
+	CaptchaActionRegister       = "register"
+	CaptchaActionLogin          = "login"
+	CaptchaActionForgotPassword = "forgot_password"
+)
+
+// CaptchaService verifies the CAPTCHA tokens clients solved before calling an endpoint
+type CaptchaService interface {
+	// Verify checks that token was solved for action by the client at remoteIP. It returns
+	// domain.ErrInvalidCaptcha when the token is rejected, and domain.ErrCaptchaUnavailable when
+	// the provider can't be reached.
+	Verify(ctx context.Context, token, action, remoteIP string) error
+}
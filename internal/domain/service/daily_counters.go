@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// DailyCounterRetention is how long the daily counters are kept, which bounds how far back their
+// time series go
+const DailyCounterRetention = 90 * 24 * time.Hour
+
+// Daily counters
+const (
+	// DailyCounterLogins counts successful logins
+	DailyCounterLogins = "logins"
+	// DailyCounterRateLimitRejections counts requests rejected by the rate limiter
+	DailyCounterRateLimitRejections = "rate_limit_rejections"
+)
+
+// DailyCounters counts events per UTC day in Redis, for the time series of events that leave no
+// record in Postgres
+type DailyCounters struct {
+	cacheService *CacheService
+}
+
+// NewDailyCounters creates daily counters stored with the cache service
+func NewDailyCounters(cacheService *CacheService) *DailyCounters {
+	return &DailyCounters{
+		cacheService: cacheService,
+	}
+}
+
+// Increment counts an event of today. Counting is best-effort: a failure is logged and doesn't
+// fail the operation being counted.
+func (d *DailyCounters) Increment(ctx context.Context, name string) {
+	key := DailyCounterCacheKey(name, time.Now())
+	// The counter outlives the retention by a day, so the oldest day of a series is complete
+	if _, err := d.cacheService.IncrementWithExpiry(ctx, key, DailyCounterRetention+24*time.Hour); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("counter", name).Debug("Failed to increment daily counter")
+	}
+}
+
+// Series returns the count of each UTC day from the day of since up to today, oldest first.
+// Days nothing was counted on have a count of 0.
+func (d *DailyCounters) Series(ctx context.Context, name string, since time.Time) ([]repository.DailyCount, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	counts := []repository.DailyCount{}
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(today); day = day.AddDate(0, 0, 1) {
+		value, err := d.cacheService.GetString(ctx, DailyCounterCacheKey(name, day))
+		if err != nil {
+			return nil, err
+		}
+		count, _ := strconv.ParseInt(value, 10, 64)
+		counts = append(counts, repository.DailyCount{Day: day, Count: count})
+	}
+	return counts, nil
+}
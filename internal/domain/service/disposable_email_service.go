@@ -0,0 +1,8 @@
+package service
+
+// DisposableEmailService recognizes addresses of disposable email providers, which give out
+// throwaway inboxes
+type DisposableEmailService interface {
+	// IsDisposable reports whether the email belongs to a disposable email provider
+	IsDisposable(email string) bool
+}
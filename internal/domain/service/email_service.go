@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/email"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// JobSendEmail is the job type delivering a rendered email
+const JobSendEmail = "email.send"
+
+// EmailService sends templated emails in the background. Emails are rendered when they are sent,
+// so template errors reach the caller, and delivered by a job, so a slow or failing provider
+// neither delays the request nor loses the email.
+type EmailService struct {
+	sender            email.Sender
+	templates         *email.Templates
+	jobQueue          *JobQueue
+	settings          repository.UserSettingsRepository
+	unsubscribeTokens *UnsubscribeTokenService
+}
+
+// NewEmailService creates a new email service. Register Deliver as the function of JobSendEmail
+// jobs. settings and unsubscribeTokens may be nil when no notifications are sent, e.g. in
+// commands.
+func NewEmailService(sender email.Sender, templates *email.Templates, jobQueue *JobQueue, settings repository.UserSettingsRepository, unsubscribeTokens *UnsubscribeTokenService) *EmailService {
+	return &EmailService{
+		sender:            sender,
+		templates:         templates,
+		jobQueue:          jobQueue,
+		settings:          settings,
+		unsubscribeTokens: unsubscribeTokens,
+	}
+}
+
+// Send renders the email template for the recipient with data, and enqueues its delivery.
+// Within a unit of work, the email is only sent if the transaction commits.
+func (s *EmailService) Send(ctx context.Context, to, template string, data interface{}) error {
+	message, err := s.templates.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+	return s.jobQueue.Enqueue(ctx, JobSendEmail, message)
+}
+
+// SendNotification sends the user an email of a notification category, unless the user opted
+// out of the category. The template gets the link unsubscribing from it as UnsubscribeURL, to
+// show in the footer.
+func (s *EmailService) SendNotification(ctx context.Context, user *entity.User, category entity.NotificationCategory, template string, data map[string]interface{}) error {
+	if s.settings == nil || s.unsubscribeTokens == nil {
+		return fmt.Errorf("email service can't send notifications")
+	}
+
+	settings, err := s.settings.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find user settings: %w", err)
+	}
+	if !settings.EmailNotifications.Enabled(category) {
+		logging.FromContext(ctx).WithField("category", category).Debug("Notification skipped, the user opted out")
+		return nil
+	}
+
+	data["UnsubscribeURL"] = s.unsubscribeTokens.URL(user.ID, category)
+	return s.Send(ctx, user.Email, template, data)
+}
+
+// SendNow renders the email template for the recipient with data, and delivers it right away
+func (s *EmailService) SendNow(ctx context.Context, to, template string, data interface{}) error {
+	message, err := s.templates.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+	return s.Deliver(ctx, message)
+}
+
+// Deliver delivers a rendered email
+func (s *EmailService) Deliver(ctx context.Context, message email.Message) error {
+	if err := s.sender.Send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	logging.FromContext(ctx).WithField("subject", message.Subject).Debug("Email sent")
+	return nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"gin-boilerplate/internal/infrastructure/redis"
+)
+
+// eventSubscriberBuffer is how many events a slow subscriber may fall behind before it is dropped
+const eventSubscriberBuffer = 32
+
+// eventIDPattern matches Redis stream IDs, which event IDs are
+var eventIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// Event is a notification delivered to a user's live connections
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// EventBusConfig configures how much event history is kept for clients that reconnect
+type EventBusConfig struct {
+	// HistorySize is how many recent events are kept per user
+	HistorySize int64
+	// HistoryTTL drops the history of users without new events
+	HistoryTTL time.Duration
+}
+
+// EventBus delivers per-user events across instances. Events are appended to a per-user Redis
+// stream, which keeps recent history for resuming, and announced on a pub/sub channel that every
+// instance listens on to fan them out to its local subscribers.
+type EventBus struct {
+	redisClient *redis.RedisClient
+	config      EventBusConfig
+	prefix      string
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+	pubsub      *goredis.PubSub
+}
+
+// NewEventBus creates a new event bus. Call Start to receive events published by any instance.
+func NewEventBus(redisClient *redis.RedisClient, config EventBusConfig) *EventBus {
+	return &EventBus{
+		redisClient: redisClient,
+		config:      config,
+		prefix:      "gin-boilerplate:events:user:",
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Start listens for published events and dispatches them to local subscribers until Close
+func (b *EventBus) Start(ctx context.Context) error {
+	pubsub := b.redisClient.GetClient().PSubscribe(ctx, b.prefix+"*")
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	b.mu.Lock()
+	b.pubsub = pubsub
+	b.mu.Unlock()
+
+	go b.dispatch(pubsub.Channel())
+	return nil
+}
+
+// Close stops receiving events and disconnects every local subscriber
+func (b *EventBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for userID, subs := range b.subscribers {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(b.subscribers, userID)
+	}
+
+	if b.pubsub == nil {
+		return nil
+	}
+	return b.pubsub.Close()
+}
+
+// Publish sends an event to every live connection of a user and records it for resuming
+func (b *EventBus) Publish(ctx context.Context, userID, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := Event{
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	key := b.prefix + userID
+	client := b.redisClient.GetClient()
+	event.ID, err = client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: key,
+		MaxLen: b.config.HistorySize,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":       event.Type,
+			"data":       string(event.Data),
+			"created_at": event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+
+	if err := client.Expire(ctx, key, b.config.HistoryTTL).Err(); err != nil {
+		// The history still gets trimmed by MaxLen; it just lingers longer
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := client.Publish(ctx, key, message).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe registers a live connection of a user. The channel is closed when the subscriber
+// falls too far behind or the bus closes; the client should then resume from its last event.
+// The returned function unsubscribes.
+func (b *EventBus) Subscribe(userID string) (<-chan Event, func()) {
+	ch := make(chan Event, eventSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+}
+
+// Since returns the recorded events of a user published after lastID, oldest first. Events
+// older than the kept history are gone, so a client away for long may miss some.
+func (b *EventBus) Since(ctx context.Context, userID, lastID string) ([]Event, error) {
+	if !eventIDPattern.MatchString(lastID) {
+		return nil, nil
+	}
+
+	messages, err := b.redisClient.GetClient().XRange(ctx, b.prefix+userID, "("+lastID, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event history: %w", err)
+	}
+
+	events := make([]Event, 0, len(messages))
+	for _, message := range messages {
+		event := Event{ID: message.ID}
+		event.Type, _ = message.Values["type"].(string)
+		if data, ok := message.Values["data"].(string); ok {
+			event.Data = json.RawMessage(data)
+		}
+		if createdAt, ok := message.Values["created_at"].(string); ok {
+			event.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// EventIDAfter reports whether event ID a was published after event ID b
+func EventIDAfter(a, b string) bool {
+	aMs, aSeq := splitEventID(a)
+	bMs, bSeq := splitEventID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+// splitEventID splits a stream ID into its millisecond time and sequence number
+func splitEventID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
+
+// dispatch fans published events out to the local subscribers of their user
+func (b *EventBus) dispatch(messages <-chan *goredis.Message) {
+	for message := range messages {
+		userID := strings.TrimPrefix(message.Channel, b.prefix)
+
+		var event Event
+		if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+			continue
+		}
+
+		b.mu.Lock()
+		for ch := range b.subscribers[userID] {
+			select {
+			case ch <- event:
+			default:
+				// Too far behind; the client reconnects and catches up from the history
+				b.remove(userID, ch)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+// remove unregisters and closes a subscriber channel. The caller must hold b.mu.
+func (b *EventBus) remove(userID string, ch chan Event) {
+	subs, ok := b.subscribers[userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
@@ -0,0 +1,8 @@
+package service
+
+// GeoIPService resolves the country of IP addresses
+type GeoIPService interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the IP, or "" when it isn't
+	// known, e.g. for private addresses
+	Country(ip string) string
+}
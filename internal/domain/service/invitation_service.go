@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// InvitationService issues the invitations to register while registration is invite-only, and
+// redeems them. Like action tokens, only the hashes of their tokens are stored.
+type InvitationService struct {
+	repo repository.InvitationRepository
+	// ttl is how long an invitation can be used
+	ttl time.Duration
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(repo repository.InvitationRepository, ttl time.Duration) *InvitationService {
+	return &InvitationService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// TTL returns how long an invitation can be used
+func (s *InvitationService) TTL() time.Duration {
+	return s.ttl
+}
+
+// Create invites the email to register with the role, and returns the invitation with the
+// plaintext of its token. The email's earlier pending invitations are deleted, so only the
+// latest link works.
+func (s *InvitationService) Create(ctx context.Context, email string, role entity.Role, invitedBy string) (*entity.Invitation, string, error) {
+	if err := s.repo.DeletePendingByEmail(ctx, email); err != nil {
+		return nil, "", err
+	}
+
+	token, err := randomHex(actionTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invitation := entity.NewInvitation(email, role, hashActionToken(token), invitedBy, s.ttl)
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, "", err
+	}
+	return invitation, token, nil
+}
+
+// Find returns the pending invitation of a token. Unknown, accepted and expired invitations all
+// return domain.ErrInvalidInvitation.
+func (s *InvitationService) Find(ctx context.Context, token string) (*entity.Invitation, error) {
+	invitation, err := s.repo.FindByTokenHash(ctx, hashActionToken(token))
+	if errors.Is(err, domain.ErrInvitationNotFound) {
+		return nil, domain.ErrInvalidInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invitation.IsPending() {
+		return nil, domain.ErrInvalidInvitation
+	}
+	return invitation, nil
+}
+
+// FindPendingByEmail returns the latest pending invitation of the email, or nil when there is
+// none
+func (s *InvitationService) FindPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error) {
+	invitation, err := s.repo.FindPendingByEmail(ctx, email)
+	if errors.Is(err, domain.ErrInvitationNotFound) {
+		return nil, nil
+	}
+	return invitation, err
+}
+
+// Accept marks the invitation as used by the user who registered with it. It returns
+// domain.ErrInvalidInvitation when another registration used it first.
+func (s *InvitationService) Accept(ctx context.Context, invitation *entity.Invitation, userID string) error {
+	err := s.repo.Accept(ctx, invitation.ID, userID)
+	if errors.Is(err, domain.ErrInvitationNotFound) {
+		return domain.ErrInvalidInvitation
+	}
+	return err
+}
+
+// List returns a page of invitations, newest first, with the number of invitations
+func (s *InvitationService) List(ctx context.Context, limit, offset int) ([]*entity.Invitation, int64, error) {
+	invitations, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return invitations, total, nil
+}
+
+// Revoke deletes an invitation, so its link stops working. It returns
+// domain.ErrInvitationNotFound when there is no such invitation.
+func (s *InvitationService) Revoke(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxJobRetryBackoff caps the doubling delay between retries of a job
+const maxJobRetryBackoff = time.Hour
+
+// jobLeaseMargin is how long after its timeout a running job is left to its worker before
+// another worker takes it over
+const jobLeaseMargin = time.Minute
+
+// jobPurgeInterval is how often succeeded jobs past their retention are deleted
+const jobPurgeInterval = time.Hour
+
+// errJobAbandoned fails a job whose workers kept dying before it finished
+var errJobAbandoned = errors.New("job was abandoned by its worker too many times")
+
+// jobAttemptKey stores the attempt number of a running job in its context
+type jobAttemptKey struct{}
+
+// JobAttempt returns the attempt number of the job running with ctx, starting at 1, or 0 outside
+// of a job
+func JobAttempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(jobAttemptKey{}).(int)
+	return attempt
+}
+
+// JobFunc runs a job with its JSON payload. An error fails the attempt, which is retried.
+type JobFunc func(ctx context.Context, payload []byte) error
+
+// JSONJobFunc returns a JobFunc decoding the payload into T for fn. A payload that can't be
+// decoded fails every attempt.
+func JSONJobFunc[T any](fn func(ctx context.Context, payload T) error) JobFunc {
+	return func(ctx context.Context, payload []byte) error {
+		var decoded T
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			return fmt.Errorf("failed to decode job payload: %w", err)
+		}
+		return fn(ctx, decoded)
+	}
+}
+
+// JobQueueConfig configures the workers and retry policy of the job queue
+type JobQueueConfig struct {
+	// Workers is how many jobs run at once; 0 only enqueues
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for due jobs again
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job runs before it is dead-lettered
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled for every further one
+	RetryBackoff time.Duration
+	// Timeout bounds a run
+	Timeout time.Duration
+	// Retention is how long succeeded jobs are kept; 0 keeps them
+	Retention time.Duration
+}
+
+// JobObserver is told the outcome and duration of every job run
+type JobObserver func(jobType string, status entity.JobStatus, duration time.Duration)
+
+// JobQueue runs background jobs stored in Postgres. Jobs survive restarts, are retried with
+// exponential backoff, and land in the dead-letter queue (status dead) after their last attempt.
+// Any number of instances can run workers on the same queue.
+type JobQueue struct {
+	jobRepo repository.JobRepository
+	config  JobQueueConfig
+	observe JobObserver
+
+	mu    sync.RWMutex
+	funcs map[string]JobFunc
+}
+
+// NewJobQueue creates a job queue. observe may be nil.
+func NewJobQueue(jobRepo repository.JobRepository, config JobQueueConfig, observe JobObserver) *JobQueue {
+	if observe == nil {
+		observe = func(string, entity.JobStatus, time.Duration) {}
+	}
+	return &JobQueue{
+		jobRepo: jobRepo,
+		config:  config,
+		observe: observe,
+		funcs:   make(map[string]JobFunc),
+	}
+}
+
+// Register sets the function running the jobs of a type. Workers only claim jobs of registered
+// types, so jobs of a type this instance doesn't know wait for one that does.
+func (q *JobQueue) Register(jobType string, fn JobFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.funcs[jobType] = fn
+}
+
+// Enqueue stores a job of jobType with payload encoded as JSON. Within a unit of work, the job is
+// only enqueued if the transaction commits.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	if err := q.jobRepo.Create(ctx, entity.NewJob(jobType, string(encoded), q.config.MaxAttempts)); err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return nil
+}
+
+// Run runs the workers until ctx is cancelled, then waits for the jobs they are running to
+// finish
+func (q *JobQueue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	if q.config.Retention > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.purge(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs due jobs one after the other, polling when there are none
+func (q *JobQueue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		if q.runNext(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// runNext claims and runs the next due job, and reports whether there was one
+func (q *JobQueue) runNext(ctx context.Context) bool {
+	job, err := q.jobRepo.Claim(ctx, q.types(), q.config.Timeout+jobLeaseMargin)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) && ctx.Err() == nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to claim job")
+		}
+		return false
+	}
+
+	// A job that was started is finished even when the workers are stopped meanwhile
+	jobCtx := logging.WithFields(context.WithValue(context.WithoutCancel(ctx), jobAttemptKey{}, job.Attempts), logrus.Fields{
+		"job_id":   job.ID,
+		"job_type": job.Type,
+		"attempt":  job.Attempts,
+	})
+
+	start := time.Now()
+	if job.Attempts > job.MaxAttempts {
+		err = errJobAbandoned
+	} else {
+		err = q.run(jobCtx, job)
+	}
+	duration := time.Since(start)
+
+	logger := logging.FromContext(jobCtx).WithField("duration", duration)
+	if err != nil {
+		job.Fail(err, q.backoff(job.Attempts))
+		if job.IsDead() {
+			logger.WithError(err).Error("Job failed its last attempt and was dead-lettered")
+		} else {
+			logger.WithError(err).WithField("retry_at", job.RunAt).Warn("Job failed, retrying")
+		}
+	} else {
+		job.Succeed()
+		logger.Debug("Job succeeded")
+	}
+
+	if err := q.jobRepo.Update(jobCtx, job); err != nil {
+		// The job is taken over again once its lease runs out
+		logger.WithError(err).Error("Failed to save job outcome")
+	}
+	q.observe(job.Type, job.Status, duration)
+	return true
+}
+
+// run runs a job within its timeout, failing it when the function panics
+func (q *JobQueue) run(ctx context.Context, job *entity.Job) (err error) {
+	q.mu.RLock()
+	fn, ok := q.funcs[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no function registered for job type %s", job.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.config.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx, []byte(job.Payload))
+}
+
+// backoff returns the delay before retrying a job that failed attempts times
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	backoff := q.config.RetryBackoff
+	for i := 1; i < attempts && backoff < maxJobRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxJobRetryBackoff)
+}
+
+// types returns the registered job types
+func (q *JobQueue) types() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	types := make([]string, 0, len(q.funcs))
+	for jobType := range q.funcs {
+		types = append(types, jobType)
+	}
+	return types
+}
+
+// purge deletes the succeeded jobs past their retention on every interval until ctx is cancelled
+func (q *JobQueue) purge(ctx context.Context) {
+	ticker := time.NewTicker(jobPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := q.jobRepo.DeleteSucceededBefore(ctx, time.Now().Add(-q.config.Retention))
+			if err != nil {
+				logging.FromContext(ctx).WithError(err).Error("Failed to purge succeeded jobs")
+				continue
+			}
+			logging.FromContext(ctx).WithField("deleted", deleted).Debug("Purged succeeded jobs")
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted
+const minRSAKeyBits = 2048
+
+// AsymmetricKey is an RSA or Ed25519 key tokens are signed or verified with. Its public key is
+// published as a JSON Web Key, so other services can verify tokens without the HMAC secret.
+type AsymmetricKey struct {
+	// ID names the key in the kid header of tokens and in the JWKS
+	ID     string
+	Method jwt.SigningMethod
+	// Private signs tokens; it is nil for keys that only verify them
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// ParseAsymmetricKey parses a PEM-encoded private key (RSA in PKCS#1 or PKCS#8, Ed25519 in
+// PKCS#8) or public key (PKIX). RSA keys sign with RS256 and Ed25519 keys with EdDSA. The key ID
+// is derived from the public key, so it needs no setting and stays the same when a private key
+// is later configured as a public key.
+func ParseAsymmetricKey(data []byte) (*AsymmetricKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		private crypto.Signer
+		public  crypto.PublicKey
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		private = key
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		private = signer
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		public = key
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if private != nil {
+		public = private.Public()
+	}
+
+	var method jwt.SigningMethod
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &AsymmetricKey{
+		ID:      hex.EncodeToString(sum[:8]),
+		Method:  method,
+		Private: private,
+		Public:  public,
+	}, nil
+}
+
+// JSONWebKey is a public key in the JSON Web Key format (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the set of public keys tokens are verified with, as served at
+// /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWK returns the public key as a JSON Web Key
+func (k *AsymmetricKey) JWK() JSONWebKey {
+	jwk := JSONWebKey{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+	switch key := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	}
+	return jwk
+}
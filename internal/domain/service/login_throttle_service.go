@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// LoginThrottleConfig configures failed-login throttling. A limit of 0 disables that counter.
+type LoginThrottleConfig struct {
+	// MaxAttemptsPerAccount locks an email after that many failures across all IPs
+	MaxAttemptsPerAccount int
+	// MaxAttemptsPerAccountIP caps failures for an email from a single IP
+	MaxAttemptsPerAccountIP int
+	// MaxAttemptsPerIP caps failures from a single IP across all emails
+	MaxAttemptsPerIP int
+	Window           time.Duration
+	// LockoutDuration is how long a locked account stays locked
+	LockoutDuration time.Duration
+}
+
+// LoginThrottleService counts failed logins per account, per account+IP and per IP. An account
+// reaching its limit is locked until the lockout ends or an admin unlocks it, so password
+// guessing against one account is stopped even when it is spread across many IPs, and an IP
+// trying many accounts is slowed.
+type LoginThrottleService struct {
+	cacheService *CacheService
+	config       LoginThrottleConfig
+}
+
+// NewLoginThrottleService creates a new login throttle service
+func NewLoginThrottleService(cacheService *CacheService, config LoginThrottleConfig) *LoginThrottleService {
+	return &LoginThrottleService{
+		cacheService: cacheService,
+		config:       config,
+	}
+}
+
+// Check returns a *domain.AccountLockedError when the account is locked, and a
+// *domain.LoginThrottledError when it may not attempt a login from the IP right now
+func (s *LoginThrottleService) Check(ctx context.Context, email, ip string) error {
+	lockKey := s.lockKey(email)
+	if locked, err := s.cacheService.Exists(ctx, lockKey); err == nil && locked {
+		retryAfter := s.config.LockoutDuration
+		if ttl, err := s.cacheService.TTL(ctx, lockKey); err == nil && ttl > 0 {
+			retryAfter = ttl
+		}
+		return &domain.AccountLockedError{Wait: retryAfter}
+	}
+
+	for _, counter := range s.counters(email, ip) {
+		// The account counter is enforced by the lock it sets
+		if counter.locks || counter.limit <= 0 {
+			continue
+		}
+
+		value, err := s.cacheService.GetString(ctx, counter.key)
+		if err != nil || value == "" {
+			// Leave it to the IP rate limiter when the cache is unavailable
+			continue
+		}
+
+		failures, err := strconv.Atoi(value)
+		if err != nil || failures < counter.limit {
+			continue
+		}
+
+		retryAfter := s.config.Window
+		if ttl, err := s.cacheService.TTL(ctx, counter.key); err == nil && ttl > 0 {
+			retryAfter = ttl
+		}
+		return &domain.LoginThrottledError{Wait: retryAfter}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login attempt, and reports whether it was the one locking the
+// account, or the account from this IP. Failures past the account's limit, made after a lockout
+// ended but within the window, lock it again.
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, email, ip string) (locked bool, err error) {
+	for _, counter := range s.counters(email, ip) {
+		if counter.limit <= 0 {
+			continue
+		}
+		failures, err := s.cacheService.IncrementWithExpiry(ctx, counter.key, s.config.Window)
+		if err != nil {
+			return locked, err
+		}
+		if counter.perAccountIP {
+			// Outlives the counters of the IPs listed, so Unlock finds them all
+			if err := s.cacheService.AddToSetWithExpiry(ctx, s.ipsKey(email), ip, s.config.Window); err != nil {
+				return locked, err
+			}
+		}
+
+		switch {
+		case counter.locks && failures >= int64(counter.limit):
+			if err := s.cacheService.SetWithExpiration(ctx, s.lockKey(email), "1", s.config.LockoutDuration); err != nil {
+				return locked, err
+			}
+			locked = true
+		case counter.reportsLock && failures == int64(counter.limit):
+			locked = true
+		}
+	}
+	return locked, nil
+}
+
+// Reset clears the account's failure counters after a successful login. The IP's counter is
+// kept, so logging in to one account doesn't allow more guesses at others.
+func (s *LoginThrottleService) Reset(ctx context.Context, email, ip string) error {
+	for _, counter := range s.counters(email, ip) {
+		if counter.perIP {
+			continue
+		}
+		if err := s.cacheService.Delete(ctx, counter.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unlock lifts the account's lock and clears its failure counters from every IP. The IPs are
+// read from the set RecordFailure keeps per account, rather than by matching key names, which
+// an email could otherwise widen to other accounts.
+func (s *LoginThrottleService) Unlock(ctx context.Context, email string) error {
+	email = NormalizeEmail(email)
+
+	ips, err := s.cacheService.SetMembers(ctx, s.ipsKey(email))
+	if err != nil {
+		return err
+	}
+	keys := []CacheKey{s.lockKey(email), LoginThrottleCacheKey("account:" + email)}
+	for _, ip := range ips {
+		keys = append(keys, s.accountIPKey(email, ip))
+	}
+	keys = append(keys, s.ipsKey(email))
+
+	for _, key := range keys {
+		if err := s.cacheService.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type loginThrottleCounter struct {
+	key   CacheKey
+	limit int
+	// locks is set on the account counter, which locks the account at its limit
+	locks bool
+	// reportsLock is set on counters whose limit is reported as a lock of the account
+	reportsLock bool
+	// perAccountIP is set on the counter of the account from the IP
+	perAccountIP bool
+	// perIP is set on the counter of the IP across accounts
+	perIP bool
+}
+
+// counters returns the account-wide, account+IP and IP-wide counters for a login attempt
+func (s *LoginThrottleService) counters(email, ip string) []loginThrottleCounter {
+	email = NormalizeEmail(email)
+
+	return []loginThrottleCounter{
+		{key: LoginThrottleCacheKey("account:" + email), limit: s.config.MaxAttemptsPerAccount, locks: true},
+		{key: s.accountIPKey(email, ip), limit: s.config.MaxAttemptsPerAccountIP, reportsLock: true, perAccountIP: true},
+		{key: LoginThrottleCacheKey("ip:" + ip), limit: s.config.MaxAttemptsPerIP, perIP: true},
+	}
+}
+
+// accountIPKey returns the key of the account's failure counter from the IP
+func (s *LoginThrottleService) accountIPKey(email, ip string) CacheKey {
+	return LoginThrottleCacheKey("account_ip:" + NormalizeEmail(email) + "|" + ip)
+}
+
+// ipsKey returns the key of the set of IPs the account failed to log in from
+func (s *LoginThrottleService) ipsKey(email string) CacheKey {
+	return LoginThrottleCacheKey("account_ips:" + NormalizeEmail(email))
+}
+
+// lockKey returns the key marking the account as locked
+func (s *LoginThrottleService) lockKey(email string) CacheKey {
+	return LoginThrottleCacheKey("lock:" + NormalizeEmail(email))
+}
+
+// NormalizeEmail lowercases and trims an email so variants map to the same account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// OAuthState is a sign-in with an OAuth provider waiting for the provider's callback
+type OAuthState struct {
+	// Provider is the name of the provider the sign-in was started with
+	Provider string `json:"provider"`
+	// State is sent to the provider, which sends it back in the callback
+	State string `json:"state"`
+	// CodeVerifier is the PKCE secret whose challenge is sent to the provider; only the server
+	// that started the sign-in can exchange the authorization code
+	CodeVerifier string `json:"code_verifier"`
+	// LinkUserID is the user the provider account is linked to, or "" for a sign-in
+	LinkUserID string `json:"link_user_id,omitempty"`
+}
+
+// OAuthStateService keeps the state of OAuth sign-ins in the cache until their callback. The
+// browser only holds a random nonce, in a cookie, which the state is keyed by, so the callback
+// must come back to the browser that started the sign-in, and each sign-in completes once.
+type OAuthStateService struct {
+	cacheService *CacheService
+	// ttl is how long a user has to get through the consent page of a provider
+	ttl time.Duration
+}
+
+// NewOAuthStateService creates a new OAuth state service
+func NewOAuthStateService(cacheService *CacheService, ttl time.Duration) *OAuthStateService {
+	return &OAuthStateService{
+		cacheService: cacheService,
+		ttl:          ttl,
+	}
+}
+
+// TTL returns how long a sign-in can take, which the nonce cookie lives as long as
+func (s *OAuthStateService) TTL() time.Duration {
+	return s.ttl
+}
+
+// Begin starts a sign-in with the named provider, or links an account at it to the user of
+// linkUserID when not empty. It returns the nonce the browser keeps and the new state.
+func (s *OAuthStateService) Begin(ctx context.Context, provider, linkUserID string) (string, *OAuthState, error) {
+	nonce, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	state, err := randomHex(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// RFC 7636 verifiers are 43 to 128 characters of the unpadded base64url alphabet
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate PKCE code verifier: %w", err)
+	}
+
+	oauthState := &OAuthState{
+		Provider:     provider,
+		State:        state,
+		CodeVerifier: base64.RawURLEncoding.EncodeToString(buf),
+		LinkUserID:   linkUserID,
+	}
+	if err := s.cacheService.Set(ctx, OAuthStateCacheKey(nonce), oauthState, s.ttl); err != nil {
+		return "", nil, fmt.Errorf("failed to store OAuth state: %w", err)
+	}
+	return nonce, oauthState, nil
+}
+
+// Consume returns the state of the sign-in of the nonce and forgets it, so a callback can't be
+// replayed. It returns nil when the nonce is unknown or the sign-in took too long.
+func (s *OAuthStateService) Consume(ctx context.Context, nonce string) (*OAuthState, error) {
+	if nonce == "" {
+		return nil, nil
+	}
+
+	key := OAuthStateCacheKey(nonce)
+	var oauthState OAuthState
+	if err := s.cacheService.Get(ctx, key, &oauthState); err != nil {
+		return nil, fmt.Errorf("failed to read OAuth state: %w", err)
+	}
+	if oauthState.State == "" {
+		return nil, nil
+	}
+	if err := s.cacheService.Delete(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to delete OAuth state: %w", err)
+	}
+	return &oauthState, nil
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// OTP purposes name what a one-time code confirms, keeping their codes apart
+const (
+	// OTPPurposePhone confirms a phone number added to a profile
+	OTPPurposePhone = "phone"
+	// OTPPurposeLogin is the second step of a login with SMS two-factor authentication
+	OTPPurposeLogin = "login"
+	// OTPPurposeAccountDeletion confirms that users with SMS two-factor authentication delete
+	// their account
+	OTPPurposeAccountDeletion = "account_deletion"
+)
+
+// otpResendInterval is how long to wait before another code of the same challenge is sent, so
+// the endpoints can't be used to flood a phone with messages
+const otpResendInterval = time.Minute
+
+// otpDigits is the length of codes
+const otpDigits = 6
+
+// OTPChallenge is a one-time code sent by text message, waiting to be entered
+type OTPChallenge struct {
+	UserID string `json:"user_id"`
+	// Phone is the number the code was sent to
+	Phone string `json:"phone"`
+	// RememberMe is that of the login the code completes
+	RememberMe bool `json:"remember_me,omitempty"`
+	// NewPasswordHash is the hash of the new password of a login that has to change the
+	// password, which is only set once the code is verified
+	NewPasswordHash string `json:"new_password_hash,omitempty"`
+	// CodeHash is the SHA-256 of the code, so the cache doesn't hold usable codes
+	CodeHash string    `json:"code_hash"`
+	Attempts int       `json:"attempts"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// OTPConfig configures one-time codes
+type OTPConfig struct {
+	// TTL is how long a code can be entered
+	TTL time.Duration
+	// MaxAttempts is how many wrong codes end a challenge
+	MaxAttempts int
+}
+
+// OTPService sends one-time codes by text message and checks them. Challenges are kept in the
+// cache until the code is entered, the attempts run out or the code expires.
+type OTPService struct {
+	cacheService *CacheService
+	sms          SMSService
+	config       OTPConfig
+}
+
+// NewOTPService creates a new OTP service
+func NewOTPService(cacheService *CacheService, sms SMSService, config OTPConfig) *OTPService {
+	return &OTPService{
+		cacheService: cacheService,
+		sms:          sms,
+		config:       config,
+	}
+}
+
+// TTL returns how long a code can be entered
+func (s *OTPService) TTL() time.Duration {
+	return s.config.TTL
+}
+
+// Send sends a new code to the phone of the challenge, replacing the code of the challenge of
+// the purpose and id if any. It returns domain.ErrOTPRecentlySent when a code was sent for it
+// less than a minute ago.
+func (s *OTPService) Send(ctx context.Context, purpose, id string, challenge *OTPChallenge) error {
+	key := OTPCacheKey(purpose, id)
+
+	var previous OTPChallenge
+	if err := s.cacheService.Get(ctx, key, &previous); err != nil {
+		return fmt.Errorf("failed to read OTP challenge: %w", err)
+	}
+	if !previous.SentAt.IsZero() && time.Since(previous.SentAt) < otpResendInterval {
+		return domain.ErrOTPRecentlySent
+	}
+
+	code, err := newOTPCode()
+	if err != nil {
+		return err
+	}
+	challenge.CodeHash = hashOTPCode(code)
+	challenge.Attempts = 0
+	challenge.SentAt = time.Now().UTC()
+
+	if err := s.cacheService.Set(ctx, key, challenge, s.config.TTL); err != nil {
+		return fmt.Errorf("failed to store OTP challenge: %w", err)
+	}
+
+	text := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(s.config.TTL.Minutes()))
+	if err := s.sms.Send(ctx, challenge.Phone, text); err != nil {
+		// The code never arrived, so another one can be asked for right away
+		_ = s.cacheService.Delete(ctx, key)
+		return err
+	}
+	return nil
+}
+
+// SendLogin starts the second step of a login: it sends a code to the phone of the challenge,
+// and returns the token the client completes the login with
+func (s *OTPService) SendLogin(ctx context.Context, challenge *OTPChallenge) (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Send(ctx, OTPPurposeLogin, token, challenge); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Verify checks a code against the challenge of the purpose and id, and ends the challenge when
+// the code is right or the attempts run out. It returns domain.ErrInvalidOTP when the code is
+// wrong or there is no such challenge.
+func (s *OTPService) Verify(ctx context.Context, purpose, id, code string) (*OTPChallenge, error) {
+	return s.VerifyFunc(ctx, purpose, id, func(challenge *OTPChallenge) error {
+		if subtle.ConstantTimeCompare([]byte(hashOTPCode(code)), []byte(challenge.CodeHash)) != 1 {
+			return domain.ErrInvalidOTP
+		}
+		return nil
+	})
+}
+
+// VerifyFunc checks the challenge of the purpose and id with check instead of its code, so
+// something else can stand in for the code, and ends the challenge when check accepts it or the
+// attempts run out. check returns nil to accept the challenge. A domain error of KindInvalid is
+// a wrong attempt, counted like a wrong code and returned; other errors are returned as they
+// are. It returns domain.ErrInvalidOTP when there is no such challenge.
+func (s *OTPService) VerifyFunc(ctx context.Context, purpose, id string, check func(challenge *OTPChallenge) error) (*OTPChallenge, error) {
+	key := OTPCacheKey(purpose, id)
+
+	var challenge OTPChallenge
+	if err := s.cacheService.Get(ctx, key, &challenge); err != nil {
+		return nil, fmt.Errorf("failed to read OTP challenge: %w", err)
+	}
+	if challenge.CodeHash == "" {
+		return nil, domain.ErrInvalidOTP
+	}
+
+	checkErr := check(&challenge)
+	if checkErr == nil {
+		if err := s.cacheService.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to delete OTP challenge: %w", err)
+		}
+		return &challenge, nil
+	}
+	if domain.KindOf(checkErr) != domain.KindInvalid {
+		return nil, checkErr
+	}
+
+	challenge.Attempts++
+	if challenge.Attempts >= s.config.MaxAttempts {
+		if err := s.cacheService.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to delete OTP challenge: %w", err)
+		}
+		return nil, checkErr
+	}
+
+	// The challenge keeps the expiry of its code
+	ttl, err := s.cacheService.TTL(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTP challenge expiry: %w", err)
+	}
+	if ttl > 0 {
+		if err := s.cacheService.Set(ctx, key, &challenge, ttl); err != nil {
+			return nil, fmt.Errorf("failed to store OTP challenge: %w", err)
+		}
+	}
+	return nil, checkErr
+}
+
+// newOTPCode returns a random code of otpDigits digits
+func newOTPCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(math.Pow10(otpDigits))))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate OTP code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", otpDigits, n), nil
+}
+
+// hashOTPCode returns the SHA-256 of a code, hex encoded
+func hashOTPCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// PasswordHistoryService keeps users from setting one of their recent passwords again. The
+// replaced passwords are kept as hashes, and a new password is checked against each of them.
+type PasswordHistoryService struct {
+	repo            repository.PreviousPasswordRepository
+	passwordService PasswordService
+	// size is how many of the latest passwords, the current one included, can't be set again;
+	// 0 allows any
+	size int
+}
+
+// NewPasswordHistoryService creates a new password history service
+func NewPasswordHistoryService(repo repository.PreviousPasswordRepository, passwordService PasswordService, size int) *PasswordHistoryService {
+	return &PasswordHistoryService{
+		repo:            repo,
+		passwordService: passwordService,
+		size:            size,
+	}
+}
+
+// CheckReuse returns domain.ErrPasswordReused when password is the user's current password or
+// one of the passwords it replaced recently
+func (s *PasswordHistoryService) CheckReuse(ctx context.Context, user *entity.User, password string) error {
+	if s.size <= 0 {
+		return nil
+	}
+
+	if user.HasPassword() && s.passwordService.VerifyPassword(password, *user.Password) == nil {
+		return domain.ErrPasswordReused
+	}
+	if s.size == 1 {
+		return nil
+	}
+
+	previous, err := s.repo.ListRecent(ctx, user.ID, s.size-1)
+	if err != nil {
+		return fmt.Errorf("failed to list previous passwords: %w", err)
+	}
+	for _, p := range previous {
+		if s.passwordService.VerifyPassword(password, p.PasswordHash) == nil {
+			return domain.ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Remember keeps the user's current password before it is replaced, and forgets the replaced
+// passwords that no longer count
+func (s *PasswordHistoryService) Remember(ctx context.Context, user *entity.User) error {
+	if s.size <= 1 || !user.HasPassword() {
+		return nil
+	}
+
+	if err := s.repo.Create(ctx, entity.NewPreviousPassword(user.ID, *user.Password)); err != nil {
+		return fmt.Errorf("failed to store previous password: %w", err)
+	}
+	if err := s.repo.Prune(ctx, user.ID, s.size-1); err != nil {
+		return fmt.Errorf("failed to prune previous passwords: %w", err)
+	}
+	return nil
+}
@@ -1,45 +1,119 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
+	"math/big"
+	"strings"
 
+	"gin-boilerplate/internal/domain"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Password hashing algorithms
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+// argon2id salt and key sizes, as recommended by RFC 9106
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// argon2idPrefix starts argon2id hashes in the PHC string format
+const argon2idPrefix = "$argon2id$"
+
+// temporaryPasswordAlphabet leaves out characters easily mistaken for others (0, 1, I, O, i, l
+// and o), since temporary passwords are passed on to the user
+const temporaryPasswordAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghjkmnpqrstuvwxyz"
+
+// temporaryPasswordLength is the number of characters of a temporary password
+const temporaryPasswordLength = 16
+
 // PasswordService handles password-related operations
 type PasswordService interface {
-	// HashPassword hashes a password using bcrypt
+	// HashPassword hashes a password with the configured algorithm
 	HashPassword(password string) (string, error)
 
-	// VerifyPassword verifies a password against its hash
+	// VerifyPassword verifies a password against its hash, whichever supported algorithm made it
 	VerifyPassword(password, hash string) error
 
+	// NeedsRehash reports whether a hash was made with another algorithm or parameters than the
+	// configured ones, so it should be replaced once the password is known
+	NeedsRehash(hash string) bool
+
 	// ValidatePassword validates password strength
 	ValidatePassword(password string) error
 }
 
+// PasswordHashConfig represents the algorithm and parameters new password hashes are made with
+type PasswordHashConfig struct {
+	// Algorithm is PasswordHashBcrypt or PasswordHashArgon2id
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory is in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// argon2Params are the parameters of an argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
 type passwordService struct {
-	cost int
+	algorithm string
+	cost      int
+	argon2    argon2Params
 }
 
 // NewPasswordService creates a new password service
 func NewPasswordService() PasswordService {
 	return &passwordService{
-		cost: bcrypt.DefaultCost, // Can be configured
+		algorithm: PasswordHashBcrypt,
+		cost:      bcrypt.DefaultCost,
 	}
 }
 
 // NewPasswordServiceWithCost creates a new password service with custom cost
 func NewPasswordServiceWithCost(cost int) PasswordService {
 	return &passwordService{
-		cost: cost,
+		algorithm: PasswordHashBcrypt,
+		cost:      cost,
+	}
+}
+
+// NewPasswordServiceWithConfig creates a new password service hashing with the given algorithm
+// and parameters. Hashes of the other algorithm are still verified.
+func NewPasswordServiceWithConfig(config PasswordHashConfig) PasswordService {
+	return &passwordService{
+		algorithm: config.Algorithm,
+		cost:      config.BcryptCost,
+		argon2: argon2Params{
+			memory:      config.Argon2Memory,
+			iterations:  config.Argon2Iterations,
+			parallelism: config.Argon2Parallelism,
+		},
 	}
 }
 
-// HashPassword hashes a password using bcrypt
+// HashPassword hashes a password with the configured algorithm
 func (s *passwordService) HashPassword(password string) (string, error) {
 	if err := s.ValidatePassword(password); err != nil {
-		return "", fmt.Errorf("password validation failed: %w", err)
+		return "", err
+	}
+
+	if s.algorithm == PasswordHashArgon2id {
+		return s.hashArgon2id(password)
 	}
 
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
@@ -50,19 +124,99 @@ func (s *passwordService) HashPassword(password string) (string, error) {
 	return string(hashedBytes), nil
 }
 
-// VerifyPassword verifies a password against its hash
+// hashArgon2id hashes a password with argon2id in the PHC string format,
+// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>"
+func (s *passwordService) hashArgon2id(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.argon2.iterations, s.argon2.memory, s.argon2.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version,
+		s.argon2.memory, s.argon2.iterations, s.argon2.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword verifies a password against its hash, whichever supported algorithm made it
 func (s *passwordService) VerifyPassword(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	}
+
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return fmt.Errorf("password does not match")
+	}
+	return nil
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or parameters than the
+// configured ones
+func (s *passwordService) NeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		if s.algorithm != PasswordHashArgon2id {
+			return true
+		}
+		params, _, _, err := parseArgon2id(hash)
+		return err != nil || params != s.argon2
+	}
+
+	if s.algorithm != PasswordHashBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != s.cost
+}
+
+// parseArgon2id reads the parameters, salt and key of an argon2id PHC string
+func parseArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("malformed argon2id key")
+	}
+
+	return params, salt, key, nil
 }
 
 // ValidatePassword validates password strength
 func (s *passwordService) ValidatePassword(password string) error {
 	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
+		return domain.ErrValidation.WithMessage("Password must be at least 8 characters long")
 	}
 
 	if len(password) > 128 {
-		return fmt.Errorf("password must be less than 128 characters long")
+		return domain.ErrValidation.WithMessage("Password must be less than 128 characters long")
 	}
 
 	// Can add more validation rules here
@@ -72,4 +226,19 @@ func (s *passwordService) ValidatePassword(password string) error {
 	// - At least one special character
 
 	return nil
-}
\ No newline at end of file
+}
+
+// GenerateTemporaryPassword returns a random password, such as 7hKm2xQpa9RtzW4c, for a user to
+// replace on its first login
+func GenerateTemporaryPassword() (string, error) {
+	max := big.NewInt(int64(len(temporaryPasswordAlphabet)))
+	b := make([]byte, temporaryPasswordLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate temporary password: %w", err)
+		}
+		b[i] = temporaryPasswordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// policyCacheTTL bounds how long the permissions of a role stay cached. Changes made through
+// the role endpoints invalidate them at once.
+const policyCacheTTL = 5 * time.Minute
+
+// PolicyService resolves the permissions of roles. The USER, MODERATOR and ADMIN roles have
+// fixed permissions; those of custom roles are stored and cached in Redis.
+type PolicyService struct {
+	cacheService *CacheService
+	roleRepo     repository.RoleRepository
+}
+
+// NewPolicyService creates a new policy service
+func NewPolicyService(cacheService *CacheService, roleRepo repository.RoleRepository) *PolicyService {
+	return &PolicyService{
+		cacheService: cacheService,
+		roleRepo:     roleRepo,
+	}
+}
+
+// Permissions returns the permissions of a role. Unknown roles, e.g. a custom role deleted while
+// users still had tokens carrying it, have none.
+func (s *PolicyService) Permissions(ctx context.Context, role entity.Role) ([]entity.Permission, error) {
+	if permissions, ok := entity.BuiltinPermissions(role); ok {
+		return permissions, nil
+	}
+
+	cacheKey := PolicyCacheKey(string(role))
+	var permissions []entity.Permission
+	if err := s.cacheService.Get(ctx, cacheKey, &permissions); err == nil && permissions != nil {
+		return permissions, nil
+	}
+
+	permissions = []entity.Permission{}
+	customRole, err := s.roleRepo.FindByName(ctx, role)
+	if err != nil && !errors.Is(err, domain.ErrRoleNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		permissions = customRole.PermissionList()
+	}
+
+	s.cacheService.Set(ctx, cacheKey, permissions, policyCacheTTL)
+	return permissions, nil
+}
+
+// Can reports whether a role has a permission
+func (s *PolicyService) Can(ctx context.Context, role entity.Role, permission entity.Permission) (bool, error) {
+	permissions, err := s.Permissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+// HasAll reports whether a role has every one of the permissions
+func (s *PolicyService) HasAll(ctx context.Context, role entity.Role, permissions []entity.Permission) (bool, error) {
+	granted, err := s.Permissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CanGrant reports whether users with actorRole can give a user the role, or take it away: the
+// role can't allow more than actorRole does. ADMIN is granted only by admins, as some endpoints
+// are open to the ADMIN role rather than to a permission.
+func (s *PolicyService) CanGrant(ctx context.Context, actorRole, role entity.Role) (bool, error) {
+	if role == entity.RoleAdmin {
+		return actorRole == entity.RoleAdmin, nil
+	}
+
+	permissions, err := s.Permissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	return s.HasAll(ctx, actorRole, permissions)
+}
+
+// Invalidate drops the cached permissions of a role after it changed
+func (s *PolicyService) Invalidate(ctx context.Context, role entity.Role) error {
+	return s.cacheService.Delete(ctx, PolicyCacheKey(string(role)))
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// quotaRetention keeps counters around after their period ends so the final value can be rolled up
+const quotaRetention = 48 * time.Hour
+
+// quotaOverrideCacheTTL bounds how long a changed override takes to apply on other instances
+const quotaOverrideCacheTTL = time.Minute
+
+// QuotaLimits holds the default limit per metric. A limit of 0 means unlimited.
+type QuotaLimits map[entity.QuotaMetric]int64
+
+// QuotaStatus describes the current usage of a metric for a user
+type QuotaStatus struct {
+	Metric     entity.QuotaMetric `json:"metric"`
+	Limit      int64              `json:"limit"`
+	Used       int64              `json:"used"`
+	Period     string             `json:"period"`
+	ResetsAt   time.Time          `json:"resets_at"`
+	Overridden bool               `json:"overridden"`
+}
+
+// QuotaService tracks long-window usage counters in Redis and rolls them up into Postgres
+type QuotaService struct {
+	cacheService *CacheService
+	quotaRepo    repository.QuotaRepository
+	limits       QuotaLimits
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(cacheService *CacheService, quotaRepo repository.QuotaRepository, limits QuotaLimits) *QuotaService {
+	return &QuotaService{
+		cacheService: cacheService,
+		quotaRepo:    quotaRepo,
+		limits:       limits,
+	}
+}
+
+// Consume adds amount to the user's usage of a metric. It returns a *domain.QuotaExceededError
+// when the new total would exceed the limit, in which case the usage is not recorded.
+func (s *QuotaService) Consume(ctx context.Context, userID string, metric entity.QuotaMetric, amount int64) (*QuotaStatus, error) {
+	limit, overridden, err := s.limit(ctx, userID, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	period := metric.Period(now)
+	resetsAt := metric.PeriodEnd(now)
+	key := QuotaCacheKey(string(metric), period, userID)
+
+	used, err := s.cacheService.IncrementByWithExpiry(ctx, key, amount, time.Until(resetsAt)+quotaRetention)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	// A fresh counter may have been lost (eviction, restart); seed it from the last rollup
+	if used == amount {
+		rollup, err := s.quotaRepo.FindRollup(ctx, userID, metric, period)
+		if err == nil && rollup.Value > 0 {
+			if used, err = s.cacheService.IncrementByWithExpiry(ctx, key, rollup.Value, time.Until(resetsAt)+quotaRetention); err != nil {
+				return nil, fmt.Errorf("failed to record usage: %w", err)
+			}
+		}
+	}
+
+	status := &QuotaStatus{
+		Metric:     metric,
+		Limit:      limit,
+		Used:       used,
+		Period:     period,
+		ResetsAt:   resetsAt,
+		Overridden: overridden,
+	}
+
+	if limit > 0 && used > limit {
+		if _, err := s.cacheService.DecrementBy(ctx, key, amount); err == nil {
+			status.Used = used - amount
+		}
+		return status, &domain.QuotaExceededError{
+			Metric:   string(metric),
+			Limit:    limit,
+			Used:     status.Used,
+			ResetsAt: resetsAt,
+		}
+	}
+
+	return status, nil
+}
+
+// Refund takes amount back off the user's usage of a metric in the period it was consumed in,
+// for work that was counted but didn't happen
+func (s *QuotaService) Refund(ctx context.Context, userID string, metric entity.QuotaMetric, period string, amount int64) error {
+	key := QuotaCacheKey(string(metric), period, userID)
+	resetsAt := metric.PeriodEnd(time.Now())
+
+	used, err := s.cacheService.IncrementByWithExpiry(ctx, key, -amount, time.Until(resetsAt)+quotaRetention)
+	if err != nil {
+		return fmt.Errorf("failed to refund usage: %w", err)
+	}
+
+	// The counter was reset or lost since the usage was recorded
+	if used < 0 {
+		if err := s.cacheService.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to refund usage: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Usage returns the current usage of every metric for a user
+func (s *QuotaService) Usage(ctx context.Context, userID string) ([]QuotaStatus, error) {
+	now := time.Now()
+	statuses := make([]QuotaStatus, 0, len(entity.QuotaMetrics))
+
+	for _, metric := range entity.QuotaMetrics {
+		limit, overridden, err := s.limit(ctx, userID, metric)
+		if err != nil {
+			return nil, err
+		}
+
+		period := metric.Period(now)
+		used, err := s.used(ctx, userID, metric, period)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, QuotaStatus{
+			Metric:     metric,
+			Limit:      limit,
+			Used:       used,
+			Period:     period,
+			ResetsAt:   metric.PeriodEnd(now),
+			Overridden: overridden,
+		})
+	}
+
+	return statuses, nil
+}
+
+// ResetUsage clears the user's usage of a metric for the current period
+func (s *QuotaService) ResetUsage(ctx context.Context, userID string, metric entity.QuotaMetric) error {
+	period := metric.Period(time.Now())
+
+	if err := s.cacheService.Delete(ctx, QuotaCacheKey(string(metric), period, userID)); err != nil {
+		return fmt.Errorf("failed to reset usage counter: %w", err)
+	}
+
+	if err := s.quotaRepo.UpsertRollup(ctx, entity.NewUsageRollup(userID, metric, period, 0)); err != nil {
+		return fmt.Errorf("failed to reset usage rollup: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateOverride drops the cached override so a changed limit applies immediately
+func (s *QuotaService) InvalidateOverride(ctx context.Context, userID string, metric entity.QuotaMetric) error {
+	return s.cacheService.Delete(ctx, quotaOverrideCacheKey(userID, metric))
+}
+
+// DefaultLimit returns the configured limit of a metric
+func (s *QuotaService) DefaultLimit(metric entity.QuotaMetric) int64 {
+	return s.limits[metric]
+}
+
+// Rollup persists all Redis counters into Postgres
+func (s *QuotaService) Rollup(ctx context.Context) error {
+	ids, err := s.cacheService.ScanNamespace(ctx, "quota")
+	if err != nil {
+		return fmt.Errorf("failed to scan usage counters: %w", err)
+	}
+
+	for _, id := range ids {
+		// IDs have the form metric:period:userID
+		parts := strings.SplitN(id, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		metric := entity.QuotaMetric(parts[0])
+		if !metric.IsValid() {
+			continue
+		}
+
+		value, err := s.used(ctx, parts[2], metric, parts[1])
+		if err != nil {
+			return err
+		}
+
+		if err := s.quotaRepo.UpsertRollup(ctx, entity.NewUsageRollup(parts[2], metric, parts[1], value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// used reads the usage of a metric for a period, falling back to the rollup when Redis has no counter
+func (s *QuotaService) used(ctx context.Context, userID string, metric entity.QuotaMetric, period string) (int64, error) {
+	value, err := s.cacheService.GetString(ctx, QuotaCacheKey(string(metric), period, userID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read usage counter: %w", err)
+	}
+
+	if value != "" {
+		used, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid usage counter: %w", err)
+		}
+		return used, nil
+	}
+
+	rollup, err := s.quotaRepo.FindRollup(ctx, userID, metric, period)
+	if errors.Is(err, domain.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rollup.Value, nil
+}
+
+// limit resolves the effective limit of a metric for a user
+func (s *QuotaService) limit(ctx context.Context, userID string, metric entity.QuotaMetric) (int64, bool, error) {
+	cacheKey := quotaOverrideCacheKey(userID, metric)
+
+	// Cached value is the override limit, or "none" when the user has no override
+	cached, err := s.cacheService.GetString(ctx, cacheKey)
+	if err == nil && cached != "" {
+		if cached == "none" {
+			return s.limits[metric], false, nil
+		}
+		if limit, err := strconv.ParseInt(cached, 10, 64); err == nil {
+			return limit, true, nil
+		}
+	}
+
+	override, err := s.quotaRepo.FindOverride(ctx, userID, metric)
+	if errors.Is(err, domain.ErrNotFound) {
+		s.cacheService.SetWithExpiration(ctx, cacheKey, "none", quotaOverrideCacheTTL)
+		return s.limits[metric], false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.cacheService.SetWithExpiration(ctx, cacheKey, strconv.FormatInt(override.Limit, 10), quotaOverrideCacheTTL)
+	return override.Limit, true, nil
+}
+
+func quotaOverrideCacheKey(userID string, metric entity.QuotaMetric) CacheKey {
+	return CacheKey{Namespace: "quota_override", ID: string(metric) + ":" + userID}
+}
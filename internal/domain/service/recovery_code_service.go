@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+)
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time
+const RecoveryCodeCount = 10
+
+// recoveryCodeAlphabet leaves out characters easily mistaken for others (0, 1, i, l and o)
+const recoveryCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// recoveryCodeLength is the number of characters of a code, shown in two halves
+const recoveryCodeLength = 10
+
+// RecoveryCodeService hands out the single-use recovery codes of users with two-factor
+// authentication, and checks them in place of the second factor. Only their hashes are stored,
+// so codes are shown once, when they are generated.
+type RecoveryCodeService struct {
+	repo repository.RecoveryCodeRepository
+}
+
+// NewRecoveryCodeService creates a new recovery code service
+func NewRecoveryCodeService(repo repository.RecoveryCodeRepository) *RecoveryCodeService {
+	return &RecoveryCodeService{
+		repo: repo,
+	}
+}
+
+// Generate replaces the user's recovery codes with RecoveryCodeCount new ones, and returns them
+func (s *RecoveryCodeService) Generate(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]*entity.RecoveryCode, 0, RecoveryCodeCount)
+	for len(codes) < RecoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, entity.NewRecoveryCode(userID, hashRecoveryCode(code)))
+	}
+
+	if err := s.repo.Replace(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Use uses up one of the user's recovery codes. It returns domain.ErrInvalidRecoveryCode when
+// the code isn't one of them or was already used.
+func (s *RecoveryCodeService) Use(ctx context.Context, userID, code string) error {
+	err := s.repo.Use(ctx, userID, hashRecoveryCode(code))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrInvalidRecoveryCode
+	}
+	return err
+}
+
+// Remaining returns how many of the user's recovery codes are left
+func (s *RecoveryCodeService) Remaining(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountUnused(ctx, userID)
+}
+
+// Delete deletes all the user's recovery codes
+func (s *RecoveryCodeService) Delete(ctx context.Context, userID string) error {
+	return s.repo.DeleteByUserID(ctx, userID)
+}
+
+// newRecoveryCode returns a random code, such as 7hk2m-x9qpa
+func newRecoveryCode() (string, error) {
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	var b strings.Builder
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// hashRecoveryCode returns the SHA-256 of a code, hex encoded. Codes are compared without case,
+// dashes and spaces, so they can be typed as read.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
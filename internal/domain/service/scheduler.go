@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/redis"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// schedulerLeaderKey holds the ID of the instance that runs scheduled tasks
+const schedulerLeaderKey = "gin-boilerplate:scheduler:leader"
+
+// schedulerReleaseTimeout bounds handing over the leadership on shutdown
+const schedulerReleaseTimeout = 5 * time.Second
+
+// renewLeaderScript extends the leadership, but only while this instance still holds it
+var renewLeaderScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaderScript gives up the leadership, but only while this instance still holds it
+var releaseLeaderScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ScheduledTaskFunc runs a scheduled task once
+type ScheduledTaskFunc func(ctx context.Context) error
+
+// ScheduledTaskObserver is told the outcome and duration of every scheduled task run
+type ScheduledTaskObserver func(task string, err error, duration time.Duration)
+
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	fn       ScheduledTaskFunc
+}
+
+// Scheduler runs recurring maintenance tasks on one instance at a time. Instances elect a leader
+// with a Redis key that expires unless the leader renews it, and only the leader runs tasks, so a
+// task doesn't run once per instance. When the leader stops or loses Redis, another instance
+// takes over within the leader TTL. A task may run twice around a handover, so tasks must be
+// safe to repeat.
+type Scheduler struct {
+	redisClient *redis.RedisClient
+	leaderTTL   time.Duration
+	observe     ScheduledTaskObserver
+	instanceID  string
+	leader      atomic.Bool
+	tasks       []scheduledTask
+}
+
+// NewScheduler creates a scheduler. observe may be nil.
+func NewScheduler(redisClient *redis.RedisClient, leaderTTL time.Duration, observe ScheduledTaskObserver) *Scheduler {
+	if observe == nil {
+		observe = func(string, error, time.Duration) {}
+	}
+	return &Scheduler{
+		redisClient: redisClient,
+		leaderTTL:   leaderTTL,
+		observe:     observe,
+		instanceID:  uuid.New().String(),
+	}
+}
+
+// Every runs fn on every interval while this instance is the leader. A task with an interval of 0
+// is disabled. Tasks must be added before Run.
+func (s *Scheduler) Every(name string, interval time.Duration, fn ScheduledTaskFunc) {
+	if interval <= 0 {
+		return
+	}
+	s.tasks = append(s.tasks, scheduledTask{name: name, interval: interval, fn: fn})
+}
+
+// IsLeader reports whether this instance currently runs the scheduled tasks
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// Run takes part in the leader election and runs the tasks until ctx is cancelled, then hands
+// over the leadership
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.tasks) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.elect(ctx)
+	}()
+	for _, task := range s.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.schedule(ctx, task)
+		}()
+	}
+	wg.Wait()
+
+	s.release(context.WithoutCancel(ctx))
+}
+
+// elect acquires or renews the leadership three times per TTL, so a slow renewal doesn't let it
+// expire
+func (s *Scheduler) elect(ctx context.Context) {
+	ticker := time.NewTicker(s.leaderTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		s.campaign(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign renews the leadership if this instance holds it, and tries to acquire it otherwise
+func (s *Scheduler) campaign(ctx context.Context) {
+	client := s.redisClient.GetClient()
+
+	var leader bool
+	var err error
+	if s.leader.Load() {
+		var renewed int
+		renewed, err = renewLeaderScript.Run(ctx, client, []string{schedulerLeaderKey}, s.instanceID, s.leaderTTL.Milliseconds()).Int()
+		leader = err == nil && renewed == 1
+	} else {
+		leader, err = s.redisClient.SetNX(ctx, schedulerLeaderKey, s.instanceID, s.leaderTTL)
+	}
+	if err != nil && ctx.Err() == nil {
+		// Without Redis the leadership can't be renewed, so another instance may take over
+		logging.FromContext(ctx).WithError(err).Warn("Failed to renew scheduler leadership")
+	}
+
+	if s.leader.Swap(leader) != leader {
+		if leader {
+			logging.FromContext(ctx).WithField("instance_id", s.instanceID).Info("Became scheduler leader")
+		} else {
+			logging.FromContext(ctx).WithField("instance_id", s.instanceID).Info("Lost scheduler leadership")
+		}
+	}
+}
+
+// release gives up the leadership, so another instance takes over without waiting for the TTL
+func (s *Scheduler) release(ctx context.Context) {
+	if !s.leader.Swap(false) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, schedulerReleaseTimeout)
+	defer cancel()
+	if err := releaseLeaderScript.Run(ctx, s.redisClient.GetClient(), []string{schedulerLeaderKey}, s.instanceID).Err(); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to release scheduler leadership")
+	}
+}
+
+// schedule runs a task on every interval while this instance is the leader, until ctx is
+// cancelled
+func (s *Scheduler) schedule(ctx context.Context, task scheduledTask) {
+	ticker := time.NewTicker(task.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.leader.Load() {
+				s.run(ctx, task)
+			}
+		}
+	}
+}
+
+// run runs a task once, failing it when the function panics
+func (s *Scheduler) run(ctx context.Context, task scheduledTask) {
+	ctx = logging.WithFields(ctx, logrus.Fields{"task": task.name})
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("task panicked: %v", r)
+			}
+		}()
+		return task.fn(ctx)
+	}()
+	duration := time.Since(start)
+
+	if err != nil && ctx.Err() == nil {
+		logging.FromContext(ctx).WithError(err).Error("Scheduled task failed")
+	}
+	s.observe(task.name, err, duration)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// SearchHit is a document found by a SearchIndexer
+type SearchHit struct {
+	DocumentID string
+	// Rank is how well the document matches, higher first
+	Rank float64
+	// TitleHighlight and Snippet are highlighted like those of repository.DocumentSearchResult
+	TitleHighlight string
+	Snippet        string
+}
+
+// SearchIndexer keeps the documents in an external search engine, which then answers document
+// searches in place of Postgres
+type SearchIndexer interface {
+	// EnsureIndex creates the index when it doesn't exist yet
+	EnsureIndex(ctx context.Context) error
+	// Index adds the document to the index, or replaces it
+	Index(ctx context.Context, document *entity.Document) error
+	// Delete removes a document from the index. Deleting one that isn't indexed succeeds.
+	Delete(ctx context.Context, documentID string) error
+	// Search returns a page of a user's documents matching the query, in web search syntax
+	// ("quoted phrases", or, -excluded words), the best matches first, and how many match. It
+	// returns domain.ErrSearchUnavailable when the search engine can't be reached.
+	Search(ctx context.Context, userID, query string, limit, offset int) ([]SearchHit, int64, error)
+}
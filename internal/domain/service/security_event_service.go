@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// securityAlertTemplate is the email template telling a user about a security event
+const securityAlertTemplate = "security_alert"
+
+// securityEventMessages are the titles and summaries of the alerts of each event type
+var securityEventMessages = map[entity.SecurityEventType]struct{ title, summary string }{
+	entity.SecurityEventNewDeviceLogin: {
+		"New sign-in to your account",
+		"Your account was signed in to from a device it hasn't been used from before.",
+	},
+	entity.SecurityEventNewCountryLogin: {
+		"New sign-in to your account from another country",
+		"Your account was signed in to from a country it hasn't been used from before.",
+	},
+	entity.SecurityEventPasswordChanged: {
+		"Your password was changed",
+		"The password of your account was changed, and its sessions were signed out.",
+	},
+	entity.SecurityEventEmailChanged: {
+		"Your email address was changed",
+		"The email address of your account was changed.",
+	},
+	entity.SecurityEventProviderLinked: {
+		"A sign-in method was added",
+		"A new way to sign in was added to your account.",
+	},
+	entity.SecurityEventProviderUnlinked: {
+		"A sign-in method was removed",
+		"A way to sign in was removed from your account.",
+	},
+	entity.SecurityEventTokenReuse: {
+		"Your sessions were signed out",
+		"A sign-in token of your account was used again after it had been replaced, which happens when a token is stolen. Every session of your account was signed out.",
+	},
+	entity.SecurityEventAccountLocked: {
+		"Sign-ins to your account were paused",
+		"Sign-ins to your account were paused for a while after too many failed attempts.",
+	},
+	entity.SecurityEventAdminRoleGranted: {
+		"You were made an administrator",
+		"Your account was given the admin role.",
+	},
+	entity.SecurityEventPhoneChanged: {
+		"Your phone number was changed",
+		"The phone number of your account was changed.",
+	},
+	entity.SecurityEventTwoFactorEnabled: {
+		"Two-factor authentication was turned on",
+		"Signing in to your account now needs a code sent to your phone.",
+	},
+	entity.SecurityEventTwoFactorDisabled: {
+		"Two-factor authentication was turned off",
+		"Signing in to your account no longer needs a code sent to your phone.",
+	},
+	entity.SecurityEventRecoveryCodeUsed: {
+		"A recovery code was used to sign in",
+		"Someone signed in to your account with one of its recovery codes instead of a code sent to your phone.",
+	},
+	entity.SecurityEventRecoveryCodesRegenerated: {
+		"New recovery codes were generated",
+		"New recovery codes were generated for your account, and the previous ones no longer work.",
+	},
+}
+
+// SecurityEventConfig configures the alerts of security events
+type SecurityEventConfig struct {
+	// EmailAlerts emails the affected user about each event
+	EmailAlerts bool
+}
+
+// SecurityEventObserver is told about each security event, e.g. to count them in a metric
+type SecurityEventObserver func(eventType entity.SecurityEventType)
+
+// SecurityEventService records security events and alerts the affected user by email and on
+// the event stream, so failures that used to pass silently are seen by the user and by admins.
+// Recording is best-effort: failures are logged and never fail the operation that caused the
+// event.
+type SecurityEventService struct {
+	repo         repository.SecurityEventRepository
+	eventBus     *EventBus
+	emailService *EmailService
+	geoIP        GeoIPService
+	activities   *ActivityService
+	settings     repository.UserSettingsRepository
+	config       SecurityEventConfig
+	observe      SecurityEventObserver
+}
+
+// NewSecurityEventService creates a new security event service. eventBus and emailService may be
+// nil, e.g. in commands without Redis, to skip those alerts. geoIP may be nil to skip reporting
+// logins from new countries. activities may be nil to leave logins from new devices out of the
+// activity feeds. settings may be nil to give the times of alerts in UTC.
+func NewSecurityEventService(
+	repo repository.SecurityEventRepository,
+	eventBus *EventBus,
+	emailService *EmailService,
+	geoIP GeoIPService,
+	activities *ActivityService,
+	settings repository.UserSettingsRepository,
+	config SecurityEventConfig,
+	observe SecurityEventObserver,
+) *SecurityEventService {
+	if observe == nil {
+		observe = func(entity.SecurityEventType) {}
+	}
+	return &SecurityEventService{
+		repo:         repo,
+		eventBus:     eventBus,
+		emailService: emailService,
+		geoIP:        geoIP,
+		activities:   activities,
+		settings:     settings,
+		config:       config,
+		observe:      observe,
+	}
+}
+
+// Record records a security event of the user and alerts the user. ip and userAgent are those
+// of the request causing it, if any. Users who turned security alerts off in their settings get
+// no email.
+func (s *SecurityEventService) Record(ctx context.Context, user *entity.User, eventType entity.SecurityEventType, ip, userAgent string, details map[string]string) {
+	// The alerts go out even when the request that caused the event is cancelled
+	ctx = context.WithoutCancel(ctx)
+	event := entity.NewSecurityEvent(user.ID, eventType, ip, userAgent, details)
+
+	logger := logging.ModuleFromContext(ctx, logging.ModuleAuth).WithFields(logrus.Fields{
+		"security_event": eventType,
+		"target_user_id": user.ID,
+		"ip":             ip,
+	})
+	logger.Warn("Security event")
+	s.observe(eventType)
+
+	if err := s.repo.Create(ctx, event); err != nil {
+		logger.WithError(err).Error("Failed to record security event")
+	}
+
+	if s.eventBus != nil {
+		if err := s.eventBus.Publish(ctx, user.ID, "security."+string(eventType), event); err != nil {
+			logger.WithError(err).Warn("Failed to publish security event")
+		}
+	}
+
+	if s.emailService != nil && s.config.EmailAlerts {
+		s.sendAlert(ctx, logger, user, eventType, ip, userAgent, details)
+	}
+}
+
+// sendAlert emails the user about a security event, unless it turned security alerts off. The
+// time is in the user's time zone.
+func (s *SecurityEventService) sendAlert(ctx context.Context, logger *logrus.Entry, user *entity.User, eventType entity.SecurityEventType, ip, userAgent string, details map[string]string) {
+	location := time.UTC
+	if s.settings != nil {
+		settings, err := s.settings.FindByUserID(ctx, user.ID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to find user settings")
+		} else {
+			location = settings.Location()
+		}
+	}
+
+	message := securityEventMessages[eventType]
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"Title":     message.title,
+		"Summary":   message.summary,
+		"Time":      time.Now().In(location).Format(time.RFC1123),
+		"IP":        ip,
+		"UserAgent": userAgent,
+		"Country":   details["country"],
+	}
+	if err := s.emailService.SendNotification(ctx, user, entity.NotificationSecurityAlerts, securityAlertTemplate, data); err != nil {
+		logger.WithError(err).Warn("Failed to send security alert email")
+	}
+}
+
+// RecordLogin remembers the device and the country of a successful login, which started the
+// session of sessionID. A login from a new country is recorded when the user had logged in from
+// other countries before, and otherwise a login from a new device when the user had logged in
+// from other devices before. The first device and country of a user aren't reported. Either is
+// also added to the user's activity feed.
+func (s *SecurityEventService) RecordLogin(ctx context.Context, user *entity.User, sessionID, ip, userAgent string) {
+	logger := logging.ModuleFromContext(ctx, logging.ModuleAuth)
+
+	newDevice, hadDevices, err := s.repo.TouchDevice(ctx, entity.NewKnownDevice(user.ID, userAgent, ip))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record login device")
+		return
+	}
+
+	var country string
+	newCountry := false
+	if s.geoIP != nil {
+		country = s.geoIP.Country(ip)
+	}
+	if country != "" {
+		isNew, hadCountries, err := s.repo.TouchCountry(ctx, entity.NewKnownCountry(user.ID, country, ip))
+		if err != nil {
+			logger.WithError(err).Warn("Failed to record login country")
+		}
+		newCountry = isNew && hadCountries
+	}
+
+	details := map[string]string{"session_id": sessionID}
+	if country != "" {
+		details["country"] = country
+	}
+	switch {
+	case newCountry:
+		s.Record(ctx, user, entity.SecurityEventNewCountryLogin, ip, userAgent, details)
+	case newDevice && hadDevices:
+		s.Record(ctx, user, entity.SecurityEventNewDeviceLogin, ip, userAgent, details)
+	default:
+		return
+	}
+
+	if s.activities != nil {
+		activityDetails := map[string]string{"ip": ip, "user_agent": userAgent}
+		if country != "" {
+			activityDetails["country"] = country
+		}
+		s.activities.Record(ctx, user.ID, entity.ActivityNewDeviceLogin, activityDetails)
+	}
+}
@@ -0,0 +1,10 @@
+package service
+
+import "context"
+
+// SMSService sends text messages to phone numbers
+type SMSService interface {
+	// Send sends the text to the phone number, in E.164 format. It returns
+	// domain.ErrSMSUnavailable when the provider can't be reached or rejects the message.
+	Send(ctx context.Context, to, text string) error
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// TokenDenylistService rejects access tokens before they expire. Single tokens are denied by
+// their JWT ID, and all tokens of a user issued up to a moment by a per-user cutoff. Entries
+// expire with the tokens they deny, so the denylist stays as small as the tokens still valid.
+type TokenDenylistService struct {
+	cacheService *CacheService
+	// accessExpiry is how long a user's cutoff is kept: by then every token it denies expired
+	accessExpiry time.Duration
+}
+
+// NewTokenDenylistService creates a new token denylist service
+func NewTokenDenylistService(cacheService *CacheService, accessExpiry time.Duration) *TokenDenylistService {
+	return &TokenDenylistService{
+		cacheService: cacheService,
+		accessExpiry: accessExpiry,
+	}
+}
+
+// Deny rejects the token of the claims until it expires. Tokens without a JWT ID, issued before
+// tokens had one, can only be denied with DenyUser.
+func (s *TokenDenylistService) Deny(ctx context.Context, claims *TokenClaims) error {
+	if claims == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.cacheService.SetWithExpiration(ctx, TokenDenylistCacheKey("jti:"+claims.ID), "1", ttl)
+}
+
+// DenyUser rejects every token of the user issued until now. Tokens are issued with a precision
+// of a second, so a token issued in the same second as the cutoff is rejected too.
+func (s *TokenDenylistService) DenyUser(ctx context.Context, userID string) error {
+	cutoff := strconv.FormatInt(time.Now().Unix(), 10)
+	return s.cacheService.SetWithExpiration(ctx, TokenDenylistCacheKey("user:"+userID), cutoff, s.accessExpiry)
+}
+
+// DenyUserBefore rejects every token of the user issued before the second of issuedBefore. Unlike
+// DenyUser, tokens issued in that second are kept, so a device can be given new tokens along
+// with the change that denies the others.
+func (s *TokenDenylistService) DenyUserBefore(ctx context.Context, userID string, issuedBefore time.Time) error {
+	cutoff := strconv.FormatInt(issuedBefore.Unix()-1, 10)
+	return s.cacheService.SetWithExpiration(ctx, TokenDenylistCacheKey("user:"+userID), cutoff, s.accessExpiry)
+}
+
+// IsDenied reports whether the token of the claims was denied
+func (s *TokenDenylistService) IsDenied(ctx context.Context, claims *TokenClaims) (bool, error) {
+	if claims.ID != "" {
+		denied, err := s.cacheService.Exists(ctx, TokenDenylistCacheKey("jti:"+claims.ID))
+		if err != nil || denied {
+			return denied, err
+		}
+	}
+
+	value, err := s.cacheService.GetString(ctx, TokenDenylistCacheKey("user:"+claims.UserID))
+	if err != nil || value == "" {
+		return false, err
+	}
+	cutoff, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, nil
+	}
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= cutoff, nil
+}
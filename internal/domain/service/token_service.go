@@ -1,10 +1,14 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // TokenType represents the type of token
@@ -17,20 +21,26 @@ const (
 
 // TokenClaims represents JWT claims
 type TokenClaims struct {
-	UserID   string                 `json:"user_id"`
-	Email    string                 `json:"email"`
-	Role     string                 `json:"role"`
-	TokenType TokenType             `json:"token_type"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenType TokenType `json:"token_type"`
+	// Locale is the user's preferred locale, set on access tokens only
+	Locale string `json:"locale,omitempty"`
+	// RateLimitTier is the tier of the user's rate limit, set on access tokens only
+	RateLimitTier string `json:"rate_limit_tier,omitempty"`
+	// RegisteredClaims.ID is the JWT ID (jti), which the token denylist names tokens by
 	jwt.RegisteredClaims
 }
 
 // TokenService handles JWT token operations
 type TokenService interface {
 	// GenerateAccessToken generates an access token
-	GenerateAccessToken(userID, email, role string) (string, error)
+	GenerateAccessToken(userID, email, role, locale, rateLimitTier string) (string, error)
 
-	// GenerateRefreshToken generates a refresh token
-	GenerateRefreshToken(userID, email, role string) (string, error)
+	// GenerateRefreshToken generates a refresh token expiring at expiresAt, which is
+	// GetTokenExpiration(TokenTypeRefresh) from now unless the session is remembered longer
+	GenerateRefreshToken(userID, email, role string, expiresAt time.Time) (string, error)
 
 	// ValidateAccessToken validates an access token
 	ValidateAccessToken(tokenString string) (*TokenClaims, error)
@@ -40,59 +50,167 @@ type TokenService interface {
 
 	// GetTokenExpiration returns the expiration time for a token type
 	GetTokenExpiration(tokenType TokenType) time.Duration
+
+	// JWKS returns the public keys tokens are verified with, so other services can verify them.
+	// It is empty when tokens are signed with the HMAC secret only.
+	JWKS() JSONWebKeySet
+}
+
+// SigningKey is an HMAC secret tokens are signed with, identified by the kid header of the tokens
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// NewSigningKey creates a signing key whose ID is derived from the secret, so rotating the
+// secret needs no other setting and the ID reveals nothing about it
+func NewSigningKey(secret string) SigningKey {
+	sum := sha256.Sum256([]byte(secret))
+	return SigningKey{
+		ID:     hex.EncodeToString(sum[:8]),
+		Secret: []byte(secret),
+	}
+}
+
+// PreviousKeyObserver is told about every token accepted with a previous signing key
+type PreviousKeyObserver func(keyID string, tokenType TokenType)
+
+// TokenServiceConfig represents the keys and lifetimes of tokens
+type TokenServiceConfig struct {
+	// Secret signs tokens with HS256 unless SigningKey is set, and always verifies them
+	Secret string
+	// PreviousSecrets still verify tokens during a rotation of the secret
+	PreviousSecrets []string
+	// SigningKey signs tokens with RS256 or EdDSA instead of HS256 when set
+	SigningKey *AsymmetricKey
+	// PreviousKeys still verify tokens during a rotation of the signing key
+	PreviousKeys  []*AsymmetricKey
+	AccessExpiry  time.Duration
+	RefreshExpiry time.Duration
+	// Issuer and Audience are set as the iss and aud claims of tokens, which must then carry
+	// them; empty leaves the claim out and unchecked
+	Issuer   string
+	Audience string
+	// OnPreviousKey may be nil
+	OnPreviousKey PreviousKeyObserver
 }
 
 type tokenService struct {
-	secretKey     []byte
+	currentKey    SigningKey
+	previousKeys  []SigningKey
+	signingKey    *AsymmetricKey
+	verifyingKeys []*AsymmetricKey
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
+	issuer        string
+	audience      string
+	onPreviousKey PreviousKeyObserver
 }
 
-// NewTokenService creates a new token service
-func NewTokenService(secretKey string, accessExpiry, refreshExpiry time.Duration) TokenService {
+// NewTokenService creates a new token service. Tokens are signed with secretKey and still accepted
+// when signed with one of previousKeys, so secrets can be rotated without logging users out.
+// onPreviousKey may be nil.
+func NewTokenService(secretKey string, previousKeys []string, accessExpiry, refreshExpiry time.Duration, onPreviousKey PreviousKeyObserver) TokenService {
+	return NewTokenServiceWithConfig(TokenServiceConfig{
+		Secret:          secretKey,
+		PreviousSecrets: previousKeys,
+		AccessExpiry:    accessExpiry,
+		RefreshExpiry:   refreshExpiry,
+		OnPreviousKey:   onPreviousKey,
+	})
+}
+
+// NewTokenServiceWithConfig creates a new token service. With a signing key, tokens are signed
+// with it and tokens signed with the HMAC secrets are still accepted, so switching to asymmetric
+// signing doesn't log users out.
+func NewTokenServiceWithConfig(config TokenServiceConfig) TokenService {
+	previous := make([]SigningKey, len(config.PreviousSecrets))
+	for i, key := range config.PreviousSecrets {
+		previous[i] = NewSigningKey(key)
+	}
+
+	var verifyingKeys []*AsymmetricKey
+	if config.SigningKey != nil {
+		verifyingKeys = append(verifyingKeys, config.SigningKey)
+	}
+	verifyingKeys = append(verifyingKeys, config.PreviousKeys...)
+
 	return &tokenService{
-		secretKey:     []byte(secretKey),
-		accessExpiry:  accessExpiry,
-		refreshExpiry: refreshExpiry,
+		currentKey:    NewSigningKey(config.Secret),
+		previousKeys:  previous,
+		signingKey:    config.SigningKey,
+		verifyingKeys: verifyingKeys,
+		accessExpiry:  config.AccessExpiry,
+		refreshExpiry: config.RefreshExpiry,
+		issuer:        config.Issuer,
+		audience:      config.Audience,
+		onPreviousKey: config.OnPreviousKey,
 	}
 }
 
 // GenerateAccessToken generates an access token
-func (s *tokenService) GenerateAccessToken(userID, email, role string) (string, error) {
+func (s *tokenService) GenerateAccessToken(userID, email, role, locale, rateLimitTier string) (string, error) {
 	claims := &TokenClaims{
-		UserID:   userID,
-		Email:    email,
-		Role:     role,
-		TokenType: TokenTypeAccess,
+		UserID:        userID,
+		Email:         email,
+		Role:          role,
+		TokenType:     TokenTypeAccess,
+		Locale:        locale,
+		RateLimitTier: rateLimitTier,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Audience:  s.audienceClaim(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Subject:   userID,
+			ID:        uuid.New().String(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	return s.sign(claims)
 }
 
-// GenerateRefreshToken generates a refresh token
-func (s *tokenService) GenerateRefreshToken(userID, email, role string) (string, error) {
+// GenerateRefreshToken generates a refresh token expiring at expiresAt
+func (s *tokenService) GenerateRefreshToken(userID, email, role string, expiresAt time.Time) (string, error) {
 	claims := &TokenClaims{
-		UserID:   userID,
-		Email:    email,
-		Role:     role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
 		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshExpiry)),
+			Issuer:    s.issuer,
+			Audience:  s.audienceClaim(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Subject:   userID,
+			ID:        uuid.New().String(),
 		},
 	}
 
+	return s.sign(claims)
+}
+
+// audienceClaim returns the aud claim of new tokens, or nil to leave it out
+func (s *tokenService) audienceClaim() jwt.ClaimStrings {
+	if s.audience == "" {
+		return nil
+	}
+	return jwt.ClaimStrings{s.audience}
+}
+
+// sign signs claims with the current key, naming it in the kid header
+func (s *tokenService) sign(claims *TokenClaims) (string, error) {
+	if s.signingKey != nil {
+		token := jwt.NewWithClaims(s.signingKey.Method, claims)
+		token.Header["kid"] = s.signingKey.ID
+		return token.SignedString(s.signingKey.Private)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	token.Header["kid"] = s.currentKey.ID
+	return token.SignedString(s.currentKey.Secret)
 }
 
 // ValidateAccessToken validates an access token
@@ -107,12 +225,37 @@ func (s *tokenService) ValidateRefreshToken(tokenString string) (*TokenClaims, e
 
 // validateToken validates a token and returns claims
 func (s *tokenService) validateToken(tokenString string, expectedType TokenType) (*TokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	var (
+		token *jwt.Token
+		keyID string
+		err   error
+	)
+	parser := s.parser()
+	kid, named := tokenKeyID(tokenString)
+	if key := s.asymmetricKey(kid); named && key != nil {
+		keyID = key.ID
+		token, err = parser.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+			// The algorithm must be the key's, so the public key can't be used as an HMAC secret
+			if token.Method.Alg() != key.Method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return key.Public, nil
+		})
+	} else {
+		for _, key := range s.candidateKeys(kid, named) {
+			keyID = key.ID
+			token, err = parser.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+				}
+				return key.Secret, nil
+			})
+			// Only a wrong key is worth trying the next one for
+			if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+				break
+			}
 		}
-		return s.secretKey, nil
-	})
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -127,9 +270,70 @@ func (s *tokenService) validateToken(tokenString string, expectedType TokenType)
 		return nil, fmt.Errorf("invalid token type: expected %s, got %s", expectedType, claims.TokenType)
 	}
 
+	if keyID != s.signingKeyID() && s.onPreviousKey != nil {
+		s.onPreviousKey(keyID, expectedType)
+	}
+
 	return claims, nil
 }
 
+// parser returns the parser of tokens, which rejects tokens of another issuer or audience, such
+// as those of another environment
+func (s *tokenService) parser() *jwt.Parser {
+	var options []jwt.ParserOption
+	if s.issuer != "" {
+		options = append(options, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		options = append(options, jwt.WithAudience(s.audience))
+	}
+	return jwt.NewParser(options...)
+}
+
+// signingKeyID returns the ID of the key new tokens are signed with
+func (s *tokenService) signingKeyID() string {
+	if s.signingKey != nil {
+		return s.signingKey.ID
+	}
+	return s.currentKey.ID
+}
+
+// tokenKeyID returns the kid header of a token, and whether it has one
+func tokenKeyID(tokenString string) (string, bool) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &TokenClaims{})
+	if err != nil {
+		return "", false
+	}
+	kid, ok := token.Header["kid"].(string)
+	return kid, ok
+}
+
+// asymmetricKey returns the asymmetric key with the ID, or nil
+func (s *tokenService) asymmetricKey(kid string) *AsymmetricKey {
+	for _, key := range s.verifyingKeys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// candidateKeys returns the HMAC key named by the kid header of a token, or every HMAC key,
+// current first, for tokens issued before keys were named. An unknown kid gets the current key,
+// which fails verification.
+func (s *tokenService) candidateKeys(kid string, named bool) []SigningKey {
+	if !named {
+		return append([]SigningKey{s.currentKey}, s.previousKeys...)
+	}
+
+	for _, key := range s.previousKeys {
+		if key.ID == kid {
+			return []SigningKey{key}
+		}
+	}
+	return []SigningKey{s.currentKey}
+}
+
 // GetTokenExpiration returns the expiration time for a token type
 func (s *tokenService) GetTokenExpiration(tokenType TokenType) time.Duration {
 	switch tokenType {
@@ -140,4 +344,13 @@ func (s *tokenService) GetTokenExpiration(tokenType TokenType) time.Duration {
 	default:
 		return s.accessExpiry
 	}
-}
\ No newline at end of file
+}
+
+// JWKS returns the public keys tokens are verified with
+func (s *tokenService) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range s.verifyingKeys {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+)
+
+// UnsubscribeTokenService signs the tokens of the unsubscribe links in notification emails. A
+// token names a user and a notification category, and never expires, so the links of old emails
+// keep working. Nothing is stored: a token is valid while the secret it was signed with is one
+// of the service's.
+type UnsubscribeTokenService struct {
+	url     string
+	secrets [][]byte
+}
+
+// NewUnsubscribeTokenService creates a new unsubscribe token service. Tokens are signed with the
+// first secret, and the others still verify them during a rotation. url is the page the links
+// open, which posts the token back.
+func NewUnsubscribeTokenService(url string, secrets ...string) *UnsubscribeTokenService {
+	keys := make([][]byte, len(secrets))
+	for i, secret := range secrets {
+		keys[i] = []byte(secret)
+	}
+	return &UnsubscribeTokenService{
+		url:     url,
+		secrets: keys,
+	}
+}
+
+// Issue returns the token unsubscribing the user from the category
+func (s *UnsubscribeTokenService) Issue(userID string, category entity.NotificationCategory) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + ":" + string(category)))
+	return payload + "." + s.sign(s.secrets[0], payload)
+}
+
+// URL returns the unsubscribe link of the user for the category, with the token as its token
+// query parameter
+func (s *UnsubscribeTokenService) URL(userID string, category entity.NotificationCategory) string {
+	link, err := url.Parse(s.url)
+	if err != nil {
+		return s.url
+	}
+	query := link.Query()
+	query.Set("token", s.Issue(userID, category))
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// Parse verifies a token and returns the user and the category it unsubscribes from. Malformed
+// tokens and tokens of other secrets return domain.ErrInvalidUnsubscribeToken.
+func (s *UnsubscribeTokenService) Parse(token string) (string, entity.NotificationCategory, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !s.verify(payload, signature) {
+		return "", "", domain.ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", domain.ErrInvalidUnsubscribeToken
+	}
+	userID, category, ok := strings.Cut(string(decoded), ":")
+	if !ok || userID == "" || !entity.NotificationCategory(category).IsValid() {
+		return "", "", domain.ErrInvalidUnsubscribeToken
+	}
+	return userID, entity.NotificationCategory(category), nil
+}
+
+// verify reports whether one of the secrets signed the payload
+func (s *UnsubscribeTokenService) verify(payload, signature string) bool {
+	for _, secret := range s.secrets {
+		if hmac.Equal([]byte(signature), []byte(s.sign(secret, payload))) {
+			return true
+		}
+	}
+	return false
+}
+
+// sign returns the signature of the payload with the secret. The purpose is signed too, so an
+// unsubscribe token can't be forged from a signature made for something else with the secret.
+func (s *UnsubscribeTokenService) sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("unsubscribe:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
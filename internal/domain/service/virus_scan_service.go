@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// VirusScanner scans files for malware
+type VirusScanner interface {
+	// Scan reads the file to its end and returns the name of the malware found in it, or "" when
+	// it is clean. It returns domain.ErrVirusScanUnavailable when the scanner can't be reached.
+	Scan(ctx context.Context, file io.Reader) (string, error)
+}
+
+// VirusScanService scans uploaded files before they are stored. Infected files are rejected. When
+// the scanner is unavailable, uploads are rejected too if scanning is required, and stored
+// unscanned otherwise.
+type VirusScanService struct {
+	scanner  VirusScanner
+	required bool
+}
+
+// NewVirusScanService creates a new virus scan service. A nil scanner disables scanning, and every
+// file is stored unscanned.
+func NewVirusScanService(scanner VirusScanner, required bool) *VirusScanService {
+	return &VirusScanService{
+		scanner:  scanner,
+		required: required,
+	}
+}
+
+// ScanUpload scans an uploaded file. It returns domain.ErrFileInfected when malware is found, and
+// domain.ErrVirusScanUnavailable when the scanner is unavailable and scanning is required.
+func (s *VirusScanService) ScanUpload(ctx context.Context, file *multipart.FileHeader) (entity.ScanStatus, error) {
+	if s.scanner == nil {
+		return entity.ScanStatusUnscanned, nil
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file to scan: %w", err)
+	}
+	defer reader.Close()
+
+	signature, err := s.scanner.Scan(ctx, reader)
+	if err != nil {
+		if s.required || !errors.Is(err, domain.ErrVirusScanUnavailable) {
+			return "", err
+		}
+		logging.FromContext(ctx).WithError(err).WithField("file_name", file.Filename).Warn("Storing file unscanned, virus scanner unavailable")
+		return entity.ScanStatusUnscanned, nil
+	}
+	if signature != "" {
+		logging.FromContext(ctx).WithFields(logrus.Fields{
+			"file_name": file.Filename,
+			"signature": signature,
+		}).Warn("Rejected infected upload")
+		return "", domain.ErrFileInfected
+	}
+	return entity.ScanStatusClean, nil
+}
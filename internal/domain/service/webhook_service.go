@@ -0,0 +1,335 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/google/uuid"
+)
+
+// JobDeliverWebhook is the job type delivering an event to a webhook
+const JobDeliverWebhook = "webhook.deliver"
+
+// WebhookEventPing is the event type of test deliveries, which every webhook is sent whatever it
+// subscribes to
+const WebhookEventPing = "ping"
+
+// Headers of webhook deliveries
+const (
+	// WebhookIDHeader is the event ID, the same for every attempt, so receivers can deduplicate
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookSignatureHeader is "v1=" followed by SignWebhook of the timestamp and body
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookSecretPrefix makes webhook secrets recognisable, like the prefix of API keys
+const webhookSecretPrefix = "whsec_"
+
+// maxWebhookResponse bounds how much of an endpoint's response the delivery log keeps
+const maxWebhookResponse = 1024
+
+// WebhookEventTypes are the event types webhooks can subscribe to, the same events the live
+// connections of the user receive
+var WebhookEventTypes = []string{
+	"avatar.updated",
+	"avatar.removed",
+	"avatar.rejected",
+	"document.created",
+	"document.updated",
+	"document.deleted",
+}
+
+// errPrivateNetwork rejects connections to private addresses, so webhooks can't reach internal
+// services
+var errPrivateNetwork = errors.New("address is in a private network")
+
+// WebhookConfig configures webhook deliveries
+type WebhookConfig struct {
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+	// AllowHTTP accepts webhook URLs without TLS
+	AllowHTTP bool
+	// AllowPrivateNetworks lets webhooks reach loopback, private and link-local addresses
+	AllowPrivateNetworks bool
+}
+
+// WebhookEvent is the JSON body of a webhook delivery
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WebhookJobPayload is the payload of JobDeliverWebhook jobs
+type WebhookJobPayload struct {
+	WebhookID string       `json:"webhook_id"`
+	Event     WebhookEvent `json:"event"`
+}
+
+// WebhookService sends the events of users to their webhooks. Every delivery runs as a job, so
+// an endpoint that is down is retried with the backoff of the job queue, and every attempt is
+// recorded in the delivery log of its webhook.
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	jobQueue    *JobQueue
+	config      WebhookConfig
+	client      *http.Client
+}
+
+// NewWebhookService creates a new webhook service. Register Deliver as the function of
+// JobDeliverWebhook jobs.
+func NewWebhookService(webhookRepo repository.WebhookRepository, jobQueue *JobQueue, config WebhookConfig) *WebhookService {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		// Checked on the resolved address, so a public name pointing at a private address fails too
+		dialer.Control = rejectPrivateNetwork
+	}
+
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		jobQueue:    jobQueue,
+		config:      config,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: config.Timeout,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// A redirect could lead anywhere; receivers register the URL that answers
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// GenerateSecret returns a new random secret to sign the deliveries of a webhook with
+func (s *WebhookService) GenerateSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(random), nil
+}
+
+// ValidateURL checks that deliveries may be sent to rawURL. Host names are only checked when
+// delivering, as what they resolve to can change.
+func (s *WebhookService) ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return domain.ErrInvalidWebhookURL.WithMessage("Webhook URL must be an absolute http or https URL")
+	}
+	if u.Scheme == "http" && !s.config.AllowHTTP {
+		return domain.ErrInvalidWebhookURL.WithMessage("Webhook URL must use https")
+	}
+
+	if !s.config.AllowPrivateNetworks {
+		host := strings.ToLower(u.Hostname())
+		ip := net.ParseIP(host)
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && isPrivateIP(ip)) {
+			return domain.ErrInvalidWebhookURL.WithMessage("Webhook URL must not point to a private network")
+		}
+	}
+	return nil
+}
+
+// ValidateEventTypes checks that webhooks can subscribe to every event type
+func (s *WebhookService) ValidateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return domain.ErrInvalidWebhookEventType.WithMessage(fmt.Sprintf(
+				"Unknown webhook event type %q, expected one of %s", eventType, strings.Join(WebhookEventTypes, ", ")))
+		}
+	}
+	return nil
+}
+
+// Dispatch enqueues the delivery of an event to every active webhook of the user subscribed to
+// its type
+func (s *WebhookService) Dispatch(ctx context.Context, userID, eventType string, data interface{}) error {
+	webhooks, err := s.webhookRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find webhooks: %w", err)
+	}
+
+	var event *WebhookEvent
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		if event == nil {
+			if event, err = newWebhookEvent(eventType, data); err != nil {
+				return err
+			}
+		}
+		if err := s.jobQueue.Enqueue(ctx, JobDeliverWebhook, WebhookJobPayload{WebhookID: webhook.ID, Event: *event}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver delivers an event to its webhook and records the attempt, failing when the endpoint
+// doesn't accept it so the job is retried. Webhooks deleted, disabled or unsubscribed from the
+// event since it was enqueued are skipped. It runs JobDeliverWebhook jobs.
+func (s *WebhookService) Deliver(ctx context.Context, payload WebhookJobPayload) error {
+	webhook, err := s.webhookRepo.FindByID(ctx, payload.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find webhook: %w", err)
+	}
+	if !webhook.Subscribes(payload.Event.Type) {
+		return nil
+	}
+
+	delivery := s.send(ctx, webhook, payload.Event, JobAttempt(ctx))
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		// The delivery log is informational; the outcome still decides the retry
+		logging.FromContext(ctx).WithError(err).Warn("Failed to record webhook delivery")
+	}
+
+	if !delivery.Succeeded {
+		return fmt.Errorf("failed to deliver webhook: %s", delivery.Error)
+	}
+	return nil
+}
+
+// Ping sends a ping event to a webhook right away, whether it is active or not, and records the
+// attempt
+func (s *WebhookService) Ping(ctx context.Context, webhook *entity.Webhook) (*entity.WebhookDelivery, error) {
+	event, err := newWebhookEvent(WebhookEventPing, map[string]string{"webhook_id": webhook.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := s.send(ctx, webhook, *event, 1)
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// send posts an event to a webhook and returns the record of the attempt. Any status other than
+// 2xx fails it.
+func (s *WebhookService) send(ctx context.Context, webhook *entity.Webhook, event WebhookEvent, attempt int) *entity.WebhookDelivery {
+	delivery := &entity.WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Attempt:   attempt,
+		CreatedAt: time.Now(),
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to encode event: %v", err)
+		return delivery
+	}
+	delivery.Request = string(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to create request: %v", err)
+		return delivery
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gin-boilerplate-webhooks")
+	req.Header.Set(WebhookIDHeader, event.ID)
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "v1="+SignWebhook(webhook.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	delivery.StatusCode = resp.StatusCode
+	delivery.Response = string(response)
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = fmt.Sprintf("endpoint answered with status %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// SignWebhook returns the signature of a delivery: the hex HMAC-SHA256, keyed with the secret of
+// the webhook, of the timestamp, a dot and the body. Signing the timestamp lets receivers reject
+// replayed deliveries.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookEvent creates an event with a new ID and data encoded as JSON
+func newWebhookEvent(eventType string, data interface{}) (*WebhookEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook event data: %w", err)
+	}
+	return &WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// rejectPrivateNetwork fails connections to private addresses; it is a net.Dialer Control
+// function
+func rejectPrivateNetwork(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return fmt.Errorf("%s: %w", host, errPrivateNetwork)
+	}
+	return nil
+}
+
+// isPrivateIP reports whether ip is a loopback, private, link-local, multicast or unspecified
+// address
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
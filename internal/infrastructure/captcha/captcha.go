@@ -0,0 +1,103 @@
+// Package captcha verifies CAPTCHA tokens with reCAPTCHA v3 or hCaptcha
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Providers verify CAPTCHA tokens
+const (
+	// ProviderRecaptcha verifies Google reCAPTCHA v3 tokens
+	ProviderRecaptcha = "recaptcha"
+	// ProviderHCaptcha verifies hCaptcha tokens
+	ProviderHCaptcha = "hcaptcha"
+)
+
+// Config configures the provider verifying CAPTCHA tokens
+type Config struct {
+	Provider  string
+	SecretKey string
+	// MinScore is the lowest reCAPTCHA v3 score accepted, from 0.0 (a bot) to 1.0 (a human)
+	MinScore float64
+	// Timeout bounds each verification
+	Timeout time.Duration
+}
+
+// New creates the CAPTCHA service of the configured provider
+func New(cfg Config) (service.CaptchaService, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Provider {
+	case ProviderRecaptcha:
+		return &recaptchaService{secretKey: cfg.SecretKey, minScore: cfg.MinScore, client: client}, nil
+	case ProviderHCaptcha:
+		return &hcaptchaService{secretKey: cfg.SecretKey, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown CAPTCHA provider %q", cfg.Provider)
+	}
+}
+
+// siteVerifyResponse is the part of a siteverify response both providers share
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      float64  `json:"score"`
+	Action     string   `json:"action"`
+	Hostname   string   `json:"hostname"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// siteVerify posts a token to a siteverify endpoint. A provider that can't be reached gives
+// domain.ErrCaptchaUnavailable, so clients can tell it from a token being rejected.
+func siteVerify(ctx context.Context, client *http.Client, endpoint, secretKey, token, remoteIP string) (*siteVerifyResponse, error) {
+	form := url.Values{
+		"secret":   {secretKey},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CAPTCHA verification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrCaptchaUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status code %d", domain.ErrCaptchaUnavailable, resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode response: %v", domain.ErrCaptchaUnavailable, err)
+	}
+	return &result, nil
+}
+
+// reject logs why a token was rejected and returns domain.ErrInvalidCaptcha
+func reject(ctx context.Context, reason string, result *siteVerifyResponse) error {
+	logging.ModuleFromContext(ctx, logging.ModuleAuth).WithFields(logrus.Fields{
+		"score":       result.Score,
+		"action":      result.Action,
+		"error_codes": result.ErrorCodes,
+	}).Debug("CAPTCHA rejected: " + reason)
+	return domain.ErrInvalidCaptcha
+}
@@ -0,0 +1,29 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+)
+
+// hcaptchaEndpoint verifies hCaptcha tokens
+const hcaptchaEndpoint = "https://api.hcaptcha.com/siteverify"
+
+// hcaptchaService verifies hCaptcha tokens. hCaptcha tokens aren't bound to an action, so any
+// solved challenge of the site is accepted for every endpoint.
+type hcaptchaService struct {
+	secretKey string
+	client    *http.Client
+}
+
+// Verify checks the token
+func (s *hcaptchaService) Verify(ctx context.Context, token, action, remoteIP string) error {
+	result, err := siteVerify(ctx, s.client, hcaptchaEndpoint, s.secretKey, token, remoteIP)
+	if err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return reject(ctx, "not successful", result)
+	}
+	return nil
+}
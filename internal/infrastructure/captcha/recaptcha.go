@@ -0,0 +1,36 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+)
+
+// recaptchaEndpoint verifies reCAPTCHA tokens
+const recaptchaEndpoint = "https://www.google.com/recaptcha/api/siteverify"
+
+// recaptchaService verifies reCAPTCHA v3 tokens. v3 doesn't ask users to solve anything; it scores
+// how likely the client is a human, so tokens are accepted from the minimum score up.
+type recaptchaService struct {
+	secretKey string
+	minScore  float64
+	client    *http.Client
+}
+
+// Verify checks the token, its score, and that it was issued for the action
+func (s *recaptchaService) Verify(ctx context.Context, token, action, remoteIP string) error {
+	result, err := siteVerify(ctx, s.client, recaptchaEndpoint, s.secretKey, token, remoteIP)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !result.Success:
+		return reject(ctx, "not successful", result)
+	case result.Action != action:
+		// A token solved on another page can't be reused here
+		return reject(ctx, "issued for another action", result)
+	case result.Score < s.minScore:
+		return reject(ctx, "score too low", result)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package clamav scans files for malware with the clamd daemon of ClamAV
+package clamav
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// chunkSize is the size of the chunks files are streamed to clamd in. clamd rejects streams
+// longer than its StreamMaxLength, 25MB by default, which is more than any upload accepted.
+const chunkSize = 64 * 1024
+
+// Config configures the clamd daemon files are scanned with
+type Config struct {
+	// Address is where clamd listens: tcp://host:port or unix:///path/to/clamd.sock
+	Address string
+	// Timeout bounds each scan
+	Timeout time.Duration
+}
+
+// Scanner scans files with the INSTREAM command of clamd
+type Scanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+var _ service.VirusScanner = (*Scanner)(nil)
+
+// New creates a new clamd scanner
+func New(cfg Config) (*Scanner, error) {
+	address, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamd address: %w", err)
+	}
+
+	switch address.Scheme {
+	case "tcp":
+		return &Scanner{network: "tcp", address: address.Host, timeout: cfg.Timeout}, nil
+	case "unix":
+		return &Scanner{network: "unix", address: address.Path, timeout: cfg.Timeout}, nil
+	default:
+		return nil, fmt.Errorf("clamd address must start with tcp:// or unix://, got %q", cfg.Address)
+	}
+}
+
+// Ping checks that clamd answers
+func (s *Scanner) Ping(ctx context.Context) error {
+	reply, err := s.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("%w: unexpected clamd reply %q", domain.ErrVirusScanUnavailable, reply)
+	}
+	return nil
+}
+
+// Scan streams the file to clamd and returns the name of the malware it found, or "" when the
+// file is clean
+func (s *Scanner) Scan(ctx context.Context, file io.Reader) (string, error) {
+	reply, err := s.command(ctx, "INSTREAM", file)
+	if err != nil {
+		return "", err
+	}
+
+	// Replies are "stream: OK", "stream: <signature> FOUND" or "<reason> ERROR"
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", fmt.Errorf("%w: clamd failed to scan: %s", domain.ErrVirusScanUnavailable, reply)
+	}
+}
+
+// command sends a command to clamd, followed by the stream of the file when not nil, and returns
+// the reply. Commands are prefixed with z so they and their reply end with a null byte.
+func (s *Scanner) command(ctx context.Context, command string, file io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to connect to clamd: %v", domain.ErrVirusScanUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("z" + command + "\x00")); err != nil {
+		return "", fmt.Errorf("%w: failed to send clamd command: %v", domain.ErrVirusScanUnavailable, err)
+	}
+	if file != nil {
+		if err := stream(conn, file); err != nil {
+			return "", err
+		}
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read clamd reply: %v", domain.ErrVirusScanUnavailable, err)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(reply, "\x00")), nil
+}
+
+// stream sends the file as chunks prefixed with their length, as 4 bytes in network order,
+// ended by a chunk of length zero
+func stream(conn net.Conn, file io.Reader) error {
+	buffer := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(file, buffer[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buffer[:4], uint32(n))
+			if _, err := conn.Write(buffer[:4+n]); err != nil {
+				return fmt.Errorf("%w: failed to stream file to clamd: %v", domain.ErrVirusScanUnavailable, err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file to scan: %w", err)
+		}
+	}
+
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("%w: failed to stream file to clamd: %v", domain.ErrVirusScanUnavailable, err)
+	}
+	return nil
+}
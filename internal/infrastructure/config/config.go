@@ -1,27 +1,98 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"gin-boilerplate/internal/infrastructure/logging"
+	"gin-boilerplate/internal/infrastructure/secrets"
+
 	"github.com/joho/godotenv"
 )
 
 // Config represents application configuration
 type Config struct {
-	Server ServerConfig
-	Database DatabaseConfig
-	JWT JWTConfig
-	Google GoogleConfig
-	S3 S3Config
+	Server            ServerConfig
+	Database          DatabaseConfig
+	JWT               JWTConfig
+	Password          PasswordConfig
+	PasswordReset     PasswordResetConfig
+	EmailChange       EmailChangeConfig
+	EmailVerification EmailVerificationConfig
+	Registration      RegistrationConfig
+	AccountDeletion   AccountDeletionConfig
+	DataExport        DataExportConfig
+	Avatar            AvatarConfig
+	DisposableEmails  DisposableEmailsConfig
+	OAuth             OAuthConfig
+	AuthCookies       AuthCookiesConfig
+	Google            GoogleConfig
+	OIDC              OIDCConfig
+	Captcha           CaptchaConfig
+	SMS               SMSConfig
+	S3                S3Config
+	Search            SearchConfig
+	VirusScan         VirusScanConfig
+	Email             EmailConfig
+	Redis             RedisConfig
+	RateLimit         RateLimitConfig
+	Quota             QuotaConfig
+	Concurrency       ConcurrencyConfig
+	LoginThrottle     LoginThrottleConfig
+	SecurityEvents    SecurityEventsConfig
+	AuditLog          AuditLogConfig
+	Activity          ActivityConfig
+	Dashboard         DashboardConfig
+	Compression       CompressionConfig
+	Timeout           TimeoutConfig
+	Sentry            SentryConfig
+	CORS              CORSConfig
+	AdminAccess       AdminAccessConfig
+	TLS               TLSConfig
+	Events            EventsConfig
+	Webhooks          WebhooksConfig
+	GRPC              GRPCConfig
+	Static            StaticConfig
+	Secrets           SecretsConfig
+	Log               LogConfig
+	Seed              SeedConfig
+	TokenCleanup      TokenCleanupConfig
+	Jobs              JobsConfig
+	Scheduler         SchedulerConfig
+
+	secretsProvider secrets.Provider
+	// settings are the values read, for the startup summary
+	settings []Setting
 }
 
 // ServerConfig represents server configuration
 type ServerConfig struct {
 	Port string
 	Env  string
+	// TrustedProxies lists the proxies whose X-Forwarded-For header is used for the client IP.
+	// When empty, every proxy is trusted.
+	TrustedProxies []string
+	// TCPEnabled serves on Port; it can be turned off when only a socket is used
+	TCPEnabled bool
+	// SocketPath is a unix domain socket to serve on in addition to the TCP port
+	SocketPath string
+	SocketMode os.FileMode
+	// SystemdActivation serves the sockets passed by systemd socket activation
+	SystemdActivation bool
+	// ReadTimeout and WriteTimeout are raised to fit UPLOAD_REQUEST_TIMEOUT when shorter
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
 }
 
 // DatabaseConfig represents database configuration
@@ -33,13 +104,184 @@ type DatabaseConfig struct {
 	DBName   string
 	SSLMode  string
 	DSN      string
+	// MaxOpenConns of 0 and ConnMaxLifetime or ConnMaxIdleTime of 0 mean no limit
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// SlowQueryThreshold is how long a query may take before it is logged as slow, 0 disables it
+	SlowQueryThreshold time.Duration
 }
 
 // JWTConfig represents JWT configuration
 type JWTConfig struct {
-	Secret        string
-	AccessExpiry  time.Duration
-	RefreshExpiry time.Duration
+	Secret string
+	// PreviousSecrets still validate tokens during a rotation, until those tokens expire
+	PreviousSecrets []string
+	// SigningKeyFile is a PEM RSA or Ed25519 private key tokens are signed with (RS256 or EdDSA)
+	// instead of the secret, which then only verifies tokens issued before the switch
+	SigningKeyFile string
+	// PreviousKeyFiles are PEM keys that still verify tokens during a rotation of the signing key
+	PreviousKeyFiles []string
+	AccessExpiry     time.Duration
+	RefreshExpiry    time.Duration
+	// RememberMeExpiry is the refresh token lifetime of logins with remember_me; 0 ignores
+	// remember_me
+	RememberMeExpiry time.Duration
+	// SlidingRefresh moves the expiry of a session forward on each refresh, so only idle
+	// sessions expire
+	SlidingRefresh bool
+	// Issuer and Audience are the iss and aud claims of tokens, which only tokens carrying the
+	// same pass; empty leaves them out
+	Issuer   string
+	Audience string
+}
+
+// PasswordConfig represents how new password hashes are made. Existing hashes are upgraded on
+// the next login after a change.
+type PasswordConfig struct {
+	// HashAlgorithm is "bcrypt" or "argon2id"
+	HashAlgorithm string
+	BcryptCost    int
+	// Argon2Memory is in KiB
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	// HistorySize is how many of a user's latest passwords, the current one included, can't be
+	// set again; 0 allows any
+	HistorySize int
+}
+
+// PasswordResetConfig represents the forgot password flow
+type PasswordResetConfig struct {
+	// URL is the frontend page that sets the new password; the reset link adds a token parameter
+	URL string
+	// TokenTTL is how long a reset link can be used
+	TokenTTL time.Duration
+}
+
+// EmailChangeConfig represents the flow changing the email of local accounts
+type EmailChangeConfig struct {
+	// URL is the frontend page that confirms the new address; the link adds a token parameter
+	URL string
+	// RevertURL is the frontend page that cancels or undoes a change; the link adds a token
+	// parameter
+	RevertURL string
+	// TokenTTL is how long a confirmation link can be used
+	TokenTTL time.Duration
+	// RollbackWindow is how long the previous address can undo a confirmed change
+	RollbackWindow time.Duration
+}
+
+// EmailVerificationConfig represents the verification of the emails of local accounts
+type EmailVerificationConfig struct {
+	// URL is the frontend page that verifies the email; the link adds a token parameter
+	URL string
+	// TokenTTL is how long a verification link can be used
+	TokenTTL time.Duration
+	// Required blocks logins until the email is verified
+	Required bool
+}
+
+// RegistrationConfig represents who can sign up
+type RegistrationConfig struct {
+	// Mode is "open", "invite_only" or "closed"
+	Mode string
+	// AllowedDomains limits registration to emails of these domains, unless the user was
+	// invited; empty allows any
+	AllowedDomains []string
+	// InviteURL is the frontend page that registers with an invitation; the link adds a token
+	// parameter
+	InviteURL string
+	// InviteTTL is how long an invitation can be used
+	InviteTTL time.Duration
+}
+
+// InviteOnly reports whether registering needs an invitation
+func (c *RegistrationConfig) InviteOnly() bool {
+	return c.Mode == "invite_only"
+}
+
+// Closed reports whether new accounts are refused
+func (c *RegistrationConfig) Closed() bool {
+	return c.Mode == "closed"
+}
+
+// AccountDeletionConfig represents what happens to users who delete their account
+type AccountDeletionConfig struct {
+	// Mode is "delete" to delete the user permanently, or "anonymize" to keep it with its
+	// personal data replaced
+	Mode string
+}
+
+// Anonymize reports whether deleted accounts are anonymized rather than deleted
+func (c *AccountDeletionConfig) Anonymize() bool {
+	return c.Mode == "anonymize"
+}
+
+// DataExportConfig represents the exports users make of their data
+type DataExportConfig struct {
+	// LinkTTL is how long the emailed download link works, after which the export is deleted
+	LinkTTL time.Duration
+}
+
+// AvatarConfig represents the avatars users upload
+type AvatarConfig struct {
+	// Moderation keeps uploaded avatars pending until a moderator approves them, showing the
+	// previous avatar meanwhile
+	Moderation bool
+}
+
+// DisposableEmailsConfig represents the blocking of addresses of disposable email providers
+type DisposableEmailsConfig struct {
+	// Blocked rejects disposable addresses when registering or changing the email
+	Blocked bool
+	// ListURL is a list of disposable domains, one per line, replacing the embedded one; empty
+	// keeps the embedded list
+	ListURL string
+	// RefreshInterval is how often the list is fetched again from ListURL
+	RefreshInterval time.Duration
+	// ExtraDomains are disposable too, in addition to the listed ones
+	ExtraDomains []string
+	// AllowedDomains are never considered disposable, even when listed
+	AllowedDomains []string
+}
+
+// OAuthConfig represents the sign-ins with OAuth providers, whichever the provider
+type OAuthConfig struct {
+	// StateTTL is how long a user has to get through the consent page of a provider
+	StateTTL time.Duration
+	// CallbackMode is json, returning the tokens in the callback's response, or cookie, setting
+	// them as httpOnly cookies and redirecting to SuccessURL
+	CallbackMode string
+	SuccessURL   string
+	// CookieDomain and CookieSecure apply to the state and token cookies
+	CookieDomain string
+	CookieSecure bool
+}
+
+// AuthCookiesConfig represents handing the tokens of the auth endpoints over as cookies, for
+// browser clients
+type AuthCookiesConfig struct {
+	// Enabled sets the tokens as httpOnly cookies instead of returning them in the body, and
+	// accepts the access token cookie with a CSRF token
+	Enabled bool
+	Domain  string
+	Secure  bool
+	// SameSite is strict, lax or none
+	SameSite string
+}
+
+// SameSiteMode returns the SameSite attribute of the cookies
+func (c *AuthCookiesConfig) SameSiteMode() http.SameSite {
+	switch c.SameSite {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
 }
 
 // GoogleConfig represents Google OAuth configuration
@@ -47,6 +289,74 @@ type GoogleConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
+	// MobileClientIDs are the client IDs of the iOS and Android apps, whose ID tokens can be
+	// exchanged at POST /auth/google/token
+	MobileClientIDs []string
+}
+
+// Enabled reports whether Google sign-in is configured
+func (c *GoogleConfig) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// OIDCConfig represents the configuration of an OpenID Connect provider such as Keycloak,
+// Auth0 or Okta
+type OIDCConfig struct {
+	// Name identifies the provider in the sign-in URLs and is stored upper-cased as the
+	// provider of its users
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Enabled reports whether OIDC sign-in is configured
+func (c *OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// CaptchaConfig represents CAPTCHA verification of the auth endpoints. Provider is recaptcha
+// (v3) or hcaptcha; empty disables it.
+type CaptchaConfig struct {
+	Provider  string
+	SecretKey string
+	// MinScore is the lowest reCAPTCHA v3 score accepted
+	MinScore float64
+	// Timeout bounds each verification
+	Timeout time.Duration
+	// Endpoints require a CAPTCHA: register, login and forgot_password
+	Endpoints []string
+}
+
+// Enabled reports whether CAPTCHAs are verified
+func (c *CaptchaConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+// SMSConfig represents the text messages of phone verification and SMS two-factor
+// authentication
+type SMSConfig struct {
+	// Provider is log, twilio or vonage; empty disables text messages
+	Provider string
+	// From is the sender number in E.164 format, or an alphanumeric sender ID
+	From             string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	VonageAPIKey     string
+	VonageAPISecret  string
+	// Timeout bounds the sending of each message
+	Timeout time.Duration
+	// OTPTTL is how long a texted code can be entered
+	OTPTTL time.Duration
+	// OTPMaxAttempts is how many wrong codes end a challenge
+	OTPMaxAttempts int
+}
+
+// Enabled reports whether text messages are sent
+func (c *SMSConfig) Enabled() bool {
+	return c.Provider != ""
 }
 
 // S3Config represents S3-compatible storage configuration
@@ -57,47 +367,554 @@ type S3Config struct {
 	Region          string
 	Bucket          string
 	UseSSL          bool
+	DialTimeout     time.Duration
+	// Timeout bounds each S3 request, uploads included. 0 leaves it to the request deadline.
+	Timeout time.Duration
+}
+
+// SearchConfig represents the backend answering document searches
+type SearchConfig struct {
+	// Backend is postgres, searching the documents table, or opensearch, searching an index of
+	// the documents in OpenSearch or Elasticsearch kept up to date by background jobs
+	Backend string
+	// OpenSearchURL is the base URL of the OpenSearch or Elasticsearch cluster
+	OpenSearchURL string
+	// OpenSearchIndex is the name of the index of the documents, created when missing
+	OpenSearchIndex    string
+	OpenSearchUsername string
+	OpenSearchPassword string
+	// Timeout bounds each request to the cluster
+	Timeout time.Duration
+}
+
+// External reports whether an external search engine answers document searches
+func (c *SearchConfig) External() bool {
+	return c.Backend == "opensearch"
+}
+
+// VirusScanConfig represents the scanning of uploaded files for malware
+type VirusScanConfig struct {
+	// Mode is off, best_effort, storing files unscanned when clamd is unavailable, or required,
+	// rejecting uploads when clamd is unavailable
+	Mode string
+	// ClamAVAddress is where clamd listens: tcp://host:port or unix:///path/to/clamd.sock
+	ClamAVAddress string
+	// Timeout bounds the scan of each file
+	Timeout time.Duration
+}
+
+// Enabled reports whether uploaded files are scanned
+func (c *VirusScanConfig) Enabled() bool {
+	return c.Mode != "off"
+}
+
+// Required reports whether uploads are rejected when they can't be scanned
+func (c *VirusScanConfig) Required() bool {
+	return c.Mode == "required"
+}
+
+// EmailConfig represents email delivery. Driver is log, smtp, ses or sendgrid; log only logs
+// emails, for development.
+type EmailConfig struct {
+	Driver   string
+	From     string
+	FromName string
+	// TemplateDir holds templates overriding the embedded ones; empty uses the embedded ones only
+	TemplateDir string
+	// Timeout bounds the delivery of each email
+	Timeout time.Duration
+	// UnsubscribeURL is the frontend page the unsubscribe links of notification emails open, with
+	// a token query parameter
+	UnsubscribeURL string
+	SMTPHost       string
+	SMTPPort       int
+	SMTPUsername   string
+	SMTPPassword   string
+	SMTPTLS        string
+	SESRegion      string
+	SendGridAPIKey string
 }
 
 // RedisConfig represents Redis configuration
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-	PoolSize int
+	Host         string
+	Port         string
+	Password     string
+	DB           int
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// PoolTimeout is how long a command waits for a free connection
+	PoolTimeout time.Duration
+}
+
+// RateLimitConfig represents rate limiting configuration
+type RateLimitConfig struct {
+	RequestsPerWindow   int
+	WindowDuration      time.Duration
+	APIKeyDefaultLimit  int
+	APIKeyDefaultWindow time.Duration
+	// FailureMode is "open" or "closed" and applies when Redis is unavailable
+	FailureMode string
+	// FailClosedRoutes lists route classes that reject requests while Redis is unavailable
+	FailClosedRoutes []string
+	// Policies are the named limits routes can be mapped to
+	Policies []RateLimitPolicyConfig
+	// Routes maps route patterns to policies
+	Routes []RateLimitRouteConfig
+	// Tiers are the limits of authenticated users by their rate limit tier
+	Tiers []RateLimitTierConfig
+}
+
+// RateLimitPolicyConfig represents a named rate limit policy. The name is also its route class.
+type RateLimitPolicyConfig struct {
+	Name string
+	// Algorithm is "fixed_window" or "sliding_window"
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+	// Key is "client" (API key, else user, else IP), "ip" or "user"
+	Key string
+}
+
+// RateLimitTierConfig represents the fixed window limit of the users of a rate limit tier
+type RateLimitTierConfig struct {
+	// Name is "free", "pro" or "internal"
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitRouteConfig maps a route pattern, relative to /api/<version>, to a policy
+type RateLimitRouteConfig struct {
+	// Method is an HTTP method, or "*" for any
+	Method string
+	Path   string
+	Policy string
+}
+
+// QuotaConfig represents daily/monthly usage quota configuration. A limit of 0 means unlimited.
+type QuotaConfig struct {
+	RequestsPerDay        int64
+	UploadsPerMonth       int64
+	DownloadBytesPerMonth int64
+	// RollupInterval is how often the scheduler leader persists the Redis counters into Postgres;
+	// 0 only persists them on shutdown
+	RollupInterval time.Duration
+}
+
+// LoginThrottleConfig represents failed-login throttling and account lockout. A limit of 0
+// disables it.
+type LoginThrottleConfig struct {
+	MaxAttemptsPerAccount   int
+	MaxAttemptsPerAccountIP int
+	MaxAttemptsPerIP        int
+	Window                  time.Duration
+	LockoutDuration         time.Duration
+}
+
+// SecurityEventsConfig represents the recording of security events and the alerts about them
+type SecurityEventsConfig struct {
+	// EmailAlerts emails users about the security events of their account
+	EmailAlerts bool
+	// Retention is how long security events are kept; 0 keeps them
+	Retention time.Duration
+	// GeoIPDatabase is the path of a CSV database of the countries of IP ranges, to report logins
+	// from new countries; empty disables it
+	GeoIPDatabase string
+}
+
+// AuditLogConfig represents the audit log of who did what
+type AuditLogConfig struct {
+	// Retention is how long audit log entries are kept; 0 keeps them
+	Retention time.Duration
+}
+
+// ActivityConfig represents the activity feeds of users
+type ActivityConfig struct {
+	// Retention is how long activities are kept; 0 keeps them
+	Retention time.Duration
+}
+
+// DashboardConfig represents the admin operations dashboard
+type DashboardConfig struct {
+	// CacheTTL is how long dashboard figures are cached; 0 computes them on every request
+	CacheTTL time.Duration
+}
+
+// ConcurrencyConfig represents in-flight request limits for expensive endpoints. A limit of 0 disables it.
+type ConcurrencyConfig struct {
+	MaxGlobal    int
+	MaxPerClient int
+	RetryAfter   time.Duration
+}
+
+// CompressionConfig represents response compression configuration
+type CompressionConfig struct {
+	Enabled bool
+	Level   int
+	MinSize int
+}
+
+// TimeoutConfig represents request deadlines. A timeout of 0 disables it.
+type TimeoutConfig struct {
+	Request time.Duration
+	Upload  time.Duration
+	// HealthCheck bounds each dependency probe of the readiness check
+	HealthCheck time.Duration
+	// Shutdown bounds the whole graceful shutdown after SIGTERM
+	Shutdown time.Duration
+}
+
+// SentryConfig represents error reporting configuration. Reporting is disabled without a DSN.
+type SentryConfig struct {
+	DSN         string
+	Environment string
+	Release     string
+	SampleRate  float64
+}
+
+// CORSConfig represents cross-origin request configuration
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	// AllowAll accepts every origin and is rejected in production
+	AllowAll bool
+}
+
+// AdminAccessConfig restricts admin, docs and metrics routes to trusted networks. Access is
+// unrestricted when neither CIDRs nor a trusted header are set.
+type AdminAccessConfig struct {
+	AllowedCIDRs       []string
+	TrustedHeader      string
+	TrustedHeaderValue string
+}
+
+// TLSConfig represents HTTPS served directly by the application, with certificate files or
+// automatic Let's Encrypt certificates. HTTPS is off when neither is set.
+type TLSConfig struct {
+	CertFile         string
+	KeyFile          string
+	AutocertDomains  []string
+	AutocertEmail    string
+	AutocertCacheDir string
+	// RedirectPort serves plain HTTP redirects to HTTPS (and ACME challenges) when set
+	RedirectPort string
+}
+
+// Enabled reports whether HTTPS is configured
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertDomains) > 0
+}
+
+// EventsConfig represents live event delivery configuration
+type EventsConfig struct {
+	// HeartbeatInterval keeps idle event streams from being closed by proxies
+	HeartbeatInterval time.Duration
+	// HistorySize is how many recent events per user are kept for reconnecting clients
+	HistorySize int64
+	HistoryTTL  time.Duration
+}
+
+// WebhooksConfig represents the delivery of user events to their webhooks
+type WebhooksConfig struct {
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+	// MaxPerUser is how many webhooks a user may register; 0 doesn't limit them
+	MaxPerUser int
+	// AllowHTTP accepts webhook URLs without TLS, for development
+	AllowHTTP bool
+	// AllowPrivateNetworks lets webhooks reach loopback, private and link-local addresses, which
+	// would otherwise expose internal services to users
+	AllowPrivateNetworks bool
+	// DeliveryRetention is how long the delivery log is kept; 0 keeps it
+	DeliveryRetention time.Duration
+}
+
+// GRPCConfig represents the gRPC API configuration
+type GRPCConfig struct {
+	Enabled bool
+	Port    string
+	// GatewayEnabled serves the RPCs as JSON over HTTP under /rpc
+	GatewayEnabled bool
+}
+
+// StaticConfig represents hosting of a built single-page frontend. An empty Dir disables it.
+type StaticConfig struct {
+	Dir string
+	// AssetsPath holds content-hashed files, which are cached for AssetsMaxAge
+	AssetsPath   string
+	AssetsMaxAge time.Duration
+}
+
+// LogConfig represents application logging
+type LogConfig struct {
+	// Level is a logrus level such as "debug" or "warn". Empty logs debug in development and
+	// info otherwise.
+	Level string
+	// ModuleLevels overrides the level of modules such as "gorm" or "auth"
+	ModuleLevels map[string]string
+	// Format is "text" or "json". Empty is text in development and json otherwise.
+	Format string
+	// Handler writes the lines: "logrus", or "slog" for the format of log/slog
+	Handler string
+	// Outputs are "stdout", "stderr" and "file". Empty is file in production and stdout otherwise.
+	Outputs []string
+	File    string
+	// FileMaxSizeMB and FileRotateInterval of 0 don't rotate the file by size or time
+	FileMaxSizeMB      int
+	FileRotateInterval time.Duration
+	// FileMaxBackups of 0 keeps every rotated file
+	FileMaxBackups int
+	// SampledRoutes are paths of which only SampleRate of the successful requests are logged
+	SampledRoutes []string
+	SampleRate    float64
+}
+
+// TokenCleanupConfig represents the job deleting expired and revoked refresh tokens. An Interval
+// of 0 disables it.
+type TokenCleanupConfig struct {
+	Interval time.Duration
+}
+
+// JobsConfig represents the persistent background job queue and its workers
+type JobsConfig struct {
+	// Workers is how many jobs this instance runs at once; 0 only enqueues jobs
+	Workers      int
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job runs before it is dead-lettered
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled for every further one
+	RetryBackoff time.Duration
+	// Timeout bounds a job run; the job of a crashed worker is picked up again after it
+	Timeout time.Duration
+	// Retention is how long succeeded jobs are kept; 0 keeps them
+	Retention time.Duration
+}
+
+// SchedulerConfig represents the recurring maintenance tasks, which run on the instance elected
+// leader. An interval of 0 disables its task.
+type SchedulerConfig struct {
+	// Enabled lets this instance take part in the leader election and run tasks
+	Enabled bool
+	// LeaderTTL is how long the leadership lasts unless renewed, and so how long it takes another
+	// instance to take over from a leader that died
+	LeaderTTL          time.Duration
+	TrashPurgeInterval time.Duration
+	// TrashRetention is how long soft-deleted users and documents are kept before they are purged
+	TrashRetention    time.Duration
+	StorageGCInterval time.Duration
+	// StorageGCMinAge keeps orphaned files younger than it, which may be uploads in progress
+	StorageGCMinAge time.Duration
+}
+
+// SeedConfig represents the records the seed command, or the server on startup, creates.
+// Seeding is idempotent.
+type SeedConfig struct {
+	// OnStartup seeds every time the server starts
+	OnStartup bool
+	// AdminEmail is the admin user to create or promote, none when empty
+	AdminEmail    string
+	AdminName     string
+	AdminPassword string
+	// DemoData creates demo users sharing DemoPassword
+	DemoData     bool
+	DemoPassword string
 }
 
-// Load loads configuration from environment variables
-func Load() (*Config, error) {
-	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
+// Load loads configuration from environment variables. Settings are taken, in order of
+// precedence, from the process environment, the .env.<profile> file, the .env file, the secrets
+// provider, the YAML or TOML config file at configFile (or CONFIG_FILE, or ./config.yaml) with
+// its config.<profile>.yaml variant merged over it, and finally the defaults. The profile is
+// SERVER_ENV, read from the environment, .env or the base config file.
+func Load(configFile string) (*Config, error) {
+	settings = map[string]Setting{}
+	invalidSettings = nil
+
+	// The base files may choose the profile, so read them before loading any
+	dotenv, err := godotenv.Read(baseDotenvFile)
+	if err != nil {
+		dotenv = map[string]string{}
+	}
+
+	// Settings missing from the environment are read from the config file
+	fileValues = map[string]string{}
+	path := configFilePath(configFile)
+	if path != "" {
+		values, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileValues = values
+	}
+
+	profile := profileName(dotenv)
+
+	loaded, err := loadDotenv(profile)
+	if err != nil {
+		return nil, err
+	}
+	if len(loaded) == 0 {
 		// .env file not found is not an error in production
 		fmt.Println("Warning: .env file not found, using environment variables")
 	}
 
+	// The profile's config file overrides the base one key by key
+	if path == "" {
+		path = defaultConfigFile
+	}
+	if profilePath := profileFilePath(path, profile); profilePath != "" {
+		values, err := loadFile(profilePath)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			fileValues[key] = value
+		}
+	}
+
+	// Then from the secrets provider, whose own settings come from the environment or file
+	secretsConfig := loadSecretsConfig()
+	secretsProvider, err := loadSecrets(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Env:  getEnv("SERVER_ENV", "development"),
+			Port:              getEnv("SERVER_PORT", "8080"),
+			Env:               getEnv("SERVER_ENV", "development"),
+			TrustedProxies:    getListEnv("TRUSTED_PROXIES", nil),
+			TCPEnabled:        getBoolEnv("SERVER_TCP_ENABLED", true),
+			SocketPath:        getEnv("SERVER_SOCKET_PATH", ""),
+			SocketMode:        getFileModeEnv("SERVER_SOCKET_MODE", 0660),
+			SystemdActivation: getBoolEnv("SERVER_SYSTEMD_ACTIVATION", false),
+			ReadTimeout:       getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:      getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:       getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			MaxHeaderBytes:    getIntEnv("SERVER_MAX_HEADER_BYTES", 1<<20),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "gin_boilerplate"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:               getEnv("DB_HOST", "localhost"),
+			Port:               getEnv("DB_PORT", "5432"),
+			User:               getEnv("DB_USER", "postgres"),
+			Password:           getEnv("DB_PASSWORD", "postgres"),
+			DBName:             getEnv("DB_NAME", "gin_boilerplate"),
+			SSLMode:            getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:       getIntEnv("DB_MAX_OPEN_CONNS", 100),
+			MaxIdleConns:       getIntEnv("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime:    getDurationEnv("DB_CONN_MAX_LIFETIME", time.Hour),
+			ConnMaxIdleTime:    getDurationEnv("DB_CONN_MAX_IDLE_TIME", 0),
+			SlowQueryThreshold: getDurationEnv("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", ""),
-			AccessExpiry:  getDurationEnv("JWT_ACCESS_EXPIRY", 15*time.Minute),
-			RefreshExpiry: getDurationEnv("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
+			Secret:           getEnv("JWT_SECRET", ""),
+			PreviousSecrets:  getListEnv("JWT_PREVIOUS_SECRETS", nil),
+			SigningKeyFile:   getEnv("JWT_SIGNING_KEY_FILE", ""),
+			PreviousKeyFiles: getListEnv("JWT_PREVIOUS_KEY_FILES", nil),
+			AccessExpiry:     getDurationEnv("JWT_ACCESS_EXPIRY", 15*time.Minute),
+			RefreshExpiry:    getDurationEnv("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
+			RememberMeExpiry: getDurationEnv("JWT_REMEMBER_ME_EXPIRY", 30*24*time.Hour),
+			SlidingRefresh:   getBoolEnv("JWT_SLIDING_REFRESH", true),
+			Issuer:           getEnv("JWT_ISSUER", ""),
+			Audience:         getEnv("JWT_AUDIENCE", ""),
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+			BcryptCost:        getIntEnv("PASSWORD_BCRYPT_COST", 10),
+			Argon2Memory:      getIntEnv("PASSWORD_ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:  getIntEnv("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getIntEnv("PASSWORD_ARGON2_PARALLELISM", 2),
+			HistorySize:       getIntEnv("PASSWORD_HISTORY_SIZE", 5),
+		},
+		PasswordReset: PasswordResetConfig{
+			URL:      getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			TokenTTL: getDurationEnv("PASSWORD_RESET_TOKEN_TTL", time.Hour),
+		},
+		EmailChange: EmailChangeConfig{
+			URL:            getEnv("EMAIL_CHANGE_URL", "http://localhost:3000/confirm-email-change"),
+			RevertURL:      getEnv("EMAIL_CHANGE_REVERT_URL", "http://localhost:3000/revert-email-change"),
+			TokenTTL:       getDurationEnv("EMAIL_CHANGE_TOKEN_TTL", 24*time.Hour),
+			RollbackWindow: getDurationEnv("EMAIL_CHANGE_ROLLBACK_WINDOW", 72*time.Hour),
+		},
+		EmailVerification: EmailVerificationConfig{
+			URL:      getEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			TokenTTL: getDurationEnv("EMAIL_VERIFICATION_TOKEN_TTL", 24*time.Hour),
+			Required: getBoolEnv("EMAIL_VERIFICATION_REQUIRED", false),
+		},
+		Registration: RegistrationConfig{
+			Mode:           getEnv("REGISTRATION_MODE", "open"),
+			AllowedDomains: getListEnv("REGISTRATION_ALLOWED_DOMAINS", nil),
+			InviteURL:      getEnv("REGISTRATION_INVITE_URL", "http://localhost:3000/accept-invite"),
+			InviteTTL:      getDurationEnv("REGISTRATION_INVITE_TTL", 7*24*time.Hour),
+		},
+		AccountDeletion: AccountDeletionConfig{
+			Mode: getEnv("ACCOUNT_DELETION_MODE", "delete"),
+		},
+		DataExport: DataExportConfig{
+			LinkTTL: getDurationEnv("DATA_EXPORT_LINK_TTL", 72*time.Hour),
+		},
+		Avatar: AvatarConfig{
+			Moderation: getBoolEnv("AVATAR_MODERATION", false),
+		},
+		DisposableEmails: DisposableEmailsConfig{
+			Blocked:         getBoolEnv("DISPOSABLE_EMAILS_BLOCKED", true),
+			ListURL:         getEnv("DISPOSABLE_EMAILS_LIST_URL", ""),
+			RefreshInterval: getDurationEnv("DISPOSABLE_EMAILS_REFRESH_INTERVAL", 24*time.Hour),
+			ExtraDomains:    getListEnv("DISPOSABLE_EMAILS_EXTRA_DOMAINS", nil),
+			AllowedDomains:  getListEnv("DISPOSABLE_EMAILS_ALLOWED_DOMAINS", nil),
+		},
+		OAuth: OAuthConfig{
+			StateTTL:     getDurationEnv("OAUTH_STATE_TTL", 10*time.Minute),
+			CallbackMode: getEnv("OAUTH_CALLBACK_MODE", "json"),
+			SuccessURL:   getEnv("OAUTH_SUCCESS_URL", "http://localhost:3000/oauth/success"),
+			CookieDomain: getEnv("OAUTH_COOKIE_DOMAIN", ""),
+			CookieSecure: getBoolEnv("OAUTH_COOKIE_SECURE", true),
+		},
+		AuthCookies: AuthCookiesConfig{
+			Enabled:  getBoolEnv("AUTH_COOKIES_ENABLED", false),
+			Domain:   getEnv("AUTH_COOKIES_DOMAIN", ""),
+			Secure:   getBoolEnv("AUTH_COOKIES_SECURE", true),
+			SameSite: getEnv("AUTH_COOKIES_SAME_SITE", "strict"),
 		},
 		Google: GoogleConfig{
 			ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 			ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
 			RedirectURL:  getEnv("GOOGLE_REDIRECT_URL", ""),
+
+			MobileClientIDs: getListEnv("GOOGLE_MOBILE_CLIENT_IDS", nil),
+		},
+		OIDC: OIDCConfig{
+			Name:         getEnv("OIDC_NAME", "oidc"),
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:       getListEnv("OIDC_SCOPES", []string{"openid", "email", "profile"}),
+		},
+		Captcha: CaptchaConfig{
+			Provider:  getEnv("CAPTCHA_PROVIDER", ""),
+			SecretKey: getEnv("CAPTCHA_SECRET_KEY", ""),
+			MinScore:  getFloatEnv("CAPTCHA_MIN_SCORE", 0.5),
+			Timeout:   getDurationEnv("CAPTCHA_TIMEOUT", 5*time.Second),
+			Endpoints: getListEnv("CAPTCHA_ENDPOINTS", []string{"register", "login", "forgot_password"}),
+		},
+		SMS: SMSConfig{
+			Provider:         getEnv("SMS_PROVIDER", ""),
+			From:             getEnv("SMS_FROM", ""),
+			TwilioAccountSID: getEnv("SMS_TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:  getEnv("SMS_TWILIO_AUTH_TOKEN", ""),
+			VonageAPIKey:     getEnv("SMS_VONAGE_API_KEY", ""),
+			VonageAPISecret:  getEnv("SMS_VONAGE_API_SECRET", ""),
+			Timeout:          getDurationEnv("SMS_TIMEOUT", 10*time.Second),
+			OTPTTL:           getDurationEnv("SMS_OTP_TTL", 5*time.Minute),
+			OTPMaxAttempts:   getIntEnv("SMS_OTP_MAX_ATTEMPTS", 5),
 		},
 		S3: S3Config{
 			Endpoint:        getEnv("S3_ENDPOINT", ""),
@@ -106,14 +923,191 @@ func Load() (*Config, error) {
 			Region:          getEnv("S3_REGION", "us-east-1"),
 			Bucket:          getEnv("S3_BUCKET", ""),
 			UseSSL:          getBoolEnv("S3_USE_SSL", true),
+			DialTimeout:     getDurationEnv("S3_DIAL_TIMEOUT", 30*time.Second),
+			Timeout:         getDurationEnv("S3_TIMEOUT", 0),
+		},
+		Search: SearchConfig{
+			Backend:            getEnv("SEARCH_BACKEND", "postgres"),
+			OpenSearchURL:      getEnv("SEARCH_OPENSEARCH_URL", ""),
+			OpenSearchIndex:    getEnv("SEARCH_OPENSEARCH_INDEX", "documents"),
+			OpenSearchUsername: getEnv("SEARCH_OPENSEARCH_USERNAME", ""),
+			OpenSearchPassword: getEnv("SEARCH_OPENSEARCH_PASSWORD", ""),
+			Timeout:            getDurationEnv("SEARCH_TIMEOUT", 5*time.Second),
+		},
+		VirusScan: VirusScanConfig{
+			Mode:          getEnv("VIRUS_SCAN_MODE", "off"),
+			ClamAVAddress: getEnv("VIRUS_SCAN_CLAMAV_ADDRESS", "tcp://localhost:3310"),
+			Timeout:       getDurationEnv("VIRUS_SCAN_TIMEOUT", 30*time.Second),
+		},
+		Email: EmailConfig{
+			Driver:         getEnv("EMAIL_DRIVER", "log"),
+			From:           getEnv("EMAIL_FROM", "no-reply@localhost"),
+			FromName:       getEnv("EMAIL_FROM_NAME", "Gin Boilerplate"),
+			TemplateDir:    getEnv("EMAIL_TEMPLATE_DIR", ""),
+			Timeout:        getDurationEnv("EMAIL_TIMEOUT", 30*time.Second),
+			UnsubscribeURL: getEnv("EMAIL_UNSUBSCRIBE_URL", "http://localhost:3000/unsubscribe"),
+			SMTPHost:       getEnv("EMAIL_SMTP_HOST", ""),
+			SMTPPort:       getIntEnv("EMAIL_SMTP_PORT", 587),
+			SMTPUsername:   getEnv("EMAIL_SMTP_USERNAME", ""),
+			SMTPPassword:   getEnv("EMAIL_SMTP_PASSWORD", ""),
+			SMTPTLS:        getEnv("EMAIL_SMTP_TLS", "starttls"),
+			SESRegion:      getEnv("EMAIL_SES_REGION", "us-east-1"),
+			SendGridAPIKey: getEnv("EMAIL_SENDGRID_API_KEY", ""),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getIntEnv("REDIS_DB", 0),
-			PoolSize: getIntEnv("REDIS_POOL_SIZE", 10),
+			Host:         getEnv("REDIS_HOST", "localhost"),
+			Port:         getEnv("REDIS_PORT", "6379"),
+			Password:     getEnv("REDIS_PASSWORD", ""),
+			DB:           getIntEnv("REDIS_DB", 0),
+			PoolSize:     getIntEnv("REDIS_POOL_SIZE", 10),
+			MinIdleConns: getIntEnv("REDIS_MIN_IDLE_CONNS", 0),
+			DialTimeout:  getDurationEnv("REDIS_DIAL_TIMEOUT", 5*time.Second),
+			ReadTimeout:  getDurationEnv("REDIS_READ_TIMEOUT", 3*time.Second),
+			WriteTimeout: getDurationEnv("REDIS_WRITE_TIMEOUT", 3*time.Second),
+			PoolTimeout:  getDurationEnv("REDIS_POOL_TIMEOUT", 4*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerWindow:   getIntEnv("RATE_LIMIT_REQUESTS", 100),
+			WindowDuration:      getDurationEnv("RATE_LIMIT_WINDOW", time.Minute),
+			APIKeyDefaultLimit:  getIntEnv("API_KEY_DEFAULT_RATE_LIMIT", 1000),
+			APIKeyDefaultWindow: getDurationEnv("API_KEY_DEFAULT_RATE_WINDOW", time.Minute),
+			FailureMode:         getEnv("RATE_LIMIT_FAILURE_MODE", "open"),
+			FailClosedRoutes:    getListEnv("RATE_LIMIT_FAIL_CLOSED_ROUTES", []string{"login"}),
+		},
+		Quota: QuotaConfig{
+			RequestsPerDay:        getInt64Env("QUOTA_REQUESTS_PER_DAY", 10000),
+			UploadsPerMonth:       getInt64Env("QUOTA_UPLOADS_PER_MONTH", 500),
+			DownloadBytesPerMonth: getInt64Env("QUOTA_DOWNLOAD_BYTES_PER_MONTH", 10*1024*1024*1024),
+			RollupInterval:        getDurationEnv("QUOTA_ROLLUP_INTERVAL", 5*time.Minute),
+		},
+		Concurrency: ConcurrencyConfig{
+			MaxGlobal:    getIntEnv("CONCURRENCY_MAX_GLOBAL", 50),
+			MaxPerClient: getIntEnv("CONCURRENCY_MAX_PER_CLIENT", 3),
+			RetryAfter:   getDurationEnv("CONCURRENCY_RETRY_AFTER", 5*time.Second),
+		},
+		LoginThrottle: LoginThrottleConfig{
+			MaxAttemptsPerAccount:   getIntEnv("LOGIN_MAX_ATTEMPTS_PER_ACCOUNT", 20),
+			MaxAttemptsPerAccountIP: getIntEnv("LOGIN_MAX_ATTEMPTS_PER_ACCOUNT_IP", 5),
+			MaxAttemptsPerIP:        getIntEnv("LOGIN_MAX_ATTEMPTS_PER_IP", 50),
+			Window:                  getDurationEnv("LOGIN_THROTTLE_WINDOW", 15*time.Minute),
+			LockoutDuration:         getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		SecurityEvents: SecurityEventsConfig{
+			EmailAlerts:   getBoolEnv("SECURITY_EVENT_EMAIL_ALERTS", true),
+			Retention:     getDurationEnv("SECURITY_EVENT_RETENTION", 90*24*time.Hour),
+			GeoIPDatabase: getEnv("SECURITY_EVENT_GEOIP_DATABASE", ""),
+		},
+		AuditLog: AuditLogConfig{
+			Retention: getDurationEnv("AUDIT_LOG_RETENTION", 365*24*time.Hour),
+		},
+		Activity: ActivityConfig{
+			Retention: getDurationEnv("ACTIVITY_RETENTION", 90*24*time.Hour),
+		},
+		Dashboard: DashboardConfig{
+			CacheTTL: getDurationEnv("DASHBOARD_CACHE_TTL", time.Minute),
 		},
+		Compression: CompressionConfig{
+			Enabled: getBoolEnv("COMPRESSION_ENABLED", true),
+			Level:   getIntEnv("COMPRESSION_LEVEL", -1),
+			MinSize: getIntEnv("COMPRESSION_MIN_SIZE", 1024),
+		},
+		Timeout: TimeoutConfig{
+			Request:     getDurationEnv("REQUEST_TIMEOUT", 30*time.Second),
+			Upload:      getDurationEnv("UPLOAD_REQUEST_TIMEOUT", 5*time.Minute),
+			HealthCheck: getDurationEnv("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			Shutdown:    getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+		},
+		Sentry: SentryConfig{
+			DSN:         getEnv("SENTRY_DSN", ""),
+			Environment: getEnv("SENTRY_ENVIRONMENT", getEnv("SERVER_ENV", "development")),
+			Release:     getEnv("SENTRY_RELEASE", ""),
+			SampleRate:  getFloatEnv("SENTRY_SAMPLE_RATE", 1.0),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   getListEnv("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:8080"}),
+			AllowedMethods:   getListEnv("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}),
+			AllowedHeaders:   getListEnv("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Requested-With", "X-CSRF-Token", "X-Request-ID", "X-Captcha-Token"}),
+			ExposedHeaders:   getListEnv("CORS_EXPOSED_HEADERS", []string{"Content-Length", "X-Total-Count", "Link", "X-Request-ID", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "API-Version"}),
+			AllowCredentials: getBoolEnv("CORS_ALLOW_CREDENTIALS", true),
+			MaxAge:           getDurationEnv("CORS_MAX_AGE", 12*time.Hour),
+			AllowAll:         getBoolEnv("CORS_ALLOW_ALL", false),
+		},
+		AdminAccess: AdminAccessConfig{
+			AllowedCIDRs:       getListEnv("ADMIN_ALLOWED_CIDRS", nil),
+			TrustedHeader:      getEnv("ADMIN_TRUSTED_HEADER", ""),
+			TrustedHeaderValue: getEnv("ADMIN_TRUSTED_HEADER_VALUE", ""),
+		},
+		TLS: TLSConfig{
+			CertFile:         getEnv("TLS_CERT_FILE", ""),
+			KeyFile:          getEnv("TLS_KEY_FILE", ""),
+			AutocertDomains:  getListEnv("TLS_AUTOCERT_DOMAINS", nil),
+			AutocertEmail:    getEnv("TLS_AUTOCERT_EMAIL", ""),
+			AutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE_DIR", "certs"),
+			RedirectPort:     getEnv("TLS_REDIRECT_PORT", ""),
+		},
+		Events: EventsConfig{
+			HeartbeatInterval: getDurationEnv("EVENTS_HEARTBEAT_INTERVAL", 15*time.Second),
+			HistorySize:       getInt64Env("EVENTS_HISTORY_SIZE", 100),
+			HistoryTTL:        getDurationEnv("EVENTS_HISTORY_TTL", 24*time.Hour),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:              getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxPerUser:           getIntEnv("WEBHOOK_MAX_PER_USER", 10),
+			AllowHTTP:            getBoolEnv("WEBHOOK_ALLOW_HTTP", false),
+			AllowPrivateNetworks: getBoolEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+			DeliveryRetention:    getDurationEnv("WEBHOOK_DELIVERY_RETENTION", 30*24*time.Hour),
+		},
+		GRPC: GRPCConfig{
+			Enabled:        getBoolEnv("GRPC_ENABLED", false),
+			Port:           getEnv("GRPC_PORT", "9090"),
+			GatewayEnabled: getBoolEnv("GRPC_GATEWAY_ENABLED", false),
+		},
+		Static: StaticConfig{
+			Dir:          getEnv("STATIC_DIR", ""),
+			AssetsPath:   getEnv("STATIC_ASSETS_PATH", "/assets/"),
+			AssetsMaxAge: getDurationEnv("STATIC_ASSETS_MAX_AGE", 365*24*time.Hour),
+		},
+		Log: LogConfig{
+			Level:              getEnv("LOG_LEVEL", ""),
+			Format:             getEnv("LOG_FORMAT", ""),
+			Handler:            getEnv("LOG_HANDLER", logging.HandlerLogrus),
+			Outputs:            getListEnv("LOG_OUTPUTS", nil),
+			File:               getEnv("LOG_FILE", "app.log"),
+			FileMaxSizeMB:      getIntEnv("LOG_FILE_MAX_SIZE_MB", 100),
+			FileRotateInterval: getDurationEnv("LOG_FILE_ROTATE_INTERVAL", 0),
+			FileMaxBackups:     getIntEnv("LOG_FILE_MAX_BACKUPS", 7),
+			SampledRoutes:      getListEnv("LOG_SAMPLED_ROUTES", []string{"/healthz", "/readyz", "/metrics"}),
+			SampleRate:         getFloatEnv("LOG_SAMPLE_RATE", 0.01),
+		},
+		Seed: SeedConfig{
+			OnStartup:     getBoolEnv("SEED_ON_STARTUP", false),
+			AdminEmail:    getEnv("SEED_ADMIN_EMAIL", ""),
+			AdminName:     getEnv("SEED_ADMIN_NAME", "Admin"),
+			AdminPassword: getEnv("SEED_ADMIN_PASSWORD", ""),
+			DemoData:      getBoolEnv("SEED_DEMO_DATA", false),
+			DemoPassword:  getEnv("SEED_DEMO_PASSWORD", "demo-password"),
+		},
+		TokenCleanup: TokenCleanupConfig{
+			Interval: getDurationEnv("TOKEN_CLEANUP_INTERVAL", time.Hour),
+		},
+		Jobs: JobsConfig{
+			Workers:      getIntEnv("JOB_WORKERS", 4),
+			PollInterval: getDurationEnv("JOB_POLL_INTERVAL", time.Second),
+			MaxAttempts:  getIntEnv("JOB_MAX_ATTEMPTS", 5),
+			RetryBackoff: getDurationEnv("JOB_RETRY_BACKOFF", 10*time.Second),
+			Timeout:      getDurationEnv("JOB_TIMEOUT", 5*time.Minute),
+			Retention:    getDurationEnv("JOB_RETENTION", 7*24*time.Hour),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:            getBoolEnv("SCHEDULER_ENABLED", true),
+			LeaderTTL:          getDurationEnv("SCHEDULER_LEADER_TTL", 30*time.Second),
+			TrashPurgeInterval: getDurationEnv("SCHEDULER_TRASH_PURGE_INTERVAL", 24*time.Hour),
+			TrashRetention:     getDurationEnv("SCHEDULER_TRASH_RETENTION", 30*24*time.Hour),
+			StorageGCInterval:  getDurationEnv("SCHEDULER_STORAGE_GC_INTERVAL", 0),
+			StorageGCMinAge:    getDurationEnv("SCHEDULER_STORAGE_GC_MIN_AGE", 24*time.Hour),
+		},
+		Secrets:         secretsConfig,
+		secretsProvider: secretsProvider,
 	}
 
 	// Build DSN
@@ -127,79 +1121,1252 @@ func Load() (*Config, error) {
 		config.Database.SSLMode,
 	)
 
-	// Validate required fields
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+	config.Log.ModuleLevels = loadModuleLevels()
+
+	// Policies default to the global limit and window
+	config.RateLimit.Policies = loadRateLimitPolicies(config.RateLimit.RequestsPerWindow, config.RateLimit.WindowDuration)
+	config.RateLimit.Routes = loadRateLimitRoutes()
+	config.RateLimit.Tiers = loadRateLimitTiers(config.RateLimit.RequestsPerWindow, config.RateLimit.WindowDuration)
+
+	// Report unparsable values along with every other problem, not one per restart
+	if err := errors.Join(append(invalidSettings, config.Validate())...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
 	}
 
+	config.settings = sortedSettings()
 	return config, nil
 }
 
-// Validate validates the configuration
+// minJWTSecretLength is the shortest accepted JWT_SECRET, the key size of HS256
+const minJWTSecretLength = 32
+
+// exampleJWTSecret is the JWT_SECRET of .env.example and config.example.yaml
+const exampleJWTSecret = "your-super-secret-key-change-this-in-production"
+
+// Validate validates the configuration. It reports every problem found rather than the first.
 func (c *Config) Validate() error {
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
+	errs := []error{
+		c.Server.validate(),
+		c.Database.validate(),
+		c.JWT.validate(c.IsProduction()),
+		c.Password.validate(),
+		c.PasswordReset.validate(),
+		c.EmailChange.validate(),
+		c.EmailVerification.validate(),
+		c.Registration.validate(),
+		c.AccountDeletion.validate(),
+		c.DataExport.validate(),
+		c.DisposableEmails.validate(),
+		c.OAuth.validate(),
+		c.AuthCookies.validate(),
+		c.Google.validate(),
+		c.OIDC.validate(),
+		c.Captcha.validate(),
+		c.SMS.validate(),
+		c.S3.validate(),
+		c.Search.validate(),
+		c.VirusScan.validate(),
+		c.Email.validate(),
+		c.Redis.validate(),
+		c.RateLimit.validate(),
+		c.Quota.validate(),
+		c.Concurrency.validate(),
+		c.LoginThrottle.validate(),
+		c.SecurityEvents.validate(),
+		c.AuditLog.validate(),
+		c.Activity.validate(),
+		c.Dashboard.validate(),
+		c.Compression.validate(),
+		c.Timeout.validate(),
+		c.Sentry.validate(),
+		c.CORS.validate(c.IsProduction()),
+		c.AdminAccess.validate(),
+		c.TLS.validate(),
+		c.Events.validate(),
+		c.Webhooks.validate(),
+		c.GRPC.validate(c.Server),
+		c.Static.validate(),
+		c.Secrets.validate(),
+		c.Log.validate(),
+		c.Seed.validate(c.IsProduction()),
+		c.TokenCleanup.validate(),
+		c.Jobs.validate(),
+		c.Scheduler.validate(),
 	}
 
-	if c.Google.ClientID == "" {
-		return fmt.Errorf("GOOGLE_CLIENT_ID is required")
+	// errors.Join drops the sections without errors
+	return errors.Join(errs...)
+}
+
+// validate checks the listeners and that the server listens somewhere
+func (c *ServerConfig) validate() error {
+	errs := []error{}
+
+	switch c.Env {
+	case "development", "test", "staging", "production":
+	default:
+		errs = append(errs, fmt.Errorf("SERVER_ENV must be development, test, staging or production, got %q", c.Env))
 	}
 
-	if c.Google.ClientSecret == "" {
-		return fmt.Errorf("GOOGLE_CLIENT_SECRET is required")
+	if c.TCPEnabled {
+		errs = append(errs, validatePort("SERVER_PORT", c.Port))
+	} else if c.SocketPath == "" && !c.SystemdActivation {
+		errs = append(errs, fmt.Errorf("SERVER_TCP_ENABLED=false requires SERVER_SOCKET_PATH or SERVER_SYSTEMD_ACTIVATION"))
 	}
 
-	if c.Google.RedirectURL == "" {
-		return fmt.Errorf("GOOGLE_REDIRECT_URL is required")
+	if c.ReadTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_READ_TIMEOUT must be positive"))
+	}
+	if c.WriteTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_WRITE_TIMEOUT must be positive"))
+	}
+	if c.IdleTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_IDLE_TIMEOUT must be positive"))
+	}
+	if c.MaxHeaderBytes < 4096 {
+		errs = append(errs, fmt.Errorf("SERVER_MAX_HEADER_BYTES must be at least 4096"))
 	}
 
-	return nil
-}
+	for _, proxy := range c.TrustedProxies {
+		if err := validateNetwork(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXIES entry: %w", err))
+		}
+	}
 
-// IsDevelopment checks if the application is running in development mode
-func (c *Config) IsDevelopment() bool {
-	return c.Server.Env == "development"
+	return errors.Join(errs...)
 }
 
-// IsProduction checks if the application is running in production mode
-func (c *Config) IsProduction() bool {
-	return c.Server.Env == "production"
-}
+// validate checks the connection settings
+func (c *DatabaseConfig) validate() error {
+	errs := []error{
+		validateRequired("DB_HOST", c.Host),
+		validatePort("DB_PORT", c.Port),
+		validateRequired("DB_USER", c.User),
+		validateRequired("DB_NAME", c.DBName),
+	}
 
-// getEnv gets environment variable with default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("DB_SSLMODE must be a PostgreSQL sslmode such as disable or verify-full, got %q", c.SSLMode))
+	}
+
+	if c.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS must not be negative"))
 	}
-	return defaultValue
+	if c.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS"))
+	}
+	if c.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONN_MAX_LIFETIME must not be negative"))
+	}
+	if c.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONN_MAX_IDLE_TIME must not be negative"))
+	}
+	if c.SlowQueryThreshold < 0 {
+		errs = append(errs, fmt.Errorf("DB_SLOW_QUERY_THRESHOLD must not be negative"))
+	}
+
+	return errors.Join(errs...)
 }
 
-// getDurationEnv gets environment variable as duration with default value
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// validate checks the signing secrets and that refresh tokens outlive access tokens
+func (c *JWTConfig) validate(production bool) error {
+	errs := []error{}
+
+	switch {
+	case c.Secret == "":
+		errs = append(errs, fmt.Errorf("JWT_SECRET is required"))
+	case len(c.Secret) < minJWTSecretLength:
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters", minJWTSecretLength))
+	case production && c.Secret == exampleJWTSecret:
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be changed from the example value in production"))
+	}
+
+	for i, secret := range c.PreviousSecrets {
+		switch {
+		case len(secret) < minJWTSecretLength:
+			errs = append(errs, fmt.Errorf("JWT_PREVIOUS_SECRETS entry %d must be at least %d characters", i+1, minJWTSecretLength))
+		case secret == c.Secret:
+			errs = append(errs, fmt.Errorf("JWT_PREVIOUS_SECRETS entry %d must differ from JWT_SECRET", i+1))
+		}
+	}
+
+	for _, path := range append([]string{c.SigningKeyFile}, c.PreviousKeyFiles...) {
+		if path == "" {
+			continue
 		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("JWT key file %s is not readable: %w", path, err))
+		}
+	}
+
+	if c.AccessExpiry <= 0 {
+		errs = append(errs, fmt.Errorf("JWT_ACCESS_EXPIRY must be positive"))
+	}
+	if c.RefreshExpiry <= c.AccessExpiry {
+		errs = append(errs, fmt.Errorf("JWT_REFRESH_EXPIRY must be longer than JWT_ACCESS_EXPIRY"))
 	}
-	return defaultValue
+	if c.RememberMeExpiry != 0 && c.RememberMeExpiry < c.RefreshExpiry {
+		errs = append(errs, fmt.Errorf("JWT_REMEMBER_ME_EXPIRY must be 0 or at least JWT_REFRESH_EXPIRY"))
+	}
+
+	return errors.Join(errs...)
 }
 
-// getIntEnv gets environment variable as integer with default value
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+// validate checks the algorithm and that its parameters are within what the algorithm accepts
+func (c *PasswordConfig) validate() error {
+	errs := []error{}
+
+	switch c.HashAlgorithm {
+	case "bcrypt":
+		// bcrypt.MinCost and bcrypt.MaxCost
+		if c.BcryptCost < 4 || c.BcryptCost > 31 {
+			errs = append(errs, fmt.Errorf("PASSWORD_BCRYPT_COST must be between 4 and 31"))
+		}
+	case "argon2id":
+		if c.Argon2Parallelism < 1 || c.Argon2Parallelism > 255 {
+			errs = append(errs, fmt.Errorf("PASSWORD_ARGON2_PARALLELISM must be between 1 and 255"))
 		}
+		if c.Argon2Memory < 8*c.Argon2Parallelism {
+			errs = append(errs, fmt.Errorf("PASSWORD_ARGON2_MEMORY must be at least 8 KiB per thread"))
+		}
+		if c.Argon2Iterations < 1 {
+			errs = append(errs, fmt.Errorf("PASSWORD_ARGON2_ITERATIONS must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PASSWORD_HASH_ALGORITHM must be bcrypt or argon2id, got %q", c.HashAlgorithm))
+	}
+
+	if c.HistorySize < 0 {
+		errs = append(errs, fmt.Errorf("PASSWORD_HISTORY_SIZE must not be negative"))
 	}
-	return defaultValue
+
+	return errors.Join(errs...)
 }
 
-// getBoolEnv gets environment variable as boolean with default value
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
+// validate checks the reset page URL and that reset links can be used
+func (c *PasswordResetConfig) validate() error {
+	errs := []error{validateURL("PASSWORD_RESET_URL", c.URL)}
+	if c.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("PASSWORD_RESET_TOKEN_TTL must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the confirmation and revert page URLs and that their links can be used
+func (c *EmailChangeConfig) validate() error {
+	errs := []error{
+		validateURL("EMAIL_CHANGE_URL", c.URL),
+		validateURL("EMAIL_CHANGE_REVERT_URL", c.RevertURL),
+	}
+	if c.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("EMAIL_CHANGE_TOKEN_TTL must be positive"))
+	}
+	if c.RollbackWindow <= 0 {
+		errs = append(errs, fmt.Errorf("EMAIL_CHANGE_ROLLBACK_WINDOW must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the verification page URL and that verification links can be used
+func (c *EmailVerificationConfig) validate() error {
+	errs := []error{validateURL("EMAIL_VERIFICATION_URL", c.URL)}
+	if c.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("EMAIL_VERIFICATION_TOKEN_TTL must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the registration mode, the allowed domains, the invitation page URL and that
+// invitations can be used
+func (c *RegistrationConfig) validate() error {
+	errs := []error{validateURL("REGISTRATION_INVITE_URL", c.InviteURL)}
+	switch c.Mode {
+	case "open", "invite_only", "closed":
+	default:
+		errs = append(errs, fmt.Errorf("REGISTRATION_MODE must be open, invite_only or closed"))
+	}
+	errs = append(errs, validateDomains("REGISTRATION_ALLOWED_DOMAINS", c.AllowedDomains))
+	if c.InviteTTL <= 0 {
+		errs = append(errs, fmt.Errorf("REGISTRATION_INVITE_TTL must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the account deletion mode
+func (c *AccountDeletionConfig) validate() error {
+	switch c.Mode {
+	case "delete", "anonymize":
+		return nil
+	default:
+		return fmt.Errorf("ACCOUNT_DELETION_MODE must be delete or anonymize")
+	}
+}
+
+// validate checks that download links work, for at most the 7 days S3 presigned URLs can last
+func (c *DataExportConfig) validate() error {
+	if c.LinkTTL <= 0 || c.LinkTTL > 7*24*time.Hour {
+		return fmt.Errorf("DATA_EXPORT_LINK_TTL must be positive and at most 168h")
+	}
+	return nil
+}
+
+// validate checks the list URL, the refresh interval and the domains
+func (c *DisposableEmailsConfig) validate() error {
+	var errs []error
+	if c.ListURL != "" {
+		errs = append(errs, validateURL("DISPOSABLE_EMAILS_LIST_URL", c.ListURL))
+		if c.RefreshInterval <= 0 {
+			errs = append(errs, fmt.Errorf("DISPOSABLE_EMAILS_REFRESH_INTERVAL must be positive"))
 		}
 	}
-	return defaultValue
-}
\ No newline at end of file
+	errs = append(errs, validateDomains("DISPOSABLE_EMAILS_EXTRA_DOMAINS", c.ExtraDomains))
+	errs = append(errs, validateDomains("DISPOSABLE_EMAILS_ALLOWED_DOMAINS", c.AllowedDomains))
+	return errors.Join(errs...)
+}
+
+// validate checks the callback mode and that sign-ins can be completed
+func (c *OAuthConfig) validate() error {
+	var errs []error
+	if c.StateTTL <= 0 {
+		errs = append(errs, fmt.Errorf("OAUTH_STATE_TTL must be positive"))
+	}
+
+	switch c.CallbackMode {
+	case "json":
+	case "cookie":
+		errs = append(errs, validateURL("OAUTH_SUCCESS_URL", c.SuccessURL))
+	default:
+		errs = append(errs, fmt.Errorf("OAUTH_CALLBACK_MODE must be json or cookie"))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the SameSite attribute, which browsers only accept as none on secure cookies
+func (c *AuthCookiesConfig) validate() error {
+	switch c.SameSite {
+	case "strict", "lax":
+	case "none":
+		if !c.Secure {
+			return fmt.Errorf("AUTH_COOKIES_SAME_SITE=none needs AUTH_COOKIES_SECURE")
+		}
+	default:
+		return fmt.Errorf("AUTH_COOKIES_SAME_SITE must be strict, lax or none")
+	}
+	return nil
+}
+
+// validate checks the OAuth client settings. Google sign-in is optional, but a client ID needs
+// the rest of its settings.
+func (c *GoogleConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	errs := []error{
+		validateRequired("GOOGLE_CLIENT_ID", c.ClientID),
+		validateRequired("GOOGLE_CLIENT_SECRET", c.ClientSecret),
+		validateRequired("GOOGLE_REDIRECT_URL", c.RedirectURL),
+	}
+
+	if c.RedirectURL != "" {
+		errs = append(errs, validateURL("GOOGLE_REDIRECT_URL", c.RedirectURL))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the OIDC client settings. OIDC sign-in is optional, but an issuer needs the
+// rest of its settings.
+func (c *OIDCConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	errs := []error{
+		validateURL("OIDC_ISSUER_URL", c.IssuerURL),
+		validateRequired("OIDC_CLIENT_ID", c.ClientID),
+		validateRequired("OIDC_CLIENT_SECRET", c.ClientSecret),
+		validateRequired("OIDC_REDIRECT_URL", c.RedirectURL),
+	}
+
+	if c.RedirectURL != "" {
+		errs = append(errs, validateURL("OIDC_REDIRECT_URL", c.RedirectURL))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the CAPTCHA provider and endpoints. CAPTCHAs are optional, but a provider
+// needs its secret key.
+func (c *CaptchaConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	errs := []error{validateRequired("CAPTCHA_SECRET_KEY", c.SecretKey)}
+
+	if c.Provider != "recaptcha" && c.Provider != "hcaptcha" {
+		errs = append(errs, fmt.Errorf("CAPTCHA_PROVIDER must be recaptcha or hcaptcha"))
+	}
+	if c.MinScore < 0 || c.MinScore > 1 {
+		errs = append(errs, fmt.Errorf("CAPTCHA_MIN_SCORE must be between 0 and 1"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("CAPTCHA_TIMEOUT must be positive"))
+	}
+	for _, endpoint := range c.Endpoints {
+		switch endpoint {
+		case "register", "login", "forgot_password":
+		default:
+			errs = append(errs, fmt.Errorf("CAPTCHA_ENDPOINTS has unknown endpoint %q, use register, login or forgot_password", endpoint))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the bucket and the static credentials the client signs requests with
+func (c *S3Config) validate() error {
+	errs := []error{
+		validateRequired("S3_ACCESS_KEY_ID", c.AccessKeyID),
+		validateRequired("S3_SECRET_ACCESS_KEY", c.SecretAccessKey),
+		validateRequired("S3_REGION", c.Region),
+		validateRequired("S3_BUCKET", c.Bucket),
+	}
+
+	if c.Endpoint != "" {
+		errs = append(errs, validateURL("S3_ENDPOINT", c.Endpoint))
+	}
+
+	if c.DialTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("S3_DIAL_TIMEOUT must be positive"))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("S3_TIMEOUT must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the backend and the cluster of the opensearch backend
+func (c *SearchConfig) validate() error {
+	errs := []error{}
+	switch c.Backend {
+	case "postgres":
+	case "opensearch":
+		errs = append(errs,
+			validateURL("SEARCH_OPENSEARCH_URL", c.OpenSearchURL),
+			validateRequired("SEARCH_OPENSEARCH_INDEX", c.OpenSearchIndex),
+		)
+	default:
+		errs = append(errs, fmt.Errorf("SEARCH_BACKEND must be postgres or opensearch"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("SEARCH_TIMEOUT must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the mode and the clamd address when files are scanned
+func (c *VirusScanConfig) validate() error {
+	errs := []error{}
+	switch c.Mode {
+	case "off":
+	case "best_effort", "required":
+		if !strings.HasPrefix(c.ClamAVAddress, "tcp://") && !strings.HasPrefix(c.ClamAVAddress, "unix://") {
+			errs = append(errs, fmt.Errorf("VIRUS_SCAN_CLAMAV_ADDRESS must start with tcp:// or unix://"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("VIRUS_SCAN_MODE must be off, best_effort or required"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("VIRUS_SCAN_TIMEOUT must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the sender address and the settings of the driver
+func (c *EmailConfig) validate() error {
+	errs := []error{}
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		errs = append(errs, fmt.Errorf("EMAIL_FROM must be an email address, got %q", c.From))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("EMAIL_TIMEOUT must be positive"))
+	}
+
+	switch c.Driver {
+	case "log":
+	case "smtp":
+		errs = append(errs, validateRequired("EMAIL_SMTP_HOST", c.SMTPHost))
+		if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("EMAIL_SMTP_PORT must be a port number, got %d", c.SMTPPort))
+		}
+		switch c.SMTPTLS {
+		case "starttls", "implicit", "none":
+		default:
+			errs = append(errs, fmt.Errorf("EMAIL_SMTP_TLS must be starttls, implicit or none, got %q", c.SMTPTLS))
+		}
+	case "ses":
+		errs = append(errs, validateRequired("EMAIL_SES_REGION", c.SESRegion))
+	case "sendgrid":
+		errs = append(errs, validateRequired("EMAIL_SENDGRID_API_KEY", c.SendGridAPIKey))
+	default:
+		errs = append(errs, fmt.Errorf("EMAIL_DRIVER must be log, smtp, ses or sendgrid, got %q", c.Driver))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the connection settings
+func (c *RedisConfig) validate() error {
+	errs := []error{
+		validateRequired("REDIS_HOST", c.Host),
+		validatePort("REDIS_PORT", c.Port),
+	}
+
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_DB must not be negative"))
+	}
+	if c.PoolSize <= 0 {
+		errs = append(errs, fmt.Errorf("REDIS_POOL_SIZE must be positive"))
+	}
+	if c.MinIdleConns < 0 || c.MinIdleConns > c.PoolSize {
+		errs = append(errs, fmt.Errorf("REDIS_MIN_IDLE_CONNS must be between 0 and REDIS_POOL_SIZE"))
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"REDIS_DIAL_TIMEOUT", c.DialTimeout},
+		{"REDIS_READ_TIMEOUT", c.ReadTimeout},
+		{"REDIS_WRITE_TIMEOUT", c.WriteTimeout},
+		{"REDIS_POOL_TIMEOUT", c.PoolTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", timeout.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the limits and windows
+func (c *RateLimitConfig) validate() error {
+	errs := []error{}
+
+	if c.RequestsPerWindow <= 0 {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_REQUESTS must be positive"))
+	}
+	if c.WindowDuration <= 0 {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_WINDOW must be positive"))
+	}
+	if c.APIKeyDefaultLimit <= 0 {
+		errs = append(errs, fmt.Errorf("API_KEY_DEFAULT_RATE_LIMIT must be positive"))
+	}
+	if c.APIKeyDefaultWindow <= 0 {
+		errs = append(errs, fmt.Errorf("API_KEY_DEFAULT_RATE_WINDOW must be positive"))
+	}
+	if c.FailureMode != "open" && c.FailureMode != "closed" {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_FAILURE_MODE must be open or closed, got %q", c.FailureMode))
+	}
+
+	policies := make(map[string]bool, len(c.Policies))
+	for _, policy := range c.Policies {
+		if policies[policy.Name] {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_POLICIES lists %q twice", policy.Name))
+		}
+		policies[policy.Name] = true
+		errs = append(errs, policy.validate())
+	}
+
+	routes := make(map[string]bool, len(c.Routes))
+	for _, route := range c.Routes {
+		pattern := route.Method + " " + route.Path
+		if route.Method != "*" && !validHTTPMethod(route.Method) {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES entry %q must start with an HTTP method or *", pattern))
+		}
+		if routes[pattern] {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES maps %q twice", pattern))
+		}
+		routes[pattern] = true
+		if !policies[route.Policy] {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES maps %q to %q, which is not in RATE_LIMIT_POLICIES", pattern, route.Policy))
+		}
+	}
+
+	for _, tier := range c.Tiers {
+		errs = append(errs, tier.validate())
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the limits, where 0 means unlimited
+func (c *QuotaConfig) validate() error {
+	errs := []error{}
+
+	if c.RequestsPerDay < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_REQUESTS_PER_DAY must not be negative"))
+	}
+	if c.UploadsPerMonth < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_UPLOADS_PER_MONTH must not be negative"))
+	}
+	if c.DownloadBytesPerMonth < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_DOWNLOAD_BYTES_PER_MONTH must not be negative"))
+	}
+	if c.RollupInterval < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_ROLLUP_INTERVAL must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the limits, where 0 disables them
+func (c *ConcurrencyConfig) validate() error {
+	errs := []error{}
+
+	if c.MaxGlobal < 0 {
+		errs = append(errs, fmt.Errorf("CONCURRENCY_MAX_GLOBAL must not be negative"))
+	}
+	if c.MaxPerClient < 0 {
+		errs = append(errs, fmt.Errorf("CONCURRENCY_MAX_PER_CLIENT must not be negative"))
+	}
+	if c.RetryAfter <= 0 {
+		errs = append(errs, fmt.Errorf("CONCURRENCY_RETRY_AFTER must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the limits, where 0 disables them
+func (c *LoginThrottleConfig) validate() error {
+	errs := []error{}
+
+	if c.MaxAttemptsPerAccount < 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_MAX_ATTEMPTS_PER_ACCOUNT must not be negative"))
+	}
+	if c.MaxAttemptsPerAccountIP < 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_MAX_ATTEMPTS_PER_ACCOUNT_IP must not be negative"))
+	}
+	if c.MaxAttemptsPerIP < 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_MAX_ATTEMPTS_PER_IP must not be negative"))
+	}
+	if c.Window <= 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_THROTTLE_WINDOW must be positive"))
+	}
+	if c.LockoutDuration <= 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCKOUT_DURATION must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the credentials of the provider and the limits of codes
+func (c *SMSConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	errs := []error{}
+	switch c.Provider {
+	case "log":
+	case "twilio":
+		errs = append(errs,
+			validateRequired("SMS_FROM", c.From),
+			validateRequired("SMS_TWILIO_ACCOUNT_SID", c.TwilioAccountSID),
+			validateRequired("SMS_TWILIO_AUTH_TOKEN", c.TwilioAuthToken),
+		)
+	case "vonage":
+		errs = append(errs,
+			validateRequired("SMS_FROM", c.From),
+			validateRequired("SMS_VONAGE_API_KEY", c.VonageAPIKey),
+			validateRequired("SMS_VONAGE_API_SECRET", c.VonageAPISecret),
+		)
+	default:
+		errs = append(errs, fmt.Errorf("SMS_PROVIDER must be log, twilio or vonage"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("SMS_TIMEOUT must be positive"))
+	}
+	if c.OTPTTL < time.Minute {
+		errs = append(errs, fmt.Errorf("SMS_OTP_TTL must be at least 1m"))
+	}
+	if c.OTPMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("SMS_OTP_MAX_ATTEMPTS must be at least 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the retention, where 0 keeps the events
+func (c *SecurityEventsConfig) validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("SECURITY_EVENT_RETENTION must not be negative")
+	}
+	return nil
+}
+
+// validate checks the retention, where 0 keeps the entries
+func (c *AuditLogConfig) validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("AUDIT_LOG_RETENTION must not be negative")
+	}
+	return nil
+}
+
+// validate checks the retention, where 0 keeps the activities
+func (c *ActivityConfig) validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("ACTIVITY_RETENTION must not be negative")
+	}
+	return nil
+}
+
+// validate checks the cache TTL, where 0 disables caching
+func (c *DashboardConfig) validate() error {
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("DASHBOARD_CACHE_TTL must not be negative")
+	}
+	return nil
+}
+
+// validate checks the compress/flate level, from HuffmanOnly (-2) to BestCompression (9)
+func (c *CompressionConfig) validate() error {
+	errs := []error{}
+
+	if c.Level < -2 || c.Level > 9 {
+		errs = append(errs, fmt.Errorf("COMPRESSION_LEVEL must be between -2 and 9"))
+	}
+	if c.MinSize < 0 {
+		errs = append(errs, fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the deadlines, where 0 disables the request ones
+func (c *TimeoutConfig) validate() error {
+	errs := []error{}
+
+	if c.Request < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT must not be negative"))
+	}
+	if c.Upload < 0 {
+		errs = append(errs, fmt.Errorf("UPLOAD_REQUEST_TIMEOUT must not be negative"))
+	}
+	if c.HealthCheck <= 0 {
+		errs = append(errs, fmt.Errorf("HEALTH_CHECK_TIMEOUT must be positive"))
+	}
+	if c.Shutdown <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the DSN and sample rate
+func (c *SentryConfig) validate() error {
+	errs := []error{}
+
+	if c.DSN != "" {
+		errs = append(errs, validateURL("SENTRY_DSN", c.DSN))
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("SENTRY_SAMPLE_RATE must be between 0 and 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the CORS origins so a typo fails at startup instead of silently blocking browsers
+func (c *CORSConfig) validate(production bool) error {
+	errs := []error{}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("CORS_MAX_AGE must not be negative"))
+	}
+
+	if c.AllowAll {
+		if production {
+			errs = append(errs, fmt.Errorf("CORS_ALLOW_ALL must not be enabled in production"))
+		}
+		return errors.Join(errs...)
+	}
+
+	if len(c.AllowedOrigins) == 0 {
+		errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS must list at least one origin"))
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q: %w", origin, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the admin networks and that a trusted header always comes with its value
+func (c *AdminAccessConfig) validate() error {
+	errs := []error{}
+
+	for _, cidr := range c.AllowedCIDRs {
+		if err := validateNetwork(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid ADMIN_ALLOWED_CIDRS entry: %w", err))
+		}
+	}
+
+	if c.TrustedHeader != "" && c.TrustedHeaderValue == "" {
+		errs = append(errs, fmt.Errorf("ADMIN_TRUSTED_HEADER_VALUE is required when ADMIN_TRUSTED_HEADER is set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that exactly one certificate source is configured
+func (c *TLSConfig) validate() error {
+	errs := []error{}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+
+	if c.CertFile != "" && len(c.AutocertDomains) > 0 {
+		errs = append(errs, fmt.Errorf("use either TLS_CERT_FILE/TLS_KEY_FILE or TLS_AUTOCERT_DOMAINS, not both"))
+	}
+
+	if c.RedirectPort != "" {
+		if !c.Enabled() {
+			errs = append(errs, fmt.Errorf("TLS_REDIRECT_PORT requires TLS to be enabled"))
+		}
+		errs = append(errs, validatePort("TLS_REDIRECT_PORT", c.RedirectPort))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the event stream timing and history
+func (c *EventsConfig) validate() error {
+	errs := []error{}
+
+	if c.HeartbeatInterval <= 0 {
+		errs = append(errs, fmt.Errorf("EVENTS_HEARTBEAT_INTERVAL must be positive"))
+	}
+	if c.HistorySize < 0 {
+		errs = append(errs, fmt.Errorf("EVENTS_HISTORY_SIZE must not be negative"))
+	}
+	if c.HistoryTTL <= 0 {
+		errs = append(errs, fmt.Errorf("EVENTS_HISTORY_TTL must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the delivery timeout, limit and retention
+func (c *WebhooksConfig) validate() error {
+	errs := []error{}
+
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_TIMEOUT must be positive"))
+	}
+	if c.MaxPerUser < 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_PER_USER must not be negative"))
+	}
+	if c.DeliveryRetention < 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_DELIVERY_RETENTION must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the gRPC port, which must differ from the HTTP one, and that the gateway has
+// a server to forward to
+func (c *GRPCConfig) validate(server ServerConfig) error {
+	errs := []error{}
+
+	if c.GatewayEnabled && !c.Enabled {
+		errs = append(errs, fmt.Errorf("GRPC_GATEWAY_ENABLED requires GRPC_ENABLED"))
+	}
+
+	if c.Enabled {
+		errs = append(errs, validatePort("GRPC_PORT", c.Port))
+		if server.TCPEnabled && c.Port == server.Port {
+			errs = append(errs, fmt.Errorf("GRPC_PORT must differ from SERVER_PORT"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that the frontend build exists so a wrong path fails at startup
+func (c *StaticConfig) validate() error {
+	if c.Dir == "" {
+		return nil
+	}
+
+	errs := []error{}
+
+	if _, err := os.Stat(filepath.Join(c.Dir, "index.html")); err != nil {
+		errs = append(errs, fmt.Errorf("STATIC_DIR must contain index.html: %w", err))
+	}
+
+	if c.AssetsPath != "" && (!strings.HasPrefix(c.AssetsPath, "/") || !strings.HasSuffix(c.AssetsPath, "/")) {
+		errs = append(errs, fmt.Errorf("STATIC_ASSETS_PATH must start and end with /"))
+	}
+
+	if c.AssetsMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("STATIC_ASSETS_MAX_AGE must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the level is one logrus knows
+func (c *LogConfig) validate() error {
+	errs := []error{}
+
+	if c.Level != "" && !validLogLevel(c.Level) {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be trace, debug, info, warn, error, fatal or panic, got %q", c.Level))
+	}
+
+	switch c.Format {
+	case "", logging.FormatText, logging.FormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be text or json, got %q", c.Format))
+	}
+
+	switch c.Handler {
+	case logging.HandlerLogrus, logging.HandlerSlog:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_HANDLER must be logrus or slog, got %q", c.Handler))
+	}
+
+	for _, output := range c.Outputs {
+		switch output {
+		case logging.OutputStdout, logging.OutputStderr:
+		case logging.OutputFile:
+			if c.File == "" {
+				errs = append(errs, fmt.Errorf("LOG_FILE is required when LOG_OUTPUTS has file"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("LOG_OUTPUTS must only have stdout, stderr and file, got %q", output))
+		}
+	}
+
+	if c.FileMaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_MAX_SIZE_MB must not be negative"))
+	}
+	if c.FileRotateInterval < 0 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_ROTATE_INTERVAL must not be negative"))
+	}
+	if c.FileMaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_MAX_BACKUPS must not be negative"))
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("LOG_SAMPLE_RATE must be between 0 and 1"))
+	}
+
+	for module, level := range c.ModuleLevels {
+		if !logging.IsModule(module) {
+			errs = append(errs, fmt.Errorf("LOG_MODULE_LEVELS has unknown module %q, expected one of %s", module, strings.Join(logging.Modules(), ", ")))
+		}
+		if !validLogLevel(level) {
+			errs = append(errs, fmt.Errorf("LOG_MODULE_LEVELS level of %s must be trace, debug, info, warn, error, fatal or panic, got %q", module, level))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the interval isn't negative
+func (c *TokenCleanupConfig) validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("TOKEN_CLEANUP_INTERVAL must not be negative")
+	}
+	return nil
+}
+
+// validate checks the job workers and retry policy
+func (c *JobsConfig) validate() error {
+	errs := []error{}
+
+	if c.Workers < 0 {
+		errs = append(errs, fmt.Errorf("JOB_WORKERS must not be negative"))
+	}
+	if c.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_POLL_INTERVAL must be positive"))
+	}
+	if c.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_MAX_ATTEMPTS must be positive"))
+	}
+	if c.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_RETRY_BACKOFF must be positive"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_TIMEOUT must be positive"))
+	}
+	if c.Retention < 0 {
+		errs = append(errs, fmt.Errorf("JOB_RETENTION must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the leader TTL and that the intervals and ages aren't negative
+func (c *SchedulerConfig) validate() error {
+	errs := []error{}
+
+	if c.LeaderTTL < 3*time.Second {
+		errs = append(errs, fmt.Errorf("SCHEDULER_LEADER_TTL must be at least 3s"))
+	}
+	if c.TrashPurgeInterval < 0 {
+		errs = append(errs, fmt.Errorf("SCHEDULER_TRASH_PURGE_INTERVAL must not be negative"))
+	}
+	if c.TrashRetention < 0 {
+		errs = append(errs, fmt.Errorf("SCHEDULER_TRASH_RETENTION must not be negative"))
+	}
+	if c.StorageGCInterval < 0 {
+		errs = append(errs, fmt.Errorf("SCHEDULER_STORAGE_GC_INTERVAL must not be negative"))
+	}
+	if c.StorageGCMinAge < time.Hour {
+		errs = append(errs, fmt.Errorf("SCHEDULER_STORAGE_GC_MIN_AGE must be at least 1h"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that startup seeding has something to seed and that demo data stays out of
+// production
+func (c *SeedConfig) validate(production bool) error {
+	errs := []error{}
+
+	if c.OnStartup && c.AdminEmail == "" && !c.DemoData {
+		errs = append(errs, fmt.Errorf("SEED_ON_STARTUP requires SEED_ADMIN_EMAIL or SEED_DEMO_DATA"))
+	}
+	if c.AdminEmail != "" && c.AdminPassword == "" {
+		errs = append(errs, fmt.Errorf("SEED_ADMIN_PASSWORD is required with SEED_ADMIN_EMAIL"))
+	}
+	if c.DemoData && production {
+		errs = append(errs, fmt.Errorf("SEED_DEMO_DATA must not be enabled in production"))
+	}
+	if c.DemoData && c.DemoPassword == "" {
+		errs = append(errs, fmt.Errorf("SEED_DEMO_PASSWORD is required with SEED_DEMO_DATA"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validLogLevel reports whether a level is a logrus level name
+func validLogLevel(level string) bool {
+	switch level {
+	case "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic":
+		return true
+	}
+	return false
+}
+
+// loadModuleLevels reads LOG_MODULE_LEVELS, whose entries look like "gorm=warn"
+func loadModuleLevels() map[string]string {
+	levels := map[string]string{}
+	for _, entry := range getListEnv("LOG_MODULE_LEVELS", nil) {
+		module, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			invalidSetting("LOG_MODULE_LEVELS", entry, `entries such as "gorm=warn"`)
+			continue
+		}
+		levels[strings.TrimSpace(module)] = strings.TrimSpace(level)
+	}
+	return levels
+}
+
+// validateRequired checks that a setting is set
+func validateRequired(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	return nil
+}
+
+// validatePort checks that a setting is a TCP port number
+func validatePort(name, value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be a port between 1 and 65535, got %q", name, value)
+	}
+	return nil
+}
+
+// validateURL checks that a setting is an absolute http or https URL
+func validateURL(name, value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL", name)
+	}
+	return nil
+}
+
+// validateDomains checks that every entry of a list is a domain name rather than an email or URL
+func validateDomains(name string, domains []string) error {
+	var errs []error
+	for _, domain := range domains {
+		if strings.ContainsAny(domain, "@/ ") || !strings.Contains(domain, ".") {
+			errs = append(errs, fmt.Errorf("%s entry %q is not a domain, e.g. example.com", name, domain))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateNetwork accepts a CIDR ("10.0.0.0/8") or a single IP address
+func validateNetwork(value string) error {
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("%q is not a valid CIDR", value)
+		}
+		return nil
+	}
+
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("%q is not a valid IP address", value)
+	}
+	return nil
+}
+
+// validateOrigin accepts "scheme://host[:port]", where host may start with "*." to match any subdomain
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return fmt.Errorf("use CORS_ALLOW_ALL to allow every origin")
+	}
+
+	parsed, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if parsed.Host == "" || strings.Contains(parsed.Host, "*") {
+		return fmt.Errorf("host must be a domain, optionally prefixed with *.")
+	}
+	if parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return fmt.Errorf("origin must not contain a path, query or credentials")
+	}
+
+	return nil
+}
+
+// IsDevelopment checks if the application is running in development mode
+func (c *Config) IsDevelopment() bool {
+	return c.Server.Env == "development"
+}
+
+// IsProduction checks if the application is running in production mode
+func (c *Config) IsProduction() bool {
+	return c.Server.Env == "production"
+}
+
+// getEnv gets environment variable, or its config file setting, with default value
+func getEnv(key, defaultValue string) string {
+	if value := getValue(key); value != "" {
+		return recordSetting(key, value)
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getDurationEnv gets environment variable as duration with default value
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := getValue(key); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
+			return recordSetting(key, duration)
+		}
+		invalidSetting(key, value, "a duration such as 30s or 5m")
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getIntEnv gets environment variable as integer with default value
+func getIntEnv(key string, defaultValue int) int {
+	if value := getValue(key); value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err == nil {
+			return recordSetting(key, intValue)
+		}
+		invalidSetting(key, value, "an integer")
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getInt64Env gets environment variable as 64-bit integer with default value
+func getInt64Env(key string, defaultValue int64) int64 {
+	if value := getValue(key); value != "" {
+		intValue, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return recordSetting(key, intValue)
+		}
+		invalidSetting(key, value, "an integer")
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getFloatEnv gets environment variable as float with default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := getValue(key); value != "" {
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return recordSetting(key, floatValue)
+		}
+		invalidSetting(key, value, "a number")
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getFileModeEnv gets environment variable as octal file permissions ("0660") with default value
+func getFileModeEnv(key string, defaultValue os.FileMode) os.FileMode {
+	if value := getValue(key); value != "" {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err == nil {
+			return recordSetting(key, os.FileMode(mode)&os.ModePerm)
+		}
+		invalidSetting(key, value, "octal permissions such as 0660")
+	}
+	return recordSetting(key, defaultValue)
+}
+
+// getListEnv gets comma-separated environment variable as a list with default value
+func getListEnv(key string, defaultValue []string) []string {
+	value, ok := lookupEnv(key)
+	if !ok {
+		return recordSetting(key, defaultValue)
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return recordSetting(key, items)
+}
+
+// getBoolEnv gets environment variable as boolean with default value
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := getValue(key); value != "" {
+		boolValue, err := strconv.ParseBool(value)
+		if err == nil {
+			return recordSetting(key, boolValue)
+		}
+		invalidSetting(key, value, "true or false")
+	}
+	return recordSetting(key, defaultValue)
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read when it exists and no other file is given
+const defaultConfigFile = "config.yaml"
+
+// fileValues holds the settings of the config file keyed by environment variable name. They
+// are consulted for variables missing from the environment.
+var fileValues = map[string]string{}
+
+// lookupEnv returns the value of an environment variable, falling back to the secrets provider
+// and then to the config file. A value referring to an AWS secret is replaced by the secret.
+func lookupEnv(key string) (string, bool) {
+	if value, ok := referenceValues[key]; ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	if value, ok := secretValues[key]; ok {
+		return value, true
+	}
+	value, ok := fileValues[key]
+	return value, ok
+}
+
+// getValue returns the value of an environment variable, or of the secret or config file
+// setting for it when the variable is unset or empty
+func getValue(key string) string {
+	if value, ok := referenceValues[key]; ok {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := secretValues[key]; value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// configFilePath picks the config file: the given path, then CONFIG_FILE, then config.yaml in
+// the working directory if it exists. An empty result means no file.
+func configFilePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+	if _, err := os.Stat(defaultConfigFile); err == nil {
+		return defaultConfigFile
+	}
+	return ""
+}
+
+// loadFile reads a YAML or TOML config file, picked by extension, into values keyed by
+// environment variable name. Nested keys are joined with underscores and upper-cased, so
+// "db: {host: x}" sets DB_HOST and "rate_limit: {requests: 100}" sets RATE_LIMIT_REQUESTS.
+// Lists become comma-separated values.
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := flatten(values, "", tree); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten adds the leaves of a parsed config tree to values
+func flatten(values map[string]string, prefix string, node interface{}) error {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+			if err := flatten(values, name, node[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, len(node))
+		for i, item := range node {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("%s must be a list of plain values", prefix)
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		if prefix == "" {
+			return fmt.Errorf("top level must be a mapping")
+		}
+		values[prefix] = fmt.Sprint(node)
+	}
+	return nil
+}
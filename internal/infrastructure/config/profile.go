@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// defaultProfile is used when SERVER_ENV is set nowhere
+const defaultProfile = "development"
+
+// baseDotenvFile holds the settings shared by every profile
+const baseDotenvFile = ".env"
+
+// profileName picks the profile from SERVER_ENV in the environment, then the .env file, then
+// the config file
+func profileName(dotenv map[string]string) string {
+	if profile := os.Getenv("SERVER_ENV"); profile != "" {
+		return profile
+	}
+	if profile := dotenv["SERVER_ENV"]; profile != "" {
+		return profile
+	}
+	if profile := fileValues["SERVER_ENV"]; profile != "" {
+		return profile
+	}
+	return defaultProfile
+}
+
+// loadDotenv loads .env.<profile> and then .env into the environment. Variables already set
+// are kept, so the process environment wins over the profile file, which wins over .env.
+// It returns the files loaded.
+func loadDotenv(profile string) ([]string, error) {
+	loaded := []string{}
+	for _, path := range []string{baseDotenvFile + "." + profile, baseDotenvFile} {
+		if err := godotenv.Load(path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		loaded = append(loaded, path)
+	}
+	return loaded, nil
+}
+
+// profileFilePath returns the profile variant of a config file, "config.production.yaml" for
+// "config.yaml", or "" when it doesn't exist
+func profileFilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	profilePath := strings.TrimSuffix(path, ext) + "." + profile + ext
+	if _, err := os.Stat(profilePath); err != nil {
+		return ""
+	}
+	return profilePath
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultRateLimitRoutes are the routes limited out of the box, each by a policy of its own name
+// except for the password reset and email verification routes, which share one per flow, the
+// email change links, which share the email verification policy, and data exports
+var defaultRateLimitRoutes = []string{
+	"POST /auth/register=register",
+	"POST /auth/login=login",
+	"POST /auth/forgot-password=password_reset",
+	"POST /auth/reset-password=password_reset",
+	"POST /auth/verify-email=email_verification",
+	"POST /auth/resend-verification=email_verification",
+	"POST /auth/confirm-email-change=email_verification",
+	"POST /auth/revert-email-change=email_verification",
+	"POST /users/avatar=avatar_upload",
+	"POST /documents/upload=document_upload",
+	"POST /users/me/export=data_export",
+}
+
+// rateLimitPolicyDefault is the limit of a policy unless its variables set another
+type rateLimitPolicyDefault struct {
+	limit  int
+	window time.Duration
+	key    string
+}
+
+// rateLimitPolicyDefaults are the policies limited harder than the global limit out of the box.
+// Each data export assembles a ZIP of the user's data, so users get a few a day.
+var rateLimitPolicyDefaults = map[string]rateLimitPolicyDefault{
+	"data_export": {limit: 3, window: 24 * time.Hour, key: "user"},
+}
+
+// loadRateLimitPolicies reads the policies named in RATE_LIMIT_POLICIES. Each is configured
+// by RATE_LIMIT_POLICY_<NAME>_ALGORITHM, _LIMIT, _WINDOW and _KEY, which default to a fixed
+// window of the global limit and window counted per client, or to rateLimitPolicyDefaults.
+func loadRateLimitPolicies(defaultLimit int, defaultWindow time.Duration) []RateLimitPolicyConfig {
+	names := getListEnv("RATE_LIMIT_POLICIES", []string{"register", "login", "password_reset", "email_verification", "avatar_upload", "document_upload", "data_export"})
+
+	policies := make([]RateLimitPolicyConfig, 0, len(names))
+	for _, name := range names {
+		defaults, ok := rateLimitPolicyDefaults[name]
+		if !ok {
+			defaults = rateLimitPolicyDefault{limit: defaultLimit, window: defaultWindow, key: "client"}
+		}
+
+		prefix := rateLimitPolicyPrefix(name)
+		policies = append(policies, RateLimitPolicyConfig{
+			Name:      name,
+			Algorithm: getEnv(prefix+"ALGORITHM", "fixed_window"),
+			Limit:     getIntEnv(prefix+"LIMIT", defaults.limit),
+			Window:    getDurationEnv(prefix+"WINDOW", defaults.window),
+			Key:       getEnv(prefix+"KEY", defaults.key),
+		})
+	}
+	return policies
+}
+
+// rateLimitPolicyPrefix returns the start of the variable names configuring a policy
+func rateLimitPolicyPrefix(name string) string {
+	return "RATE_LIMIT_POLICY_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// rateLimitTierDefaults are the limits of the paid and internal rate limit tiers per window,
+// multiples of the global limit. The free tier gets the global limit, like anonymous traffic.
+var rateLimitTierDefaults = []struct {
+	name     string
+	multiple int
+}{
+	{name: "free", multiple: 1},
+	{name: "pro", multiple: 10},
+	{name: "internal", multiple: 100},
+}
+
+// loadRateLimitTiers reads the limits of the rate limit tiers from RATE_LIMIT_TIER_<NAME>_LIMIT
+// and _WINDOW, which default to a multiple of the global limit per the global window
+func loadRateLimitTiers(defaultLimit int, defaultWindow time.Duration) []RateLimitTierConfig {
+	tiers := make([]RateLimitTierConfig, 0, len(rateLimitTierDefaults))
+	for _, defaults := range rateLimitTierDefaults {
+		prefix := rateLimitTierPrefix(defaults.name)
+		tiers = append(tiers, RateLimitTierConfig{
+			Name:   defaults.name,
+			Limit:  getIntEnv(prefix+"LIMIT", defaultLimit*defaults.multiple),
+			Window: getDurationEnv(prefix+"WINDOW", defaultWindow),
+		})
+	}
+	return tiers
+}
+
+// rateLimitTierPrefix returns the start of the variable names configuring a tier
+func rateLimitTierPrefix(name string) string {
+	return "RATE_LIMIT_TIER_" + strings.ToUpper(name) + "_"
+}
+
+// loadRateLimitRoutes reads RATE_LIMIT_ROUTES, whose entries look like "POST /auth/login=login"
+func loadRateLimitRoutes() []RateLimitRouteConfig {
+	entries := getListEnv("RATE_LIMIT_ROUTES", defaultRateLimitRoutes)
+
+	routes := make([]RateLimitRouteConfig, 0, len(entries))
+	for _, entry := range entries {
+		route, ok := parseRateLimitRoute(entry)
+		if !ok {
+			invalidSetting("RATE_LIMIT_ROUTES", entry, `entries such as "POST /auth/login=login"`)
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// parseRateLimitRoute splits a "METHOD /path=policy" entry
+func parseRateLimitRoute(entry string) (RateLimitRouteConfig, bool) {
+	pattern, policy, ok := strings.Cut(entry, "=")
+	if !ok {
+		return RateLimitRouteConfig{}, false
+	}
+	method, path, ok := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !ok {
+		return RateLimitRouteConfig{}, false
+	}
+
+	route := RateLimitRouteConfig{
+		Method: strings.ToUpper(method),
+		Path:   strings.TrimSpace(path),
+		Policy: strings.TrimSpace(policy),
+	}
+	if !strings.HasPrefix(route.Path, "/") || route.Policy == "" {
+		return RateLimitRouteConfig{}, false
+	}
+	return route, true
+}
+
+// validate checks the algorithm, limit, window and key of a policy
+func (p *RateLimitPolicyConfig) validate() error {
+	errs := []error{}
+	prefix := rateLimitPolicyPrefix(p.Name)
+
+	if p.Algorithm != "fixed_window" && p.Algorithm != "sliding_window" {
+		errs = append(errs, fmt.Errorf("%sALGORITHM must be fixed_window or sliding_window, got %q", prefix, p.Algorithm))
+	}
+	if p.Limit <= 0 {
+		errs = append(errs, fmt.Errorf("%sLIMIT must be positive", prefix))
+	}
+	if p.Window <= 0 {
+		errs = append(errs, fmt.Errorf("%sWINDOW must be positive", prefix))
+	}
+	if p.Key != "client" && p.Key != "ip" && p.Key != "user" {
+		errs = append(errs, fmt.Errorf("%sKEY must be client, ip or user, got %q", prefix, p.Key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the limit and window of a tier
+func (t *RateLimitTierConfig) validate() error {
+	errs := []error{}
+	prefix := rateLimitTierPrefix(t.Name)
+
+	if t.Limit <= 0 {
+		errs = append(errs, fmt.Errorf("%sLIMIT must be positive", prefix))
+	}
+	if t.Window <= 0 {
+		errs = append(errs, fmt.Errorf("%sWINDOW must be positive", prefix))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validHTTPMethod reports whether a route can be registered with the method
+func validHTTPMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/secrets"
+)
+
+// secretValues holds the secrets fetched from the secrets provider keyed by environment
+// variable name. They are consulted for variables missing from the environment, before the
+// config file.
+var secretValues = map[string]string{}
+
+// referenceValues holds the resolved values of settings that refer to AWS secrets, keyed by
+// environment variable name. They replace the reference wherever it was set.
+var referenceValues = map[string]string{}
+
+// SecretsConfig represents the external secret store. An empty Provider reads secrets from the
+// environment and config file only.
+type SecretsConfig struct {
+	// Provider is "" or "vault"
+	Provider string
+	// RefreshInterval re-reads the secrets and renews the provider credentials. 0 disables it.
+	RefreshInterval time.Duration
+	Vault           VaultConfig
+	AWS             AWSSecretsConfig
+}
+
+// VaultConfig represents the HashiCorp Vault connection
+type VaultConfig struct {
+	Addr      string
+	Token     string
+	RoleID    string
+	SecretID  string
+	AuthMount string
+	// SecretPath is the KV secret holding the settings, e.g. "secret/data/ginfinity"
+	SecretPath string
+	Namespace  string
+}
+
+// AWSSecretsConfig represents how settings referring to AWS Secrets Manager ("awssm://") or SSM
+// Parameter Store ("ssm://") are resolved
+type AWSSecretsConfig struct {
+	// Region overrides the region of the default AWS configuration
+	Region   string
+	CacheTTL time.Duration
+}
+
+// loadSecretsConfig reads the secrets provider settings, which can't come from the provider itself
+func loadSecretsConfig() SecretsConfig {
+	return SecretsConfig{
+		Provider:        getEnv("SECRETS_PROVIDER", ""),
+		RefreshInterval: getDurationEnv("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+		Vault: VaultConfig{
+			Addr:       getEnv("VAULT_ADDR", ""),
+			Token:      getEnv("VAULT_TOKEN", ""),
+			RoleID:     getEnv("VAULT_ROLE_ID", ""),
+			SecretID:   getEnv("VAULT_SECRET_ID", ""),
+			AuthMount:  getEnv("VAULT_AUTH_MOUNT", "approle"),
+			SecretPath: getEnv("VAULT_SECRET_PATH", ""),
+			Namespace:  getEnv("VAULT_NAMESPACE", ""),
+		},
+		AWS: AWSSecretsConfig{
+			Region:   getEnv("AWS_SECRETS_REGION", ""),
+			CacheTTL: getDurationEnv("AWS_SECRETS_CACHE_TTL", 5*time.Minute),
+		},
+	}
+}
+
+// newProvider creates the configured secrets provider, or nil when none is configured
+func (c *SecretsConfig) newProvider() (secrets.Provider, error) {
+	switch c.Provider {
+	case "":
+		return nil, nil
+	case "vault":
+		provider, err := secrets.NewVault(secrets.VaultConfig{
+			Addr:      c.Vault.Addr,
+			Token:     c.Vault.Token,
+			RoleID:    c.Vault.RoleID,
+			SecretID:  c.Vault.SecretID,
+			AuthMount: c.Vault.AuthMount,
+			Path:      c.Vault.SecretPath,
+			Namespace: c.Vault.Namespace,
+			Timeout:   10 * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid Vault configuration: %w", err)
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("SECRETS_PROVIDER must be empty or vault, got %q", c.Provider)
+	}
+}
+
+// validate checks the refresh timing. The provider settings are checked when it is created.
+func (c *SecretsConfig) validate() error {
+	errs := []error{}
+
+	if c.RefreshInterval < 0 {
+		errs = append(errs, fmt.Errorf("SECRETS_REFRESH_INTERVAL must not be negative"))
+	}
+	if c.AWS.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("AWS_SECRETS_CACHE_TTL must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// loadSecrets fetches the secrets from the configured provider into secretValues, then resolves
+// the settings referring to AWS secrets into referenceValues. It returns a provider fetching
+// both again, or nil when there is neither.
+func loadSecrets(config SecretsConfig) (secrets.Provider, error) {
+	secretValues = map[string]string{}
+	referenceValues = map[string]string{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	providers := []secrets.Provider{}
+
+	provider, err := config.newProvider()
+	if err != nil {
+		return nil, err
+	}
+	if provider != nil {
+		values, err := provider.Fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch secrets from %s: %w", provider.Name(), err)
+		}
+		secretValues = values
+		providers = append(providers, provider)
+	}
+
+	// Any layer may hold a reference, including the secrets just fetched
+	references := map[string]string{}
+	for _, key := range settingNames() {
+		if value, _ := lookupEnv(key); secrets.IsAWSReference(value) {
+			references[key] = value
+		}
+	}
+	if len(references) > 0 {
+		resolver, err := secrets.NewAWSResolver(ctx, secrets.AWSConfig{
+			Region:   config.AWS.Region,
+			CacheTTL: config.AWS.CacheTTL,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		awsReferences := secrets.NewAWSReferences(resolver, references)
+		values, err := awsReferences.Fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve AWS secrets: %w", err)
+		}
+		referenceValues = values
+		providers = append(providers, awsReferences)
+	}
+
+	return secrets.Combine(providers...), nil
+}
+
+// settingNames returns the names set in the environment, the secrets provider or the config file
+func settingNames() []string {
+	names := []string{}
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		names = append(names, name)
+	}
+	for name := range secretValues {
+		names = append(names, name)
+	}
+	for name := range fileValues {
+		names = append(names, name)
+	}
+	return names
+}
+
+// SecretsProvider returns the secrets provider the configuration was loaded from, or nil
+func (c *Config) SecretsProvider() secrets.Provider {
+	return c.secretsProvider
+}
+
+// SecretValues returns a copy of the secrets the configuration was loaded from, as the secrets
+// provider returns them
+func (c *Config) SecretValues() map[string]string {
+	values := make(map[string]string, len(secretValues)+len(referenceValues))
+	for key, value := range secretValues {
+		values[key] = value
+	}
+	for key, value := range referenceValues {
+		values[key] = value
+	}
+	return values
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces the value of a sensitive setting in the summary
+const redactedValue = "[redacted]"
+
+// sensitiveNameParts mark the settings whose value is never shown, matching whole words of the
+// name so SECRETS_PROVIDER isn't redacted
+var sensitiveNameParts = []string{"SECRET", "PREVIOUS_SECRETS", "PASSWORD", "TOKEN", "DSN", "ACCESS_KEY", "ROLE_ID", "HEADER_VALUE", "SENDGRID_API_KEY"}
+
+// settings holds every setting read by the last Load, keyed by environment variable name
+var settings = map[string]Setting{}
+
+// invalidSettings holds the values the last Load couldn't parse and replaced by their default
+var invalidSettings []error
+
+// Setting is the effective value of a setting and where it was read from: "env" (including
+// .env), "secrets", "aws", "file" or "default"
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// String formats the setting for logs
+func (s Setting) String() string {
+	return fmt.Sprintf("%s (%s)", s.Value, s.Source)
+}
+
+// Summary returns the effective settings sorted by name, with sensitive values redacted
+func (c *Config) Summary() []Setting {
+	summary := make([]Setting, len(c.settings))
+	for i, setting := range c.settings {
+		if setting.Value != "" && isSensitive(setting.Name) {
+			setting.Value = redactedValue
+		}
+		summary[i] = setting
+	}
+	return summary
+}
+
+// recordSetting records the effective value of a setting and returns it
+func recordSetting[T any](key string, value T) T {
+	var formatted string
+	switch v := any(value).(type) {
+	case []string:
+		formatted = strings.Join(v, ",")
+	case os.FileMode:
+		formatted = fmt.Sprintf("%04o", uint32(v))
+	default:
+		formatted = fmt.Sprint(value)
+	}
+
+	settings[key] = Setting{Name: key, Value: formatted, Source: settingSource(key)}
+	return value
+}
+
+// invalidSetting records a value that can't be parsed
+func invalidSetting(key, value, expected string) {
+	if isSensitive(key) {
+		value = redactedValue
+	}
+	invalidSettings = append(invalidSettings, fmt.Errorf("%s must be %s, got %q", key, expected, value))
+}
+
+// settingSource names the layer a setting is read from, following getValue
+func settingSource(key string) string {
+	if _, ok := referenceValues[key]; ok {
+		return "aws"
+	}
+	if os.Getenv(key) != "" {
+		return "env"
+	}
+	if secretValues[key] != "" {
+		return "secrets"
+	}
+	if fileValues[key] != "" {
+		return "file"
+	}
+	return "default"
+}
+
+// sortedSettings returns the recorded settings sorted by name
+func sortedSettings() []Setting {
+	sorted := make([]Setting, 0, len(settings))
+	for _, setting := range settings {
+		sorted = append(sorted, setting)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// isSensitive reports whether a setting holds a credential
+func isSensitive(name string) bool {
+	name = "_" + name + "_"
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(name, "_"+part+"_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+// Package disposable recognizes addresses of disposable email providers from a list of their
+// domains. The list is embedded in the binary and can be refreshed from a URL.
+package disposable
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-boilerplate/internal/domain/service"
+)
+
+//go:embed domains.txt
+var embedded string
+
+// List looks email domains up in a list of disposable domains held in memory
+type List struct {
+	mu      sync.RWMutex
+	domains map[string]struct{}
+	// blocked are domains added to the list by configuration, kept across refreshes
+	blocked []string
+	// allowed are domains never considered disposable, even when listed
+	allowed map[string]struct{}
+	client  *http.Client
+}
+
+var _ service.DisposableEmailService = (*List)(nil)
+
+// New creates a list of the embedded domains and the blocked ones. Addresses of allowed domains
+// are never disposable.
+func New(blocked, allowed []string) *List {
+	l := &List{
+		blocked: blocked,
+		allowed: map[string]struct{}{},
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, domain := range allowed {
+		l.allowed[normalize(domain)] = struct{}{}
+	}
+
+	domains, _ := read(strings.NewReader(embedded))
+	l.set(domains)
+	return l
+}
+
+// IsDisposable reports whether the domain of the email, or a domain it is a subdomain of, is
+// listed
+func (l *List) IsDisposable(email string) bool {
+	domain := normalize(email[strings.LastIndex(email, "@")+1:])
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for {
+		if _, ok := l.allowed[domain]; ok {
+			return false
+		}
+		if _, ok := l.domains[domain]; ok {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+// Len returns how many domains are listed
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.domains)
+}
+
+// Refresh replaces the listed domains with those of the URL, one per line, plus the blocked
+// ones. Lines starting with # are comments. The list is kept when the URL can't be fetched.
+func (l *List) Refresh(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch disposable email domains: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch disposable email domains: unexpected status code: %d", resp.StatusCode)
+	}
+
+	domains, err := read(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read disposable email domains: %w", err)
+	}
+	// An empty answer is more likely a broken mirror than a list without domains
+	if len(domains) == 0 {
+		return fmt.Errorf("no disposable email domains at %s", url)
+	}
+	l.set(domains)
+	return nil
+}
+
+// Watch refreshes the list from the URL on every interval until ctx is cancelled. onError
+// receives the failed refreshes.
+func (l *List) Watch(ctx context.Context, url string, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Refresh(ctx, url); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// set replaces the listed domains with the given ones plus the blocked ones
+func (l *List) set(domains map[string]struct{}) {
+	for _, domain := range l.blocked {
+		domains[normalize(domain)] = struct{}{}
+	}
+
+	l.mu.Lock()
+	l.domains = domains
+	l.mu.Unlock()
+}
+
+// read reads domains, one per line, skipping blank lines and comments
+func read(r io.Reader) (map[string]struct{}, error) {
+	domains := map[string]struct{}{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[normalize(line)] = struct{}{}
+	}
+	return domains, scanner.Err()
+}
+
+// normalize lowercases a domain and drops the trailing dot of fully qualified names
+func normalize(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
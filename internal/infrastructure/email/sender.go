@@ -0,0 +1,105 @@
+// Package email delivers emails through SMTP, Amazon SES or SendGrid, and renders them from
+// templates.
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Drivers deliver emails
+const (
+	// DriverLog logs emails instead of sending them, for development
+	DriverLog = "log"
+	// DriverSMTP sends emails to an SMTP server
+	DriverSMTP = "smtp"
+	// DriverSES sends emails through the Amazon SES API
+	DriverSES = "ses"
+	// DriverSendGrid sends emails through the SendGrid API
+	DriverSendGrid = "sendgrid"
+)
+
+// Message is a rendered email. HTML is optional; Text is always sent, for clients that don't
+// show HTML.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// maxErrorBody bounds how much of an API error response is kept in the error
+const maxErrorBody = 1024
+
+// Sender delivers emails
+type Sender interface {
+	// Send delivers a message. An error means it may not have been delivered, and sending it
+	// again may deliver it twice.
+	Send(ctx context.Context, message Message) error
+}
+
+// Config configures the driver delivering emails and the sender address
+type Config struct {
+	Driver   string
+	From     string
+	FromName string
+	// Timeout bounds the delivery of each email
+	Timeout  time.Duration
+	SMTP     SMTPConfig
+	SES      SESConfig
+	SendGrid SendGridConfig
+}
+
+// NewSender creates the sender of the configured driver
+func NewSender(ctx context.Context, cfg Config) (Sender, error) {
+	from := mail.Address{Name: cfg.FromName, Address: cfg.From}
+
+	switch cfg.Driver {
+	case DriverLog:
+		return &logSender{}, nil
+	case DriverSMTP:
+		return newSMTPSender(cfg.SMTP, from, cfg.Timeout), nil
+	case DriverSES:
+		return newSESSender(ctx, cfg.SES, from, cfg.Timeout)
+	case DriverSendGrid:
+		return newSendGridSender(cfg.SendGrid, from, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown email driver %q", cfg.Driver)
+	}
+}
+
+// logSender logs emails instead of sending them
+type logSender struct{}
+
+// Send logs the message
+func (s *logSender) Send(ctx context.Context, message Message) error {
+	logging.FromContext(ctx).WithFields(logrus.Fields{
+		"to":      message.To,
+		"subject": message.Subject,
+	}).Info("Email not sent (log driver):\n" + message.Text)
+	return nil
+}
+
+// send sends an API request, failing on any status other than 2xx
+func send(client *http.Client, req *http.Request, api string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", api, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("%s rejected the email with status %d: %s", api, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"time"
+)
+
+// SendGridConfig configures SendGrid
+type SendGridConfig struct {
+	APIKey string
+}
+
+// sendGridEndpoint is the SendGrid v3 mail send API
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridSender sends emails with the SendGrid v3 mail send API
+type sendGridSender struct {
+	apiKey string
+	client *http.Client
+	from   mail.Address
+}
+
+func newSendGridSender(config SendGridConfig, from mail.Address, timeout time.Duration) *sendGridSender {
+	return &sendGridSender{
+		apiKey: config.APIKey,
+		client: &http.Client{Timeout: timeout},
+		from:   from,
+	}
+}
+
+// Send sends the message with SendGrid
+func (s *sendGridSender) Send(ctx context.Context, message Message) error {
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	type content struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+
+	// SendGrid requires text/plain before text/html
+	contents := []content{{Type: "text/plain", Value: message.Text}}
+	if message.HTML != "" {
+		contents = append(contents, content{Type: "text/html", Value: message.HTML})
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string][]address{{"to": {{Email: message.To}}}},
+		"from":             address{Email: s.from.Address, Name: s.from.Name},
+		"subject":          message.Subject,
+		"content":          contents,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	return send(s.client, req, "SendGrid")
+}
@@ -0,0 +1,93 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// SESConfig configures Amazon SES. Credentials come from the default AWS chain: environment,
+// shared config, or the instance or task role.
+type SESConfig struct {
+	Region string
+}
+
+// sesSender sends emails with the SES v2 SendEmail API, signed with SigV4
+type sesSender struct {
+	endpoint    string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+	from        mail.Address
+}
+
+func newSESSender(ctx context.Context, config SESConfig, from mail.Address, timeout time.Duration) (*sesSender, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(config.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &sesSender{
+		endpoint:    fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", config.Region),
+		region:      config.Region,
+		credentials: awsCfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: timeout},
+		from:        from,
+	}, nil
+}
+
+// Send sends the message with SES
+func (s *sesSender) Send(ctx context.Context, message Message) error {
+	type content struct {
+		Data    string
+		Charset string
+	}
+	body := map[string]interface{}{
+		"Text": content{Data: message.Text, Charset: "UTF-8"},
+	}
+	if message.HTML != "" {
+		body["Html"] = content{Data: message.HTML, Charset: "UTF-8"}
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": s.from.String(),
+		"Destination":      map[string][]string{"ToAddresses": {message.To}},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": content{Data: message.Subject, Charset: "UTF-8"},
+				"Body":    body,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode SES request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	credentials, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256(payload)
+	if err := s.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "ses", s.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign SES request: %w", err)
+	}
+
+	return send(s.client, req, "SES")
+}
@@ -0,0 +1,199 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP TLS modes
+const (
+	// SMTPTLSStartTLS upgrades the connection with STARTTLS, and fails when the server can't
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects with TLS from the start, usually on port 465
+	SMTPTLSImplicit = "implicit"
+	// SMTPTLSNone sends in plain text, only for local development servers
+	SMTPTLSNone = "none"
+)
+
+// SMTPConfig configures the SMTP server emails are sent to
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth, unless Username is empty
+	Username string
+	Password string
+	TLS      string
+}
+
+type smtpSender struct {
+	config  SMTPConfig
+	from    mail.Address
+	timeout time.Duration
+}
+
+func newSMTPSender(config SMTPConfig, from mail.Address, timeout time.Duration) *smtpSender {
+	return &smtpSender{
+		config:  config,
+		from:    from,
+		timeout: timeout,
+	}
+}
+
+// Send delivers the message over one SMTP connection
+func (s *smtpSender) Send(ctx context.Context, message Message) error {
+	body, err := buildMIME(s.from, message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: s.config.Host, MinVersion: tls.VersionTLS12}
+	if s.config.TLS == SMTPTLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if s.config.TLS == SMTPTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS with SMTP server: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("SMTP server rejected recipient: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMIME encodes the message as a MIME email, with a text and an HTML alternative when it has
+// HTML
+func buildMIME(from mail.Address, message Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", (&mail.Address{Address: message.To}).String())
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(from))
+	header.Set("MIME-Version", "1.0")
+
+	if message.HTML == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	writeHeader(&buf, header)
+
+	// Clients show the last alternative they understand, so HTML goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message: %w", err)
+		}
+		if err := writeQuotedPrintable(writer, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader writes the header followed by the blank line ending it
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes body quoted-printable encoded, which keeps lines short for SMTP
+func writeQuotedPrintable(w io.Writer, body string) error {
+	writer := quotedprintable.NewWriter(w)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return nil
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(from mail.Address) string {
+	random := make([]byte, 16)
+	rand.Read(random)
+
+	domain := "localhost"
+	if at := strings.LastIndexByte(from.Address, '@'); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
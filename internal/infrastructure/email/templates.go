@@ -0,0 +1,125 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+// layoutTemplate wraps the HTML of every email
+const layoutTemplate = "layout.html"
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// Templates renders emails. An email named "welcome" is made of welcome.txt, a text template
+// defining a "subject" template and the text body, and optionally welcome.html, the HTML body,
+// which layout.html wraps as its "content" template. Files in the override directory replace
+// the embedded ones of the same name, and are read on every render, so changes apply without a
+// restart.
+type Templates struct {
+	sources []fs.FS
+}
+
+// NewTemplates creates templates from the embedded files, overridden by those in dir unless it
+// is empty
+func NewTemplates(dir string) (*Templates, error) {
+	embedded, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []fs.FS{embedded}
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read email template directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("email template directory %s is not a directory", dir)
+		}
+		sources = append([]fs.FS{os.DirFS(dir)}, sources...)
+	}
+	return &Templates{sources: sources}, nil
+}
+
+// Render renders the email name for the recipient with data
+func (t *Templates) Render(name, to string, data interface{}) (Message, error) {
+	text, err := t.read(name + ".txt")
+	if err != nil {
+		return Message{}, err
+	}
+	textTemplate, err := texttemplate.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+	if textTemplate.Lookup("subject") == nil {
+		return Message{}, fmt.Errorf("email template %s.txt doesn't define a subject", name)
+	}
+
+	message := Message{To: to}
+	var buf bytes.Buffer
+	if err := textTemplate.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email subject %s: %w", name, err)
+	}
+	// Subjects are one line
+	message.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := textTemplate.Execute(&buf, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email %s: %w", name, err)
+	}
+	message.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	content, err := t.read(name + ".html")
+	if errors.Is(err, fs.ErrNotExist) {
+		return message, nil
+	}
+	if err != nil {
+		return Message{}, err
+	}
+	layout, err := t.read(layoutTemplate)
+	if err != nil {
+		return Message{}, err
+	}
+	htmlTemplate, err := htmltemplate.New(layoutTemplate).Option("missingkey=error").Parse(layout)
+	if err == nil {
+		_, err = htmlTemplate.New("content").Parse(content)
+	}
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+
+	buf.Reset()
+	if err := htmlTemplate.ExecuteTemplate(&buf, layoutTemplate, htmlData{Subject: message.Subject, Data: data}); err != nil {
+		return Message{}, fmt.Errorf("failed to render email %s: %w", name, err)
+	}
+	message.HTML = buf.String()
+	return message, nil
+}
+
+// htmlData is what the layout is rendered with; it passes Data on to the content
+type htmlData struct {
+	Subject string
+	Data    interface{}
+}
+
+// read returns the first file named name of the sources
+func (t *Templates) read(name string) (string, error) {
+	for _, source := range t.sources {
+		content, err := fs.ReadFile(source, name)
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read email template %s: %w", name, err)
+		}
+	}
+	return "", fmt.Errorf("email template %s: %w", name, fs.ErrNotExist)
+}
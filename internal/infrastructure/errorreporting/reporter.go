@@ -0,0 +1,107 @@
+package errorreporting
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Config represents error reporting configuration. Reporting is disabled when DSN is empty.
+type Config struct {
+	DSN         string
+	Environment string
+	Release     string
+	// SampleRate is the share of errors sent, from 0 to 1
+	SampleRate float64
+}
+
+// RequestContext describes the request and user an error happened in
+type RequestContext struct {
+	Request   *http.Request
+	RequestID string
+	Route     string
+	UserID    string
+	UserEmail string
+	ClientIP  string
+}
+
+// Reporter sends errors and panics to Sentry or any Sentry-compatible service
+type Reporter struct {
+	enabled bool
+}
+
+// NewReporter creates a new error reporter
+func NewReporter(config Config) (*Reporter, error) {
+	if config.DSN == "" {
+		return &Reporter{}, nil
+	}
+
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:         config.DSN,
+		Environment: config.Environment,
+		Release:     config.Release,
+		SampleRate:  config.SampleRate,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize error reporting: %w", err)
+	}
+
+	return &Reporter{enabled: true}, nil
+}
+
+// Enabled reports whether errors are sent anywhere
+func (r *Reporter) Enabled() bool {
+	return r.enabled
+}
+
+// CaptureError reports an error that happened while serving a request
+func (r *Reporter) CaptureError(err error, reqCtx RequestContext) {
+	if !r.enabled || err == nil {
+		return
+	}
+
+	r.hubFor(reqCtx).CaptureException(err)
+}
+
+// CapturePanic reports a recovered panic. It must be called from the deferred function that
+// recovered, so the stack trace still points at the panic.
+func (r *Reporter) CapturePanic(recovered interface{}, reqCtx RequestContext) {
+	if !r.enabled {
+		return
+	}
+
+	r.hubFor(reqCtx).Recover(recovered)
+}
+
+// Flush waits up to timeout for buffered events to be sent
+func (r *Reporter) Flush(timeout time.Duration) bool {
+	if !r.enabled {
+		return true
+	}
+	return sentry.Flush(timeout)
+}
+
+// hubFor returns a hub whose scope carries the request and user details
+func (r *Reporter) hubFor(reqCtx RequestContext) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		if reqCtx.Request != nil {
+			scope.SetRequest(reqCtx.Request)
+		}
+		if reqCtx.RequestID != "" {
+			scope.SetTag("request_id", reqCtx.RequestID)
+		}
+		if reqCtx.Route != "" {
+			scope.SetTag("route", reqCtx.Route)
+		}
+		if reqCtx.UserID != "" {
+			scope.SetUser(sentry.User{
+				ID:        reqCtx.UserID,
+				Email:     reqCtx.UserEmail,
+				IPAddress: reqCtx.ClientIP,
+			})
+		}
+	})
+	return hub
+}
@@ -0,0 +1,145 @@
+// Package geoip resolves the country of IP addresses from a CSV database of IP ranges, such as
+// the free DB-IP "IP to Country Lite" or IP2Location LITE DB1 files
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"gin-boilerplate/internal/domain/service"
+)
+
+// ipRange is a range of addresses of one country
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// Database looks countries up in IP ranges held in memory
+type Database struct {
+	ranges []ipRange
+}
+
+var _ service.GeoIPService = (*Database)(nil)
+
+// Open reads a database of IP ranges. Each CSV row starts with the first and last address of a
+// range, as IP addresses or as decimal integers, followed by the ISO 3166-1 alpha-2 code of the
+// country. Further columns, such as the country's name, are ignored.
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer file.Close()
+
+	db, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GeoIP database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Read reads a database of IP ranges in the format of Open
+func Read(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected start, end and country", line)
+		}
+
+		start, err := parseAddr(record[0])
+		if err != nil {
+			// A header row is skipped
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		end, err := parseAddr(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		country := strings.ToUpper(strings.TrimSpace(record[2]))
+		// Unassigned and reserved ranges have no country
+		if len(country) != 2 || country == "ZZ" {
+			continue
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("line %d: invalid range %s-%s", line, start, end)
+		}
+		ranges = append(ranges, ipRange{start: start, end: end, country: country})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+	return &Database{ranges: ranges}, nil
+}
+
+// Country returns the country of the IP, or "" when the IP is invalid or in no range
+func (d *Database) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// The last range starting at or before the address is the only one that can hold it
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return addr.Less(d.ranges[i].start)
+	}) - 1
+	if i < 0 || d.ranges[i].end.Less(addr) {
+		return ""
+	}
+	return d.ranges[i].country
+}
+
+// Len returns the number of ranges with a country
+func (d *Database) Len() int {
+	return len(d.ranges)
+}
+
+// maxIPv4 is the largest decimal IPv4 address
+var maxIPv4 = big.NewInt(1<<32 - 1)
+
+// parseAddr parses an IP address, or a decimal integer, which is IPv4 up to 2^32-1 and IPv6
+// beyond. IPv4 addresses mapped to IPv6 are stored as IPv4.
+func parseAddr(value string) (netip.Addr, error) {
+	value = strings.TrimSpace(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap(), nil
+	}
+
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 128 {
+		return netip.Addr{}, fmt.Errorf("invalid address %q", value)
+	}
+	if n.Cmp(maxIPv4) <= 0 {
+		var b [4]byte
+		n.FillBytes(b[:])
+		return netip.AddrFrom4(b), nil
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b).Unmap(), nil
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Check status values
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc probes a dependency and returns an error when it is unusable
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of probing one dependency
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of probing every registered dependency
+type Report struct {
+	Status    string                 `json:"status"`
+	Timestamp time.Time              `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Healthy reports whether every dependency is up
+func (r Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker probes the dependencies the application needs to serve traffic
+type Checker struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+// NewChecker creates a checker that gives each probe at most timeout to answer
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Register adds a dependency probe. Probes must be registered before Check is first called.
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Check runs every probe concurrently and reports their status and latency
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusUp,
+		Timestamp: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			result := c.run(ctx, nc.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(nc)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run executes one probe within the checker timeout
+func (c *Checker) run(ctx context.Context, check CheckFunc) CheckResult {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFdsStart is the first file descriptor systemd passes to socket-activated services
+const listenFdsStart = 3
+
+// ListenerConfig configures where the HTTP server accepts connections. Every enabled source
+// is served at the same time.
+type ListenerConfig struct {
+	// Port is the TCP port to listen on. Empty disables TCP.
+	Port string
+	// SocketPath is the path of a unix domain socket to listen on. Empty disables it.
+	SocketPath string
+	// SocketMode is applied to the socket file, so a reverse proxy running as another user can connect
+	SocketMode os.FileMode
+	// SystemdActivation serves the sockets systemd passes to the process, if any
+	SystemdActivation bool
+}
+
+// Listener is a net.Listener with a description of where it listens, for logging
+type Listener struct {
+	net.Listener
+	Description string
+}
+
+// Listen opens the configured listeners. If one fails, those already opened are closed.
+func Listen(config ListenerConfig) ([]Listener, error) {
+	var listeners []Listener
+	fail := func(err error) ([]Listener, error) {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil, err
+	}
+
+	if config.SystemdActivation {
+		activated, err := systemdListeners()
+		if err != nil {
+			return fail(err)
+		}
+		listeners = append(listeners, activated...)
+	}
+
+	if config.Port != "" {
+		l, err := net.Listen("tcp", ":"+config.Port)
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen on port %s: %w", config.Port, err))
+		}
+		listeners = append(listeners, Listener{Listener: l, Description: "tcp :" + config.Port})
+	}
+
+	if config.SocketPath != "" {
+		l, err := listenUnix(config.SocketPath, config.SocketMode)
+		if err != nil {
+			return fail(err)
+		}
+		listeners = append(listeners, Listener{Listener: &unixListener{l}, Description: "unix " + config.SocketPath})
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listener configured")
+	}
+
+	return listeners, nil
+}
+
+// listenUnix listens on a unix socket, replacing a stale socket file left by a previous run
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on socket %s: %w", path, err)
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// localAddr is reported as the peer of unix socket connections
+var localAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+// unixListener reports unix socket peers as 127.0.0.1, since a socket has no peer IP for
+// Gin to derive the client IP from. Add 127.0.0.1 to the trusted proxies so X-Forwarded-For
+// from the reverse proxy is honoured.
+type unixListener struct {
+	net.Listener
+}
+
+func (l *unixListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &unixConn{conn}, nil
+}
+
+type unixConn struct {
+	net.Conn
+}
+
+func (c *unixConn) RemoteAddr() net.Addr {
+	return localAddr
+}
+
+// systemdListeners returns the sockets passed by systemd socket activation (LISTEN_PID and
+// LISTEN_FDS), or none when the process was not socket-activated
+func systemdListeners() ([]Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+
+	// Child processes must not think the sockets were passed to them
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]Listener, 0, count)
+	for fd := listenFdsStart; fd < listenFdsStart+count; fd++ {
+		file := os.NewFile(uintptr(fd), fmt.Sprintf("systemd-socket-%d", fd))
+		l, err := net.FileListener(file)
+		// FileListener dups the descriptor, so the original is closed either way
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to use systemd socket %d: %w", fd, err)
+		}
+		listeners = append(listeners, Listener{Listener: l, Description: "systemd " + l.Addr().String()})
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig configures HTTPS served directly by the application. Either a certificate and key
+// file or a list of autocert domains enables it.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// AutocertDomains are the host names certificates are requested for from Let's Encrypt
+	AutocertDomains []string
+	// AutocertEmail is given to Let's Encrypt for expiry and problem notices
+	AutocertEmail string
+	// AutocertCacheDir stores issued certificates between restarts
+	AutocertCacheDir string
+	// RedirectAddr is the address of a plain HTTP listener (":80") that redirects to HTTPS and
+	// answers ACME HTTP-01 challenges. Empty disables the listener.
+	RedirectAddr string
+	// HTTPSPort is the public HTTPS port redirects point to
+	HTTPSPort string
+}
+
+// Enabled reports whether HTTPS is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertDomains) > 0
+}
+
+// TLS holds the TLS settings of the HTTPS server and the handler of its redirect listener
+type TLS struct {
+	config    *tls.Config
+	manager   *autocert.Manager
+	httpsPort string
+}
+
+// NewTLS loads the certificate files, or sets up automatic certificates when autocert
+// domains are configured
+func NewTLS(config TLSConfig) (*TLS, error) {
+	t := &TLS{
+		httpsPort: config.HTTPSPort,
+	}
+
+	if len(config.AutocertDomains) > 0 {
+		t.manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.AutocertDomains...),
+			Cache:      autocert.DirCache(config.AutocertCacheDir),
+			Email:      config.AutocertEmail,
+		}
+		t.config = t.manager.TLSConfig()
+		t.config.MinVersion = tls.VersionTLS12
+		return t, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	t.config = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return t, nil
+}
+
+// Config returns the TLS settings to serve HTTPS with
+func (t *TLS) Config() *tls.Config {
+	return t.config
+}
+
+// RedirectHandler redirects plain HTTP requests to HTTPS. With autocert it also answers the
+// ACME HTTP-01 challenges Let's Encrypt sends to port 80.
+func (t *TLS) RedirectHandler() http.Handler {
+	redirect := http.HandlerFunc(t.redirect)
+	if t.manager != nil {
+		return t.manager.HTTPHandler(redirect)
+	}
+	return redirect
+}
+
+// redirect sends the client to the same URL over HTTPS
+func (t *TLS) redirect(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if t.httpsPort != "" && t.httpsPort != "443" {
+		host = net.JoinHostPort(host, t.httpsPort)
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
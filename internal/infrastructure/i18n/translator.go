@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the language messages are written in and the fallback for unsupported locales
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Translator translates client-facing messages. Catalogs are keyed by the English message, so
+// messages without a translation fall back to English.
+type Translator struct {
+	locales  []string
+	catalogs map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// NewTranslator creates a translator from the embedded locale catalogs
+func NewTranslator() (*Translator, error) {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locale catalogs: %w", err)
+	}
+
+	t := &Translator{
+		locales:  []string{DefaultLocale},
+		catalogs: make(map[string]map[string]string),
+	}
+
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		data, err := localeFiles.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale catalog %s: %w", locale, err)
+		}
+
+		catalog := make(map[string]string)
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to parse locale catalog %s: %w", locale, err)
+		}
+		t.catalogs[locale] = catalog
+		if locale != DefaultLocale {
+			t.locales = append(t.locales, locale)
+		}
+	}
+	sort.Strings(t.locales[1:])
+
+	// The default locale comes first so it wins when nothing matches
+	tags := make([]language.Tag, len(t.locales))
+	for i, locale := range t.locales {
+		tags[i] = language.Make(locale)
+	}
+	t.matcher = language.NewMatcher(tags)
+
+	return t, nil
+}
+
+// Locales returns the supported locales, default first
+func (t *Translator) Locales() []string {
+	return t.locales
+}
+
+// Locale picks the supported locale for a request: the user's saved preference if it matches a
+// supported locale, then the Accept-Language header, then DefaultLocale. Regional variants fall
+// back to their base language, so "id-ID" is served in "id".
+func (t *Translator) Locale(userLocale, acceptLanguage string) string {
+	if userLocale != "" {
+		if tag, err := language.Parse(userLocale); err == nil {
+			if locale, ok := t.match(tag); ok {
+				return locale
+			}
+		}
+	}
+
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+			if locale, ok := t.match(tags...); ok {
+				return locale
+			}
+		}
+	}
+
+	return DefaultLocale
+}
+
+// match returns the supported locale closest to the preferred tags
+func (t *Translator) match(preferred ...language.Tag) (string, bool) {
+	_, index, confidence := t.matcher.Match(preferred...)
+	if confidence == language.No {
+		return "", false
+	}
+	return t.locales[index], true
+}
+
+// Translate returns message in locale, replacing {name} placeholders with args
+func (t *Translator) Translate(locale, message string, args map[string]string) string {
+	if translated, ok := t.catalogs[locale][message]; ok && translated != "" {
+		message = translated
+	}
+
+	for name, value := range args {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey struct{}
+
+// defaultLogger backs FromContext for contexts without a request logger
+var defaultLogger atomic.Pointer[logrus.Logger]
+
+func init() {
+	defaultLogger.Store(logrus.StandardLogger())
+}
+
+// SetDefault sets the logger used for contexts that carry no request logger, such as
+// background jobs and startup code
+func SetDefault(logger *logrus.Logger) {
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	defaultLogger.Store(logger)
+	// Module loggers are recreated from the new default logger
+	moduleLevels.loggers = map[string]*logrus.Logger{}
+}
+
+// NewContext returns a copy of ctx carrying entry as its logger
+func NewContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the logger of ctx, which carries the request ID and user ID of the request
+// being served, or an entry of the default logger when ctx has none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(defaultLogger.Load())
+}
+
+// TraceID returns the trace ID of a W3C traceparent header such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", or "" when the header isn't valid.
+// Logging it correlates log lines with the traces of proxies and callers.
+func TraceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if strings.Trim(traceID, "0") == "" || strings.Trim(traceID, "0123456789abcdef") != "" {
+		return ""
+	}
+	return traceID
+}
+
+// WithFields returns a copy of ctx whose logger also carries fields
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return NewContext(ctx, FromContext(ctx).WithFields(fields))
+}
@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Modules with a level of their own, which can be set apart from the default logger's
+const (
+	// ModuleAuth logs authentication: logins, tokens and API keys
+	ModuleAuth = "auth"
+	// ModuleGORM logs database queries
+	ModuleGORM = "gorm"
+)
+
+// modules lists the modules whose level can be overridden
+var modules = []string{ModuleAuth, ModuleGORM}
+
+// moduleLevels tracks the module loggers and the levels overriding the default level
+var moduleLevels = struct {
+	mu        sync.Mutex
+	loggers   map[string]*logrus.Logger
+	overrides map[string]logrus.Level
+}{
+	loggers:   map[string]*logrus.Logger{},
+	overrides: map[string]logrus.Level{},
+}
+
+// Modules returns the modules whose level can be overridden
+func Modules() []string {
+	return append([]string(nil), modules...)
+}
+
+// IsModule reports whether a module's level can be overridden
+func IsModule(module string) bool {
+	for _, name := range modules {
+		if name == module {
+			return true
+		}
+	}
+	return false
+}
+
+// Level returns the level of the default logger
+func Level() logrus.Level {
+	return defaultLogger.Load().GetLevel()
+}
+
+// SetLevel changes the level of the default logger, and of the modules without an override
+func SetLevel(level logrus.Level) {
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	defaultLogger.Load().SetLevel(level)
+	for module, logger := range moduleLevels.loggers {
+		if _, ok := moduleLevels.overrides[module]; !ok {
+			logger.SetLevel(level)
+		}
+	}
+}
+
+// SetModuleLevel overrides the level of a module, e.g. to log GORM only from warnings while
+// debugging authentication
+func SetModuleLevel(module string, level logrus.Level) error {
+	if !IsModule(module) {
+		return fmt.Errorf("unknown log module %q", module)
+	}
+
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	moduleLevels.overrides[module] = level
+	if logger, ok := moduleLevels.loggers[module]; ok {
+		logger.SetLevel(level)
+	}
+	return nil
+}
+
+// ClearModuleLevel removes the override of a module, which then follows the default level
+func ClearModuleLevel(module string) error {
+	if !IsModule(module) {
+		return fmt.Errorf("unknown log module %q", module)
+	}
+
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	delete(moduleLevels.overrides, module)
+	if logger, ok := moduleLevels.loggers[module]; ok {
+		logger.SetLevel(Level())
+	}
+	return nil
+}
+
+// ModuleLevels returns the overridden module levels
+func ModuleLevels() map[string]logrus.Level {
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	levels := make(map[string]logrus.Level, len(moduleLevels.overrides))
+	for module, level := range moduleLevels.overrides {
+		levels[module] = level
+	}
+	return levels
+}
+
+// ModuleFromContext returns the logger of ctx, like FromContext, logging at the level of module
+// and tagged with a "module" field
+func ModuleFromContext(ctx context.Context, module string) *logrus.Entry {
+	entry := FromContext(ctx)
+	return logrus.NewEntry(moduleLogger(module)).
+		WithContext(entry.Context).
+		WithFields(entry.Data).
+		WithField("module", module)
+}
+
+// moduleLogger returns the logger of a module. It writes like the default logger, through the
+// same hooks, but has a level of its own.
+func moduleLogger(module string) *logrus.Logger {
+	moduleLevels.mu.Lock()
+	defer moduleLevels.mu.Unlock()
+
+	if logger, ok := moduleLevels.loggers[module]; ok {
+		return logger
+	}
+
+	base := defaultLogger.Load()
+	level, ok := moduleLevels.overrides[module]
+	if !ok {
+		level = base.GetLevel()
+	}
+
+	// Hooks is a map, so hooks added to the default logger later are shared
+	logger := &logrus.Logger{
+		Out:          base.Out,
+		Hooks:        base.Hooks,
+		Formatter:    base.Formatter,
+		ReportCaller: base.ReportCaller,
+		Level:        level,
+		ExitFunc:     base.ExitFunc,
+	}
+	moduleLevels.loggers[module] = logger
+	return logger
+}
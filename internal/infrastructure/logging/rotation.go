@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files, e.g. app.log.20240102-150405.000, so they sort by age
+const backupTimeFormat = "20060102-150405.000"
+
+// RotatingFile is a log file that is moved aside and started afresh when it would grow beyond a
+// size, or when a new interval starts. Rotated files are kept next to it up to a number of backups.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// OpenRotatingFile opens or creates the log file at path. maxSize (in bytes) and interval of 0
+// don't rotate by size or time, and maxBackups of 0 keeps every rotated file.
+func OpenRotatingFile(path string, maxSize int64, interval time.Duration, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		interval:   interval,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes a log line, rotating the file first when needed
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the current file rather than losing lines
+			fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n", err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file for appending. An existing file counts as opened when it was last
+// written, so a file from a previous interval is rotated on the first write.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// due reports whether the file has to be rotated before writing n more bytes. A file is never
+// rotated for size while empty, so a line longer than the limit is still written.
+func (f *RotatingFile) due(n int64) bool {
+	if f.maxSize > 0 && f.size > 0 && f.size+n > f.maxSize {
+		return true
+	}
+	// Intervals start at multiples of the interval since the zero time, e.g. at midnight UTC
+	return f.interval > 0 && time.Now().Truncate(f.interval).After(f.openedAt)
+}
+
+// rotate moves the file aside, opens a new one and deletes the backups beyond maxBackups
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	backup := f.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	renameErr := os.Rename(f.path, backup)
+	// Reopen even when the rename failed, so logging goes on
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to move log file aside: %w", renameErr)
+	}
+	return f.prune()
+}
+
+// prune deletes the oldest rotated files beyond maxBackups
+func (f *RotatingFile) prune() error {
+	if f.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(f.path + ".[0-9]*")
+	if err != nil {
+		return fmt.Errorf("failed to list rotated log files: %w", err)
+	}
+	sort.Strings(backups)
+	for len(backups) > f.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to delete rotated log file: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
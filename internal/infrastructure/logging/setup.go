@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of log lines
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Handlers writing log lines. logrus writes them with its own formatters, slog with the handlers
+// of log/slog.
+const (
+	HandlerLogrus = "logrus"
+	HandlerSlog   = "slog"
+)
+
+// Outputs log lines can be written to
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// Options configure the application logger
+type Options struct {
+	Level   logrus.Level
+	Format  string
+	Handler string
+	// Color colors the text format of the logrus handler
+	Color bool
+	// Outputs are written to together, e.g. stdout and file
+	Outputs []string
+	File    FileOptions
+}
+
+// FileOptions configure the log file and its rotation
+type FileOptions struct {
+	Path string
+	// MaxSize in bytes and RotateInterval of 0 don't rotate by size or time
+	MaxSize        int64
+	RotateInterval time.Duration
+	// MaxBackups of 0 keeps every rotated file
+	MaxBackups int
+}
+
+// New creates the application logger. When an output can't be opened, the logger writes to the
+// others, or to stdout when none is left, and the error is returned along with it.
+func New(opts Options) (*logrus.Logger, error) {
+	logger := logrus.New()
+	logger.SetLevel(opts.Level)
+	logger.SetFormatter(newFormatter(opts))
+
+	writers := []io.Writer{}
+	errs := []error{}
+	for _, output := range opts.Outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputStderr:
+			writers = append(writers, os.Stderr)
+		case OutputFile:
+			file, err := OpenRotatingFile(opts.File.Path, opts.File.MaxSize, opts.File.RotateInterval, opts.File.MaxBackups)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			writers = append(writers, file)
+		default:
+			errs = append(errs, fmt.Errorf("unknown log output %q", output))
+		}
+	}
+
+	switch len(writers) {
+	case 0:
+		logger.SetOutput(os.Stdout)
+	case 1:
+		logger.SetOutput(writers[0])
+	default:
+		logger.SetOutput(io.MultiWriter(writers...))
+	}
+	return logger, errors.Join(errs...)
+}
+
+// newFormatter returns the formatter of the handler and format
+func newFormatter(opts Options) logrus.Formatter {
+	if opts.Handler == HandlerSlog {
+		return &SlogFormatter{JSON: opts.Format == FormatJSON}
+	}
+	if opts.Format == FormatJSON {
+		return &logrus.JSONFormatter{}
+	}
+	return &logrus.TextFormatter{
+		FullTimestamp: true,
+		ForceColors:   opts.Color,
+	}
+}
@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Levels of log/slog for the logrus levels it has no name for
+const (
+	slogLevelTrace = slog.Level(-8)
+	slogLevelFatal = slog.Level(12)
+	slogLevelPanic = slog.Level(16)
+)
+
+// SlogFormatter is a logrus formatter writing lines with a log/slog handler, for log pipelines
+// that expect the slog format. Logging still goes through logrus, with its levels and hooks.
+type SlogFormatter struct {
+	// JSON selects slog's JSONHandler instead of its TextHandler
+	JSON bool
+}
+
+// Format formats an entry as a slog record with the entry's fields as attributes
+func (f *SlogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	options := &slog.HandlerOptions{
+		Level:       slogLevelTrace,
+		ReplaceAttr: replaceSlogLevel,
+	}
+	var handler slog.Handler = slog.NewTextHandler(buffer, options)
+	if f.JSON {
+		handler = slog.NewJSONHandler(buffer, options)
+	}
+
+	record := slog.NewRecord(entry.Time, toSlogLevel(entry.Level), entry.Message, 0)
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := entry.Data[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		record.AddAttrs(slog.Any(key, value))
+	}
+
+	if err := handler.Handle(context.Background(), record); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// replaceSlogLevel names the levels slog would print as offsets, such as DEBUG-4 for trace
+func replaceSlogLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key != slog.LevelKey || len(groups) > 0 {
+		return attr
+	}
+	switch attr.Value.Any() {
+	case slogLevelTrace:
+		attr.Value = slog.StringValue("TRACE")
+	case slogLevelFatal:
+		attr.Value = slog.StringValue("FATAL")
+	case slogLevelPanic:
+		attr.Value = slog.StringValue("PANIC")
+	}
+	return attr
+}
+
+// toSlogLevel converts a logrus level to a slog level
+func toSlogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return slogLevelTrace
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	case logrus.FatalLevel:
+		return slogLevelFatal
+	default:
+		return slogLevelPanic
+	}
+}
+
+// toLogrusLevel converts a slog level to the logrus level logging at least as much. Levels above
+// error are logged as errors, so slog never exits or panics the process.
+func toLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return logrus.TraceLevel
+	case level < slog.LevelInfo:
+		return logrus.DebugLevel
+	case level < slog.LevelWarn:
+		return logrus.InfoLevel
+	case level < slog.LevelError:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// slogHandler is a slog.Handler logging through logrus, so code and libraries using log/slog
+// share the application logger's level, outputs and correlation fields
+type slogHandler struct {
+	fields logrus.Fields
+	group  string
+}
+
+// NewSlogHandler returns a slog.Handler logging with the logger of the context, as
+// FromContext does. slog.SetDefault(slog.New(NewSlogHandler())) routes log/slog and the log
+// package into the application logger.
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{fields: logrus.Fields{}}
+}
+
+// Enabled reports whether the default logger logs at the level
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return FromContext(ctx).Logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+// Handle logs a record with the fields of the handler and the context's logger
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, len(h.fields)+record.NumAttrs())
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, h.group, attr)
+		return true
+	})
+
+	entry := FromContext(ctx).WithFields(fields)
+	if !record.Time.IsZero() {
+		entry = entry.WithTime(record.Time)
+	}
+	entry.Log(toLogrusLevel(record.Level), record.Message)
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to every record
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(logrus.Fields, len(h.fields)+len(attrs))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	for _, attr := range attrs {
+		addSlogAttr(fields, h.group, attr)
+	}
+	return &slogHandler{fields: fields, group: h.group}
+}
+
+// WithGroup returns a handler prefixing the keys of further attributes with the group name
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{fields: h.fields, group: h.group + name + "."}
+}
+
+// addSlogAttr adds an attribute to fields, flattening groups into dotted keys
+func addSlogAttr(fields logrus.Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			addSlogAttr(fields, groupPrefix, member)
+		}
+		return
+	}
+	fields[prefix+attr.Key] = attr.Value.Any()
+}
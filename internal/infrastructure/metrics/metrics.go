@@ -0,0 +1,251 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "ginfinity"
+
+// Metrics holds the Prometheus collectors of the application
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests          *prometheus.CounterVec
+	httpRequestDuration   *prometheus.HistogramVec
+	uploadSize            *prometheus.HistogramVec
+	rateLimitRejections   *prometheus.CounterVec
+	rateLimitDegradations *prometheus.CounterVec
+	previousJWTKeyTokens  *prometheus.CounterVec
+	dbQueryDuration       *prometheus.HistogramVec
+	expiredTokensDeleted  prometheus.Counter
+	refreshTokenRows      prometheus.Gauge
+	securityEvents        *prometheus.CounterVec
+	jobsProcessed         *prometheus.CounterVec
+	jobDuration           *prometheus.HistogramVec
+	scheduledTaskRuns     *prometheus.CounterVec
+	scheduledTaskDuration *prometheus.HistogramVec
+}
+
+// NewMetrics creates the application collectors on a dedicated registry, together with the
+// Go runtime and process collectors
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		uploadSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "uploads",
+			Name:      "size_bytes",
+			Help:      "Size of accepted uploads, by kind.",
+			// 16KB up to 16MB
+			Buckets: prometheus.ExponentialBuckets(16*1024, 4, 6),
+		}, []string{"kind"}),
+		rateLimitRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rate_limit",
+			Name:      "rejections_total",
+			Help:      "Requests rejected by the rate limiter, by route class and reason.",
+		}, []string{"route_class", "reason"}),
+		rateLimitDegradations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rate_limit",
+			Name:      "degradations_total",
+			Help:      "Requests handled by the failure policy while Redis was unavailable.",
+		}, []string{"route_class", "failure_mode"}),
+		previousJWTKeyTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "previous_key_tokens_total",
+			Help:      "Tokens accepted with a previous JWT signing key, by token type and key ID.",
+		}, []string{"token_type", "kid"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Database query latency, by operation, table and status.",
+			// 0.5ms up to about 4s
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"operation", "table", "status"}),
+		expiredTokensDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "expired_tokens_deleted_total",
+			Help:      "Expired and revoked refresh tokens deleted by the cleanup job.",
+		}),
+		refreshTokenRows: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "refresh_token_rows",
+			Help:      "Rows of the refresh token table after the last cleanup.",
+		}),
+		securityEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "security_events_total",
+			Help:      "Security events recorded, by event type.",
+		}, []string{"type"}),
+		jobsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "processed_total",
+			Help:      "Background job runs, by job type and the status they left the job in.",
+		}, []string{"type", "status"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "duration_seconds",
+			Help:      "Background job run time, by job type.",
+			// 10ms up to about 10 minutes
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"type"}),
+		scheduledTaskRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "task_runs_total",
+			Help:      "Scheduled maintenance task runs, by task and status.",
+		}, []string{"task", "status"}),
+		scheduledTaskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "task_duration_seconds",
+			Help:      "Scheduled maintenance task run time, by task.",
+			// 10ms up to about 10 minutes
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"task"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.uploadSize,
+		m.rateLimitRejections,
+		m.rateLimitDegradations,
+		m.previousJWTKeyTokens,
+		m.dbQueryDuration,
+		m.expiredTokensDeleted,
+		m.refreshTokenRows,
+		m.securityEvents,
+		m.jobsProcessed,
+		m.jobDuration,
+		m.scheduledTaskRuns,
+		m.scheduledTaskDuration,
+	)
+
+	return m
+}
+
+// RegisterDB exposes the connection pool stats of a database
+func (m *Metrics) RegisterDB(name string, db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedis exposes the connection pool stats of a Redis client
+func (m *Metrics) RegisterRedis(client *redis.Client) {
+	m.registry.MustRegister(newRedisPoolCollector(client))
+}
+
+// RegisterSchedulerLeader exposes whether this instance is the one running scheduled tasks
+func (m *Metrics) RegisterSchedulerLeader(isLeader func() bool) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "leader",
+		Help:      "1 when this instance holds the scheduler leadership and runs scheduled tasks.",
+	}, func() float64 {
+		if isLeader() {
+			return 1
+		}
+		return 0
+	}))
+}
+
+// Handler serves the collected metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records a handled HTTP request
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveUpload records the size of an accepted upload
+func (m *Metrics) ObserveUpload(kind string, size int64) {
+	m.uploadSize.WithLabelValues(kind).Observe(float64(size))
+}
+
+// RateLimitRejected records a request rejected by the rate limiter
+func (m *Metrics) RateLimitRejected(routeClass, reason string) {
+	m.rateLimitRejections.WithLabelValues(routeClass, reason).Inc()
+}
+
+// RateLimitDegraded records a request handled by the rate limiter's failure policy
+func (m *Metrics) RateLimitDegraded(routeClass, failureMode string) {
+	m.rateLimitDegradations.WithLabelValues(routeClass, failureMode).Inc()
+}
+
+// PreviousJWTKeyUsed records a token accepted with a previous JWT signing key
+func (m *Metrics) PreviousJWTKeyUsed(tokenType, keyID string) {
+	m.previousJWTKeyTokens.WithLabelValues(tokenType, keyID).Inc()
+}
+
+// SecurityEventRecorded records a security event of a type
+func (m *Metrics) SecurityEventRecorded(eventType string) {
+	m.securityEvents.WithLabelValues(eventType).Inc()
+}
+
+// ObserveQuery records a database query and whether it failed
+func (m *Metrics) ObserveQuery(operation, table string, failed bool, duration time.Duration) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	m.dbQueryDuration.WithLabelValues(operation, table, status).Observe(duration.Seconds())
+}
+
+// TokensCleanedUp records a run of the token cleanup job
+func (m *Metrics) TokensCleanedUp(deleted, remaining int64) {
+	m.expiredTokensDeleted.Add(float64(deleted))
+	m.refreshTokenRows.Set(float64(remaining))
+}
+
+// ObserveJob records a background job run and the status it left the job in: succeeded, pending
+// for a retry, or dead
+func (m *Metrics) ObserveJob(jobType, status string, duration time.Duration) {
+	m.jobsProcessed.WithLabelValues(jobType, status).Inc()
+	m.jobDuration.WithLabelValues(jobType).Observe(duration.Seconds())
+}
+
+// ObserveScheduledTask records a run of a scheduled maintenance task and whether it failed
+func (m *Metrics) ObserveScheduledTask(task string, failed bool, duration time.Duration) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	m.scheduledTaskRuns.WithLabelValues(task, status).Inc()
+	m.scheduledTaskDuration.WithLabelValues(task).Observe(duration.Seconds())
+}
@@ -0,0 +1,150 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// googleUserInfoURL returns the account of the user a Google token was issued to
+	googleUserInfoURL = "https://www.googleapis.com/oauth2/v2/userinfo"
+	// googleCertsURL is the JSON Web Key Set Google signs its ID tokens with
+	googleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+)
+
+// googleIssuers are the issuers of Google ID tokens, which differ on the scheme
+var googleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+// GoogleProvider signs users in with their Google account
+type GoogleProvider struct {
+	config oauth2.Config
+	// audiences are the client IDs ID tokens may be issued to: the web client's and those of
+	// the mobile apps
+	audiences []string
+	keys      *keySet
+}
+
+var (
+	_ Provider        = (*GoogleProvider)(nil)
+	_ IDTokenVerifier = (*GoogleProvider)(nil)
+)
+
+// NewGoogleProvider creates a Google provider for an OAuth client of the Google Cloud Console.
+// mobileClientIDs are the client IDs of the iOS and Android apps whose ID tokens are accepted
+// besides those of the web client.
+func NewGoogleProvider(clientID, clientSecret, redirectURL string, mobileClientIDs []string) *GoogleProvider {
+	return &GoogleProvider{
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes: []string{
+				"https://www.googleapis.com/auth/userinfo.email",
+				"https://www.googleapis.com/auth/userinfo.profile",
+			},
+			Endpoint: google.Endpoint,
+		},
+		audiences: append([]string{clientID}, mobileClientIDs...),
+		keys:      newKeySet(googleCertsURL, &http.Client{Timeout: oidcTimeout}),
+	}
+}
+
+// Name returns "google"
+func (p *GoogleProvider) Name() string {
+	return "google"
+}
+
+// AuthURL returns the URL of Google's consent page
+func (p *GoogleProvider) AuthURL(state, codeVerifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// Exchange exchanges an authorization code for a token
+func (p *GoogleProvider) Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+	return token, nil
+}
+
+// FetchUserInfo fetches the Google account of the user the token was issued to
+func (p *GoogleProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user info request: %w", err)
+	}
+
+	resp, err := p.config.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var account struct {
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+		VerifiedEmail bool   `json:"verified_email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+
+	return &UserInfo{
+		ID:            account.ID,
+		Email:         account.Email,
+		Name:          account.Name,
+		Avatar:        account.Picture,
+		EmailVerified: account.VerifiedEmail,
+	}, nil
+}
+
+// VerifyIDToken checks an ID token a mobile app got from Google's sign-in SDK against Google's
+// keys, and returns the account of its user. It must be issued to the web client or one of the
+// mobile apps, and not be expired.
+func (p *GoogleProvider) VerifyIDToken(ctx context.Context, rawIDToken string) (*UserInfo, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	claims := &oidcClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if !slices.Contains(googleIssuers, claims.Issuer) {
+		return nil, fmt.Errorf("invalid ID token: issued by %q", claims.Issuer)
+	}
+	if !slices.ContainsFunc(claims.Audience, func(audience string) bool {
+		return slices.Contains(p.audiences, audience)
+	}) {
+		return nil, errors.New("invalid ID token: issued to another client")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+
+	return claims.userInfo(), nil
+}
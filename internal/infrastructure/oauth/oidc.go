@@ -0,0 +1,267 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// oidcTimeout bounds the requests to the issuer
+const oidcTimeout = 10 * time.Second
+
+// oidcSigningMethods are the ID token signatures accepted. HMAC signatures aren't, so the client
+// secret can't be used to forge tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCConfig configures an OpenID Connect provider
+type OIDCConfig struct {
+	// Name identifies the provider in URLs, e.g. "keycloak"
+	Name string
+	// IssuerURL is the issuer, whose discovery document is at
+	// {IssuerURL}/.well-known/openid-configuration
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes requested; "openid" is always requested
+	Scopes []string
+}
+
+// OIDCProvider signs users in with an OpenID Connect provider such as Keycloak, Auth0 or Okta.
+// Its endpoints are discovered from the issuer, and the user is read from the ID token after
+// its signature, issuer, audience, expiry and nonce are checked.
+type OIDCProvider struct {
+	name        string
+	issuer      string
+	config      oauth2.Config
+	userInfoURL string
+	keys        *keySet
+	client      *http.Client
+}
+
+var _ Provider = (*OIDCProvider)(nil)
+
+// oidcDiscovery is the part of an OpenID Connect discovery document used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider creates an OpenID Connect provider. It fetches the issuer's discovery
+// document, so it fails when the issuer can't be reached.
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	client := &http.Client{Timeout: oidcTimeout}
+
+	var discovery oidcDiscovery
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, discoveryURL, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", config.IssuerURL, err)
+	}
+
+	// Issuers differ on the trailing slash, so it is ignored, but tokens must name the issuer
+	// exactly as its discovery document does
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, not %q", discovery.Issuer, config.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document lacks the authorization, token or JWKS endpoint")
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &OIDCProvider{
+		name:   config.Name,
+		issuer: discovery.Issuer,
+		config: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+		},
+		userInfoURL: discovery.UserInfoEndpoint,
+		keys:        newKeySet(discovery.JWKSURI, client),
+		client:      client,
+	}, nil
+}
+
+// Name returns the configured name
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthURL returns the URL of the issuer's consent page. Its nonce is derived from the state.
+func (p *OIDCProvider) AuthURL(state, codeVerifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonceFor(state)), oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// Exchange exchanges an authorization code for a token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(p.clientContext(ctx), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+	return token, nil
+}
+
+// FetchUserInfo reads the user from the token's ID token. ctx must carry the state of the
+// sign-in (see WithState), which the ID token's nonce is checked against. Claims the ID token
+// lacks, such as the email with some issuers, are fetched from the userinfo endpoint.
+func (p *OIDCProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("token response has no ID token")
+	}
+
+	state := stateFromContext(ctx)
+	if state == "" {
+		return nil, errors.New("no sign-in state to check the ID token's nonce against")
+	}
+
+	claims, err := p.verifyIDToken(ctx, rawIDToken, nonceFor(state))
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Email == "" && p.userInfoURL != "" {
+		var fetched oidcClaims
+		if err := getJSON(ctx, p.config.Client(p.clientContext(ctx), token), p.userInfoURL, &fetched); err != nil {
+			return nil, fmt.Errorf("failed to get user info: %w", err)
+		}
+		// The userinfo response must be about the user of the ID token
+		if fetched.Subject != claims.Subject {
+			return nil, errors.New("userinfo response is about another user")
+		}
+		claims.merge(&fetched)
+	}
+
+	return claims.userInfo(), nil
+}
+
+// clientContext makes the oauth2 package send its requests with the provider's client
+func (p *OIDCProvider) clientContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, p.client)
+}
+
+// verifyIDToken checks the ID token's signature against the issuer's keys, and its issuer,
+// audience, expiry and nonce, and returns its claims
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidcClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	claims := &oidcClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	// A token for several audiences must have been issued to this client
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, errors.New("invalid ID token: issued to another client")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+// oidcClaims are the standard claims of an ID token or userinfo response mapped to a user
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty   string       `json:"azp"`
+	Nonce             string       `json:"nonce"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+	Picture           string       `json:"picture"`
+}
+
+// merge fills the user claims missing from c with those of other
+func (c *oidcClaims) merge(other *oidcClaims) {
+	if c.Email == "" {
+		c.Email = other.Email
+		c.EmailVerified = other.EmailVerified
+	}
+	if c.Name == "" {
+		c.Name = other.Name
+	}
+	if c.PreferredUsername == "" {
+		c.PreferredUsername = other.PreferredUsername
+	}
+	if c.Picture == "" {
+		c.Picture = other.Picture
+	}
+}
+
+// userInfo maps the claims to a user. The name falls back to the username, then to the local
+// part of the email.
+func (c *oidcClaims) userInfo() *UserInfo {
+	name := c.Name
+	if name == "" {
+		name = c.PreferredUsername
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(c.Email, "@")
+	}
+
+	return &UserInfo{
+		ID:            c.Subject,
+		Email:         c.Email,
+		Name:          name,
+		Avatar:        c.Picture,
+		EmailVerified: bool(c.EmailVerified),
+	}
+}
+
+// flexibleBool decodes a JSON boolean, or the string "true" or "false" some issuers send instead
+type flexibleBool bool
+
+// UnmarshalJSON decodes a boolean or a string
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(strings.EqualFold(v, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package oauth signs users in through OAuth 2.0 providers such as Google. A new provider is
+// added by implementing Provider and registering it in a Registry.
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// UserInfo is the account of a user at a provider
+type UserInfo struct {
+	// ID identifies the user at the provider
+	ID     string
+	Email  string
+	Name   string
+	Avatar string
+	// EmailVerified is whether the provider verified that the user owns the email
+	EmailVerified bool
+}
+
+// Provider is an OAuth 2.0 provider users can sign in with
+type Provider interface {
+	// Name identifies the provider in URLs such as /auth/oauth/{name}, e.g. "google"
+	Name() string
+
+	// AuthURL returns the URL of the provider's consent page, which redirects back with an
+	// authorization code and state. The S256 challenge of the PKCE code verifier is sent along.
+	AuthURL(state, codeVerifier string) string
+
+	// Exchange exchanges an authorization code for a token, proving with the code verifier that
+	// the sign-in was started here
+	Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error)
+
+	// FetchUserInfo fetches the account of the user the token was issued to
+	FetchUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error)
+}
+
+// IDTokenVerifier is a provider whose ID tokens can be exchanged directly, such as those a
+// mobile app gets from the provider's native SDK without a browser redirect
+type IDTokenVerifier interface {
+	// VerifyIDToken checks the ID token's signature, issuer, audience and expiry, and returns
+	// the account of its user
+	VerifyIDToken(ctx context.Context, rawIDToken string) (*UserInfo, error)
+}
+
+// validName matches provider names. They are stored in upper case as the provider of users, in a
+// column of 10 characters.
+var validName = regexp.MustCompile(`^[a-z][a-z0-9]{0,9}$`)
+
+// Registry holds the providers users can sign in with
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds a provider. It fails when the name isn't valid or is taken.
+func (r *Registry) Register(provider Provider) error {
+	name := provider.Name()
+	if !validName.MatchString(name) || name == "local" {
+		return fmt.Errorf("invalid OAuth provider name %q", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[name]; exists {
+		return fmt.Errorf("OAuth provider %q is already registered", name)
+	}
+	r.providers[name] = provider
+	return nil
+}
+
+// Get returns the provider with the name
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// Names returns the names of the registered providers, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VerifyState reports whether the state received in a callback is the one that was issued
+func VerifyState(received, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(received), []byte(expected)) == 1
+}
+
+// stateContextKey is the context key of the state of a sign-in
+type stateContextKey struct{}
+
+// WithState returns a copy of ctx carrying the state of the sign-in whose callback is being
+// handled. Providers that bind their tokens to the sign-in, such as OIDC with its nonce, read it.
+func WithState(ctx context.Context, state string) context.Context {
+	return context.WithValue(ctx, stateContextKey{}, state)
+}
+
+// stateFromContext returns the state of the sign-in of ctx, or "" when ctx has none
+func stateFromContext(ctx context.Context) string {
+	state, _ := ctx.Value(stateContextKey{}).(string)
+	return state
+}
+
+// nonceFor derives the nonce of a sign-in from its state. Each sign-in has its own state, kept by
+// the server until its callback, so an ID token issued for another sign-in can't be replayed.
+func nonceFor(state string) string {
+	sum := sha256.Sum256([]byte("nonce:" + state))
+	return hex.EncodeToString(sum[:])
+}
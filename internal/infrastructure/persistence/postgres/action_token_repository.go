@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type actionTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewActionTokenRepository creates a new PostgreSQL action token repository
+func NewActionTokenRepository(db *gorm.DB) repository.ActionTokenRepository {
+	return &actionTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a new action token
+func (r *actionTokenRepository) Create(ctx context.Context, token *entity.ActionToken) error {
+	if err := withContext(ctx, r.db).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create action token: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// Consume deletes the action token with the purpose and hash and returns it. Deleting and
+// returning it in one statement lets only one of two concurrent requests have it.
+func (r *actionTokenRepository) Consume(ctx context.Context, purpose entity.ActionTokenPurpose, tokenHash string) (*entity.ActionToken, error) {
+	var tokens []entity.ActionToken
+	result := withContext(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		Delete(&tokens)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to consume action token: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if len(tokens) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &tokens[0], nil
+}
+
+// DeleteByUserID deletes the user's action tokens for the purpose
+func (r *actionTokenRepository) DeleteByUserID(ctx context.Context, userID string, purpose entity.ActionTokenPurpose) error {
+	if err := withContext(ctx, r.db).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(&entity.ActionToken{}).Error; err != nil {
+		return fmt.Errorf("failed to delete action tokens: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// DeleteExpired deletes the expired action tokens, and returns how many were deleted
+func (r *actionTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := withContext(ctx, r.db).Where("expires_at < ?", time.Now()).Delete(&entity.ActionToken{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired action tokens: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository creates a new PostgreSQL activity repository
+func NewActivityRepository(db *gorm.DB) repository.ActivityRepository {
+	return &activityRepository{
+		db: db,
+	}
+}
+
+// Create records an activity
+func (r *activityRepository) Create(ctx context.Context, activity *entity.Activity) error {
+	if err := withContext(ctx, r.db).Create(activity).Error; err != nil {
+		return fmt.Errorf("failed to create activity: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// List returns a page of the activities matching the filter, newest first
+func (r *activityRepository) List(ctx context.Context, filter repository.ActivityFilter, limit, offset int) ([]*entity.Activity, error) {
+	var activities []*entity.Activity
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", translateError(err, domain.ErrNotFound))
+	}
+	return activities, nil
+}
+
+// Count returns the number of activities matching the filter
+func (r *activityRepository) Count(ctx context.Context, filter repository.ActivityFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count activities: %w", translateError(err, domain.ErrNotFound))
+	}
+	return count, nil
+}
+
+// DeleteByUserID deletes the activities of a user
+func (r *activityRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.Activity{}).Error; err != nil {
+		return fmt.Errorf("failed to delete activities: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// DeleteBefore deletes the activities recorded before the time
+func (r *activityRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).Where("created_at < ?", before).Delete(&entity.Activity{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete activities: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// filtered returns a query of the activities matching the filter
+func (r *activityRepository) filtered(ctx context.Context, filter repository.ActivityFilter) *gorm.DB {
+	query := withContext(ctx, r.db).Model(&entity.Activity{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if !filter.Before.IsZero() {
+		query = query.Where("created_at < ?", filter.Before)
+	}
+	return query
+}
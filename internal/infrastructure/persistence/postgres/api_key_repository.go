@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new PostgreSQL API key repository
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+// Create creates a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *entity.APIKey) error {
+	if err := withContext(ctx, r.db).Create(apiKey).Error; err != nil {
+		return fmt.Errorf("failed to create API key: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// FindByID finds an API key by ID
+func (r *apiKeyRepository) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	var apiKey entity.APIKey
+	if err := withContext(ctx, r.db).Where("id = ?", id).First(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to find API key by ID: %w", translateError(err, domain.ErrAPIKeyNotFound))
+	}
+	return &apiKey, nil
+}
+
+// FindByKeyHash finds an API key by the hash of its plaintext value
+func (r *apiKeyRepository) FindByKeyHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	var apiKey entity.APIKey
+	if err := withContext(ctx, r.db).Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to find API key by hash: %w", translateError(err, domain.ErrAPIKeyNotFound))
+	}
+	return &apiKey, nil
+}
+
+// FindByUserID finds API keys by user ID
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	var apiKeys []*entity.APIKey
+	if err := withContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to find API keys by user ID: %w", translateError(err, domain.ErrNotFound))
+	}
+	return apiKeys, nil
+}
+
+// Update updates an API key
+func (r *apiKeyRepository) Update(ctx context.Context, apiKey *entity.APIKey) error {
+	if err := withContext(ctx, r.db).Save(apiKey).Error; err != nil {
+		return fmt.Errorf("failed to update API key: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// TouchLastUsed records the time the API key was last used
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	if err := withContext(ctx, r.db).
+		Model(&entity.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to update API key last used time: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// Delete deletes an API key by ID
+func (r *apiKeyRepository) Delete(ctx context.Context, id string) error {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Delete(&entity.APIKey{}).Error; err != nil {
+		return fmt.Errorf("failed to delete API key: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new PostgreSQL audit log repository
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// Create records an audit log entry
+func (r *auditLogRepository) Create(ctx context.Context, entry *entity.AuditLog) error {
+	if err := withContext(ctx, r.db).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// List returns a page of the audit log entries matching the filter, newest first
+func (r *auditLogRepository) List(ctx context.Context, filter repository.AuditLogFilter, limit, offset int) ([]*entity.AuditLog, error) {
+	var entries []*entity.AuditLog
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", translateError(err, domain.ErrNotFound))
+	}
+	return entries, nil
+}
+
+// Count returns the number of audit log entries matching the filter
+func (r *auditLogRepository) Count(ctx context.Context, filter repository.AuditLogFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count audit log: %w", translateError(err, domain.ErrNotFound))
+	}
+	return count, nil
+}
+
+// DeleteBefore deletes the audit log entries recorded before the time
+func (r *auditLogRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).Where("created_at < ?", before).Delete(&entity.AuditLog{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete audit log: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// filtered returns a query of the audit log entries matching the filter
+func (r *auditLogRepository) filtered(ctx context.Context, filter repository.AuditLogFilter) *gorm.DB {
+	query := withContext(ctx, r.db).Model(&entity.AuditLog{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.Before.IsZero() {
+		query = query.Where("created_at < ?", filter.Before)
+	}
+	return query
+}
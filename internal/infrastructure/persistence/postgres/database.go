@@ -1,11 +1,13 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/infrastructure/metrics"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,19 +19,22 @@ type Database struct {
 	DB *gorm.DB
 }
 
-// NewDatabase creates a new database connection
-func NewDatabase(dsn string, isDevelopment bool) (*Database, error) {
-	// Configure GORM logger
-	var gormLogger logger.Interface
-	if isDevelopment {
-		gormLogger = logger.Default.LogMode(logger.Info)
-	} else {
-		gormLogger = logger.Default.LogMode(logger.Error)
-	}
+// PoolConfig represents the connection pool limits. MaxOpenConns, ConnMaxLifetime and
+// ConnMaxIdleTime of 0 mean no limit.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
 
+// NewDatabase creates a new database connection. Failed queries, and queries slower than
+// slowQueryThreshold unless it is 0, are logged through the logging.ModuleGORM module.
+func NewDatabase(dsn string, pool PoolConfig, slowQueryThreshold time.Duration) (*Database, error) {
 	// Open database connection
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+		Logger:         newGormLogger(logger.Warn, slowQueryThreshold),
+		TranslateError: true,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -45,9 +50,10 @@ func NewDatabase(dsn string, isDevelopment bool) (*Database, error) {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	database := &Database{
 		DB: db,
@@ -64,10 +70,86 @@ func NewDatabase(dsn string, isDevelopment bool) (*Database, error) {
 
 // AutoMigrate runs auto migration for all entities
 func (d *Database) AutoMigrate() error {
-	return d.DB.AutoMigrate(
+	err := d.DB.AutoMigrate(
 		&entity.User{},
+		&entity.UserProvider{},
 		&entity.Token{},
+		&entity.Document{},
+		&entity.APIKey{},
+		&entity.UsageRollup{},
+		&entity.QuotaOverride{},
+		&entity.Job{},
+		&entity.Webhook{},
+		&entity.WebhookDelivery{},
+		&entity.SecurityEvent{},
+		&entity.KnownDevice{},
+		&entity.KnownCountry{},
+		&entity.ActionToken{},
+		&entity.PreviousPassword{},
+		&entity.RecoveryCode{},
+		&entity.Invitation{},
+		&entity.CustomRole{},
+		&entity.RolePermission{},
+		&entity.AuditLog{},
+		&entity.UserMetadataField{},
+		&entity.Activity{},
+		&entity.UserSettings{},
 	)
+	if err != nil {
+		return err
+	}
+	if err := d.migrateUserProviders(); err != nil {
+		return err
+	}
+	return d.migrateDocumentSearch()
+}
+
+// migrateUserProviders moves the provider accounts of users, which were stored in
+// users.provider_id before users could link several providers, to user_providers. The column is
+// dropped afterwards, so this runs once.
+func (d *Database) migrateUserProviders() error {
+	if !d.DB.Migrator().HasColumn("users", "provider_id") {
+		return nil
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO user_providers (id, user_id, provider, provider_id, email, created_at)
+			SELECT gen_random_uuid(), id, provider, provider_id, email, NOW()
+			FROM users
+			WHERE provider_id IS NOT NULL AND provider_id <> '' AND provider <> ?
+			ON CONFLICT DO NOTHING`, entity.ProviderLocal).Error
+		if err != nil {
+			return fmt.Errorf("failed to copy provider accounts to user_providers: %w", err)
+		}
+		if err := tx.Migrator().DropColumn("users", "provider_id"); err != nil {
+			return fmt.Errorf("failed to drop users.provider_id: %w", err)
+		}
+		return nil
+	})
+}
+
+// migrateDocumentSearch adds the full-text search vector of documents, over their title,
+// description and extracted text, with a GIN index. Postgres generates the column, so GORM
+// doesn't manage it; generated columns need PostgreSQL 12 or later.
+func (d *Database) migrateDocumentSearch() error {
+	if d.DB.Migrator().HasColumn("documents", "search_vector") {
+		return nil
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`ALTER TABLE documents ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(extracted_text, '')), 'C')
+		) STORED`).Error
+		if err != nil {
+			return fmt.Errorf("failed to add documents.search_vector: %w", err)
+		}
+		if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN (search_vector)").Error; err != nil {
+			return fmt.Errorf("failed to index documents.search_vector: %w", err)
+		}
+		return nil
+	})
 }
 
 // Close closes the database connection
@@ -93,7 +175,24 @@ func (d *Database) Health() error {
 	return nil
 }
 
+// Ping checks that the database answers within the context deadline
+func (d *Database) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// ObserveQueries records the latency of every query in m
+func (d *Database) ObserveQueries(m *metrics.Metrics) error {
+	if err := d.DB.Use(newQueryMetricsPlugin(m)); err != nil {
+		return fmt.Errorf("failed to register query metrics: %w", err)
+	}
+	return nil
+}
+
 // GetDB returns the GORM database instance
 func (d *Database) GetDB() *gorm.DB {
 	return d.DB
-}
\ No newline at end of file
+}
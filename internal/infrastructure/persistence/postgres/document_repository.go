@@ -2,7 +2,10 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 
@@ -20,61 +23,196 @@ func NewDocumentRepository(db *gorm.DB) repository.DocumentRepository {
 }
 
 func (r *documentRepository) Create(ctx context.Context, document *entity.Document) error {
-	return r.db.WithContext(ctx).Create(document).Error
+	return translateError(withContext(ctx, r.db).Create(document).Error, domain.ErrDocumentNotFound)
 }
 
 func (r *documentRepository) FindByID(ctx context.Context, id string) (*entity.Document, error) {
 	var document entity.Document
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&document).Error
+	err := withContext(ctx, r.db).Where("id = ?", id).First(&document).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, gorm.ErrRecordNotFound
-		}
-		return nil, err
+		return nil, translateError(err, domain.ErrDocumentNotFound)
 	}
 	return &document, nil
 }
 
 func (r *documentRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]*entity.Document, error) {
 	var documents []*entity.Document
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&documents).Error
-	return documents, err
+	return documents, translateError(err, domain.ErrDocumentNotFound)
+}
+
+func (r *documentRepository) FindByUserIDAfter(ctx context.Context, userID string, after *repository.Cursor, limit int) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	err := keysetPage(withContext(ctx, r.db).Where("user_id = ?", userID), after, limit).
+		Find(&documents).Error
+	return documents, translateError(err, domain.ErrDocumentNotFound)
+}
+
+func (r *documentRepository) FindByIDs(ctx context.Context, ids []string) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	if len(ids) == 0 {
+		return documents, nil
+	}
+	err := withContext(ctx, r.db).Where("id IN ?", ids).Find(&documents).Error
+	return documents, translateError(err, domain.ErrDocumentNotFound)
+}
+
+func (r *documentRepository) FindAfter(ctx context.Context, after *repository.Cursor, limit int) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	err := keysetPage(withContext(ctx, r.db), after, limit).
+		Find(&documents).Error
+	return documents, translateError(err, domain.ErrDocumentNotFound)
 }
 
 func (r *documentRepository) Update(ctx context.Context, document *entity.Document) error {
-	return r.db.WithContext(ctx).Save(document).Error
+	return translateError(compareAndSwap(withContext(ctx, r.db), document, &document.Version), domain.ErrDocumentNotFound)
 }
 
 func (r *documentRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&entity.Document{}, "id = ?", id).Error
+	return translateError(withContext(ctx, r.db).Delete(&entity.Document{}, "id = ?", id).Error, domain.ErrDocumentNotFound)
 }
 
 func (r *documentRepository) GetFileURL(ctx context.Context, id string) (string, error) {
 	var fileURL string
-	err := r.db.WithContext(ctx).
+	result := withContext(ctx, r.db).
 		Model(&entity.Document{}).
 		Where("id = ?", id).
 		Select("file_url").
-		Scan(&fileURL).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return "", gorm.ErrRecordNotFound
-		}
-		return "", err
+		Scan(&fileURL)
+	if result.Error != nil {
+		return "", translateError(result.Error, domain.ErrDocumentNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return "", domain.ErrDocumentNotFound
 	}
 	return fileURL, nil
 }
 
 func (r *documentRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.Document{}).
 		Where("user_id = ?", userID).
 		Count(&count).Error
-	return count, err
-}
\ No newline at end of file
+	return count, translateError(err, domain.ErrDocumentNotFound)
+}
+
+// documentSearchQuery parses a search in web search syntax. The simple configuration doesn't stem
+// words, so documents in any language match; the search_vector column uses it too.
+const documentSearchQuery = "websearch_to_tsquery('simple', ?)"
+
+// titleHighlightOptions highlight the matches of the whole title, and snippetOptions those of up
+// to two excerpts of the description and extracted text
+var (
+	titleHighlightOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, repository.SearchHighlightStart, repository.SearchHighlightStop)
+	snippetOptions        = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`, repository.SearchHighlightStart, repository.SearchHighlightStop)
+)
+
+// documentSearchRow is a document matching a search, with what the search computes
+type documentSearchRow struct {
+	entity.Document `gorm:"embedded"`
+	Rank            float64
+	TitleHighlight  string
+	Snippet         string
+}
+
+// Search returns a page of a user's documents matching the query, ranked with the title weighing
+// more than the description, and the description more than the extracted text
+func (r *documentRepository) Search(ctx context.Context, userID, query string, limit, offset int) ([]repository.DocumentSearchResult, error) {
+	var rows []documentSearchRow
+	err := r.searched(ctx, userID, query).
+		Select("documents.*, ts_rank_cd(documents.search_vector, search_query) AS rank, "+
+			"ts_headline('simple', documents.title, search_query, ?) AS title_highlight, "+
+			"ts_headline('simple', concat_ws(' ', documents.description, documents.extracted_text), search_query, ?) AS snippet",
+			titleHighlightOptions, snippetOptions).
+		Order("rank DESC, documents.created_at DESC, documents.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, translateError(err, domain.ErrDocumentNotFound)
+	}
+
+	results := make([]repository.DocumentSearchResult, len(rows))
+	for i := range rows {
+		results[i] = repository.DocumentSearchResult{
+			Document:       &rows[i].Document,
+			Rank:           rows[i].Rank,
+			TitleHighlight: rows[i].TitleHighlight,
+			Snippet:        rows[i].Snippet,
+		}
+	}
+	return results, nil
+}
+
+// CountSearch returns the number of a user's documents matching the query
+func (r *documentRepository) CountSearch(ctx context.Context, userID, query string) (int64, error) {
+	var count int64
+	err := r.searched(ctx, userID, query).Count(&count).Error
+	return count, translateError(err, domain.ErrDocumentNotFound)
+}
+
+// searched returns a query of a user's documents matching the search, which it names
+// search_query. The GIN index of search_vector finds them.
+func (r *documentRepository) searched(ctx context.Context, userID, query string) *gorm.DB {
+	return withContext(ctx, r.db).
+		Model(&entity.Document{}).
+		Joins("CROSS JOIN "+documentSearchQuery+" AS search_query", query).
+		Where("documents.user_id = ? AND documents.search_vector @@ search_query", userID)
+}
+
+// PurgeDeletedBefore permanently deletes the documents soft-deleted before the time
+func (r *documentRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).
+		Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(&entity.Document{})
+	return result.RowsAffected, translateError(result.Error, domain.ErrDocumentNotFound)
+}
+
+// FileURLsInUse returns those of the file URLs some document, soft-deleted or not, refers to
+func (r *documentRepository) FileURLsInUse(ctx context.Context, fileURLs []string) ([]string, error) {
+	inUse := []string{}
+	if len(fileURLs) == 0 {
+		return inUse, nil
+	}
+	err := withContext(ctx, r.db).
+		Unscoped().
+		Model(&entity.Document{}).
+		Where("file_url IN ?", fileURLs).
+		Distinct().
+		Pluck("file_url", &inUse).Error
+	return inUse, translateError(err, domain.ErrDocumentNotFound)
+}
+
+// Reindex rebuilds the indexes of the documents table. CONCURRENTLY keeps the table readable and
+// writable meanwhile, so it can run while the API serves.
+func (r *documentRepository) Reindex(ctx context.Context) error {
+	err := withContext(ctx, r.db).Exec("REINDEX TABLE CONCURRENTLY documents").Error
+	return translateError(err, domain.ErrDocumentNotFound)
+}
+
+// CountCreatedPerDay returns how many documents were uploaded on each UTC day since the time,
+// oldest first
+func (r *documentRepository) CountCreatedPerDay(ctx context.Context, since time.Time) ([]repository.DailyCount, error) {
+	counts, err := countCreatedPerDay(ctx, r.db, &entity.Document{}, since)
+	return counts, translateError(err, domain.ErrDocumentNotFound)
+}
+
+// TopStorageUsers returns the limit users whose documents take the most storage, largest first
+func (r *documentRepository) TopStorageUsers(ctx context.Context, limit int) ([]repository.StorageUsage, error) {
+	usage := []repository.StorageUsage{}
+	err := withContext(ctx, r.db).
+		Model(&entity.Document{}).
+		Select("user_id, count(*) AS documents, coalesce(sum(file_size), 0) AS bytes").
+		Group("user_id").
+		Order("bytes DESC, user_id").
+		Limit(limit).
+		Scan(&usage).Error
+	return usage, translateError(err, domain.ErrDocumentNotFound)
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"errors"
+
+	"gin-boilerplate/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// translateError maps a GORM error to the domain error callers match with errors.Is: a missing
+// record becomes notFound, a unique violation domain.ErrDuplicate and a foreign key violation
+// domain.ErrForeignKey. The database is opened with TranslateError, so Postgres error codes
+// arrive as GORM errors. Other errors are returned unchanged.
+func translateError(err error, notFound *domain.Error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound.Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrDuplicate.Wrap(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return domain.ErrForeignKey.Wrap(err)
+	default:
+		return err
+	}
+}
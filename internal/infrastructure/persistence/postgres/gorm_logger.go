@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM logs through the logger of the query context, so queries run with
+// db.WithContext(ctx) are logged with the request ID and user ID of the request that ran them.
+// The level of the logging.ModuleGORM module decides which of them are written.
+type gormLogger struct {
+	level logger.LogLevel
+	// slowThreshold is how long a query may take before it is logged as slow, 0 disables it
+	slowThreshold time.Duration
+}
+
+// newGormLogger creates a GORM logger logging at level and above
+func newGormLogger(level logger.LogLevel, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode returns a copy of the logger with another level
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		logging.ModuleFromContext(ctx, logging.ModuleGORM).Infof(msg, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		logging.ModuleFromContext(ctx, logging.ModuleGORM).Warnf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		logging.ModuleFromContext(ctx, logging.ModuleGORM).Errorf(msg, args...)
+	}
+}
+
+// Trace logs failed queries and slow queries. Record not found errors are expected by the
+// repositories and not logged.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	entry := logging.ModuleFromContext(ctx, logging.ModuleGORM)
+	elapsed := time.Since(begin)
+	fields := func() logrus.Fields {
+		sql, rows := fc()
+		return logrus.Fields{
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": float64(elapsed.Microseconds()) / 1000,
+		}
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		entry.WithFields(fields()).WithError(err).Error("Query failed")
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		entry.WithFields(fields()).Warn("Slow query")
+	}
+}
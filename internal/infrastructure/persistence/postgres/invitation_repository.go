@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository creates a new PostgreSQL invitation repository
+func NewInvitationRepository(db *gorm.DB) repository.InvitationRepository {
+	return &invitationRepository{
+		db: db,
+	}
+}
+
+// Create stores an invitation
+func (r *invitationRepository) Create(ctx context.Context, invitation *entity.Invitation) error {
+	if err := withContext(ctx, r.db).Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create invitation: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return nil
+}
+
+// FindByID returns the invitation with the ID
+func (r *invitationRepository) FindByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+	if err := withContext(ctx, r.db).Where("id = ?", id).First(&invitation).Error; err != nil {
+		return nil, fmt.Errorf("failed to find invitation: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return &invitation, nil
+}
+
+// FindByTokenHash returns the invitation of the token hash
+func (r *invitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+	if err := withContext(ctx, r.db).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, fmt.Errorf("failed to find invitation: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return &invitation, nil
+}
+
+// FindPendingByEmail returns the latest pending invitation of the email
+func (r *invitationRepository) FindPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+	err := withContext(ctx, r.db).
+		Where("email = ? AND accepted_at IS NULL AND expires_at > ?", email, time.Now()).
+		Order("created_at DESC").
+		First(&invitation).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find invitation: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return &invitation, nil
+}
+
+// List returns a page of invitations, newest first
+func (r *invitationRepository) List(ctx context.Context, limit, offset int) ([]*entity.Invitation, error) {
+	var invitations []*entity.Invitation
+	if err := withContext(ctx, r.db).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return invitations, nil
+}
+
+// Count returns the number of invitations
+func (r *invitationRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := withContext(ctx, r.db).Model(&entity.Invitation{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count invitations: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return count, nil
+}
+
+// Accept marks the invitation as accepted by the user, unless it already was
+func (r *invitationRepository) Accept(ctx context.Context, id, userID string) error {
+	result := withContext(ctx, r.db).
+		Model(&entity.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"accepted_by": userID,
+			"accepted_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to accept invitation: %w", translateError(result.Error, domain.ErrInvitationNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// Delete deletes an invitation
+func (r *invitationRepository) Delete(ctx context.Context, id string) error {
+	result := withContext(ctx, r.db).Where("id = ?", id).Delete(&entity.Invitation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete invitation: %w", translateError(result.Error, domain.ErrInvitationNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// DeletePendingByEmail deletes the invitations of the email that weren't accepted
+func (r *invitationRepository) DeletePendingByEmail(ctx context.Context, email string) error {
+	if err := withContext(ctx, r.db).Where("email = ? AND accepted_at IS NULL", email).Delete(&entity.Invitation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete invitations: %w", translateError(err, domain.ErrInvitationNotFound))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new PostgreSQL job repository
+func NewJobRepository(db *gorm.DB) repository.JobRepository {
+	return &jobRepository{
+		db: db,
+	}
+}
+
+// Create enqueues a job
+func (r *jobRepository) Create(ctx context.Context, job *entity.Job) error {
+	if err := withContext(ctx, r.db).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create job: %w", translateError(err, domain.ErrJobNotFound))
+	}
+	return nil
+}
+
+// FindByID finds a job by ID
+func (r *jobRepository) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	var job entity.Job
+	if err := withContext(ctx, r.db).Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to find job by ID: %w", translateError(err, domain.ErrJobNotFound))
+	}
+	return &job, nil
+}
+
+// Claim locks the due job with FOR UPDATE SKIP LOCKED, so workers polling at the same time each
+// get a different one, and starts it in the same transaction
+func (r *jobRepository) Claim(ctx context.Context, types []string, lease time.Duration) (*entity.Job, error) {
+	var job entity.Job
+	err := withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("type IN ?", types).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				entity.JobStatusPending, now, entity.JobStatusRunning, now).
+			Order("run_at").
+			Take(&job).Error; err != nil {
+			return err
+		}
+		job.Start(lease)
+		return tx.Save(&job).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", translateError(err, domain.ErrNotFound))
+	}
+	return &job, nil
+}
+
+// Update saves a job
+func (r *jobRepository) Update(ctx context.Context, job *entity.Job) error {
+	if err := withContext(ctx, r.db).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update job: %w", translateError(err, domain.ErrJobNotFound))
+	}
+	return nil
+}
+
+// List returns a page of jobs, newest first
+func (r *jobRepository) List(ctx context.Context, status entity.JobStatus, limit, offset int) ([]*entity.Job, error) {
+	var jobs []*entity.Job
+	if err := withStatus(withContext(ctx, r.db), status).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", translateError(err, domain.ErrJobNotFound))
+	}
+	return jobs, nil
+}
+
+// Count returns the number of jobs
+func (r *jobRepository) Count(ctx context.Context, status entity.JobStatus) (int64, error) {
+	var count int64
+	if err := withStatus(withContext(ctx, r.db), status).Model(&entity.Job{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count jobs: %w", translateError(err, domain.ErrJobNotFound))
+	}
+	return count, nil
+}
+
+// DeleteSucceededBefore deletes the jobs that succeeded before the time
+func (r *jobRepository) DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).
+		Where("status = ? AND finished_at < ?", entity.JobStatusSucceeded, before).
+		Delete(&entity.Job{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete succeeded jobs: %w", translateError(result.Error, domain.ErrJobNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// withStatus filters jobs by status, unless it is empty
+func withStatus(db *gorm.DB, status entity.JobStatus) *gorm.DB {
+	if status == "" {
+		return db
+	}
+	return db.Where("status = ?", status)
+}
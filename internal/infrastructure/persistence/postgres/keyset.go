@@ -0,0 +1,16 @@
+package postgres
+
+import (
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// keysetPage orders a query newest first, with ties broken by ID, and limits it to the page
+// starting after the cursor. The row comparison uses the (created_at, id) indexes.
+func keysetPage(db *gorm.DB, after *repository.Cursor, limit int) *gorm.DB {
+	if after != nil {
+		db = db.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	return db.Order("created_at DESC, id DESC").Limit(limit)
+}
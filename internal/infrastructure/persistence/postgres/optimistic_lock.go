@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"gin-boilerplate/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// compareAndSwap saves every column of model, whose version is read from and incremented in
+// version, only if the row still has the version model was read with. It returns
+// domain.ErrConflict when another update came first or the row is gone.
+func compareAndSwap(db *gorm.DB, model any, version *int64) error {
+	expected := *version
+	*version = expected + 1
+
+	result := db.Model(model).Where("version = ?", expected).Select("*").Updates(model)
+	if result.Error != nil {
+		*version = expected
+		return translateError(result.Error, domain.ErrNotFound)
+	}
+	if result.RowsAffected == 0 {
+		*version = expected
+		return domain.ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type previousPasswordRepository struct {
+	db *gorm.DB
+}
+
+// NewPreviousPasswordRepository creates a new PostgreSQL previous password repository
+func NewPreviousPasswordRepository(db *gorm.DB) repository.PreviousPasswordRepository {
+	return &previousPasswordRepository{
+		db: db,
+	}
+}
+
+// Create keeps a replaced password
+func (r *previousPasswordRepository) Create(ctx context.Context, password *entity.PreviousPassword) error {
+	if err := withContext(ctx, r.db).Create(password).Error; err != nil {
+		return fmt.Errorf("failed to create previous password: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// ListRecent returns the user's latest replaced passwords, newest first
+func (r *previousPasswordRepository) ListRecent(ctx context.Context, userID string, limit int) ([]*entity.PreviousPassword, error) {
+	var passwords []*entity.PreviousPassword
+	err := withContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&passwords).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list previous passwords: %w", translateError(err, domain.ErrNotFound))
+	}
+	return passwords, nil
+}
+
+// Prune deletes the user's replaced passwords but the latest keep
+func (r *previousPasswordRepository) Prune(ctx context.Context, userID string, keep int) error {
+	db := withContext(ctx, r.db)
+	latest := db.Model(&entity.PreviousPassword{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(keep)
+
+	err := db.Where("user_id = ? AND id NOT IN (?)", userID, latest).Delete(&entity.PreviousPassword{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to prune previous passwords: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
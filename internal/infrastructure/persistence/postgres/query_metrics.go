@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/metrics"
+
+	"gorm.io/gorm"
+)
+
+// queryStartKey stores the start time of a query on its statement
+const queryStartKey = "query_metrics:start"
+
+// queryMetricsPlugin is a GORM plugin timing every query
+type queryMetricsPlugin struct {
+	metrics *metrics.Metrics
+}
+
+func newQueryMetricsPlugin(m *metrics.Metrics) *queryMetricsPlugin {
+	return &queryMetricsPlugin{metrics: m}
+}
+
+// Name identifies the plugin
+func (p *queryMetricsPlugin) Name() string {
+	return "query_metrics"
+}
+
+// Initialize registers callbacks around every kind of query
+func (p *queryMetricsPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	processors := []struct {
+		operation     string
+		before, after callbackRegisterer
+	}{
+		{"create", callback.Create().Before("*"), callback.Create().After("*")},
+		{"query", callback.Query().Before("*"), callback.Query().After("*")},
+		{"update", callback.Update().Before("*"), callback.Update().After("*")},
+		{"delete", callback.Delete().Before("*"), callback.Delete().After("*")},
+		{"row", callback.Row().Before("*"), callback.Row().After("*")},
+		{"raw", callback.Raw().Before("*"), callback.Raw().After("*")},
+	}
+
+	for _, processor := range processors {
+		if err := processor.before.Register("query_metrics:before_"+processor.operation, start); err != nil {
+			return err
+		}
+		if err := processor.after.Register("query_metrics:after_"+processor.operation, p.observe(processor.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callbackRegisterer is a GORM callback positioned before or after the others
+type callbackRegisterer interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// start records when a query starts
+func start(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+// observe returns the callback recording how long a query took
+func (p *queryMetricsPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		begin, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		p.metrics.ObserveQuery(operation, db.Statement.Table, failed, time.Since(begin))
+	}
+}
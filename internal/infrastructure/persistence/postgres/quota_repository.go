@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+// NewQuotaRepository creates a new PostgreSQL quota repository
+func NewQuotaRepository(db *gorm.DB) repository.QuotaRepository {
+	return &quotaRepository{
+		db: db,
+	}
+}
+
+// UpsertRollup creates or replaces the usage total for a user, metric and period
+func (r *quotaRepository) UpsertRollup(ctx context.Context, rollup *entity.UsageRollup) error {
+	if err := withContext(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "metric"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"value":      rollup.Value,
+			"updated_at": time.Now(),
+		}),
+	}).Create(rollup).Error; err != nil {
+		return fmt.Errorf("failed to upsert usage rollup: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// FindRollup finds the usage total for a user, metric and period
+func (r *quotaRepository) FindRollup(ctx context.Context, userID string, metric entity.QuotaMetric, period string) (*entity.UsageRollup, error) {
+	var rollup entity.UsageRollup
+	if err := withContext(ctx, r.db).
+		Where("user_id = ? AND metric = ? AND period = ?", userID, metric, period).
+		First(&rollup).Error; err != nil {
+		return nil, fmt.Errorf("failed to find usage rollup: %w", translateError(err, domain.ErrNotFound))
+	}
+	return &rollup, nil
+}
+
+// FindOverride finds the quota override of a metric for a user
+func (r *quotaRepository) FindOverride(ctx context.Context, userID string, metric entity.QuotaMetric) (*entity.QuotaOverride, error) {
+	var override entity.QuotaOverride
+	if err := withContext(ctx, r.db).
+		Where("user_id = ? AND metric = ?", userID, metric).
+		First(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to find quota override: %w", translateError(err, domain.ErrNotFound))
+	}
+	return &override, nil
+}
+
+// FindOverridesByUserID finds all quota overrides for a user
+func (r *quotaRepository) FindOverridesByUserID(ctx context.Context, userID string) ([]*entity.QuotaOverride, error) {
+	var overrides []*entity.QuotaOverride
+	if err := withContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to find quota overrides: %w", translateError(err, domain.ErrNotFound))
+	}
+	return overrides, nil
+}
+
+// SaveOverride creates or updates a quota override
+func (r *quotaRepository) SaveOverride(ctx context.Context, override *entity.QuotaOverride) error {
+	if err := withContext(ctx, r.db).Save(override).Error; err != nil {
+		return fmt.Errorf("failed to save quota override: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// DeleteOverride deletes the quota override of a metric for a user
+func (r *quotaRepository) DeleteOverride(ctx context.Context, userID string, metric entity.QuotaMetric) error {
+	if err := withContext(ctx, r.db).
+		Where("user_id = ? AND metric = ?", userID, metric).
+		Delete(&entity.QuotaOverride{}).Error; err != nil {
+		return fmt.Errorf("failed to delete quota override: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
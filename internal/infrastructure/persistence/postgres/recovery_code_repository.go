@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository creates a new PostgreSQL recovery code repository
+func NewRecoveryCodeRepository(db *gorm.DB) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Replace deletes the user's recovery codes and stores codes in their place in one transaction
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID string, codes []*entity.RecoveryCode) error {
+	err := withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// Use marks the user's unused recovery code of the hash as used. The update only matches an
+// unused code, so a code can't be used twice by concurrent logins.
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID, codeHash string) error {
+	result := withContext(ctx, r.db).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to use recovery code: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CountUnused returns how many of the user's recovery codes are left
+func (r *recoveryCodeRepository) CountUnused(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := withContext(ctx, r.db).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", translateError(err, domain.ErrNotFound))
+	}
+	return count, nil
+}
+
+// DeleteByUserID deletes all the user's recovery codes
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new PostgreSQL role repository
+func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+// Create stores a role with its permissions
+func (r *roleRepository) Create(ctx context.Context, role *entity.CustomRole) error {
+	if err := withContext(ctx, r.db).Create(role).Error; err != nil {
+		return fmt.Errorf("failed to create role: %w", translateError(err, domain.ErrRoleNotFound))
+	}
+	return nil
+}
+
+// FindByName returns the role with its permissions
+func (r *roleRepository) FindByName(ctx context.Context, name entity.Role) (*entity.CustomRole, error) {
+	var role entity.CustomRole
+	if err := withContext(ctx, r.db).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, fmt.Errorf("failed to find role: %w", translateError(err, domain.ErrRoleNotFound))
+	}
+	return &role, nil
+}
+
+// List returns every role with its permissions, by name
+func (r *roleRepository) List(ctx context.Context) ([]*entity.CustomRole, error) {
+	var roles []*entity.CustomRole
+	if err := withContext(ctx, r.db).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", translateError(err, domain.ErrRoleNotFound))
+	}
+	return roles, nil
+}
+
+// Update stores the description of the role and replaces its permissions in one transaction
+func (r *roleRepository) Update(ctx context.Context, role *entity.CustomRole) error {
+	now := time.Now()
+	err := withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.CustomRole{}).Where("name = ?", role.Name).Updates(map[string]interface{}{
+			"description": role.Description,
+			"updated_at":  now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrRoleNotFound
+		}
+
+		if err := tx.Where("role = ?", role.Name).Delete(&entity.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", translateError(err, domain.ErrRoleNotFound))
+	}
+	role.UpdatedAt = now
+	return nil
+}
+
+// Delete deletes a role, and its permissions with the foreign key
+func (r *roleRepository) Delete(ctx context.Context, name entity.Role) error {
+	result := withContext(ctx, r.db).Where("name = ?", name).Delete(&entity.CustomRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete role: %w", translateError(result.Error, domain.ErrRoleNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return nil
+}
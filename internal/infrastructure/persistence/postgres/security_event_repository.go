@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventRepository creates a new PostgreSQL security event repository
+func NewSecurityEventRepository(db *gorm.DB) repository.SecurityEventRepository {
+	return &securityEventRepository{
+		db: db,
+	}
+}
+
+// Create records a security event
+func (r *securityEventRepository) Create(ctx context.Context, event *entity.SecurityEvent) error {
+	if err := withContext(ctx, r.db).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create security event: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// List returns a page of the security events matching the filter, newest first
+func (r *securityEventRepository) List(ctx context.Context, filter repository.SecurityEventFilter, limit, offset int) ([]*entity.SecurityEvent, error) {
+	var events []*entity.SecurityEvent
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", translateError(err, domain.ErrNotFound))
+	}
+	return events, nil
+}
+
+// Count returns the number of security events matching the filter
+func (r *securityEventRepository) Count(ctx context.Context, filter repository.SecurityEventFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count security events: %w", translateError(err, domain.ErrNotFound))
+	}
+	return count, nil
+}
+
+// DeleteBefore deletes the security events recorded before the time
+func (r *securityEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).Where("created_at < ?", before).Delete(&entity.SecurityEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete security events: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// TouchDevice updates the device when the user has been seen on it, and creates it otherwise
+func (r *securityEventRepository) TouchDevice(ctx context.Context, device *entity.KnownDevice) (isNew, hadDevices bool, err error) {
+	db := withContext(ctx, r.db)
+
+	result := db.Model(&entity.KnownDevice{}).
+		Where("user_id = ? AND fingerprint = ?", device.UserID, device.Fingerprint).
+		Updates(map[string]interface{}{"last_seen_at": device.LastSeenAt, "last_ip": device.LastIP})
+	if result.Error != nil {
+		return false, false, fmt.Errorf("failed to update known device: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if result.RowsAffected > 0 {
+		return false, true, nil
+	}
+
+	var count int64
+	if err := db.Model(&entity.KnownDevice{}).Where("user_id = ?", device.UserID).Count(&count).Error; err != nil {
+		return false, false, fmt.Errorf("failed to count known devices: %w", translateError(err, domain.ErrNotFound))
+	}
+
+	if err := db.Create(device).Error; err != nil {
+		err = translateError(err, domain.ErrNotFound)
+		// A concurrent login from the same device created it first
+		if errors.Is(err, domain.ErrDuplicate) {
+			return false, true, nil
+		}
+		return false, false, fmt.Errorf("failed to create known device: %w", err)
+	}
+	return true, count > 0, nil
+}
+
+// TouchCountry updates the country when the user has logged in from it, and creates it otherwise
+func (r *securityEventRepository) TouchCountry(ctx context.Context, country *entity.KnownCountry) (isNew, hadCountries bool, err error) {
+	db := withContext(ctx, r.db)
+
+	result := db.Model(&entity.KnownCountry{}).
+		Where("user_id = ? AND country = ?", country.UserID, country.Country).
+		Updates(map[string]interface{}{"last_seen_at": country.LastSeenAt, "last_ip": country.LastIP})
+	if result.Error != nil {
+		return false, false, fmt.Errorf("failed to update known country: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if result.RowsAffected > 0 {
+		return false, true, nil
+	}
+
+	var count int64
+	if err := db.Model(&entity.KnownCountry{}).Where("user_id = ?", country.UserID).Count(&count).Error; err != nil {
+		return false, false, fmt.Errorf("failed to count known countries: %w", translateError(err, domain.ErrNotFound))
+	}
+
+	if err := db.Create(country).Error; err != nil {
+		err = translateError(err, domain.ErrNotFound)
+		// A concurrent login from the same country created it first
+		if errors.Is(err, domain.ErrDuplicate) {
+			return false, true, nil
+		}
+		return false, false, fmt.Errorf("failed to create known country: %w", err)
+	}
+	return true, count > 0, nil
+}
+
+// filtered returns a query of the security events matching the filter
+func (r *securityEventRepository) filtered(ctx context.Context, filter repository.SecurityEventFilter) *gorm.DB {
+	query := withContext(ctx, r.db).Model(&entity.SecurityEvent{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	return query
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// countCreatedPerDay counts the rows of model created on each UTC day since the time, oldest first
+func countCreatedPerDay(ctx context.Context, db *gorm.DB, model interface{}, since time.Time) ([]repository.DailyCount, error) {
+	counts := []repository.DailyCount{}
+	err := withContext(ctx, db).
+		Model(model).
+		Select("date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, count(*) AS count").
+		Where("created_at >= ?", since).
+		Group("day").
+		Order("day").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The day comes back without a time zone, in UTC
+	for i := range counts {
+		day := counts[i].Day
+		counts[i].Day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return counts, nil
+}
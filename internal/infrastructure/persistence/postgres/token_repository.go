@@ -2,10 +2,10 @@ package postgres
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 
@@ -25,8 +25,8 @@ func NewTokenRepository(db *gorm.DB) repository.TokenRepository {
 
 // Create creates a new refresh token
 func (r *tokenRepository) Create(ctx context.Context, token *entity.Token) error {
-	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
-		return fmt.Errorf("failed to create token: %w", err)
+	if err := withContext(ctx, r.db).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
@@ -34,11 +34,8 @@ func (r *tokenRepository) Create(ctx context.Context, token *entity.Token) error
 // FindByRefreshToken finds a token by refresh token
 func (r *tokenRepository) FindByRefreshToken(ctx context.Context, refreshToken string) (*entity.Token, error) {
 	var token entity.Token
-	if err := r.db.WithContext(ctx).Where("refresh_token = ?", refreshToken).First(&token).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find token by refresh token: %w", err)
+	if err := withContext(ctx, r.db).Where("refresh_token = ?", refreshToken).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("failed to find token by refresh token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return &token, nil
 }
@@ -46,79 +43,118 @@ func (r *tokenRepository) FindByRefreshToken(ctx context.Context, refreshToken s
 // FindByUserID finds tokens by user ID
 func (r *tokenRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Token, error) {
 	var tokens []*entity.Token
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Find(&tokens).Error; err != nil {
-		return nil, fmt.Errorf("failed to find tokens by user ID: %w", err)
+		return nil, fmt.Errorf("failed to find tokens by user ID: %w", translateError(err, domain.ErrNotFound))
+	}
+	return tokens, nil
+}
+
+// FindActiveByUserID finds the unexpired tokens of a user, newest first
+func (r *tokenRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*entity.Token, error) {
+	var tokens []*entity.Token
+	if err := withContext(ctx, r.db).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to find active tokens by user ID: %w", translateError(err, domain.ErrNotFound))
 	}
 	return tokens, nil
 }
 
 // Update updates a token
 func (r *tokenRepository) Update(ctx context.Context, token *entity.Token) error {
-	if err := r.db.WithContext(ctx).Save(token).Error; err != nil {
-		return fmt.Errorf("failed to update token: %w", err)
+	if err := withContext(ctx, r.db).Save(token).Error; err != nil {
+		return fmt.Errorf("failed to update token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
 // Delete deletes a token by ID
 func (r *tokenRepository) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Token{}).Error; err != nil {
-		return fmt.Errorf("failed to delete token: %w", err)
+	if err := withContext(ctx, r.db).Where("id = ?", id).Delete(&entity.Token{}).Error; err != nil {
+		return fmt.Errorf("failed to delete token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
 // DeleteByRefreshToken deletes a token by refresh token
 func (r *tokenRepository) DeleteByRefreshToken(ctx context.Context, refreshToken string) error {
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Where("refresh_token = ?", refreshToken).
 		Delete(&entity.Token{}).Error; err != nil {
-		return fmt.Errorf("failed to delete token by refresh token: %w", err)
+		return fmt.Errorf("failed to delete token by refresh token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
 // DeleteByUserID deletes all tokens for a user (logout from all devices)
 func (r *tokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Delete(&entity.Token{}).Error; err != nil {
-		return fmt.Errorf("failed to delete tokens by user ID: %w", err)
+		return fmt.Errorf("failed to delete tokens by user ID: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
-// DeleteExpiredTokens deletes all expired tokens
-func (r *tokenRepository) DeleteExpiredTokens(ctx context.Context) error {
-	if err := r.db.WithContext(ctx).
+// DeleteExpiredTokens permanently deletes all expired tokens, soft-deleted or not, and returns
+// how many were deleted
+func (r *tokenRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	result := withContext(ctx, r.db).
+		Unscoped().
 		Where("expires_at < ?", time.Now()).
-		Delete(&entity.Token{}).Error; err != nil {
-		return fmt.Errorf("failed to delete expired tokens: %w", err)
+		Delete(&entity.Token{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired tokens: %w", translateError(result.Error, domain.ErrNotFound))
 	}
-	return nil
+	return result.RowsAffected, nil
+}
+
+// CountRows returns the number of rows of the token table, soft-deleted ones included
+func (r *tokenRepository) CountRows(ctx context.Context) (int64, error) {
+	var count int64
+	if err := withContext(ctx, r.db).Unscoped().Model(&entity.Token{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", translateError(err, domain.ErrNotFound))
+	}
+	return count, nil
 }
 
 // RevokeToken revokes a token by setting expiration to past
 func (r *tokenRepository) RevokeToken(ctx context.Context, refreshToken string) error {
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Model(&entity.Token{}).
 		Where("refresh_token = ?", refreshToken).
 		Update("expires_at", time.Now().Add(-1*time.Hour)).Error; err != nil {
-		return fmt.Errorf("failed to revoke token: %w", err)
+		return fmt.Errorf("failed to revoke token: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
 // RevokeAllUserTokens revokes all tokens for a user
 func (r *tokenRepository) RevokeAllUserTokens(ctx context.Context, userID string) error {
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Model(&entity.Token{}).
 		Where("user_id = ?", userID).
 		Update("expires_at", time.Now().Add(-1*time.Hour)).Error; err != nil {
-		return fmt.Errorf("failed to revoke all user tokens: %w", err)
+		return fmt.Errorf("failed to revoke all user tokens: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// RevokeSession revokes the tokens of a session of a user
+func (r *tokenRepository) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	result := withContext(ctx, r.db).
+		Model(&entity.Token{}).
+		Where("user_id = ? AND session_id = ? AND expires_at > ?", userID, sessionID, time.Now()).
+		Update("expires_at", time.Now().Add(-1*time.Hour))
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
 	}
 	return nil
 }
@@ -126,11 +162,11 @@ func (r *tokenRepository) RevokeAllUserTokens(ctx context.Context, userID string
 // IsTokenValid checks if a refresh token is valid and not expired
 func (r *tokenRepository) IsTokenValid(ctx context.Context, refreshToken string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Model(&entity.Token{}).
 		Where("refresh_token = ? AND expires_at > ?", refreshToken, time.Now()).
 		Count(&count).Error; err != nil {
-		return false, fmt.Errorf("failed to check token validity: %w", err)
+		return false, fmt.Errorf("failed to check token validity: %w", translateError(err, domain.ErrNotFound))
 	}
 	return count > 0, nil
-}
\ No newline at end of file
+}
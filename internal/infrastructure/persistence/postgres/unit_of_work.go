@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// txContextKey stores the transaction of a unit of work in its context
+type txContextKey struct{}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a unit of work running transactions on db
+func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
+	return &unitOfWork{
+		db: db,
+	}
+}
+
+// Do runs fn in a transaction, or in a savepoint of the transaction ctx already carries
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return transactionOf(ctx, u.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// withContext returns transactionOf(ctx, db) bound to ctx and scoped to the soft-deleted records ctx asks for. Repositories run every query
+// through it.
+func withContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return transactionOf(ctx, db).WithContext(ctx).Scopes(deletedScope(repository.DeletedScopeFromContext(ctx)))
+}
+
+// transactionOf returns the transaction of the unit of work ctx belongs to, or db outside of one
+func transactionOf(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db
+}
+
+// deletedScope returns the GORM scope showing the soft-deleted records of scope. Unscoped
+// queries also make deletes permanent.
+func deletedScope(scope repository.DeletedScope) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch scope {
+		case repository.IncludeDeleted:
+			return db.Unscoped()
+		case repository.OnlyDeleted:
+			deletedAt := clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}
+			return db.Unscoped().Where(clause.Neq{Column: deletedAt, Value: nil})
+		default:
+			return db
+		}
+	}
+}
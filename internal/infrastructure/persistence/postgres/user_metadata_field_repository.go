@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type userMetadataFieldRepository struct {
+	db *gorm.DB
+}
+
+// NewUserMetadataFieldRepository creates a new PostgreSQL user metadata field repository
+func NewUserMetadataFieldRepository(db *gorm.DB) repository.UserMetadataFieldRepository {
+	return &userMetadataFieldRepository{
+		db: db,
+	}
+}
+
+// Create stores a field
+func (r *userMetadataFieldRepository) Create(ctx context.Context, field *entity.UserMetadataField) error {
+	if err := withContext(ctx, r.db).Create(field).Error; err != nil {
+		return fmt.Errorf("failed to create metadata field: %w", translateError(err, domain.ErrMetadataFieldNotFound))
+	}
+	return nil
+}
+
+// FindByKey returns the field
+func (r *userMetadataFieldRepository) FindByKey(ctx context.Context, key string) (*entity.UserMetadataField, error) {
+	var field entity.UserMetadataField
+	if err := withContext(ctx, r.db).Where("key = ?", key).First(&field).Error; err != nil {
+		return nil, fmt.Errorf("failed to find metadata field: %w", translateError(err, domain.ErrMetadataFieldNotFound))
+	}
+	return &field, nil
+}
+
+// List returns every field, by key
+func (r *userMetadataFieldRepository) List(ctx context.Context) ([]*entity.UserMetadataField, error) {
+	var fields []*entity.UserMetadataField
+	if err := withContext(ctx, r.db).Order("key").Find(&fields).Error; err != nil {
+		return nil, fmt.Errorf("failed to list metadata fields: %w", translateError(err, domain.ErrMetadataFieldNotFound))
+	}
+	return fields, nil
+}
+
+// Update stores the type and description of the field
+func (r *userMetadataFieldRepository) Update(ctx context.Context, field *entity.UserMetadataField) error {
+	now := time.Now()
+	result := withContext(ctx, r.db).Model(&entity.UserMetadataField{}).Where("key = ?", field.Key).Updates(map[string]interface{}{
+		"type":        field.Type,
+		"description": field.Description,
+		"updated_at":  now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update metadata field: %w", translateError(result.Error, domain.ErrMetadataFieldNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMetadataFieldNotFound
+	}
+	field.UpdatedAt = now
+	return nil
+}
+
+// Delete deletes a field
+func (r *userMetadataFieldRepository) Delete(ctx context.Context, key string) error {
+	result := withContext(ctx, r.db).Where("key = ?", key).Delete(&entity.UserMetadataField{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete metadata field: %w", translateError(result.Error, domain.ErrMetadataFieldNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMetadataFieldNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type userProviderRepository struct {
+	db *gorm.DB
+}
+
+// NewUserProviderRepository creates a new PostgreSQL user provider repository
+func NewUserProviderRepository(db *gorm.DB) repository.UserProviderRepository {
+	return &userProviderRepository{
+		db: db,
+	}
+}
+
+// Create links a provider account
+func (r *userProviderRepository) Create(ctx context.Context, link *entity.UserProvider) error {
+	if err := withContext(ctx, r.db).Create(link).Error; err != nil {
+		return fmt.Errorf("failed to create user provider: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// FindByProviderID finds the link of a provider account
+func (r *userProviderRepository) FindByProviderID(ctx context.Context, provider entity.Provider, providerID string) (*entity.UserProvider, error) {
+	var link entity.UserProvider
+	if err := withContext(ctx, r.db).Where("provider = ? AND provider_id = ?", provider, providerID).First(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user provider: %w", translateError(err, domain.ErrNotFound))
+	}
+	return &link, nil
+}
+
+// ListByUserID returns the links of the user, oldest first
+func (r *userProviderRepository) ListByUserID(ctx context.Context, userID string) ([]*entity.UserProvider, error) {
+	var links []*entity.UserProvider
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Order("created_at ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user providers: %w", translateError(err, domain.ErrNotFound))
+	}
+	return links, nil
+}
+
+// Delete unlinks the user's account at the provider
+func (r *userProviderRepository) Delete(ctx context.Context, userID string, provider entity.Provider) error {
+	result := withContext(ctx, r.db).Where("user_id = ? AND provider = ?", userID, provider).Delete(&entity.UserProvider{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user provider: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
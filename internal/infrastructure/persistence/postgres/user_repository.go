@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/repository"
 
@@ -24,8 +27,8 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 
 // Create creates a new user
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+	if err := withContext(ctx, r.db).Create(user).Error; err != nil {
+		return fmt.Errorf("failed to create user: %w", translateUserError(err))
 	}
 	return nil
 }
@@ -33,11 +36,8 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 // FindByID finds a user by ID
 func (r *userRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find user by ID: %w", err)
+	if err := withContext(ctx, r.db).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user by ID: %w", translateUserError(err))
 	}
 	return &user, nil
 }
@@ -45,73 +45,85 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (*entity.User,
 // FindByEmail finds a user by email
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	if err := withContext(ctx, r.db).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user by email: %w", translateUserError(err))
 	}
 	return &user, nil
 }
 
-// FindByProviderID finds a user by provider and provider ID
-func (r *userRepository) FindByProviderID(ctx context.Context, provider entity.Provider, providerID string) (*entity.User, error) {
-	var user entity.User
-	if err := r.db.WithContext(ctx).Where("provider = ? AND provider_id = ?", provider, providerID).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find user by provider ID: %w", err)
-	}
-	return &user, nil
-}
-
-// Update updates a user
+// Update updates a user if its version is unchanged, returning domain.ErrConflict otherwise
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
-	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+	if err := compareAndSwap(withContext(ctx, r.db), user, &user.Version); err != nil {
+		return fmt.Errorf("failed to update user: %w", translateUserError(err))
 	}
 	return nil
 }
 
 // Delete deletes a user by ID
 func (r *userRepository) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.User{}).Error; err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+	if err := withContext(ctx, r.db).Where("id = ?", id).Delete(&entity.User{}).Error; err != nil {
+		return fmt.Errorf("failed to delete user: %w", translateError(err, domain.ErrNotFound))
 	}
 	return nil
 }
 
-// List returns a list of users with pagination
-func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+// Restore clears the deleted_at of a soft-deleted user
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	result := withContext(ctx, r.db).
+		Unscoped().
+		Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore user: %w", translateUserError(result.Error))
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// List returns a page of the users matching the filter, in the sort order
+func (r *userRepository) List(ctx context.Context, filter repository.UserFilter, sort repository.UserSort, limit, offset int) ([]*entity.User, error) {
 	var users []*entity.User
-	if err := r.db.WithContext(ctx).
-		Order("created_at DESC").
+	if err := r.filtered(ctx, filter).
+		Order(userOrder(sort)).
 		Limit(limit).
 		Offset(offset).
 		Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
+		return nil, fmt.Errorf("failed to list users: %w", translateError(err, domain.ErrNotFound))
+	}
+	return users, nil
+}
+
+// ListAfter returns a keyset page of the users matching the filter, newest first
+func (r *userRepository) ListAfter(ctx context.Context, filter repository.UserFilter, after *repository.Cursor, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	if err := keysetPage(r.filtered(ctx, filter), after, limit).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", translateError(err, domain.ErrNotFound))
 	}
 	return users, nil
 }
 
-// Count returns the total number of users
-func (r *userRepository) Count(ctx context.Context) (int64, error) {
+// Count returns the number of users matching the filter
+func (r *userRepository) Count(ctx context.Context, filter repository.UserFilter) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.User{}).Count(&count).Error; err != nil {
-		return 0, fmt.Errorf("failed to count users: %w", err)
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", translateError(err, domain.ErrNotFound))
 	}
 	return count, nil
 }
 
-// EmailExists checks if email already exists
+// EmailExists checks if email already exists. Soft-deleted users keep their email until they are
+// deleted permanently.
 func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
+		Unscoped().
 		Model(&entity.User{}).
 		Where("email = ?", email).
 		Count(&count).Error; err != nil {
-		return false, fmt.Errorf("failed to check email existence: %w", err)
+		return false, fmt.Errorf("failed to check email existence: %w", translateError(err, domain.ErrNotFound))
 	}
 	return count > 0, nil
 }
@@ -119,13 +131,117 @@ func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, e
 // FindByRole finds users by role
 func (r *userRepository) FindByRole(ctx context.Context, role entity.Role, limit, offset int) ([]*entity.User, error) {
 	var users []*entity.User
-	if err := r.db.WithContext(ctx).
+	if err := withContext(ctx, r.db).
 		Where("role = ?", role).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("failed to find users by role: %w", err)
+		return nil, fmt.Errorf("failed to find users by role: %w", translateError(err, domain.ErrNotFound))
 	}
 	return users, nil
-}
\ No newline at end of file
+}
+
+// PurgeDeletedBefore permanently deletes the users soft-deleted before the time
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).
+		Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(&entity.User{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", translateError(result.Error, domain.ErrNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// AvatarsInUse returns those of the avatar URLs some user, soft-deleted or not, still has,
+// awaiting moderation or not
+func (r *userRepository) AvatarsInUse(ctx context.Context, avatarURLs []string) ([]string, error) {
+	inUse := []string{}
+	if len(avatarURLs) == 0 {
+		return inUse, nil
+	}
+	for _, column := range []string{"avatar", "pending_avatar"} {
+		var urls []string
+		if err := withContext(ctx, r.db).
+			Unscoped().
+			Model(&entity.User{}).
+			Where(column+" IN ?", avatarURLs).
+			Distinct().
+			Pluck(column, &urls).Error; err != nil {
+			return nil, fmt.Errorf("failed to find avatars in use: %w", translateError(err, domain.ErrNotFound))
+		}
+		inUse = append(inUse, urls...)
+	}
+	return inUse, nil
+}
+
+// CountCreatedPerDay returns how many users were created on each UTC day since the time, oldest
+// first
+func (r *userRepository) CountCreatedPerDay(ctx context.Context, since time.Time) ([]repository.DailyCount, error) {
+	counts, err := countCreatedPerDay(ctx, r.db, &entity.User{}, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users per day: %w", translateError(err, domain.ErrNotFound))
+	}
+	return counts, nil
+}
+
+// filtered returns a query of the users matching the filter
+func (r *userRepository) filtered(ctx context.Context, filter repository.UserFilter) *gorm.DB {
+	query := withContext(ctx, r.db).Model(&entity.User{})
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Search)) + "%"
+		query = query.Where("(LOWER(email) LIKE ? OR LOWER(name) LIKE ?)", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.EmailVerified != nil {
+		query = query.Where("email_verified = ?", *filter.EmailVerified)
+	}
+	if filter.PendingAvatar {
+		query = query.Where("pending_avatar_at IS NOT NULL")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	return query
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, so searches match them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// userOrder returns the ORDER BY clause of the sort, breaking ties by ID so pages don't overlap
+func userOrder(sort repository.UserSort) string {
+	column := "created_at"
+	switch sort.Field {
+	case repository.UserSortEmail:
+		column = "email"
+	case repository.UserSortName:
+		column = "LOWER(name)"
+	case repository.UserSortPendingAvatarAt:
+		column = "pending_avatar_at"
+	}
+
+	direction := "DESC"
+	if sort.Ascending {
+		direction = "ASC"
+	}
+	return column + " " + direction + ", id " + direction
+}
+
+// translateUserError translates err like translateError, reporting a unique violation as
+// domain.ErrEmailAlreadyExists since email is the only unique column besides the primary key
+func translateUserError(err error) error {
+	err = translateError(err, domain.ErrUserNotFound)
+	if errors.Is(err, domain.ErrDuplicate) {
+		return domain.ErrEmailAlreadyExists.Wrap(err)
+	}
+	return err
+}
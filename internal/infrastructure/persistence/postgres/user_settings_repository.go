@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type userSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSettingsRepository creates a new PostgreSQL user settings repository
+func NewUserSettingsRepository(db *gorm.DB) repository.UserSettingsRepository {
+	return &userSettingsRepository{
+		db: db,
+	}
+}
+
+// FindByUserID returns the settings of a user, or the defaults
+func (r *userSettingsRepository) FindByUserID(ctx context.Context, userID string) (*entity.UserSettings, error) {
+	var settings entity.UserSettings
+	err := withContext(ctx, r.db).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.NewUserSettings(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user settings: %w", translateError(err, domain.ErrNotFound))
+	}
+	return &settings, nil
+}
+
+// Save creates or replaces the settings of a user
+func (r *userSettingsRepository) Save(ctx context.Context, settings *entity.UserSettings) error {
+	if err := withContext(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(settings).Error; err != nil {
+		return fmt.Errorf("failed to save user settings: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
+
+// DeleteByUserID deletes the settings of a user
+func (r *userSettingsRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.UserSettings{}).Error; err != nil {
+		return fmt.Errorf("failed to delete user settings: %w", translateError(err, domain.ErrNotFound))
+	}
+	return nil
+}
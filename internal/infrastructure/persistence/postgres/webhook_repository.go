@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new PostgreSQL webhook repository
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// Create creates a new webhook
+func (r *webhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	if err := withContext(ctx, r.db).Create(webhook).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return nil
+}
+
+// FindByID finds a webhook by ID
+func (r *webhookRepository) FindByID(ctx context.Context, id string) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	if err := withContext(ctx, r.db).Where("id = ?", id).First(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to find webhook by ID: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return &webhook, nil
+}
+
+// FindByUserID finds the webhooks of a user, newest first
+func (r *webhookRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Webhook, error) {
+	var webhooks []*entity.Webhook
+	if err := withContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to find webhooks by user ID: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return webhooks, nil
+}
+
+// CountByUserID returns the number of webhooks of a user
+func (r *webhookRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := withContext(ctx, r.db).Model(&entity.Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count webhooks: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return count, nil
+}
+
+// Update updates a webhook
+func (r *webhookRepository) Update(ctx context.Context, webhook *entity.Webhook) error {
+	if err := withContext(ctx, r.db).Save(webhook).Error; err != nil {
+		return fmt.Errorf("failed to update webhook: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return nil
+}
+
+// Delete deletes a webhook and its deliveries in one transaction
+func (r *webhookRepository) Delete(ctx context.Context, id string) error {
+	err := withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&entity.Webhook{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return nil
+}
+
+// CreateDelivery records a delivery attempt
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if err := withContext(ctx, r.db).Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return nil
+}
+
+// ListDeliveries returns a page of the delivery attempts of a webhook, newest first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+	if err := withContext(ctx, r.db).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return deliveries, nil
+}
+
+// CountDeliveries returns the number of delivery attempts of a webhook
+func (r *webhookRepository) CountDeliveries(ctx context.Context, webhookID string) (int64, error) {
+	var count int64
+	if err := withContext(ctx, r.db).Model(&entity.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count webhook deliveries: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return count, nil
+}
+
+// DeleteDeliveriesBefore deletes the delivery attempts made before the time
+func (r *webhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).Where("created_at < ?", before).Delete(&entity.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", translateError(result.Error, domain.ErrWebhookNotFound))
+	}
+	return result.RowsAffected, nil
+}
+
+// CountDeliveriesSince counts the delivery attempts made since the time, and those of them that
+// failed
+func (r *webhookRepository) CountDeliveriesSince(ctx context.Context, since time.Time) (attempts, failed int64, err error) {
+	var counts struct {
+		Attempts int64
+		Failed   int64
+	}
+	if err := withContext(ctx, r.db).
+		Model(&entity.WebhookDelivery{}).
+		Select("count(*) AS attempts, count(*) FILTER (WHERE NOT succeeded) AS failed").
+		Where("created_at >= ?", since).
+		Scan(&counts).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count webhook deliveries: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return counts.Attempts, counts.Failed, nil
+}
+
+// FailingWebhooks returns the limit webhooks with the most failed delivery attempts since the
+// time, most failures first
+func (r *webhookRepository) FailingWebhooks(ctx context.Context, since time.Time, limit int) ([]repository.WebhookDeliveryStats, error) {
+	stats := []repository.WebhookDeliveryStats{}
+	if err := withContext(ctx, r.db).
+		Model(&entity.WebhookDelivery{}).
+		Select("webhook_id, count(*) AS attempts, count(*) FILTER (WHERE NOT succeeded) AS failed").
+		Where("created_at >= ?", since).
+		Group("webhook_id").
+		Having("count(*) FILTER (WHERE NOT succeeded) > 0").
+		Order("failed DESC, webhook_id").
+		Limit(limit).
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to find failing webhooks: %w", translateError(err, domain.ErrWebhookNotFound))
+	}
+	return stats, nil
+}
@@ -8,36 +8,52 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// incrementWithExpiryScript increments a counter and, in the same step, sets its expiration when
+// it has none: when the increment created it, or when an earlier expiration was lost
+var incrementWithExpiryScript = redis.NewScript(`
+local result = redis.call("INCRBY", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return result
+`)
+
 type RedisClient struct {
 	client *redis.Client
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-	PoolSize int
+	Host         string
+	Port         string
+	Password     string
+	DB           int
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration
 }
 
 func NewRedisClient(config RedisConfig) (*RedisClient, error) {
 	// Build Redis connection options
 	opts := &redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", config.Host, config.Port),
-		Password: config.Password,
-		DB:       config.DB,
-		PoolSize: config.PoolSize,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolTimeout: 4 * time.Second,
+		Addr:         fmt.Sprintf("%s:%s", config.Host, config.Port),
+		Password:     config.Password,
+		DB:           config.DB,
+		PoolSize:     config.PoolSize,
+		MinIdleConns: config.MinIdleConns,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		PoolTimeout:  config.PoolTimeout,
 	}
 
 	// Create Redis client
 	client := redis.NewClient(opts)
 
 	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
@@ -53,19 +69,24 @@ func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
 
+// Ping checks that Redis answers within the context deadline
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return r.client.Set(ctx, key, value, expiration).Err()
 }
 
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	result, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
 	if err != nil {
 		return "", err
 	}
-	if result == nil {
-		return "", redis.Nil
-	}
-	return result.(string), nil
+	return result, nil
 }
 
 func (r *RedisClient) Del(ctx context.Context, key string) error {
@@ -100,6 +121,55 @@ func (r *RedisClient) Expire(ctx context.Context, key string, expiration time.Du
 	return r.client.Expire(ctx, key, expiration).Err()
 }
 
+func (r *RedisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.client.TTL(ctx, key).Result()
+}
+
+// IncrementWithExpiry atomically increments a counter and sets its expiration when
+// the counter is created, so fixed-window counters expire on their own
+func (r *RedisClient) IncrementWithExpiry(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return r.IncrementByWithExpiry(ctx, key, 1, expiration)
+}
+
+// IncrementByWithExpiry atomically increments a counter by amount and sets its
+// expiration when the counter has none, so a counter never outlives its window
+// even if a client dies mid-way
+func (r *RedisClient) IncrementByWithExpiry(ctx context.Context, key string, amount int64, expiration time.Duration) (int64, error) {
+	return incrementWithExpiryScript.Run(ctx, r.client, []string{key}, amount, expiration.Milliseconds()).Int64()
+}
+
+func (r *RedisClient) DecrementBy(ctx context.Context, key string, amount int64) (int64, error) {
+	return r.client.DecrBy(ctx, key, amount).Result()
+}
+
+// AddToSetWithExpiry adds a member to a set and restarts the set's expiration, in one
+// transaction
+func (r *RedisClient) AddToSetWithExpiry(ctx context.Context, key, member string, expiration time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.SAdd(ctx, key, member)
+	pipe.PExpire(ctx, key, expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SetMembers returns the members of a set, or none when it doesn't exist
+func (r *RedisClient) SetMembers(ctx context.Context, key string) ([]string, error) {
+	return r.client.SMembers(ctx, key).Result()
+}
+
+// Scan returns all keys matching a pattern without blocking the server like KEYS does
+func (r *RedisClient) Scan(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 func (r *RedisClient) GetClient() *redis.Client {
 	return r.client
-}
\ No newline at end of file
+}
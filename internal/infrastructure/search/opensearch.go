@@ -0,0 +1,318 @@
+// Package search indexes documents in OpenSearch or Elasticsearch, which then answer document
+// searches in place of Postgres
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+	"gin-boilerplate/internal/domain/service"
+)
+
+// maxErrorBody bounds how much of an API error response is kept in the error
+const maxErrorBody = 1024
+
+// Snippets are up to two fragments of about snippetFragmentSize characters, like the Postgres
+// search's
+const (
+	snippetFragments    = 2
+	snippetFragmentSize = 200
+	snippetDelimiter    = " … "
+)
+
+// Fields are boosted like the weights of the Postgres search vector: the title counts most, then
+// the description, then the extracted text
+var searchFields = []string{"title^5", "description^2", "text"}
+
+// indexSettings map the fields of indexed documents. The standard analyzer doesn't stem words, so
+// documents in any language match, as with the simple configuration of the Postgres search.
+var indexSettings = map[string]any{
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"user_id":     map[string]any{"type": "keyword"},
+			"title":       map[string]any{"type": "text"},
+			"description": map[string]any{"type": "text"},
+			"text":        map[string]any{"type": "text"},
+			"created_at":  map[string]any{"type": "date"},
+		},
+	},
+}
+
+// Config configures the OpenSearch or Elasticsearch cluster documents are indexed in
+type Config struct {
+	// URL is the base URL of the cluster, such as http://localhost:9200
+	URL string
+	// Index is the name of the index of the documents
+	Index string
+	// Username and Password authenticate with HTTP basic authentication; empty sends none
+	Username string
+	Password string
+	// Timeout bounds each request
+	Timeout time.Duration
+}
+
+// OpenSearch indexes and searches documents with the REST API of OpenSearch, which Elasticsearch 7
+// and later share for what is used here
+type OpenSearch struct {
+	config Config
+	client *http.Client
+}
+
+var _ service.SearchIndexer = (*OpenSearch)(nil)
+
+// NewOpenSearch creates a new OpenSearch indexer. Call EnsureIndex before indexing.
+func NewOpenSearch(cfg Config) *OpenSearch {
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	return &OpenSearch{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// indexedDocument is the part of a document kept in the index
+type indexedDocument struct {
+	UserID      string    `json:"user_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Text        string    `json:"text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// searchResponse is the part of a search response with the hits and their highlights
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID        string              `json:"_id"`
+			Score     float64             `json:"_score"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// EnsureIndex creates the index of the documents when it doesn't exist yet
+func (s *OpenSearch) EnsureIndex(ctx context.Context) error {
+	status, _, err := s.do(ctx, http.MethodHead, s.indexPath(), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+
+	status, body, err := s.do(ctx, http.MethodPut, s.indexPath(), indexSettings)
+	if err != nil {
+		return err
+	}
+	// Another instance may have just created it
+	if status == http.StatusBadRequest && bytes.Contains(body, []byte("resource_already_exists_exception")) {
+		return nil
+	}
+	return s.check("create index", status, body)
+}
+
+// Index adds the document to the index, or replaces it
+func (s *OpenSearch) Index(ctx context.Context, document *entity.Document) error {
+	status, body, err := s.do(ctx, http.MethodPut, s.documentPath(document.ID), indexedDocument{
+		UserID:      document.UserID,
+		Title:       document.Title,
+		Description: document.Description,
+		Text:        document.ExtractedText,
+		CreatedAt:   document.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return s.check("index document", status, body)
+}
+
+// Delete removes a document from the index
+func (s *OpenSearch) Delete(ctx context.Context, documentID string) error {
+	status, body, err := s.do(ctx, http.MethodDelete, s.documentPath(documentID), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return s.check("delete document", status, body)
+}
+
+// Search returns a page of a user's documents matching the query, the best matches first, ties
+// newest first
+func (s *OpenSearch) Search(ctx context.Context, userID, query string, limit, offset int) ([]service.SearchHit, int64, error) {
+	highlight := map[string]any{
+		"pre_tags":  []string{repository.SearchHighlightStart},
+		"post_tags": []string{repository.SearchHighlightStop},
+		"fields": map[string]any{
+			// The whole title
+			"title": map[string]any{"number_of_fragments": 0},
+			// The start of the description when nothing in it matches
+			"description": map[string]any{"number_of_fragments": snippetFragments, "fragment_size": snippetFragmentSize, "no_match_size": snippetFragmentSize},
+			"text":        map[string]any{"number_of_fragments": snippetFragments, "fragment_size": snippetFragmentSize},
+		},
+	}
+	request := map[string]any{
+		"from":             offset,
+		"size":             limit,
+		"track_total_hits": true,
+		"_source":          false,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []any{
+					map[string]any{"term": map[string]any{"user_id": userID}},
+				},
+				"must": []any{
+					map[string]any{"simple_query_string": map[string]any{
+						"query":            simpleQuery(query),
+						"fields":           searchFields,
+						"default_operator": "and",
+					}},
+				},
+			},
+		},
+		"sort":      []any{"_score", map[string]any{"created_at": "desc"}},
+		"highlight": highlight,
+	}
+
+	status, body, err := s.do(ctx, http.MethodPost, s.indexPath()+"/_search", request)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.check("search documents", status, body); err != nil {
+		return nil, 0, err
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, fmt.Errorf("%w: failed to decode search response: %v", domain.ErrSearchUnavailable, err)
+	}
+
+	hits := make([]service.SearchHit, len(response.Hits.Hits))
+	for i, hit := range response.Hits.Hits {
+		hits[i] = service.SearchHit{
+			DocumentID:     hit.ID,
+			Rank:           hit.Score,
+			TitleHighlight: strings.Join(hit.Highlight["title"], ""),
+			Snippet:        snippet(hit.Highlight["description"], hit.Highlight["text"]),
+		}
+	}
+	return hits, response.Hits.Total.Value, nil
+}
+
+// do sends a request with the JSON of body, when not nil, and returns the response status and
+// body. Only failing to reach the cluster is an error.
+func (s *OpenSearch) do(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal search request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.config.URL+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create search request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.config.Username != "" {
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to call the search engine: %v", domain.ErrSearchUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to read search engine response: %v", domain.ErrSearchUnavailable, err)
+	}
+	return resp.StatusCode, responseBody, nil
+}
+
+// check fails with domain.ErrSearchUnavailable on any status other than 2xx
+func (s *OpenSearch) check(operation string, status int, body []byte) error {
+	if status >= 200 && status < 300 {
+		return nil
+	}
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return fmt.Errorf("%w: failed to %s: status %d: %s", domain.ErrSearchUnavailable, operation, status, bytes.TrimSpace(body))
+}
+
+// indexPath returns the path of the index
+func (s *OpenSearch) indexPath() string {
+	return "/" + url.PathEscape(s.config.Index)
+}
+
+// documentPath returns the path of a document in the index
+func (s *OpenSearch) documentPath(documentID string) string {
+	return s.indexPath() + "/_doc/" + url.PathEscape(documentID)
+}
+
+// snippet returns up to two fragments of the description and text with matches, or the start of
+// the description when only the title matches
+func snippet(description, text []string) string {
+	fragments := []string{}
+	for _, fragment := range slices.Concat(description, text) {
+		if strings.Contains(fragment, repository.SearchHighlightStart) {
+			fragments = append(fragments, fragment)
+		}
+	}
+	if len(fragments) == 0 {
+		fragments = description
+	}
+	if len(fragments) > snippetFragments {
+		fragments = fragments[:snippetFragments]
+	}
+	return strings.Join(fragments, snippetDelimiter)
+}
+
+// simpleQueryOperators are the operators of the simple query string syntax that web search syntax
+// doesn't have, escaped so they match as text
+var simpleQueryOperators = strings.NewReplacer(
+	`\`, `\\`,
+	`+`, `\+`,
+	`|`, `\|`,
+	`*`, `\*`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`~`, `\~`,
+)
+
+// simpleQuery translates a query in web search syntax into the simple query string syntax. Quoted
+// phrases and -excluded words are the same in both, and or becomes |.
+func simpleQuery(query string) string {
+	words := strings.Fields(simpleQueryOperators.Replace(query))
+	quoted := false
+	for i, word := range words {
+		if !quoted && strings.EqualFold(word, "or") {
+			words[i] = "|"
+		}
+		if strings.Count(word, `"`)%2 == 1 {
+			quoted = !quoted
+		}
+	}
+	return strings.Join(words, " ")
+}
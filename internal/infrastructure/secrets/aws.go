@@ -0,0 +1,202 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Reference schemes of settings whose value is stored in AWS
+const (
+	secretsManagerScheme = "awssm://"
+	parameterStoreScheme = "ssm://"
+)
+
+// IsAWSReference reports whether a setting value refers to AWS Secrets Manager
+// ("awssm://<secret-id>[#<json-key>]") or SSM Parameter Store ("ssm://<parameter-name>")
+func IsAWSReference(value string) bool {
+	return strings.HasPrefix(value, secretsManagerScheme) || strings.HasPrefix(value, parameterStoreScheme)
+}
+
+// AWSConfig represents access to AWS Secrets Manager and SSM Parameter Store. Credentials come
+// from the default AWS chain (environment, shared config, instance or task role).
+type AWSConfig struct {
+	// Region overrides the region of the default AWS configuration
+	Region string
+	// CacheTTL is how long a fetched secret is reused before it is fetched again
+	CacheTTL time.Duration
+}
+
+// cachedSecret is a fetched secret string or parameter value
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// AWSResolver resolves settings that refer to AWS Secrets Manager secrets or SSM parameters.
+// Fetched values are cached, so several settings reading keys of one JSON secret fetch it once.
+type AWSResolver struct {
+	secretsManager *secretsmanager.Client
+	parameterStore *ssm.Client
+	cacheTTL       time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewAWSResolver creates a new AWS resolver
+func NewAWSResolver(ctx context.Context, config AWSConfig) (*AWSResolver, error) {
+	options := []func(*awsconfig.LoadOptions) error{}
+	if config.Region != "" {
+		options = append(options, awsconfig.WithRegion(config.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &AWSResolver{
+		secretsManager: secretsmanager.NewFromConfig(awsCfg),
+		parameterStore: ssm.NewFromConfig(awsCfg),
+		cacheTTL:       config.CacheTTL,
+		cache:          make(map[string]cachedSecret),
+	}, nil
+}
+
+// Resolve returns the value a reference points to. For a Secrets Manager reference with a
+// "#key" suffix, the secret is parsed as a JSON object and the value of key is returned.
+func (r *AWSResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, secretsManagerScheme):
+		secretID, key, hasKey := strings.Cut(strings.TrimPrefix(reference, secretsManagerScheme), "#")
+		value, err := r.fetch(ctx, secretsManagerScheme+secretID, func(ctx context.Context) (string, error) {
+			return r.getSecret(ctx, secretID)
+		})
+		if err != nil || !hasKey {
+			return value, err
+		}
+		return jsonField(value, key, reference)
+	case strings.HasPrefix(reference, parameterStoreScheme):
+		name := strings.TrimPrefix(reference, parameterStoreScheme)
+		return r.fetch(ctx, reference, func(ctx context.Context) (string, error) {
+			return r.getParameter(ctx, name)
+		})
+	default:
+		return "", fmt.Errorf("%q is not an AWS secret reference", reference)
+	}
+}
+
+// fetch returns the cached value of a secret or parameter, or loads and caches it
+func (r *AWSResolver) fetch(ctx context.Context, cacheKey string, load func(context.Context) (string, error)) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[cacheKey]
+	r.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < r.cacheTTL {
+		return cached.value, nil
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", cacheKey, err)
+	}
+
+	r.mu.Lock()
+	r.cache[cacheKey] = cachedSecret{value: value, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return value, nil
+}
+
+// getSecret reads the current version of a Secrets Manager secret
+func (r *AWSResolver) getSecret(ctx context.Context, secretID string) (string, error) {
+	output, err := r.secretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", err
+	}
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret has no string value")
+	}
+	return *output.SecretString, nil
+}
+
+// getParameter reads an SSM parameter, decrypting SecureString parameters
+func (r *AWSResolver) getParameter(ctx context.Context, name string) (string, error) {
+	output, err := r.parameterStore.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	if output.Parameter == nil || output.Parameter.Value == nil {
+		return "", fmt.Errorf("parameter has no value")
+	}
+	return *output.Parameter.Value, nil
+}
+
+// jsonField returns a field of a JSON object secret as a string
+func jsonField(secret, key, reference string) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: secret is not a JSON object", reference)
+	}
+
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("failed to resolve %s: secret has no key %q", reference, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// AWSReferences is a Provider resolving a fixed set of settings that refer to AWS secrets.
+// Fetching it again after a rotation returns the new values once the cache expires.
+type AWSReferences struct {
+	resolver   *AWSResolver
+	references map[string]string
+}
+
+// NewAWSReferences creates a provider for references, keyed by setting name
+func NewAWSReferences(resolver *AWSResolver, references map[string]string) *AWSReferences {
+	return &AWSReferences{
+		resolver:   resolver,
+		references: references,
+	}
+}
+
+// Name identifies the provider in logs
+func (p *AWSReferences) Name() string {
+	return "aws"
+}
+
+// Fetch resolves every reference
+func (p *AWSReferences) Fetch(ctx context.Context) (map[string]string, error) {
+	names := make([]string, 0, len(p.references))
+	for name := range p.references {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := p.resolver.Resolve(ctx, p.references[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Provider fetches secrets from an external store. Secrets are keyed by the name of the
+// configuration variable they set, such as JWT_SECRET or DB_PASSWORD.
+type Provider interface {
+	// Name identifies the provider in logs
+	Name() string
+	// Fetch returns the current secrets, renewing the provider's own credentials as needed
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Combine returns a provider fetching from every provider, later ones winning for secrets
+// several of them return. It returns nil without providers.
+func Combine(providers ...Provider) Provider {
+	switch len(providers) {
+	case 0:
+		return nil
+	case 1:
+		return providers[0]
+	default:
+		return combined(providers)
+	}
+}
+
+// combined fetches from several providers
+type combined []Provider
+
+// Name lists the names of the providers
+func (c combined) Name() string {
+	names := make([]string, len(c))
+	for i, provider := range c {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Fetch merges the secrets of every provider
+func (c combined) Fetch(ctx context.Context) (map[string]string, error) {
+	values := map[string]string{}
+	for _, provider := range c {
+		fetched, err := provider.Fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+		for name, value := range fetched {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// Watch fetches the secrets on every interval until ctx is cancelled, which also keeps the
+// provider's credentials renewed. onChange receives the names of the secrets whose value differs
+// from the previous fetch, and onError the failed fetches.
+func Watch(ctx context.Context, provider Provider, interval time.Duration, current map[string]string, onChange func(changed []string), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			values, err := provider.Fetch(ctx)
+			if err != nil {
+				onError(err)
+				continue
+			}
+
+			if changed := diff(current, values); len(changed) > 0 {
+				onChange(changed)
+			}
+			current = values
+		}
+	}
+}
+
+// diff returns the sorted names of the secrets added, removed or changed between two fetches
+func diff(previous, current map[string]string) []string {
+	changed := []string{}
+	for name, value := range current {
+		if old, ok := previous[name]; !ok || old != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
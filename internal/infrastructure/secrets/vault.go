@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultConfig represents the HashiCorp Vault connection. Authenticate with Token, or with
+// RoleID and SecretID through the AppRole auth method.
+type VaultConfig struct {
+	Addr      string
+	Token     string
+	RoleID    string
+	SecretID  string
+	AuthMount string
+	// Path is the secret to read, e.g. "secret/data/ginfinity" for a KV v2 engine mounted at
+	// "secret". Its keys are configuration variable names.
+	Path      string
+	Namespace string
+	Timeout   time.Duration
+}
+
+// Vault reads secrets from a KV (version 1 or 2) secret in HashiCorp Vault
+type Vault struct {
+	config     VaultConfig
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVault creates a new Vault provider
+func NewVault(config VaultConfig) (*Vault, error) {
+	if config.Addr == "" || config.Path == "" {
+		return nil, fmt.Errorf("vault address and secret path are required")
+	}
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, fmt.Errorf("vault token or AppRole role ID and secret ID are required")
+	}
+	if config.AuthMount == "" {
+		config.AuthMount = "approle"
+	}
+
+	return &Vault{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		token:      config.Token,
+	}, nil
+}
+
+// Name identifies the provider in logs
+func (v *Vault) Name() string {
+	return "vault"
+}
+
+// Fetch renews the Vault token, logging in again with AppRole when that fails, and reads the
+// secret
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	token, err := v.ensureToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, strings.TrimPrefix(v.config.Path, "/"), token, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", v.config.Path, err)
+	}
+
+	// KV v2 nests the values under data.data, next to data.metadata
+	data := response.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// ensureToken returns a valid token, renewing the current one or logging in with AppRole
+func (v *Vault) ensureToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.token != "" {
+		err := v.do(ctx, http.MethodPost, "auth/token/renew-self", v.token, struct{}{}, nil)
+		if err == nil || v.config.RoleID == "" {
+			// A static token may not be renewable; it is still used until it is rejected
+			return v.token, nil
+		}
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role_id": v.config.RoleID, "secret_id": v.config.SecretID}
+	if err := v.do(ctx, http.MethodPost, "auth/"+v.config.AuthMount+"/login", "", body, &response); err != nil {
+		return "", fmt.Errorf("failed to log in to vault: %w", err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed to log in to vault: no token returned")
+	}
+
+	v.token = response.Auth.ClientToken
+	return v.token, nil
+}
+
+// do sends a request to the Vault HTTP API and decodes the JSON response into out
+func (v *Vault) do(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(v.config.Addr, "/")+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&vaultErr); err == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
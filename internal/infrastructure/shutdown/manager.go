@@ -0,0 +1,102 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook releases the resources of a subsystem. It should return once ctx is done even if the
+// cleanup is not finished.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager runs the cleanup hooks registered by subsystems when the process stops. Hooks run
+// one at a time in reverse registration order, like defers, so a subsystem is torn down before
+// the dependencies it was built on.
+type Manager struct {
+	logger  *logrus.Logger
+	timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []namedHook
+	done  bool
+}
+
+// NewManager creates a shutdown manager whose hooks share a deadline of timeout
+func NewManager(logger *logrus.Logger, timeout time.Duration) *Manager {
+	return &Manager{
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Register adds a cleanup hook
+func (m *Manager) Register(name string, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// RegisterFunc adds a cleanup function that can't fail and doesn't need the deadline
+func (m *Manager) RegisterFunc(name string, fn func()) {
+	m.Register(name, func(context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// Shutdown runs every hook once, logging failures instead of stopping at the first one. Once the
+// deadline passes, a hook still running is abandoned and the remaining hooks are skipped.
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return
+	}
+	m.done = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		nh := hooks[i]
+		start := time.Now()
+		entry := m.logger.WithField("hook", nh.name)
+
+		if ctx.Err() != nil {
+			entry.Warn("Shutdown deadline exceeded, skipping hook")
+			continue
+		}
+
+		if err := m.run(ctx, nh.hook); err != nil {
+			entry.WithError(err).Error("Shutdown hook failed")
+			continue
+		}
+		entry.WithField("duration", time.Since(start).String()).Debug("Shutdown hook completed")
+	}
+}
+
+// run calls hook and returns when it finishes or ctx is done, whichever comes first
+func (m *Manager) run(ctx context.Context, hook Hook) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
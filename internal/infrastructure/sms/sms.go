@@ -0,0 +1,98 @@
+// Package sms sends text messages through Twilio or Vonage
+package sms
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// Providers send text messages
+const (
+	// ProviderLog logs messages instead of sending them, for development
+	ProviderLog = "log"
+	// ProviderTwilio sends messages with the Twilio Messaging API
+	ProviderTwilio = "twilio"
+	// ProviderVonage sends messages with the Vonage SMS API
+	ProviderVonage = "vonage"
+)
+
+// maxErrorBody bounds how much of an API error response is kept in the error
+const maxErrorBody = 1024
+
+// Config configures the provider sending text messages and the sender
+type Config struct {
+	Provider string
+	// From is the sender: a phone number in E.164 format, or an alphanumeric sender ID where
+	// the provider and country allow one
+	From string
+	// Timeout bounds the sending of each message
+	Timeout time.Duration
+	Twilio  TwilioConfig
+	Vonage  VonageConfig
+}
+
+// New creates the SMS service of the configured provider
+func New(cfg Config) (service.SMSService, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Provider {
+	case ProviderLog:
+		return &logService{}, nil
+	case ProviderTwilio:
+		return &twilioService{config: cfg.Twilio, from: cfg.From, client: client}, nil
+	case ProviderVonage:
+		return &vonageService{config: cfg.Vonage, from: cfg.From, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider %q", cfg.Provider)
+	}
+}
+
+// logService logs messages instead of sending them
+type logService struct{}
+
+// Send logs the message
+func (s *logService) Send(ctx context.Context, to, text string) error {
+	logging.FromContext(ctx).WithField("to", to).Info("Text message not sent (log provider): " + text)
+	return nil
+}
+
+// postForm posts a form to a provider API and returns the response body, failing with
+// domain.ErrSMSUnavailable on any status other than 2xx
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, setAuth func(*http.Request), api string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", api, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if setAuth != nil {
+		setAuth(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to call %s: %v", domain.ErrSMSUnavailable, api, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s response: %v", domain.ErrSMSUnavailable, api, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		return nil, fmt.Errorf("%w: %s rejected the message with status %d: %s", domain.ErrSMSUnavailable, api, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,39 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TwilioConfig configures Twilio
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+}
+
+// twilioEndpoint is the Twilio Messaging API of an account
+const twilioEndpoint = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// twilioService sends messages with the Twilio Messaging API
+type twilioService struct {
+	config TwilioConfig
+	from   string
+	client *http.Client
+}
+
+// Send sends the message with Twilio
+func (s *twilioService) Send(ctx context.Context, to, text string) error {
+	form := url.Values{
+		"To":   {to},
+		"From": {s.from},
+		"Body": {text},
+	}
+	endpoint := fmt.Sprintf(twilioEndpoint, url.PathEscape(s.config.AccountSID))
+
+	_, err := postForm(ctx, s.client, endpoint, form, func(req *http.Request) {
+		req.SetBasicAuth(s.config.AccountSID, s.config.AuthToken)
+	}, "Twilio")
+	return err
+}
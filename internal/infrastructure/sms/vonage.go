@@ -0,0 +1,69 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// VonageConfig configures Vonage
+type VonageConfig struct {
+	APIKey    string
+	APISecret string
+}
+
+// vonageEndpoint is the Vonage SMS API
+const vonageEndpoint = "https://rest.nexmo.com/sms/json"
+
+// vonageService sends messages with the Vonage SMS API
+type vonageService struct {
+	config VonageConfig
+	from   string
+	client *http.Client
+}
+
+// vonageResponse is the part of a Vonage SMS API response with the status of each message part
+type vonageResponse struct {
+	Messages []struct {
+		Status    string `json:"status"`
+		ErrorText string `json:"error-text"`
+	} `json:"messages"`
+}
+
+// Send sends the message with Vonage. Vonage answers 200 even when it rejects a message, with
+// the reason in the status of each part.
+func (s *vonageService) Send(ctx context.Context, to, text string) error {
+	form := url.Values{
+		"api_key":    {s.config.APIKey},
+		"api_secret": {s.config.APISecret},
+		// Vonage takes numbers without the leading +
+		"to":   {strings.TrimPrefix(to, "+")},
+		"from": {strings.TrimPrefix(s.from, "+")},
+		"text": {text},
+		"type": {"unicode"},
+	}
+
+	body, err := postForm(ctx, s.client, vonageEndpoint, form, nil, "Vonage")
+	if err != nil {
+		return err
+	}
+
+	var result vonageResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("%w: failed to decode Vonage response: %v", domain.ErrSMSUnavailable, err)
+	}
+	if len(result.Messages) == 0 {
+		return fmt.Errorf("%w: Vonage response has no messages", domain.ErrSMSUnavailable)
+	}
+	for _, message := range result.Messages {
+		if message.Status != "0" {
+			return fmt.Errorf("%w: Vonage rejected the message with status %s: %s", domain.ErrSMSUnavailable, message.Status, message.ErrorText)
+		}
+	}
+	return nil
+}
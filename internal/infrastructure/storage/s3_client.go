@@ -2,13 +2,17 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -16,6 +20,16 @@ import (
 	"github.com/google/uuid"
 )
 
+// uploadsPrefix is the key prefix of every uploaded file
+const uploadsPrefix = "uploads/"
+
+// exportsPrefix is the key prefix of the data exports of users. They are private, and left out of
+// the uploaded files.
+const exportsPrefix = "exports/"
+
+// ErrFileNotFound is returned when a file doesn't exist in the bucket
+var ErrFileNotFound = errors.New("file not found")
+
 type S3Config struct {
 	Endpoint        string
 	AccessKeyID     string
@@ -23,6 +37,9 @@ type S3Config struct {
 	Region          string
 	Bucket          string
 	UseSSL          bool
+	DialTimeout     time.Duration
+	// Timeout bounds each request, 0 leaves it to the caller's context
+	Timeout time.Duration
 }
 
 type S3Client struct {
@@ -31,8 +48,15 @@ type S3Client struct {
 }
 
 func NewS3Client(cfg S3Config) (*S3Client, error) {
+	httpClient := awshttp.NewBuildableClient().
+		WithTimeout(cfg.Timeout).
+		WithDialerOptions(func(dialer *net.Dialer) {
+			dialer.Timeout = cfg.DialTimeout
+		})
+
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
 		awsconfig.WithRegion(cfg.Region),
+		awsconfig.WithHTTPClient(httpClient),
 		awsconfig.WithCredentialsProvider(
 			credentials.NewStaticCredentialsProvider(
 				cfg.AccessKeyID,
@@ -79,6 +103,27 @@ func (s *S3Client) UploadFile(ctx context.Context, file io.Reader, filename stri
 	return &fileURL, nil
 }
 
+// UploadExport uploads a data export of the user, which can only be downloaded with a presigned
+// URL. It is downloaded as an attachment named filename.
+func (s *S3Client) UploadExport(ctx context.Context, userID string, file io.Reader, filename string, contentType string) (*string, error) {
+	key := fmt.Sprintf("%s%s/%s-%s", exportsPrefix, userID, uuid.New().String(), filename)
+
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:             aws.String(s.config.Bucket),
+		Key:                aws.String(key),
+		Body:               file,
+		ContentType:        aws.String(contentType),
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", filename)),
+		ACL:                types.ObjectCannedACLPrivate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	fileURL := s.getPublicURL(key)
+	return &fileURL, nil
+}
+
 func (s *S3Client) DeleteFile(ctx context.Context, fileURL string) error {
 	key, err := s.extractKeyFromURL(fileURL)
 	if err != nil {
@@ -96,6 +141,30 @@ func (s *S3Client) DeleteFile(ctx context.Context, fileURL string) error {
 	return nil
 }
 
+// OpenFile returns the content of a file, read from the bucket as it is consumed. It returns
+// ErrFileNotFound when the file doesn't exist.
+func (s *S3Client) OpenFile(ctx context.Context, fileURL string) (io.ReadCloser, error) {
+	key, err := s.extractKeyFromURL(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file URL: %w", err)
+	}
+
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		var responseErr *awshttp.ResponseError
+		if errors.As(err, &noSuchKey) || (errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound) {
+			return nil, fmt.Errorf("failed to open file: %w", ErrFileNotFound)
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return output.Body, nil
+}
+
 func (s *S3Client) GetPresignedURL(ctx context.Context, fileURL string, expiresIn time.Duration) (*string, error) {
 	key, err := s.extractKeyFromURL(fileURL)
 	if err != nil {
@@ -116,10 +185,67 @@ func (s *S3Client) GetPresignedURL(ctx context.Context, fileURL string, expiresI
 	return &request.URL, nil
 }
 
+// ListFiles calls fn with the URLs of the uploaded files last modified before the time, a page
+// at a time, until fn returns an error
+func (s *S3Client) ListFiles(ctx context.Context, modifiedBefore time.Time, fn func(fileURLs []string) error) error {
+	return s.listFiles(ctx, uploadsPrefix, modifiedBefore, fn)
+}
+
+// ListExports calls fn with the URLs of the data exports uploaded before the time, a page at a
+// time, until fn returns an error
+func (s *S3Client) ListExports(ctx context.Context, modifiedBefore time.Time, fn func(fileURLs []string) error) error {
+	return s.listFiles(ctx, exportsPrefix, modifiedBefore, fn)
+}
+
+// ListUserExports calls fn with the URLs of the data exports of the user, a page at a time,
+// until fn returns an error
+func (s *S3Client) ListUserExports(ctx context.Context, userID string, fn func(fileURLs []string) error) error {
+	return s.listFiles(ctx, exportsPrefix+userID+"/", time.Now().Add(time.Minute), fn)
+}
+
+// listFiles calls fn with the URLs of the files under the prefix last modified before the time
+func (s *S3Client) listFiles(ctx context.Context, prefix string, modifiedBefore time.Time, fn func(fileURLs []string) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list files: %w", err)
+		}
+
+		fileURLs := make([]string, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			if object.LastModified != nil && object.LastModified.Before(modifiedBefore) {
+				fileURLs = append(fileURLs, s.getPublicURL(aws.ToString(object.Key)))
+			}
+		}
+		if len(fileURLs) == 0 {
+			continue
+		}
+		if err := fn(fileURLs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping checks that the bucket is reachable with the configured credentials
+func (s *S3Client) Ping(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.config.Bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reach bucket: %w", err)
+	}
+	return nil
+}
+
 func (s *S3Client) generateKey(filename string) string {
 	uniqueID := uuid.New().String()
 	timestamp := time.Now().Format("2006-01-02")
-	return fmt.Sprintf("uploads/%s/%s-%s", timestamp, uniqueID, filename)
+	return fmt.Sprintf("%s%s/%s-%s", uploadsPrefix, timestamp, uniqueID, filename)
 }
 
 func (s *S3Client) getPublicURL(key string) string {
@@ -151,4 +277,4 @@ func (s *S3Client) extractKeyFromURL(fileURL string) (string, error) {
 	}
 
 	return parts[1], nil
-}
\ No newline at end of file
+}
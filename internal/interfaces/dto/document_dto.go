@@ -22,6 +22,7 @@ type DocumentResponse struct {
 	FileName    string `json:"file_name" example:"document.pdf"`
 	FileSize    int64  `json:"file_size" example:"1024000"`
 	ContentType string `json:"content_type" example:"application/pdf"`
+	ScanStatus  string `json:"scan_status" example:"clean"`
 	UserID      string `json:"user_id" example:"user123"`
 	CreatedAt   string `json:"created_at" example:"2023-01-01T00:00:00Z"`
 	UpdatedAt   string `json:"updated_at" example:"2023-01-01T00:00:00Z"`
@@ -31,4 +32,8 @@ type DocumentResponse struct {
 type PresignedURLResponse struct {
 	URL     string `json:"url" example:"https://s3.amazonaws.com/bucket/file.pdf?signature=..."`
 	Expires string `json:"expires" example:"2023-01-01T01:00:00Z"`
-}
\ No newline at end of file
+}
+// DownloadDocumentsZipRequest represents a request to download documents as a ZIP archive
+type DownloadDocumentsZipRequest struct {
+	DocumentIDs []string `json:"document_ids" binding:"required,min=1,max=100,dive,required" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	pb "gin-boilerplate/internal/interfaces/grpc/pb/ginfinity/v1"
+)
+
+// AuthService implements the gRPC AuthService on top of the auth use cases
+type AuthService struct {
+	pb.UnimplementedAuthServiceServer
+	registerUseCase *usecase.RegisterUseCase
+	loginUseCase    *usecase.LoginUseCase
+	refreshUseCase  *usecase.RefreshTokenUseCase
+	logoutUseCase   *usecase.LogoutUseCase
+}
+
+// NewAuthService creates a new gRPC auth service
+func NewAuthService(
+	registerUseCase *usecase.RegisterUseCase,
+	loginUseCase *usecase.LoginUseCase,
+	refreshUseCase *usecase.RefreshTokenUseCase,
+	logoutUseCase *usecase.LogoutUseCase,
+) *AuthService {
+	return &AuthService{
+		registerUseCase: registerUseCase,
+		loginUseCase:    loginUseCase,
+		refreshUseCase:  refreshUseCase,
+		logoutUseCase:   logoutUseCase,
+	}
+}
+
+// Register creates a local account
+func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthResponse, error) {
+	registerReq := dto.RegisterRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Name:     req.GetName(),
+	}
+	if err := validate(&registerReq); err != nil {
+		return nil, err
+	}
+
+	response, err := s.registerUseCase.Execute(ctx, registerReq)
+	if err != nil {
+		return nil, err
+	}
+	return toPBAuthResponse(response), nil
+}
+
+// Login signs in with email and password
+func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthResponse, error) {
+	loginReq := dto.LoginRequest{
+		Email:     req.GetEmail(),
+		Password:  req.GetPassword(),
+		ClientIP:  clientIP(ctx),
+		UserAgent: userAgent(ctx),
+	}
+	if err := validate(&loginReq); err != nil {
+		return nil, err
+	}
+
+	response, challenge, err := s.loginUseCase.Execute(ctx, loginReq)
+	if err != nil {
+		return nil, err
+	}
+	// The second step of logins with two-factor authentication is HTTP-only
+	if challenge != nil {
+		return nil, domain.ErrTwoFactorRequired
+	}
+	return toPBAuthResponse(response), nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair
+func (s *AuthService) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.AuthResponse, error) {
+	refreshReq := dto.RefreshTokenRequest{
+		RefreshToken: req.GetRefreshToken(),
+		ClientIP:     clientIP(ctx),
+		UserAgent:    userAgent(ctx),
+	}
+	if err := validate(&refreshReq); err != nil {
+		return nil, err
+	}
+
+	response, err := s.refreshUseCase.Execute(ctx, refreshReq)
+	if err != nil {
+		return nil, err
+	}
+	return toPBAuthResponse(response), nil
+}
+
+// Logout revokes a refresh token
+func (s *AuthService) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	logoutReq := dto.RefreshTokenRequest{
+		RefreshToken: req.GetRefreshToken(),
+	}
+	if err := validate(&logoutReq); err != nil {
+		return nil, err
+	}
+
+	// The access token is denied too; API key callers have none
+	p, _ := principalFromContext(ctx)
+	if err := s.logoutUseCase.Execute(ctx, logoutReq.RefreshToken, p.Claims); err != nil {
+		return nil, err
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+// validate applies the request DTO's binding rules, the same ones the HTTP API enforces
+func validate(req interface{}) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return domain.NewValidationError(err)
+	}
+	return nil
+}
+
+func toPBAuthResponse(response *dto.AuthResponse) *pb.AuthResponse {
+	return &pb.AuthResponse{
+		User:         toPBUser(&response.User),
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		TokenType:    response.TokenType,
+		ExpiresIn:    response.ExpiresIn,
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/interfaces/dto"
+	pb "gin-boilerplate/internal/interfaces/grpc/pb/ginfinity/v1"
+)
+
+// DocumentService implements the gRPC DocumentService on top of the document use case
+type DocumentService struct {
+	pb.UnimplementedDocumentServiceServer
+	documentUseCase *usecase.DocumentUseCase
+	policy          *service.PolicyService
+}
+
+// NewDocumentService creates a new gRPC document service
+func NewDocumentService(documentUseCase *usecase.DocumentUseCase, policy *service.PolicyService) *DocumentService {
+	return &DocumentService{
+		documentUseCase: documentUseCase,
+		policy:          policy,
+	}
+}
+
+// authorize returns the caller when its role has the permission, like the routes of the HTTP
+// API
+func (s *DocumentService) authorize(ctx context.Context, permission entity.Permission) (principal, error) {
+	p, ok := principalFromContext(ctx)
+	if !ok {
+		return principal{}, domain.ErrUnauthorized
+	}
+
+	allowed, err := s.policy.Can(ctx, entity.Role(p.Role), permission)
+	if err != nil {
+		return principal{}, err
+	}
+	if !allowed {
+		return principal{}, domain.ErrForbidden
+	}
+	return p, nil
+}
+
+// ListDocuments returns a page of the caller's documents
+func (s *DocumentService) ListDocuments(ctx context.Context, req *pb.ListDocumentsRequest) (*pb.ListDocumentsResponse, error) {
+	p, err := s.authorize(ctx, entity.PermissionDocumentsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same defaults and bounds as the HTTP API
+	page := req.GetPage()
+	if page < 1 {
+		page = 1
+	}
+	limit := req.GetLimit()
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	documents, total, err := s.documentUseCase.GetUserDocuments(ctx, p.UserID, int(limit), int((page-1)*limit))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ListDocumentsResponse{
+		Documents: make([]*pb.Document, len(documents)),
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+	}
+	for i, document := range documents {
+		response.Documents[i] = toPBDocument(document)
+	}
+	return response, nil
+}
+
+// GetDocument returns one of the caller's documents
+func (s *DocumentService) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*pb.Document, error) {
+	p, err := s.authorize(ctx, entity.PermissionDocumentsRead)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, domain.ErrValidation.WithMessage("Document ID is required")
+	}
+
+	document, err := s.documentUseCase.GetDocument(ctx, req.GetId(), p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return toPBDocument(document), nil
+}
+
+// UpdateDocument updates the title and description of one of the caller's documents
+func (s *DocumentService) UpdateDocument(ctx context.Context, req *pb.UpdateDocumentRequest) (*pb.Document, error) {
+	p, err := s.authorize(ctx, entity.PermissionDocumentsWrite)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, domain.ErrValidation.WithMessage("Document ID is required")
+	}
+
+	updateReq := dto.UpdateDocumentRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+	}
+	if err := validate(&updateReq); err != nil {
+		return nil, err
+	}
+
+	document, err := s.documentUseCase.UpdateDocument(ctx, req.GetId(), p.UserID, updateReq.Title, updateReq.Description)
+	if err != nil {
+		return nil, err
+	}
+	return toPBDocument(document), nil
+}
+
+// DeleteDocument deletes one of the caller's documents and its file
+func (s *DocumentService) DeleteDocument(ctx context.Context, req *pb.DeleteDocumentRequest) (*pb.DeleteDocumentResponse, error) {
+	p, err := s.authorize(ctx, entity.PermissionDocumentsDelete)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, domain.ErrValidation.WithMessage("Document ID is required")
+	}
+
+	if err := s.documentUseCase.DeleteDocument(ctx, req.GetId(), p.UserID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteDocumentResponse{}, nil
+}
+
+// GetDownloadURL returns a presigned download URL for one of the caller's documents
+func (s *DocumentService) GetDownloadURL(ctx context.Context, req *pb.GetDownloadURLRequest) (*pb.GetDownloadURLResponse, error) {
+	p, err := s.authorize(ctx, entity.PermissionDocumentsRead)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, domain.ErrValidation.WithMessage("Document ID is required")
+	}
+
+	url, err := s.documentUseCase.GetPresignedURL(ctx, req.GetId(), p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetDownloadURLResponse{Url: *url}, nil
+}
+
+func toPBDocument(document *usecase.DocumentResponse) *pb.Document {
+	return &pb.Document{
+		Id:          document.ID,
+		Title:       document.Title,
+		Description: document.Description,
+		FileUrl:     document.FileURL,
+		FileName:    document.FileName,
+		FileSize:    document.FileSize,
+		ContentType: document.ContentType,
+		UserId:      document.UserID,
+		CreatedAt:   document.CreatedAt,
+		UpdatedAt:   document.UpdatedAt,
+	}
+}
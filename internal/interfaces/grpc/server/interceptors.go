@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/errorreporting"
+	"gin-boilerplate/internal/infrastructure/logging"
+)
+
+// errorDomain identifies this API in the ErrorInfo details of gRPC errors
+const errorDomain = "ginfinity"
+
+// apiKeyMetadata is the metadata key machine clients send their API key in
+const apiKeyMetadata = "x-api-key"
+
+// requestIDMetadata carries the request ID, like the X-Request-ID header of the HTTP API
+const requestIDMetadata = "x-request-id"
+
+// forwardedForMetadata and gatewayUserAgentMetadata carry the address and the user agent of the
+// HTTP client of a call through the gateway
+const (
+	forwardedForMetadata     = "x-forwarded-for"
+	gatewayUserAgentMetadata = "grpcgateway-user-agent"
+)
+
+// principal is the authenticated caller of an RPC
+type principal struct {
+	UserID string
+	Email  string
+	Role   string
+	Locale string
+	// Claims are those of the caller's access token; nil for API keys
+	Claims *service.TokenClaims
+}
+
+type principalKey struct{}
+
+// principalFromContext returns the caller stored by the auth interceptor
+func principalFromContext(ctx context.Context) (principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(principal)
+	return p, ok && p.UserID != ""
+}
+
+// AuthInterceptor authenticates RPCs with the same bearer tokens and API keys as the HTTP API
+type AuthInterceptor struct {
+	tokenService       service.TokenService
+	authenticateAPIKey *usecase.AuthenticateAPIKeyUseCase
+	// denylist rejects revoked access tokens; nil accepts every valid token
+	denylist      *service.TokenDenylistService
+	publicMethods map[string]bool
+}
+
+// NewAuthInterceptor creates a new auth interceptor. Methods listed in publicMethods, by full
+// method name, are served without authentication.
+func NewAuthInterceptor(tokenService service.TokenService, authenticateAPIKey *usecase.AuthenticateAPIKeyUseCase, denylist *service.TokenDenylistService, publicMethods ...string) *AuthInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return &AuthInterceptor{
+		tokenService:       tokenService,
+		authenticateAPIKey: authenticateAPIKey,
+		denylist:           denylist,
+		publicMethods:      public,
+	}
+}
+
+// Unary creates the unary server interceptor. Callers send "authorization: Bearer <token>" or
+// "x-api-key: <key>" metadata.
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if i.publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		// Authentication errors are all client errors, so they are converted here rather than
+		// by the error interceptor, which runs inside this one to see the caller
+		p, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, statusForError(CodeForError(err), err).Err()
+		}
+
+		ctx = logging.WithFields(ctx, logrus.Fields{"user_id": p.UserID})
+		ctx = service.WithAuditActor(ctx, service.AuditActor{UserID: p.UserID, IP: clientIP(ctx), UserAgent: userAgent(ctx)})
+		return handler(context.WithValue(ctx, principalKey{}, p), req)
+	}
+}
+
+// authenticate resolves the caller from the request metadata
+func (i *AuthInterceptor) authenticate(ctx context.Context) (principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if keys := md.Get(apiKeyMetadata); len(keys) > 0 && keys[0] != "" {
+		if i.authenticateAPIKey == nil {
+			return principal{}, domain.ErrInvalidAPIKey
+		}
+		_, user, err := i.authenticateAPIKey.Execute(ctx, keys[0])
+		if err != nil {
+			return principal{}, domain.ErrInvalidAPIKey
+		}
+		return principal{UserID: user.ID, Email: user.Email, Role: string(user.Role), Locale: user.Locale}, nil
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return principal{}, domain.ErrMissingToken
+	}
+
+	tokenParts := strings.Split(values[0], " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		return principal{}, domain.ErrInvalidTokenFormat
+	}
+
+	claims, err := i.tokenService.ValidateAccessToken(tokenParts[1])
+	if err != nil {
+		return principal{}, domain.ErrInvalidToken
+	}
+
+	// Like the HTTP API, the token is accepted when the denylist can't be read
+	if i.denylist != nil {
+		denied, err := i.denylist.IsDenied(ctx, claims)
+		if err != nil {
+			logging.ModuleFromContext(ctx, logging.ModuleAuth).WithError(err).Warn("Failed to check the token denylist")
+		} else if denied {
+			return principal{}, domain.ErrTokenRevoked
+		}
+	}
+
+	return principal{UserID: claims.UserID, Email: claims.Email, Role: claims.Role, Locale: claims.Locale, Claims: claims}, nil
+}
+
+// RequestLoggerInterceptor stores a logger carrying the request ID and method in the context of
+// each RPC. The request ID is taken from the x-request-id metadata or generated, and sent back
+// in the response header. A traceparent metadata adds its trace ID.
+func RequestLoggerInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		requestID := uuid.NewString()
+		if values := md.Get(requestIDMetadata); len(values) > 0 && values[0] != "" {
+			requestID = values[0]
+		}
+
+		// Only fails once headers are sent, which can't have happened yet
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+
+		entry := logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"method":     info.FullMethod,
+		})
+		if values := md.Get("traceparent"); len(values) > 0 {
+			if traceID := logging.TraceID(values[0]); traceID != "" {
+				entry = entry.WithField("trace_id", traceID)
+			}
+		}
+		return handler(logging.NewContext(ctx, entry), req)
+	}
+}
+
+// ErrorInterceptor turns domain errors into gRPC status errors, and logs and reports server
+// errors like the HTTP error middleware does
+func ErrorInterceptor(reporter *errorreporting.Reporter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		// Already a status, for example from the gRPC runtime
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		code := CodeForError(err)
+		if code == codes.Internal || code == codes.Unknown {
+			p, _ := principalFromContext(ctx)
+			logging.FromContext(ctx).WithError(err).Error("RPC failed")
+			reporter.CaptureError(err, errorreporting.RequestContext{
+				Route:     info.FullMethod,
+				UserID:    p.UserID,
+				UserEmail: p.Email,
+				ClientIP:  clientIP(ctx),
+			})
+		}
+
+		return nil, statusForError(code, err).Err()
+	}
+}
+
+// CodeForError maps an error to its gRPC status code
+func CodeForError(err error) codes.Code {
+	switch domain.KindOf(err) {
+	case domain.KindInvalid:
+		return codes.InvalidArgument
+	case domain.KindUnauthorized:
+		return codes.Unauthenticated
+	case domain.KindForbidden:
+		return codes.PermissionDenied
+	case domain.KindNotFound:
+		return codes.NotFound
+	case domain.KindConflict:
+		return codes.AlreadyExists
+	case domain.KindTooLarge, domain.KindTooManyRequests:
+		return codes.ResourceExhausted
+	case domain.KindUnavailable:
+		return codes.Unavailable
+	case domain.KindTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// statusForError builds a status carrying the client-safe message and, as ErrorInfo details,
+// the same error code the HTTP API returns
+func statusForError(code codes.Code, err error) *status.Status {
+	domainErr := domain.ErrInternal
+	if !errors.As(err, &domainErr) || code == codes.Internal {
+		domainErr = domain.ErrInternal
+	}
+
+	st := status.New(code, domainErr.Message)
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: domainErr.Code,
+		Domain: errorDomain,
+	})
+	if detailErr != nil {
+		return st
+	}
+	return detailed
+}
+
+// clientIP returns the address of the caller. Calls through the gateway come from loopback, so
+// for those it is the first address of the x-forwarded-for metadata, which the gateway sets to the
+// address of its HTTP client; other callers can't claim an address that way.
+func clientIP(ctx context.Context) string {
+	host := peerIP(ctx)
+	if !isLoopback(host) {
+		return host
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(forwardedForMetadata); len(values) > 0 {
+		if forwarded, _, _ := strings.Cut(values[0], ","); strings.TrimSpace(forwarded) != "" {
+			return strings.TrimSpace(forwarded)
+		}
+	}
+	return host
+}
+
+// peerIP returns the address of the caller's connection
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// isLoopback reports whether the address is a loopback address, as the gateway dials from
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// userAgent returns the user agent the client sent in the metadata of the RPC, or the one the
+// HTTP client of the gateway sent for calls through the gateway
+func userAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if isLoopback(peerIP(ctx)) {
+		if values := md.Get(gatewayUserAgentMetadata); len(values) > 0 {
+			return values[0]
+		}
+	}
+	if values := md.Get("user-agent"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
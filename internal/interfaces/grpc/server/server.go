@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"gin-boilerplate/internal/infrastructure/errorreporting"
+	pb "gin-boilerplate/internal/interfaces/grpc/pb/ginfinity/v1"
+)
+
+// PublicMethods are the RPCs served without authentication
+var PublicMethods = []string{
+	pb.AuthService_Register_FullMethodName,
+	pb.AuthService_Login_FullMethodName,
+	pb.AuthService_RefreshToken_FullMethodName,
+}
+
+// Server serves the gRPC API next to the HTTP one, sharing its use cases
+type Server struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+}
+
+// NewServer creates a gRPC server with the auth, user and document services registered
+func NewServer(
+	authService *AuthService,
+	userService *UserService,
+	documentService *DocumentService,
+	authInterceptor *AuthInterceptor,
+	logger *logrus.Logger,
+	reporter *errorreporting.Reporter,
+) *Server {
+	grpcServer := grpc.NewServer(
+		// The request logger runs first so every later log line carries the request ID, and the
+		// error interceptor runs last so it sees the authenticated caller
+		grpc.ChainUnaryInterceptor(
+			RequestLoggerInterceptor(logger),
+			authInterceptor.Unary(),
+			ErrorInterceptor(reporter),
+		),
+	)
+
+	pb.RegisterAuthServiceServer(grpcServer, authService)
+	pb.RegisterUserServiceServer(grpcServer, userService)
+	pb.RegisterDocumentServiceServer(grpcServer, documentService)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	return &Server{
+		grpcServer: grpcServer,
+		health:     healthServer,
+	}
+}
+
+// Serve accepts connections on listener until Shutdown
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpcServer.Serve(listener)
+}
+
+// Shutdown stops accepting RPCs and waits for running ones, or stops at once when ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// gatewayExcludedMethods aren't served by the gateway. The HTTP API's login and registration
+// verify CAPTCHAs and apply their own rate limits, which the gateway, on the same port, would
+// otherwise bypass.
+var gatewayExcludedMethods = []string{
+	pb.AuthService_Register_FullMethodName,
+	pb.AuthService_Login_FullMethodName,
+}
+
+// NewGateway returns an HTTP handler translating JSON requests into calls to the gRPC server at
+// endpoint. Every RPC but Register and Login is available as POST /<package>.<Service>/<Method>,
+// for example POST /ginfinity.v1.DocumentService/ListDocuments, with the request message as the
+// JSON body. Calls go through the gRPC server, so its interceptors apply. The X-Forwarded-For
+// header of requests must be the address of the client alone, which the gRPC server then uses
+// as the client's address.
+func NewGateway(ctx context.Context, endpoint string) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+	)
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+
+	registrations := []func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error{
+		pb.RegisterAuthServiceHandlerFromEndpoint,
+		pb.RegisterUserServiceHandlerFromEndpoint,
+		pb.RegisterDocumentServiceHandlerFromEndpoint,
+	}
+	for _, register := range registrations {
+		if err := register(ctx, mux, endpoint, opts); err != nil {
+			return nil, fmt.Errorf("failed to register gRPC gateway handler: %w", err)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(gatewayExcludedMethods, r.URL.Path) {
+			err := status.Error(codes.Unimplemented, "Register and log in with the HTTP API's /auth endpoints")
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+			runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+// gatewayHeaderMatcher forwards the API key header as metadata, besides the headers the
+// gateway forwards by default (such as Authorization)
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(apiKeyMetadata) {
+		return apiKeyMetadata, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	pb "gin-boilerplate/internal/interfaces/grpc/pb/ginfinity/v1"
+)
+
+// UserService implements the gRPC UserService on top of the profile use cases
+type UserService struct {
+	pb.UnimplementedUserServiceServer
+	getUserProfileUseCase    *usecase.GetUserProfileUseCase
+	updateUserProfileUseCase *usecase.UpdateUserProfileUseCase
+}
+
+// NewUserService creates a new gRPC user service
+func NewUserService(getUserProfileUseCase *usecase.GetUserProfileUseCase, updateUserProfileUseCase *usecase.UpdateUserProfileUseCase) *UserService {
+	return &UserService{
+		getUserProfileUseCase:    getUserProfileUseCase,
+		updateUserProfileUseCase: updateUserProfileUseCase,
+	}
+}
+
+// GetMe returns the caller's profile
+func (s *UserService) GetMe(ctx context.Context, req *pb.GetMeRequest) (*pb.User, error) {
+	p, ok := principalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+
+	user, err := s.getUserProfileUseCase.Execute(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return toPBUser(user), nil
+}
+
+// UpdateMe updates the fields of the caller's profile that are set, keeping the others
+func (s *UserService) UpdateMe(ctx context.Context, req *pb.UpdateMeRequest) (*pb.User, error) {
+	p, ok := principalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+
+	updateReq := dto.PatchProfileRequest{
+		Name:   req.Name,
+		Avatar: req.Avatar,
+		Locale: req.Locale,
+	}
+	if err := validate(&updateReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.updateUserProfileUseCase.Patch(ctx, p.UserID, updateReq)
+	if err != nil {
+		return nil, err
+	}
+	return toPBUser(user), nil
+}
+
+func toPBUser(user *dto.UserResponse) *pb.User {
+	return &pb.User{
+		Id:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		Provider:      user.Provider,
+		Avatar:        user.Avatar,
+		EmailVerified: user.EmailVerified,
+		Locale:        user.Locale,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
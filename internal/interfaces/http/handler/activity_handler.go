@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler handles activity feed endpoints
+type ActivityHandler struct {
+	activityUseCase *usecase.ActivityUseCase
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activityUseCase *usecase.ActivityUseCase) *ActivityHandler {
+	return &ActivityHandler{
+		activityUseCase: activityUseCase,
+	}
+}
+
+// ListMyActivity godoc
+// @Summary List my activity
+// @Description List the activity of the authenticated user, newest first: the documents it uploaded, the changes to its profile and its logins from new devices
+// @Tags activity
+// @Produce json
+// @Param type query string false "Activity type" Enums(document_uploaded, profile_updated, new_device_login)
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.ActivityListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/activity [get]
+func (h *ActivityHandler) ListMyActivity(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	activityType := entity.ActivityType(c.Query("type"))
+	response, err := h.activityUseCase.ListUserActivity(c.Request.Context(), userID, activityType, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key management endpoints
+type APIKeyHandler struct {
+	createAPIKeyUseCase          *usecase.CreateAPIKeyUseCase
+	listAPIKeysUseCase           *usecase.ListAPIKeysUseCase
+	revokeAPIKeyUseCase          *usecase.RevokeAPIKeyUseCase
+	updateAPIKeyRateLimitUseCase *usecase.UpdateAPIKeyRateLimitUseCase
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(
+	createAPIKeyUseCase *usecase.CreateAPIKeyUseCase,
+	listAPIKeysUseCase *usecase.ListAPIKeysUseCase,
+	revokeAPIKeyUseCase *usecase.RevokeAPIKeyUseCase,
+	updateAPIKeyRateLimitUseCase *usecase.UpdateAPIKeyRateLimitUseCase,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		createAPIKeyUseCase:          createAPIKeyUseCase,
+		listAPIKeysUseCase:           listAPIKeysUseCase,
+		revokeAPIKeyUseCase:          revokeAPIKeyUseCase,
+		updateAPIKeyRateLimitUseCase: updateAPIKeyRateLimitUseCase,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key for the authenticated user. The plaintext key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "API key request"
+// @Security BearerAuth
+// @Success 201 {object} dto.CreateAPIKeyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.createAPIKeyUseCase.Execute(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the authenticated user's API keys
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.APIKeyResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.listAPIKeysUseCase.Execute(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke one of the authenticated user's API keys
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	err := h.revokeAPIKeyUseCase.Execute(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "API key revoked successfully",
+	})
+}
+
+// UpdateAPIKeyRateLimit godoc
+// @Summary Update API key rate limit
+// @Description Change the per-key request quota of an API key. It applies in place of the owner's rate limit tier, so it can be above or below it (admin only).
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body dto.UpdateAPIKeyRateLimitRequest true "Rate limit request"
+// @Security BearerAuth
+// @Success 200 {object} dto.APIKeyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/api-keys/{id}/rate-limit [put]
+func (h *APIKeyHandler) UpdateAPIKeyRateLimit(c *gin.Context) {
+	var req dto.UpdateAPIKeyRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.updateAPIKeyRateLimitUseCase.Execute(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogHandler handles the audit log endpoints
+type AuditLogHandler struct {
+	auditLogUseCase *usecase.AuditLogUseCase
+}
+
+// NewAuditLogHandler creates a new audit log handler
+func NewAuditLogHandler(auditLogUseCase *usecase.AuditLogUseCase) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogUseCase: auditLogUseCase,
+	}
+}
+
+// ListAuditLog godoc
+// @Summary List the audit log
+// @Description List who did what, newest first, optionally only the entries of an actor, action, target or time range (admin only)
+// @Tags audit-log
+// @Produce json
+// @Param actor_id query string false "ID of the user who acted"
+// @Param action query string false "Action" Enums(login, logout, logout_all, password_changed, password_reset, role_assigned, role_created, role_updated, role_deleted, user_created, user_deleted, user_restored, user_unlocked, rate_limit_tier_changed, avatar_approved, avatar_rejected, account_deleted, data_export_requested, document_deleted)
+// @Param target_type query string false "Type of the record acted on" Enums(user, role, document)
+// @Param target_id query string false "ID of the record acted on, or the name of a role"
+// @Param from query string false "Entries at or after the RFC 3339 time"
+// @Param to query string false "Entries before the RFC 3339 time"
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.AuditLogListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/audit-log [get]
+func (h *AuditLogHandler) ListAuditLog(c *gin.Context) {
+	var filter dto.AuditLogFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.auditLogUseCase.List(c.Request.Context(), filter, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
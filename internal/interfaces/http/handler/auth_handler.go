@@ -1,42 +1,89 @@
 package handler
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
 	"net/http"
-	"strings"
+	"net/url"
+	"strconv"
+	"time"
 
 	"gin-boilerplate/internal/application/dto"
 	"gin-boilerplate/internal/application/usecase"
-	"gin-boilerplate/internal/infrastructure/config"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/oauth"
+	"gin-boilerplate/internal/interfaces/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	registerUseCase  *usecase.RegisterUseCase
-	loginUseCase     *usecase.LoginUseCase
-	refreshUseCase   *usecase.RefreshTokenUseCase
-	logoutUseCase    *usecase.LogoutUseCase
-	googleAuthUseCase *usecase.GoogleAuthUseCase
-	googleConfig     *config.GoogleOAuthConfig
+	registerUseCase         *usecase.RegisterUseCase
+	loginUseCase            *usecase.LoginUseCase
+	refreshUseCase          *usecase.RefreshTokenUseCase
+	logoutUseCase           *usecase.LogoutUseCase
+	oauthLoginUseCase       *usecase.OAuthLoginUseCase
+	userProviderUseCase     *usecase.UserProviderUseCase
+	forgotPasswordUseCase   *usecase.ForgotPasswordUseCase
+	resetPasswordUseCase    *usecase.ResetPasswordUseCase
+	sendVerificationUseCase *usecase.SendVerificationUseCase
+	verifyEmailUseCase      *usecase.VerifyEmailUseCase
+	oauthProviders          *oauth.Registry
+	oauthStates             *service.OAuthStateService
+	oauthConfig             OAuthConfig
+	tokenCookies            TokenCookieConfig
+	// captcha verifies the CAPTCHAs of captchaActions; nil requires none
+	captcha        service.CaptchaService
+	captchaActions map[string]bool
 }
 
+// CaptchaHeader is the header clients send the token of a solved CAPTCHA in
+const CaptchaHeader = "X-Captcha-Token"
+
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(
 	registerUseCase *usecase.RegisterUseCase,
 	loginUseCase *usecase.LoginUseCase,
 	refreshUseCase *usecase.RefreshTokenUseCase,
 	logoutUseCase *usecase.LogoutUseCase,
-	googleAuthUseCase *usecase.GoogleAuthUseCase,
-	googleConfig *config.GoogleOAuthConfig,
+	oauthLoginUseCase *usecase.OAuthLoginUseCase,
+	userProviderUseCase *usecase.UserProviderUseCase,
+	forgotPasswordUseCase *usecase.ForgotPasswordUseCase,
+	resetPasswordUseCase *usecase.ResetPasswordUseCase,
+	sendVerificationUseCase *usecase.SendVerificationUseCase,
+	verifyEmailUseCase *usecase.VerifyEmailUseCase,
+	oauthProviders *oauth.Registry,
+	oauthStates *service.OAuthStateService,
+	oauthConfig OAuthConfig,
+	tokenCookies TokenCookieConfig,
+	captcha service.CaptchaService,
+	captchaActions []string,
 ) *AuthHandler {
+	actions := make(map[string]bool, len(captchaActions))
+	for _, action := range captchaActions {
+		actions[action] = true
+	}
+
 	return &AuthHandler{
-		registerUseCase:   registerUseCase,
-		loginUseCase:      loginUseCase,
-		refreshUseCase:    refreshUseCase,
-		logoutUseCase:     logoutUseCase,
-		googleAuthUseCase: googleAuthUseCase,
-		googleConfig:      googleConfig,
+		registerUseCase:         registerUseCase,
+		loginUseCase:            loginUseCase,
+		refreshUseCase:          refreshUseCase,
+		logoutUseCase:           logoutUseCase,
+		oauthLoginUseCase:       oauthLoginUseCase,
+		userProviderUseCase:     userProviderUseCase,
+		forgotPasswordUseCase:   forgotPasswordUseCase,
+		resetPasswordUseCase:    resetPasswordUseCase,
+		sendVerificationUseCase: sendVerificationUseCase,
+		verifyEmailUseCase:      verifyEmailUseCase,
+		oauthProviders:          oauthProviders,
+		oauthStates:             oauthStates,
+		oauthConfig:             oauthConfig,
+		tokenCookies:            tokenCookies,
+		captcha:                 captcha,
+		captchaActions:          actions,
 	}
 }
 
@@ -44,146 +91,118 @@ func NewAuthHandler(
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_REQUEST",
-				Message: err.Error(),
-			},
-		})
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.verifyCaptcha(c, service.CaptchaActionRegister); err != nil {
+		c.Error(err)
 		return
 	}
 
 	response, err := h.registerUseCase.Execute(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "email already exists") {
-			c.JSON(http.StatusConflict, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "EMAIL_EXISTS",
-					Message: "Email already exists",
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "REGISTRATION_FAILED",
-				Message: "Failed to register user",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	h.tokenCookies.respond(c, http.StatusCreated, response)
 }
 
 // Login handles user login
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_REQUEST",
-				Message: err.Error(),
-			},
-		})
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.verifyCaptcha(c, service.CaptchaActionLogin); err != nil {
+		c.Error(err)
 		return
 	}
 
-	response, err := h.loginUseCase.Execute(c.Request.Context(), req)
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, challenge, err := h.loginUseCase.Execute(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid credentials") || strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INVALID_CREDENTIALS",
-					Message: "Email or password is incorrect",
-				},
-			})
-			return
-		}
+		c.Error(err)
+		return
+	}
 
-		if strings.Contains(err.Error(), "OAuth login") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "OAUTH_REQUIRED",
-					Message: "Please use OAuth login for this account",
-				},
-			})
-			return
-		}
+	// The login waits for the second factor
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, challenge)
+		return
+	}
+
+	h.tokenCookies.respond(c, http.StatusOK, response)
+}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "LOGIN_FAILED",
-				Message: "Failed to login",
-			},
-		})
+// LoginTwoFactor completes a login with the code of its second factor or a recovery code
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.loginUseCase.CompleteTwoFactor(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.tokenCookies.respond(c, http.StatusOK, response)
 }
 
-// RefreshToken handles token refresh
+// RefreshToken handles token refresh. A refresh token sent as a cookie gets the new tokens as
+// cookies too.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_REQUEST",
-				Message: err.Error(),
-			},
-		})
+	req, fromCookie, err := h.bindRefreshToken(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	response, err := h.refreshUseCase.Execute(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid refresh token") || strings.Contains(err.Error(), "revoked") {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INVALID_REFRESH_TOKEN",
-					Message: "Invalid or expired refresh token",
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "TOKEN_REFRESH_FAILED",
-				Message: "Failed to refresh token",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
+	if fromCookie {
+		h.tokenCookies.respond(c, http.StatusOK, response)
+		return
+	}
 	c.JSON(http.StatusOK, response)
 }
 
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
-	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_REQUEST",
-				Message: err.Error(),
-			},
-		})
+	req, _, err := h.bindRefreshToken(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := h.logoutUseCase.Execute(c.Request.Context(), req.RefreshToken)
+	// The access token is denied too; API key callers have none
+	claims, _ := c.Get("token_claims")
+	accessToken, _ := claims.(*service.TokenClaims)
+
+	err = h.logoutUseCase.Execute(c.Request.Context(), req.RefreshToken, accessToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "LOGOUT_FAILED",
-				Message: "Failed to logout",
-			},
-		})
+		c.Error(err)
 		return
 	}
+	h.tokenCookies.clear(c)
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Message: "Successfully logged out",
@@ -194,115 +213,471 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) LogoutAll(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "UNAUTHORIZED",
-				Message: "User not authenticated",
-			},
-		})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	err := h.logoutUseCase.ExecuteAll(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "LOGOUT_ALL_FAILED",
-				Message: "Failed to logout from all devices",
-			},
-		})
+		c.Error(err)
 		return
 	}
+	h.tokenCookies.clear(c)
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Message: "Successfully logged out from all devices",
 	})
 }
 
-// GoogleAuth redirects to Google OAuth
-func (h *AuthHandler) GoogleAuth(c *gin.Context) {
-	state := config.GenerateRandomState()
+// ForgotPassword emails a password reset link. It answers the same whether or not the email has
+// an account.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
 
-	// Store state in session or cookie (simplified for this example)
-	c.SetCookie("oauth_state", state, 300, "/", "", false, true)
+	if err := h.verifyCaptcha(c, service.CaptchaActionForgotPassword); err != nil {
+		c.Error(err)
+		return
+	}
 
-	authURL := h.googleConfig.GetAuthURL(state)
-	c.Redirect(http.StatusTemporaryRedirect, authURL)
+	if err := h.forgotPasswordUseCase.Execute(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: "If the email has an account, a password reset link was sent to it",
+	})
+}
+
+// ResetPassword sets a new password with the token of a reset link
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	if err := h.resetPasswordUseCase.Execute(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Password was reset, please log in with the new password",
+	})
+}
+
+// VerifyEmail verifies the user's email with the token of a verification link
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.verifyEmailUseCase.Execute(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResendVerification emails a new verification link. It answers the same whether or not the
+// email has an unverified account.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.sendVerificationUseCase.Resend(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: "If the email has an unverified account, a verification link was sent to it",
+	})
+}
+
+// oauthStateCookie holds the nonce the state of a sign-in with an OAuth provider is kept under
+// until its callback
+const oauthStateCookie = "oauth_state"
+
+// Cookies the tokens are set in, by the callback of an OAuth sign-in in cookie mode and by the
+// auth endpoints when token cookies are enabled
+const (
+	AccessTokenCookie  = middleware.AccessTokenCookie
+	RefreshTokenCookie = "refresh_token"
+)
+
+// Ways the callback of an OAuth sign-in hands over the tokens
+const (
+	// OAuthCallbackJSON returns them in the body of the response
+	OAuthCallbackJSON = "json"
+	// OAuthCallbackCookie sets them as httpOnly cookies and redirects to the frontend, so
+	// scripts in the browser can't read them
+	OAuthCallbackCookie = "cookie"
+)
+
+// OAuthConfig sets how sign-ins with OAuth providers hand over their tokens
+type OAuthConfig struct {
+	// CallbackMode is OAuthCallbackJSON or OAuthCallbackCookie
+	CallbackMode string
+	// SuccessURL is the frontend page the callback redirects to in cookie mode
+	SuccessURL string
+	// CookieDomain and CookieSecure apply to the state and token cookies
+	CookieDomain string
+	CookieSecure bool
+	// RefreshExpiry is how long the refresh token cookie is kept
+	RefreshExpiry time.Duration
+}
+
+// setCookie sets an httpOnly cookie of the OAuth flow, or clears it with a negative maxAge.
+// Cookies are SameSite=Lax, so they are sent when the provider redirects back to the callback.
+func (cfg OAuthConfig) setCookie(c *gin.Context, name, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   cfg.CookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// TokenCookieConfig sets whether the auth endpoints hand the tokens over as cookies, for browser
+// clients, instead of in the body
+type TokenCookieConfig struct {
+	// Enabled sets the tokens as httpOnly cookies, along with a CSRF token cookie that scripts
+	// can read
+	Enabled  bool
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	// RefreshExpiry is how long the refresh token and CSRF token cookies are kept
+	RefreshExpiry time.Duration
+}
+
+// respond answers with the tokens of a sign-in, set as cookies when enabled
+func (cfg TokenCookieConfig) respond(c *gin.Context, status int, response *dto.AuthResponse) {
+	if cfg.Enabled {
+		if err := cfg.set(c, response); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	c.JSON(status, response)
 }
 
-// GoogleCallback handles Google OAuth callback
+// set sets the tokens of the response as cookies, with a new CSRF token, and removes them from
+// the response, which keeps the user and when the access token expires. Responses without
+// tokens, such as registrations waiting for the email to be verified, are left as they are.
+func (cfg TokenCookieConfig) set(c *gin.Context, response *dto.AuthResponse) error {
+	if response.AccessToken == "" {
+		return nil
+	}
+
+	csrfToken := make([]byte, 32)
+	if _, err := rand.Read(csrfToken); err != nil {
+		return fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+
+	refreshMaxAge := int(cfg.RefreshExpiry.Seconds())
+	cfg.setCookie(c, AccessTokenCookie, response.AccessToken, int(response.ExpiresIn), true)
+	cfg.setCookie(c, RefreshTokenCookie, response.RefreshToken, refreshMaxAge, true)
+	cfg.setCookie(c, middleware.CSRFCookie, base64.RawURLEncoding.EncodeToString(csrfToken), refreshMaxAge, false)
+
+	response.AccessToken = ""
+	response.RefreshToken = ""
+	response.TokenType = ""
+	return nil
+}
+
+// clear removes the token and CSRF token cookies when enabled
+func (cfg TokenCookieConfig) clear(c *gin.Context) {
+	if !cfg.Enabled {
+		return
+	}
+	cfg.setCookie(c, AccessTokenCookie, "", -1, true)
+	cfg.setCookie(c, RefreshTokenCookie, "", -1, true)
+	cfg.setCookie(c, middleware.CSRFCookie, "", -1, false)
+}
+
+// setCookie sets a cookie, or clears it with a negative maxAge
+func (cfg TokenCookieConfig) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cfg.SameSite,
+	})
+}
+
+// beginOAuth starts a sign-in with the provider, or links an account at it to the user of
+// linkUserID when not empty. The nonce its state is kept under is set in the state cookie, and
+// the URL of the provider's consent page is returned.
+func beginOAuth(c *gin.Context, states *service.OAuthStateService, cfg OAuthConfig, provider oauth.Provider, linkUserID string) (string, error) {
+	nonce, state, err := states.Begin(c.Request.Context(), provider.Name(), linkUserID)
+	if err != nil {
+		return "", err
+	}
+
+	// The callback must come back to the browser that started the sign-in
+	cfg.setCookie(c, oauthStateCookie, nonce, int(states.TTL().Seconds()))
+
+	return provider.AuthURL(state.State, state.CodeVerifier), nil
+}
+
+// OAuthRedirect redirects to the consent page of the OAuth provider named in the path
+func (h *AuthHandler) OAuthRedirect(c *gin.Context) {
+	h.oauthRedirect(c, c.Param("provider"))
+}
+
+// OAuthCallback handles the callback of the OAuth provider named in the path
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	h.oauthCallback(c, c.Param("provider"))
+}
+
+// GoogleAuth redirects to Google OAuth. It predates OAuthRedirect and is kept for existing
+// clients.
+func (h *AuthHandler) GoogleAuth(c *gin.Context) {
+	h.oauthRedirect(c, "google")
+}
+
+// GoogleCallback handles Google OAuth callback. It predates OAuthCallback and is kept for the
+// redirect URLs registered with Google.
 func (h *AuthHandler) GoogleCallback(c *gin.Context) {
-	// Get state from cookie
-	stateCookie, err := c.Cookie("oauth_state")
+	h.oauthCallback(c, "google")
+}
+
+// GoogleToken signs in with an ID token a mobile app got from Google's native sign-in SDK, without
+// the browser redirect of GoogleAuth
+func (h *AuthHandler) GoogleToken(c *gin.Context) {
+	h.oauthToken(c, "google")
+}
+
+// oauthRedirect redirects to the consent page of the provider
+func (h *AuthHandler) oauthRedirect(c *gin.Context, providerName string) {
+	provider, ok := h.oauthProviders.Get(providerName)
+	if !ok {
+		c.Error(domain.ErrOAuthProviderNotFound)
+		return
+	}
+
+	authURL, err := beginOAuth(c, h.oauthStates, h.oauthConfig, provider, "")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_STATE",
-				Message: "OAuth state not found",
-			},
-		})
+		c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, authURL)
+}
+
+// oauthCallback exchanges the authorization code of the provider's callback, and signs in the
+// user of the account, or links the account to the user who started linking it
+func (h *AuthHandler) oauthCallback(c *gin.Context, providerName string) {
+	provider, ok := h.oauthProviders.Get(providerName)
+	if !ok {
+		c.Error(domain.ErrOAuthProviderNotFound)
 		return
 	}
 
-	// Clear state cookie
-	c.SetCookie("oauth_state", "", -1, "/", "", false, true)
+	// Look up the sign-in of the state cookie, which completes once
+	nonce, _ := c.Cookie(oauthStateCookie)
+	h.oauthConfig.setCookie(c, oauthStateCookie, "", -1)
+
+	oauthState, err := h.oauthStates.Consume(c.Request.Context(), nonce)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if oauthState == nil {
+		c.Error(domain.ErrInvalidOAuthState.WithMessage("OAuth state not found"))
+		return
+	}
 
 	// Verify state
-	receivedState := c.Query("state")
-	if !config.VerifyState(receivedState, stateCookie) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_STATE",
-				Message: "Invalid OAuth state",
-			},
-		})
+	if oauthState.Provider != provider.Name() || !oauth.VerifyState(c.Query("state"), oauthState.State) {
+		c.Error(domain.ErrInvalidOAuthState)
 		return
 	}
 
 	// Get authorization code
 	code := c.Query("code")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "MISSING_CODE",
-				Message: "Authorization code not found",
-			},
-		})
+		c.Error(domain.ErrMissingOAuthCode)
 		return
 	}
 
-	// Exchange code for user info
-	userInfo, err := h.googleConfig.HandleCallback(c.Request.Context(), code, receivedState)
+	// Exchange code for the account of the user. Providers may check their tokens against
+	// the state.
+	ctx := oauth.WithState(c.Request.Context(), oauthState.State)
+	token, err := provider.Exchange(ctx, code, oauthState.CodeVerifier)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "GOOGLE_AUTH_FAILED",
-				Message: "Failed to authenticate with Google",
-			},
-		})
+		c.Error(domain.ErrOAuthFailed.Wrap(err))
 		return
 	}
 
-	// Convert to our GoogleUserInfo type
-	googleUser := &usecase.GoogleUserInfo{
-		ID:            userInfo.ID,
-		Email:         userInfo.Email,
-		Name:          userInfo.Name,
-		Avatar:        userInfo.Picture,
-		VerifiedEmail: userInfo.VerifiedEmail,
+	account, err := provider.FetchUserInfo(ctx, token)
+	if err != nil {
+		c.Error(domain.ErrOAuthFailed.Wrap(err))
+		return
+	}
+
+	// A sign-in started at POST /users/me/providers/{provider} links the account
+	if oauthState.LinkUserID != "" {
+		providers, err := h.userProviderUseCase.Link(c.Request.Context(), oauthState.LinkUserID, provider.Name(), account, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if h.oauthConfig.CallbackMode == OAuthCallbackCookie {
+			c.Redirect(http.StatusFound, h.oauthConfig.SuccessURL)
+			return
+		}
+		c.JSON(http.StatusOK, providers)
+		return
 	}
 
 	// Authenticate user
-	response, err := h.googleAuthUseCase.Execute(c.Request.Context(), googleUser)
+	response, challenge, err := h.oauthLoginUseCase.Execute(c.Request.Context(), provider.Name(), account, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The login waits for the second factor, completed at POST /auth/login/two-factor
+	if challenge != nil {
+		if h.oauthConfig.CallbackMode == OAuthCallbackCookie {
+			c.Redirect(http.StatusFound, twoFactorRedirectURL(h.oauthConfig.SuccessURL, challenge))
+			return
+		}
+		c.JSON(http.StatusAccepted, challenge)
+		return
+	}
+
+	if h.oauthConfig.CallbackMode == OAuthCallbackCookie {
+		if h.tokenCookies.Enabled {
+			if err := h.tokenCookies.set(c, response); err != nil {
+				c.Error(err)
+				return
+			}
+		} else {
+			h.oauthConfig.setCookie(c, AccessTokenCookie, response.AccessToken, int(response.ExpiresIn))
+			h.oauthConfig.setCookie(c, RefreshTokenCookie, response.RefreshToken, int(h.oauthConfig.RefreshExpiry.Seconds()))
+		}
+		c.Redirect(http.StatusFound, h.oauthConfig.SuccessURL)
+		return
+	}
+
+	h.tokenCookies.respond(c, http.StatusOK, response)
+}
+
+// oauthToken signs in the user of the provider account an ID token was issued for. The tokens are
+// always returned in the body, since native apps don't use the cookies of the callback.
+func (h *AuthHandler) oauthToken(c *gin.Context, providerName string) {
+	provider, ok := h.oauthProviders.Get(providerName)
+	if !ok {
+		c.Error(domain.ErrOAuthProviderNotFound)
+		return
+	}
+	verifier, ok := provider.(oauth.IDTokenVerifier)
+	if !ok {
+		c.Error(domain.ErrOAuthProviderNotFound)
+		return
+	}
+
+	var req dto.IDTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	account, err := verifier.VerifyIDToken(c.Request.Context(), req.IDToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "GOOGLE_LOGIN_FAILED",
-				Message: "Failed to login with Google",
-			},
-		})
+		c.Error(domain.ErrInvalidIDToken.Wrap(err))
+		return
+	}
+
+	response, challenge, err := h.oauthLoginUseCase.Execute(c.Request.Context(), provider.Name(), account, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The login waits for the second factor
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, challenge)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// twoFactorRedirectURL returns the success page of cookie mode with the two-factor challenge of
+// the login in its query, two_factor_token, method, phone and expires_in, for the page to ask
+// for the code
+func twoFactorRedirectURL(successURL string, challenge *dto.TwoFactorChallengeResponse) string {
+	link, err := url.Parse(successURL)
+	if err != nil {
+		return successURL
+	}
+	query := link.Query()
+	query.Set("two_factor_token", challenge.TwoFactorToken)
+	query.Set("method", challenge.Method)
+	query.Set("phone", challenge.Phone)
+	query.Set("expires_in", strconv.FormatInt(challenge.ExpiresIn, 10))
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// bindRefreshToken reads the refresh token of the request body, or of the refresh token cookie
+// when token cookies are enabled, reporting whether it came from the cookie. Requests sending
+// the cookie need the CSRF token, since any site can make a browser send it.
+func (h *AuthHandler) bindRefreshToken(c *gin.Context) (dto.RefreshTokenRequest, bool, error) {
+	var req dto.RefreshTokenRequest
+	if h.tokenCookies.Enabled {
+		if token, err := c.Cookie(RefreshTokenCookie); err == nil && token != "" {
+			if !middleware.ValidCSRF(c) {
+				return req, false, domain.ErrInvalidCSRFToken
+			}
+			req.RefreshToken = token
+			return req, true, nil
+		}
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return req, false, domain.NewValidationError(err)
+	}
+	return req, false, nil
+}
+
+// verifyCaptcha checks the CAPTCHA token of the request when the action requires one
+func (h *AuthHandler) verifyCaptcha(c *gin.Context, action string) error {
+	if h.captcha == nil || !h.captchaActions[action] {
+		return nil
+	}
+
+	token := c.GetHeader(CaptchaHeader)
+	if token == "" {
+		return domain.ErrCaptchaRequired
+	}
+	return h.captcha.Verify(c.Request.Context(), token, action, c.ClientIP())
+}
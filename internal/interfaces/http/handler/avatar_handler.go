@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"gin-boilerplate/internal/application/dto"
 	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,13 +27,14 @@ func NewAvatarHandler(avatarUseCase *usecase.AvatarUseCase) *AvatarHandler {
 
 // UploadAvatar godoc
 // @Summary Upload user avatar
-// @Description Upload a new avatar image for the authenticated user
+// @Description Upload a new avatar image for the authenticated user. With AVATAR_MODERATION, it awaits a moderator's approval with 202, and the previous avatar is shown meanwhile.
 // @Tags users
 // @Accept multipart/form-data
 // @Produce json
 // @Param avatar formData file true "Avatar image file (max 2MB, supported: JPEG, PNG, GIF, WebP)"
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 413 {object} map[string]interface{}
@@ -37,14 +43,14 @@ func NewAvatarHandler(avatarUseCase *usecase.AvatarUseCase) *AvatarHandler {
 func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	// Get uploaded file
 	file, err := c.FormFile("avatar")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Avatar file is required"})
+		c.Error(domain.ErrFileRequired.WithMessage("Avatar file is required"))
 		return
 	}
 
@@ -60,7 +66,7 @@ func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
 	}
 
 	if !validExt {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Supported: JPEG, PNG, GIF, WebP"})
+		c.Error(domain.ErrInvalidFileType.WithMessage("Invalid file type. Supported: JPEG, PNG, GIF, WebP"))
 		return
 	}
 
@@ -70,29 +76,31 @@ func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
 		File:   file,
 	}
 
-	apiURL, err := h.avatarUseCase.UploadAvatar(c.Request.Context(), req)
+	apiURL, pending, err := h.avatarUseCase.UploadAvatar(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "file too large") {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large (max 2MB)"})
-			return
-		}
-		if strings.Contains(err.Error(), "invalid file type") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload avatar"})
+		c.Error(err)
+		return
+	}
+
+	// The avatar shown stays the previous one until a moderator approves the upload
+	if pending {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":        "Avatar uploaded and awaiting approval",
+			"avatar_url":     apiURL,
+			"avatar_pending": true,
+		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Avatar uploaded successfully",
+		"message":    "Avatar uploaded successfully",
 		"avatar_url": *apiURL,
 	})
 }
 
 // RemoveAvatar godoc
 // @Summary Remove user avatar
-// @Description Remove the current avatar image for the authenticated user
+// @Description Remove the current avatar image for the authenticated user, and withdraw an upload awaiting moderation
 // @Tags users
 // @Produce json
 // @Security BearerAuth
@@ -104,25 +112,13 @@ func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
 func (h *AvatarHandler) RemoveAvatar(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	err := h.avatarUseCase.RemoveAvatar(c.Request.Context(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "cannot remove Google") {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot remove Google OAuth avatar"})
-			return
-		}
-		if strings.Contains(err.Error(), "user not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		if strings.Contains(err.Error(), "has no avatar") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User has no avatar to remove"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar"})
+		c.Error(err)
 		return
 	}
 
@@ -145,26 +141,18 @@ func (h *AvatarHandler) RemoveAvatar(c *gin.Context) {
 func (h *AvatarHandler) ServeAvatar(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		c.Error(domain.ErrValidation.WithMessage("User ID is required"))
 		return
 	}
 
 	avatarURL, _, err := h.avatarUseCase.ServeAvatar(c.Request.Context(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "user not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		if strings.Contains(err.Error(), "has no avatar") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User has no avatar"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get avatar"})
+		c.Error(err)
 		return
 	}
 
 	if avatarURL == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User has no avatar"})
+		c.Error(domain.ErrAvatarNotFound)
 		return
 	}
 
@@ -176,4 +164,117 @@ func (h *AvatarHandler) ServeAvatar(c *gin.Context) {
 
 	// For S3 avatars, redirect to presigned URL
 	c.Redirect(http.StatusFound, *avatarURL)
-}
\ No newline at end of file
+}
+
+// ListPendingAvatars godoc
+// @Summary List avatars awaiting moderation
+// @Description List the users whose uploaded avatar awaits moderation, the oldest upload first (needs avatars:moderate)
+// @Tags avatars
+// @Produce json
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.PendingAvatarsListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/avatars [get]
+func (h *AvatarHandler) ListPendingAvatars(c *gin.Context) {
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.avatarUseCase.ListPendingAvatars(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ServePendingAvatar godoc
+// @Summary View a pending avatar
+// @Description Redirect to the user's uploaded avatar awaiting moderation (needs avatars:moderate)
+// @Tags avatars
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 302 {string} string "Redirect to the avatar"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/avatars/{id} [get]
+func (h *AvatarHandler) ServePendingAvatar(c *gin.Context) {
+	avatarURL, err := h.avatarUseCase.ServePendingAvatar(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, *avatarURL)
+}
+
+// ApproveAvatar godoc
+// @Summary Approve a pending avatar
+// @Description Show the user's uploaded avatar awaiting moderation in place of its avatar, which is deleted. The user's event stream and webhooks get avatar.updated (needs avatars:moderate).
+// @Tags avatars
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/avatars/{id}/approve [post]
+func (h *AvatarHandler) ApproveAvatar(c *gin.Context) {
+	if err := h.avatarUseCase.ApproveAvatar(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Avatar approved successfully",
+	})
+}
+
+// RejectAvatar godoc
+// @Summary Reject a pending avatar
+// @Description Delete the user's uploaded avatar awaiting moderation, keeping its avatar. The user's event stream and webhooks get avatar.rejected with the reason (needs avatars:moderate).
+// @Tags avatars
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.RejectAvatarRequest false "Reason told to the user"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/avatars/{id}/reject [post]
+func (h *AvatarHandler) RejectAvatar(c *gin.Context) {
+	// The body is optional, rejecting without a reason
+	var req dto.RejectAvatarRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.avatarUseCase.RejectAvatar(c.Request.Context(), c.Param("id"), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Avatar rejected successfully",
+	})
+}
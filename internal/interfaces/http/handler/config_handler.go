@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/infrastructure/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler handles the effective configuration endpoint
+type ConfigHandler struct {
+	config *config.Config
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(config *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		config: config,
+	}
+}
+
+// GetConfig godoc
+// @Summary Get the effective configuration
+// @Description List the settings the server was started with, where each value came from, and with credentials redacted, optionally filtered by name prefix such as "DB_" (admin only)
+// @Tags config
+// @Produce json
+// @Param prefix query string false "Setting name prefix"
+// @Security BearerAuth
+// @Success 200 {object} dto.ConfigResponse
+// @Router /admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	prefix := strings.ToUpper(c.Query("prefix"))
+
+	settings := []dto.ConfigSettingResponse{}
+	for _, setting := range h.config.Summary() {
+		if !strings.HasPrefix(setting.Name, prefix) {
+			continue
+		}
+		settings = append(settings, dto.ConfigSettingResponse{
+			Name:   setting.Name,
+			Value:  setting.Value,
+			Source: setting.Source,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.ConfigResponse{
+		Environment: h.config.Server.Env,
+		Settings:    settings,
+	})
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DashboardHandler handles the read-only admin operations dashboard endpoints
+type DashboardHandler struct {
+	dashboardUseCase *usecase.DashboardUseCase
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(dashboardUseCase *usecase.DashboardUseCase) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardUseCase: dashboardUseCase,
+	}
+}
+
+// GetActivity godoc
+// @Summary Get daily activity
+// @Description Get the daily signups, logins and document uploads of the last days, oldest first (admin only)
+// @Tags dashboard
+// @Produce json
+// @Param days query int false "Number of days, at most 90" default(30)
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardActivityResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dashboard/activity [get]
+func (h *DashboardHandler) GetActivity(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	response, err := h.dashboardUseCase.Activity(c.Request.Context(), days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetStorage godoc
+// @Summary Get top storage consumers
+// @Description Get the users whose documents take the most storage, largest first (admin only)
+// @Tags dashboard
+// @Produce json
+// @Param limit query int false "Number of users" default(10)
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardStorageResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dashboard/storage [get]
+func (h *DashboardHandler) GetStorage(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.dashboardUseCase.StorageConsumers(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRateLimits godoc
+// @Summary Get daily rate limit rejections
+// @Description Get the requests rejected by the rate limiter on each of the last days, oldest first (admin only)
+// @Tags dashboard
+// @Produce json
+// @Param days query int false "Number of days, at most 90" default(30)
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardRateLimitResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dashboard/rate-limits [get]
+func (h *DashboardHandler) GetRateLimits(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	response, err := h.dashboardUseCase.RateLimitRejections(c.Request.Context(), days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetJobs godoc
+// @Summary Get job queue depth
+// @Description Get how many background jobs are pending, running, succeeded and dead (admin only)
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardJobsResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dashboard/jobs [get]
+func (h *DashboardHandler) GetJobs(c *gin.Context) {
+	response, err := h.dashboardUseCase.JobQueue(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhooks godoc
+// @Summary Get webhook failure rates
+// @Description Get the failure rate of the webhook deliveries of the last hours, and the webhooks that failed most (admin only)
+// @Tags dashboard
+// @Produce json
+// @Param hours query int false "Number of hours, at most 2160" default(24)
+// @Param limit query int false "Number of failing webhooks" default(10)
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardWebhooksResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dashboard/webhooks [get]
+func (h *DashboardHandler) GetWebhooks(c *gin.Context) {
+	hours, _ := strconv.Atoi(c.Query("hours"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.dashboardUseCase.WebhookFailures(c.Request.Context(), hours, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
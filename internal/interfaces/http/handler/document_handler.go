@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/interfaces/dto"
 
 	"github.com/gin-gonic/gin"
@@ -40,7 +43,7 @@ func NewDocumentHandler(documentUseCase *usecase.DocumentUseCase) *DocumentHandl
 func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
@@ -51,7 +54,7 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	// Get file
 	file, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		c.Error(domain.ErrFileRequired)
 		return
 	}
 
@@ -65,15 +68,7 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 
 	document, err := h.documentUseCase.UploadDocument(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "file too large") {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large (max 10MB)"})
-			return
-		}
-		if strings.Contains(err.Error(), "invalid file type") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		c.Error(err)
 		return
 	}
 
@@ -95,23 +90,19 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		c.Error(domain.ErrValidation.WithMessage("Document ID is required"))
 		return
 	}
 
 	document, err := h.documentUseCase.GetDocument(c.Request.Context(), documentID, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get document"})
+		c.Error(err)
 		return
 	}
 
@@ -120,23 +111,77 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 
 // GetUserDocuments godoc
 // @Summary Get user's documents
-// @Description Get all documents for the authenticated user
+// @Description Get a page of the authenticated user's documents. The total count is also sent in the X-Total-Count header, and the first, prev, next and last pages in the Link header. With a cursor, empty for the first page, pages are keyset pages without a total, and the response has the next_cursor of the next page.
 // @Tags documents
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
+// @Param cursor query string false "Keyset page cursor, replacing page"
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Header 200 {integer} X-Total-Count "Total number of documents"
+// @Header 200 {string} Link "RFC 5988 links to the first, prev, next and last pages"
 // @Failure 401 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /documents [get]
 func (h *DocumentHandler) GetUserDocuments(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
+	h.listDocuments(c, userID)
+}
+
+// ListUserDocuments godoc
+// @Summary List a user's documents
+// @Description Get a page of any user's documents, paginated like GET /documents, to moderate them (needs documents:moderate)
+// @Tags documents
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param cursor query string false "Keyset page cursor, replacing page"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Header 200 {integer} X-Total-Count "Total number of documents"
+// @Header 200 {string} Link "RFC 5988 links to the first, prev, next and last pages"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /users/{id}/documents [get]
+func (h *DocumentHandler) ListUserDocuments(c *gin.Context) {
+	h.listDocuments(c, c.Param("id"))
+}
+
+// DeleteUserDocument godoc
+// @Summary Delete a user's document
+// @Description Delete any user's document and its file, to moderate it (needs documents:moderate). The owner's event stream and webhooks are told, as when it deletes the document itself.
+// @Tags documents
+// @Produce json
+// @Param id path string true "User ID"
+// @Param document_id path string true "Document ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /users/{id}/documents/{document_id} [delete]
+func (h *DocumentHandler) DeleteUserDocument(c *gin.Context) {
+	// The document must be the user's, like its own documents are to users
+	err := h.documentUseCase.DeleteDocument(c.Request.Context(), c.Param("document_id"), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
+
+// listDocuments responds with a page of the user's documents
+func (h *DocumentHandler) listDocuments(c *gin.Context, userID string) {
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -148,19 +193,90 @@ func (h *DocumentHandler) GetUserDocuments(c *gin.Context) {
 		limit = 10
 	}
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		documents, nextCursor, err := h.documentUseCase.GetUserDocumentsAfter(c.Request.Context(), userID, cursor, limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		paginateCursor(c, nextCursor)
+		c.JSON(http.StatusOK, gin.H{
+			"documents":   documents,
+			"limit":       limit,
+			"next_cursor": nextCursor,
+		})
+		return
+	}
+
 	offset := (page - 1) * limit
 
-	documents, err := h.documentUseCase.GetUserDocuments(c.Request.Context(), userID, limit, offset)
+	documents, total, err := h.documentUseCase.GetUserDocuments(c.Request.Context(), userID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get documents"})
+		c.Error(err)
 		return
 	}
 
+	links := paginate(c, total, limit, offset, numberedPageQuery)
+
 	c.JSON(http.StatusOK, gin.H{
 		"documents": documents,
 		"page":      page,
 		"limit":     limit,
-		"total":     len(documents),
+		"total":     total,
+		"links":     links,
+	})
+}
+
+// SearchDocuments godoc
+// @Summary Search my documents
+// @Description Full-text search of the authenticated user's documents by title, description and the text of plain-text files, the best matches first. The query takes "quoted phrases", or, and -excluded words. title_highlight and snippet are HTML, with the matching words in <mark> elements and the rest escaped. The total count is also sent in the X-Total-Count header, and the first, prev, next and last pages in the Link header.
+// @Tags documents
+// @Produce json
+// @Param q query string true "Search query, at most 256 characters"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Header 200 {integer} X-Total-Count "Total number of matching documents"
+// @Header 200 {string} Link "RFC 5988 links to the first, prev, next and last pages"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /documents/search [get]
+func (h *DocumentHandler) SearchDocuments(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	results, total, err := h.documentUseCase.SearchDocuments(c.Request.Context(), userID, c.Query("q"), limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, total, limit, offset, numberedPageQuery)
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+		"links":   links,
 	})
 }
 
@@ -182,19 +298,19 @@ func (h *DocumentHandler) GetUserDocuments(c *gin.Context) {
 func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		c.Error(domain.ErrValidation.WithMessage("Document ID is required"))
 		return
 	}
 
 	var req dto.UpdateDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewValidationError(err))
 		return
 	}
 
@@ -206,11 +322,7 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 		req.Description,
 	)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		c.Error(err)
 		return
 	}
 
@@ -232,23 +344,19 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		c.Error(domain.ErrValidation.WithMessage("Document ID is required"))
 		return
 	}
 
 	err := h.documentUseCase.DeleteDocument(c.Request.Context(), documentID, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document"})
+		c.Error(err)
 		return
 	}
 
@@ -265,32 +373,72 @@ func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 // @Success 200 {object} dto.PresignedURLResponse
 // @Failure 401 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /documents/{id}/download [get]
 func (h *DocumentHandler) GetPresignedURL(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		c.Error(domain.ErrValidation.WithMessage("Document ID is required"))
 		return
 	}
 
 	url, err := h.documentUseCase.GetPresignedURL(c.Request.Context(), documentID, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate download URL"})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.PresignedURLResponse{
 		URL: *url,
 	})
-}
\ No newline at end of file
+}
+
+// DownloadDocumentsZip godoc
+// @Summary Download documents as a ZIP archive
+// @Description Download the files of up to 100 of the authenticated user's documents as a ZIP archive, built as it is sent. Files missing from storage are left out and listed in an errors.txt entry. Counts against the monthly download quota.
+// @Tags documents
+// @Accept json
+// @Produce application/zip
+// @Param request body dto.DownloadDocumentsZipRequest true "Documents to download"
+// @Security BearerAuth
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /documents/download-zip [post]
+func (h *DocumentHandler) DownloadDocumentsZip(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.DownloadDocumentsZipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	// The response starts once the documents were found
+	open := func() io.Writer {
+		filename := fmt.Sprintf("documents-%s.zip", time.Now().UTC().Format("20060102"))
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Status(http.StatusOK)
+		return c.Writer
+	}
+
+	if err := h.documentUseCase.DownloadDocumentsZip(c.Request.Context(), userID, req.DocumentIDs, open); err != nil {
+		c.Error(err)
+	}
+}
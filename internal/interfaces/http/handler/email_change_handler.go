@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailChangeHandler handles the endpoints changing the email of users
+type EmailChangeHandler struct {
+	requestEmailChangeUseCase *usecase.RequestEmailChangeUseCase
+	confirmEmailChangeUseCase *usecase.ConfirmEmailChangeUseCase
+	revertEmailChangeUseCase  *usecase.RevertEmailChangeUseCase
+}
+
+// NewEmailChangeHandler creates a new email change handler
+func NewEmailChangeHandler(
+	requestEmailChangeUseCase *usecase.RequestEmailChangeUseCase,
+	confirmEmailChangeUseCase *usecase.ConfirmEmailChangeUseCase,
+	revertEmailChangeUseCase *usecase.RevertEmailChangeUseCase,
+) *EmailChangeHandler {
+	return &EmailChangeHandler{
+		requestEmailChangeUseCase: requestEmailChangeUseCase,
+		confirmEmailChangeUseCase: confirmEmailChangeUseCase,
+		revertEmailChangeUseCase:  revertEmailChangeUseCase,
+	}
+}
+
+// RequestEmailChange godoc
+// @Summary Change the email
+// @Description Email a confirmation link to the new address and a cancel link to the current one. The email changes once the new address is confirmed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangeEmailRequest true "New email and current password"
+// @Security BearerAuth
+// @Success 202 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/email [post]
+func (h *EmailChangeHandler) RequestEmailChange(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.requestEmailChangeUseCase.Execute(c.Request.Context(), userID, req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: "A confirmation link was sent to the new email",
+	})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Change the email to the new address with the token of its confirmation link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.EmailChangeTokenRequest true "Confirmation link token"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/confirm-email-change [post]
+func (h *EmailChangeHandler) ConfirmEmailChange(c *gin.Context) {
+	var req dto.EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.confirmEmailChangeUseCase.Execute(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevertEmailChange godoc
+// @Summary Revert an email change
+// @Description Cancel a pending email change, or restore the previous email within the rollback window, with the token of a link sent to the previous address
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.EmailChangeTokenRequest true "Revert link token"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/revert-email-change [post]
+func (h *EmailChangeHandler) RevertEmailChange(c *gin.Context) {
+	var req dto.EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.revertEmailChangeUseCase.Execute(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventHandler streams user events over Server-Sent Events
+type EventHandler struct {
+	eventBus          *service.EventBus
+	heartbeatInterval time.Duration
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(eventBus *service.EventBus, heartbeatInterval time.Duration) *EventHandler {
+	return &EventHandler{
+		eventBus:          eventBus,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// StreamEvents godoc
+// @Summary Stream events
+// @Description Stream the current user's events as Server-Sent Events. Send the Last-Event-ID header (or last_event_id query parameter) to receive the events missed since then.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received"
+// @Security BearerAuth
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /events [get]
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	// Subscribe before reading the history so nothing published in between is lost
+	events, unsubscribe := h.eventBus.Subscribe(userID)
+	defer unsubscribe()
+
+	missed, err := h.eventBus.Since(c.Request.Context(), userID, lastEventID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The stream outlives the server write timeout. Without it, the connection is closed at the
+	// write timeout and the client reconnects, so every stream would be cut short.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Failed to lift the write deadline of the event stream")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, event := range missed {
+		lastEventID = event.ID
+		if !h.write(c, event) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			// Already sent from the history
+			if lastEventID != "" && !service.EventIDAfter(event.ID, lastEventID) {
+				continue
+			}
+			lastEventID = event.ID
+			if !h.write(c, event) {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// write sends one event, reporting whether the client is still connected
+func (h *EventHandler) write(c *gin.Context, event service.Event) bool {
+	_, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err == nil
+}
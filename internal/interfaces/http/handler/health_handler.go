@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"gin-boilerplate/internal/infrastructure/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	checker   *health.Checker
+	version   string
+	startedAt time.Time
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(checker *health.Checker, version string) *HealthHandler {
+	return &HealthHandler{
+		checker:   checker,
+		version:   version,
+		startedAt: time.Now(),
+	}
+}
+
+// Liveness reports that the process is running. It does not touch dependencies, so an outage
+// of Postgres or Redis doesn't get the process restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    health.StatusUp,
+		"timestamp": time.Now().UTC(),
+		"uptime":    time.Since(h.startedAt).Round(time.Second).String(),
+		"version":   h.version,
+	})
+}
+
+// Readiness reports whether Postgres, Redis and S3 are reachable, with the status and latency
+// of each. It answers 503 while any of them is down so load balancers stop routing traffic here.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationHandler handles the invitations to register (admin only)
+type InvitationHandler struct {
+	invitationUseCase *usecase.InvitationUseCase
+}
+
+// NewInvitationHandler creates a new invitation handler
+func NewInvitationHandler(invitationUseCase *usecase.InvitationUseCase) *InvitationHandler {
+	return &InvitationHandler{
+		invitationUseCase: invitationUseCase,
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Invite a user
+// @Description Email an invitation link to register to an email address without an account. The link is also returned. Inviting the address again disables its earlier link (admin only).
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateInvitationRequest true "Email and role"
+// @Security BearerAuth
+// @Success 201 {object} dto.InvitationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.invitationUseCase.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description List the invitations to register, newest first, with whether they are pending, accepted or expired (admin only)
+// @Tags invitations
+// @Produce json
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.InvitationsListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.invitationUseCase.List(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Delete an invitation, so its link stops working (admin only)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	if err := h.invitationUseCase.Revoke(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Invitation revoked successfully",
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler handles background job administration endpoints
+type JobHandler struct {
+	jobUseCase *usecase.JobUseCase
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobUseCase *usecase.JobUseCase) *JobHandler {
+	return &JobHandler{
+		jobUseCase: jobUseCase,
+	}
+}
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description List background jobs, newest first, optionally only those with a status; status=dead lists the dead-letter queue (admin only)
+// @Tags jobs
+// @Produce json
+// @Param status query string false "Job status" Enums(pending, running, succeeded, dead)
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.JobsListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.jobUseCase.ListJobs(c.Request.Context(), entity.JobStatus(c.Query("status")), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetJob godoc
+// @Summary Get background job
+// @Description Get a background job, with its payload and last error (admin only)
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.JobResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	response, err := h.jobUseCase.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryJob godoc
+// @Summary Retry dead background job
+// @Description Move a dead job back to the queue with a fresh set of attempts (admin only)
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.JobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	response, err := h.jobUseCase.RetryJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
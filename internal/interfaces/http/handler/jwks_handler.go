@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys access tokens are verified with
+type JWKSHandler struct {
+	tokenService service.TokenService
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(tokenService service.TokenService) *JWKSHandler {
+	return &JWKSHandler{tokenService: tokenService}
+}
+
+// JWKS serves the JSON Web Key Set, so other services can verify tokens without the HMAC
+// secret. Verifiers may cache it for a few minutes, and should fetch it again for an unknown kid.
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokenService.JWKS())
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LogLevelHandler handles runtime log level endpoints
+type LogLevelHandler struct{}
+
+// NewLogLevelHandler creates a new log level handler
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+// GetLogLevel godoc
+// @Summary Get log levels
+// @Description Get the level of the logger and the overridden module levels (admin only)
+// @Tags logging
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.LogLevelResponse
+// @Router /admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, logLevelResponse())
+}
+
+// SetLogLevel godoc
+// @Summary Set a log level
+// @Description Change the level of the logger, or override the level of one module such as "gorm" or "auth", until the next restart (admin only)
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Param request body dto.SetLogLevelRequest true "Level and optional module"
+// @Security BearerAuth
+// @Success 200 {object} dto.LogLevelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req dto.SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+	if err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if req.Module == "" {
+		logging.SetLevel(level)
+	} else if err := logging.SetModuleLevel(req.Module, level); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	logging.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+		"level":  level.String(),
+		"module": req.Module,
+	}).Warn("Log level changed")
+
+	c.JSON(http.StatusOK, logLevelResponse())
+}
+
+// ResetModuleLogLevel godoc
+// @Summary Reset a module log level
+// @Description Remove the level override of a module, which then logs at the logger's level (admin only)
+// @Tags logging
+// @Produce json
+// @Param module path string true "Module, e.g. gorm"
+// @Security BearerAuth
+// @Success 200 {object} dto.LogLevelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/log-level/{module} [delete]
+func (h *LogLevelHandler) ResetModuleLogLevel(c *gin.Context) {
+	if err := logging.ClearModuleLevel(c.Param("module")); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, logLevelResponse())
+}
+
+// logLevelResponse describes the current log levels
+func logLevelResponse() dto.LogLevelResponse {
+	modules := map[string]string{}
+	for module, level := range logging.ModuleLevels() {
+		modules[module] = level.String()
+	}
+
+	return dto.LogLevelResponse{
+		Level:   logging.Level().String(),
+		Modules: modules,
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gin-boilerplate/internal/application/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageQuery sets the pagination parameters of a list URL for the page starting at offset
+type pageQuery func(query url.Values, offset, limit int)
+
+// offsetPageQuery paginates with ?offset=&limit=
+func offsetPageQuery(query url.Values, offset, limit int) {
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+}
+
+// numberedPageQuery paginates with ?page=&limit=, pages starting at 1
+func numberedPageQuery(query url.Values, offset, limit int) {
+	query.Set("page", strconv.Itoa(offset/limit+1))
+	query.Set("limit", strconv.Itoa(limit))
+}
+
+// paginate sets the X-Total-Count header and an RFC 5988 Link header with the first, prev, next
+// and last pages of a list, and returns the same links for the response body. The links keep the
+// other query parameters of the request, such as filters.
+func paginate(c *gin.Context, total int64, limit, offset int, setQuery pageQuery) dto.PaginationLinks {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	lastOffset := 0
+	if total > 0 {
+		lastOffset = int((total - 1) / int64(limit) * int64(limit))
+	}
+
+	links := dto.PaginationLinks{
+		First: pageURL(c, 0, limit, setQuery),
+		Last:  pageURL(c, lastOffset, limit, setQuery),
+	}
+	if offset > 0 {
+		links.Prev = pageURL(c, max(offset-limit, 0), limit, setQuery)
+	}
+	if int64(offset+limit) < total {
+		links.Next = pageURL(c, offset+limit, limit, setQuery)
+	}
+
+	header := make([]string, 0, 4)
+	for _, link := range []struct{ rel, url string }{
+		{"first", links.First},
+		{"prev", links.Prev},
+		{"next", links.Next},
+		{"last", links.Last},
+	} {
+		if link.url != "" {
+			header = append(header, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+		}
+	}
+	c.Header("Link", strings.Join(header, ", "))
+
+	return links
+}
+
+// paginateCursor sets an RFC 5988 Link header to the next keyset page, unless it is the last one
+func paginateCursor(c *gin.Context, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	query := c.Request.URL.Query()
+	query.Set("cursor", nextCursor)
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, c.Request.URL.Path+"?"+query.Encode()))
+}
+
+// pageURL returns the request path and query with the pagination parameters of another page
+func pageURL(c *gin.Context, offset, limit int, setQuery pageQuery) string {
+	query := c.Request.URL.Query()
+	setQuery(query, offset, limit)
+	return c.Request.URL.Path + "?" + query.Encode()
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaHandler handles usage quota administration endpoints
+type QuotaHandler struct {
+	quotaUseCase *usecase.QuotaUseCase
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotaUseCase *usecase.QuotaUseCase) *QuotaHandler {
+	return &QuotaHandler{
+		quotaUseCase: quotaUseCase,
+	}
+}
+
+// GetUserQuotas godoc
+// @Summary Get user quotas
+// @Description Get the current usage and limits of every quota metric for a user (admin only)
+// @Tags quotas
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserQuotaResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/quotas/users/{id} [get]
+func (h *QuotaHandler) GetUserQuotas(c *gin.Context) {
+	response, err := h.quotaUseCase.GetUserQuotas(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetQuotaOverride godoc
+// @Summary Override user quota
+// @Description Set a per-user limit for a quota metric, 0 means unlimited (admin only)
+// @Tags quotas
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param metric path string true "Quota metric" Enums(requests_daily, uploads_monthly, download_bytes_monthly)
+// @Param request body dto.SetQuotaOverrideRequest true "Quota override request"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserQuotaResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/quotas/users/{id}/{metric} [put]
+func (h *QuotaHandler) SetQuotaOverride(c *gin.Context) {
+	var req dto.SetQuotaOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.quotaUseCase.SetOverride(c.Request.Context(), c.Param("id"), entity.QuotaMetric(c.Param("metric")), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveQuotaOverride godoc
+// @Summary Remove user quota override
+// @Description Restore the default limit of a quota metric for a user (admin only)
+// @Tags quotas
+// @Produce json
+// @Param id path string true "User ID"
+// @Param metric path string true "Quota metric" Enums(requests_daily, uploads_monthly, download_bytes_monthly)
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/quotas/users/{id}/{metric} [delete]
+func (h *QuotaHandler) RemoveQuotaOverride(c *gin.Context) {
+	err := h.quotaUseCase.RemoveOverride(c.Request.Context(), c.Param("id"), entity.QuotaMetric(c.Param("metric")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Quota override removed successfully",
+	})
+}
+
+// ResetQuotaUsage godoc
+// @Summary Reset user quota usage
+// @Description Clear a user's usage of a quota metric for the current period (admin only)
+// @Tags quotas
+// @Produce json
+// @Param id path string true "User ID"
+// @Param metric path string true "Quota metric" Enums(requests_daily, uploads_monthly, download_bytes_monthly)
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/quotas/users/{id}/{metric}/reset [post]
+func (h *QuotaHandler) ResetQuotaUsage(c *gin.Context) {
+	err := h.quotaUseCase.ResetUsage(c.Request.Context(), c.Param("id"), entity.QuotaMetric(c.Param("metric")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Quota usage reset successfully",
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/interfaces/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitHandler handles rate limiter administration endpoints
+type RateLimitHandler struct {
+	rateLimitUseCase    *usecase.RateLimitUseCase
+	rateLimitMiddleware *middleware.RateLimitMiddleware
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(rateLimitUseCase *usecase.RateLimitUseCase, rateLimitMiddleware *middleware.RateLimitMiddleware) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitUseCase:    rateLimitUseCase,
+		rateLimitMiddleware: rateLimitMiddleware,
+	}
+}
+
+// ListRateLimits godoc
+// @Summary List rate-limit counters
+// @Description List current rate-limit counters, optionally filtered by key prefix such as "ip:203.0.113.7" or "login:" (admin only)
+// @Tags rate-limits
+// @Produce json
+// @Param key query string false "Key prefix"
+// @Security BearerAuth
+// @Success 200 {object} dto.RateLimitListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rate-limits [get]
+func (h *RateLimitHandler) ListRateLimits(c *gin.Context) {
+	response, err := h.rateLimitUseCase.ListCounters(c.Request.Context(), c.Query("key"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetRateLimit godoc
+// @Summary Reset a rate-limit counter
+// @Description Delete a rate-limit counter to unblock a client immediately (admin only)
+// @Tags rate-limits
+// @Produce json
+// @Param key path string true "Counter key"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/rate-limits/{key} [delete]
+func (h *RateLimitHandler) ResetRateLimit(c *gin.Context) {
+	err := h.rateLimitUseCase.ResetCounter(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Rate limit counter reset successfully",
+	})
+}
+
+// GetDegradations godoc
+// @Summary Get rate limiter degradations
+// @Description Count how often each route class fell back to its failure mode because Redis was unavailable (admin only)
+// @Tags rate-limits
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} middleware.RateLimitDegradation
+// @Router /admin/rate-limits/degradations [get]
+func (h *RateLimitHandler) GetDegradations(c *gin.Context) {
+	c.JSON(http.StatusOK, h.rateLimitMiddleware.Degradations())
+}
+
+// SetUserTier godoc
+// @Summary Set a user's rate limit tier
+// @Description Change the rate limit tier of a user: free, pro or internal. It applies to the user's access tokens issued afterwards, e.g. on the next refresh, and to its API keys at once (needs rate_limits:manage).
+// @Tags rate-limits
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SetRateLimitTierRequest true "Rate limit tier"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/{id}/rate-limit-tier [put]
+func (h *RateLimitHandler) SetUserTier(c *gin.Context) {
+	var req dto.SetRateLimitTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.rateLimitUseCase.SetUserTier(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
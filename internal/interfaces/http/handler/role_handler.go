@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler handles the custom roles and the role of users (admin only)
+type RoleHandler struct {
+	roleUseCase *usecase.RoleUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleUseCase *usecase.RoleUseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUseCase: roleUseCase,
+	}
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List every permission roles can have (needs roles:manage)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PermissionsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, h.roleUseCase.Permissions())
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the built-in USER, MODERATOR and ADMIN roles, then the custom roles by name, with their permissions (needs roles:manage)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RolesListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	response, err := h.roleUseCase.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRole godoc
+// @Summary Get a role
+// @Description Get a built-in or custom role with its permissions (needs roles:manage)
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Security BearerAuth
+// @Success 200 {object} dto.RoleResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/roles/{name} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	response, err := h.roleUseCase.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a custom role allowing a set of permissions. Names are 2 to 50 upper-case letters, digits or underscores. Only permissions of the caller's own role can be given (needs roles:manage).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRoleRequest true "Name, description and permissions"
+// @Security BearerAuth
+// @Success 201 {object} dto.RoleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.roleUseCase.Create(c.Request.Context(), entity.Role(c.GetString("user_role")), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Replace the description and permissions of a custom role. Users with the role get the new permissions on their next request. Only permissions of the caller's own role can be given (needs roles:manage).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param request body dto.UpdateRoleRequest true "Description and permissions"
+// @Security BearerAuth
+// @Success 200 {object} dto.RoleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.roleUseCase.Update(c.Request.Context(), entity.Role(c.GetString("user_role")), c.Param("name"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a custom role. Roles assigned to users can't be deleted (needs roles:manage).
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleUseCase.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Role deleted successfully",
+	})
+}
+
+// AssignRole godoc
+// @Summary Assign a role
+// @Description Give a user a built-in or custom role. The user's sessions are revoked, so the role applies once it logs in again. Neither role can allow more than the caller's, and only admins give or take away ADMIN. The last admin can't be given another role (needs users:assign_role).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role name"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/{id}/role [put]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	adminID := c.GetString("user_id")
+	if adminID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.roleUseCase.Assign(c.Request.Context(), entity.Role(c.GetString("user_role")), c.Param("id"), adminID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
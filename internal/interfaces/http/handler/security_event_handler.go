@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityEventHandler handles security event feed endpoints
+type SecurityEventHandler struct {
+	securityEventUseCase *usecase.SecurityEventUseCase
+}
+
+// NewSecurityEventHandler creates a new security event handler
+func NewSecurityEventHandler(securityEventUseCase *usecase.SecurityEventUseCase) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		securityEventUseCase: securityEventUseCase,
+	}
+}
+
+// ListEvents godoc
+// @Summary List security events
+// @Description List the security events of every account, newest first, optionally only those of a user or type (admin only)
+// @Tags security-events
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param type query string false "Event type" Enums(new_device_login, new_country_login, password_changed, email_changed, provider_linked, provider_unlinked, token_reuse_detected, account_locked, admin_role_granted, phone_changed, two_factor_enabled, two_factor_disabled, recovery_code_used, recovery_codes_regenerated)
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.SecurityEventsListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/security-events [get]
+func (h *SecurityEventHandler) ListEvents(c *gin.Context) {
+	filter := repository.SecurityEventFilter{
+		UserID: c.Query("user_id"),
+		Type:   entity.SecurityEventType(c.Query("type")),
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.securityEventUseCase.ListEvents(c.Request.Context(), filter, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMyEvents godoc
+// @Summary List my security events
+// @Description List the security events of the authenticated user's account, newest first
+// @Tags security-events
+// @Produce json
+// @Param type query string false "Event type" Enums(new_device_login, new_country_login, password_changed, email_changed, provider_linked, provider_unlinked, token_reuse_detected, account_locked, admin_role_granted, phone_changed, two_factor_enabled, two_factor_disabled, recovery_code_used, recovery_codes_regenerated)
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.SecurityEventsListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/security-events [get]
+func (h *SecurityEventHandler) ListMyEvents(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	eventType := entity.SecurityEventType(c.Query("type"))
+	response, err := h.securityEventUseCase.ListUserEvents(c.Request.Context(), userID, eventType, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMySuspiciousLogins godoc
+// @Summary List my suspicious logins
+// @Description List the logins to the authenticated user's account from new devices and countries, newest first, with whether their sessions are still signed in. Sessions that weren't the user's are revoked with DELETE /users/me/sessions/{id}.
+// @Tags security-events
+// @Produce json
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.SuspiciousLoginsListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/suspicious-logins [get]
+func (h *SecurityEventHandler) ListMySuspiciousLogins(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.securityEventUseCase.ListSuspiciousLogins(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
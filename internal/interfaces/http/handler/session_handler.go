@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler handles the endpoints of the devices users are signed in on
+type SessionHandler struct {
+	listSessionsUseCase  *usecase.ListSessionsUseCase
+	revokeSessionUseCase *usecase.RevokeSessionUseCase
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(
+	listSessionsUseCase *usecase.ListSessionsUseCase,
+	revokeSessionUseCase *usecase.RevokeSessionUseCase,
+) *SessionHandler {
+	return &SessionHandler{
+		listSessionsUseCase:  listSessionsUseCase,
+		revokeSessionUseCase: revokeSessionUseCase,
+	}
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List the devices the authenticated user is signed in on
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.SessionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.listSessionsUseCase.Execute(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Sign the authenticated user out of one device. Its access token stays valid until it expires.
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	err := h.revokeSessionUseCase.Execute(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Session revoked successfully",
+	})
+}
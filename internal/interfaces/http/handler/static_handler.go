@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaticHandler serves a built single-page frontend. Paths without a matching file get
+// index.html so the client-side router can handle them.
+type StaticHandler struct {
+	dir              string
+	assetsPath       string
+	assetsMaxAge     time.Duration
+	excludedPrefixes []string
+}
+
+// NewStaticHandler creates a new static file handler for dir. Files under assetsPath are expected
+// to have content-hashed names and are cached for assetsMaxAge; everything else is revalidated.
+// Requests under excludedPrefixes are never served from dir.
+func NewStaticHandler(dir, assetsPath string, assetsMaxAge time.Duration, excludedPrefixes ...string) *StaticHandler {
+	return &StaticHandler{
+		dir:              dir,
+		assetsPath:       assetsPath,
+		assetsMaxAge:     assetsMaxAge,
+		excludedPrefixes: excludedPrefixes,
+	}
+}
+
+// Serve serves the requested file, falling back to index.html. It is meant as the NoRoute
+// handler; requests it doesn't serve keep the default 404 response.
+func (h *StaticHandler) Serve(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return
+	}
+
+	urlPath := path.Clean("/" + c.Request.URL.Path)
+	for _, prefix := range h.excludedPrefixes {
+		if urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/") {
+			return
+		}
+	}
+
+	if urlPath != "/" && h.serveFile(c, urlPath) {
+		return
+	}
+
+	// A missing asset is a broken build or a stale page, not a client-side route
+	if h.isAsset(urlPath) {
+		return
+	}
+
+	h.serveFile(c, "/index.html")
+}
+
+// serveFile writes the file at urlPath with its cache headers, reporting whether it exists
+func (h *StaticHandler) serveFile(c *gin.Context, urlPath string) bool {
+	file, err := os.Open(filepath.Join(h.dir, filepath.FromSlash(urlPath)))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	if h.isAsset(urlPath) {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(h.assetsMaxAge.Seconds())))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+	return true
+}
+
+// isAsset reports whether urlPath is under the content-hashed assets path
+func (h *StaticHandler) isAsset(urlPath string) bool {
+	return h.assetsPath != "" && strings.HasPrefix(urlPath, h.assetsPath)
+}
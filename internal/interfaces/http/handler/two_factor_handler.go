@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler handles the endpoints of the phone number and the second factor of users
+type TwoFactorHandler struct {
+	twoFactorUseCase *usecase.TwoFactorUseCase
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorUseCase *usecase.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUseCase: twoFactorUseCase,
+	}
+}
+
+// RequestPhoneVerification godoc
+// @Summary Add a phone number
+// @Description Text a verification code to a phone number in E.164 format. The number becomes the user's once the code is confirmed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneVerificationRequest true "Phone number"
+// @Security BearerAuth
+// @Success 202 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /users/me/phone [post]
+func (h *TwoFactorHandler) RequestPhoneVerification(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.PhoneVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if err := h.twoFactorUseCase.RequestPhoneVerification(c.Request.Context(), userID, req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: "A verification code was sent to the phone number",
+	})
+}
+
+// VerifyPhone godoc
+// @Summary Confirm a phone number
+// @Description Set the phone number the code was texted to as the user's verified number
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyPhoneRequest true "Texted code"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /users/me/phone/verify [post]
+func (h *TwoFactorHandler) VerifyPhone(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.twoFactorUseCase.VerifyPhone(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// EnableTwoFactor godoc
+// @Summary Turn on two-factor authentication
+// @Description Require a code texted to the verified phone number after the password on every password login. Turning it on returns 10 single-use recovery codes, shown this once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.EnableTwoFactorRequest true "Method and current password"
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /users/me/two-factor [put]
+func (h *TwoFactorHandler) EnableTwoFactor(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.EnableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.twoFactorUseCase.Enable(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableTwoFactor godoc
+// @Summary Turn off two-factor authentication
+// @Description Sign in with the password only. The recovery codes are deleted.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.DisableTwoFactorRequest true "Current password"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/two-factor [delete]
+func (h *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.twoFactorUseCase.Disable(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRecoveryCodes godoc
+// @Summary Count recovery codes
+// @Description Get how many of the user's recovery codes are left
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/two-factor/recovery-codes [get]
+func (h *TwoFactorHandler) GetRecoveryCodes(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.twoFactorUseCase.RecoveryCodes(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the user's recovery codes with 10 new ones, shown this once. The previous codes stop working.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RegenerateRecoveryCodesRequest true "Current password"
+// @Security BearerAuth
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/two-factor/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.twoFactorUseCase.RegenerateRecoveryCodes(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
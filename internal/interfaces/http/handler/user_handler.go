@@ -1,41 +1,64 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"gin-boilerplate/internal/application/dto"
 	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/infrastructure/spreadsheet"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserHandler handles user-related endpoints
 type UserHandler struct {
-	getProfileUseCase  *usecase.GetUserProfileUseCase
-	updateProfileUseCase *usecase.UpdateUserProfileUseCase
-	listUsersUseCase   *usecase.ListUsersUseCase
-	deleteUserUseCase  *usecase.DeleteUserUseCase
-	promoteUserUseCase *usecase.PromoteUserUseCase
-	demoteUserUseCase  *usecase.DemoteUserUseCase
+	getProfileUseCase     *usecase.GetUserProfileUseCase
+	updateProfileUseCase  *usecase.UpdateUserProfileUseCase
+	changePasswordUseCase *usecase.ChangePasswordUseCase
+	listUsersUseCase      *usecase.ListUsersUseCase
+	createUserUseCase     *usecase.CreateUserUseCase
+	exportUsersUseCase    *usecase.ExportUsersUseCase
+	deleteUserUseCase     *usecase.DeleteUserUseCase
+	restoreUserUseCase    *usecase.RestoreUserUseCase
+	unlockUserUseCase     *usecase.UnlockUserUseCase
+	deleteAccountUseCase  *usecase.AccountDeletionUseCase
+	dataExportUseCase     *usecase.DataExportUseCase
+	tokenCookies          TokenCookieConfig
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(
 	getProfileUseCase *usecase.GetUserProfileUseCase,
 	updateProfileUseCase *usecase.UpdateUserProfileUseCase,
+	changePasswordUseCase *usecase.ChangePasswordUseCase,
 	listUsersUseCase *usecase.ListUsersUseCase,
+	createUserUseCase *usecase.CreateUserUseCase,
+	exportUsersUseCase *usecase.ExportUsersUseCase,
 	deleteUserUseCase *usecase.DeleteUserUseCase,
-	promoteUserUseCase *usecase.PromoteUserUseCase,
-	demoteUserUseCase *usecase.DemoteUserUseCase,
+	restoreUserUseCase *usecase.RestoreUserUseCase,
+	unlockUserUseCase *usecase.UnlockUserUseCase,
+	deleteAccountUseCase *usecase.AccountDeletionUseCase,
+	dataExportUseCase *usecase.DataExportUseCase,
+	tokenCookies TokenCookieConfig,
 ) *UserHandler {
 	return &UserHandler{
-		getProfileUseCase:    getProfileUseCase,
-		updateProfileUseCase: updateProfileUseCase,
-		listUsersUseCase:     listUsersUseCase,
-		deleteUserUseCase:    deleteUserUseCase,
-		promoteUserUseCase:   promoteUserUseCase,
-		demoteUserUseCase:    demoteUserUseCase,
+		getProfileUseCase:     getProfileUseCase,
+		updateProfileUseCase:  updateProfileUseCase,
+		changePasswordUseCase: changePasswordUseCase,
+		listUsersUseCase:      listUsersUseCase,
+		createUserUseCase:     createUserUseCase,
+		exportUsersUseCase:    exportUsersUseCase,
+		deleteUserUseCase:     deleteUserUseCase,
+		restoreUserUseCase:    restoreUserUseCase,
+		unlockUserUseCase:     unlockUserUseCase,
+		deleteAccountUseCase:  deleteAccountUseCase,
+		dataExportUseCase:     dataExportUseCase,
+		tokenCookies:          tokenCookies,
 	}
 }
 
@@ -43,23 +66,13 @@ func NewUserHandler(
 func (h *UserHandler) GetMe(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "UNAUTHORIZED",
-				Message: "User not authenticated",
-			},
-		})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	response, err := h.getProfileUseCase.Execute(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "GET_PROFILE_FAILED",
-				Message: "Failed to get user profile",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
@@ -70,42 +83,143 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 func (h *UserHandler) UpdateMe(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "UNAUTHORIZED",
-				Message: "User not authenticated",
-			},
-		})
+		c.Error(domain.ErrUnauthorized)
 		return
 	}
 
 	var req dto.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_REQUEST",
-				Message: err.Error(),
-			},
-		})
+		c.Error(domain.NewValidationError(err))
 		return
 	}
 
 	response, err := h.updateProfileUseCase.Execute(c.Request.Context(), userID.(string), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "UPDATE_PROFILE_FAILED",
-				Message: "Failed to update profile",
-			},
-		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchMe handles partially updating current user profile, changing only the fields sent or
+// named by the update mask
+func (h *UserHandler) PatchMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.updateProfileUseCase.Patch(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// ListUsers handles listing all users (admin only)
+// ChangePassword handles changing the current user's password. The other devices are logged out,
+// and this one gets new tokens.
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.changePasswordUseCase.Execute(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.tokenCookies.respond(c, http.StatusOK, response)
+}
+
+// DeleteMe handles deleting the current user's account. Users with two-factor authentication
+// are texted a code first, and answered 202 until they send it.
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	deleted, err := h.deleteAccountUseCase.Execute(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusAccepted, dto.SuccessResponse{
+			Message: "A verification code was sent to your phone, send it as code to delete your account",
+		})
+		return
+	}
+
+	h.tokenCookies.clear(c)
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Account deleted successfully",
+	})
+}
+
+// ExportMe handles the current user asking for an export of its data. The export is assembled in
+// the background, and its download link emailed.
+func (h *UserHandler) ExportMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	if err := h.dataExportUseCase.Request(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: "Your data is being exported, the download link will be emailed to you",
+	})
+}
+
+// ListUsers handles listing all users (admin only), optionally filtered and sorted. A cursor
+// parameter, empty for the first page, switches to keyset pagination.
 func (h *UserHandler) ListUsers(c *gin.Context) {
+	var filter dto.UserFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.listUsersAfter(c, filter, cursor)
+		return
+	}
+
 	// Parse pagination parameters
 	req := dto.PaginationRequest{}
 	if limitStr := c.Query("limit"); limitStr != "" {
@@ -119,88 +233,139 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		}
 	}
 
-	response, err := h.listUsersUseCase.Execute(c.Request.Context(), req)
+	response, err := h.listUsersUseCase.Execute(c.Request.Context(), filter, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "LIST_USERS_FAILED",
-				Message: "Failed to list users",
-			},
-		})
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
+
+// listUsersAfter lists a keyset page of users
+func (h *UserHandler) listUsersAfter(c *gin.Context, filter dto.UserFilterRequest, cursor string) {
+	req := dto.CursorPaginationRequest{Cursor: cursor}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+
+	response, err := h.listUsersUseCase.ExecuteAfter(c.Request.Context(), filter, req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	paginateCursor(c, response.NextCursor)
 	c.JSON(http.StatusOK, response)
 }
 
+// ExportUsers handles exporting the users matching the filters of the user list as CSV or XLSX
+// (admin only). The file is streamed as the users are read, so exports of any size use little
+// memory.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var filter dto.UserFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+	var req dto.UserExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+	format := spreadsheet.FormatCSV
+	if req.Format != "" {
+		format = spreadsheet.Format(req.Format)
+	}
+
+	// The response starts with the header row, once the request was validated
+	var writer spreadsheet.Writer
+	writeRow := func(values []string) error {
+		if writer == nil {
+			filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102"), format)
+			c.Header("Content-Type", format.ContentType())
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+			c.Status(http.StatusOK)
+
+			var err error
+			if writer, err = spreadsheet.NewWriter(format, c.Writer); err != nil {
+				return err
+			}
+		}
+		return writer.WriteRow(values)
+	}
+
+	err := h.exportUsersUseCase.Execute(c.Request.Context(), filter, req, writeRow)
+	if writer != nil {
+		// A failed export is cut short, which makes the file invalid
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// CreateUser handles creating a user with a chosen role (admin only), bypassing public
+// registration. A generated temporary password is returned once.
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.createUserUseCase.Execute(c.Request.Context(), entity.Role(c.GetString("user_role")), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 // GetUser handles getting user by ID (admin only)
 func (h *UserHandler) GetUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_USER_ID",
-				Message: "User ID is required",
-			},
-		})
+		c.Error(domain.ErrValidation.WithMessage("User ID is required"))
 		return
 	}
 
 	response, err := h.getProfileUseCase.Execute(c.Request.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_NOT_FOUND",
-					Message: "User not found",
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "GET_USER_FAILED",
-				Message: "Failed to get user",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// DeleteUser handles deleting a user (admin only)
+// DeleteUser handles deleting a user (admin only). The user is moved to the trash, unless
+// ?hard=true deletes it permanently.
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_USER_ID",
-				Message: "User ID is required",
-			},
-		})
+		c.Error(domain.ErrValidation.WithMessage("User ID is required"))
 		return
 	}
 
-	err := h.deleteUserUseCase.Execute(c.Request.Context(), userID)
-	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_NOT_FOUND",
-					Message: "User not found",
-				},
-			})
+	permanent := false
+	if hard := c.Query("hard"); hard != "" {
+		var err error
+		if permanent, err = strconv.ParseBool(hard); err != nil {
+			c.Error(domain.ErrValidation.WithMessage("The hard parameter must be true or false"))
 			return
 		}
+	}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "DELETE_USER_FAILED",
-				Message: "Failed to delete user",
-			},
-		})
+	err := h.deleteUserUseCase.Execute(c.Request.Context(), userID, permanent)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -209,96 +374,38 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	})
 }
 
-// PromoteUser handles promoting a user to admin (admin only)
-func (h *UserHandler) PromoteUser(c *gin.Context) {
+// RestoreUser handles restoring a user from the trash (admin only)
+func (h *UserHandler) RestoreUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_USER_ID",
-				Message: "User ID is required",
-			},
-		})
+		c.Error(domain.ErrValidation.WithMessage("User ID is required"))
 		return
 	}
 
-	response, err := h.promoteUserUseCase.Execute(c.Request.Context(), userID)
+	response, err := h.restoreUserUseCase.Execute(c.Request.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_NOT_FOUND",
-					Message: "User not found",
-				},
-			})
-			return
-		}
-
-		if err.Error() == "user is already an admin" {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_ALREADY_ADMIN",
-					Message: "User is already an admin",
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "PROMOTE_USER_FAILED",
-				Message: "Failed to promote user",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// DemoteUser handles demoting an admin to user (admin only)
-func (h *UserHandler) DemoteUser(c *gin.Context) {
+// UnlockUser handles unlocking a user locked out by failed logins (admin only)
+func (h *UserHandler) UnlockUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "INVALID_USER_ID",
-				Message: "User ID is required",
-			},
-		})
+		c.Error(domain.ErrValidation.WithMessage("User ID is required"))
 		return
 	}
 
-	response, err := h.demoteUserUseCase.Execute(c.Request.Context(), userID)
+	err := h.unlockUserUseCase.Execute(c.Request.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_NOT_FOUND",
-					Message: "User not found",
-				},
-			})
-			return
-		}
-
-		if err.Error() == "user is not an admin" {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "USER_NOT_ADMIN",
-					Message: "User is not an admin",
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: dto.ErrorDetail{
-				Code:    "DEMOTE_USER_FAILED",
-				Message: "Failed to demote user",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "User unlocked successfully",
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserMetadataHandler handles the metadata fields users can set (admin only)
+type UserMetadataHandler struct {
+	userMetadataUseCase *usecase.UserMetadataUseCase
+}
+
+// NewUserMetadataHandler creates a new user metadata handler
+func NewUserMetadataHandler(userMetadataUseCase *usecase.UserMetadataUseCase) *UserMetadataHandler {
+	return &UserMetadataHandler{
+		userMetadataUseCase: userMetadataUseCase,
+	}
+}
+
+// ListMetadataFields godoc
+// @Summary List user metadata fields
+// @Description List the custom profile fields users can set in their metadata, by key (needs user_metadata:manage)
+// @Tags user-metadata
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MetadataFieldsListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/user-metadata-fields [get]
+func (h *UserMetadataHandler) ListMetadataFields(c *gin.Context) {
+	response, err := h.userMetadataUseCase.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateMetadataField godoc
+// @Summary Create a user metadata field
+// @Description Define a custom profile field users can set in their metadata. Keys are 1 to 50 lower-case letters, digits or underscores (needs user_metadata:manage).
+// @Tags user-metadata
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateMetadataFieldRequest true "Key, type and description"
+// @Security BearerAuth
+// @Success 201 {object} dto.MetadataFieldResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/user-metadata-fields [post]
+func (h *UserMetadataHandler) CreateMetadataField(c *gin.Context) {
+	var req dto.CreateMetadataFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.userMetadataUseCase.Create(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateMetadataField godoc
+// @Summary Update a user metadata field
+// @Description Replace the type and description of a metadata field. Values users already have are kept (needs user_metadata:manage).
+// @Tags user-metadata
+// @Accept json
+// @Produce json
+// @Param key path string true "Field key"
+// @Param request body dto.UpdateMetadataFieldRequest true "Type and description"
+// @Security BearerAuth
+// @Success 200 {object} dto.MetadataFieldResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/user-metadata-fields/{key} [put]
+func (h *UserMetadataHandler) UpdateMetadataField(c *gin.Context) {
+	var req dto.UpdateMetadataFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.userMetadataUseCase.Update(c.Request.Context(), c.Param("key"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteMetadataField godoc
+// @Summary Delete a user metadata field
+// @Description Delete a metadata field, so users can no longer set it. Values users have are kept until they remove them (needs user_metadata:manage).
+// @Tags user-metadata
+// @Produce json
+// @Param key path string true "Field key"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/user-metadata-fields/{key} [delete]
+func (h *UserMetadataHandler) DeleteMetadataField(c *gin.Context) {
+	if err := h.userMetadataUseCase.Delete(c.Request.Context(), c.Param("key")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Metadata field deleted successfully",
+	})
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/oauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserProviderHandler handles the endpoints of the ways users sign in
+type UserProviderHandler struct {
+	userProviderUseCase *usecase.UserProviderUseCase
+	oauthProviders      *oauth.Registry
+	oauthStates         *service.OAuthStateService
+	oauthConfig         OAuthConfig
+}
+
+// NewUserProviderHandler creates a new user provider handler
+func NewUserProviderHandler(
+	userProviderUseCase *usecase.UserProviderUseCase,
+	oauthProviders *oauth.Registry,
+	oauthStates *service.OAuthStateService,
+	oauthConfig OAuthConfig,
+) *UserProviderHandler {
+	return &UserProviderHandler{
+		userProviderUseCase: userProviderUseCase,
+		oauthProviders:      oauthProviders,
+		oauthStates:         oauthStates,
+		oauthConfig:         oauthConfig,
+	}
+}
+
+// ListProviders godoc
+// @Summary List sign-in methods
+// @Description List the password and the OAuth provider accounts the authenticated user can sign in with
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.LinkedProviderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/providers [get]
+func (h *UserProviderHandler) ListProviders(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.userProviderUseCase.List(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// LinkProvider godoc
+// @Summary Add a sign-in method
+// @Description Add a password with provider "local", which returns the sign-in methods. For an OAuth provider, return the URL of its consent page and set the OAuth state cookie; the provider's callback then links the account instead of signing in.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param provider path string true "local or the name of an OAuth provider, e.g. google"
+// @Param request body dto.LinkProviderRequest false "Password, for local"
+// @Security BearerAuth
+// @Success 200 {object} dto.LinkProviderResponse
+// @Success 201 {array} dto.LinkedProviderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/providers/{provider} [post]
+func (h *UserProviderHandler) LinkProvider(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	providerName := c.Param("provider")
+	if providerName == dto.LocalProvider {
+		h.addPassword(c, userID)
+		return
+	}
+
+	provider, ok := h.oauthProviders.Get(providerName)
+	if !ok {
+		c.Error(domain.ErrOAuthProviderNotFound)
+		return
+	}
+
+	// The provider's callback links the account instead of signing in
+	authURL, err := beginOAuth(c, h.oauthStates, h.oauthConfig, provider, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LinkProviderResponse{
+		AuthorizationURL: authURL,
+	})
+}
+
+// addPassword adds a password to the user
+func (h *UserProviderHandler) addPassword(c *gin.Context, userID string) {
+	var req dto.LinkProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.userProviderUseCase.AddPassword(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UnlinkProvider godoc
+// @Summary Remove a sign-in method
+// @Description Remove the password with provider "local", or unlink the account at an OAuth provider. The last sign-in method can't be removed.
+// @Tags users
+// @Produce json
+// @Param provider path string true "local or the name of an OAuth provider, e.g. google"
+// @Security BearerAuth
+// @Success 200 {array} dto.LinkedProviderResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/providers/{provider} [delete]
+func (h *UserProviderHandler) UnlinkProvider(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.userProviderUseCase.Unlink(c.Request.Context(), userID, c.Param("provider"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
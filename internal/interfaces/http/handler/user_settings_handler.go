@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserSettingsHandler handles the settings endpoints of the current user
+type UserSettingsHandler struct {
+	userSettingsUseCase *usecase.UserSettingsUseCase
+}
+
+// NewUserSettingsHandler creates a new user settings handler
+func NewUserSettingsHandler(userSettingsUseCase *usecase.UserSettingsUseCase) *UserSettingsHandler {
+	return &UserSettingsHandler{
+		userSettingsUseCase: userSettingsUseCase,
+	}
+}
+
+// GetSettings godoc
+// @Summary Get my settings
+// @Description Get the authenticated user's locale, time zone, theme and email notifications, the defaults until it changes them
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UserSettingsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/settings [get]
+func (h *UserSettingsHandler) GetSettings(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.userSettingsUseCase.Get(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateSettings godoc
+// @Summary Update my settings
+// @Description Change the authenticated user's settings, keeping those left out. The profile is left alone, apart from the locale access tokens carry, which applies from the next login or token refresh.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateUserSettingsRequest true "Settings to change"
+// @Security BearerAuth
+// @Success 200 {object} dto.UserSettingsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/settings [put]
+func (h *UserSettingsHandler) UpdateSettings(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.UpdateUserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.userSettingsUseCase.Update(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from notification emails
+// @Description Turn off the notification category of the token of an unsubscribe link, without signing in. The page the link opens posts the token back; the category can be turned on again with the settings.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param request body dto.UnsubscribeRequest true "Token of the unsubscribe link"
+// @Success 200 {object} dto.UnsubscribeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /notifications/unsubscribe [post]
+func (h *UserSettingsHandler) Unsubscribe(c *gin.Context) {
+	var req dto.UnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.userSettingsUseCase.Unsubscribe(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
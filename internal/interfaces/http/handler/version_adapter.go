@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONAdapter reshapes the JSON body a shared handler produced into another API version's DTO
+type JSONAdapter func(c *gin.Context, body json.RawMessage) (interface{}, error)
+
+// AdaptJSON lets a newer API version reuse an existing handler when only its response DTO
+// changed. The handler's successful JSON response is captured and passed through adapt;
+// errors and non-JSON responses are sent unchanged.
+func AdaptJSON(h gin.HandlerFunc, adapt JSONAdapter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		original := c.Writer
+		capture := &capturedResponseWriter{ResponseWriter: original}
+		c.Writer = capture
+
+		h(c)
+
+		c.Writer = original
+		if capture.status == 0 && capture.body.Len() == 0 {
+			return
+		}
+
+		status := capture.Status()
+		mediaType, _, _ := mime.ParseMediaType(original.Header().Get("Content-Type"))
+		if status < http.StatusOK || status >= http.StatusMultipleChoices || mediaType != "application/json" {
+			original.WriteHeader(status)
+			if _, err := original.Write(capture.body.Bytes()); err != nil {
+				// The client has gone away; nothing left to send
+			}
+			return
+		}
+
+		adapted, err := adapt(c, json.RawMessage(capture.body.Bytes()))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(status, adapted)
+	}
+}
+
+// capturedResponseWriter holds a handler's response so it can be rewritten before sending
+type capturedResponseWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *capturedResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *capturedResponseWriter) WriteHeaderNow() {}
+
+func (w *capturedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *capturedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *capturedResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *capturedResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *capturedResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles webhook management endpoints
+type WebhookHandler struct {
+	webhookUseCase *usecase.WebhookUseCase
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUseCase *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Register an endpoint that is sent the authenticated user's events of the subscribed types. The secret signing the deliveries is only returned once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "Webhook request"
+// @Security BearerAuth
+// @Success 201 {object} dto.CreateWebhookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.webhookUseCase.CreateWebhook(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List the authenticated user's webhooks
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.WebhookResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.webhookUseCase.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get one of the authenticated user's webhooks
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.webhookUseCase.GetWebhook(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Replace the URL, description, event types and state of one of the authenticated user's webhooks; the secret is kept
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body dto.UpdateWebhookRequest true "Webhook request"
+// @Security BearerAuth
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewValidationError(err))
+		return
+	}
+
+	response, err := h.webhookUseCase.UpdateWebhook(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete one of the authenticated user's webhooks with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	if err := h.webhookUseCase.DeleteWebhook(c.Request.Context(), userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// PingWebhook godoc
+// @Summary Ping a webhook
+// @Description Send a signed ping event to one of the authenticated user's webhooks right away and return the recorded attempt. The endpoint failing doesn't fail the request.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Security BearerAuth
+// @Success 200 {object} dto.WebhookDeliveryResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks/{id}/ping [post]
+func (h *WebhookHandler) PingWebhook(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	response, err := h.webhookUseCase.PingWebhook(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the delivery attempts of one of the authenticated user's webhooks, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Page size" default(10)
+// @Param offset query int false "Page offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} dto.WebhookDeliveriesListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/me/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	req := dto.PaginationRequest{}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		req.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		req.Offset = offset
+	}
+
+	response, err := h.webhookUseCase.ListDeliveries(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	links := paginate(c, response.Total, response.Limit, response.Offset, offsetPageQuery)
+	response.Links = &links
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,108 +1,254 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
-	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/application/usecase"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
-// AuthMiddleware handles JWT authentication
+// APIKeyHeader is the header machine clients use to send their API key
+const APIKeyHeader = "X-API-Key"
+
+// Cookies and header of browser clients holding their tokens in cookies
+const (
+	// AccessTokenCookie holds the access token, httpOnly so scripts can't read it
+	AccessTokenCookie = "access_token"
+	// CSRFCookie holds a token scripts of the frontend can read, and send back in CSRFHeader
+	// with every request changing something
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// AuthMiddleware handles JWT and API key authentication
 type AuthMiddleware struct {
-	tokenService service.TokenService
+	tokenService       service.TokenService
+	authenticateAPIKey *usecase.AuthenticateAPIKeyUseCase
+	// denylist rejects revoked access tokens; nil accepts every valid token
+	denylist *service.TokenDenylistService
+	// cookies accepts the access token of AccessTokenCookie when there is no Authorization
+	// header
+	cookies bool
 }
 
-// NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(tokenService service.TokenService) *AuthMiddleware {
+// NewAuthMiddleware creates a new auth middleware. With cookies, requests without an
+// Authorization header authenticate with the access token cookie, and need the CSRF token
+// unless their method is safe.
+func NewAuthMiddleware(tokenService service.TokenService, authenticateAPIKey *usecase.AuthenticateAPIKeyUseCase, denylist *service.TokenDenylistService, cookies bool) *AuthMiddleware {
 	return &AuthMiddleware{
-		tokenService: tokenService,
+		tokenService:       tokenService,
+		authenticateAPIKey: authenticateAPIKey,
+		denylist:           denylist,
+		cookies:            cookies,
 	}
 }
 
-// RequireAuth middleware that requires authentication
+// RequireAuth middleware that requires authentication. A request OptionalAuth already
+// authenticated isn't authenticated again.
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetString("user_id") != "" {
+			c.Next()
+			return
+		}
+
+		// Machine clients authenticate with an API key instead of a bearer token
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			if !m.setAPIKeyContext(c, apiKey) {
+				logging.ModuleFromContext(c.Request.Context(), logging.ModuleAuth).Debug("API key rejected")
+				abortWithError(c, domain.ErrInvalidAPIKey)
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		// Browsers send the access token cookie instead, which any site can make them send
+		if cookie, ok := m.cookieToken(c); ok {
+			if !ValidCSRF(c) {
+				abortWithError(c, domain.ErrInvalidCSRFToken)
+				return
+			}
+			m.authenticate(c, cookie)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "MISSING_TOKEN",
-					Message: "Authorization header is required",
-				},
-			})
-			c.Abort()
+			abortWithError(c, domain.ErrMissingToken)
 			return
 		}
 
 		// Check Bearer token format
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INVALID_TOKEN_FORMAT",
-					Message: "Authorization header must be in format: Bearer <token>",
-				},
-			})
-			c.Abort()
+			abortWithError(c, domain.ErrInvalidTokenFormat)
 			return
 		}
 
-		accessToken := tokenParts[1]
-
-		// Validate access token
-		claims, err := m.tokenService.ValidateAccessToken(accessToken)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INVALID_TOKEN",
-					Message: "Invalid or expired access token",
-				},
-			})
-			c.Abort()
-			return
-		}
+		m.authenticate(c, tokenParts[1])
+	}
+}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+// authenticate validates the access token and sets its user in the context, or aborts the
+// request
+func (m *AuthMiddleware) authenticate(c *gin.Context, accessToken string) {
+	// Validate access token
+	claims, err := m.tokenService.ValidateAccessToken(accessToken)
+	if err != nil {
+		logging.ModuleFromContext(c.Request.Context(), logging.ModuleAuth).WithError(err).Debug("Access token rejected")
+		abortWithError(c, domain.ErrInvalidToken)
+		return
+	}
 
-		c.Next()
+	if m.isDenied(c, claims) {
+		abortWithError(c, domain.ErrTokenRevoked)
+		return
 	}
+
+	// Set user information in context
+	m.setTokenContext(c, claims)
+
+	c.Next()
 }
 
 // OptionalAuth middleware that optionally extracts user information if token is provided
 func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			m.setAPIKeyContext(c, apiKey)
 			c.Next()
 			return
 		}
 
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.Next()
-			return
+		var accessToken string
+		if cookie, ok := m.cookieToken(c); ok {
+			// Cookies of requests forged by other sites are ignored
+			if !ValidCSRF(c) {
+				c.Next()
+				return
+			}
+			accessToken = cookie
+		} else {
+			tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+				c.Next()
+				return
+			}
+			accessToken = tokenParts[1]
 		}
 
-		accessToken := tokenParts[1]
-
 		claims, err := m.tokenService.ValidateAccessToken(accessToken)
-		if err != nil {
+		if err != nil || m.isDenied(c, claims) {
 			c.Next()
 			return
 		}
 
 		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		m.setTokenContext(c, claims)
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// cookieToken returns the access token cookie of a request without an Authorization header,
+// when cookies are accepted
+func (m *AuthMiddleware) cookieToken(c *gin.Context) (string, bool) {
+	if !m.cookies || c.GetHeader("Authorization") != "" {
+		return "", false
+	}
+	token, err := c.Cookie(AccessTokenCookie)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// ValidCSRF reports whether a request authenticated by cookies may proceed: requests with safe
+// methods always can, and others need CSRFHeader to match CSRFCookie. Other sites can make a
+// browser send the cookies but can't read them, so they can't set the header.
+func ValidCSRF(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := c.Cookie(CSRFCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader(CSRFHeader)), []byte(cookie)) == 1
+}
+
+// isDenied reports whether the access token was revoked. When the denylist can't be read the
+// token is accepted, as it was before the denylist existed, rather than logging everyone out.
+func (m *AuthMiddleware) isDenied(c *gin.Context, claims *service.TokenClaims) bool {
+	if m.denylist == nil {
+		return false
+	}
+
+	denied, err := m.denylist.IsDenied(c.Request.Context(), claims)
+	if err != nil {
+		logging.ModuleFromContext(c.Request.Context(), logging.ModuleAuth).WithError(err).Warn("Failed to check the token denylist")
+		return false
+	}
+	if denied {
+		logging.ModuleFromContext(c.Request.Context(), logging.ModuleAuth).Debug("Revoked access token rejected")
+	}
+	return denied
+}
+
+// setTokenContext stores the user of an access token, and its claims, in the context
+func (m *AuthMiddleware) setTokenContext(c *gin.Context, claims *service.TokenClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("user_locale", claims.Locale)
+	c.Set("user_rate_limit_tier", claims.RateLimitTier)
+	c.Set("token_claims", claims)
+	setRequestUser(c, claims.UserID)
+}
+
+// setAPIKeyContext authenticates an API key and stores the key and its owner in the context
+func (m *AuthMiddleware) setAPIKeyContext(c *gin.Context, key string) bool {
+	if m.authenticateAPIKey == nil {
+		return false
+	}
+
+	apiKey, user, err := m.authenticateAPIKey.Execute(c.Request.Context(), key)
+	if err != nil {
+		return false
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("user_role", string(user.Role))
+	c.Set("user_locale", user.Locale)
+	c.Set("user_rate_limit_tier", string(user.RateLimitTier))
+	c.Set("api_key", apiKey)
+	c.Set("api_key_id", apiKey.ID)
+	setRequestUser(c, user.ID)
+
+	return true
+}
+
+// setRequestUser adds the authenticated user to the request logger, and makes it the actor of
+// the actions the request audits
+func setRequestUser(c *gin.Context, userID string) {
+	ctx := logging.WithFields(c.Request.Context(), logrus.Fields{"user_id": userID})
+	ctx = service.WithAuditActor(ctx, service.AuditActor{
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	c.Request = c.Request.WithContext(ctx)
+}
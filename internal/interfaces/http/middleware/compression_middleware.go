@@ -0,0 +1,310 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig configures response compression
+type CompressionConfig struct {
+	// Level is a compress/flate level, from gzip.HuffmanOnly (-2) to gzip.BestCompression (9)
+	Level int
+	// MinSize is the smallest response body, in bytes, worth compressing
+	MinSize int
+}
+
+// skippedContentTypes are already compressed or streamed and gain nothing from compression
+var skippedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+	"application/octet-stream",
+	"text/event-stream",
+}
+
+// compressibleImageTypes are image types that are text and do compress well
+var compressibleImageTypes = []string{
+	"image/svg+xml",
+}
+
+// CompressionMiddleware compresses response bodies with gzip or deflate based on Accept-Encoding
+type CompressionMiddleware struct {
+	config      CompressionConfig
+	gzipPool    sync.Pool
+	deflatePool sync.Pool
+}
+
+// NewCompressionMiddleware creates a new compression middleware
+func NewCompressionMiddleware(config CompressionConfig) *CompressionMiddleware {
+	if config.Level < gzip.HuffmanOnly || config.Level > gzip.BestCompression {
+		config.Level = gzip.DefaultCompression
+	}
+	if config.MinSize < 0 {
+		config.MinSize = 0
+	}
+
+	m := &CompressionMiddleware{config: config}
+	m.gzipPool.New = func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, config.Level)
+		return w
+	}
+	m.deflatePool.New = func() interface{} {
+		w, _ := zlib.NewWriterLevel(io.Discard, config.Level)
+		return w
+	}
+	return m
+}
+
+// Compress creates a middleware that compresses eligible responses. Bodies are buffered until
+// MinSize bytes are written, so small responses and streams that flush early go out untouched.
+// Responses to clients not accepting gzip or deflate are passed through, still marked as varying
+// by Accept-Encoding.
+func (m *CompressionMiddleware) Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			middleware:     m,
+			encoding:       encoding,
+		}
+		c.Writer = w
+		defer w.finish()
+
+		c.Next()
+	}
+}
+
+// compressionWriter is the common interface of gzip.Writer and zlib.Writer
+type compressionWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter buffers the start of a response body until it can decide whether to compress it
+type compressWriter struct {
+	gin.ResponseWriter
+	middleware *CompressionMiddleware
+	encoding   string
+	buf        bytes.Buffer
+	decided    bool
+	compressor compressionWriter
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf.Write(data)
+	if w.encoding == "" || w.buf.Len() >= w.middleware.config.MinSize {
+		if err := w.decide(w.encoding != ""); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether anything has been written, including buffered bytes
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow sends headers without a body, so there is nothing to compress
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush means the handler is streaming, so an undecided response is sent uncompressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack hands the raw connection over, so compression is abandoned
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide picks compression or passthrough and writes out any buffered bytes
+func (w *compressWriter) decide(allowCompression bool) error {
+	w.decided = true
+
+	header := w.Header()
+	if header.Get("Content-Type") == "" && w.buf.Len() > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+	}
+
+	// Caches have to key the response by Accept-Encoding whenever it could have been compressed,
+	// even when this one wasn't, e.g. because it was short
+	compressible := w.shouldCompress()
+	if compressible {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	if allowCompression && compressible {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+
+		if w.encoding == "gzip" {
+			w.compressor = w.middleware.gzipPool.Get().(*gzip.Writer)
+		} else {
+			w.compressor = w.middleware.deflatePool.Get().(*zlib.Writer)
+		}
+		w.compressor.Reset(w.ResponseWriter)
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// shouldCompress checks the status and headers set so far
+func (w *compressWriter) shouldCompress() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	return isCompressibleContentType(header.Get("Content-Type"))
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// finish sends short bodies as-is and closes the compressor
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if w.compressor == nil {
+		return
+	}
+
+	// Only fails once the client has gone away, with nothing left to send
+	_ = w.compressor.Close()
+	w.compressor.Reset(io.Discard)
+	if w.encoding == "gzip" {
+		w.middleware.gzipPool.Put(w.compressor)
+	} else {
+		w.middleware.deflatePool.Put(w.compressor)
+	}
+	w.compressor = nil
+}
+
+// isCompressibleContentType reports whether a response of contentType is worth compressing
+func isCompressibleContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, compressible := range compressibleImageTypes {
+		if mediaType == compressible {
+			return true
+		}
+	}
+	for _, skipped := range skippedContentTypes {
+		if strings.HasPrefix(mediaType, skipped) {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiateEncoding returns "gzip", "deflate" or "" for an Accept-Encoding header, preferring gzip
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := make(map[string]bool)
+	refused := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		// q=0 explicitly refuses an encoding
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				refused[name] = true
+				continue
+			}
+		}
+
+		if name == "*" {
+			accepted["gzip"] = true
+			accepted["deflate"] = true
+			continue
+		}
+		accepted[name] = true
+	}
+
+	switch {
+	case accepted["gzip"] && !refused["gzip"]:
+		return "gzip"
+	case accepted["deflate"] && !refused["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
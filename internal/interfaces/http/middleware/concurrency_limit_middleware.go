@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// ConcurrencyLimitConfig configures how many requests may be in flight at once. A limit of 0 disables the check.
+type ConcurrencyLimitConfig struct {
+	MaxGlobal    int
+	MaxPerClient int
+	RetryAfter   time.Duration
+}
+
+// ConcurrencyLimitMiddleware caps in-flight requests on expensive endpoints, both per client and across the instance
+type ConcurrencyLimitMiddleware struct {
+	config   ConcurrencyLimitConfig
+	global   chan struct{}
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewConcurrencyLimitMiddleware creates a new concurrency limit middleware
+func NewConcurrencyLimitMiddleware(config ConcurrencyLimitConfig) *ConcurrencyLimitMiddleware {
+	m := &ConcurrencyLimitMiddleware{
+		config:   config,
+		inFlight: make(map[string]int),
+	}
+	if config.MaxGlobal > 0 {
+		m.global = make(chan struct{}, config.MaxGlobal)
+	}
+	return m
+}
+
+// Limit creates a middleware that rejects a request with 503 when the client already has too many
+// requests in flight for the named route group, or when the instance-wide limit is reached.
+func (m *ConcurrencyLimitMiddleware) Limit(identifier string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := identifier + ":" + clientIdentifier(c)
+
+		if !m.acquireClient(key) {
+			m.reject(c, "Too many concurrent requests from this client")
+			return
+		}
+		defer m.releaseClient(key)
+
+		if m.global != nil {
+			select {
+			case m.global <- struct{}{}:
+				defer func() { <-m.global }()
+			default:
+				m.reject(c, "Server is busy, please retry later")
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// acquireClient reserves a per-client slot for key
+func (m *ConcurrencyLimitMiddleware) acquireClient(key string) bool {
+	if m.config.MaxPerClient <= 0 {
+		return true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.inFlight[key] >= m.config.MaxPerClient {
+		return false
+	}
+	m.inFlight[key]++
+	return true
+}
+
+// releaseClient frees a per-client slot for key
+func (m *ConcurrencyLimitMiddleware) releaseClient(key string) {
+	if m.config.MaxPerClient <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Drop idle clients so the map doesn't grow unbounded
+	if m.inFlight[key] <= 1 {
+		delete(m.inFlight, key)
+		return
+	}
+	m.inFlight[key]--
+}
+
+// reject aborts the request with 503 and a Retry-After hint
+func (m *ConcurrencyLimitMiddleware) reject(c *gin.Context, message string) {
+	retryAfter := int(m.config.RetryAfter.Seconds())
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	abortWithError(c, domain.ErrTooManyConcurrent.WithMessage(message))
+}
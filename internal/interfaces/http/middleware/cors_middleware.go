@@ -7,30 +7,39 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// CORSConfig configures cross-origin requests
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins ("https://app.example.com") and wildcard
+	// subdomains ("https://*.example.com")
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	// AllowAll accepts every origin; meant for local development only
+	AllowAll bool
+}
+
 // CORSMiddleware returns a CORS middleware
-func CORSMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000", // React development server
-			"http://localhost:8080", // API server
-			"https://yourdomain.com", // Production domain
-		},
-		AllowMethods: []string{
-			"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS",
-		},
-		AllowHeaders: []string{
-			"Origin",
-			"Content-Type",
-			"Accept",
-			"Authorization",
-			"X-Requested-With",
-			"X-CSRF-Token",
-		},
-		ExposeHeaders: []string{
-			"Content-Length",
-			"X-Total-Count",
-		},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	})
-}
\ No newline at end of file
+func CORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	corsConfig := cors.Config{
+		AllowOrigins:     config.AllowedOrigins,
+		AllowMethods:     config.AllowedMethods,
+		AllowHeaders:     config.AllowedHeaders,
+		ExposeHeaders:    config.ExposedHeaders,
+		AllowCredentials: config.AllowCredentials,
+		AllowWildcard:    true,
+		MaxAge:           config.MaxAge,
+	}
+
+	// Echo the request origin instead of "*" so credentialed requests keep working
+	if config.AllowAll {
+		corsConfig.AllowOrigins = nil
+		corsConfig.AllowOriginFunc = func(origin string) bool {
+			return true
+		}
+	}
+
+	return cors.New(corsConfig)
+}
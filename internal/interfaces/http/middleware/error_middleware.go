@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/infrastructure/errorreporting"
+	"gin-boilerplate/internal/infrastructure/i18n"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorMiddleware renders errors that handlers and middleware attach with c.Error. It must be
+// registered before any middleware that aborts with an error so it can write their response.
+// Server errors are logged and sent to the error reporter, and messages are translated to the
+// user's saved locale or the request's Accept-Language.
+func ErrorMiddleware(reporter *errorreporting.Reporter, translator *i18n.Translator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status := StatusForError(err)
+
+		if status >= http.StatusInternalServerError {
+			logging.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+			}).WithError(err).Error("Request failed")
+			reporter.CaptureError(err, requestContext(c))
+		}
+
+		writeError(c, translator, status, err)
+	}
+}
+
+// RecoveryMiddleware recovers from panics, reports them and answers with a 500 error response
+func RecoveryMiddleware(reporter *errorreporting.Reporter, translator *i18n.Translator) gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		reporter.CapturePanic(recovered, requestContext(c))
+		if !c.Writer.Written() {
+			writeError(c, translator, http.StatusInternalServerError, domain.ErrInternal)
+		}
+		c.Abort()
+	})
+}
+
+// requestContext collects the request and user details attached to reported errors
+func requestContext(c *gin.Context) errorreporting.RequestContext {
+	return errorreporting.RequestContext{
+		Request:   c.Request,
+		RequestID: c.GetString("request_id"),
+		Route:     c.FullPath(),
+		UserID:    c.GetString("user_id"),
+		UserEmail: c.GetString("user_email"),
+		ClientIP:  c.ClientIP(),
+	}
+}
+
+// StatusForError maps an error to its HTTP status code
+func StatusForError(err error) int {
+	switch domain.KindOf(err) {
+	case domain.KindInvalid:
+		return http.StatusBadRequest
+	case domain.KindUnauthorized:
+		return http.StatusUnauthorized
+	case domain.KindForbidden:
+		return http.StatusForbidden
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case domain.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case domain.KindTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// abortWithError stops the chain and leaves the response to ErrorMiddleware
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// writeError writes err in the common error response format, in the request's locale
+func writeError(c *gin.Context, translator *i18n.Translator, status int, err error) {
+	locale := translator.Locale(c.GetString("user_locale"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale)
+
+	detail := dto.ErrorDetail{
+		Code:      domain.ErrInternal.Code,
+		Message:   translator.Translate(locale, domain.ErrInternal.Message, nil),
+		RequestID: c.GetString("request_id"),
+	}
+
+	// Only domain errors carry client-safe messages
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		detail.Code = domainErr.Code
+		detail.Message = translator.Translate(locale, domainErr.Message, nil)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		detail.Message = validationMessage(translator, locale, validationErrs)
+	}
+
+	var retryable interface{ RetryAfter() time.Duration }
+	if errors.As(err, &retryable) && c.Writer.Header().Get("Retry-After") == "" {
+		retryAfter := retryable.RetryAfter()
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds()+0.5)))
+	}
+
+	var quotaErr *domain.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		response := dto.ToQuotaExceededResponse(quotaErr)
+		response.Error.Message = translator.Translate(locale, response.Error.Message, nil)
+		response.Error.RequestID = detail.RequestID
+		c.JSON(status, response)
+		return
+	}
+
+	c.JSON(status, dto.ErrorResponse{Error: detail})
+}
+
+// validationMessage describes every failed binding rule in one translated message
+func validationMessage(translator *i18n.Translator, locale string, errs validator.ValidationErrors) string {
+	messages := make([]string, 0, len(errs))
+	for _, fieldErr := range errs {
+		messages = append(messages, translator.Translate(locale, validationTemplate(fieldErr), map[string]string{
+			"field": fieldErr.Field(),
+			"param": fieldErr.Param(),
+		}))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validationTemplate returns the English message template of a failed binding rule
+func validationTemplate(fieldErr validator.FieldError) string {
+	// min, max and len measure length for strings and collections, and value for numbers
+	measuresLength := false
+	switch fieldErr.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		measuresLength = true
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "{field} is required"
+	case "email":
+		return "{field} must be a valid email address"
+	case "min", "gte":
+		if measuresLength {
+			return "{field} must be at least {param} characters long"
+		}
+		return "{field} must be at least {param}"
+	case "max", "lte":
+		if measuresLength {
+			return "{field} must be at most {param} characters long"
+		}
+		return "{field} must be at most {param}"
+	case "len":
+		return "{field} must be exactly {param} characters long"
+	case "gt":
+		return "{field} must be greater than {param}"
+	case "lt":
+		return "{field} must be less than {param}"
+	case "oneof":
+		return "{field} must be one of: {param}"
+	case "url":
+		return "{field} must be a valid URL"
+	case "uuid":
+		return "{field} must be a valid UUID"
+	case "bcp47_language_tag":
+		return "{field} must be a valid language tag"
+	case "timezone", "eq=|timezone":
+		return "{field} must be a valid time zone"
+	default:
+		return "{field} is invalid"
+	}
+}
@@ -5,31 +5,95 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"io"
+	mathrand "math/rand"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"gin-boilerplate/internal/infrastructure/logging"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-// responseBodyWriter is a wrapper around gin.ResponseWriter to capture response body
+// logBodyLimit is the size of the largest request and response bodies logged in debug mode
+const logBodyLimit = 1024
+
+// responseBodyWriter is a wrapper around gin.ResponseWriter to capture response body in debug
+// mode. Streamed responses aren't captured, since they last as long as the connection, and
+// neither are downloads, which can be as large as the file sent. Bodies too large to be logged
+// are dropped once they pass logBodyLimit.
 type responseBodyWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body    *bytes.Buffer
+	capture bool
 }
 
-func (r responseBodyWriter) Write(b []byte) (int, error) {
-	r.body.Write(b)
+func (r *responseBodyWriter) Write(b []byte) (int, error) {
+	if r.capture {
+		if r.body.Len()+len(b) >= logBodyLimit || !r.captured() {
+			r.capture = false
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
 	return r.ResponseWriter.Write(b)
 }
 
-// LoggerMiddleware returns a logging middleware
-func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *responseBodyWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// captured reports whether the body of the response is worth capturing for the log
+func (r *responseBodyWriter) captured() bool {
+	header := r.Header()
+	if disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition")); disposition == "attachment" {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream", "application/zip", "application/octet-stream", "text/csv",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return false
+	}
+	return true
+}
+
+// LogSampling thins out the request lines of noisy routes, such as probes polled every few
+// seconds. Failed requests to them are always logged.
+type LogSampling struct {
+	// Routes are the paths whose successful requests are sampled, e.g. "/healthz"
+	Routes []string
+	// Rate is the share of their successful requests logged, from 0.0 to 1.0
+	Rate float64
+}
+
+// skip reports whether the line of a request is left out
+func (s LogSampling) skip(path string, status int) bool {
+	return status < 400 && slices.Contains(s.Routes, path) && mathrand.Float64() >= s.Rate
+}
+
+// LoggerMiddleware returns a logging middleware. It stores a logger carrying the request ID, and
+// the trace ID of a traceparent header, in the request context, so use cases and repositories
+// log with logging.FromContext(ctx) and every line of a request can be correlated.
+func LoggerMiddleware(logger *logrus.Logger, sampling LogSampling) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
+		entry := logger.WithField("request_id", c.GetString("request_id"))
+		if traceID := logging.TraceID(c.GetHeader("traceparent")); traceID != "" {
+			entry = entry.WithField("trace_id", traceID)
+		}
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), entry))
+
 		// Read request body
 		var requestBody []byte
-		if c.Request.Body != nil {
+		if gin.Mode() == gin.DebugMode && loggedRequestBody(c.Request) {
 			requestBody, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
 		}
@@ -38,12 +102,17 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		w := &responseBodyWriter{
 			ResponseWriter: c.Writer,
 			body:           &bytes.Buffer{},
+			capture:        gin.Mode() == gin.DebugMode,
 		}
 		c.Writer = w
 
 		// Process request
 		c.Next()
 
+		if sampling.skip(c.Request.URL.Path, c.Writer.Status()) {
+			return
+		}
+
 		// Calculate duration
 		duration := time.Since(start)
 
@@ -72,28 +141,39 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 
 		// Add request/response body for debugging in development
 		if gin.Mode() == gin.DebugMode {
-			if len(requestBody) > 0 && len(requestBody) < 1024 {
+			if len(requestBody) > 0 {
 				fields["request_body"] = string(requestBody)
 			}
-			if w.body.Len() > 0 && w.body.Len() < 1024 {
+			if w.capture && w.body.Len() > 0 {
 				fields["response_body"] = w.body.String()
 			}
 		}
 
-		// Log based on status code
+		// Log based on status code, with the user added by the auth middleware
+		requestLogger := logging.FromContext(c.Request.Context())
 		switch {
 		case c.Writer.Status() >= 500:
-			logger.WithFields(fields).Error("Internal server error")
+			requestLogger.WithFields(fields).Error("Internal server error")
 		case c.Writer.Status() >= 400:
-			logger.WithFields(fields).Warn("Client error")
+			requestLogger.WithFields(fields).Warn("Client error")
 		case c.Writer.Status() >= 300:
-			logger.WithFields(fields).Info("Redirection")
+			requestLogger.WithFields(fields).Info("Redirection")
 		default:
-			logger.WithFields(fields).Info("Request completed")
+			requestLogger.WithFields(fields).Info("Request completed")
 		}
 	}
 }
 
+// loggedRequestBody reports whether the request body is small enough to be logged. It is read
+// before the concurrency limiter runs, so uploads and bodies of unknown length are left alone.
+func loggedRequestBody(r *http.Request) bool {
+	if r.Body == nil || r.ContentLength <= 0 || r.ContentLength >= logBodyLimit {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return !strings.HasPrefix(mediaType, "multipart/")
+}
+
 // RequestIDMiddleware adds a unique request ID to each request
 func RequestIDMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -127,4 +207,4 @@ func randomString(length int) string {
 		return string(b)
 	}
 	return hex.EncodeToString(bytes)[:length]
-}
\ No newline at end of file
+}
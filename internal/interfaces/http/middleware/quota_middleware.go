@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaMiddleware enforces long-window usage quotas for authenticated users
+type QuotaMiddleware struct {
+	quotaService *service.QuotaService
+}
+
+// NewQuotaMiddleware creates a new quota middleware
+func NewQuotaMiddleware(quotaService *service.QuotaService) *QuotaMiddleware {
+	return &QuotaMiddleware{
+		quotaService: quotaService,
+	}
+}
+
+// EnforceQuota counts one unit of the metric per request and rejects requests once the quota is used up
+func (m *QuotaMiddleware) EnforceQuota(metric entity.QuotaMetric) gin.HandlerFunc {
+	return m.enforce(metric, false)
+}
+
+// EnforceQuotaOnSuccess works like EnforceQuota, but gives the unit back when the request fails,
+// for metrics that count completed work such as uploads
+func (m *QuotaMiddleware) EnforceQuotaOnSuccess(metric entity.QuotaMetric) gin.HandlerFunc {
+	return m.enforce(metric, true)
+}
+
+func (m *QuotaMiddleware) enforce(metric entity.QuotaMetric, refundFailures bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		status, err := m.quotaService.Consume(c.Request.Context(), userID, metric, 1)
+		if err != nil {
+			if errors.Is(err, domain.ErrQuotaExceeded) {
+				abortWithError(c, err)
+				return
+			}
+
+			// Don't block requests when usage can't be recorded
+			c.Next()
+			return
+		}
+
+		if status.Limit > 0 {
+			c.Header("X-Quota-Limit", strconv.FormatInt(status.Limit, 10))
+			c.Header("X-Quota-Remaining", strconv.FormatInt(status.Limit-status.Used, 10))
+		}
+
+		c.Next()
+
+		// Handler errors are only rendered later by the error middleware
+		if refundFailures && (len(c.Errors) > 0 || c.Writer.Status() >= http.StatusBadRequest) {
+			// The request may have been cancelled, which mustn't stop the refund
+			ctx := context.WithoutCancel(c.Request.Context())
+			if err := m.quotaService.Refund(ctx, userID, metric, status.Period, 1); err != nil {
+				logging.FromContext(ctx).WithField("metric", metric).WithError(err).Warn("Failed to refund quota usage")
+			}
+		}
+	}
+}
@@ -1,38 +1,141 @@
 package middleware
 
 import (
-	"context"
-	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"gin-boilerplate/internal/domain"
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/domain/service"
+	"gin-boilerplate/internal/infrastructure/metrics"
+)
+
+// FailureMode decides what the rate limiter does when its backing store is unavailable
+type FailureMode string
+
+const (
+	// FailOpen lets requests through unthrottled
+	FailOpen FailureMode = "open"
+	// FailClosed rejects requests until the store recovers
+	FailClosed FailureMode = "closed"
+)
+
+// ParseFailureMode converts a config value to a FailureMode, defaulting to FailOpen
+func ParseFailureMode(value string) FailureMode {
+	if FailureMode(strings.ToLower(strings.TrimSpace(value))) == FailClosed {
+		return FailClosed
+	}
+	return FailOpen
+}
+
+// Rate limit algorithms
+const (
+	// RateLimitFixedWindow counts requests in windows starting at the first request
+	RateLimitFixedWindow = "fixed_window"
+	// RateLimitSlidingWindow weighs the previous window's count by how much of it still
+	// overlaps the last window duration, which smooths bursts at window boundaries
+	RateLimitSlidingWindow = "sliding_window"
+)
+
+// Rate limit key strategies, deciding which requests share a counter
+const (
+	// RateLimitKeyClient counts per API key, else per user, else per IP
+	RateLimitKeyClient = "client"
+	// RateLimitKeyIP counts per client IP
+	RateLimitKeyIP = "ip"
+	// RateLimitKeyUser counts per authenticated user, and per IP for anonymous requests
+	RateLimitKeyUser = "user"
 )
 
 type RateLimitConfig struct {
 	RequestsPerWindow int
 	WindowDuration    time.Duration
+	// FailureMode is the default degradation policy
+	FailureMode FailureMode
+	// FailureModes overrides the policy per route class (e.g. "login", "ip", "api_key")
+	FailureModes map[string]FailureMode
+	// Policies are the named limits routes are mapped to. The policy name is the route class.
+	Policies map[string]RateLimitPolicy
+	// Routes maps route patterns to policies
+	Routes []RateLimitRoute
+	// Tiers are the limits of authenticated users by their rate limit tier. Users of a tier
+	// without one get the free tier's, else the default limit and window.
+	Tiers map[entity.RateLimitTier]RateLimitPolicy
+}
+
+// RateLimitPolicy represents a named limit
+type RateLimitPolicy struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+	Key       string
+}
+
+// RateLimitRoute maps a route pattern, relative to the API version prefix, to a policy
+type RateLimitRoute struct {
+	// Method is an HTTP method, or "*" for any
+	Method string
+	// Path is the route as registered, e.g. "/documents/:id"
+	Path   string
+	Policy string
 }
 
 type RateLimitMiddleware struct {
-	cacheService    *service.CacheService
-	config        RateLimitConfig
+	cacheService *service.CacheService
+	config       RateLimitConfig
+	metrics      *metrics.Metrics
+	// dailyCounters counts the rejections for the admin dashboard
+	dailyCounters *service.DailyCounters
+
+	mu           sync.Mutex
+	degradations map[string]int64
 }
 
-func NewRateLimitMiddleware(cacheService *service.CacheService, config RateLimitConfig) *RateLimitMiddleware {
+// NewRateLimitMiddleware creates a new rate limit middleware. metrics and dailyCounters may be
+// nil.
+func NewRateLimitMiddleware(cacheService *service.CacheService, config RateLimitConfig, metrics *metrics.Metrics, dailyCounters *service.DailyCounters) *RateLimitMiddleware {
 	return &RateLimitMiddleware{
-		cacheService: cacheService,
-		config:      config,
+		cacheService:  cacheService,
+		config:        config,
+		metrics:       metrics,
+		dailyCounters: dailyCounters,
+		degradations:  make(map[string]int64),
+	}
+}
+
+// RateLimitDegradation counts how often a route class fell back to its failure mode
+type RateLimitDegradation struct {
+	RouteClass  string      `json:"route_class"`
+	FailureMode FailureMode `json:"failure_mode"`
+	Count       int64       `json:"count"`
+}
+
+// Degradations returns how many requests were handled by the failure policy, per route class
+func (m *RateLimitMiddleware) Degradations() []RateLimitDegradation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]RateLimitDegradation, 0, len(m.degradations))
+	for routeClass, count := range m.degradations {
+		stats = append(stats, RateLimitDegradation{
+			RouteClass:  routeClass,
+			FailureMode: m.failureMode(routeClass),
+			Count:       count,
+		})
 	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].RouteClass < stats[j].RouteClass })
+	return stats
 }
 
 // RateLimiter tracks request counts per key
 type RateLimiter struct {
-	mu         sync.Mutex
-	requests   int
+	mu          sync.Mutex
+	requests    int
 	windowStart time.Time
 }
 
@@ -58,154 +161,230 @@ func (rl *RateLimiter) IsAllowed(config RateLimitConfig) bool {
 	return rl.requests <= config.RequestsPerWindow
 }
 
-// RateLimit creates a rate limiting middleware
-func (m *RateLimitMiddleware) RateLimit(identifier string) gin.HandlerFunc {
-	// Create rate limiter for this identifier
-	limiter := NewRateLimiter()
-
+// RateLimit creates a rate limiting middleware applying a named policy. An unknown policy
+// gets the default limit and window, counted per client.
+func (m *RateLimitMiddleware) RateLimit(policyName string) gin.HandlerFunc {
+	policy := m.policy(policyName)
 	return func(c *gin.Context) {
-		// Get client identifier (IP, user ID, etc.)
-		key := service.RateLimitCacheKey(identifier)
+		m.limit(c, policyName, policyName+":"+policyIdentifier(c, policy.Key), policy)
+	}
+}
 
-		// Try to increment counter in cache
-		incremented, err := m.cacheService.SetNX(c.Request.Context(), key, 1, m.config.WindowDuration)
-		if err != nil {
-			// Log error but don't block the request
+// RateLimitRoutes creates middleware applying the policy each configured route is mapped to.
+// basePath is the prefix the route patterns are relative to, e.g. "/api/v1". Routes without a
+// policy pass through.
+func (m *RateLimitMiddleware) RateLimitRoutes(basePath string) gin.HandlerFunc {
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	// Resolve the mapping once, keyed by method and full route pattern
+	limiters := make(map[string]gin.HandlerFunc, len(m.config.Routes))
+	for _, route := range m.config.Routes {
+		limiters[route.Method+" "+basePath+route.Path] = m.RateLimit(route.Policy)
+	}
+
+	return func(c *gin.Context) {
+		limiter, ok := limiters[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			limiter, ok = limiters["* "+c.FullPath()]
+		}
+		if !ok {
 			c.Next()
 			return
 		}
+		limiter(c)
+	}
+}
 
-		if !incremented {
-			// Key exists, get current count
-			countStr, err := m.cacheService.GetString(c.Request.Context(), key)
-			if err != nil {
-				c.Next()
-				return
-			}
-
-			if countStr != "" {
-				if count, err := strconv.Atoi(countStr); err == nil {
-					if count > m.config.RequestsPerWindow {
-						c.JSON(http.StatusTooManyRequests, gin.H{
-							"error": "Rate limit exceeded",
-							"retry_after": m.config.WindowDuration.Seconds(),
-						})
-						c.Abort()
-						return
-					}
-				}
-			}
-		}
+// policy returns a named policy, falling back to the default limit and window
+func (m *RateLimitMiddleware) policy(name string) RateLimitPolicy {
+	if policy, ok := m.config.Policies[name]; ok {
+		return policy
+	}
+	return m.defaultPolicy()
+}
 
-		// Allow the request
-		c.Next()
+// defaultPolicy is the fixed window limit of the IP and user limiters
+func (m *RateLimitMiddleware) defaultPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		Algorithm: RateLimitFixedWindow,
+		Limit:     m.config.RequestsPerWindow,
+		Window:    m.config.WindowDuration,
+		Key:       RateLimitKeyClient,
 	}
 }
 
 // RateLimitByIP creates rate limiting middleware by IP address
 func (m *RateLimitMiddleware) RateLimitByIP() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		limiter := NewRateLimiter()
-
-		key := service.RateLimitCacheKey("ip:" + clientIP)
-
-		// Check current rate in cache
-		countStr, err := m.cacheService.GetString(c.Request.Context(), key)
-		if err != nil {
-			c.Next()
-			return
-		}
-
-		currentCount := 0
-		if countStr != "" {
-			if parsed, err := strconv.Atoi(countStr); err == nil {
-				currentCount = parsed
-			}
-		}
+		m.limit(c, "ip", "ip:"+c.ClientIP(), m.defaultPolicy())
+	}
+}
 
-		if currentCount >= m.config.RequestsPerWindow {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"retry_after": m.config.WindowDuration.Seconds(),
+// RateLimitByUser creates rate limiting middleware by user ID, with the limit of the user's
+// rate limit tier. Requests authenticated with an API key are limited by the limit stored on the
+// key instead, so a key can be given more than its owner's tier. Anonymous requests are limited
+// by IP address, like RateLimitByIP, so it goes after OptionalAuth.
+func (m *RateLimitMiddleware) RateLimitByUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("api_key")
+		if apiKey, ok := value.(*entity.APIKey); ok {
+			m.limit(c, "api_key", "api_key:"+apiKey.ID, RateLimitPolicy{
+				Algorithm: RateLimitFixedWindow,
+				Limit:     apiKey.RateLimit,
+				Window:    apiKey.RateWindow(),
 			})
-			c.Abort()
 			return
 		}
 
-		// Increment counter
-		if err := m.cacheService.Increment(c.Request.Context(), key); err != nil {
-			// Log error but allow request
-			c.Next()
+		userID := c.GetString("user_id")
+		if userID == "" {
+			m.limit(c, "ip", "ip:"+c.ClientIP(), m.defaultPolicy())
 			return
 		}
 
-		// Set expiry for the key if not already set
-		if countStr == "" {
-			m.cacheService.SetWithExpiration(
-				c.Request.Context(),
-				key,
-				"1",
-				m.config.WindowDuration,
-			)
-		}
+		m.limit(c, "user", "user:"+userID, m.tierPolicy(entity.RateLimitTier(c.GetString("user_rate_limit_tier"))))
+	}
+}
 
-		c.Next()
+// tierPolicy returns the limit of a rate limit tier, falling back to the free tier's
+func (m *RateLimitMiddleware) tierPolicy(tier entity.RateLimitTier) RateLimitPolicy {
+	if policy, ok := m.config.Tiers[tier]; ok {
+		return policy
 	}
+	if policy, ok := m.config.Tiers[entity.RateLimitTierFree]; ok {
+		return policy
+	}
+	return m.defaultPolicy()
 }
 
-// RateLimitByUser creates rate limiting middleware by user ID
-func (m *RateLimitMiddleware) RateLimitByUser() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.Next()
-			return
+// limit counts the request against the policy and aborts once the limit is exceeded
+func (m *RateLimitMiddleware) limit(c *gin.Context, routeClass, identifier string, policy RateLimitPolicy) {
+	count, resetIn, err := m.count(c, identifier, policy)
+	if err != nil {
+		m.degrade(c, routeClass)
+		return
+	}
+
+	remaining := int64(policy.Limit) - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(policy.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(resetIn).Unix(), 10))
+
+	if count > int64(policy.Limit) {
+		c.Header("Retry-After", strconv.Itoa(int(resetIn.Seconds()+0.5)))
+		if m.metrics != nil {
+			m.metrics.RateLimitRejected(routeClass, "exceeded")
+		}
+		if m.dailyCounters != nil {
+			m.dailyCounters.Increment(c.Request.Context(), service.DailyCounterRateLimitRejections)
 		}
+		abortWithError(c, domain.ErrRateLimitExceeded)
+		return
+	}
+
+	c.Next()
+}
 
-		limiter := NewRateLimiter()
-		key := service.RateLimitCacheKey("user:" + userID)
+// count adds the request to the policy's counter and returns the count and the time until it
+// resets
+func (m *RateLimitMiddleware) count(c *gin.Context, identifier string, policy RateLimitPolicy) (int64, time.Duration, error) {
+	ctx := c.Request.Context()
 
-		// Check current rate in cache
-		countStr, err := m.cacheService.GetString(c.Request.Context(), key)
+	if policy.Algorithm != RateLimitSlidingWindow {
+		key := service.RateLimitCacheKey(identifier)
+		count, err := m.cacheService.IncrementWithExpiry(ctx, key, policy.Window)
 		if err != nil {
-			c.Next()
-			return
+			return 0, 0, err
 		}
 
-		currentCount := 0
-		if countStr != "" {
-			if parsed, err := strconv.Atoi(countStr); err == nil {
-				currentCount = parsed
-			}
+		resetIn := policy.Window
+		if ttl, err := m.cacheService.TTL(ctx, key); err == nil && ttl > 0 {
+			resetIn = ttl
 		}
+		return count, resetIn, nil
+	}
 
-		if currentCount >= m.config.RequestsPerWindow {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"retry_after": m.config.WindowDuration.Seconds(),
-			})
-			c.Abort()
-			return
-		}
+	// Windows are aligned to the clock, so the previous window has a known key. Each counter
+	// lives for two windows, long enough to be read as the previous one.
+	now := time.Now()
+	window := now.UnixNano() / int64(policy.Window)
+	elapsed := time.Duration(now.UnixNano() % int64(policy.Window))
 
-		// Increment counter
-		if err := m.cacheService.Increment(c.Request.Context(), key); err != nil {
-			// Log error but allow request
-			c.Next()
-			return
-		}
+	current, err := m.cacheService.IncrementWithExpiry(ctx, service.RateLimitCacheKey(identifier+":"+strconv.FormatInt(window, 10)), 2*policy.Window)
+	if err != nil {
+		return 0, 0, err
+	}
 
-		// Set expiry for the key if not already set
-		if countStr == "" {
-			m.cacheService.SetWithExpiration(
-				c.Request.Context(),
-				key,
-				"1",
-				m.config.WindowDuration,
-			)
-		}
+	var previous int64
+	value, err := m.cacheService.GetString(ctx, service.RateLimitCacheKey(identifier+":"+strconv.FormatInt(window-1, 10)))
+	if err == nil {
+		previous, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	return current + int64(float64(previous)*weight), policy.Window - elapsed, nil
+}
 
+// degrade applies the failure policy of the route class when the cache is unavailable
+func (m *RateLimitMiddleware) degrade(c *gin.Context, routeClass string) {
+	m.mu.Lock()
+	m.degradations[routeClass]++
+	m.mu.Unlock()
+
+	mode := m.failureMode(routeClass)
+	if m.metrics != nil {
+		m.metrics.RateLimitDegraded(routeClass, string(mode))
+	}
+
+	if mode == FailOpen {
 		c.Next()
+		return
 	}
-}
\ No newline at end of file
+
+	if m.metrics != nil {
+		m.metrics.RateLimitRejected(routeClass, "unavailable")
+	}
+	c.Header("Retry-After", "5")
+	abortWithError(c, domain.ErrRateLimitUnavailable)
+}
+
+// failureMode resolves the degradation policy of a route class
+func (m *RateLimitMiddleware) failureMode(routeClass string) FailureMode {
+	if mode, ok := m.config.FailureModes[routeClass]; ok {
+		return mode
+	}
+	if m.config.FailureMode == "" {
+		return FailOpen
+	}
+	return m.config.FailureMode
+}
+
+// policyIdentifier returns the identity a policy counts requests by
+func policyIdentifier(c *gin.Context, key string) string {
+	switch key {
+	case RateLimitKeyIP:
+		return "ip:" + c.ClientIP()
+	case RateLimitKeyUser:
+		if userID := c.GetString("user_id"); userID != "" {
+			return "user:" + userID
+		}
+		return "ip:" + c.ClientIP()
+	default:
+		return clientIdentifier(c)
+	}
+}
+
+// clientIdentifier returns the most specific identity available for the request
+func clientIdentifier(c *gin.Context) string {
+	if apiKeyID := c.GetString("api_key_id"); apiKeyID != "" {
+		return "api_key:" + apiKeyID
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
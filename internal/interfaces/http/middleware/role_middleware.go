@@ -1,20 +1,48 @@
 package middleware
 
 import (
-	"net/http"
+	"slices"
 
-	"gin-boilerplate/internal/application/dto"
+	"gin-boilerplate/internal/domain"
 	"gin-boilerplate/internal/domain/entity"
+	"gin-boilerplate/internal/domain/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RoleMiddleware handles role-based access control
-type RoleMiddleware struct{}
+type RoleMiddleware struct {
+	policy *service.PolicyService
+}
 
 // NewRoleMiddleware creates a new role middleware
-func NewRoleMiddleware() *RoleMiddleware {
-	return &RoleMiddleware{}
+func NewRoleMiddleware(policy *service.PolicyService) *RoleMiddleware {
+	return &RoleMiddleware{
+		policy: policy,
+	}
+}
+
+// RequirePermission middleware that requires the user's role to have a permission
+func (m *RoleMiddleware) RequirePermission(permission entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole, exists := c.Get("user_role")
+		if !exists {
+			abortWithError(c, domain.ErrUnauthorized)
+			return
+		}
+
+		allowed, err := m.policy.Can(c.Request.Context(), entity.Role(userRole.(string)), permission)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if !allowed {
+			abortWithError(c, domain.ErrForbidden)
+			return
+		}
+
+		c.Next()
+	}
 }
 
 // RequireRole middleware that requires specific role
@@ -22,24 +50,12 @@ func (m *RoleMiddleware) RequireRole(role entity.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "UNAUTHORIZED",
-					Message: "User not authenticated",
-				},
-			})
-			c.Abort()
+			abortWithError(c, domain.ErrUnauthorized)
 			return
 		}
 
 		if userRole.(string) != string(role) {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INSUFFICIENT_PERMISSIONS",
-					Message: "Insufficient permissions to access this resource",
-				},
-			})
-			c.Abort()
+			abortWithError(c, domain.ErrForbidden)
 			return
 		}
 
@@ -52,31 +68,36 @@ func (m *RoleMiddleware) RequireAdmin() gin.HandlerFunc {
 	return m.RequireRole(entity.RoleAdmin)
 }
 
+// RequireModerator middleware that requires moderator role (or higher)
+func (m *RoleMiddleware) RequireModerator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_role"); !exists {
+			abortWithError(c, domain.ErrUnauthorized)
+			return
+		}
+
+		if !IsModerator(c) {
+			abortWithError(c, domain.ErrForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireUser middleware that requires user role (or higher)
 func (m *RoleMiddleware) RequireUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "UNAUTHORIZED",
-					Message: "User not authenticated",
-				},
-			})
-			c.Abort()
+			abortWithError(c, domain.ErrUnauthorized)
 			return
 		}
 
-		// Both USER and ADMIN roles can access user endpoints
-		role := userRole.(string)
-		if role != string(entity.RoleUser) && role != string(entity.RoleAdmin) {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: dto.ErrorDetail{
-					Code:    "INSUFFICIENT_PERMISSIONS",
-					Message: "Insufficient permissions to access this resource",
-				},
-			})
-			c.Abort()
+		// Every built-in role can access user endpoints
+		role := entity.Role(userRole.(string))
+		if !slices.Contains(entity.BuiltinRoles, role) {
+			abortWithError(c, domain.ErrForbidden)
 			return
 		}
 
@@ -98,12 +119,16 @@ func IsAdmin(c *gin.Context) bool {
 	return HasRole(c, entity.RoleAdmin)
 }
 
+// IsModerator checks if user has at least moderator role
+func IsModerator(c *gin.Context) bool {
+	return HasRole(c, entity.RoleModerator) || HasRole(c, entity.RoleAdmin)
+}
+
 // IsUser checks if user has at least user role
 func IsUser(c *gin.Context) bool {
 	userRole, exists := c.Get("user_role")
 	if !exists {
 		return false
 	}
-	role := userRole.(string)
-	return role == string(entity.RoleUser) || role == string(entity.RoleAdmin)
-}
\ No newline at end of file
+	return slices.Contains(entity.BuiltinRoles, entity.Role(userRole.(string)))
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-boilerplate/internal/domain"
+)
+
+// TimeoutConfig configures request deadlines. A timeout of 0 leaves the request without a deadline.
+type TimeoutConfig struct {
+	Default time.Duration
+	// Routes overrides Default for specific routes, keyed by "METHOD /full/path" as registered in the router
+	Routes map[string]time.Duration
+}
+
+// TimeoutMiddleware puts a deadline on the request context so slow work is cancelled
+type TimeoutMiddleware struct {
+	config TimeoutConfig
+}
+
+// NewTimeoutMiddleware creates a new timeout middleware
+func NewTimeoutMiddleware(config TimeoutConfig) *TimeoutMiddleware {
+	return &TimeoutMiddleware{
+		config: config,
+	}
+}
+
+// Timeout creates a middleware that cancels c.Request.Context() once the route's deadline passes.
+// Handlers run on the request goroutine, so they must pass the request context down to use cases
+// and repositories; a request that overran its deadline without writing a response gets 504.
+func (m *TimeoutMiddleware) Timeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := m.timeoutFor(c)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			// Takes precedence over whatever error the cancelled call chain reported
+			c.Error(domain.ErrTimeout)
+		}
+	}
+}
+
+// timeoutFor returns the deadline configured for the matched route
+func (m *TimeoutMiddleware) timeoutFor(c *gin.Context) time.Duration {
+	if timeout, ok := m.config.Routes[c.Request.Method+" "+c.FullPath()]; ok {
+		return timeout
+	}
+	return m.config.Default
+}
@@ -1,10 +1,17 @@
 package router
 
 import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gin-boilerplate/internal/domain/entity"
 	"gin-boilerplate/internal/interfaces/http/handler"
 	"gin-boilerplate/internal/interfaces/http/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -20,26 +27,88 @@ func NewRouter(
 	userHandler *handler.UserHandler,
 	documentHandler *handler.DocumentHandler,
 	avatarHandler *handler.AvatarHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	sessionHandler *handler.SessionHandler,
+	emailChangeHandler *handler.EmailChangeHandler,
+	userProviderHandler *handler.UserProviderHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	webhookHandler *handler.WebhookHandler,
+	securityEventHandler *handler.SecurityEventHandler,
+	dashboardHandler *handler.DashboardHandler,
+	invitationHandler *handler.InvitationHandler,
+	roleHandler *handler.RoleHandler,
+	auditLogHandler *handler.AuditLogHandler,
+	userMetadataHandler *handler.UserMetadataHandler,
+	activityHandler *handler.ActivityHandler,
+	userSettingsHandler *handler.UserSettingsHandler,
+	quotaHandler *handler.QuotaHandler,
+	rateLimitHandler *handler.RateLimitHandler,
+	logLevelHandler *handler.LogLevelHandler,
+	configHandler *handler.ConfigHandler,
+	jobHandler *handler.JobHandler,
+	healthHandler *handler.HealthHandler,
+	jwksHandler *handler.JWKSHandler,
+	eventHandler *handler.EventHandler,
+	staticHandler *handler.StaticHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	roleMiddleware *middleware.RoleMiddleware,
 	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	quotaMiddleware *middleware.QuotaMiddleware,
+	concurrencyMiddleware *middleware.ConcurrencyLimitMiddleware,
+	compressionMiddleware *middleware.CompressionMiddleware,
+	timeoutMiddleware *middleware.TimeoutMiddleware,
+	metricsMiddleware *middleware.MetricsMiddleware,
+	networkRestrictionMiddleware *middleware.NetworkRestrictionMiddleware,
 	loggerMiddleware func() gin.HandlerFunc,
+	errorMiddleware func() gin.HandlerFunc,
+	recoveryMiddleware func() gin.HandlerFunc,
+	corsMiddleware func() gin.HandlerFunc,
+	grpcGateway http.Handler,
 ) *Router {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 
+	// Report validation errors with JSON field names instead of Go struct field names
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+
 	// Add global middleware
-	engine.Use(gin.Recovery())
-	engine.Use(rateLimitMiddleware.RateLimitByIP())
-	engine.Use(loggerMiddleware())
-	engine.Use(middleware.CORSMiddleware())
+	engine.Use(recoveryMiddleware())
 	engine.Use(middleware.RequestIDMiddleware())
+	engine.Use(metricsMiddleware.Instrument())
+	if compressionMiddleware != nil {
+		engine.Use(compressionMiddleware.Compress())
+	}
+	engine.Use(loggerMiddleware())
+	engine.Use(errorMiddleware())
+	engine.Use(timeoutMiddleware.Timeout())
+	engine.Use(corsMiddleware())
+	// Authenticated users are limited by their rate limit tier, and anonymous traffic by IP
+	engine.Use(authMiddleware.OptionalAuth())
+	engine.Use(rateLimitMiddleware.RateLimitByUser())
 
 	router := &Router{
 		engine: engine,
 	}
 
-	router.setupRoutes(authHandler, userHandler, documentHandler, avatarHandler, authMiddleware, roleMiddleware, rateLimitMiddleware)
+	router.setupRoutes(authHandler, userHandler, documentHandler, avatarHandler, apiKeyHandler, sessionHandler, emailChangeHandler, userProviderHandler, twoFactorHandler, webhookHandler, securityEventHandler, dashboardHandler, invitationHandler, roleHandler, auditLogHandler, userMetadataHandler, activityHandler, userSettingsHandler, quotaHandler, rateLimitHandler, logLevelHandler, configHandler, jobHandler, healthHandler, jwksHandler, eventHandler, authMiddleware, roleMiddleware, rateLimitMiddleware, quotaMiddleware, concurrencyMiddleware, metricsMiddleware, networkRestrictionMiddleware)
+
+	// gRPC methods as JSON over HTTP, e.g. POST /rpc/ginfinity.v1.AuthService/Login
+	if grpcGateway != nil {
+		gateway := http.StripPrefix("/rpc", grpcGateway)
+		engine.Any("/rpc/*path", func(c *gin.Context) {
+			// The gRPC server takes the client's address from X-Forwarded-For, so it is replaced
+			// with the address resolved through the trusted proxies rather than trusted as sent
+			c.Request.Header.Set("X-Forwarded-For", c.ClientIP())
+			gateway.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// Built frontend for every path no route matched
+	if staticHandler != nil {
+		engine.NoRoute(staticHandler.Serve)
+	}
 
 	return router
 }
@@ -50,55 +119,117 @@ func (r *Router) setupRoutes(
 	userHandler *handler.UserHandler,
 	documentHandler *handler.DocumentHandler,
 	avatarHandler *handler.AvatarHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	sessionHandler *handler.SessionHandler,
+	emailChangeHandler *handler.EmailChangeHandler,
+	userProviderHandler *handler.UserProviderHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	webhookHandler *handler.WebhookHandler,
+	securityEventHandler *handler.SecurityEventHandler,
+	dashboardHandler *handler.DashboardHandler,
+	invitationHandler *handler.InvitationHandler,
+	roleHandler *handler.RoleHandler,
+	auditLogHandler *handler.AuditLogHandler,
+	userMetadataHandler *handler.UserMetadataHandler,
+	activityHandler *handler.ActivityHandler,
+	userSettingsHandler *handler.UserSettingsHandler,
+	quotaHandler *handler.QuotaHandler,
+	rateLimitHandler *handler.RateLimitHandler,
+	logLevelHandler *handler.LogLevelHandler,
+	configHandler *handler.ConfigHandler,
+	jobHandler *handler.JobHandler,
+	healthHandler *handler.HealthHandler,
+	jwksHandler *handler.JWKSHandler,
+	eventHandler *handler.EventHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	roleMiddleware *middleware.RoleMiddleware,
 	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	quotaMiddleware *middleware.QuotaMiddleware,
+	concurrencyMiddleware *middleware.ConcurrencyLimitMiddleware,
+	metricsMiddleware *middleware.MetricsMiddleware,
+	networkRestrictionMiddleware *middleware.NetworkRestrictionMiddleware,
 ) {
+	// Operational endpoints are limited to the admin networks
+	restrictNetwork := networkRestrictionMiddleware.Restrict()
+
 	// Swagger documentation
-	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.engine.GET("/swagger/*any", restrictNetwork, ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Health check endpoint
-	r.engine.GET("/health", r.healthCheck)
+	// Health check endpoints: liveness for restarts, readiness for traffic routing
+	r.engine.GET("/healthz", healthHandler.Liveness)
+	r.engine.GET("/readyz", healthHandler.Readiness)
 
-	// Public avatar endpoint (no authentication required)
-	r.engine.GET("/api/v1/users/avatar/:id", avatarHandler.ServeAvatar)
+	// Public keys of access tokens, for services verifying them
+	r.engine.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	// Prometheus metrics endpoint
+	r.engine.GET("/metrics", restrictNetwork, metricsMiddleware.Handler())
+
+	// Every API version is mounted under /api/<version>. Versions share handlers; a route whose
+	// DTOs change in a newer version registers that version's handler (or a handler.AdaptJSON
+	// wrapper around the shared one) when setting up that version's group.
+	for _, version := range apiVersions {
+		api := r.engine.Group("/api/"+version.Name, versionMiddleware(version))
+
+		// Rate limit policies of the configured routes, matched against the registered patterns
+		routeRateLimits := rateLimitMiddleware.RateLimitRoutes(api.BasePath())
+
+		// Public avatar endpoint (no authentication required)
+		api.GET("/users/avatar/:id", avatarHandler.ServeAvatar)
 
-	// API v1 routes
-	v1 := r.engine.Group("/api/v1")
-	{
 		// Public routes (no authentication required)
-		public := v1.Group("/")
+		public := api.Group("/")
+		public.Use(routeRateLimits)
 		{
-			r.setupPublicRoutes(public, authHandler, avatarHandler)
+			r.setupPublicRoutes(public, authHandler, emailChangeHandler, avatarHandler, userSettingsHandler)
 		}
 
 		// Protected routes (authentication required)
-		protected := v1.Group("/")
+		protected := api.Group("/")
 		protected.Use(authMiddleware.RequireAuth())
+		protected.Use(quotaMiddleware.EnforceQuota(entity.QuotaMetricRequestsDaily))
+		protected.Use(routeRateLimits)
 		{
-			r.setupProtectedRoutes(protected, authHandler, userHandler, documentHandler, avatarHandler, roleMiddleware)
+			r.setupProtectedRoutes(protected, authHandler, userHandler, documentHandler, avatarHandler, apiKeyHandler, sessionHandler, emailChangeHandler, userProviderHandler, twoFactorHandler, webhookHandler, securityEventHandler, activityHandler, userSettingsHandler, eventHandler, roleMiddleware, quotaMiddleware, concurrencyMiddleware, metricsMiddleware)
 		}
 
-		// Admin routes (admin role required)
-		admin := v1.Group("/")
+		// Admin routes (admin network required, and the permission of each route)
+		admin := api.Group("/")
+		admin.Use(restrictNetwork)
 		admin.Use(authMiddleware.RequireAuth())
-		admin.Use(roleMiddleware.RequireAdmin())
+		admin.Use(routeRateLimits)
 		{
-			r.setupAdminRoutes(admin, userHandler)
+			r.setupAdminRoutes(admin, userHandler, documentHandler, avatarHandler, apiKeyHandler, quotaHandler, rateLimitHandler, logLevelHandler, configHandler, jobHandler, securityEventHandler, dashboardHandler, invitationHandler, roleHandler, auditLogHandler, userMetadataHandler, roleMiddleware)
 		}
 	}
 }
 
 // setupPublicRoutes configures public routes
-func (r *Router) setupPublicRoutes(group *gin.RouterGroup, authHandler *handler.AuthHandler, avatarHandler *handler.AvatarHandler, rateLimitMiddleware *middleware.RateLimitMiddleware) {
+func (r *Router) setupPublicRoutes(group *gin.RouterGroup, authHandler *handler.AuthHandler, emailChangeHandler *handler.EmailChangeHandler, avatarHandler *handler.AvatarHandler, userSettingsHandler *handler.UserSettingsHandler) {
 	// Authentication routes
 	auth := group.Group("/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/two-factor", authHandler.LoginTwoFactor)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/resend-verification", authHandler.ResendVerification)
+		auth.POST("/confirm-email-change", emailChangeHandler.ConfirmEmailChange)
+		auth.POST("/revert-email-change", emailChangeHandler.RevertEmailChange)
+		auth.GET("/oauth/:provider", authHandler.OAuthRedirect)
+		auth.GET("/oauth/:provider/callback", authHandler.OAuthCallback)
 		auth.GET("/google", authHandler.GoogleAuth)
 		auth.GET("/google/callback", authHandler.GoogleCallback)
+		auth.POST("/google/token", authHandler.GoogleToken)
+	}
+
+	// Unsubscribe links of notification emails, signed instead of authenticated
+	notifications := group.Group("/notifications")
+	{
+		notifications.POST("/unsubscribe", userSettingsHandler.Unsubscribe)
 	}
 }
 
@@ -109,8 +240,20 @@ func (r *Router) setupProtectedRoutes(
 	userHandler *handler.UserHandler,
 	documentHandler *handler.DocumentHandler,
 	avatarHandler *handler.AvatarHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	sessionHandler *handler.SessionHandler,
+	emailChangeHandler *handler.EmailChangeHandler,
+	userProviderHandler *handler.UserProviderHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	webhookHandler *handler.WebhookHandler,
+	securityEventHandler *handler.SecurityEventHandler,
+	activityHandler *handler.ActivityHandler,
+	userSettingsHandler *handler.UserSettingsHandler,
+	eventHandler *handler.EventHandler,
 	roleMiddleware *middleware.RoleMiddleware,
-	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	quotaMiddleware *middleware.QuotaMiddleware,
+	concurrencyMiddleware *middleware.ConcurrencyLimitMiddleware,
+	metricsMiddleware *middleware.MetricsMiddleware,
 ) {
 	// Authentication routes (require valid token)
 	auth := group.Group("/auth")
@@ -119,63 +262,219 @@ func (r *Router) setupProtectedRoutes(
 		auth.POST("/logout-all", authHandler.LogoutAll)
 	}
 
-	// API endpoints with rate limiting
-	api := group.Group("/")
-	{
-		// Add rate limiting to specific sensitive endpoints
-		api.POST("/auth/login", rateLimitMiddleware.RateLimit("login"))
-		api.POST("/auth/register", rateLimitMiddleware.RateLimit("register"))
-		api.POST("/users/avatar", rateLimitMiddleware.RateLimit("avatar_upload"))
-		api.POST("/documents/upload", rateLimitMiddleware.RateLimit("document_upload"))
-	}
-
 	// User routes (authenticated users)
 	users := group.Group("/users")
 	{
 		// Current user endpoints
 		users.GET("/me", userHandler.GetMe)
 		users.PUT("/me", userHandler.UpdateMe)
+		users.PATCH("/me", userHandler.PatchMe)
+		users.DELETE("/me", userHandler.DeleteMe)
+		users.GET("/me/settings", userSettingsHandler.GetSettings)
+		users.PUT("/me/settings", userSettingsHandler.UpdateSettings)
+		users.POST("/me/export", userHandler.ExportMe)
+		users.PUT("/me/password", userHandler.ChangePassword)
+		users.POST("/me/email", emailChangeHandler.RequestEmailChange)
+
+		// Sign-in method endpoints
+		users.GET("/me/providers", userProviderHandler.ListProviders)
+		users.POST("/me/providers/:provider", userProviderHandler.LinkProvider)
+		users.DELETE("/me/providers/:provider", userProviderHandler.UnlinkProvider)
+
+		// Phone number and two-factor authentication endpoints
+		users.POST("/me/phone", twoFactorHandler.RequestPhoneVerification)
+		users.POST("/me/phone/verify", twoFactorHandler.VerifyPhone)
+		users.PUT("/me/two-factor", twoFactorHandler.EnableTwoFactor)
+		users.DELETE("/me/two-factor", twoFactorHandler.DisableTwoFactor)
+		users.GET("/me/two-factor/recovery-codes", twoFactorHandler.GetRecoveryCodes)
+		users.POST("/me/two-factor/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
 
 		// Avatar endpoints
-		users.POST("/avatar", avatarHandler.UploadAvatar)
+		users.POST("/avatar", concurrencyMiddleware.Limit("upload"), quotaMiddleware.EnforceQuotaOnSuccess(entity.QuotaMetricUploadsMonthly), metricsMiddleware.ObserveUpload("avatar"), avatarHandler.UploadAvatar)
 		users.DELETE("/avatar", avatarHandler.RemoveAvatar)
+
+		// API key endpoints
+		users.POST("/me/api-keys", apiKeyHandler.CreateAPIKey)
+		users.GET("/me/api-keys", apiKeyHandler.ListAPIKeys)
+		users.DELETE("/me/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+		// Session endpoints
+		users.GET("/me/sessions", sessionHandler.ListSessions)
+		users.DELETE("/me/sessions/:id", sessionHandler.RevokeSession)
+
+		// Webhook endpoints
+		users.POST("/me/webhooks", webhookHandler.CreateWebhook)
+		users.GET("/me/webhooks", webhookHandler.ListWebhooks)
+		users.GET("/me/webhooks/:id", webhookHandler.GetWebhook)
+		users.PUT("/me/webhooks/:id", webhookHandler.UpdateWebhook)
+		users.DELETE("/me/webhooks/:id", webhookHandler.DeleteWebhook)
+		users.POST("/me/webhooks/:id/ping", webhookHandler.PingWebhook)
+		users.GET("/me/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+
+		// Security event endpoints
+		users.GET("/me/security-events", securityEventHandler.ListMyEvents)
+		users.GET("/me/suspicious-logins", securityEventHandler.ListMySuspiciousLogins)
+
+		// Activity feed endpoints
+		users.GET("/me/activity", activityHandler.ListMyActivity)
 	}
 
 	// Document routes (authenticated users)
+	read := roleMiddleware.RequirePermission(entity.PermissionDocumentsRead)
+	write := roleMiddleware.RequirePermission(entity.PermissionDocumentsWrite)
 	documents := group.Group("/documents")
 	{
-		documents.POST("/upload", documentHandler.UploadDocument)
-		documents.GET("", documentHandler.GetUserDocuments)
-		documents.GET("/:id", documentHandler.GetDocument)
-		documents.PUT("/:id", documentHandler.UpdateDocument)
-		documents.DELETE("/:id", documentHandler.DeleteDocument)
-		documents.GET("/:id/download", documentHandler.GetPresignedURL)
+		documents.POST("/upload", write, concurrencyMiddleware.Limit("upload"), quotaMiddleware.EnforceQuotaOnSuccess(entity.QuotaMetricUploadsMonthly), metricsMiddleware.ObserveUpload("document"), documentHandler.UploadDocument)
+		documents.GET("", read, documentHandler.GetUserDocuments)
+		documents.GET("/search", read, documentHandler.SearchDocuments)
+		documents.GET("/:id", read, documentHandler.GetDocument)
+		documents.PUT("/:id", write, documentHandler.UpdateDocument)
+		documents.DELETE("/:id", roleMiddleware.RequirePermission(entity.PermissionDocumentsDelete), documentHandler.DeleteDocument)
+		documents.GET("/:id/download", read, documentHandler.GetPresignedURL)
+		documents.POST("/download-zip", read, concurrencyMiddleware.Limit("download"), documentHandler.DownloadDocumentsZip)
 	}
+
+	// Server-Sent Events stream of the current user's events
+	group.GET("/events", eventHandler.StreamEvents)
 }
 
-// setupAdminRoutes configures admin routes
-func (r *Router) setupAdminRoutes(group *gin.RouterGroup, userHandler *handler.UserHandler) {
+// setupAdminRoutes configures admin routes, each requiring a permission
+func (r *Router) setupAdminRoutes(group *gin.RouterGroup, userHandler *handler.UserHandler, documentHandler *handler.DocumentHandler, avatarHandler *handler.AvatarHandler, apiKeyHandler *handler.APIKeyHandler, quotaHandler *handler.QuotaHandler, rateLimitHandler *handler.RateLimitHandler, logLevelHandler *handler.LogLevelHandler, configHandler *handler.ConfigHandler, jobHandler *handler.JobHandler, securityEventHandler *handler.SecurityEventHandler, dashboardHandler *handler.DashboardHandler, invitationHandler *handler.InvitationHandler, roleHandler *handler.RoleHandler, auditLogHandler *handler.AuditLogHandler, userMetadataHandler *handler.UserMetadataHandler, roleMiddleware *middleware.RoleMiddleware) {
+	require := roleMiddleware.RequirePermission
+
 	// Admin user management
 	users := group.Group("/users")
 	{
-		users.GET("", userHandler.ListUsers)           // List all users
-		users.GET("/:id", userHandler.GetUser)         // Get user by ID
-		users.DELETE("/:id", userHandler.DeleteUser)   // Delete user
-		users.POST("/:id/promote", userHandler.PromoteUser) // Promote to admin
-		users.POST("/:id/demote", userHandler.DemoteUser)   // Demote from admin
+		users.GET("", require(entity.PermissionUsersRead), userHandler.ListUsers)                  // List all users
+		users.POST("", require(entity.PermissionUsersCreate), userHandler.CreateUser)              // Create a user
+		users.GET("/export", require(entity.PermissionUsersRead), userHandler.ExportUsers)         // Export as CSV or XLSX
+		users.GET("/:id", require(entity.PermissionUsersRead), userHandler.GetUser)                // Get user by ID
+		users.DELETE("/:id", require(entity.PermissionUsersDelete), userHandler.DeleteUser)        // Delete user
+		users.POST("/:id/restore", require(entity.PermissionUsersDelete), userHandler.RestoreUser) // Restore from the trash
+		users.POST("/:id/unlock", require(entity.PermissionUsersUnlock), userHandler.UnlockUser)   // Lift a failed-login lockout
+		users.PUT("/:id/role", require(entity.PermissionUsersAssignRole), roleHandler.AssignRole)  // Assign a built-in or custom role
+	}
+
+	// Moderation of the users' documents
+	moderate := require(entity.PermissionDocumentsModerate)
+	{
+		users.GET("/:id/documents", moderate, documentHandler.ListUserDocuments)                  // List a user's documents
+		users.DELETE("/:id/documents/:document_id", moderate, documentHandler.DeleteUserDocument) // Delete a user's document
+	}
+
+	// Moderation of the uploaded avatars, when AVATAR_MODERATION is on
+	avatars := group.Group("/admin/avatars", require(entity.PermissionAvatarsModerate))
+	{
+		avatars.GET("", avatarHandler.ListPendingAvatars)         // Avatars awaiting moderation, oldest first
+		avatars.GET("/:id", avatarHandler.ServePendingAvatar)     // View a user's pending avatar
+		avatars.POST("/:id/approve", avatarHandler.ApproveAvatar) // Show it in place of the user's avatar
+		avatars.POST("/:id/reject", avatarHandler.RejectAvatar)   // Delete it, keeping the user's avatar
+	}
+
+	// Admin roles and their permissions
+	group.GET("/admin/permissions", require(entity.PermissionRolesManage), roleHandler.ListPermissions)
+	roles := group.Group("/admin/roles", require(entity.PermissionRolesManage))
+	{
+		roles.GET("", roleHandler.ListRoles)           // Built-in and custom roles
+		roles.POST("", roleHandler.CreateRole)         // Create a custom role
+		roles.GET("/:name", roleHandler.GetRole)       // Get a role with its permissions
+		roles.PUT("/:name", roleHandler.UpdateRole)    // Replace a custom role's permissions
+		roles.DELETE("/:name", roleHandler.DeleteRole) // Delete a custom role no user has
+	}
+
+	// Admin custom profile fields of the users' metadata
+	metadataFields := group.Group("/admin/user-metadata-fields", require(entity.PermissionUserMetadataManage))
+	{
+		metadataFields.GET("", userMetadataHandler.ListMetadataFields)          // Fields users can set
+		metadataFields.POST("", userMetadataHandler.CreateMetadataField)        // Define a field
+		metadataFields.PUT("/:key", userMetadataHandler.UpdateMetadataField)    // Replace a field's type
+		metadataFields.DELETE("/:key", userMetadataHandler.DeleteMetadataField) // Delete a field
+	}
+
+	// Admin API key management
+	apiKeys := group.Group("/admin/api-keys", require(entity.PermissionAPIKeysManage))
+	{
+		apiKeys.PUT("/:id/rate-limit", apiKeyHandler.UpdateAPIKeyRateLimit) // Change per-key quota
+	}
+
+	// Admin usage quota management
+	quotas := group.Group("/admin/quotas", require(entity.PermissionQuotasManage))
+	{
+		quotas.GET("/users/:id", quotaHandler.GetUserQuotas)                  // Get usage and limits
+		quotas.PUT("/users/:id/:metric", quotaHandler.SetQuotaOverride)       // Override a limit
+		quotas.DELETE("/users/:id/:metric", quotaHandler.RemoveQuotaOverride) // Restore the default limit
+		quotas.POST("/users/:id/:metric/reset", quotaHandler.ResetQuotaUsage) // Clear current usage
+	}
+
+	// Admin rate limiter management
+	rateLimits := group.Group("/admin/rate-limits", require(entity.PermissionRateLimitsManage))
+	{
+		rateLimits.GET("", rateLimitHandler.ListRateLimits)               // Inspect counters (?key= prefix)
+		rateLimits.GET("/degradations", rateLimitHandler.GetDegradations) // Fail-open/closed counters
+		rateLimits.DELETE("/:key", rateLimitHandler.ResetRateLimit)       // Unblock a client
+	}
+	users.PUT("/:id/rate-limit-tier", require(entity.PermissionRateLimitsManage), rateLimitHandler.SetUserTier)
+
+	// Admin runtime log levels
+	logLevel := group.Group("/admin/log-level", require(entity.PermissionLogLevelManage))
+	{
+		logLevel.GET("", logLevelHandler.GetLogLevel)                    // Current levels
+		logLevel.PUT("", logLevelHandler.SetLogLevel)                    // Change the level or a module's
+		logLevel.DELETE("/:module", logLevelHandler.ResetModuleLogLevel) // Follow the logger's level again
+	}
+
+	// Effective configuration, for diagnosing misconfiguration
+	group.GET("/admin/config", require(entity.PermissionConfigRead), configHandler.GetConfig)
+
+	// Admin background job queue
+	jobs := group.Group("/admin/jobs", require(entity.PermissionJobsManage))
+	{
+		jobs.GET("", jobHandler.ListJobs)            // Inspect jobs (?status=dead for the dead-letter queue)
+		jobs.GET("/:id", jobHandler.GetJob)          // Get a job with its payload and last error
+		jobs.POST("/:id/retry", jobHandler.RetryJob) // Requeue a dead job
+	}
+
+	// Admin invitations to register
+	invitations := group.Group("/admin/invitations", require(entity.PermissionInvitationsManage))
+	{
+		invitations.POST("", invitationHandler.CreateInvitation)       // Invite an email, emailing the link
+		invitations.GET("", invitationHandler.ListInvitations)         // List invitations and their status
+		invitations.DELETE("/:id", invitationHandler.RevokeInvitation) // Disable an invitation link
+	}
+
+	// Security events of every account (?user_id= and ?type= filter)
+	group.GET("/admin/security-events", require(entity.PermissionSecurityEventsRead), securityEventHandler.ListEvents)
+
+	// Who did what (?actor_id=, ?action=, ?target_type=, ?target_id=, ?from= and ?to= filter)
+	group.GET("/admin/audit-log", require(entity.PermissionAuditLogRead), auditLogHandler.ListAuditLog)
+
+	// Read-only operations dashboard, cached for a short TTL
+	dashboard := group.Group("/admin/dashboard", require(entity.PermissionDashboardRead))
+	{
+		dashboard.GET("/activity", dashboardHandler.GetActivity)      // Daily signups, logins and uploads (?days=)
+		dashboard.GET("/storage", dashboardHandler.GetStorage)        // Top storage consumers (?limit=)
+		dashboard.GET("/rate-limits", dashboardHandler.GetRateLimits) // Daily rate limit rejections (?days=)
+		dashboard.GET("/jobs", dashboardHandler.GetJobs)              // Job queue depth
+		dashboard.GET("/webhooks", dashboardHandler.GetWebhooks)      // Webhook failure rates (?hours=&limit=)
 	}
 }
 
-// healthCheck returns server health status
-func (r *Router) healthCheck(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status":    "ok",
-		"timestamp": gin.H{},
-		"version":   "1.0.0",
-	})
+// jsonFieldName returns the JSON name of a struct field, falling back to the Go name
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// Handler returns the HTTP handler to serve, which negotiates the API version of unversioned
+// /api requests from the Accept header before routing them
+func (r *Router) Handler() http.Handler {
+	return negotiateVersion(r.engine)
 }
 
 // GetEngine returns the Gin engine
 func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
-}
\ No newline at end of file
+}
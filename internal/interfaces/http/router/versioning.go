@@ -0,0 +1,124 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIVersion1 is the original API
+	APIVersion1 = "v1"
+	// APIVersion2 is the API for breaking DTO changes; routes without a v2 handler share the v1 one
+	APIVersion2 = "v2"
+
+	// DefaultAPIVersion serves unversioned requests that don't ask for a version
+	DefaultAPIVersion = APIVersion1
+
+	// apiVendorMediaType is the Accept media type prefix used to request a version,
+	// e.g. "application/vnd.ginfinity.v2+json"
+	apiVendorMediaType = "application/vnd.ginfinity."
+)
+
+// APIVersionInfo describes a mounted API version
+type APIVersionInfo struct {
+	Name string
+	// Deprecated versions stay mounted but advertise their deprecation in a response header
+	Deprecated bool
+}
+
+// apiVersions lists the mounted API versions, oldest first
+var apiVersions = []APIVersionInfo{
+	{Name: APIVersion1},
+	{Name: APIVersion2},
+}
+
+// APIVersions returns the names of the mounted API versions, oldest first
+func APIVersions() []string {
+	names := make([]string, len(apiVersions))
+	for i, version := range apiVersions {
+		names[i] = version.Name
+	}
+	return names
+}
+
+// isAPIVersion reports whether name is a mounted API version
+func isAPIVersion(name string) bool {
+	for _, version := range apiVersions {
+		if version.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// versionMiddleware tags requests with the API version that serves them
+func versionMiddleware(version APIVersionInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("api_version", version.Name)
+		c.Header("API-Version", version.Name)
+		if version.Deprecated {
+			c.Header("Deprecation", "true")
+		}
+		c.Next()
+	}
+}
+
+// negotiateVersion rewrites unversioned /api/... requests to the version named in the Accept
+// header, so /api/documents with "Accept: application/vnd.ginfinity.v2+json" is served by
+// /api/v2/documents. Requests whose path already names a version are left alone.
+func negotiateVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rest, ok := strings.CutPrefix(req.URL.Path, "/api/")
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		segment, _, _ := strings.Cut(rest, "/")
+		if isAPIVersion(segment) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		version := versionFromAccept(req.Header.Get("Accept"))
+		if version == "" {
+			version = DefaultAPIVersion
+		}
+
+		w.Header().Add("Vary", "Accept")
+		req.URL.Path = "/api/" + version + "/" + rest
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = "/api/" + version + "/" + strings.TrimPrefix(req.URL.RawPath, "/api/")
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// versionFromAccept returns the mounted version requested by an Accept header, either as a vendor
+// media type ("application/vnd.ginfinity.v2+json") or a version parameter ("application/json; version=2")
+func versionFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		if vendor, ok := strings.CutPrefix(mediaType, apiVendorMediaType); ok {
+			name, _, _ := strings.Cut(vendor, "+")
+			if isAPIVersion(name) {
+				return name
+			}
+		}
+
+		if value, ok := params["version"]; ok {
+			name := "v" + strings.TrimPrefix(value, "v")
+			if isAPIVersion(name) {
+				return name
+			}
+		}
+	}
+	return ""
+}